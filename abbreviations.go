@@ -0,0 +1,117 @@
+package autocomplete
+
+import (
+	"strings"
+	"unicode"
+)
+
+// AbbreviationExpander replaces abbreviated words with their full form, so that
+// "MG Rd." and "MG Road" index and query alike. Its Expand method is a Normalizer:
+//
+//	expander := autocomplete.NewAbbreviationExpander(autocomplete.IndianAddressAbbreviations())
+//	config.Options.Normalizer = expander.Expand
+//
+// Words are matched case-insensitively and a trailing period is consumed with
+// the abbreviation. Only whole words are expanded, so a query still being typed
+// ("sta") is left alone until it forms an abbreviation ("st" becomes "Street").
+// An AbbreviationExpander is immutable and safe for concurrent use.
+type AbbreviationExpander struct {
+	expansions map[string]string
+}
+
+// NewAbbreviationExpander creates an expander from a map of abbreviations to
+// their full forms. Abbreviations are matched case-insensitively; the map is
+// copied, so later changes to it have no effect.
+func NewAbbreviationExpander(expansions map[string]string) *AbbreviationExpander {
+	e := &AbbreviationExpander{expansions: make(map[string]string, len(expansions))}
+	for abbreviation, expansion := range expansions {
+		e.expansions[strings.ToLower(abbreviation)] = expansion
+	}
+	return e
+}
+
+// IndianAddressAbbreviations returns common abbreviations in Indian postal addresses
+// mapped to their full forms. It returns a new map on every call, so callers can
+// add, change, or remove entries before passing it to NewAbbreviationExpander.
+func IndianAddressAbbreviations() map[string]string {
+	return map[string]string{
+		"rd":    "Road",
+		"st":    "Street",
+		"ln":    "Lane",
+		"ngr":   "Nagar",
+		"opp":   "Opposite",
+		"nr":    "Near",
+		"bhd":   "Behind",
+		"apt":   "Apartment",
+		"apts":  "Apartments",
+		"bldg":  "Building",
+		"soc":   "Society",
+		"hsg":   "Housing",
+		"sec":   "Sector",
+		"ph":    "Phase",
+		"extn":  "Extension",
+		"blk":   "Block",
+		"flr":   "Floor",
+		"stn":   "Station",
+		"rly":   "Railway",
+		"jn":    "Junction",
+		"hwy":   "Highway",
+		"mkt":   "Market",
+		"sq":    "Square",
+		"cir":   "Circle",
+		"est":   "Estate",
+		"vill":  "Village",
+		"tal":   "Taluka",
+		"dist":  "District",
+		"govt":  "Government",
+		"hosp":  "Hospital",
+		"univ":  "University",
+		"clg":   "College",
+		"chs":   "Co-operative Housing Society",
+		"indl":  "Industrial",
+		"colny": "Colony",
+	}
+}
+
+// Expand returns text with every abbreviated word replaced by its full form.
+// Everything else, including punctuation and spacing, is kept as is.
+func (e *AbbreviationExpander) Expand(text string) string {
+	var b strings.Builder
+	b.Grow(len(text))
+
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		if !isWordRune(runes[i]) {
+			b.WriteRune(runes[i])
+			i++
+			continue
+		}
+
+		end := i
+		for end < len(runes) && isWordRune(runes[end]) {
+			end++
+		}
+		word := string(runes[i:end])
+
+		expansion, ok := e.expansions[strings.ToLower(word)]
+		if !ok {
+			b.WriteString(word)
+			i = end
+			continue
+		}
+		b.WriteString(expansion)
+		i = end
+		if i < len(runes) && runes[i] == '.' {
+			i++
+			// "Opp.Bus Stand" becomes "Opposite Bus Stand", not "OppositeBus Stand"
+			if i < len(runes) && isWordRune(runes[i]) {
+				b.WriteByte(' ')
+			}
+		}
+	}
+	return b.String()
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
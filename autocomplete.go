@@ -38,8 +38,13 @@ package autocomplete
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"math"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/remiges-tech/autocomplete/providers"
 )
@@ -54,6 +59,21 @@ type Result struct {
 
 	// Score indicates relevance (higher scores rank first).
 	Score float64 `json:"score"`
+
+	// Fallback is true when the result comes from the case-insensitive retry
+	// enabled by Options.CaseInsensitiveFallback rather than an exact-case match.
+	Fallback bool `json:"fallback,omitempty"`
+
+	// Stale is true when the provider failed and the result was served from
+	// the results kept for Options.StaleCacheSize instead.
+	Stale bool `json:"stale,omitempty"`
+
+	// Metadata is the payload the entry was indexed with, if any.
+	Metadata json.RawMessage `json:"metadata,omitempty"`
+
+	// Explanation breaks Score down into its components for queries made with
+	// RequestOptions.Explain, and is nil otherwise.
+	Explanation *ScoreExplanation `json:"explanation,omitempty"`
 }
 
 // AutoComplete defines the interface for autocomplete functionality.
@@ -63,26 +83,226 @@ type AutoComplete interface {
 	// If an entry with the given ID already exists, it will be replaced.
 	// The text parameter is what gets indexed and matched against queries,
 	// while display is what appears in search results.
-	// Returns ErrEmptyID, ErrEmptyText, or ErrEmptyDisplay for empty parameters.
+	// Returns ErrEmptyID, ErrEmptyText, or ErrEmptyDisplay for empty parameters;
+	// display may be empty when Options.DisplayResolver is set.
 	Index(ctx context.Context, id string, text string, display string) error
 
+	// IndexWithMetadata indexes like Index and stores metadata alongside the
+	// entry, returning it in Result.Metadata so that a selected result needs no
+	// further lookup. metadata is marshalled with encoding/json, so it may be a
+	// map[string]interface{}, a struct, or raw JSON as a json.RawMessage; nil
+	// stores no metadata. Returns ErrInvalidMetadata if metadata cannot be
+	// marshalled, or ErrMetadataUnsupported if the provider cannot store it.
+	IndexWithMetadata(ctx context.Context, id, text, display string, metadata interface{}) error
+
+	// IndexWithScore indexes like Index with the given score in place of the one
+	// Options.Scorer (or the default of 1) would give, e.g. to boost popular
+	// entries so they rank above equally good matches. Zero uses the usual
+	// score. Returns ErrInvalidScore if score is NaN or infinite.
+	IndexWithScore(ctx context.Context, id, text, display string, score float64) error
+
+	// UpdateScore sets the score of the entry with the given ID without
+	// indexing its text again, e.g. to apply nightly popularity figures.
+	// Entries that do not exist are ignored. Returns ErrEmptyID, ErrInvalidScore
+	// if score is NaN or infinite, or ErrScoreUpdateUnsupported if the provider
+	// does not implement providers.ScoreUpdater.
+	UpdateScore(ctx context.Context, id string, score float64) error
+
+	// IncrementScore adds delta, which may be negative, to the score of the
+	// entry with the given ID, e.g. each time a user selects it, so that
+	// frequently chosen suggestions rise over time. Concurrent increments all
+	// count. Entries that do not exist are ignored. Returns ErrEmptyID,
+	// ErrInvalidScore if delta is NaN or infinite, or
+	// ErrScoreIncrementUnsupported if the provider does not implement
+	// providers.ScoreIncrementer.
+	IncrementScore(ctx context.Context, id string, delta float64) error
+
+	// Exists reports whether an entry with the given ID is indexed, e.g. so that
+	// sync jobs can skip IDs they have already written. Returns ErrEmptyID, or
+	// ErrExistsUnsupported if the provider implements neither
+	// providers.EntryChecker nor providers.ChangeDetector.
+	Exists(ctx context.Context, id string) (bool, error)
+
+	// Count returns the number of entries indexed in the namespace, e.g. to
+	// show how many items are searchable. Copies kept for
+	// Options.CaseInsensitiveFallback and Options.Segmenter are not counted.
+	// Returns ErrCountUnsupported if the provider does not implement
+	// providers.EntryCounter.
+	Count(ctx context.Context) (int64, error)
+
+	// List returns a page of the entries indexed in the namespace, starting at
+	// cursor, and the cursor of the next page, e.g. to audit or export
+	// everything indexed without knowing IDs in advance. An empty cursor starts
+	// from the beginning; an empty next cursor means there are no more
+	// entries. pageSize is a hint: providers that scan their storage, such as
+	// Redis, may return more or fewer entries per page; zero or negative uses
+	// a default of 100. Entries are returned as Get returns them, except that
+	// providers that do not list scores leave Score 0. Entries written while
+	// paging may or may not be returned, and may be returned more than once.
+	// Returns ErrListUnsupported if the provider does not implement
+	// providers.EntryLister.
+	List(ctx context.Context, cursor string, pageSize int) (entries []Entry, nextCursor string, err error)
+
+	// Stats returns the size of the namespace's index: its entry count, token
+	// postings, approximate storage bytes, and provider-specific details, e.g.
+	// so that operators can chart index growth per namespace. Copies kept for
+	// Options.CaseInsensitiveFallback and Options.Segmenter are not included;
+	// they are stored under their own namespaces. Returns ErrStatsUnsupported
+	// if the provider does not implement providers.StatsReporter.
+	Stats(ctx context.Context) (Stats, error)
+
+	// Get returns the entry with the given ID as it is currently indexed, e.g.
+	// for admin tooling, with its text as indexed (after Options.Normalizer),
+	// its stored display, its current score, and its metadata. Displays left
+	// empty for Options.DisplayResolver are not resolved. Returns ErrEmptyID,
+	// ErrEntryNotFound if no entry has the ID, or ErrGetUnsupported if the
+	// provider does not implement providers.EntryGetter.
+	Get(ctx context.Context, id string) (Entry, error)
+
+	// IndexBatch adds or updates multiple entries and reports the outcome of each.
+	// The returned slice has one IndexResult per entry, in input order, so callers
+	// can retry only the failed entries. A failing entry does not stop the batch.
+	IndexBatch(ctx context.Context, entries []Entry) []IndexResult
+
+	// IndexAtomic adds or updates multiple entries as a single all-or-nothing unit,
+	// for logical records spread across several entries (aliases, fields).
+	// Every entry is validated before anything is written. Intended for small batches.
+	// Returns ErrTransactionsUnsupported if the provider cannot write atomically.
+	IndexAtomic(ctx context.Context, entries []Entry) error
+
+	// BatchIndex adds or updates multiple entries in as few round trips as the
+	// provider allows, for bulk loads such as a full dataset import. Unlike
+	// IndexBatch, stored entries are not looked up, so Options.SkipUnchanged is
+	// ignored and no per-entry outcome is reported. Every entry is validated
+	// before anything is written. If a write fails, some entries may have been
+	// written; the batch can be retried as a whole. Providers that cannot write
+	// in bulk index the entries one by one.
+	BatchIndex(ctx context.Context, entries []Entry) error
+
 	// Query searches for entries matching the given query string.
 	// Results are sorted by score (highest first). The matching behavior
 	// depends on the configured MatchStrategy. If limit is 0 or negative,
-	// DefaultLimit is used.
+	// DefaultLimit is used. With Options.CaseInsensitiveFallback, a case-sensitive
+	// query that finds nothing is retried ignoring case. With
+	// Options.StaleCacheSize, a query the provider fails is answered from the
+	// results it last returned, flagged with Result.Stale. RequestOptions
+	// carried by ctx (see WithRequestOptions) override the limit, namespace,
+	// and ranking.
 	// Returns ErrQueryTooShort if query is too short, ErrLimitExceeded if
-	// limit exceeds MaxLimit, or an empty slice if no matches are found.
+	// limit exceeds MaxLimit (unless Options.LimitPolicy is LimitClampToMax),
+	// or an empty slice if no matches are found.
 	Query(ctx context.Context, query string, limit int) ([]Result, error)
 
+	// QueryWithOptions searches like Query with the limit, minimum score, case
+	// sensitivity, and metadata filter overridden for this call by opts, e.g.
+	// so that one instance serves both a strict search box and a lenient
+	// admin lookup. Returns the errors of Query, ErrInvalidScore if
+	// opts.MinScore is NaN or infinite, or ErrCaseSensitivityNotIndexed if
+	// the namespace is not indexed for opts.CaseSensitive.
+	QueryWithOptions(ctx context.Context, query string, opts QueryOptions) ([]Result, error)
+
+	// QueryStrategy searches like Query but matches with the given strategy, which
+	// must be one of Options.MatchStrategies (or Options.MatchStrategy when that
+	// is empty). Returns ErrStrategyNotIndexed for any other strategy.
+	QueryStrategy(ctx context.Context, strategy MatchStrategy, query string, limit int) ([]Result, error)
+
+	// QueryEx searches like Query and returns the results in a Response, which
+	// also reports whether more entries matched than limit allowed, how many
+	// (up to Options.MoreCountLimit), and, when every match was fetched, how
+	// many there are. A query whose context deadline passes returns a Response
+	// with TimedOut set rather than an error.
+	QueryEx(ctx context.Context, query string, limit int) (Response, error)
+
+	// QueryPage searches like Query and returns one page of limit results,
+	// starting at cursor, and the cursor of the next page, for "load more"
+	// lists and infinite scroll. An empty cursor returns the first page; an
+	// empty next cursor means there are no more results. Providers that
+	// implement providers.PagedQuerier, such as Redis and Elasticsearch, read
+	// each page where the last one ended; others fetch the results up to the
+	// end of each page again, so paging stops at MaxLimit. Entries written
+	// while paging may be skipped or returned twice; use OpenSnapshot for a
+	// consistent view. Pages are not served stale, retried ignoring case, or
+	// joined with Options.Segmenter matches. Returns the errors of Query, or
+	// ErrInvalidCursor if cursor was not returned for the same query.
+	QueryPage(ctx context.Context, query string, limit int, cursor string) (results []Result, nextCursor string, err error)
+
+	// OpenSnapshot opens a consistent, read-only view of the namespace so that
+	// multi-page queries neither skip nor duplicate entries while indexing continues.
+	// The snapshot must be closed when no longer needed.
+	// Returns ErrSnapshotsUnsupported if the provider cannot provide snapshots.
+	OpenSnapshot(ctx context.Context) (Snapshot, error)
+
 	// Delete removes an entry from the autocomplete index.
 	// Deleting a non-existent entry returns nil (idempotent).
 	// Returns ErrEmptyID if id is empty.
 	Delete(ctx context.Context, id string) error
 
+	// DeleteMany removes the entries with the given IDs in as few round trips as
+	// the provider allows, e.g. for sync jobs removing thousands of stale IDs.
+	// IDs that do not exist are ignored. If a removal fails, some entries may
+	// have been removed; the call can be retried as a whole. Providers that
+	// cannot remove in bulk delete the entries one by one.
+	// Returns ErrEmptyID, and removes nothing, if any ID is empty.
+	DeleteMany(ctx context.Context, ids []string) error
+
 	// DeleteAll removes all entries from the autocomplete index.
 	// This operation is irreversible and only affects entries in the configured namespace.
 	DeleteAll(ctx context.Context) error
 
+	// Maintain removes orphaned data from the namespace, such as tokens left
+	// behind when entries are updated, keeping long-lived namespaces healthy.
+	// The case-folded and segment copies of the entries are maintained too,
+	// with progress reported as running totals across all of them.
+	// Maintain does not expire entries or purge tombstones: entries have no
+	// TTL and are removed as soon as they are deleted, so there are none.
+	// It can be run periodically (e.g. from a ticker) while the index is in use.
+	// Returns ErrMaintenanceUnsupported if the provider has nothing to maintain.
+	Maintain(ctx context.Context, options MaintenanceOptions) (MaintenanceStats, error)
+
+	// Compact merges fragmented index data and reclaims the space left by
+	// deleted and replaced entries, e.g. after heavy delete churn, in every
+	// namespace the provider holds. It can take as long as rewriting the index
+	// and may hold up writes, so it suits quiet periods. Returns
+	// ErrCompactionUnsupported if the provider does not implement
+	// providers.Compactor, as only embedded providers do.
+	Compact(ctx context.Context) error
+
+	// Renormalize re-indexes entries that were indexed with a NormalizerVersion
+	// other than the configured one, so that changes to Options.Normalizer reach
+	// existing entries gradually. It can run in the background while the index
+	// is in use. Returns ErrRenormalizeUnsupported if the provider cannot list entries.
+	Renormalize(ctx context.Context, options RenormalizeOptions) (RenormalizeStats, error)
+
+	// ExportSince returns the entries indexed and deleted since cursor, the
+	// Cursor of a previous Delta, for incremental backups and downstream sync
+	// without full dumps. An empty cursor exports every entry. Entries written
+	// while ExportSince runs are returned by it or by the next call.
+	// Returns ErrExportUnsupported if the provider does not record changes.
+	ExportSince(ctx context.Context, cursor string) (Delta, error)
+
+	// AliasNamespace makes every operation on namespace alias act on namespace
+	// target, so that instances configured with alias switch to a dataset built
+	// under another name without changing configuration. Re-aliasing re-points
+	// the alias in a single step, and an empty target removes it. The copies kept
+	// for Options.CaseInsensitiveFallback and Options.Segmenter are re-pointed
+	// just before the namespace itself.
+	// Returns ErrAliasesUnsupported if the provider cannot alias namespaces.
+	AliasNamespace(ctx context.Context, alias, target string) error
+
+	// RenameNamespace moves the entries of namespace oldName to namespace
+	// newName in a single step, replacing the entries newName held, and
+	// re-points aliases of oldName to newName. Like AliasNamespace, it also
+	// moves the copies kept for the configured options.
+	// Returns ErrAliasesUnsupported if the provider cannot rename namespaces.
+	RenameNamespace(ctx context.Context, oldName, newName string) error
+
+	// UpdateOptions changes query-time options, such as DefaultLimit and
+	// MinScore, while the instance is in use, without reconnecting or
+	// reindexing. Queries already running finish with the previous values.
+	// Returns an error wrapping ErrInvalidOptions, and changes nothing, if the
+	// patched options are inconsistent.
+	UpdateOptions(ctx context.Context, patch OptionsPatch) error
+
 	// Close closes the autocomplete provider and releases resources.
 	// It is safe to call multiple times. After Close, other methods will fail.
 	Close() error
@@ -90,69 +310,296 @@ type AutoComplete interface {
 
 // autocompleteImpl is the default implementation of AutoComplete.
 type autocompleteImpl struct {
-	provider providers.Provider
-	config   Config
+	provider        providers.Provider
+	config          Config
+	displays        *lruCache[string]
+	staleResults    *lruCache[keptResults]
+	namespaceTokens atomic.Int64
+
+	// clock is Options.Clock, or the system clock.
+	clock Clock
+
+	// tunables holds the options UpdateOptions can change; tunablesMu serializes updates.
+	tunables   atomic.Pointer[tunables]
+	tunablesMu sync.Mutex
 }
 
 // Index adds or updates a text entry for autocomplete.
 // See AutoComplete.Index for details.
 func (a *autocompleteImpl) Index(ctx context.Context, id, text, display string) error {
-	if id == "" {
+	return a.index(ctx, Entry{ID: id, Text: text, Display: display})
+}
+
+// IndexWithMetadata adds or updates an entry carrying a metadata payload.
+// See AutoComplete.IndexWithMetadata for details.
+func (a *autocompleteImpl) IndexWithMetadata(ctx context.Context, id, text, display string, metadata interface{}) error {
+	payload, err := marshalMetadata(metadata)
+	if err != nil {
+		return err
+	}
+	return a.index(ctx, Entry{ID: id, Text: text, Display: display, Metadata: payload})
+}
+
+// IndexWithScore adds or updates an entry with an explicit score.
+// See AutoComplete.IndexWithScore for details.
+func (a *autocompleteImpl) IndexWithScore(ctx context.Context, id, text, display string, score float64) error {
+	return a.index(ctx, Entry{ID: id, Text: text, Display: display, Score: score})
+}
+
+// index writes a single entry, then drops its cached display text and reports it.
+func (a *autocompleteImpl) index(ctx context.Context, entry Entry) error {
+	_, err := a.indexEntry(ctx, entry, a.config.Options.SkipUnchanged)
+	if err != nil {
+		return err
+	}
+	if a.displays != nil {
+		a.displays.remove(entry.ID)
+	}
+	a.reportIndexMutation(ctx, []Entry{entry}, false)
+	return nil
+}
+
+// indexEntry validates and writes a single entry, reporting what happened to it.
+// When inspect is true the stored entry is looked up first, so new entries are
+// reported as created and unchanged entries are skipped if SkipUnchanged is set.
+func (a *autocompleteImpl) indexEntry(ctx context.Context, entry Entry, inspect bool) (IndexStatus, error) {
+	entry.Text = a.normalize(entry.Text)
+	if err := a.validateEntry(entry); err != nil {
+		return IndexFailed, err
+	}
+	entry, err := a.applyTokenBudget(ctx, entry)
+	if err != nil {
+		return IndexFailed, err
+	}
+
+	options := a.indexOptions(entry)
+
+	status := IndexUpdated
+	if inspect {
+		status, err = a.storedStatus(ctx, entry.ID, options.ContentHash)
+		if err != nil {
+			return IndexFailed, err
+		}
+	}
+	if status == IndexUnchanged && a.config.Options.SkipUnchanged {
+		return IndexUnchanged, nil
+	}
+
+	err = a.provider.Index(ctx, a.config.Options.Namespace, entry.ID, entry.Text, entry.Display, options)
+	if err != nil {
+		return IndexFailed, err
+	}
+	if err := a.indexFolded(ctx, entry, options); err != nil {
+		return IndexFailed, err
+	}
+	if err := a.indexSegments(ctx, entry, options); err != nil {
+		return IndexFailed, err
+	}
+	a.reportIndexed(ctx, entry)
+	return status, nil
+}
+
+// validateEntry checks that all required entry fields are present.
+// Display may be empty when a DisplayResolver supplies it at query time.
+func (a *autocompleteImpl) validateEntry(entry Entry) error {
+	if entry.ID == "" {
 		return ErrEmptyID
 	}
-	if text == "" {
+	if entry.Text == "" {
 		return ErrEmptyText
 	}
-	if display == "" {
+	if entry.Display == "" && a.config.Options.DisplayResolver == nil {
 		return ErrEmptyDisplay
 	}
+	if score := a.entryScore(entry); math.IsNaN(score) || math.IsInf(score, 0) {
+		return ErrInvalidScore
+	}
+	return a.validateMetadata(entry.Metadata)
+}
 
+// indexOptions builds the provider index options for an entry.
+func (a *autocompleteImpl) indexOptions(entry Entry) providers.IndexOptions {
 	options := providers.IndexOptions{
-		Score:         1.0,
-		MatchStrategy: providers.MatchStrategy(a.config.Options.MatchStrategy),
-		NGramSize:     a.config.Options.NGramSize,
-		CaseSensitive: a.config.Options.CaseSensitive,
+		Metadata:        string(entry.Metadata),
+		Score:           a.entryScore(entry),
+		MatchStrategy:   providers.MatchStrategy(a.config.Options.MatchStrategy),
+		NGramSize:       a.config.Options.NGramSize,
+		CaseSensitive:   a.config.Options.CaseSensitive,
+		MatchStrategies: providerStrategies(a.config.Options.MatchStrategies),
+	}
+	options.ContentHash = a.versionedHash(contentHash(entry.Text, entry.Display, options, a.caseFallback()))
+	return options
+}
+
+// Exists reports whether an entry is stored.
+// See AutoComplete.Exists for details.
+func (a *autocompleteImpl) Exists(ctx context.Context, id string) (bool, error) {
+	if id == "" {
+		return false, ErrEmptyID
+	}
+	if checker, ok := a.provider.(providers.EntryChecker); ok {
+		return checker.Exists(ctx, a.config.Options.Namespace, id)
 	}
+	detector, ok := a.provider.(providers.ChangeDetector)
+	if !ok {
+		return false, ErrExistsUnsupported
+	}
+	_, exists, err := detector.ContentHash(ctx, a.config.Options.Namespace, id)
+	return exists, err
+}
 
-	return a.provider.Index(ctx, a.config.Options.Namespace, id, text, display, options)
+// Count returns the number of stored entries.
+// See AutoComplete.Count for details.
+func (a *autocompleteImpl) Count(ctx context.Context) (int64, error) {
+	counter, ok := a.provider.(providers.EntryCounter)
+	if !ok {
+		return 0, ErrCountUnsupported
+	}
+	return counter.CountEntries(ctx, a.config.Options.Namespace)
+}
+
+// Get returns a stored entry.
+// See AutoComplete.Get for details.
+func (a *autocompleteImpl) Get(ctx context.Context, id string) (Entry, error) {
+	if id == "" {
+		return Entry{}, ErrEmptyID
+	}
+	getter, ok := a.provider.(providers.EntryGetter)
+	if !ok {
+		return Entry{}, ErrGetUnsupported
+	}
+	stored, exists, err := getter.GetEntry(ctx, a.config.Options.Namespace, id)
+	if err != nil {
+		return Entry{}, err
+	}
+	if !exists {
+		return Entry{}, ErrEntryNotFound
+	}
+	entry := Entry{ID: stored.ID, Text: stored.Text, Display: stored.Display, Score: stored.Score}
+	if stored.Metadata != "" {
+		entry.Metadata = json.RawMessage(stored.Metadata)
+	}
+	return entry, nil
+}
+
+// List returns a page of stored entries.
+// See AutoComplete.List for details.
+func (a *autocompleteImpl) List(ctx context.Context, cursor string, pageSize int) ([]Entry, string, error) {
+	lister, ok := a.provider.(providers.EntryLister)
+	if !ok {
+		return nil, "", ErrListUnsupported
+	}
+	if pageSize <= 0 {
+		pageSize = defaultListPageSize
+	}
+	stored, next, err := lister.ListEntries(ctx, a.config.Options.Namespace, cursor, pageSize)
+	if err != nil {
+		return nil, "", err
+	}
+	entries := make([]Entry, len(stored))
+	for i, e := range stored {
+		entries[i] = Entry{ID: e.ID, Text: e.Text, Display: e.Display, Metadata: metadataPayload(e.Metadata), Score: e.Score}
+	}
+	return entries, next, nil
+}
+
+// storedStatus compares an entry's content hash against the stored one.
+// It returns IndexUpdated when the provider cannot detect changes.
+func (a *autocompleteImpl) storedStatus(ctx context.Context, id, hash string) (IndexStatus, error) {
+	detector, ok := a.provider.(providers.ChangeDetector)
+	if !ok {
+		return IndexUpdated, nil
+	}
+
+	stored, exists, err := detector.ContentHash(ctx, a.config.Options.Namespace, id)
+	if err != nil {
+		return IndexFailed, err
+	}
+	switch {
+	case !exists:
+		return IndexCreated, nil
+	case stored == hash:
+		return IndexUnchanged, nil
+	default:
+		return IndexUpdated, nil
+	}
 }
 
 // Query searches for entries matching the given query.
 // See AutoComplete.Query for details.
 func (a *autocompleteImpl) Query(ctx context.Context, query string, limit int) ([]Result, error) {
-	if len(query) < a.config.Options.MinPrefixLength {
-		return nil, ErrQueryTooShort
+	query = a.normalize(query)
+	options, err := a.queryOptions(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := a.queryOrStale(ctx, query, options)
+	return boostResults(ctx, results), err
+}
+
+// queryOptions validates a query and builds the provider query options for it,
+// taking a non-positive limit from the RequestOptions carried by ctx.
+func (a *autocompleteImpl) queryOptions(ctx context.Context, query string, limit int) (providers.QueryOptions, error) {
+	if a.queryLength(query) < a.tuning().minPrefixLength {
+		return providers.QueryOptions{}, ErrQueryTooShort
 	}
 
 	if limit <= 0 {
-		limit = a.config.Options.DefaultLimit
+		limit = requestOptions(ctx).Limit
 	}
-	if limit > a.config.Options.MaxLimit {
-		return nil, ErrLimitExceeded
+	limit, err := a.effectiveLimit(limit)
+	if err != nil {
+		return providers.QueryOptions{}, err
 	}
-
-	options := providers.QueryOptions{
-		MaxResults:    limit,
-		CaseSensitive: a.config.Options.CaseSensitive,
-		MatchStrategy: providers.MatchStrategy(a.config.Options.MatchStrategy),
-		NGramSize:     a.config.Options.NGramSize,
+	filter := requestOptions(ctx).Filter
+	if len(filter) > 0 && !a.supportsMetadata() {
+		return providers.QueryOptions{}, ErrMetadataUnsupported
 	}
-
-	providerResults, err := a.provider.Query(ctx, a.config.Options.Namespace, query, options)
+	caseSensitive, err := a.queryCaseSensitive(ctx)
 	if err != nil {
-		return nil, err
+		return providers.QueryOptions{}, err
 	}
 
+	return providers.QueryOptions{
+		ExplainScores:   requestOptions(ctx).Explain,
+		FilterMetadata:  filter,
+		MaxResults:      limit,
+		MinScore:        a.minScore(ctx),
+		CaseSensitive:   caseSensitive,
+		MatchStrategy:   providers.MatchStrategy(a.config.Options.MatchStrategy),
+		NGramSize:       a.config.Options.NGramSize,
+		MatchStrategies: providerStrategies(a.config.Options.MatchStrategies),
+	}, nil
+}
+
+// runQuery executes a query against the configured namespace and converts the results.
+//
+//nolint:gocritic // hugeParam: options is passed by value to match the Provider interface
+func (a *autocompleteImpl) runQuery(ctx context.Context, query string, options providers.QueryOptions) ([]Result, error) {
+	return a.queryResults(ctx, a.config.Options.Namespace, query, options, false)
+}
+
+// convertResults converts provider results scoring at least Options.MinScore,
+// resolving display text if configured.
+func (a *autocompleteImpl) convertResults(ctx context.Context, providerResults []providers.ProviderResult, fallback bool) []Result {
+	providerResults = a.aboveMinScore(ctx, providerResults)
 	results := make([]Result, len(providerResults))
 	for i, pr := range providerResults {
 		results[i] = Result{
-			ID:      pr.ID,
-			Display: pr.Display,
-			Score:   pr.Score,
+			ID:       pr.ID,
+			Display:  pr.Display,
+			Score:    pr.Score,
+			Fallback: fallback,
+			Metadata: metadataPayload(pr.Metadata),
+		}
+		if requestOptions(ctx).Explain {
+			results[i].Explanation = explainScore(pr)
 		}
 	}
+	a.resolveDisplays(ctx, results)
 
-	return results, nil
+	return results
 }
 
 // Delete removes an entry from the autocomplete index.
@@ -161,14 +608,104 @@ func (a *autocompleteImpl) Delete(ctx context.Context, id string) error {
 	if id == "" {
 		return ErrEmptyID
 	}
+	if a.displays != nil {
+		a.displays.remove(id)
+	}
 
-	return a.provider.Delete(ctx, a.config.Options.Namespace, id)
+	if err := a.provider.Delete(ctx, a.config.Options.Namespace, id); err != nil {
+		return err
+	}
+	if a.caseFallback() {
+		if err := a.provider.Delete(ctx, a.foldedNamespace(), id); err != nil {
+			return err
+		}
+	}
+	if err := a.deleteSegments(ctx, id); err != nil {
+		return err
+	}
+	a.reportMutation(ctx, Mutation{Op: MutationDelete, ID: id})
+	return nil
+}
+
+// DeleteMany removes the entries with the given IDs.
+// See AutoComplete.DeleteMany for details.
+func (a *autocompleteImpl) DeleteMany(ctx context.Context, ids []string) error {
+	for i, id := range ids {
+		if id == "" {
+			return fmt.Errorf("%w: id %d", ErrEmptyID, i)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	if a.displays != nil {
+		for _, id := range ids {
+			a.displays.remove(id)
+		}
+	}
+
+	deleter, ok := a.provider.(providers.BatchDeleter)
+	if !ok {
+		deleter = entryByEntryDeleter{a.provider}
+	}
+	if err := deleter.DeleteMany(ctx, a.config.Options.Namespace, ids); err != nil {
+		return err
+	}
+	if a.caseFallback() {
+		if err := deleter.DeleteMany(ctx, a.foldedNamespace(), ids); err != nil {
+			return err
+		}
+	}
+	if a.config.Options.Segmenter != nil {
+		segmentIDs := make([]string, 0, len(ids)*maxSegments)
+		for _, id := range ids {
+			for n := 1; n <= maxSegments; n++ {
+				segmentIDs = append(segmentIDs, segmentID(id, n))
+			}
+		}
+		if err := deleter.DeleteMany(ctx, a.segmentNamespace(), segmentIDs); err != nil {
+			return err
+		}
+	}
+	a.reportMutation(ctx, Mutation{Op: MutationDeleteMany, IDs: slices.Clone(ids)})
+	return nil
+}
+
+// entryByEntryDeleter removes entries with one Delete call per ID, for
+// providers that do not implement providers.BatchDeleter.
+type entryByEntryDeleter struct {
+	provider providers.Provider
+}
+
+// DeleteMany removes the entries one at a time, stopping at the first failure.
+func (d entryByEntryDeleter) DeleteMany(ctx context.Context, key string, ids []string) error {
+	for _, id := range ids {
+		if err := d.provider.Delete(ctx, key, id); err != nil {
+			return fmt.Errorf("entry %q: %w", id, err)
+		}
+	}
+	return nil
 }
 
 // DeleteAll removes all entries from the autocomplete index.
 // See AutoComplete.DeleteAll for details.
 func (a *autocompleteImpl) DeleteAll(ctx context.Context) error {
-	return a.provider.DeleteAll(ctx, a.config.Options.Namespace)
+	a.clearCaches()
+	if err := a.provider.DeleteAll(ctx, a.config.Options.Namespace); err != nil {
+		return err
+	}
+	if a.caseFallback() {
+		if err := a.provider.DeleteAll(ctx, a.foldedNamespace()); err != nil {
+			return err
+		}
+	}
+	if a.config.Options.Segmenter != nil {
+		if err := a.provider.DeleteAll(ctx, a.segmentNamespace()); err != nil {
+			return err
+		}
+	}
+	a.reportMutation(ctx, Mutation{Op: MutationDeleteAll})
+	return nil
 }
 
 // Close closes the autocomplete provider and releases resources.
@@ -181,6 +718,8 @@ func (a *autocompleteImpl) Close() error {
 // The providerType must be registered (case-insensitive). Config contains
 // both provider-specific settings and common options.
 // Returns ErrProviderNotFound if the provider is not registered.
+// NewOption values such as WithSelfTest and WithWarmStart enable optional
+// startup behavior.
 //
 // Example:
 //
@@ -190,8 +729,10 @@ func (a *autocompleteImpl) Close() error {
 //	ac, err := autocomplete.New("redis", config)
 //
 //nolint:gocritic // hugeParam: Config is 80 bytes but New() is only called once at startup, making the copy negligible
-func New(providerType string, config Config) (AutoComplete, error) {
-	factory, exists := providerFactories[providerType]
+func New(providerType string, config Config, opts ...NewOption) (AutoComplete, error) {
+	providerFactoriesMu.RLock()
+	factory, exists := providerFactories[strings.ToLower(providerType)]
+	providerFactoriesMu.RUnlock()
 	if !exists {
 		return nil, fmt.Errorf("%w: %s", ErrProviderNotFound, providerType)
 	}
@@ -200,11 +741,46 @@ func New(providerType string, config Config) (AutoComplete, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := validateStrategies(provider, config.Options); err != nil {
+		_ = provider.Close()
+		return nil, err
+	}
 
-	return &autocompleteImpl{
+	ac := &autocompleteImpl{
 		provider: provider,
 		config:   config,
-	}, nil
+		clock:    clockOrSystem(config.Options.Clock),
+	}
+	ac.tunables.Store(newTunables(config.Options))
+	if config.Options.DisplayResolver != nil && config.Options.DisplayCacheSize > 0 {
+		ac.displays = newLRUCache[string](config.Options.DisplayCacheSize, config.Options.DisplayCacheTTL, ac.clock)
+	}
+	if config.Options.StaleCacheSize > 0 {
+		ttl := config.Options.StaleCacheTTL
+		if ttl <= 0 {
+			ttl = defaultStaleCacheTTL
+		}
+		ac.staleResults = newLRUCache[keptResults](config.Options.StaleCacheSize, ttl, ac.clock)
+	}
+
+	var options newOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.selfTest {
+		if err := ac.selfTest(context.Background()); err != nil {
+			_ = provider.Close()
+			return nil, err
+		}
+	}
+	if options.warmStart != nil {
+		if err := ac.warmStart(context.Background(), options.warmStart); err != nil {
+			_ = provider.Close()
+			return nil, err
+		}
+	}
+
+	return ac, nil
 }
 
 // ProviderFactory creates a Provider instance from a configuration.
@@ -212,7 +788,10 @@ func New(providerType string, config Config) (AutoComplete, error) {
 type ProviderFactory func(config interface{}) (providers.Provider, error)
 
 // providerFactories holds the registered provider factories.
-var providerFactories = make(map[string]ProviderFactory)
+var (
+	providerFactoriesMu sync.RWMutex
+	providerFactories   = make(map[string]ProviderFactory)
+)
 
 // RegisterProvider registers a new autocomplete provider factory.
 // Typically called from a provider's init() function. The name is
@@ -242,10 +821,10 @@ var providerFactories = make(map[string]ProviderFactory)
 //	    }
 //	}
 //
-// Thread safety:
-//   - Safe to call during init() (single-threaded)
-//   - Not safe to call after init() (no mutex protection)
-//   - In practice, only called during init() so this is not an issue
+// RegisterProvider is safe to call at any time, so providers can also be
+// registered programmatically, e.g. by RegisterMapProvider or LoadPlugin.
 func RegisterProvider(name string, factory ProviderFactory) {
+	providerFactoriesMu.Lock()
+	defer providerFactoriesMu.Unlock()
 	providerFactories[strings.ToLower(name)] = factory
 }
@@ -2,21 +2,31 @@ package autocomplete
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/remiges-tech/autocomplete/providers"
 )
 
 // mockProvider is an in-memory provider for testing.
 type mockProvider struct {
-	data map[string]map[string]*mockEntry
+	data       map[string]map[string]*mockEntry
+	indexCalls int
 }
 
 type mockEntry struct {
 	text          string
 	result        *providers.ProviderResult
 	caseSensitive bool
+	contentHash   string
 }
 
 func newMockProvider() *mockProvider {
@@ -26,6 +36,7 @@ func newMockProvider() *mockProvider {
 }
 
 func (m *mockProvider) Index(ctx context.Context, key, id, text, display string, options providers.IndexOptions) error {
+	m.indexCalls++
 	if m.data[key] == nil {
 		m.data[key] = make(map[string]*mockEntry)
 	}
@@ -36,15 +47,34 @@ func (m *mockProvider) Index(ctx context.Context, key, id, text, display string,
 	m.data[key][id] = &mockEntry{
 		text: indexText,
 		result: &providers.ProviderResult{
-			ID:      id,
-			Display: display,
-			Score:   options.Score,
+			ID:       id,
+			Display:  display,
+			Score:    options.Score,
+			Metadata: options.Metadata,
 		},
 		caseSensitive: options.CaseSensitive,
+		contentHash:   options.ContentHash,
 	}
 	return nil
 }
 
+func (m *mockProvider) IndexAtomic(ctx context.Context, key string, entries []providers.IndexEntry) error {
+	for _, entry := range entries {
+		if err := m.Index(ctx, key, entry.ID, entry.Text, entry.Display, entry.Options); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *mockProvider) ContentHash(ctx context.Context, key, id string) (string, bool, error) {
+	entry, exists := m.data[key][id]
+	if !exists {
+		return "", false, nil
+	}
+	return entry.contentHash, true, nil
+}
+
 func (m *mockProvider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
 	var results []providers.ProviderResult
 	if keyData, exists := m.data[key]; exists {
@@ -53,6 +83,9 @@ func (m *mockProvider) Query(ctx context.Context, key, query string, options pro
 			searchQuery = strings.ToLower(query)
 		}
 		for _, entry := range keyData {
+			if !providers.MatchesMetadata(entry.result.Metadata, options.FilterMetadata) {
+				continue
+			}
 			if len(entry.text) >= len(searchQuery) && entry.text[:len(searchQuery)] == searchQuery {
 				results = append(results, *entry.result)
 				if len(results) >= options.MaxResults {
@@ -284,3 +317,2790 @@ func TestCaseSensitive(t *testing.T) {
 		})
 	}
 }
+
+func TestSkipUnchanged(t *testing.T) {
+	provider := newMockProvider()
+	RegisterProvider("mock-skip", func(config interface{}) (providers.Provider, error) {
+		return provider, nil
+	})
+
+	config := NewConfig(nil)
+	config.Options.SkipUnchanged = true
+	ac, err := New("mock-skip", config)
+	if err != nil {
+		t.Fatalf("Failed to create autocomplete: %v", err)
+	}
+
+	ctx := context.Background()
+	steps := []struct {
+		name      string
+		text      string
+		display   string
+		wantCalls int
+	}{
+		{"first index writes", "Mumbai", "Mumbai, Maharashtra", 1},
+		{"identical index is skipped", "Mumbai", "Mumbai, Maharashtra", 1},
+		{"changed display writes", "Mumbai", "Mumbai, MH", 2},
+		{"changed text writes", "Mumbai City", "Mumbai, MH", 3},
+		{"identical again is skipped", "Mumbai City", "Mumbai, MH", 3},
+	}
+
+	for _, step := range steps {
+		if err := ac.Index(ctx, "1", step.text, step.display); err != nil {
+			t.Fatalf("%s: Index() error = %v", step.name, err)
+		}
+		if provider.indexCalls != step.wantCalls {
+			t.Errorf("%s: provider Index calls = %d, want %d", step.name, provider.indexCalls, step.wantCalls)
+		}
+	}
+
+	if err := ac.Delete(ctx, "1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := ac.Index(ctx, "1", "Mumbai City", "Mumbai, MH"); err != nil {
+		t.Fatalf("Index() after delete error = %v", err)
+	}
+	if provider.indexCalls != 4 {
+		t.Errorf("Index() after delete should write, provider Index calls = %d, want 4", provider.indexCalls)
+	}
+}
+
+func TestIndexBatch(t *testing.T) {
+	RegisterProvider("mock-batch", func(config interface{}) (providers.Provider, error) {
+		return newMockProvider(), nil
+	})
+
+	ac, err := New("mock-batch", NewConfig(nil))
+	if err != nil {
+		t.Fatalf("Failed to create autocomplete: %v", err)
+	}
+
+	ctx := context.Background()
+	results := ac.IndexBatch(ctx, []Entry{
+		{ID: "1", Text: "Mumbai", Display: "Mumbai, Maharashtra"},
+		{ID: "2", Text: "", Display: "Pune, Maharashtra"},
+		{ID: "3", Text: "Chennai", Display: "Chennai, Tamil Nadu"},
+	})
+	wantFirst := []IndexStatus{IndexCreated, IndexFailed, IndexCreated}
+	for i, want := range wantFirst {
+		if results[i].Status != want {
+			t.Errorf("first batch result %d status = %v, want %v", i, results[i].Status, want)
+		}
+	}
+	if results[1].Err != ErrEmptyText {
+		t.Errorf("first batch result 1 error = %v, want %v", results[1].Err, ErrEmptyText)
+	}
+
+	results = ac.IndexBatch(ctx, []Entry{
+		{ID: "1", Text: "Mumbai", Display: "Mumbai, Maharashtra"},
+		{ID: "3", Text: "Chennai", Display: "Chennai, TN"},
+	})
+	wantSecond := []IndexStatus{IndexUnchanged, IndexUpdated}
+	for i, want := range wantSecond {
+		if results[i].Status != want || results[i].Err != nil {
+			t.Errorf("second batch result %d = (%v, %v), want (%v, nil)", i, results[i].Status, results[i].Err, want)
+		}
+	}
+}
+
+func TestIndexAtomic(t *testing.T) {
+	provider := newMockProvider()
+	RegisterProvider("mock-atomic", func(config interface{}) (providers.Provider, error) {
+		return provider, nil
+	})
+
+	ac, err := New("mock-atomic", NewConfig(nil))
+	if err != nil {
+		t.Fatalf("Failed to create autocomplete: %v", err)
+	}
+
+	ctx := context.Background()
+	err = ac.IndexAtomic(ctx, []Entry{
+		{ID: "1", Text: "Mumbai", Display: "Mumbai"},
+		{ID: "2", Text: "Bombay", Display: ""},
+	})
+	if !errors.Is(err, ErrEmptyDisplay) {
+		t.Errorf("IndexAtomic() with invalid entry error = %v, want %v", err, ErrEmptyDisplay)
+	}
+	if provider.indexCalls != 0 {
+		t.Errorf("IndexAtomic() with invalid entry wrote %d entries, want 0", provider.indexCalls)
+	}
+
+	err = ac.IndexAtomic(ctx, []Entry{
+		{ID: "1", Text: "Mumbai", Display: "Mumbai"},
+		{ID: "2", Text: "Bombay", Display: "Mumbai"},
+	})
+	if err != nil {
+		t.Fatalf("IndexAtomic() error = %v", err)
+	}
+	results, err := ac.Query(ctx, "b", 10)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "2" {
+		t.Errorf("Query() after IndexAtomic = %+v, want entry 2", results)
+	}
+}
+
+func TestOpenSnapshotUnsupported(t *testing.T) {
+	RegisterProvider("mock-snapshot", func(config interface{}) (providers.Provider, error) {
+		return newMockProvider(), nil
+	})
+
+	ac, err := New("mock-snapshot", NewConfig(nil))
+	if err != nil {
+		t.Fatalf("Failed to create autocomplete: %v", err)
+	}
+
+	_, err = ac.OpenSnapshot(context.Background())
+	if err != ErrSnapshotsUnsupported {
+		t.Errorf("OpenSnapshot() error = %v, want %v", err, ErrSnapshotsUnsupported)
+	}
+}
+
+func TestDisplayResolver(t *testing.T) {
+	RegisterProvider("mock-resolver", func(config interface{}) (providers.Provider, error) {
+		return newMockProvider(), nil
+	})
+
+	catalog := map[string]string{"1": "Mumbai, Maharashtra", "2": "Mumbai Suburban, Maharashtra"}
+	var resolved [][]string
+	config := NewConfig(nil)
+	config.Options.DisplayCacheSize = 10
+	config.Options.DisplayResolver = func(ctx context.Context, ids []string) map[string]string {
+		resolved = append(resolved, ids)
+		displays := make(map[string]string)
+		for _, id := range ids {
+			if display, ok := catalog[id]; ok {
+				displays[id] = display
+			}
+		}
+		return displays
+	}
+
+	ac, err := New("mock-resolver", config)
+	if err != nil {
+		t.Fatalf("Failed to create autocomplete: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := ac.Index(ctx, "1", "Mumbai", ""); err != nil {
+		t.Fatalf("Index() with empty display error = %v", err)
+	}
+	if err := ac.Index(ctx, "3", "Mumbra", "Mumbra (stored)"); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+
+	for round := 0; round < 2; round++ {
+		results, err := ac.Query(ctx, "mum", 10)
+		if err != nil {
+			t.Fatalf("Query() error = %v", err)
+		}
+		displays := make(map[string]string)
+		for _, r := range results {
+			displays[r.ID] = r.Display
+		}
+		if displays["1"] != "Mumbai, Maharashtra" || displays["3"] != "Mumbra (stored)" {
+			t.Errorf("round %d: Query() displays = %v", round, displays)
+		}
+	}
+
+	if len(resolved) != 2 || len(resolved[1]) != 1 || resolved[1][0] != "3" {
+		t.Errorf("resolver calls = %v, want second call only for the uncached ID 3", resolved)
+	}
+}
+
+func TestTokenCount(t *testing.T) {
+	tests := []struct {
+		strategy MatchStrategy
+		text     string
+		want     int
+	}{
+		{MatchPrefix, "Mumbai", 6},
+		{MatchNGram, "Mumbai", 4},
+		{MatchNGram, "Mu", 0},
+		{MatchNOrMoreGram, "test", 3},
+		{MatchNOrMoreGram, "te", 0},
+		{MatchSubstring, "test", 10},
+		{MatchAnchoredSubstring, "Pune India", 19},
+	}
+
+	for _, tt := range tests {
+		options := DefaultOptions()
+		options.MatchStrategy = tt.strategy
+		if got := tokenCount(tt.text, options); got != tt.want {
+			t.Errorf("tokenCount(%q, strategy %d) = %d, want %d", tt.text, tt.strategy, got, tt.want)
+		}
+	}
+}
+
+func TestOnIndexHook(t *testing.T) {
+	RegisterProvider("mock-hooks", func(config interface{}) (providers.Provider, error) {
+		return newMockProvider(), nil
+	})
+
+	var reported []IndexMetrics
+	config := NewConfig(nil)
+	config.Options.MatchStrategy = MatchPrefix
+	config.Options.Hooks.OnIndex = func(ctx context.Context, metrics IndexMetrics) {
+		reported = append(reported, metrics)
+	}
+
+	ac, err := New("mock-hooks", config)
+	if err != nil {
+		t.Fatalf("Failed to create autocomplete: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := ac.Index(ctx, "1", "Pune", "Pune"); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	ac.IndexBatch(ctx, []Entry{{ID: "2", Text: "Mumbai", Display: "Mumbai"}, {ID: "3", Text: "", Display: "x"}})
+
+	want := []IndexMetrics{
+		{Namespace: "autocomplete", ID: "1", Tokens: 4, NamespaceTokens: 4},
+		{Namespace: "autocomplete", ID: "2", Tokens: 6, NamespaceTokens: 10},
+	}
+	if len(reported) != len(want) {
+		t.Fatalf("OnIndex called %d times, want %d", len(reported), len(want))
+	}
+	for i := range want {
+		if reported[i] != want[i] {
+			t.Errorf("OnIndex call %d = %+v, want %+v", i, reported[i], want[i])
+		}
+	}
+}
+
+func TestTokenBudget(t *testing.T) {
+	RegisterProvider("mock-budget", func(config interface{}) (providers.Provider, error) {
+		return newMockProvider(), nil
+	})
+
+	var events []TruncateEvent
+	config := NewConfig(nil)
+	config.Options.MatchStrategy = MatchSubstring
+	config.Options.MaxTokensPerEntry = 10
+	config.Options.Hooks.OnTruncate = func(ctx context.Context, event TruncateEvent) {
+		events = append(events, event)
+	}
+
+	ac, err := New("mock-budget", config)
+	if err != nil {
+		t.Fatalf("Failed to create autocomplete: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := ac.Index(ctx, "1", "test", "test"); err != nil {
+		t.Fatalf("Index() within budget error = %v", err)
+	}
+	if err := ac.Index(ctx, "2", "testing", "testing"); !errors.Is(err, ErrTokenBudgetExceeded) {
+		t.Fatalf("Index() over budget error = %v, want ErrTokenBudgetExceeded", err)
+	}
+
+	config.Options.TokenBudgetPolicy = TokenBudgetTruncate
+	ac, err = New("mock-budget", config)
+	if err != nil {
+		t.Fatalf("Failed to create autocomplete: %v", err)
+	}
+	if err := ac.Index(ctx, "2", "testing", "testing"); err != nil {
+		t.Fatalf("Index() with truncation error = %v", err)
+	}
+
+	want := TruncateEvent{
+		Namespace:      "autocomplete",
+		ID:             "2",
+		OriginalLength: 7,
+		IndexedLength:  4,
+		OriginalTokens: 28,
+		IndexedTokens:  10,
+	}
+	if len(events) != 1 || events[0] != want {
+		t.Fatalf("OnTruncate events = %+v, want [%+v]", events, want)
+	}
+
+	results, err := ac.Query(ctx, "tes", 10)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "2" {
+		t.Errorf("Query() = %+v, want only the truncated entry", results)
+	}
+}
+
+func TestMaintainUnsupported(t *testing.T) {
+	RegisterProvider("mock-maintain", func(config interface{}) (providers.Provider, error) {
+		return newMockProvider(), nil
+	})
+
+	ac, err := New("mock-maintain", NewConfig(nil))
+	if err != nil {
+		t.Fatalf("Failed to create autocomplete: %v", err)
+	}
+
+	_, err = ac.Maintain(context.Background(), MaintenanceOptions{})
+	if err != ErrMaintenanceUnsupported {
+		t.Errorf("Maintain() error = %v, want %v", err, ErrMaintenanceUnsupported)
+	}
+}
+
+// maintainingProvider records the namespaces it maintains, reporting one
+// batch of 10 scanned and 1 removed item for each.
+type maintainingProvider struct {
+	*mockProvider
+	maintained *[]string
+}
+
+func (p maintainingProvider) Maintain(ctx context.Context, key string, options providers.MaintenanceOptions) (providers.MaintenanceStats, error) {
+	*p.maintained = append(*p.maintained, key)
+	stats := providers.MaintenanceStats{Scanned: 10, Removed: 1}
+	if options.Progress != nil {
+		options.Progress(stats)
+	}
+	return stats, nil
+}
+
+func TestMaintainCopies(t *testing.T) {
+	var maintained []string
+	RegisterProvider("mock-maintain-copies", func(config interface{}) (providers.Provider, error) {
+		return maintainingProvider{newMockProvider(), &maintained}, nil
+	})
+
+	config := NewConfig(nil)
+	config.Options.Namespace = "contacts"
+	config.Options.CaseSensitive = true
+	config.Options.CaseInsensitiveFallback = true
+	config.Options.Segmenter = EmailSegments
+	ac, err := New("mock-maintain-copies", config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	var progress []MaintenanceStats
+	stats, err := ac.Maintain(context.Background(), MaintenanceOptions{
+		Progress: func(stats MaintenanceStats) { progress = append(progress, stats) },
+	})
+	if err != nil {
+		t.Fatalf("Maintain() error = %v", err)
+	}
+	if want := []string{"contacts", "contacts:folded", "contacts:segments"}; !reflect.DeepEqual(maintained, want) {
+		t.Errorf("Maintain() maintained %v, want %v", maintained, want)
+	}
+	if want := (MaintenanceStats{Scanned: 30, Removed: 3}); stats != want {
+		t.Errorf("Maintain() = %+v, want %+v", stats, want)
+	}
+	want := []MaintenanceStats{{Scanned: 10, Removed: 1}, {Scanned: 20, Removed: 2}, {Scanned: 30, Removed: 3}}
+	if !reflect.DeepEqual(progress, want) {
+		t.Errorf("Maintain() progress = %+v, want totals carried forward %+v", progress, want)
+	}
+}
+
+// caseFoldingProvider ignores CaseSensitive, simulating a misconfigured backend.
+type caseFoldingProvider struct {
+	*mockProvider
+}
+
+func (p caseFoldingProvider) Index(ctx context.Context, key, id, text, display string, options providers.IndexOptions) error {
+	options.CaseSensitive = false
+	return p.mockProvider.Index(ctx, key, id, text, display, options)
+}
+
+func (p caseFoldingProvider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	options.CaseSensitive = false
+	return p.mockProvider.Query(ctx, key, query, options)
+}
+
+func TestWithSelfTest(t *testing.T) {
+	mock := newMockProvider()
+	RegisterProvider("mock-selftest", func(config interface{}) (providers.Provider, error) {
+		return mock, nil
+	})
+	RegisterProvider("mock-selftest-casefolding", func(config interface{}) (providers.Provider, error) {
+		return caseFoldingProvider{newMockProvider()}, nil
+	})
+
+	for _, caseSensitive := range []bool{false, true} {
+		config := NewConfig(nil)
+		config.Options.MatchStrategy = MatchPrefix
+		config.Options.CaseSensitive = caseSensitive
+		if _, err := New("mock-selftest", config, WithSelfTest()); err != nil {
+			t.Errorf("New() with CaseSensitive = %t error = %v", caseSensitive, err)
+		}
+	}
+	if len(mock.data) != 0 {
+		t.Errorf("self-test left data behind: %v", mock.data)
+	}
+
+	config := NewConfig(nil)
+	config.Options.MatchStrategy = MatchPrefix
+	config.Options.CaseSensitive = true
+	_, err := New("mock-selftest-casefolding", config, WithSelfTest())
+	if !errors.Is(err, ErrSelfTestFailed) {
+		t.Errorf("New() with case-folding provider error = %v, want ErrSelfTestFailed", err)
+	}
+}
+
+// blindProvider never returns results, like a backend whose writes do not
+// become visible, and fails deletions once ctx is done, like a real backend.
+type blindProvider struct {
+	*mockProvider
+}
+
+func (p blindProvider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	return nil, nil
+}
+
+func (p blindProvider) DeleteAll(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return p.mockProvider.DeleteAll(ctx, key)
+}
+
+func TestSelfTestCleansUpAfterTimeout(t *testing.T) {
+	mock := newMockProvider()
+	RegisterProvider("mock-selftest-blind", func(config interface{}) (providers.Provider, error) {
+		return blindProvider{mock}, nil
+	})
+	config := NewConfig(nil)
+	config.Options.MatchStrategy = MatchPrefix
+	ac, err := New("mock-selftest-blind", config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// A short deadline stands in for selfTestTimeout expiring while waiting
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := ac.(*autocompleteImpl).selfTest(ctx); !errors.Is(err, ErrSelfTestFailed) {
+		t.Fatalf("selfTest() error = %v, want %v", err, ErrSelfTestFailed)
+	}
+	if _, ok := mock.data[config.Options.Namespace+":selftest"]; ok {
+		t.Error("self-test left its namespace behind after the sentinel never appeared")
+	}
+}
+
+// multiStrategyProvider accepts multi-strategy indexing; the mock matches by prefix regardless.
+type multiStrategyProvider struct {
+	*mockProvider
+}
+
+func (p multiStrategyProvider) SupportsMatchStrategies(strategies []providers.MatchStrategy) bool {
+	return true
+}
+
+func TestMultipleStrategies(t *testing.T) {
+	RegisterProvider("mock-single-strategy", func(config interface{}) (providers.Provider, error) {
+		return newMockProvider(), nil
+	})
+	RegisterProvider("mock-multi-strategy", func(config interface{}) (providers.Provider, error) {
+		return multiStrategyProvider{newMockProvider()}, nil
+	})
+
+	config := NewConfig(nil)
+	config.Options.MatchStrategy = MatchPrefix
+	config.Options.MatchStrategies = []MatchStrategy{MatchPrefix, MatchSubstring}
+
+	if _, err := New("mock-single-strategy", config); !errors.Is(err, ErrMultiStrategyUnsupported) {
+		t.Errorf("New() with unsupported provider error = %v, want ErrMultiStrategyUnsupported", err)
+	}
+
+	unlisted := config
+	unlisted.Options.MatchStrategy = MatchNGram
+	if _, err := New("mock-multi-strategy", unlisted); !errors.Is(err, ErrStrategyNotIndexed) {
+		t.Errorf("New() with unlisted MatchStrategy error = %v, want ErrStrategyNotIndexed", err)
+	}
+
+	ac, err := New("mock-multi-strategy", config)
+	if err != nil {
+		t.Fatalf("Failed to create autocomplete: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := ac.Index(ctx, "1", "Mumbai", "Mumbai"); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	results, err := ac.QueryStrategy(ctx, MatchSubstring, "mum", 10)
+	if err != nil || len(results) != 1 {
+		t.Errorf("QueryStrategy(MatchSubstring) = %v, %v, want 1 result", results, err)
+	}
+	if _, err := ac.QueryStrategy(ctx, MatchNGram, "mum", 10); !errors.Is(err, ErrStrategyNotIndexed) {
+		t.Errorf("QueryStrategy(MatchNGram) error = %v, want ErrStrategyNotIndexed", err)
+	}
+
+	if got := tokenCount("test", config.Options); got != 4+10 {
+		t.Errorf("tokenCount() with prefix and substring = %d, want 14", got)
+	}
+}
+
+func TestCaseInsensitiveFallback(t *testing.T) {
+	provider := newMockProvider()
+	RegisterProvider("mock-fallback", func(config interface{}) (providers.Provider, error) {
+		return provider, nil
+	})
+
+	config := NewConfig(nil)
+	config.Options.CaseSensitive = true
+	config.Options.CaseInsensitiveFallback = true
+	config.Options.MatchStrategy = MatchPrefix
+	ac, err := New("mock-fallback", config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer ac.Close()
+
+	ctx := context.Background()
+	if err := ac.Index(ctx, "1", "Hello World", "Hello World"); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if err := ac.Index(ctx, "2", "hello there", "hello there"); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+
+	results, err := ac.Query(ctx, "Hello", 10)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "1" || results[0].Fallback {
+		t.Errorf("exact-case query = %+v, want only entry 1 without fallback", results)
+	}
+
+	results, err = ac.Query(ctx, "HELLO", 10)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("fallback query returned %d results, want 2", len(results))
+	}
+	for _, r := range results {
+		if !r.Fallback {
+			t.Errorf("result %q should be flagged as a fallback match", r.ID)
+		}
+	}
+
+	if err := ac.Delete(ctx, "1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, exists := provider.data["autocomplete:folded"]["1"]; exists {
+		t.Error("Delete() should remove the case-folded copy")
+	}
+
+	// Without the option, the folded copy is neither written nor queried
+	config.Options.CaseInsensitiveFallback = false
+	strict, err := New("mock-fallback", config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	results, err = strict.Query(ctx, "HELLO", 10)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("strict query returned %d results, want 0", len(results))
+	}
+}
+
+type listingProvider struct {
+	*mockProvider
+}
+
+func (p listingProvider) ListEntries(ctx context.Context, key, cursor string, count int) ([]providers.StoredEntry, string, error) {
+	var entries []providers.StoredEntry
+	for id, entry := range p.data[key] {
+		entries = append(entries, providers.StoredEntry{
+			ID: id, Text: entry.text, Display: entry.result.Display, ContentHash: entry.contentHash,
+		})
+	}
+	return entries, "", nil
+}
+
+func TestRenormalize(t *testing.T) {
+	provider := listingProvider{newMockProvider()}
+	RegisterProvider("mock-listing", func(config interface{}) (providers.Provider, error) {
+		return provider, nil
+	})
+
+	ctx := context.Background()
+	config := NewConfig(nil)
+	config.Options.MatchStrategy = MatchPrefix
+	v1, err := New("mock-listing", config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := v1.Index(ctx, "1", "St. Louis", "St. Louis, Missouri"); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if err := v1.Index(ctx, "2", "St. Paul", "St. Paul, Minnesota"); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+
+	config.Options.Normalizer = func(text string) string {
+		return strings.ReplaceAll(strings.ToLower(text), "st.", "saint")
+	}
+	config.Options.NormalizerVersion = 2
+	v2, err := New("mock-listing", config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	results, err := v2.Query(ctx, "Saint", 10)
+	if err != nil || len(results) != 0 {
+		t.Fatalf("Query() before Renormalize = %v, %v; want no results", results, err)
+	}
+
+	var progress []RenormalizeStats
+	stats, err := v2.Renormalize(ctx, RenormalizeOptions{
+		Source: func(ctx context.Context, ids []string) map[string]Entry {
+			// Only entry 1 is known to the source; entry 2 is upgraded from its stored text
+			return map[string]Entry{"1": {Text: "St. Louis", Display: "St. Louis, MO"}}
+		},
+		Progress: func(stats RenormalizeStats) { progress = append(progress, stats) },
+	})
+	if err != nil {
+		t.Fatalf("Renormalize() error = %v", err)
+	}
+	if stats != (RenormalizeStats{Scanned: 2, Upgraded: 2}) || len(progress) != 1 {
+		t.Errorf("Renormalize() = %+v with %d progress calls, want 2 scanned, 2 upgraded, 1 call", stats, len(progress))
+	}
+
+	results, err = v2.Query(ctx, "St. P", 10)
+	if err != nil || len(results) != 1 || results[0].ID != "2" {
+		t.Errorf("normalized Query(%q) = %v, %v; want entry 2", "St. P", results, err)
+	}
+	results, err = v2.Query(ctx, "saint l", 10)
+	if err != nil || len(results) != 1 || results[0].Display != "St. Louis, MO" {
+		t.Errorf("Query(%q) = %v, %v; want entry 1 with display from the source", "saint l", results, err)
+	}
+	if got := normalizerVersion(provider.data["autocomplete"]["1"].contentHash); got != 2 {
+		t.Errorf("recorded normalizer version = %d, want 2", got)
+	}
+
+	stats, err = v2.Renormalize(ctx, RenormalizeOptions{})
+	if err != nil || stats.Upgraded != 0 {
+		t.Errorf("second Renormalize() = %+v, %v; want nothing upgraded", stats, err)
+	}
+
+	if _, err := v1.Renormalize(ctx, RenormalizeOptions{}); err != nil {
+		t.Errorf("Renormalize() back to version 0 error = %v", err)
+	}
+	RegisterProvider("mock-unlisted", func(config interface{}) (providers.Provider, error) {
+		return newMockProvider(), nil
+	})
+	unsupported, err := New("mock-unlisted", NewConfig(nil))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := unsupported.Renormalize(ctx, RenormalizeOptions{}); !errors.Is(err, ErrRenormalizeUnsupported) {
+		t.Errorf("Renormalize() error = %v, want ErrRenormalizeUnsupported", err)
+	}
+}
+
+func TestAbbreviationExpander(t *testing.T) {
+	abbreviations := IndianAddressAbbreviations()
+	abbreviations["clny"] = "Colony"
+	expander := NewAbbreviationExpander(abbreviations)
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"MG Rd", "MG Road"},
+		{"Opp. City Mall, Nr Stn Rd.", "Opposite City Mall, Near Station Road"},
+		{"Opp.Bus Stand", "Opposite Bus Stand"},
+		{"Shivaji NGR", "Shivaji Nagar"},
+		{"Model Clny", "Model Colony"},
+		{"Rdx Stadium", "Rdx Stadium"},
+		{"sta", "sta"},
+		{"Koramangala 5th Blk", "Koramangala 5th Block"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := expander.Expand(tt.text); got != tt.want {
+			t.Errorf("Expand(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+
+	RegisterProvider("mock-abbreviations", func(config interface{}) (providers.Provider, error) {
+		return newMockProvider(), nil
+	})
+	config := NewConfig(nil)
+	config.Options.MatchStrategy = MatchPrefix
+	config.Options.Normalizer = expander.Expand
+	ac, err := New("mock-abbreviations", config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx := context.Background()
+	if err := ac.Index(ctx, "1", "Nr. Central Rly Stn", "Near Central Railway Station"); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	for _, query := range []string{"near central", "nr central rly stn"} {
+		results, err := ac.Query(ctx, query, 10)
+		if err != nil || len(results) != 1 {
+			t.Errorf("Query(%q) = %v, %v; want entry 1", query, results, err)
+		}
+	}
+}
+
+func TestIdentifierPreset(t *testing.T) {
+	tests := []struct {
+		identifier string
+		want       string
+	}{
+		{"parseHTTPRequest", "parse HTTP Request"},
+		{"ParseHTTP", "Parse HTTP"},
+		{"MAX_RETRY_COUNT", "MAX RETRY COUNT"},
+		{"get-user-name", "get user name"},
+		{"__init__", "init"},
+		{"utf8Decode", "utf8 Decode"},
+		{"os.ReadFile", "os Read File"},
+		{"parse HTTP Request", "parse HTTP Request"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeIdentifier(tt.identifier); got != tt.want {
+			t.Errorf("NormalizeIdentifier(%q) = %q, want %q", tt.identifier, got, tt.want)
+		}
+	}
+
+	RegisterProvider("mock-identifiers", func(config interface{}) (providers.Provider, error) {
+		return newMockProvider(), nil
+	})
+	ac, err := New("mock-identifiers", NewConfigWithOptions(nil, IdentifierOptions()))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx := context.Background()
+	for id, identifier := range map[string]string{"1": "getUserName", "2": "get_user_id", "3": "GetUsers"} {
+		if err := ac.Index(ctx, id, identifier, identifier); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	queries := []struct {
+		query    string
+		want     []string
+		fallback bool
+	}{
+		{"getU", []string{"1"}, false},
+		{"get_user", []string{"2"}, false},
+		{"GetU", []string{"3"}, false},
+		{"get_user_n", []string{"1"}, true},
+		{"getuser", nil, false},
+	}
+	for _, q := range queries {
+		results, err := ac.Query(ctx, q.query, 10)
+		if err != nil {
+			t.Fatalf("Query(%q) error = %v", q.query, err)
+		}
+		var ids []string
+		for _, r := range results {
+			ids = append(ids, r.ID)
+			if r.Fallback != q.fallback {
+				t.Errorf("Query(%q) result %s Fallback = %t, want %t", q.query, r.ID, r.Fallback, q.fallback)
+			}
+		}
+		if strings.Join(ids, ",") != strings.Join(q.want, ",") {
+			t.Errorf("Query(%q) = %v, want %v", q.query, ids, q.want)
+		}
+	}
+}
+
+func TestEmailPreset(t *testing.T) {
+	if got := fmt.Sprint(EmailSegments("priya.sharma@mail.example.com")); got != "[6 13 18 26]" {
+		t.Errorf("EmailSegments() = %s, want [6 13 18 26]", got)
+	}
+	if got := fmt.Sprint(EmailSegments("a..b@")); got != "[3]" {
+		t.Errorf("EmailSegments() = %s, want [3]", got)
+	}
+
+	provider := newMockProvider()
+	RegisterProvider("mock-emails", func(config interface{}) (providers.Provider, error) {
+		return provider, nil
+	})
+	ac, err := New("mock-emails", NewConfigWithOptions(nil, EmailOptions()))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx := context.Background()
+	for id, email := range map[string]string{"1": "priya.sharma@example.com", "2": "sharma.r@corp.in", "3": "ravi@sharmaco.in"} {
+		if err := ac.Index(ctx, id, email, email); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"sharma.", []string{"2"}},
+		{"sharma@", []string{"1"}},
+		{"Exam", []string{"1"}},
+		{"corp.i", []string{"2"}},
+		{"riya", nil},
+	}
+	for _, tt := range tests {
+		results, err := ac.Query(ctx, tt.query, 10)
+		if err != nil {
+			t.Fatalf("Query(%q) error = %v", tt.query, err)
+		}
+		var ids []string
+		for _, r := range results {
+			ids = append(ids, r.ID)
+		}
+		if fmt.Sprint(ids) != fmt.Sprint(tt.want) {
+			t.Errorf("Query(%q) = %v, want %v", tt.query, ids, tt.want)
+		}
+	}
+
+	if err := ac.Index(ctx, "1", "priya@example.com", "priya@example.com"); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if n := len(provider.data["autocomplete:segments"]); n != 7 {
+		t.Errorf("segment copies after update = %d, want 7", n)
+	}
+	if err := ac.Delete(ctx, "1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if results, _ := ac.Query(ctx, "sharma", 10); len(results) != 2 || results[0].ID != "2" {
+		t.Errorf("Query() = %+v, want entry 2 before entry 3", results)
+	}
+	if results, _ := ac.Query(ctx, "exa", 10); len(results) != 0 {
+		t.Errorf("Query() after Delete = %+v, want none", results)
+	}
+	if err := ac.DeleteAll(ctx); err != nil {
+		t.Fatalf("DeleteAll() error = %v", err)
+	}
+	if _, exists := provider.data["autocomplete:segments"]; exists {
+		t.Error("DeleteAll() should remove the segment copies")
+	}
+}
+
+func TestDuplicatePolicy(t *testing.T) {
+	RegisterProvider("mock-duplicates", func(config interface{}) (providers.Provider, error) {
+		return newMockProvider(), nil
+	})
+	fields := func(text string) []int {
+		var offsets []int
+		for i := range text {
+			if text[i] == '|' {
+				offsets = append(offsets, i+1)
+			}
+		}
+		return offsets
+	}
+
+	tests := []struct {
+		policy DuplicatePolicy
+		want   map[string]float64
+		order  []string
+	}{
+		{DuplicateFirst, map[string]float64{"1": 2, "2": 3, "3": 2.5}, nil},
+		{DuplicateMax, map[string]float64{"1": 2, "2": 3, "3": 2.5}, []string{"2", "3", "1"}},
+		{DuplicateSum, map[string]float64{"1": 4, "2": 3, "3": 2.5}, []string{"1", "2", "3"}},
+	}
+	for _, tt := range tests {
+		options := DefaultOptions()
+		options.Segmenter = fields
+		options.DuplicatePolicy = tt.policy
+		ac, err := New("mock-duplicates", NewConfigWithOptions(nil, options))
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		ctx := context.Background()
+		for _, entry := range []Entry{
+			{ID: "1", Text: "ravi|ravi.k@example.com", Score: 2},
+			{ID: "2", Text: "ravindra|rk@example.com", Score: 3},
+			{ID: "3", Text: "kumar|ravi", Score: 2.5},
+		} {
+			if err := ac.IndexWithScore(ctx, entry.ID, entry.Text, entry.Text, entry.Score); err != nil {
+				t.Fatalf("IndexWithScore() error = %v", err)
+			}
+		}
+
+		results, err := ac.Query(ctx, "ravi", 10)
+		if err != nil {
+			t.Fatalf("Query() error = %v", err)
+		}
+		got := make(map[string]float64)
+		var order []string
+		for _, result := range results {
+			got[result.ID] = result.Score
+			order = append(order, result.ID)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("policy %d: Query() scores = %v, want %v", tt.policy, got, tt.want)
+		}
+		if tt.order != nil && !reflect.DeepEqual(order, tt.order) {
+			t.Errorf("policy %d: Query() order = %v, want %v", tt.policy, order, tt.order)
+		}
+		if tt.policy == DuplicateFirst && (len(order) != 3 || order[2] != "3") {
+			t.Errorf("policy %d: Query() order = %v, want entry 3 after the entries matched from the start", tt.policy, order)
+		}
+	}
+}
+
+func TestPhonePreset(t *testing.T) {
+	if got := NormalizePhone("+91 (987) 65-43.210"); got != "919876543210" {
+		t.Errorf("NormalizePhone() = %q, want 919876543210", got)
+	}
+
+	RegisterProvider("mock-phones", func(config interface{}) (providers.Provider, error) {
+		return newMockProvider(), nil
+	})
+	ac, err := New("mock-phones", NewConfigWithOptions(nil, PhoneOptions(4)))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx := context.Background()
+	for id, phone := range map[string]string{"1": "+91 98765 43210", "2": "+91 91234-03210", "3": "022 2345 6789"} {
+		if err := ac.Index(ctx, id, phone, phone); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"+91 98765", 1},
+		{"91-9", 2},
+		{"3210", 2},
+		{"678", 1},
+		{"4321", 0},
+	}
+	for _, tt := range tests {
+		results, err := ac.Query(ctx, tt.query, 10)
+		if err != nil {
+			t.Fatalf("Query(%q) error = %v", tt.query, err)
+		}
+		if len(results) != tt.want {
+			t.Errorf("Query(%q) returned %d results, want %d", tt.query, len(results), tt.want)
+		}
+	}
+	if _, err := ac.Query(ctx, "ravi", 10); !errors.Is(err, ErrQueryTooShort) {
+		t.Errorf("Query() without digits error = %v, want ErrQueryTooShort", err)
+	}
+}
+
+func TestPathPreset(t *testing.T) {
+	if got, want := PathSegments("/usr//local/bin/"), []int{1, 6, 12}; !reflect.DeepEqual(got, want) {
+		t.Errorf("PathSegments() = %v, want %v", got, want)
+	}
+
+	RegisterProvider("mock-paths", func(config interface{}) (providers.Provider, error) {
+		return newMockProvider(), nil
+	})
+	ac, err := New("mock-paths", NewConfigWithOptions(nil, PathOptions()))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx := context.Background()
+	paths := map[string]string{"1": "cmd/server/main.go", "2": "docs", "3": "docs/api/README.md", "4": "internal/docs/index.md"}
+	for id, path := range paths {
+		if err := ac.Index(ctx, id, path, path); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  map[string]float64
+	}{
+		{"main", map[string]float64{"1": 1.0 / 3}},
+		{"cmd/ser", map[string]float64{"1": 1.0 / 3}},
+		{"docs", map[string]float64{"2": 1, "3": 1.0 / 3, "4": 1.0 / 3}},
+		{"api/read", map[string]float64{"3": 1.0 / 3}},
+		{"go", map[string]float64{}},
+	}
+	for _, tt := range tests {
+		results, err := ac.Query(ctx, tt.query, 10)
+		if err != nil {
+			t.Fatalf("Query(%q) error = %v", tt.query, err)
+		}
+		got := make(map[string]float64, len(results))
+		for _, result := range results {
+			got[result.ID] = result.Score
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Query(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+// rankingProvider ranks matches by score, breaking ties by descending ID, and
+// pages through them like a snapshotting provider.
+type rankingProvider struct {
+	*mockProvider
+}
+
+func (p rankingProvider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	limit := options.MaxResults
+	options.MaxResults = len(p.data[key])
+	results, err := p.mockProvider.Query(ctx, key, query, options)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ID > results[j].ID
+	})
+	results = results[min(options.Offset, len(results)):]
+	return results[:min(limit, len(results))], nil
+}
+
+func (p rankingProvider) OpenSnapshot(ctx context.Context, key string, keepAlive time.Duration) (string, error) {
+	return "snapshot", nil
+}
+
+func (p rankingProvider) CloseSnapshot(ctx context.Context, snapshotID string) error {
+	return nil
+}
+
+func TestResultLess(t *testing.T) {
+	RegisterProvider("mock-ranking", func(config interface{}) (providers.Provider, error) {
+		return rankingProvider{newMockProvider()}, nil
+	})
+
+	config := NewConfig(nil)
+	config.Options.MatchStrategy = MatchPrefix
+	config.Options.Scorer = func(text string) float64 {
+		if strings.HasSuffix(text, "!") {
+			return 2
+		}
+		return 1
+	}
+	config.Options.ResultLess = func(a, b Result) bool { return a.Display < b.Display }
+	ac, err := New("mock-ranking", config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx := context.Background()
+	for id, text := range map[string]string{"1": "tea d", "2": "tea b", "3": "tea e", "4": "tea a", "5": "tea z!", "6": "tea c"} {
+		if err := ac.Index(ctx, id, text, text); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	displays := func(results []Result) []string {
+		var got []string
+		for _, result := range results {
+			got = append(got, result.Display)
+		}
+		return got
+	}
+
+	results, err := ac.Query(ctx, "tea", 3)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if got, want := displays(results), []string{"tea z!", "tea a", "tea b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Query() = %v, want %v", got, want)
+	}
+
+	snapshot, err := ac.OpenSnapshot(ctx)
+	if err != nil {
+		t.Fatalf("OpenSnapshot() error = %v", err)
+	}
+	var pages []string
+	for offset := 0; offset < 8; offset += 2 {
+		results, err := snapshot.Query(ctx, "tea", 2, offset)
+		if err != nil {
+			t.Fatalf("Snapshot.Query() error = %v", err)
+		}
+		pages = append(pages, displays(results)...)
+	}
+	if want := []string{"tea z!", "tea a", "tea b", "tea c", "tea d", "tea e"}; !reflect.DeepEqual(pages, want) {
+		t.Errorf("Snapshot.Query() pages = %v, want %v", pages, want)
+	}
+}
+
+func TestLimitPolicy(t *testing.T) {
+	RegisterProvider("mock-limit", func(config interface{}) (providers.Provider, error) {
+		return newMockProvider(), nil
+	})
+
+	ctx := context.Background()
+	for _, policy := range []LimitPolicy{LimitError, LimitClampToMax} {
+		config := NewConfig(nil)
+		config.Options.MaxLimit = 2
+		config.Options.LimitPolicy = policy
+		ac, err := New("mock-limit", config)
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		for _, id := range []string{"1", "2", "3"} {
+			if err := ac.Index(ctx, id, "chai "+id, "Chai "+id); err != nil {
+				t.Fatalf("Index() error = %v", err)
+			}
+		}
+
+		results, err := ac.Query(ctx, "chai", 100)
+		switch policy {
+		case LimitError:
+			if err != ErrLimitExceeded {
+				t.Errorf("LimitError: Query() error = %v, want %v", err, ErrLimitExceeded)
+			}
+		case LimitClampToMax:
+			if err != nil || len(results) != 2 {
+				t.Errorf("LimitClampToMax: Query() = %d results, %v; want 2 results", len(results), err)
+			}
+		}
+	}
+}
+
+func TestMinPrefixLengthUnit(t *testing.T) {
+	RegisterProvider("mock-length", func(config interface{}) (providers.Provider, error) {
+		return newMockProvider(), nil
+	})
+
+	tests := []struct {
+		unit    LengthUnit
+		query   string
+		wantErr bool
+	}{
+		{LengthRunes, "mu", false},
+		{LengthRunes, "म", true},   // one rune, three bytes
+		{LengthRunes, "मु", false}, // two runes
+		{LengthGraphemes, "मु", true},
+		{LengthGraphemes, "मुं", true},
+		{LengthGraphemes, "मुंब", false},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		config := NewConfig(nil)
+		config.Options.MinPrefixLength = 2
+		config.Options.MinPrefixLengthUnit = tt.unit
+		ac, err := New("mock-length", config)
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		_, err = ac.Query(ctx, tt.query, 10)
+		if gotErr := errors.Is(err, ErrQueryTooShort); gotErr != tt.wantErr {
+			t.Errorf("unit %d: Query(%q) error = %v, want ErrQueryTooShort: %t", tt.unit, tt.query, err, tt.wantErr)
+		}
+	}
+}
+
+// failingProvider fails every query while down is set, as during a failover.
+type failingProvider struct {
+	*mockProvider
+	down bool
+}
+
+func (f *failingProvider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	if f.down {
+		return nil, errors.New("connection refused")
+	}
+	return f.mockProvider.Query(ctx, key, query, options)
+}
+
+func TestStaleResultsOnProviderError(t *testing.T) {
+	provider := &failingProvider{mockProvider: newMockProvider()}
+	RegisterProvider("mock-stale", func(config interface{}) (providers.Provider, error) {
+		return provider, nil
+	})
+
+	var events []StaleEvent
+	config := NewConfig(nil)
+	config.Options.StaleCacheSize = 10
+	config.Options.Hooks.OnStale = func(ctx context.Context, event StaleEvent) {
+		events = append(events, event)
+	}
+	ac, err := New("mock-stale", config)
+	if err != nil {
+		t.Fatalf("Failed to create autocomplete: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := ac.Index(ctx, "1", "Mumbai", "Mumbai"); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	fresh, err := ac.Query(ctx, "mum", 10)
+	if err != nil || len(fresh) != 1 || fresh[0].Stale {
+		t.Fatalf("Query() = %v, %v; want one fresh result", fresh, err)
+	}
+	fresh[0].Display = "modified by caller"
+
+	provider.down = true
+	results, err := ac.Query(ctx, "mum", 10)
+	if err != nil {
+		t.Fatalf("Query() during outage error = %v, want stale results", err)
+	}
+	if len(results) != 1 || !results[0].Stale || results[0].Display != "Mumbai" {
+		t.Errorf("Query() during outage = %+v, want the kept result flagged stale", results)
+	}
+	if len(events) != 1 || events[0].Query != "mum" || events[0].Err == nil {
+		t.Errorf("OnStale events = %+v, want one for the query", events)
+	}
+
+	// Queries never answered before, or with another limit, still fail
+	if _, err := ac.Query(ctx, "pun", 10); err == nil {
+		t.Error("Query() of an unseen query during outage should fail")
+	}
+	if _, err := ac.Query(ctx, "mum", 5); err == nil {
+		t.Error("Query() with another limit during outage should fail")
+	}
+
+	// A cancelled query fails rather than being answered stale
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := ac.Query(cancelled, "mum", 10); err == nil {
+		t.Error("Query() with a cancelled context should fail")
+	}
+
+	provider.down = false
+	if err := ac.DeleteAll(ctx); err != nil {
+		t.Fatalf("DeleteAll() error = %v", err)
+	}
+	provider.down = true
+	if _, err := ac.Query(ctx, "mum", 10); err == nil {
+		t.Error("Query() after DeleteAll should not serve results kept before it")
+	}
+}
+
+// manualClock is a Clock advanced by hand.
+type manualClock struct {
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	return c.now
+}
+
+func TestClock(t *testing.T) {
+	provider := &failingProvider{mockProvider: newMockProvider()}
+	RegisterProvider("mock-clock", func(config interface{}) (providers.Provider, error) {
+		return provider, nil
+	})
+
+	clock := &manualClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	var events []StaleEvent
+	var mutations []Mutation
+	config := NewConfig(nil)
+	config.Options.Clock = clock
+	config.Options.StaleCacheSize = 10
+	config.Options.StaleCacheTTL = time.Hour
+	config.Options.Hooks.OnStale = func(ctx context.Context, event StaleEvent) {
+		events = append(events, event)
+	}
+	config.Options.Hooks.OnMutation = func(ctx context.Context, mutation Mutation) {
+		mutations = append(mutations, mutation)
+	}
+	ac, err := New("mock-clock", config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx := context.Background()
+	if err := ac.Index(ctx, "1", "Mumbai", "Mumbai"); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if len(mutations) != 1 || !mutations[0].Time.Equal(clock.now) {
+		t.Errorf("OnMutation mutations = %+v, want one stamped %v", mutations, clock.now)
+	}
+	if _, err := ac.Query(ctx, "mum", 10); err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+
+	provider.down = true
+	clock.now = clock.now.Add(59 * time.Minute)
+	if _, err := ac.Query(ctx, "mum", 10); err != nil {
+		t.Fatalf("Query() within StaleCacheTTL error = %v, want stale results", err)
+	}
+	if len(events) != 1 || events[0].Age != 59*time.Minute {
+		t.Errorf("OnStale events = %+v, want one aged 59m", events)
+	}
+
+	clock.now = clock.now.Add(2 * time.Minute)
+	if _, err := ac.Query(ctx, "mum", 10); err == nil {
+		t.Error("Query() after StaleCacheTTL on the clock should fail")
+	}
+}
+
+func TestWarmStart(t *testing.T) {
+	provider := listingProvider{newMockProvider()}
+	RegisterProvider("mock-warm", func(config interface{}) (providers.Provider, error) {
+		return provider, nil
+	})
+	ctx := context.Background()
+	config := NewConfig(nil)
+	config.Options.MatchStrategy = MatchPrefix
+
+	snapshot := `{"id":"1","text":"Mumbai","display":"Mumbai, Maharashtra"}
+{"id":"2","text":"Pune","display":"Pune, Maharashtra"}
+`
+	ac, err := New("mock-warm", config, WithWarmStart(strings.NewReader(snapshot)))
+	if err != nil {
+		t.Fatalf("New() with warm start error = %v", err)
+	}
+	results, err := ac.Query(ctx, "mum", 10)
+	if err != nil || len(results) != 1 || results[0].Display != "Mumbai, Maharashtra" {
+		t.Fatalf("Query() after warm start = %v, %v; want the snapshot entry", results, err)
+	}
+
+	// A namespace with entries is not populated again
+	if err := ac.Index(ctx, "1", "Mumbai", "Mumbai (updated)"); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if _, err := New("mock-warm", config, WithWarmStart(strings.NewReader(snapshot))); err != nil {
+		t.Fatalf("New() with warm start of a populated namespace error = %v", err)
+	}
+	if results, _ := ac.Query(ctx, "mum", 10); len(results) != 1 || results[0].Display != "Mumbai (updated)" {
+		t.Errorf("warm start overwrote an existing namespace: %v", results)
+	}
+
+	// Malformed snapshots and providers that cannot list entries fail New
+	if err := ac.DeleteAll(ctx); err != nil {
+		t.Fatalf("DeleteAll() error = %v", err)
+	}
+	if _, err := New("mock-warm", config, WithWarmStart(strings.NewReader(`{"id":"1",`))); err == nil {
+		t.Error("New() with a malformed snapshot should fail")
+	}
+	if _, err := New("mock-warm", config, WithWarmStart(strings.NewReader(`{"id":"","text":"x","display":"x"}`))); !errors.Is(err, ErrEmptyID) {
+		t.Errorf("New() with an invalid entry error = %v, want %v", err, ErrEmptyID)
+	}
+	RegisterProvider("mock-warm-unlisted", func(config interface{}) (providers.Provider, error) {
+		return newMockProvider(), nil
+	})
+	if _, err := New("mock-warm-unlisted", config, WithWarmStart(strings.NewReader(snapshot))); !errors.Is(err, ErrWarmStartUnsupported) {
+		t.Errorf("New() error = %v, want %v", err, ErrWarmStartUnsupported)
+	}
+}
+
+type warmMetadataProvider struct {
+	listingProvider
+}
+
+func (p warmMetadataProvider) SupportsMetadata() bool {
+	return true
+}
+
+func TestWarmStartMetadataAndScore(t *testing.T) {
+	RegisterProvider("mock-warm-metadata", func(config interface{}) (providers.Provider, error) {
+		return warmMetadataProvider{listingProvider{newMockProvider()}}, nil
+	})
+	config := NewConfig(nil)
+	config.Options.MatchStrategy = MatchPrefix
+
+	snapshot := `{"id":"1","text":"Mumbai","display":"Mumbai","metadata":{"state":"MH"}}
+{"id":"2","text":"Mumbra","display":"Mumbra","score":5}
+`
+	ac, err := New("mock-warm-metadata", config, WithWarmStart(strings.NewReader(snapshot)))
+	if err != nil {
+		t.Fatalf("New() with warm start error = %v", err)
+	}
+	results, err := ac.Query(context.Background(), "mum", 10)
+	if err != nil || len(results) != 2 {
+		t.Fatalf("Query() after warm start = %v, %v; want the snapshot entries", results, err)
+	}
+	byID := map[string]Result{}
+	for _, result := range results {
+		byID[result.ID] = result
+	}
+	if got := string(byID["1"].Metadata); got != `{"state":"MH"}` {
+		t.Errorf("entry 1 Metadata = %s, want the snapshot's", got)
+	}
+	if got := byID["2"].Score; got != 5 {
+		t.Errorf("entry 2 Score = %v, want the snapshot's 5", got)
+	}
+}
+
+func TestScorerInvalidScore(t *testing.T) {
+	RegisterProvider("mock-scorer-invalid", func(config interface{}) (providers.Provider, error) {
+		return newMockProvider(), nil
+	})
+
+	config := NewConfig(nil)
+	config.Options.MatchStrategy = MatchPrefix
+	config.Options.Scorer = func(text string) float64 {
+		switch text {
+		case "nan":
+			return math.NaN()
+		case "inf":
+			return math.Inf(1)
+		}
+		return 1
+	}
+	ac, err := New("mock-scorer-invalid", config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx := context.Background()
+	for _, text := range []string{"nan", "inf"} {
+		if err := ac.Index(ctx, text, text, text); !errors.Is(err, ErrInvalidScore) {
+			t.Errorf("Index(%q) error = %v, want %v", text, err, ErrInvalidScore)
+		}
+	}
+	if err := ac.IndexWithScore(ctx, "nan", "nan", "nan", 3); err != nil {
+		t.Errorf("IndexWithScore() error = %v; an explicit score overrides the Scorer", err)
+	}
+
+	err = ac.BatchIndex(ctx, []Entry{
+		{ID: "1", Text: "pune", Display: "Pune"},
+		{ID: "2", Text: "inf", Display: "Inf"},
+	})
+	if !errors.Is(err, ErrInvalidScore) {
+		t.Errorf("BatchIndex() error = %v, want %v", err, ErrInvalidScore)
+	}
+	if results, err := ac.Query(ctx, "pune", 10); err != nil || len(results) != 0 {
+		t.Errorf("Query() = %v, %v; a batch with an invalid entry should write nothing", results, err)
+	}
+}
+
+func TestUpdateOptions(t *testing.T) {
+	RegisterProvider("mock-update", func(config interface{}) (providers.Provider, error) {
+		return newMockProvider(), nil
+	})
+
+	config := NewConfig(nil)
+	config.Options.MatchStrategy = MatchPrefix
+	config.Options.Scorer = func(text string) float64 { return float64(len(text)) }
+	ac, err := New("mock-update", config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx := context.Background()
+	for id, text := range map[string]string{"1": "chai", "2": "chai latte", "3": "chai masala"} {
+		if err := ac.Index(ctx, id, text, text); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	zero, two, five, minScore := 0, 2, 5, 5.0
+	if err := ac.UpdateOptions(ctx, OptionsPatch{DefaultLimit: &two, MinPrefixLength: &five}); err != nil {
+		t.Fatalf("UpdateOptions() error = %v", err)
+	}
+	if _, err := ac.Query(ctx, "chai", 0); err != ErrQueryTooShort {
+		t.Errorf("Query() error = %v, want %v after raising MinPrefixLength", err, ErrQueryTooShort)
+	}
+	if results, err := ac.Query(ctx, "chai ", 0); err != nil || len(results) != 2 {
+		t.Errorf("Query() = %d results, %v; want DefaultLimit 2", len(results), err)
+	}
+
+	if err := ac.UpdateOptions(ctx, OptionsPatch{MinScore: &minScore, MinPrefixLength: &zero}); err != nil {
+		t.Fatalf("UpdateOptions() error = %v", err)
+	}
+	results, err := ac.Query(ctx, "chai", 10)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	for _, result := range results {
+		if result.Score < minScore {
+			t.Errorf("Query() returned %q scoring %v, below MinScore %v", result.Display, result.Score, minScore)
+		}
+	}
+	if len(results) != 2 {
+		t.Errorf("Query() = %d results, want the 2 scoring at least MinScore", len(results))
+	}
+
+	// An inconsistent patch is rejected as a whole
+	overMax, one := 101, 1
+	err = ac.UpdateOptions(ctx, OptionsPatch{DefaultLimit: &overMax, MinPrefixLength: &one})
+	if !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("UpdateOptions() error = %v, want %v", err, ErrInvalidOptions)
+	}
+	if _, err := ac.Query(ctx, "c", 0); err != nil {
+		t.Errorf("Query() error = %v; a rejected patch should change nothing", err)
+	}
+
+	// Updates are safe while queries run
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 1; i <= 100; i++ {
+			limit := i%10 + 1
+			_ = ac.UpdateOptions(ctx, OptionsPatch{DefaultLimit: &limit})
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		if _, err := ac.Query(ctx, "chai", 0); err != nil {
+			t.Fatalf("Query() during updates error = %v", err)
+		}
+	}
+	<-done
+}
+
+// changesProvider records the arguments of ListChanges and returns a fixed change set.
+type changesProvider struct {
+	*mockProvider
+	key, cursor string
+}
+
+func (p *changesProvider) ListChanges(ctx context.Context, key, cursor string) (providers.ChangeSet, error) {
+	p.key, p.cursor = key, cursor
+	return providers.ChangeSet{
+		Entries: []providers.StoredEntry{{ID: "1", Text: "mumbai", Display: "Mumbai", ContentHash: "h1"}},
+		Deleted: []string{"2"},
+		Cursor:  "next",
+	}, nil
+}
+
+func TestExportSince(t *testing.T) {
+	provider := &changesProvider{mockProvider: newMockProvider()}
+	RegisterProvider("mock-changes", func(config interface{}) (providers.Provider, error) {
+		return provider, nil
+	})
+	RegisterProvider("mock-no-changes", func(config interface{}) (providers.Provider, error) {
+		return newMockProvider(), nil
+	})
+
+	config := NewConfig(nil)
+	config.Options.Namespace = "cities"
+	ac, err := New("mock-changes", config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	delta, err := ac.ExportSince(context.Background(), "checkpoint")
+	if err != nil {
+		t.Fatalf("ExportSince() error = %v", err)
+	}
+	want := Delta{Entries: []Entry{{ID: "1", Text: "mumbai", Display: "Mumbai"}}, Deleted: []string{"2"}, Cursor: "next"}
+	if !reflect.DeepEqual(delta, want) {
+		t.Errorf("ExportSince() = %+v, want %+v", delta, want)
+	}
+	if provider.key != "cities" || provider.cursor != "checkpoint" {
+		t.Errorf("ListChanges() called with %q, %q; want the namespace and cursor", provider.key, provider.cursor)
+	}
+
+	unsupported, err := New("mock-no-changes", NewConfig(nil))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := unsupported.ExportSince(context.Background(), ""); !errors.Is(err, ErrExportUnsupported) {
+		t.Errorf("ExportSince() error = %v, want %v", err, ErrExportUnsupported)
+	}
+}
+
+type aliasingProvider struct {
+	*mockProvider
+	calls []string
+}
+
+func (p *aliasingProvider) AliasNamespace(ctx context.Context, alias, target string) error {
+	p.calls = append(p.calls, "alias "+alias+" "+target)
+	return nil
+}
+
+func (p *aliasingProvider) RenameNamespace(ctx context.Context, from, to string) error {
+	p.calls = append(p.calls, "rename "+from+" "+to)
+	return nil
+}
+
+func TestAliasAndRenameNamespace(t *testing.T) {
+	provider := &aliasingProvider{mockProvider: newMockProvider()}
+	RegisterProvider("mock-aliases", func(config interface{}) (providers.Provider, error) {
+		return provider, nil
+	})
+
+	config := NewConfig(nil)
+	config.Options.CaseSensitive = true
+	config.Options.CaseInsensitiveFallback = true
+	ac, err := New("mock-aliases", config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+	if err := ac.AliasNamespace(ctx, "products", "products_v2"); err != nil {
+		t.Fatalf("AliasNamespace() error = %v", err)
+	}
+	if err := ac.AliasNamespace(ctx, "products", ""); err != nil {
+		t.Fatalf("AliasNamespace() error = %v", err)
+	}
+	if err := ac.RenameNamespace(ctx, "products_v2", "products"); err != nil {
+		t.Fatalf("RenameNamespace() error = %v", err)
+	}
+
+	// The case-folded copies are re-pointed before the namespaces themselves
+	want := []string{
+		"alias products:folded products_v2:folded",
+		"alias products products_v2",
+		"alias products:folded ",
+		"alias products ",
+		"rename products_v2:folded products:folded",
+		"rename products_v2 products",
+	}
+	if !reflect.DeepEqual(provider.calls, want) {
+		t.Errorf("provider calls = %q, want %q", provider.calls, want)
+	}
+
+	unsupported, err := New("mock", NewConfig(nil))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := unsupported.AliasNamespace(ctx, "products", "products_v2"); !errors.Is(err, ErrAliasesUnsupported) {
+		t.Errorf("AliasNamespace() error = %v, want %v", err, ErrAliasesUnsupported)
+	}
+	if err := unsupported.RenameNamespace(ctx, "products_v2", "products"); !errors.Is(err, ErrAliasesUnsupported) {
+		t.Errorf("RenameNamespace() error = %v, want %v", err, ErrAliasesUnsupported)
+	}
+}
+
+func TestRegisterMapProvider(t *testing.T) {
+	var got map[string]any
+	RegisterMapProvider("Mock-Map", func(config map[string]any) (providers.Provider, error) {
+		got = config
+		return newMockProvider(), nil
+	})
+
+	if _, err := New("mock-map", NewConfig(map[string]any{"endpoint": "http://search.internal"})); err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if got["endpoint"] != "http://search.internal" {
+		t.Errorf("factory config = %v, want the configuration map", got)
+	}
+	if _, err := New("MOCK-MAP", NewConfig(nil)); err != nil || got == nil {
+		t.Errorf("New() without config = %v, %v; want an empty map", got, err)
+	}
+	if _, err := New("mock-map", NewConfig(struct{}{})); err == nil {
+		t.Error("New() should reject a configuration that is not a map")
+	}
+}
+
+func TestLoadPluginFailsForMissingFile(t *testing.T) {
+	if err := LoadPlugin(t.TempDir() + "/missing.so"); err == nil {
+		t.Error("LoadPlugin() should fail for a missing file")
+	}
+}
+
+type deadlineProvider struct {
+	*mockProvider
+}
+
+func (p deadlineProvider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return p.mockProvider.Query(ctx, key, query, options)
+}
+
+func TestQueryEx(t *testing.T) {
+	RegisterProvider("mock-deadline", func(config interface{}) (providers.Provider, error) {
+		return deadlineProvider{mockProvider: newMockProvider()}, nil
+	})
+	ac, err := New("mock-deadline", NewConfig(nil))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+	for _, id := range []string{"1", "2", "3"} {
+		if err := ac.Index(ctx, id, "Mumbai "+id, "Mumbai "+id); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	response, err := ac.QueryEx(ctx, "mum", 2)
+	if err != nil {
+		t.Fatalf("QueryEx() error = %v", err)
+	}
+	if len(response.Results) != 2 || !response.Truncated || response.Total != -1 {
+		t.Errorf("QueryEx() with a smaller limit = %+v, want 2 truncated results of an unknown total", response)
+	}
+
+	response, err = ac.QueryEx(ctx, "mum", 3)
+	if err != nil {
+		t.Fatalf("QueryEx() error = %v", err)
+	}
+	if len(response.Results) != 3 || response.Truncated || response.Total != 3 {
+		t.Errorf("QueryEx() with a limit fitting every match = %+v, want all 3 results", response)
+	}
+
+	expired, cancel := context.WithDeadline(ctx, time.Now().Add(-time.Second))
+	defer cancel()
+	response, err = ac.QueryEx(expired, "mum", 3)
+	if err != nil || !response.TimedOut || len(response.Results) != 0 {
+		t.Errorf("QueryEx() past its deadline = %+v, %v; want a timed-out response", response, err)
+	}
+	if _, err := ac.QueryEx(ctx, "", 3); !errors.Is(err, ErrQueryTooShort) {
+		t.Errorf("QueryEx() error = %v, want %v", err, ErrQueryTooShort)
+	}
+}
+
+func TestQueryExMoreCount(t *testing.T) {
+	RegisterProvider("mock-more", func(config interface{}) (providers.Provider, error) {
+		return newMockProvider(), nil
+	})
+	config := NewConfig(nil)
+	config.Options.MoreCountLimit = 3
+	ac, err := New("mock-more", config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+	for i := 1; i <= 6; i++ {
+		id := fmt.Sprint(i)
+		if err := ac.Index(ctx, id, "Mumbai "+id, "Mumbai "+id); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		limit       int
+		more        int
+		moreAtLeast bool
+		total       int
+	}{
+		{limit: 1, more: 3, moreAtLeast: true, total: -1},
+		{limit: 4, more: 2, total: 6},
+		{limit: 6, more: 0, total: 6},
+	}
+	for _, tt := range tests {
+		response, err := ac.QueryEx(ctx, "mum", tt.limit)
+		if err != nil {
+			t.Fatalf("QueryEx() error = %v", err)
+		}
+		if len(response.Results) != tt.limit || response.More != tt.more ||
+			response.MoreAtLeast != tt.moreAtLeast || response.Total != tt.total || response.Truncated != (tt.more > 0) {
+			t.Errorf("QueryEx(limit %d) = %+v, want More %d (at least: %v) of total %d",
+				tt.limit, response, tt.more, tt.moreAtLeast, tt.total)
+		}
+	}
+}
+
+func TestRequestOptions(t *testing.T) {
+	mock := newMockProvider()
+	RegisterProvider("mock-request-options", func(config interface{}) (providers.Provider, error) {
+		return mock, nil
+	})
+	ac, err := New("mock-request-options", NewConfig(nil))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+	for _, id := range []string{"1", "2", "3"} {
+		if err := ac.Index(ctx, id, "Mumbai "+id, "Mumbai "+id); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+	if err := mock.Index(ctx, "tenant", "t1", "mumbra", "Mumbra", providers.IndexOptions{Score: 1}); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+
+	limited := WithRequestOptions(ctx, RequestOptions{Limit: 2})
+	if results, err := ac.Query(limited, "mum", 0); err != nil || len(results) != 2 {
+		t.Errorf("Query() with a request limit = %v, %v; want 2 results", results, err)
+	}
+	if results, err := ac.Query(limited, "mum", 3); err != nil || len(results) != 3 {
+		t.Errorf("Query() with an explicit limit = %v, %v; want 3 results", results, err)
+	}
+
+	tenant := WithRequestOptions(ctx, RequestOptions{Namespace: "tenant"})
+	results, err := ac.Query(tenant, "mum", 10)
+	if err != nil || len(results) != 1 || results[0].ID != "t1" {
+		t.Errorf("Query() with a request namespace = %v, %v; want only t1", results, err)
+	}
+
+	boosted := WithRequestOptions(ctx, RequestOptions{Boosts: map[string]float64{"2": 3}})
+	response, err := ac.QueryEx(boosted, "mum", 10)
+	if err != nil || len(response.Results) != 3 || response.Results[0].ID != "2" || response.Results[0].Score != 3 {
+		t.Errorf("QueryEx() with boosts = %+v, %v; want entry 2 first with score 3", response, err)
+	}
+}
+
+type batchProvider struct {
+	*mockProvider
+	batches int
+}
+
+func (p *batchProvider) BatchIndex(ctx context.Context, key string, entries []providers.IndexEntry) error {
+	p.batches++
+	return p.IndexAtomic(ctx, key, entries)
+}
+
+func TestBatchIndex(t *testing.T) {
+	mock := newMockProvider()
+	batcher := &batchProvider{mockProvider: newMockProvider()}
+	RegisterProvider("mock-batch-fallback", func(config interface{}) (providers.Provider, error) {
+		return mock, nil
+	})
+	RegisterProvider("mock-batch", func(config interface{}) (providers.Provider, error) {
+		return batcher, nil
+	})
+	ctx := context.Background()
+	entries := []Entry{
+		{ID: "1", Text: "Mumbai", Display: "Mumbai"},
+		{ID: "2", Text: "Bombay", Display: "Mumbai"},
+	}
+
+	for _, name := range []string{"mock-batch-fallback", "mock-batch"} {
+		ac, err := New(name, NewConfig(nil))
+		if err != nil {
+			t.Fatalf("New(%q) error = %v", name, err)
+		}
+		err = ac.BatchIndex(ctx, append(slices.Clone(entries), Entry{ID: "3", Text: "Pune"}))
+		if !errors.Is(err, ErrEmptyDisplay) {
+			t.Errorf("%s: BatchIndex() with invalid entry error = %v, want %v", name, err, ErrEmptyDisplay)
+		}
+		if err := ac.BatchIndex(ctx, entries); err != nil {
+			t.Fatalf("%s: BatchIndex() error = %v", name, err)
+		}
+		results, err := ac.Query(ctx, "b", 10)
+		if err != nil || len(results) != 1 || results[0].ID != "2" {
+			t.Errorf("%s: Query() after BatchIndex = %+v, %v; want entry 2", name, results, err)
+		}
+	}
+	if mock.indexCalls != 2 {
+		t.Errorf("BatchIndex() without provider support made %d Index calls, want 2", mock.indexCalls)
+	}
+	if batcher.batches != 1 {
+		t.Errorf("BatchIndex() made %d provider batches, want 1", batcher.batches)
+	}
+}
+
+func (p *batchProvider) DeleteMany(ctx context.Context, key string, ids []string) error {
+	p.batches++
+	for _, id := range ids {
+		if err := p.Delete(ctx, key, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestDeleteMany(t *testing.T) {
+	batcher := &batchProvider{mockProvider: newMockProvider()}
+	RegisterProvider("mock-delete-many", func(config interface{}) (providers.Provider, error) {
+		return batcher, nil
+	})
+	var mutations []Mutation
+	config := NewConfig(nil)
+	config.Options.Hooks.OnMutation = func(ctx context.Context, mutation Mutation) {
+		mutations = append(mutations, mutation)
+	}
+	ac, err := New("mock-delete-many", config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+	for _, id := range []string{"1", "2", "3"} {
+		if err := ac.Index(ctx, id, "Mumbai "+id, "Mumbai "+id); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	if err := ac.DeleteMany(ctx, []string{"1", ""}); !errors.Is(err, ErrEmptyID) {
+		t.Errorf("DeleteMany() with an empty ID error = %v, want %v", err, ErrEmptyID)
+	}
+	if err := ac.DeleteMany(ctx, []string{"1", "3", "missing"}); err != nil {
+		t.Fatalf("DeleteMany() error = %v", err)
+	}
+	results, err := ac.Query(ctx, "mum", 10)
+	if err != nil || len(results) != 1 || results[0].ID != "2" {
+		t.Errorf("Query() after DeleteMany = %+v, %v; want only entry 2", results, err)
+	}
+	if batcher.batches != 1 {
+		t.Errorf("DeleteMany() made %d provider batches, want 1", batcher.batches)
+	}
+	last := mutations[len(mutations)-1]
+	if last.Op != MutationDeleteMany || !reflect.DeepEqual(last.IDs, []string{"1", "3", "missing"}) {
+		t.Errorf("OnMutation() got %+v, want a delete of 1, 3, and missing", last)
+	}
+}
+
+func TestMatchSuffixRequiresProviderSupport(t *testing.T) {
+	RegisterProvider("mock-suffix", func(config interface{}) (providers.Provider, error) {
+		return newMockProvider(), nil
+	})
+	config := NewConfig(nil)
+	config.Options.MatchStrategy = MatchSuffix
+	if _, err := New("mock-suffix", config); !errors.Is(err, ErrStrategyUnsupported) {
+		t.Errorf("New() with MatchSuffix error = %v, want %v", err, ErrStrategyUnsupported)
+	}
+	if got := MatchSuffix.String(); got != "suffix" {
+		t.Errorf("MatchSuffix.String() = %q, want %q", got, "suffix")
+	}
+}
+
+type metadataProvider struct {
+	*mockProvider
+}
+
+func (p metadataProvider) SupportsMetadata() bool {
+	return true
+}
+
+func TestIndexWithMetadata(t *testing.T) {
+	RegisterProvider("mock-metadata", func(config interface{}) (providers.Provider, error) {
+		return metadataProvider{newMockProvider()}, nil
+	})
+	RegisterProvider("mock-no-metadata", func(config interface{}) (providers.Provider, error) {
+		return newMockProvider(), nil
+	})
+
+	ctx := context.Background()
+	plain, err := New("mock-no-metadata", NewConfig(nil))
+	if err != nil {
+		t.Fatalf("Failed to create autocomplete: %v", err)
+	}
+	err = plain.IndexWithMetadata(ctx, "1", "Mumbai", "Mumbai", map[string]interface{}{"state": "MH"})
+	if !errors.Is(err, ErrMetadataUnsupported) {
+		t.Errorf("IndexWithMetadata() without provider support error = %v, want %v", err, ErrMetadataUnsupported)
+	}
+
+	ac, err := New("mock-metadata", NewConfig(nil))
+	if err != nil {
+		t.Fatalf("Failed to create autocomplete: %v", err)
+	}
+	if err := ac.IndexWithMetadata(ctx, "1", "Mumbai", "Mumbai", map[string]interface{}{"state": "MH", "pin": 400001}); err != nil {
+		t.Fatalf("IndexWithMetadata() error = %v", err)
+	}
+	if err := ac.IndexWithMetadata(ctx, "2", "Mumbra", "Mumbra", json.RawMessage(`{"state":"MH"}`)); err != nil {
+		t.Fatalf("IndexWithMetadata() with raw JSON error = %v", err)
+	}
+	if err := ac.IndexWithMetadata(ctx, "3", "Mundra", "Mundra", nil); err != nil {
+		t.Fatalf("IndexWithMetadata() with nil metadata error = %v", err)
+	}
+	err = ac.IndexWithMetadata(ctx, "4", "Munnar", "Munnar", json.RawMessage(`{"state":`))
+	if !errors.Is(err, ErrInvalidMetadata) {
+		t.Errorf("IndexWithMetadata() with malformed JSON error = %v, want %v", err, ErrInvalidMetadata)
+	}
+	if err := ac.IndexBatch(ctx, []Entry{{ID: "5", Text: "Munger", Display: "Munger", Metadata: json.RawMessage(`[1,`)}})[0].Err; !errors.Is(err, ErrInvalidMetadata) {
+		t.Errorf("IndexBatch() with malformed metadata error = %v, want %v", err, ErrInvalidMetadata)
+	}
+
+	results, err := ac.Query(ctx, "mu", 10)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	want := map[string]string{"1": `{"pin":400001,"state":"MH"}`, "2": `{"state":"MH"}`, "3": ""}
+	if len(results) != len(want) {
+		t.Fatalf("Query() returned %d results, want %d", len(results), len(want))
+	}
+	for _, result := range results {
+		if got := string(result.Metadata); got != want[result.ID] {
+			t.Errorf("result %s Metadata = %s, want %s", result.ID, got, want[result.ID])
+		}
+	}
+
+	encoded, err := json.Marshal(Result{ID: "1", Display: "Mumbai", Metadata: json.RawMessage(`{"state":"MH"}`)})
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if got, wantJSON := string(encoded), `{"id":"1","display":"Mumbai","score":0,"metadata":{"state":"MH"}}`; got != wantJSON {
+		t.Errorf("json.Marshal(Result) = %s, want %s", got, wantJSON)
+	}
+}
+
+func TestQueryFilter(t *testing.T) {
+	RegisterProvider("mock-filter", func(config interface{}) (providers.Provider, error) {
+		return metadataProvider{newMockProvider()}, nil
+	})
+	RegisterProvider("mock-no-filter", func(config interface{}) (providers.Provider, error) {
+		return newMockProvider(), nil
+	})
+
+	ctx := context.Background()
+	filtered := WithRequestOptions(ctx, RequestOptions{Filter: map[string]interface{}{"state": "Maharashtra"}})
+
+	plain, err := New("mock-no-filter", NewConfig(nil))
+	if err != nil {
+		t.Fatalf("Failed to create autocomplete: %v", err)
+	}
+	if _, err := plain.Query(filtered, "mu", 10); !errors.Is(err, ErrMetadataUnsupported) {
+		t.Errorf("Query() with filter and no provider support error = %v, want %v", err, ErrMetadataUnsupported)
+	}
+
+	ac, err := New("mock-filter", NewConfig(nil))
+	if err != nil {
+		t.Fatalf("Failed to create autocomplete: %v", err)
+	}
+	cities := []struct{ id, text, state string }{
+		{"1", "Mumbai", "Maharashtra"},
+		{"2", "Mundra", "Gujarat"},
+		{"3", "Munnar", ""},
+	}
+	for _, city := range cities {
+		var metadata interface{}
+		if city.state != "" {
+			metadata = map[string]string{"state": city.state}
+		}
+		if err := ac.IndexWithMetadata(ctx, city.id, city.text, city.text, metadata); err != nil {
+			t.Fatalf("IndexWithMetadata() error = %v", err)
+		}
+	}
+
+	results, err := ac.Query(filtered, "mu", 10)
+	if err != nil {
+		t.Fatalf("Query() with filter error = %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "1" {
+		t.Errorf("Query() with filter = %+v, want only entry 1", results)
+	}
+	if results, _ := ac.Query(ctx, "mu", 10); len(results) != len(cities) {
+		t.Errorf("Query() without filter returned %d results, want %d", len(results), len(cities))
+	}
+}
+
+func TestIndexWithScore(t *testing.T) {
+	RegisterProvider("mock-score", func(config interface{}) (providers.Provider, error) {
+		return newMockProvider(), nil
+	})
+
+	ctx := context.Background()
+	ac, err := New("mock-score", NewConfig(nil))
+	if err != nil {
+		t.Fatalf("Failed to create autocomplete: %v", err)
+	}
+	if err := ac.IndexWithScore(ctx, "1", "Mumbai", "Mumbai", 50); err != nil {
+		t.Fatalf("IndexWithScore() error = %v", err)
+	}
+	if err := ac.IndexWithScore(ctx, "2", "Mumbra", "Mumbra", 0); err != nil {
+		t.Fatalf("IndexWithScore() with zero score error = %v", err)
+	}
+	if err := ac.IndexBatch(ctx, []Entry{{ID: "3", Text: "Mundra", Display: "Mundra", Score: 7}})[0].Err; err != nil {
+		t.Fatalf("IndexBatch() error = %v", err)
+	}
+	if err := ac.IndexWithScore(ctx, "4", "Munnar", "Munnar", math.NaN()); !errors.Is(err, ErrInvalidScore) {
+		t.Errorf("IndexWithScore() with NaN error = %v, want %v", err, ErrInvalidScore)
+	}
+	if err := ac.IndexWithScore(ctx, "5", "Munger", "Munger", math.Inf(1)); !errors.Is(err, ErrInvalidScore) {
+		t.Errorf("IndexWithScore() with +Inf error = %v, want %v", err, ErrInvalidScore)
+	}
+
+	results, err := ac.Query(ctx, "mu", 10)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	got := make(map[string]float64)
+	for _, result := range results {
+		got[result.ID] = result.Score
+	}
+	if want := map[string]float64{"1": 50, "2": 1, "3": 7}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Query() scores = %v, want %v", got, want)
+	}
+}
+
+type scoreUpdatingProvider struct {
+	*mockProvider
+}
+
+func (p scoreUpdatingProvider) UpdateScore(ctx context.Context, key, id string, score float64) error {
+	if entry, ok := p.data[key][id]; ok {
+		entry.result.Score = score
+	}
+	return nil
+}
+
+func (p scoreUpdatingProvider) IncrementScore(ctx context.Context, key, id string, delta float64) error {
+	if entry, ok := p.data[key][id]; ok {
+		entry.result.Score += delta
+	}
+	return nil
+}
+
+func TestUpdateScore(t *testing.T) {
+	RegisterProvider("mock-update-score", func(config interface{}) (providers.Provider, error) {
+		return scoreUpdatingProvider{newMockProvider()}, nil
+	})
+	RegisterProvider("mock-no-update-score", func(config interface{}) (providers.Provider, error) {
+		return newMockProvider(), nil
+	})
+
+	ctx := context.Background()
+	plain, err := New("mock-no-update-score", NewConfig(nil))
+	if err != nil {
+		t.Fatalf("Failed to create autocomplete: %v", err)
+	}
+	if err := plain.UpdateScore(ctx, "1", 5); !errors.Is(err, ErrScoreUpdateUnsupported) {
+		t.Errorf("UpdateScore() without provider support error = %v, want %v", err, ErrScoreUpdateUnsupported)
+	}
+
+	var mutations []Mutation
+	config := NewConfig(nil)
+	config.Options.Hooks.OnMutation = func(ctx context.Context, mutation Mutation) {
+		mutations = append(mutations, mutation)
+	}
+	ac, err := New("mock-update-score", config)
+	if err != nil {
+		t.Fatalf("Failed to create autocomplete: %v", err)
+	}
+	if err := ac.Index(ctx, "1", "Mumbai", "Mumbai"); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if err := ac.UpdateScore(ctx, "1", 42); err != nil {
+		t.Fatalf("UpdateScore() error = %v", err)
+	}
+	if err := ac.UpdateScore(ctx, "missing", 42); err != nil {
+		t.Errorf("UpdateScore() of unknown ID error = %v", err)
+	}
+	if err := ac.UpdateScore(ctx, "", 42); !errors.Is(err, ErrEmptyID) {
+		t.Errorf("UpdateScore() with empty ID error = %v, want %v", err, ErrEmptyID)
+	}
+	if err := ac.UpdateScore(ctx, "1", math.NaN()); !errors.Is(err, ErrInvalidScore) {
+		t.Errorf("UpdateScore() with NaN error = %v, want %v", err, ErrInvalidScore)
+	}
+
+	results, err := ac.Query(ctx, "mum", 10)
+	if err != nil || len(results) != 1 || results[0].Score != 42 {
+		t.Errorf("Query() = %+v, %v; want entry 1 with score 42", results, err)
+	}
+	last := mutations[len(mutations)-1]
+	if last.Op != MutationUpdateScore || last.ID != "missing" || last.Score != 42 || last.Op.String() != "update score" {
+		t.Errorf("OnMutation() got %+v, want a score update of missing to 42", last)
+	}
+}
+
+func TestIncrementScore(t *testing.T) {
+	RegisterProvider("mock-increment-score", func(config interface{}) (providers.Provider, error) {
+		return scoreUpdatingProvider{newMockProvider()}, nil
+	})
+	RegisterProvider("mock-no-increment-score", func(config interface{}) (providers.Provider, error) {
+		return newMockProvider(), nil
+	})
+
+	ctx := context.Background()
+	plain, err := New("mock-no-increment-score", NewConfig(nil))
+	if err != nil {
+		t.Fatalf("Failed to create autocomplete: %v", err)
+	}
+	if err := plain.IncrementScore(ctx, "1", 1); !errors.Is(err, ErrScoreIncrementUnsupported) {
+		t.Errorf("IncrementScore() without provider support error = %v, want %v", err, ErrScoreIncrementUnsupported)
+	}
+
+	var mutations []Mutation
+	config := NewConfig(nil)
+	config.Options.Hooks.OnMutation = func(ctx context.Context, mutation Mutation) {
+		mutations = append(mutations, mutation)
+	}
+	ac, err := New("mock-increment-score", config)
+	if err != nil {
+		t.Fatalf("Failed to create autocomplete: %v", err)
+	}
+	if err := ac.Index(ctx, "1", "Mumbai", "Mumbai"); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	for range 3 {
+		if err := ac.IncrementScore(ctx, "1", 1); err != nil {
+			t.Fatalf("IncrementScore() error = %v", err)
+		}
+	}
+	if err := ac.IncrementScore(ctx, "1", -0.5); err != nil {
+		t.Fatalf("IncrementScore() with negative delta error = %v", err)
+	}
+	if err := ac.IncrementScore(ctx, "", 1); !errors.Is(err, ErrEmptyID) {
+		t.Errorf("IncrementScore() with empty ID error = %v, want %v", err, ErrEmptyID)
+	}
+	if err := ac.IncrementScore(ctx, "1", math.Inf(-1)); !errors.Is(err, ErrInvalidScore) {
+		t.Errorf("IncrementScore() with -Inf error = %v, want %v", err, ErrInvalidScore)
+	}
+
+	results, err := ac.Query(ctx, "mum", 10)
+	if err != nil || len(results) != 1 || results[0].Score != 3.5 {
+		t.Errorf("Query() = %+v, %v; want entry 1 with score 3.5", results, err)
+	}
+	last := mutations[len(mutations)-1]
+	if last.Op != MutationIncrementScore || last.ID != "1" || last.Score != -0.5 {
+		t.Errorf("OnMutation() got %+v, want an increment of 1 by -0.5", last)
+	}
+}
+
+type explainingProvider struct {
+	*mockProvider
+}
+
+func (p explainingProvider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	results, err := p.mockProvider.Query(ctx, key, query, options)
+	if options.ExplainScores {
+		for i := range results {
+			results[i].Breakdown = &providers.ScoreBreakdown{Entry: results[i].Score}
+		}
+	}
+	return results, err
+}
+
+func TestExplainScores(t *testing.T) {
+	RegisterProvider("mock-explain", func(config interface{}) (providers.Provider, error) {
+		return explainingProvider{newMockProvider()}, nil
+	})
+
+	ctx := context.Background()
+	ac, err := New("mock-explain", NewConfig(nil))
+	if err != nil {
+		t.Fatalf("Failed to create autocomplete: %v", err)
+	}
+	if err := ac.IndexWithScore(ctx, "1", "Mumbai", "Mumbai", 2); err != nil {
+		t.Fatalf("IndexWithScore() error = %v", err)
+	}
+
+	results, err := ac.Query(ctx, "mum", 10)
+	if err != nil || len(results) != 1 || results[0].Explanation != nil {
+		t.Fatalf("Query() without Explain = %+v, %v; want one result without explanation", results, err)
+	}
+
+	explained := WithRequestOptions(ctx, RequestOptions{Explain: true, Boosts: map[string]float64{"1": 1.5}})
+	results, err = ac.Query(explained, "mum", 10)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("Query() with Explain = %+v, %v; want one result", results, err)
+	}
+	want := ScoreExplanation{Provider: 2, Entry: 2, Boost: 1.5}
+	if got := results[0].Explanation; got == nil || *got != want {
+		t.Errorf("Explanation = %+v, want %+v", got, want)
+	}
+	if results[0].Score != 3 {
+		t.Errorf("Score = %v, want 3", results[0].Score)
+	}
+
+	encoded, err := json.Marshal(results[0].Explanation)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if got, wantJSON := string(encoded), `{"provider":2,"entry":2,"boost":1.5}`; got != wantJSON {
+		t.Errorf("json.Marshal(Explanation) = %s, want %s", got, wantJSON)
+	}
+}
+
+type existenceCheckingProvider struct {
+	*mockProvider
+	checks *int
+}
+
+func (p existenceCheckingProvider) Exists(ctx context.Context, key, id string) (bool, error) {
+	*p.checks++
+	_, exists := p.data[key][id]
+	return exists, nil
+}
+
+func TestExists(t *testing.T) {
+	checks := 0
+	RegisterProvider("mock-exists", func(config interface{}) (providers.Provider, error) {
+		return existenceCheckingProvider{mockProvider: newMockProvider(), checks: &checks}, nil
+	})
+	RegisterProvider("mock-exists-hash", func(config interface{}) (providers.Provider, error) {
+		return newMockProvider(), nil
+	})
+	RegisterProvider("mock-no-exists", func(config interface{}) (providers.Provider, error) {
+		return struct{ providers.Provider }{newMockProvider()}, nil
+	})
+
+	ctx := context.Background()
+	for _, name := range []string{"mock-exists", "mock-exists-hash"} {
+		ac, err := New(name, NewConfig(nil))
+		if err != nil {
+			t.Fatalf("New(%q) error = %v", name, err)
+		}
+		if err := ac.Index(ctx, "1", "Mumbai", "Mumbai"); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+		if exists, err := ac.Exists(ctx, "1"); err != nil || !exists {
+			t.Errorf("%s: Exists() of indexed entry = %v, %v; want true", name, exists, err)
+		}
+		if exists, err := ac.Exists(ctx, "2"); err != nil || exists {
+			t.Errorf("%s: Exists() of unknown entry = %v, %v; want false", name, exists, err)
+		}
+		if err := ac.Delete(ctx, "1"); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if exists, err := ac.Exists(ctx, "1"); err != nil || exists {
+			t.Errorf("%s: Exists() after Delete = %v, %v; want false", name, exists, err)
+		}
+		if _, err := ac.Exists(ctx, ""); !errors.Is(err, ErrEmptyID) {
+			t.Errorf("%s: Exists() with empty ID error = %v, want %v", name, err, ErrEmptyID)
+		}
+	}
+	if checks != 3 {
+		t.Errorf("provider Exists calls = %d, want 3", checks)
+	}
+
+	ac, err := New("mock-no-exists", NewConfig(nil))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := ac.Exists(ctx, "1"); !errors.Is(err, ErrExistsUnsupported) {
+		t.Errorf("Exists() error = %v, want %v", err, ErrExistsUnsupported)
+	}
+}
+
+type entryGettingProvider struct {
+	metadataProvider
+}
+
+func (p entryGettingProvider) GetEntry(ctx context.Context, key, id string) (providers.StoredEntry, bool, error) {
+	entry, exists := p.data[key][id]
+	if !exists {
+		return providers.StoredEntry{}, false, nil
+	}
+	return providers.StoredEntry{
+		ID:          id,
+		Text:        entry.text,
+		Display:     entry.result.Display,
+		ContentHash: entry.contentHash,
+		Metadata:    entry.result.Metadata,
+		Score:       entry.result.Score,
+	}, true, nil
+}
+
+func TestGet(t *testing.T) {
+	RegisterProvider("mock-get", func(config interface{}) (providers.Provider, error) {
+		return entryGettingProvider{metadataProvider{newMockProvider()}}, nil
+	})
+	RegisterProvider("mock-no-get", func(config interface{}) (providers.Provider, error) {
+		return newMockProvider(), nil
+	})
+
+	ctx := context.Background()
+	ac, err := New("mock-get", NewConfig(nil))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	err = ac.IndexBatch(ctx, []Entry{
+		{ID: "1", Text: "mumbai", Display: "Mumbai GPO", Score: 5, Metadata: json.RawMessage(`{"state":"MH"}`)},
+		{ID: "2", Text: "pune", Display: "Pune"},
+	})[0].Err
+	if err != nil {
+		t.Fatalf("IndexBatch() error = %v", err)
+	}
+	entry, err := ac.Get(ctx, "1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	want := Entry{ID: "1", Text: "mumbai", Display: "Mumbai GPO", Score: 5, Metadata: json.RawMessage(`{"state":"MH"}`)}
+	if !reflect.DeepEqual(entry, want) {
+		t.Errorf("Get() = %+v, want %+v", entry, want)
+	}
+	if entry, err := ac.Get(ctx, "2"); err != nil || entry.Score != 1 || entry.Metadata != nil {
+		t.Errorf("Get() of entry without metadata = %+v, %v; want score 1 and no metadata", entry, err)
+	}
+	if _, err := ac.Get(ctx, "3"); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("Get() of unknown entry error = %v, want %v", err, ErrEntryNotFound)
+	}
+	if _, err := ac.Get(ctx, ""); !errors.Is(err, ErrEmptyID) {
+		t.Errorf("Get() with empty ID error = %v, want %v", err, ErrEmptyID)
+	}
+
+	plain, err := New("mock-no-get", NewConfig(nil))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := plain.Get(ctx, "1"); !errors.Is(err, ErrGetUnsupported) {
+		t.Errorf("Get() error = %v, want %v", err, ErrGetUnsupported)
+	}
+}
+
+type compactingProvider struct {
+	*mockProvider
+	compactions *int
+}
+
+func (p compactingProvider) Compact(ctx context.Context) error {
+	*p.compactions++
+	return nil
+}
+
+func TestCompact(t *testing.T) {
+	compactions := 0
+	RegisterProvider("mock-compact", func(config interface{}) (providers.Provider, error) {
+		return compactingProvider{mockProvider: newMockProvider(), compactions: &compactions}, nil
+	})
+	RegisterProvider("mock-no-compact", func(config interface{}) (providers.Provider, error) {
+		return newMockProvider(), nil
+	})
+
+	ctx := context.Background()
+	ac, err := New("mock-compact", NewConfig(nil))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := ac.Compact(ctx); err != nil || compactions != 1 {
+		t.Errorf("Compact() = %v with %d provider compactions, want nil with 1", err, compactions)
+	}
+
+	plain, err := New("mock-no-compact", NewConfig(nil))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := plain.Compact(ctx); !errors.Is(err, ErrCompactionUnsupported) {
+		t.Errorf("Compact() error = %v, want %v", err, ErrCompactionUnsupported)
+	}
+}
+
+type countingProvider struct {
+	*mockProvider
+}
+
+func (p countingProvider) CountEntries(ctx context.Context, key string) (int64, error) {
+	return int64(len(p.data[key])), nil
+}
+
+func TestCount(t *testing.T) {
+	RegisterProvider("mock-count", func(config interface{}) (providers.Provider, error) {
+		return countingProvider{newMockProvider()}, nil
+	})
+	RegisterProvider("mock-no-count", func(config interface{}) (providers.Provider, error) {
+		return newMockProvider(), nil
+	})
+
+	ctx := context.Background()
+	options := DefaultOptions()
+	options.CaseInsensitiveFallback = true
+	ac, err := New("mock-count", NewConfigWithOptions(nil, options))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if count, err := ac.Count(ctx); err != nil || count != 0 {
+		t.Errorf("Count() of empty namespace = %d, %v; want 0", count, err)
+	}
+	for id, city := range map[string]string{"1": "Mumbai", "2": "Pune", "3": "Nagpur"} {
+		if err := ac.Index(ctx, id, city, city); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+	if count, err := ac.Count(ctx); err != nil || count != 3 {
+		t.Errorf("Count() = %d, %v; want 3 without the case-folded copies", count, err)
+	}
+
+	plain, err := New("mock-no-count", NewConfig(nil))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := plain.Count(ctx); !errors.Is(err, ErrCountUnsupported) {
+		t.Errorf("Count() error = %v, want %v", err, ErrCountUnsupported)
+	}
+}
+
+// pagingProvider lists entries in ID order, count at a time.
+type pagingProvider struct {
+	*mockProvider
+}
+
+func (p pagingProvider) ListEntries(ctx context.Context, key, cursor string, count int) ([]providers.StoredEntry, string, error) {
+	var ids []string
+	for id := range p.data[key] {
+		if id > cursor {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	next := ""
+	if len(ids) > count {
+		ids = ids[:count]
+		next = ids[count-1]
+	}
+	entries := make([]providers.StoredEntry, len(ids))
+	for i, id := range ids {
+		entry := p.data[key][id]
+		entries[i] = providers.StoredEntry{ID: id, Text: entry.text, Display: entry.result.Display, Score: entry.result.Score}
+	}
+	return entries, next, nil
+}
+
+func TestList(t *testing.T) {
+	RegisterProvider("mock-list", func(config interface{}) (providers.Provider, error) {
+		return pagingProvider{newMockProvider()}, nil
+	})
+	RegisterProvider("mock-no-list", func(config interface{}) (providers.Provider, error) {
+		return newMockProvider(), nil
+	})
+
+	ctx := context.Background()
+	options := DefaultOptions()
+	options.CaseInsensitiveFallback = true
+	options.CaseSensitive = true
+	ac, err := New("mock-list", NewConfigWithOptions(nil, options))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if entries, next, err := ac.List(ctx, "", 2); err != nil || len(entries) != 0 || next != "" {
+		t.Errorf("List() of empty namespace = %v, %q, %v; want no entries", entries, next, err)
+	}
+	for id, city := range map[string]string{"1": "Mumbai", "2": "Pune", "3": "Nagpur", "4": "Nashik", "5": "Thane"} {
+		if err := ac.Index(ctx, id, city, city+", Maharashtra"); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	var pages [][]string
+	cursor := ""
+	for {
+		entries, next, err := ac.List(ctx, cursor, 2)
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		var ids []string
+		for _, entry := range entries {
+			ids = append(ids, entry.ID)
+		}
+		pages = append(pages, ids)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if got := fmt.Sprint(pages); got != "[[1 2] [3 4] [5]]" {
+		t.Errorf("List() pages = %v, want [[1 2] [3 4] [5]] without the case-folded copies", got)
+	}
+
+	entries, _, err := ac.List(ctx, "", 0)
+	if err != nil || len(entries) != 5 {
+		t.Fatalf("List() with the default page size = %v, %v; want all 5 entries", entries, err)
+	}
+	want := Entry{ID: "1", Text: "Mumbai", Display: "Mumbai, Maharashtra", Score: 1}
+	if !reflect.DeepEqual(entries[0], want) {
+		t.Errorf("List() entry = %+v, want %+v", entries[0], want)
+	}
+
+	plain, err := New("mock-no-list", NewConfig(nil))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, _, err := plain.List(ctx, "", 10); !errors.Is(err, ErrListUnsupported) {
+		t.Errorf("List() error = %v, want %v", err, ErrListUnsupported)
+	}
+}
+
+func TestQueryWithOptions(t *testing.T) {
+	RegisterProvider("mock-query-options", func(config interface{}) (providers.Provider, error) {
+		return metadataProvider{newMockProvider()}, nil
+	})
+
+	ctx := context.Background()
+	options := DefaultOptions()
+	options.CaseSensitive = true
+	options.CaseInsensitiveFallback = true
+	ac, err := New("mock-query-options", NewConfigWithOptions(nil, options))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	for id, city := range map[string]string{"1": "Mumbai", "2": "Mumbra"} {
+		if err := ac.IndexWithMetadata(ctx, id, city, city, map[string]interface{}{"state": "MH"}); err != nil {
+			t.Fatalf("IndexWithMetadata() error = %v", err)
+		}
+	}
+	if err := ac.IndexWithScore(ctx, "3", "Mumbai Port", "Mumbai Port", 0.2); err != nil {
+		t.Fatalf("IndexWithScore() error = %v", err)
+	}
+
+	ids := func(results []Result) string {
+		var ids []string
+		for _, r := range results {
+			if r.Fallback {
+				ids = append(ids, r.ID+"(fallback)")
+			} else {
+				ids = append(ids, r.ID)
+			}
+		}
+		sort.Strings(ids)
+		return strings.Join(ids, " ")
+	}
+	insensitive, minScore := false, 0.5
+	tests := []struct {
+		name string
+		opts QueryOptions
+		want string
+	}{
+		{"instance options", QueryOptions{}, "1(fallback) 2(fallback) 3(fallback)"},
+		{"case-insensitive", QueryOptions{CaseSensitive: &insensitive}, "1 2 3"},
+		{"min score", QueryOptions{CaseSensitive: &insensitive, MinScore: &minScore}, "1 2"},
+		{"filter", QueryOptions{Filter: map[string]interface{}{"state": "MH"}, CaseSensitive: &insensitive}, "1 2"},
+		{"filter without matches", QueryOptions{CaseSensitive: &insensitive, Filter: map[string]interface{}{"state": "GA"}}, ""},
+	}
+	for _, tt := range tests {
+		results, err := ac.QueryWithOptions(ctx, "mum", tt.opts)
+		if err != nil {
+			t.Fatalf("%s: QueryWithOptions() error = %v", tt.name, err)
+		}
+		if got := ids(results); got != tt.want {
+			t.Errorf("%s: QueryWithOptions() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if results, err := ac.QueryWithOptions(ctx, "mum", QueryOptions{Limit: 1, CaseSensitive: &insensitive}); err != nil || len(results) != 1 {
+		t.Errorf("QueryWithOptions() with limit 1 = %v, %v; want 1 result", results, err)
+	}
+
+	// Overrides apply to their call only
+	if results, err := ac.Query(ctx, "mum", 10); err != nil || ids(results) != "1(fallback) 2(fallback) 3(fallback)" {
+		t.Errorf("Query() after QueryWithOptions = %q, %v; want the fallback results", ids(results), err)
+	}
+
+	nan := math.NaN()
+	if _, err := ac.QueryWithOptions(ctx, "mum", QueryOptions{MinScore: &nan}); !errors.Is(err, ErrInvalidScore) {
+		t.Errorf("QueryWithOptions() with a NaN min score error = %v, want %v", err, ErrInvalidScore)
+	}
+	folded, err := New("mock-query-options", NewConfig(nil))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	sensitive := true
+	if _, err := folded.QueryWithOptions(ctx, "mum", QueryOptions{CaseSensitive: &sensitive}); !errors.Is(err, ErrCaseSensitivityNotIndexed) {
+		t.Errorf("QueryWithOptions() case-sensitive of a case-insensitive namespace error = %v, want %v", err, ErrCaseSensitivityNotIndexed)
+	}
+	options.CaseInsensitiveFallback = false
+	strict, err := New("mock-query-options", NewConfigWithOptions(nil, options))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := strict.QueryWithOptions(ctx, "mum", QueryOptions{CaseSensitive: &insensitive}); !errors.Is(err, ErrCaseSensitivityNotIndexed) {
+		t.Errorf("QueryWithOptions() case-insensitive without folded copies error = %v, want %v", err, ErrCaseSensitivityNotIndexed)
+	}
+}
+
+type pagedQueryProvider struct {
+	*mockProvider
+}
+
+func (p pagedQueryProvider) QueryPage(
+	ctx context.Context, key, query string, options providers.QueryOptions, cursor string,
+) ([]providers.ProviderResult, string, error) {
+	var ids []string
+	for id, entry := range p.data[key] {
+		if id > cursor && strings.HasPrefix(entry.text, strings.ToLower(query)) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	next := ""
+	if len(ids) > options.MaxResults {
+		ids = ids[:options.MaxResults]
+		next = ids[len(ids)-1]
+	}
+	results := make([]providers.ProviderResult, len(ids))
+	for i, id := range ids {
+		results[i] = *p.data[key][id].result
+	}
+	return results, next, nil
+}
+
+// queryPages pages through query with the given limit, returning the IDs of each page
+func queryPages(t *testing.T, ac AutoComplete, query string, limit int) [][]string {
+	t.Helper()
+	var pages [][]string
+	cursor := ""
+	for {
+		results, next, err := ac.QueryPage(context.Background(), query, limit, cursor)
+		if err != nil {
+			t.Fatalf("QueryPage() error = %v", err)
+		}
+		var ids []string
+		for _, r := range results {
+			ids = append(ids, r.ID)
+		}
+		pages = append(pages, ids)
+		if next == "" {
+			return pages
+		}
+		cursor = next
+	}
+}
+
+func TestQueryPage(t *testing.T) {
+	RegisterProvider("mock-page", func(config interface{}) (providers.Provider, error) {
+		return pagedQueryProvider{newMockProvider()}, nil
+	})
+	RegisterProvider("mock-no-page", func(config interface{}) (providers.Provider, error) {
+		return newMockProvider(), nil
+	})
+
+	ctx := context.Background()
+	index := func(ac AutoComplete) {
+		for id, city := range map[string]string{"1": "Nagpur", "2": "Nashik", "3": "Navi Mumbai", "4": "Nanded", "5": "Nandurbar", "6": "Pune"} {
+			if err := ac.Index(ctx, id, city, city); err != nil {
+				t.Fatalf("Index() error = %v", err)
+			}
+		}
+	}
+
+	paged, err := New("mock-page", NewConfig(nil))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	index(paged)
+	if got := fmt.Sprint(queryPages(t, paged, "na", 2)); got != "[[1 2] [3 4] [5]]" {
+		t.Errorf("QueryPage() pages = %v, want [[1 2] [3 4] [5]] from the provider", got)
+	}
+
+	// Without provider support, each page is cut from the results up to its
+	// end, ordered here by ResultLess, and paging stops at MaxLimit
+	options := DefaultOptions()
+	options.MaxLimit = 4
+	options.ResultLess = func(a, b Result) bool { return a.ID < b.ID }
+	plain, err := New("mock-no-page", NewConfigWithOptions(nil, options))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	index(plain)
+	if got := fmt.Sprint(queryPages(t, plain, "na", 2)); got != "[[1 2] [3 4]]" {
+		t.Errorf("QueryPage() pages = %v, want [[1 2] [3 4]] up to MaxLimit", got)
+	}
+	if got := fmt.Sprint(queryPages(t, plain, "pu", 2)); got != "[[6]]" {
+		t.Errorf("QueryPage() pages = %v, want [[6]]", got)
+	}
+
+	response, err := plain.QueryEx(ctx, "na", 2)
+	if err != nil || response.NextCursor == "" {
+		t.Fatalf("QueryEx() = %+v, %v; want a next cursor", response, err)
+	}
+	if results, _, err := plain.QueryPage(ctx, "na", 2, response.NextCursor); err != nil || len(results) != 2 || results[0].ID != "3" {
+		t.Errorf("QueryPage() after QueryEx = %v, %v; want the page from 3", results, err)
+	}
+
+	_, next, err := plain.QueryPage(ctx, "na", 2, "")
+	if err != nil || next == "" {
+		t.Fatalf("QueryPage() = %q, %v; want a next cursor", next, err)
+	}
+	for _, cursor := range []string{"not a cursor", next} {
+		if _, _, err := plain.QueryPage(ctx, "nag", 2, cursor); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("QueryPage(%q) error = %v, want %v", cursor, err, ErrInvalidCursor)
+		}
+	}
+}
+
+type statsReportingProvider struct {
+	*mockProvider
+}
+
+func (p statsReportingProvider) IndexStats(ctx context.Context, key string) (providers.IndexStats, error) {
+	stats := providers.IndexStats{Entries: int64(len(p.data[key])), Details: map[string]string{"key": key}}
+	for _, e := range p.data[key] {
+		stats.Tokens += int64(len(e.text))
+		stats.Bytes += int64(len(e.text) + len(e.result.Display))
+	}
+	return stats, nil
+}
+
+func TestStats(t *testing.T) {
+	RegisterProvider("mock-stats", func(config interface{}) (providers.Provider, error) {
+		return statsReportingProvider{newMockProvider()}, nil
+	})
+	RegisterProvider("mock-no-stats", func(config interface{}) (providers.Provider, error) {
+		return newMockProvider(), nil
+	})
+
+	ctx := context.Background()
+	options := DefaultOptions()
+	options.Namespace = "cities"
+	options.CaseInsensitiveFallback = true
+	ac, err := New("mock-stats", NewConfigWithOptions(nil, options))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	for id, city := range map[string]string{"1": "Mumbai", "2": "Pune"} {
+		if err := ac.Index(ctx, id, city, city); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	stats, err := ac.Stats(ctx)
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	want := Stats{Namespace: "cities", Entries: 2, Tokens: 10, Bytes: 20, Details: map[string]string{"key": "cities"}}
+	if !reflect.DeepEqual(stats, want) {
+		t.Errorf("Stats() = %+v, want %+v without the case-folded copies", stats, want)
+	}
+
+	plain, err := New("mock-no-stats", NewConfig(nil))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := plain.Stats(ctx); !errors.Is(err, ErrStatsUnsupported) {
+		t.Errorf("Stats() error = %v, want %v", err, ErrStatsUnsupported)
+	}
+}
@@ -0,0 +1,212 @@
+package autocomplete
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// Entry is a single item to be indexed.
+type Entry struct {
+	// ID is the unique identifier of the entry.
+	ID string `json:"id"`
+
+	// Text is what gets indexed and matched against queries.
+	Text string `json:"text"`
+
+	// Display is what appears in search results.
+	Display string `json:"display"`
+
+	// Metadata is an optional JSON payload stored alongside the entry and
+	// returned in Result.Metadata, e.g. the fields an application needs once a
+	// result is selected. Requires a provider implementing
+	// providers.MetadataSupporter.
+	Metadata json.RawMessage `json:"metadata,omitempty"`
+
+	// Score, if non-zero, is the score the entry is indexed with in place of
+	// the one Options.Scorer (or the default of 1) would give.
+	Score float64 `json:"score,omitempty"`
+}
+
+// IndexStatus describes what happened to an entry during indexing.
+type IndexStatus int
+
+const (
+	// IndexCreated means the entry did not exist before and was written.
+	IndexCreated IndexStatus = iota
+	// IndexUpdated means an existing entry was rewritten with new content.
+	// Providers that cannot detect existing entries report every write as updated.
+	IndexUpdated
+	// IndexUnchanged means the stored entry already had identical content.
+	// The write is skipped when Options.SkipUnchanged is set.
+	IndexUnchanged
+	// IndexFailed means the entry was not written; IndexResult.Err holds the cause.
+	IndexFailed
+)
+
+// String returns the lowercase name of the status.
+func (s IndexStatus) String() string {
+	switch s {
+	case IndexCreated:
+		return "created"
+	case IndexUpdated:
+		return "updated"
+	case IndexUnchanged:
+		return "unchanged"
+	case IndexFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// IndexResult is the outcome of indexing a single entry in a batch.
+type IndexResult struct {
+	// ID is the entry ID as given in the batch.
+	ID string
+
+	// Status reports whether the entry was created, updated, unchanged, or failed.
+	Status IndexStatus
+
+	// Err is the reason the entry failed. It is nil unless Status is IndexFailed.
+	Err error
+}
+
+// IndexBatch adds or updates multiple entries and reports the outcome of each.
+// See AutoComplete.IndexBatch for details.
+func (a *autocompleteImpl) IndexBatch(ctx context.Context, entries []Entry) []IndexResult {
+	results := make([]IndexResult, len(entries))
+	for i, entry := range entries {
+		status, err := a.indexEntry(ctx, entry, true)
+		if err == nil {
+			if a.displays != nil {
+				a.displays.remove(entry.ID)
+			}
+			a.reportIndexMutation(ctx, []Entry{entry}, false)
+		}
+		results[i] = IndexResult{ID: entry.ID, Status: status, Err: err}
+	}
+	return results
+}
+
+// IndexAtomic adds or updates multiple entries as a single all-or-nothing unit.
+// See AutoComplete.IndexAtomic for details.
+func (a *autocompleteImpl) IndexAtomic(ctx context.Context, entries []Entry) error {
+	indexer, ok := a.provider.(providers.TransactionalIndexer)
+	if !ok {
+		return ErrTransactionsUnsupported
+	}
+
+	providerEntries, err := a.providerEntries(ctx, entries)
+	if err != nil {
+		return err
+	}
+
+	if err := indexer.IndexAtomic(ctx, a.config.Options.Namespace, providerEntries); err != nil {
+		return err
+	}
+	if err := a.indexFoldedAtomic(ctx, indexer, providerEntries); err != nil {
+		return err
+	}
+	if err := a.finishBatch(ctx, providerEntries); err != nil {
+		return err
+	}
+	a.reportIndexMutation(ctx, entries, true)
+	return nil
+}
+
+// BatchIndex adds or updates multiple entries in as few round trips as the provider allows.
+// See AutoComplete.BatchIndex for details.
+func (a *autocompleteImpl) BatchIndex(ctx context.Context, entries []Entry) error {
+	providerEntries, err := a.providerEntries(ctx, entries)
+	if err != nil {
+		return err
+	}
+
+	indexer, ok := a.provider.(providers.BatchIndexer)
+	if !ok {
+		indexer = entryByEntryIndexer{a.provider}
+	}
+	if err := indexer.BatchIndex(ctx, a.config.Options.Namespace, providerEntries); err != nil {
+		return err
+	}
+	if a.caseFallback() {
+		folded := make([]providers.IndexEntry, len(providerEntries))
+		for i, entry := range providerEntries {
+			folded[i] = entry
+			folded[i].Options = foldedOptions(entry.Options)
+		}
+		if err := indexer.BatchIndex(ctx, a.foldedNamespace(), folded); err != nil {
+			return err
+		}
+	}
+	if err := a.finishBatch(ctx, providerEntries); err != nil {
+		return err
+	}
+	a.reportIndexMutation(ctx, entries, false)
+	return nil
+}
+
+// providerEntries normalizes and validates entries written together, returning
+// them as provider entries. Nothing is written if any entry is invalid.
+func (a *autocompleteImpl) providerEntries(ctx context.Context, entries []Entry) ([]providers.IndexEntry, error) {
+	providerEntries := make([]providers.IndexEntry, len(entries))
+	for i, entry := range entries {
+		entry.Text = a.normalize(entry.Text)
+		if err := a.validateEntry(entry); err != nil {
+			return nil, fmt.Errorf("%w: entry %d (id %q)", err, i, entry.ID)
+		}
+		entry, err := a.applyTokenBudget(ctx, entry)
+		if err != nil {
+			return nil, fmt.Errorf("%w: entry %d", err, i)
+		}
+		providerEntries[i] = providers.IndexEntry{
+			ID:      entry.ID,
+			Text:    entry.Text,
+			Display: entry.Display,
+			Options: a.indexOptions(entry),
+		}
+	}
+	return providerEntries, nil
+}
+
+// finishBatch writes the segment copies of entries written together, once the
+// entries are in place, then drops their cached display text and reports them.
+func (a *autocompleteImpl) finishBatch(ctx context.Context, entries []providers.IndexEntry) error {
+	for _, entry := range entries {
+		// Segment copies are written one by one once the entries are in place.
+		if err := a.indexSegments(ctx, storedEntry(entry), entry.Options); err != nil {
+			return err
+		}
+	}
+	for _, entry := range entries {
+		if a.displays != nil {
+			a.displays.remove(entry.ID)
+		}
+		a.reportIndexed(ctx, storedEntry(entry))
+	}
+	return nil
+}
+
+// storedEntry returns the entry a provider entry was built from, after normalization.
+func storedEntry(entry providers.IndexEntry) Entry {
+	return Entry{ID: entry.ID, Text: entry.Text, Display: entry.Display, Metadata: metadataPayload(entry.Options.Metadata)}
+}
+
+// entryByEntryIndexer writes batches with one Index call per entry, for
+// providers that do not implement providers.BatchIndexer.
+type entryByEntryIndexer struct {
+	provider providers.Provider
+}
+
+// BatchIndex writes the entries one at a time, stopping at the first failure.
+func (w entryByEntryIndexer) BatchIndex(ctx context.Context, key string, entries []providers.IndexEntry) error {
+	for _, entry := range entries {
+		if err := w.provider.Index(ctx, key, entry.ID, entry.Text, entry.Display, entry.Options); err != nil {
+			return fmt.Errorf("entry %q: %w", entry.ID, err)
+		}
+	}
+	return nil
+}
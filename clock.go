@@ -0,0 +1,28 @@
+package autocomplete
+
+import "time"
+
+// Clock is the source of the current time for everything an instance times:
+// cache expiry, the age of stale results, and Mutation.Time. Tests and
+// simulations can set Options.Clock to one they advance by hand, instead of
+// waiting for real time to pass.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+}
+
+// systemClock is the Clock used when Options.Clock is nil.
+type systemClock struct{}
+
+// Now returns time.Now().
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// clockOrSystem returns clock, or the system clock if it is nil.
+func clockOrSystem(clock Clock) Clock {
+	if clock == nil {
+		return systemClock{}
+	}
+	return clock
+}
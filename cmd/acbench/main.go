@@ -0,0 +1,212 @@
+// Command acbench loads a synthetic dataset into a provider and runs a mixed
+// query and index workload against it, printing latency percentiles and the
+// index size the provider reports, e.g. to size Redis before launch:
+//
+//	go run ./cmd/acbench -provider redis -redis-addr localhost:6379 \
+//		-entries 100000 -min-length 10 -max-length 60 -length-dist normal \
+//		-duration 30s -workers 16 -index-ratio 0.05 -project 5000000
+//
+// The namespace it benchmarks (-namespace, "acbench" by default) is cleared
+// before loading and, unless -keep is set, after the run.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"time"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers/memory"
+	"github.com/remiges-tech/autocomplete/providers/redis"
+)
+
+// strategies are the match strategies accepted by -strategy, by name.
+var strategies = []autocomplete.MatchStrategy{
+	autocomplete.MatchPrefix,
+	autocomplete.MatchNGram,
+	autocomplete.MatchNOrMoreGram,
+	autocomplete.MatchSubstring,
+	autocomplete.MatchSuffix,
+	autocomplete.MatchAnchoredSubstring,
+}
+
+// flags holds the command line.
+type flags struct {
+	provider    string
+	redisAddr   string
+	redisLayout string
+	namespace   string
+	strategy    string
+	dataset     datasetConfig
+	workload    workloadConfig
+	project     int
+	keep        bool
+}
+
+func main() {
+	var f flags
+	flag.StringVar(&f.provider, "provider", "memory", "provider to benchmark: memory or redis")
+	flag.StringVar(&f.redisAddr, "redis-addr", "localhost:6379", "Redis address, with -provider redis")
+	flag.StringVar(&f.redisLayout, "redis-layout", "lexicographic", "Redis layout, lexicographic or scored, with -provider redis")
+	flag.StringVar(&f.namespace, "namespace", "acbench", "namespace to load; it is cleared first")
+	flag.StringVar(&f.strategy, "strategy", autocomplete.MatchSubstring.String(), "match strategy")
+	flag.IntVar(&f.dataset.entries, "entries", 10000, "number of synthetic entries to load")
+	flag.IntVar(&f.dataset.minLength, "min-length", 8, "minimum entry text length in bytes")
+	flag.IntVar(&f.dataset.maxLength, "max-length", 40, "maximum entry text length in bytes")
+	flag.StringVar(&f.dataset.dist, "length-dist", distUniform, "entry text length distribution: uniform or normal")
+	flag.IntVar(&f.workload.workers, "workers", 8, "concurrent workers for loading and the workload")
+	flag.DurationVar(&f.workload.duration, "duration", 10*time.Second, "how long to run the mixed workload")
+	flag.Float64Var(&f.workload.indexRatio, "index-ratio", 0.1, "fraction of workload operations that re-index an entry")
+	flag.IntVar(&f.workload.limit, "limit", 10, "query result limit")
+	flag.Uint64Var(&f.workload.seed, "seed", 1, "random seed, for repeatable datasets and workloads")
+	flag.IntVar(&f.project, "project", 0, "if set, estimate the index size for this many entries")
+	flag.BoolVar(&f.keep, "keep", false, "keep the loaded entries after the run")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := bench(ctx, f); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// bench runs the benchmark described by f and prints its report.
+//
+//nolint:gocritic // hugeParam: f is read once per run
+func bench(ctx context.Context, f flags) error {
+	if f.workload.workers <= 0 {
+		return fmt.Errorf("workers must be positive, got %d", f.workload.workers)
+	}
+	if f.workload.indexRatio < 0 || f.workload.indexRatio > 1 {
+		return fmt.Errorf("index ratio must be between 0 and 1, got %v", f.workload.indexRatio)
+	}
+	gen, err := newTextGenerator(f.dataset)
+	if err != nil {
+		return err
+	}
+	ac, err := open(f)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = ac.Close() }()
+
+	if err := ac.DeleteAll(ctx); err != nil {
+		return fmt.Errorf("failed to clear namespace %q: %w", f.namespace, err)
+	}
+	if !f.keep {
+		defer func() {
+			if err := ac.DeleteAll(context.Background()); err != nil {
+				log.Printf("failed to clear namespace %q: %v", f.namespace, err)
+			}
+		}()
+	}
+
+	fmt.Printf("provider %s, strategy %s, %d entries of %d-%d bytes (%s)\n\n",
+		f.provider, f.strategy, f.dataset.entries, f.dataset.minLength, f.dataset.maxLength, f.dataset.dist)
+
+	started := time.Now()
+	texts, loaded, err := load(ctx, ac, gen, f.workload.workers, f.workload.seed)
+	if err != nil {
+		return err
+	}
+	elapsed := time.Since(started)
+	fmt.Printf("load      %d entries in %v (%.0f/s)\n", len(loaded.durations), elapsed.Round(time.Millisecond),
+		float64(len(loaded.durations))/elapsed.Seconds())
+	printLatencies("  index", &loaded, elapsed)
+
+	fmt.Printf("\nworkload  %v, %d workers, %.0f%% index\n", f.workload.duration, f.workload.workers, f.workload.indexRatio*100)
+	res := run(ctx, ac, gen, texts, f.workload)
+	printLatencies("  query", &res.queries, res.elapsed)
+	printLatencies("  index", &res.indexes, res.elapsed)
+
+	fmt.Println()
+	return printStats(ctx, ac, f.project)
+}
+
+// open creates the instance to benchmark.
+//
+//nolint:gocritic // hugeParam: f is read once per run
+func open(f flags) (autocomplete.AutoComplete, error) {
+	i := slices.IndexFunc(strategies, func(s autocomplete.MatchStrategy) bool { return s.String() == f.strategy })
+	if i < 0 {
+		return nil, fmt.Errorf("unknown match strategy %q", f.strategy)
+	}
+	options := autocomplete.DefaultOptions()
+	options.Namespace = f.namespace
+	options.MatchStrategy = strategies[i]
+	options.MaxLimit = max(options.MaxLimit, f.workload.limit)
+
+	switch f.provider {
+	case "memory":
+		return autocomplete.New("memory", autocomplete.NewConfigWithOptions(memory.Config{}, options))
+	case "redis":
+		config := redis.Config{Addr: f.redisAddr}
+		switch f.redisLayout {
+		case "lexicographic":
+		case "scored":
+			config.Layout = redis.LayoutScored
+		default:
+			return nil, fmt.Errorf("unknown Redis layout %q", f.redisLayout)
+		}
+		return autocomplete.New("redis", autocomplete.NewConfigWithOptions(config, options))
+	default:
+		return nil, fmt.Errorf("unknown provider %q, want memory or redis", f.provider)
+	}
+}
+
+// printLatencies prints the count, rate, percentiles, and errors of the
+// operations recorded in l over elapsed.
+func printLatencies(name string, l *latencies, elapsed time.Duration) {
+	fmt.Printf("%-9s n=%-8d %8.0f/s  p50=%-10v p95=%-10v p99=%-10v errors=%d\n",
+		name, len(l.durations), float64(len(l.durations))/elapsed.Seconds(),
+		l.percentile(0.50).Round(time.Microsecond), l.percentile(0.95).Round(time.Microsecond),
+		l.percentile(0.99).Round(time.Microsecond), l.errors)
+}
+
+// printStats prints the index size the provider reports and, if project is
+// set, extrapolates it to that many entries.
+func printStats(ctx context.Context, ac autocomplete.AutoComplete, project int) error {
+	stats, err := ac.Stats(ctx)
+	if errors.Is(err, autocomplete.ErrStatsUnsupported) {
+		fmt.Println("index     size not reported by the provider")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("index     %d entries, %d token postings, %s\n", stats.Entries, stats.Tokens, formatBytes(stats.Bytes))
+	for _, key := range slices.Sorted(maps.Keys(stats.Details)) {
+		fmt.Printf("  %s: %s\n", key, stats.Details[key])
+	}
+	if stats.Entries == 0 {
+		return nil
+	}
+	perEntry := float64(stats.Bytes) / float64(stats.Entries)
+	fmt.Printf("  per entry: %.0f bytes, %.1f token postings\n", perEntry, float64(stats.Tokens)/float64(stats.Entries))
+	if project > 0 {
+		fmt.Printf("  projected for %d entries: %s\n", project, formatBytes(int64(perEntry*float64(project))))
+	}
+	return nil
+}
+
+// formatBytes formats n bytes with a binary unit.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
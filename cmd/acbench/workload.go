@@ -0,0 +1,250 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/remiges-tech/autocomplete"
+)
+
+// Text length distributions accepted by -length-dist.
+const (
+	distUniform = "uniform"
+	distNormal  = "normal"
+)
+
+const (
+	// minWordLength and maxWordLength bound the length of generated words.
+	minWordLength = 3
+	maxWordLength = 10
+
+	// minQueryLength and maxQueryLength bound the length of generated queries.
+	minQueryLength = 2
+	maxQueryLength = 6
+
+	// syllables are joined into words, so that generated text shares prefixes
+	// and substrings the way place and product names do.
+	syllables = "ka ra ma na pu ne ta la sa va ri ba ga de ho mi lo chi shi vi"
+)
+
+// datasetConfig shapes the synthetic entries.
+type datasetConfig struct {
+	entries   int
+	minLength int
+	maxLength int
+	dist      string
+}
+
+// textGenerator produces synthetic entry texts and queries.
+type textGenerator struct {
+	config    datasetConfig
+	syllables []string
+}
+
+// newTextGenerator returns a generator for config, rejecting invalid settings.
+func newTextGenerator(config datasetConfig) (*textGenerator, error) {
+	if config.entries <= 0 {
+		return nil, fmt.Errorf("entries must be positive, got %d", config.entries)
+	}
+	if config.minLength < minWordLength || config.maxLength < config.minLength {
+		return nil, fmt.Errorf("text lengths must satisfy %d <= min <= max, got %d and %d",
+			minWordLength, config.minLength, config.maxLength)
+	}
+	if config.dist != distUniform && config.dist != distNormal {
+		return nil, fmt.Errorf("unknown length distribution %q, want %s or %s", config.dist, distUniform, distNormal)
+	}
+	return &textGenerator{config: config, syllables: strings.Fields(syllables)}, nil
+}
+
+// length draws a text length from the configured distribution. The normal
+// distribution is centred between the bounds, with the bounds three standard
+// deviations away, and clamped to them.
+func (g *textGenerator) length(rng *rand.Rand) int {
+	lo, hi := g.config.minLength, g.config.maxLength
+	if g.config.dist == distNormal {
+		mean := float64(lo+hi) / 2
+		stddev := max(float64(hi-lo)/6, 1)
+		n := int(math.Round(rng.NormFloat64()*stddev + mean))
+		return min(max(n, lo), hi)
+	}
+	return lo + rng.IntN(hi-lo+1)
+}
+
+// text returns a text of words, capitalized like names, of about a drawn length.
+func (g *textGenerator) text(rng *rand.Rand) string {
+	length := g.length(rng)
+	var b strings.Builder
+	for b.Len() < length {
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(g.word(rng, min(length-b.Len(), maxWordLength)))
+	}
+	return b.String()
+}
+
+// word returns a capitalized word of about limit bytes, at least minWordLength.
+func (g *textGenerator) word(rng *rand.Rand, limit int) string {
+	limit = max(limit, minWordLength)
+	var w []byte
+	for len(w) < limit {
+		w = append(w, g.syllables[rng.IntN(len(g.syllables))]...)
+	}
+	w = w[:limit]
+	w[0] -= 'a' - 'A'
+	return string(w)
+}
+
+// query returns the start of a random word of text, lowercased, so that it
+// matches the entry under every match strategy.
+func (g *textGenerator) query(rng *rand.Rand, text string) string {
+	words := strings.Fields(text)
+	word := words[rng.IntN(len(words))]
+	n := min(minQueryLength+rng.IntN(maxQueryLength-minQueryLength+1), len(word))
+	return strings.ToLower(word[:n])
+}
+
+// workloadConfig shapes the mixed query and index workload.
+type workloadConfig struct {
+	workers    int
+	duration   time.Duration
+	indexRatio float64
+	limit      int
+	seed       uint64
+}
+
+// latencies holds the durations of one kind of operation.
+type latencies struct {
+	durations []time.Duration
+	errors    int
+}
+
+// record adds the duration of an operation, counting it as an error if err is set.
+func (l *latencies) record(d time.Duration, err error) {
+	if err != nil {
+		l.errors++
+		return
+	}
+	l.durations = append(l.durations, d)
+}
+
+// merge adds the durations and errors of other.
+func (l *latencies) merge(other *latencies) {
+	l.durations = append(l.durations, other.durations...)
+	l.errors += other.errors
+}
+
+// percentile returns the duration below which the fraction p of the recorded
+// durations fall (nearest rank), or 0 if none was recorded. The durations are
+// sorted as a side effect.
+func (l *latencies) percentile(p float64) time.Duration {
+	if len(l.durations) == 0 {
+		return 0
+	}
+	slices.Sort(l.durations)
+	rank := int(math.Ceil(p*float64(len(l.durations)))) - 1
+	return l.durations[min(max(rank, 0), len(l.durations)-1)]
+}
+
+// results are the latencies of a workload run.
+type results struct {
+	queries latencies
+	indexes latencies
+	elapsed time.Duration
+}
+
+// load indexes gen.config.entries synthetic entries with the given number of
+// workers, returning their texts by entry number and the index latencies.
+func load(ctx context.Context, ac autocomplete.AutoComplete, gen *textGenerator, workers int, seed uint64) ([]string, latencies, error) {
+	texts := make([]string, gen.config.entries)
+	rng := rand.New(rand.NewPCG(seed, 0))
+	for i := range texts {
+		texts[i] = gen.text(rng)
+	}
+
+	var mu sync.Mutex
+	var all latencies
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var own latencies
+			for i := w; i < len(texts) && ctx.Err() == nil; i += workers {
+				start := time.Now()
+				err := ac.Index(ctx, entryID(i), texts[i], texts[i])
+				own.record(time.Since(start), err)
+			}
+			mu.Lock()
+			all.merge(&own)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return texts, all, ctx.Err()
+}
+
+// run queries and re-indexes the loaded entries from config.workers goroutines
+// until config.duration passes. Each operation is an Index of a random entry
+// with new text with probability config.indexRatio, and a query for part of a
+// random entry's text otherwise. Workers own disjoint entries, so that their
+// writes do not race on the texts queries are drawn from.
+func run(ctx context.Context, ac autocomplete.AutoComplete, gen *textGenerator, texts []string, config workloadConfig) results {
+	ctx, cancel := context.WithTimeout(ctx, config.duration)
+	defer cancel()
+
+	var mu sync.Mutex
+	var all results
+	var wg sync.WaitGroup
+	started := time.Now()
+	for w := range config.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rng := rand.New(rand.NewPCG(config.seed, uint64(w)+1))
+			owned := (len(texts) - w + config.workers - 1) / config.workers
+			var queries, indexes latencies
+			for owned > 0 && ctx.Err() == nil {
+				i := w + rng.IntN(owned)*config.workers
+				if rng.Float64() < config.indexRatio {
+					text := gen.text(rng)
+					start := time.Now()
+					err := ac.Index(ctx, entryID(i), text, text)
+					if ctx.Err() != nil {
+						break
+					}
+					indexes.record(time.Since(start), err)
+					if err == nil {
+						texts[i] = text
+					}
+					continue
+				}
+				start := time.Now()
+				_, err := ac.Query(ctx, gen.query(rng, texts[i]), config.limit)
+				if ctx.Err() != nil {
+					break
+				}
+				queries.record(time.Since(start), err)
+			}
+			mu.Lock()
+			all.queries.merge(&queries)
+			all.indexes.merge(&indexes)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	all.elapsed = time.Since(started)
+	return all
+}
+
+// entryID returns the ID of the i-th synthetic entry.
+func entryID(i int) string {
+	return "bench-" + strconv.Itoa(i)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"math/rand/v2"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers/memory"
+)
+
+func TestTextGeneratorLengths(t *testing.T) {
+	for _, dist := range []string{distUniform, distNormal} {
+		gen, err := newTextGenerator(datasetConfig{entries: 1, minLength: 10, maxLength: 30, dist: dist})
+		if err != nil {
+			t.Fatalf("newTextGenerator(%s) error = %v", dist, err)
+		}
+		rng := rand.New(rand.NewPCG(1, 0))
+		for range 1000 {
+			text := gen.text(rng)
+			// The last word may overrun the drawn length to reach the minimum word length
+			if len(text) < 10 || len(text) > 30+minWordLength-1 {
+				t.Fatalf("%s: text %q has %d bytes, want about 10-30", dist, text, len(text))
+			}
+			query := gen.query(rng, text)
+			if !strings.Contains(strings.ToLower(text), query) || len(query) < minQueryLength {
+				t.Fatalf("%s: query %q does not match text %q", dist, query, text)
+			}
+		}
+	}
+
+	if _, err := newTextGenerator(datasetConfig{entries: 1, minLength: 10, maxLength: 30, dist: "zipf"}); err == nil {
+		t.Error("newTextGenerator() should reject an unknown distribution")
+	}
+	if _, err := newTextGenerator(datasetConfig{entries: 1, minLength: 30, maxLength: 10, dist: distUniform}); err == nil {
+		t.Error("newTextGenerator() should reject a minimum length above the maximum")
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	var l latencies
+	if got := l.percentile(0.5); got != 0 {
+		t.Errorf("percentile() of no durations = %v, want 0", got)
+	}
+	for i := 100; i >= 1; i-- {
+		l.record(time.Duration(i)*time.Millisecond, nil)
+	}
+	for p, want := range map[float64]time.Duration{0.5: 50 * time.Millisecond, 0.95: 95 * time.Millisecond, 0.99: 99 * time.Millisecond, 1: 100 * time.Millisecond} {
+		if got := l.percentile(p); got != want {
+			t.Errorf("percentile(%v) = %v, want %v", p, got, want)
+		}
+	}
+}
+
+func TestLoadAndRun(t *testing.T) {
+	options := autocomplete.DefaultOptions()
+	options.MatchStrategy = autocomplete.MatchPrefix
+	ac, err := autocomplete.New("memory", autocomplete.NewConfigWithOptions(memory.Config{}, options))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer func() { _ = ac.Close() }()
+
+	gen, err := newTextGenerator(datasetConfig{entries: 200, minLength: 8, maxLength: 24, dist: distUniform})
+	if err != nil {
+		t.Fatalf("newTextGenerator() error = %v", err)
+	}
+	ctx := context.Background()
+	texts, loaded, err := load(ctx, ac, gen, 4, 1)
+	if err != nil || len(loaded.durations) != 200 || loaded.errors != 0 {
+		t.Fatalf("load() = %d indexed, %d errors, %v; want 200 indexed", len(loaded.durations), loaded.errors, err)
+	}
+
+	res := run(ctx, ac, gen, texts, workloadConfig{workers: 4, duration: 50 * time.Millisecond, indexRatio: 0.2, limit: 10, seed: 1})
+	if len(res.queries.durations) == 0 || len(res.indexes.durations) == 0 || res.queries.errors+res.indexes.errors != 0 {
+		t.Errorf("run() = %d queries, %d indexes, %d errors; want both kinds without errors",
+			len(res.queries.durations), len(res.indexes.durations), res.queries.errors+res.indexes.errors)
+	}
+	if count, err := ac.Count(ctx); err != nil || count != 200 {
+		t.Errorf("Count() after run() = %d, %v; want the 200 loaded entries", count, err)
+	}
+}
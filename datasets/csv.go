@@ -0,0 +1,169 @@
+// Package datasets loads public Indian location datasets, such as the India Post
+// pincode directory and the Local Government Directory list of districts, and
+// turns their records into entries ready for AutoComplete.IndexBatch.
+//
+// Loaders read CSV files from a path chosen by the caller, so the datasets can be
+// downloaded once and kept wherever suits the deployment:
+//
+//	pincodes, err := datasets.LoadPincodes("/data/all_india_pincode.csv")
+//	if err != nil {
+//		return err
+//	}
+//	results := ac.IndexBatch(ctx, datasets.PincodeEntries(pincodes))
+//
+// Columns are found by header name, so the column order and the extra columns of
+// different releases of a dataset do not matter.
+package datasets
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// column describes a column to find in a CSV header.
+type column struct {
+	// aliases are the header names the column is known by, in normalized form
+	// (see normalizeHeader).
+	aliases []string
+
+	// required makes a header without the column an error.
+	required bool
+}
+
+// table is a CSV file being read with its columns resolved.
+type table struct {
+	reader  *csv.Reader
+	indexes []int
+	line    int
+}
+
+// openTable reads the header of a CSV file and resolves columns against it.
+// A column that is missing and not required gets index -1.
+func openTable(r io.Reader, columns []column) (*table, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("empty CSV file")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	positions := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			// Spreadsheet exports often start with a byte order mark.
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		if _, seen := positions[normalizeHeader(name)]; !seen {
+			positions[normalizeHeader(name)] = i
+		}
+	}
+
+	t := &table{reader: reader, indexes: make([]int, len(columns)), line: 1}
+	for i, c := range columns {
+		t.indexes[i] = -1
+		for _, alias := range c.aliases {
+			if position, ok := positions[alias]; ok {
+				t.indexes[i] = position
+				break
+			}
+		}
+		if t.indexes[i] < 0 && c.required {
+			return nil, fmt.Errorf("CSV header has no %q column", c.aliases[0])
+		}
+	}
+	return t, nil
+}
+
+// next returns the trimmed values of the resolved columns of the next record,
+// in column order, or io.EOF after the last record. Missing values are empty.
+func (t *table) next() ([]string, error) {
+	record, err := t.reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("failed to read CSV record: %w", err)
+	}
+	t.line++
+
+	values := make([]string, len(t.indexes))
+	for i, index := range t.indexes {
+		if index >= 0 && index < len(record) {
+			values[i] = strings.TrimSpace(record[index])
+		}
+	}
+	return values, nil
+}
+
+// loadFile opens path and passes it to read.
+func loadFile[T any](path string, read func(io.Reader) ([]T, error)) ([]T, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dataset: %w", err)
+	}
+	defer file.Close()
+
+	records, err := read(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return records, nil
+}
+
+// normalizeHeader reduces a header name to its lowercase letters and digits, so
+// that "StateName", "statename" and "State Name" are the same column.
+func normalizeHeader(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// titleCase converts an all-uppercase name, as published in many government
+// datasets, to title case: "BANGALORE URBAN" becomes "Bangalore Urban". Names
+// already in mixed case are returned as is, as are abbreviations with periods
+// such as "S.O".
+func titleCase(name string) string {
+	if name != strings.ToUpper(name) {
+		return name
+	}
+
+	words := strings.Fields(name)
+	for i, word := range words {
+		if strings.Contains(word, ".") {
+			continue
+		}
+		runes := []rune(strings.ToLower(word))
+		for j, r := range runes {
+			if j == 0 || runes[j-1] == '-' || runes[j-1] == '(' {
+				runes[j] = unicode.ToUpper(r)
+			}
+		}
+		words[i] = string(runes)
+	}
+	return strings.Join(words, " ")
+}
+
+// joinNonEmpty joins the non-empty values with sep.
+func joinNonEmpty(sep string, values ...string) string {
+	kept := values[:0:0]
+	for _, v := range values {
+		if v != "" {
+			kept = append(kept, v)
+		}
+	}
+	return strings.Join(kept, sep)
+}
@@ -0,0 +1,108 @@
+package datasets
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// indiaPostCSV follows the column layout of the All India Pincode Directory on data.gov.in.
+const indiaPostCSV = "\ufeffcirclename,regionname,divisionname,officename,pincode,officetype,delivery,district,statename,latitude,longitude\n" +
+	"Karnataka Circle,Bangalore HQ Region,Bangalore GPO Division,Bangalore G.P.O.,560001,HO,Delivery,BENGALURU URBAN,KARNATAKA,12.9757,77.5931\n" +
+	"Karnataka Circle,Bangalore HQ Region,Bangalore East Division,Vidhana Soudha S.O,560001,PO,Delivery,BENGALURU URBAN,KARNATAKA,NA,NA\n" +
+	"Maharashtra Circle,Mumbai Region,Mumbai GPO Division,Mumbai G.P.O.,400001,HO,Delivery,MUMBAI,MAHARASHTRA,18.9398,72.8355\n"
+
+func TestReadPincodes(t *testing.T) {
+	pincodes, err := ReadPincodes(strings.NewReader(indiaPostCSV))
+	if err != nil {
+		t.Fatalf("ReadPincodes() error = %v", err)
+	}
+	if len(pincodes) != 3 {
+		t.Fatalf("ReadPincodes() returned %d records, want 3", len(pincodes))
+	}
+
+	want := Pincode{
+		Pincode: "560001", Office: "Vidhana Soudha", OfficeType: "PO",
+		District: "Bengaluru Urban", State: "Karnataka",
+	}
+	if pincodes[1] != want {
+		t.Errorf("ReadPincodes()[1] = %+v, want %+v", pincodes[1], want)
+	}
+	if pincodes[0].Latitude != 12.9757 || pincodes[0].Longitude != 77.5931 {
+		t.Errorf("coordinates = %v, %v, want 12.9757, 77.5931", pincodes[0].Latitude, pincodes[0].Longitude)
+	}
+
+	entries := PincodeEntries(append(pincodes, pincodes[0]))
+	if len(entries) != 3 {
+		t.Fatalf("PincodeEntries() returned %d entries, want 3 (duplicates skipped)", len(entries))
+	}
+	got := fmt.Sprintf("%s|%s|%s", entries[1].ID, entries[1].Text, entries[1].Display)
+	wantEntry := "560001/Vidhana Soudha|560001 Vidhana Soudha Bengaluru Urban Karnataka|560001 - Vidhana Soudha, Bengaluru Urban (Karnataka)"
+	if got != wantEntry {
+		t.Errorf("PincodeEntries()[1] = %q, want %q", got, wantEntry)
+	}
+}
+
+func TestReadPincodes_Errors(t *testing.T) {
+	tests := map[string]string{
+		"empty file":      "",
+		"missing column":  "officename,district\nMumbai,Mumbai\n",
+		"invalid pincode": "pincode,officename\n40001,Mumbai\n",
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ReadPincodes(strings.NewReader(data)); err == nil {
+				t.Error("ReadPincodes() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestLoadDistricts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "districts.csv")
+	data := "S.No.,State Code,State Name (In English),District Code,District Name (In English)\n" +
+		"1,29,Karnataka,572,Bengaluru Urban\n" +
+		"2,29,Karnataka,547,Mysuru\n" +
+		"3,27,Maharashtra,482,Mumbai\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	districts, err := LoadDistricts(path)
+	if err != nil {
+		t.Fatalf("LoadDistricts() error = %v", err)
+	}
+	want := District{Name: "Bengaluru Urban", State: "Karnataka", Code: "572", StateCode: "29"}
+	if len(districts) != 3 || districts[0] != want {
+		t.Fatalf("LoadDistricts() = %+v, want 3 records starting with %+v", districts, want)
+	}
+
+	entries := DistrictEntries(districts)
+	if entries[0].ID != "district:572" || entries[0].Display != "Bengaluru Urban, Karnataka" {
+		t.Errorf("DistrictEntries()[0] = %+v", entries[0])
+	}
+	states := StateEntries(districts)
+	if len(states) != 2 || states[0].ID != "state:29" || states[1].Display != "Maharashtra" {
+		t.Errorf("StateEntries() = %+v, want Karnataka and Maharashtra", states)
+	}
+
+	if _, err := LoadDistricts(filepath.Join(t.TempDir(), "missing.csv")); err == nil {
+		t.Error("LoadDistricts() of missing file error = nil, want error")
+	}
+}
+
+func TestTitleCase(t *testing.T) {
+	for input, want := range map[string]string{
+		"BANGALORE URBAN":   "Bangalore Urban",
+		"NORTH 24 PARGANAS": "North 24 Parganas",
+		"JAMMU-KASHMIR":     "Jammu-Kashmir",
+		"ACHAMPET S.O":      "Achampet S.O",
+		"Navi Mumbai":       "Navi Mumbai",
+	} {
+		if got := titleCase(input); got != want {
+			t.Errorf("titleCase(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
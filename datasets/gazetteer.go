@@ -0,0 +1,114 @@
+package datasets
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/remiges-tech/autocomplete"
+)
+
+// districtColumns are the columns read from district gazetteer CSVs, in the order
+// of the values returned for each record. The aliases cover the district lists
+// of the Local Government Directory (lgdirectory.gov.in) and simpler
+// "state,district" files.
+var districtColumns = []column{
+	{aliases: []string{"districtnameinenglish", "districtname", "district"}, required: true},
+	{aliases: []string{"statenameinenglish", "statename", "state"}, required: true},
+	{aliases: []string{"districtcode", "districtlgdcode"}},
+	{aliases: []string{"statecode", "statelgdcode"}},
+}
+
+// District is a district record from a gazetteer. Names published in uppercase
+// are converted to title case.
+type District struct {
+	Name  string `json:"name"`
+	State string `json:"state"`
+
+	// Code and StateCode are the Local Government Directory codes, when the
+	// dataset has them.
+	Code      string `json:"code,omitempty"`
+	StateCode string `json:"state_code,omitempty"`
+}
+
+// LoadDistricts reads a district gazetteer CSV file. See ReadDistricts.
+func LoadDistricts(path string) ([]District, error) {
+	return loadFile(path, ReadDistricts)
+}
+
+// ReadDistricts reads district records from CSV data with a header row. District
+// and state name columns are required; code columns are used when present.
+func ReadDistricts(r io.Reader) ([]District, error) {
+	t, err := openTable(r, districtColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	var districts []District
+	for {
+		values, err := t.next()
+		if errors.Is(err, io.EOF) {
+			return districts, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if values[0] == "" || values[1] == "" {
+			return nil, fmt.Errorf("line %d: district and state names are required", t.line)
+		}
+
+		districts = append(districts, District{
+			Name:      titleCase(values[0]),
+			State:     titleCase(values[1]),
+			Code:      values[2],
+			StateCode: values[3],
+		})
+	}
+}
+
+// DistrictEntries returns one entry per district, ready for IndexBatch. The ID is
+// "district:" followed by the district code, or by the state and district names
+// when the dataset has no codes. The text holds both names and the display reads
+// "Bangalore Urban, Karnataka". Records repeating an earlier ID are skipped.
+func DistrictEntries(districts []District) []autocomplete.Entry {
+	entries := make([]autocomplete.Entry, 0, len(districts))
+	seen := make(map[string]bool, len(districts))
+	for _, d := range districts {
+		id := "district:" + d.Code
+		if d.Code == "" {
+			id = "district:" + d.State + "/" + d.Name
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		entries = append(entries, autocomplete.Entry{
+			ID:      id,
+			Text:    d.Name + " " + d.State,
+			Display: d.Name + ", " + d.State,
+		})
+	}
+	return entries
+}
+
+// StateEntries returns one entry per distinct state of the districts, in order of
+// first appearance. The ID is "state:" followed by the state code, or by the state
+// name when the dataset has no codes; text and display are the state name.
+func StateEntries(districts []District) []autocomplete.Entry {
+	var entries []autocomplete.Entry
+	seen := make(map[string]bool)
+	for _, d := range districts {
+		id := "state:" + d.StateCode
+		if d.StateCode == "" {
+			id = "state:" + d.State
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		entries = append(entries, autocomplete.Entry{ID: id, Text: d.State, Display: d.State})
+	}
+	return entries
+}
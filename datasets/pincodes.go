@@ -0,0 +1,172 @@
+package datasets
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/remiges-tech/autocomplete"
+)
+
+// pincodeColumns are the columns read from pincode directory CSVs, in the order
+// of the values returned for each record. The aliases cover the column names of
+// the India Post "All India Pincode Directory" releases on data.gov.in.
+var pincodeColumns = []column{
+	{aliases: []string{"pincode", "pin", "pincodes"}, required: true},
+	{aliases: []string{"officename", "office", "postoffice"}},
+	{aliases: []string{"officetype"}},
+	{aliases: []string{"taluk", "tehsil", "subdistrict"}},
+	{aliases: []string{"district", "districtname"}},
+	{aliases: []string{"statename", "state"}},
+	{aliases: []string{"latitude", "lat"}},
+	{aliases: []string{"longitude", "long", "lng"}},
+}
+
+// officeSuffixes are the office type abbreviations that India Post appends to
+// office names ("Achampet S.O"); they are removed from Pincode.Office.
+var officeSuffixes = []string{" B.O", " S.O", " H.O", " B.O.", " S.O.", " H.O."}
+
+// Pincode is a post office record from a pincode directory. Names published in
+// uppercase are converted to title case.
+type Pincode struct {
+	// Pincode is the six-digit postal index number.
+	Pincode string `json:"pincode"`
+
+	// Office is the post office name without its office type suffix. Several
+	// offices can share a pincode.
+	Office string `json:"office,omitempty"`
+
+	// OfficeType is the office type as published, such as "BO", "PO" or "HO".
+	OfficeType string `json:"office_type,omitempty"`
+
+	// Taluk is the sub-district, when the dataset has one.
+	Taluk string `json:"taluk,omitempty"`
+
+	District string `json:"district"`
+	State    string `json:"state"`
+
+	// Latitude and Longitude locate the office; both are zero when the dataset
+	// does not have them ("NA" in the India Post directory).
+	Latitude  float64 `json:"latitude,omitempty"`
+	Longitude float64 `json:"longitude,omitempty"`
+}
+
+// LoadPincodes reads a pincode directory CSV file. See ReadPincodes.
+func LoadPincodes(path string) ([]Pincode, error) {
+	return loadFile(path, ReadPincodes)
+}
+
+// ReadPincodes reads pincode directory records from CSV data with a header row.
+// The pincode column is required; office, office type, taluk, district, state,
+// latitude and longitude columns are used when present. Records without a valid
+// six-digit pincode are an error.
+func ReadPincodes(r io.Reader) ([]Pincode, error) {
+	t, err := openTable(r, pincodeColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	var pincodes []Pincode
+	for {
+		values, err := t.next()
+		if errors.Is(err, io.EOF) {
+			return pincodes, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !validPincode(values[0]) {
+			return nil, fmt.Errorf("line %d: invalid pincode %q", t.line, values[0])
+		}
+
+		pincodes = append(pincodes, Pincode{
+			Pincode:    values[0],
+			Office:     officeName(values[1]),
+			OfficeType: values[2],
+			Taluk:      titleCase(notAvailable(values[3])),
+			District:   titleCase(values[4]),
+			State:      titleCase(values[5]),
+			Latitude:   coordinate(values[6]),
+			Longitude:  coordinate(values[7]),
+		})
+	}
+}
+
+// PincodeEntries returns one entry per post office, ready for IndexBatch.
+//
+// The ID is the pincode followed by the office name ("560001/Bangalore GPO"), or
+// the pincode alone for records without an office. The text holds the pincode and
+// every place name, so any of them matches under substring or n-gram matching,
+// and the display reads "560001 - Bangalore GPO, Bangalore Urban (Karnataka)".
+// Records repeating an earlier ID are skipped.
+func PincodeEntries(pincodes []Pincode) []autocomplete.Entry {
+	entries := make([]autocomplete.Entry, 0, len(pincodes))
+	seen := make(map[string]bool, len(pincodes))
+	for _, p := range pincodes {
+		id := joinNonEmpty("/", p.Pincode, p.Office)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		entries = append(entries, autocomplete.Entry{
+			ID:      id,
+			Text:    joinNonEmpty(" ", p.Pincode, p.Office, p.Taluk, p.District, p.State),
+			Display: p.Display(),
+		})
+	}
+	return entries
+}
+
+// Display formats the record for search results, as
+// "560001 - Bangalore GPO, Bangalore Urban (Karnataka)".
+func (p Pincode) Display() string {
+	display := p.Pincode + " - " + joinNonEmpty(", ", p.Office, p.District)
+	if p.State != "" {
+		display += " (" + p.State + ")"
+	}
+	return display
+}
+
+// officeName title-cases an office name and removes its office type suffix.
+func officeName(name string) string {
+	for _, suffix := range officeSuffixes {
+		if trimmed, ok := strings.CutSuffix(name, suffix); ok {
+			name = trimmed
+			break
+		}
+	}
+	return titleCase(strings.TrimSpace(name))
+}
+
+// validPincode reports whether s is a six-digit pincode, which never starts with 0.
+func validPincode(s string) bool {
+	if len(s) != 6 || s[0] == '0' {
+		return false
+	}
+	for i := range len(s) {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// coordinate parses a latitude or longitude, returning 0 for missing values.
+func coordinate(s string) float64 {
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+// notAvailable returns s, or "" when the dataset marks it as not available.
+func notAvailable(s string) string {
+	if strings.EqualFold(s, "NA") {
+		return ""
+	}
+	return s
+}
@@ -0,0 +1,43 @@
+package autocomplete
+
+import "context"
+
+// DisplayResolver returns display text for the given entry IDs at query time,
+// typically by looking them up in the application's own database.
+// IDs missing from the returned map keep the display stored at index time.
+type DisplayResolver func(ctx context.Context, ids []string) map[string]string
+
+// resolveDisplays replaces result display text with text from the configured
+// DisplayResolver, consulting the display cache first when enabled.
+func (a *autocompleteImpl) resolveDisplays(ctx context.Context, results []Result) {
+	resolver := a.config.Options.DisplayResolver
+	if resolver == nil || len(results) == 0 {
+		return
+	}
+
+	var missing []string
+	for i := range results {
+		if a.displays != nil {
+			if display, ok := a.displays.get(results[i].ID); ok {
+				results[i].Display = display
+				continue
+			}
+		}
+		missing = append(missing, results[i].ID)
+	}
+	if len(missing) == 0 {
+		return
+	}
+
+	resolved := resolver(ctx, missing)
+	for i := range results {
+		display, ok := resolved[results[i].ID]
+		if !ok {
+			continue
+		}
+		results[i].Display = display
+		if a.displays != nil {
+			a.displays.put(results[i].ID, display)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package autocomplete
+
+import "strings"
+
+// EmailOptions returns options for autocompleting users by email address or
+// username, e.g. in admin panels:
+//
+//	ac, err := autocomplete.New("memory", autocomplete.NewConfigWithOptions(nil, autocomplete.EmailOptions()))
+//
+// Entries match by prefix, ignoring case, from the start of the address and
+// from the start of each part separated by "@" or "." (EmailSegments), so
+// "priya.sharma@example.com" is found by "pri", "sha", and "exa" without
+// indexing every substring of the address.
+func EmailOptions() Options {
+	options := DefaultOptions()
+	options.MatchStrategy = MatchPrefix
+	options.Segmenter = EmailSegments
+	return options
+}
+
+// EmailSegments is a Segmenter for email addresses and usernames. A segment
+// begins after every "@" and ".", so the local part and the domain, and each
+// dot-separated part of them, are matched on their own.
+func EmailSegments(text string) []int {
+	var offsets []int
+	for i := 0; i < len(text)-1; i++ {
+		if strings.IndexByte("@.", text[i]) >= 0 && strings.IndexByte("@.", text[i+1]) < 0 {
+			offsets = append(offsets, i+1)
+		}
+	}
+	return offsets
+}
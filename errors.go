@@ -12,7 +12,8 @@ var (
 	// ErrQueryTooShort is returned when the query is shorter than MinPrefixLength.
 	ErrQueryTooShort = errors.New("query too short")
 
-	// ErrLimitExceeded is returned when the requested limit exceeds MaxLimit.
+	// ErrLimitExceeded is returned when the requested limit exceeds MaxLimit
+	// and Options.LimitPolicy is LimitError.
 	ErrLimitExceeded = errors.New("limit exceeded")
 
 	// ErrEmptyID is returned when an empty ID is provided to Index or Delete.
@@ -23,4 +24,117 @@ var (
 
 	// ErrEmptyDisplay is returned when empty display text is provided to Index.
 	ErrEmptyDisplay = errors.New("empty display")
+
+	// ErrTransactionsUnsupported is returned by IndexAtomic when the provider
+	// does not implement providers.TransactionalIndexer.
+	ErrTransactionsUnsupported = errors.New("provider does not support atomic indexing")
+
+	// ErrSnapshotsUnsupported is returned by OpenSnapshot when the provider
+	// does not implement providers.Snapshotter.
+	ErrSnapshotsUnsupported = errors.New("provider does not support snapshots")
+
+	// ErrNegativeOffset is returned when a negative offset is passed to Snapshot.Query.
+	ErrNegativeOffset = errors.New("negative offset")
+
+	// ErrMaintenanceUnsupported is returned by Maintain when the provider
+	// does not implement providers.Maintainer.
+	ErrMaintenanceUnsupported = errors.New("provider does not support maintenance")
+
+	// ErrCompactionUnsupported is returned by Compact when the provider does
+	// not implement providers.Compactor.
+	ErrCompactionUnsupported = errors.New("provider does not support compaction")
+
+	// ErrSelfTestFailed is returned by New when the startup self-test enabled
+	// with WithSelfTest fails. The error message describes what went wrong.
+	ErrSelfTestFailed = errors.New("autocomplete self-test failed")
+
+	// ErrMultiStrategyUnsupported is returned by New when Options.MatchStrategies is
+	// set but the provider cannot index entries under several strategies at once.
+	ErrMultiStrategyUnsupported = errors.New("provider does not support multiple match strategies")
+
+	// ErrStrategyUnsupported is returned by New when Options.MatchStrategy or
+	// Options.MatchStrategies names a strategy the provider cannot serve.
+	ErrStrategyUnsupported = errors.New("provider does not support match strategy")
+
+	// ErrStrategyNotIndexed is returned when a query selects a match strategy the
+	// namespace is not indexed under, and by New when Options.MatchStrategies
+	// does not list Options.MatchStrategy.
+	ErrStrategyNotIndexed = errors.New("match strategy not indexed")
+
+	// ErrCaseSensitivityNotIndexed is returned when QueryOptions.CaseSensitive
+	// asks for case handling the namespace is not indexed for: case-sensitive
+	// queries of a case-insensitive namespace, or case-insensitive queries of a
+	// case-sensitive one without Options.CaseInsensitiveFallback.
+	ErrCaseSensitivityNotIndexed = errors.New("case sensitivity not indexed")
+
+	// ErrTokenBudgetExceeded is returned when an entry's token expansion exceeds
+	// Options.MaxTokensPerEntry under TokenBudgetReject.
+	ErrTokenBudgetExceeded = errors.New("token budget exceeded")
+
+	// ErrRenormalizeUnsupported is returned by Renormalize when the provider
+	// does not implement providers.EntryLister.
+	ErrRenormalizeUnsupported = errors.New("provider cannot list entries for re-normalization")
+
+	// ErrWarmStartUnsupported is returned by New when WithWarmStart is used with
+	// a provider that does not implement providers.EntryLister.
+	ErrWarmStartUnsupported = errors.New("provider cannot list entries for warm start")
+
+	// ErrExportUnsupported is returned by ExportSince when the provider does
+	// not implement providers.ChangeLister.
+	ErrExportUnsupported = errors.New("provider does not record changes for export")
+
+	// ErrAliasesUnsupported is returned by AliasNamespace and RenameNamespace
+	// when the provider does not implement providers.NamespaceAliaser.
+	ErrAliasesUnsupported = errors.New("provider does not support namespace aliases")
+
+	// ErrInvalidOptions is returned by UpdateOptions when the patched options
+	// are inconsistent, such as a DefaultLimit above MaxLimit.
+	ErrInvalidOptions = errors.New("invalid options")
+
+	// ErrMetadataUnsupported is returned when an entry carries metadata, or a
+	// query filters by it, and the provider does not implement
+	// providers.MetadataSupporter.
+	ErrMetadataUnsupported = errors.New("provider does not store metadata")
+
+	// ErrInvalidScore is returned when an entry's score, whether set on the
+	// entry or computed by Options.Scorer, is NaN or infinite.
+	ErrInvalidScore = errors.New("invalid score")
+
+	// ErrScoreUpdateUnsupported is returned by UpdateScore when the provider
+	// does not implement providers.ScoreUpdater.
+	ErrScoreUpdateUnsupported = errors.New("provider does not support score updates")
+
+	// ErrScoreIncrementUnsupported is returned by IncrementScore when the
+	// provider does not implement providers.ScoreIncrementer.
+	ErrScoreIncrementUnsupported = errors.New("provider does not support score increments")
+
+	// ErrExistsUnsupported is returned by Exists when the provider implements
+	// neither providers.EntryChecker nor providers.ChangeDetector.
+	ErrExistsUnsupported = errors.New("provider cannot look up entries")
+
+	// ErrCountUnsupported is returned by Count when the provider does not
+	// implement providers.EntryCounter.
+	ErrCountUnsupported = errors.New("provider cannot count entries")
+
+	// ErrListUnsupported is returned by List when the provider does not
+	// implement providers.EntryLister.
+	ErrListUnsupported = errors.New("provider cannot list entries")
+
+	// ErrStatsUnsupported is returned by Stats when the provider does not
+	// implement providers.StatsReporter.
+	ErrStatsUnsupported = errors.New("provider cannot report index statistics")
+
+	// ErrGetUnsupported is returned by Get when the provider does not
+	// implement providers.EntryGetter.
+	ErrGetUnsupported = errors.New("provider cannot retrieve entries")
+
+	// ErrEntryNotFound is returned by Get when no entry has the given ID.
+	ErrEntryNotFound = errors.New("entry not found")
+
+	// ErrInvalidCursor is returned by QueryPage when the cursor was not
+	// returned by QueryPage for the same query, namespace, and strategy.
+	ErrInvalidCursor = errors.New("invalid cursor")
+
+	// ErrInvalidMetadata is returned when an entry's metadata is not valid JSON.
+	ErrInvalidMetadata = errors.New("invalid metadata")
 )
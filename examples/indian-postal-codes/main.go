@@ -2,46 +2,48 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"strings"
 	"time"
 
 	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/datasets"
 	"github.com/remiges-tech/autocomplete/providers/redis"
 	_ "github.com/remiges-tech/autocomplete/providers/redis"
 )
 
 const (
-	defaultSearchLimit        = 5
-	interactiveSearchLimit    = 10
-	demoDelayDuration         = 2 * time.Second
-	separatorLineLength       = 70
-	totalPostalCodesInDataset = 80
+	defaultSearchLimit     = 5
+	interactiveSearchLimit = 10
+	demoDelayDuration      = 2 * time.Second
+	separatorLineLength    = 70
+	defaultDatasetPath     = "data/pincodes.csv"
 )
 
-// PostalCode represents an Indian postal code with location information
-type PostalCode struct {
-	Pincode  string `json:"pincode"`
-	City     string `json:"city"`
-	District string `json:"district"`
-	State    string `json:"state"`
-}
-
 func main() {
+	datasetPath := flag.String("data", defaultDatasetPath, "pincode directory CSV to index, such as the All India Pincode Directory from data.gov.in")
+	flag.Parse()
+
 	ctx := context.Background()
 
 	fmt.Println("Indian Postal Code Autocomplete Example")
 	fmt.Println("======================================")
 	fmt.Println()
 
+	pincodes, err := datasets.LoadPincodes(*datasetPath)
+	if err != nil {
+		log.Fatalf("Failed to load pincode dataset: %v", err)
+	}
+
 	ac := createAutocomplete()
 	defer func() {
 		if err := ac.Close(); err != nil {
 			log.Printf("Failed to close autocomplete: %v", err)
 		}
 	}()
-	indexSampleData(ctx, ac)
+	indexSampleData(ctx, ac, pincodes)
 	searchExample(ctx, ac, "bangalore")
 	searchExample(ctx, ac, "400")
 	searchExample(ctx, ac, "pun")
@@ -49,7 +51,7 @@ func main() {
 	fmt.Println("\n\nWant to see more? Running full demo...")
 	time.Sleep(demoDelayDuration)
 
-	runFullDemo(ctx, ac)
+	runFullDemo(ctx, ac, pincodes)
 }
 
 func createAutocomplete() autocomplete.AutoComplete {
@@ -80,40 +82,27 @@ func createAutocompleteConfiguration(redisConfig redis.Config) autocomplete.Conf
 	return config
 }
 
-func indexSampleData(ctx context.Context, ac autocomplete.AutoComplete) {
-	sampleCodes := getSamplePostalCodes()
-
-	for _, pc := range sampleCodes {
-		id := pc.Pincode
-		displayText := createDisplayText(pc)
-
-		// Index each field separately with the same ID
-		fields := []string{pc.Pincode, pc.City, pc.District, pc.State}
-		for _, field := range fields {
-			err := ac.Index(ctx, id, field, displayText)
-			if err != nil {
-				log.Printf("Failed to index field '%s' for %s: %v", field, pc.Pincode, err)
-			}
+func indexSampleData(ctx context.Context, ac autocomplete.AutoComplete, pincodes []datasets.Pincode) {
+	// Index the head post office of each city, whose pincode ends in 001
+	var sample []datasets.Pincode
+	for _, p := range pincodes {
+		if strings.HasSuffix(p.Pincode, "001") {
+			sample = append(sample, p)
 		}
 	}
 
-	fmt.Printf("[OK] Step 2: Indexed %d sample postal codes\n", len(sampleCodes))
+	indexEntries(ac.IndexBatch(ctx, datasets.PincodeEntries(sample)))
+	fmt.Printf("[OK] Step 2: Indexed %d sample postal codes\n", len(sample))
 }
 
-func getSamplePostalCodes() []PostalCode {
-	return []PostalCode{
-		{Pincode: "560001", City: "Bangalore", District: "Bangalore Urban", State: "Karnataka"},
-		{Pincode: "400001", City: "Mumbai", District: "Mumbai City", State: "Maharashtra"},
-		{Pincode: "411001", City: "Pune", District: "Pune", State: "Maharashtra"},
-		{Pincode: "110001", City: "New Delhi", District: "Central Delhi", State: "Delhi"},
-		{Pincode: "600001", City: "Chennai", District: "Chennai", State: "Tamil Nadu"},
+func indexEntries(results []autocomplete.IndexResult) {
+	for _, result := range results {
+		if result.Err != nil {
+			log.Printf("Failed to index %s: %v", result.ID, result.Err)
+		}
 	}
 }
 
-func createDisplayText(pc PostalCode) string {
-	return fmt.Sprintf("%s - %s, %s (%s)", pc.Pincode, pc.City, pc.District, pc.State)
-}
-
 func searchExample(ctx context.Context, ac autocomplete.AutoComplete, query string) {
 	results, err := ac.Query(ctx, query, defaultSearchLimit)
 	if err != nil {
@@ -131,38 +120,24 @@ func printSearchResults(results []autocomplete.Result) {
 	}
 }
 
-func runFullDemo(ctx context.Context, ac autocomplete.AutoComplete) {
-	indexFullDataset(ctx, ac)
+func runFullDemo(ctx context.Context, ac autocomplete.AutoComplete, pincodes []datasets.Pincode) {
+	indexFullDataset(ctx, ac, pincodes)
 	demonstrateSearches(ctx, ac)
-	showStatistics()
+	showStatistics(len(pincodes))
 	runInteractiveMode(ctx, ac)
 }
 
-func indexFullDataset(ctx context.Context, ac autocomplete.AutoComplete) {
+func indexFullDataset(ctx context.Context, ac autocomplete.AutoComplete, pincodes []datasets.Pincode) {
 	fmt.Println("\nIndexing complete dataset...")
 
 	if err := ac.DeleteAll(ctx); err != nil {
 		log.Printf("Warning: failed to clear existing data: %v", err)
 	}
 
-	postalCodes := getFullPostalCodeDataset()
 	startTime := time.Now()
+	indexEntries(ac.IndexBatch(ctx, datasets.PincodeEntries(pincodes)))
 
-	for _, pc := range postalCodes {
-		id := pc.Pincode
-		displayText := createDisplayText(pc)
-
-		// Index each field separately with the same ID
-		fields := []string{pc.Pincode, pc.City, pc.District, pc.State}
-		for _, field := range fields {
-			err := ac.Index(ctx, id, field, displayText)
-			if err != nil {
-				log.Printf("Failed to index field '%s' for %s: %v", field, pc.Pincode, err)
-			}
-		}
-	}
-
-	fmt.Printf("Indexed %d postal codes in %v\n", len(postalCodes), time.Since(startTime))
+	fmt.Printf("Indexed %d postal codes in %v\n", len(pincodes), time.Since(startTime))
 }
 
 func demonstrateSearches(ctx context.Context, ac autocomplete.AutoComplete) {
@@ -207,9 +182,9 @@ func demonstrateSearches(ctx context.Context, ac autocomplete.AutoComplete) {
 	}
 }
 
-func showStatistics() {
+func showStatistics(total int) {
 	fmt.Printf("\n========== Statistics ==========\n")
-	fmt.Printf("Total postal codes indexed: %d\n", totalPostalCodesInDataset)
+	fmt.Printf("Total postal codes indexed: %d\n", total)
 	fmt.Printf("Storage provider: Redis\n")
 	fmt.Printf("Search strategy: Substring\n")
 	fmt.Printf("\nSubstring matching finds exact partial matches anywhere in the text.\n")
@@ -285,88 +260,3 @@ func displayFirstResultDetails(result autocomplete.Result) {
 	fmt.Printf("Display: %s\n", result.Display)
 	fmt.Printf("Score: %.2f\n", result.Score)
 }
-
-func getFullPostalCodeDataset() []PostalCode {
-	return []PostalCode{
-		{Pincode: "110001", City: "New Delhi", District: "Central Delhi", State: "Delhi"},
-		{Pincode: "110002", City: "New Delhi", District: "North Delhi", State: "Delhi"},
-		{Pincode: "110003", City: "New Delhi", District: "North Delhi", State: "Delhi"},
-		{Pincode: "110005", City: "New Delhi", District: "Central Delhi", State: "Delhi"},
-		{Pincode: "110006", City: "New Delhi", District: "Central Delhi", State: "Delhi"},
-		{Pincode: "110007", City: "New Delhi", District: "Central Delhi", State: "Delhi"},
-		{Pincode: "110008", City: "New Delhi", District: "Central Delhi", State: "Delhi"},
-		{Pincode: "110009", City: "New Delhi", District: "North Delhi", State: "Delhi"},
-		{Pincode: "110011", City: "New Delhi", District: "New Delhi", State: "Delhi"},
-		{Pincode: "110012", City: "New Delhi", District: "South Delhi", State: "Delhi"},
-		{Pincode: "400001", City: "Mumbai", District: "Mumbai City", State: "Maharashtra"},
-		{Pincode: "400002", City: "Mumbai", District: "Mumbai City", State: "Maharashtra"},
-		{Pincode: "400003", City: "Mumbai", District: "Mumbai City", State: "Maharashtra"},
-		{Pincode: "400004", City: "Mumbai", District: "Mumbai City", State: "Maharashtra"},
-		{Pincode: "400005", City: "Mumbai", District: "Mumbai City", State: "Maharashtra"},
-		{Pincode: "400006", City: "Mumbai", District: "Mumbai City", State: "Maharashtra"},
-		{Pincode: "400007", City: "Mumbai", District: "Mumbai City", State: "Maharashtra"},
-		{Pincode: "400008", City: "Mumbai", District: "Mumbai City", State: "Maharashtra"},
-		{Pincode: "400009", City: "Mumbai", District: "Mumbai City", State: "Maharashtra"},
-		{Pincode: "400010", City: "Mumbai", District: "Mumbai City", State: "Maharashtra"},
-		{Pincode: "560001", City: "Bangalore", District: "Bangalore Urban", State: "Karnataka"},
-		{Pincode: "560002", City: "Bangalore", District: "Bangalore Urban", State: "Karnataka"},
-		{Pincode: "560003", City: "Bangalore", District: "Bangalore Urban", State: "Karnataka"},
-		{Pincode: "560004", City: "Bangalore", District: "Bangalore Urban", State: "Karnataka"},
-		{Pincode: "560005", City: "Bangalore", District: "Bangalore Urban", State: "Karnataka"},
-		{Pincode: "560006", City: "Bangalore", District: "Bangalore Urban", State: "Karnataka"},
-		{Pincode: "560007", City: "Bangalore", District: "Bangalore Urban", State: "Karnataka"},
-		{Pincode: "560008", City: "Bangalore", District: "Bangalore Urban", State: "Karnataka"},
-		{Pincode: "560009", City: "Bangalore", District: "Bangalore Urban", State: "Karnataka"},
-		{Pincode: "560010", City: "Bangalore", District: "Bangalore Urban", State: "Karnataka"},
-		{Pincode: "600001", City: "Chennai", District: "Chennai", State: "Tamil Nadu"},
-		{Pincode: "600002", City: "Chennai", District: "Chennai", State: "Tamil Nadu"},
-		{Pincode: "600003", City: "Chennai", District: "Chennai", State: "Tamil Nadu"},
-		{Pincode: "600004", City: "Chennai", District: "Chennai", State: "Tamil Nadu"},
-		{Pincode: "600005", City: "Chennai", District: "Chennai", State: "Tamil Nadu"},
-		{Pincode: "600006", City: "Chennai", District: "Chennai", State: "Tamil Nadu"},
-		{Pincode: "600007", City: "Chennai", District: "Chennai", State: "Tamil Nadu"},
-		{Pincode: "600008", City: "Chennai", District: "Chennai", State: "Tamil Nadu"},
-		{Pincode: "600009", City: "Chennai", District: "Chennai", State: "Tamil Nadu"},
-		{Pincode: "600010", City: "Chennai", District: "Chennai", State: "Tamil Nadu"},
-		{Pincode: "700001", City: "Kolkata", District: "Kolkata", State: "West Bengal"},
-		{Pincode: "700002", City: "Kolkata", District: "Kolkata", State: "West Bengal"},
-		{Pincode: "700003", City: "Kolkata", District: "Kolkata", State: "West Bengal"},
-		{Pincode: "700004", City: "Kolkata", District: "Kolkata", State: "West Bengal"},
-		{Pincode: "700005", City: "Kolkata", District: "Kolkata", State: "West Bengal"},
-		{Pincode: "700006", City: "Kolkata", District: "Kolkata", State: "West Bengal"},
-		{Pincode: "700007", City: "Kolkata", District: "Kolkata", State: "West Bengal"},
-		{Pincode: "700008", City: "Kolkata", District: "Kolkata", State: "West Bengal"},
-		{Pincode: "700009", City: "Kolkata", District: "Kolkata", State: "West Bengal"},
-		{Pincode: "700010", City: "Kolkata", District: "Kolkata", State: "West Bengal"},
-		{Pincode: "500001", City: "Hyderabad", District: "Hyderabad", State: "Telangana"},
-		{Pincode: "500002", City: "Hyderabad", District: "Hyderabad", State: "Telangana"},
-		{Pincode: "500003", City: "Hyderabad", District: "Hyderabad", State: "Telangana"},
-		{Pincode: "500004", City: "Hyderabad", District: "Hyderabad", State: "Telangana"},
-		{Pincode: "500005", City: "Hyderabad", District: "Hyderabad", State: "Telangana"},
-		{Pincode: "500006", City: "Hyderabad", District: "Hyderabad", State: "Telangana"},
-		{Pincode: "500007", City: "Hyderabad", District: "Hyderabad", State: "Telangana"},
-		{Pincode: "500008", City: "Hyderabad", District: "Hyderabad", State: "Telangana"},
-		{Pincode: "500009", City: "Hyderabad", District: "Hyderabad", State: "Telangana"},
-		{Pincode: "500010", City: "Hyderabad", District: "Hyderabad", State: "Telangana"},
-		{Pincode: "380001", City: "Ahmedabad", District: "Ahmedabad", State: "Gujarat"},
-		{Pincode: "380002", City: "Ahmedabad", District: "Ahmedabad", State: "Gujarat"},
-		{Pincode: "380003", City: "Ahmedabad", District: "Ahmedabad", State: "Gujarat"},
-		{Pincode: "380004", City: "Ahmedabad", District: "Ahmedabad", State: "Gujarat"},
-		{Pincode: "380005", City: "Ahmedabad", District: "Ahmedabad", State: "Gujarat"},
-		{Pincode: "411001", City: "Pune", District: "Pune", State: "Maharashtra"},
-		{Pincode: "411002", City: "Pune", District: "Pune", State: "Maharashtra"},
-		{Pincode: "411003", City: "Pune", District: "Pune", State: "Maharashtra"},
-		{Pincode: "411004", City: "Pune", District: "Pune", State: "Maharashtra"},
-		{Pincode: "411005", City: "Pune", District: "Pune", State: "Maharashtra"},
-		{Pincode: "226001", City: "Lucknow", District: "Lucknow", State: "Uttar Pradesh"},
-		{Pincode: "226002", City: "Lucknow", District: "Lucknow", State: "Uttar Pradesh"},
-		{Pincode: "226003", City: "Lucknow", District: "Lucknow", State: "Uttar Pradesh"},
-		{Pincode: "226004", City: "Lucknow", District: "Lucknow", State: "Uttar Pradesh"},
-		{Pincode: "226005", City: "Lucknow", District: "Lucknow", State: "Uttar Pradesh"},
-		{Pincode: "302001", City: "Jaipur", District: "Jaipur", State: "Rajasthan"},
-		{Pincode: "302002", City: "Jaipur", District: "Jaipur", State: "Rajasthan"},
-		{Pincode: "302003", City: "Jaipur", District: "Jaipur", State: "Rajasthan"},
-		{Pincode: "302004", City: "Jaipur", District: "Jaipur", State: "Rajasthan"},
-		{Pincode: "302005", City: "Jaipur", District: "Jaipur", State: "Rajasthan"},
-	}
-}
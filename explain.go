@@ -0,0 +1,34 @@
+package autocomplete
+
+import "github.com/remiges-tech/autocomplete/providers"
+
+// ScoreExplanation breaks a Result's score down into the components it was
+// ranked by. Score is Provider multiplied by Boost.
+type ScoreExplanation struct {
+	// Provider is the score the provider ranked the entry by.
+	Provider float64 `json:"provider"`
+
+	// Entry is the part of Provider that is the entry's own score, given by
+	// Options.Scorer, IndexWithScore, or UpdateScore. It is zero when the
+	// provider does not break its scores down; the Redis and in-memory
+	// providers do.
+	Entry float64 `json:"entry,omitempty"`
+
+	// Proximity is the part of Provider from how closely the query's n-grams
+	// occur together in the entry, for Redis n-gram queries longer than the
+	// n-gram size.
+	Proximity float64 `json:"proximity,omitempty"`
+
+	// Boost is the RequestOptions.Boosts multiplier applied to Provider, or 1.
+	Boost float64 `json:"boost"`
+}
+
+// explainScore returns the explanation of a provider result's score before boosts.
+func explainScore(pr providers.ProviderResult) *ScoreExplanation {
+	explanation := &ScoreExplanation{Provider: pr.Score, Boost: 1}
+	if pr.Breakdown != nil {
+		explanation.Entry = pr.Breakdown.Entry
+		explanation.Proximity = pr.Breakdown.Proximity
+	}
+	return explanation
+}
@@ -0,0 +1,51 @@
+package autocomplete
+
+import (
+	"context"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// Delta holds the changes to a namespace returned by AutoComplete.ExportSince.
+type Delta struct {
+	// Reset is set when the delta is not relative to the given cursor, because
+	// it was empty, predates a DeleteAll, or cannot be resumed by the provider,
+	// e.g. after a restart of the in-memory provider. Entries then holds every
+	// entry, and a copy must drop the entries not listed.
+	Reset bool `json:"reset,omitempty"`
+
+	// Entries holds the entries indexed since the cursor, with their stored
+	// (normalized) text.
+	Entries []Entry `json:"entries"`
+
+	// Deleted holds the IDs of the entries deleted since the cursor.
+	Deleted []string `json:"deleted,omitempty"`
+
+	// Cursor is the checkpoint to pass to the next ExportSince call.
+	Cursor string `json:"cursor"`
+}
+
+// ExportSince returns the entries changed since cursor.
+// See AutoComplete.ExportSince for details.
+func (a *autocompleteImpl) ExportSince(ctx context.Context, cursor string) (Delta, error) {
+	lister, ok := a.provider.(providers.ChangeLister)
+	if !ok {
+		return Delta{}, ErrExportUnsupported
+	}
+
+	changes, err := lister.ListChanges(ctx, a.config.Options.Namespace, cursor)
+	if err != nil {
+		return Delta{}, err
+	}
+
+	delta := Delta{
+		Reset:   changes.Reset,
+		Entries: make([]Entry, len(changes.Entries)),
+		Deleted: changes.Deleted,
+		Cursor:  changes.Cursor,
+	}
+	for i, e := range changes.Entries {
+		delta.Entries[i] = Entry{ID: e.ID, Text: e.Text, Display: e.Display, Metadata: metadataPayload(e.Metadata)}
+	}
+	return delta, nil
+}
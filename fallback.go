@@ -0,0 +1,89 @@
+package autocomplete
+
+import (
+	"context"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// foldedNamespaceSuffix names the namespace holding the case-folded copy of a
+// case-sensitive namespace when Options.CaseInsensitiveFallback is set.
+const foldedNamespaceSuffix = ":folded"
+
+// caseFallback reports whether entries are also kept case-folded for
+// case-insensitive fallback queries.
+func (a *autocompleteImpl) caseFallback() bool {
+	return a.config.Options.CaseSensitive && a.config.Options.CaseInsensitiveFallback
+}
+
+// foldedQuery reports whether a query of a case-sensitive namespace was made
+// case-insensitive with QueryOptions.CaseSensitive, and so is answered from
+// the case-folded copy of the entries.
+//
+//nolint:gocritic // hugeParam: options is passed by value to match the Provider interface
+func (a *autocompleteImpl) foldedQuery(options providers.QueryOptions) bool {
+	return a.caseFallback() && !options.CaseSensitive
+}
+
+// foldedNamespace returns the namespace holding the case-folded copy of the entries.
+func (a *autocompleteImpl) foldedNamespace() string {
+	return a.config.Options.Namespace + foldedNamespaceSuffix
+}
+
+// foldedOptions returns index options for the case-folded copy of an entry.
+//
+//nolint:gocritic // hugeParam: options is passed by value to match the Provider interface
+func foldedOptions(options providers.IndexOptions) providers.IndexOptions {
+	options.CaseSensitive = false
+	return options
+}
+
+// indexFolded writes the case-folded copy of an entry, if the fallback is enabled.
+//
+//nolint:gocritic // hugeParam: options is passed by value to match the Provider interface
+func (a *autocompleteImpl) indexFolded(ctx context.Context, entry Entry, options providers.IndexOptions) error {
+	if !a.caseFallback() {
+		return nil
+	}
+	return a.provider.Index(ctx, a.foldedNamespace(), entry.ID, entry.Text, entry.Display, foldedOptions(options))
+}
+
+// indexFoldedAtomic writes the case-folded copies of entries written by IndexAtomic,
+// if the fallback is enabled. The copies are written in a second atomic unit.
+func (a *autocompleteImpl) indexFoldedAtomic(
+	ctx context.Context, indexer providers.TransactionalIndexer, entries []providers.IndexEntry,
+) error {
+	if !a.caseFallback() {
+		return nil
+	}
+	folded := make([]providers.IndexEntry, len(entries))
+	for i, entry := range entries {
+		folded[i] = entry
+		folded[i].Options = foldedOptions(entry.Options)
+	}
+	return indexer.IndexAtomic(ctx, a.foldedNamespace(), folded)
+}
+
+// queryWithFallback runs a query against the namespace queried with ctx and,
+// when it returns nothing and the fallback is enabled, repeats it
+// case-insensitively against the case-folded copy of the entries, flagging
+// those results with Fallback. Queries made case-insensitive with
+// QueryOptions.CaseSensitive go to the copy directly, unflagged.
+//
+//nolint:gocritic // hugeParam: options is passed by value to match the Provider interface
+func (a *autocompleteImpl) queryWithFallback(ctx context.Context, query string, options providers.QueryOptions) ([]Result, error) {
+	namespace := a.queryNamespace(ctx)
+	if a.foldedQuery(options) {
+		return a.queryResults(ctx, namespace+foldedNamespaceSuffix, query, options, false)
+	}
+	results, err := a.queryResults(ctx, namespace, query, options, false)
+	if err == nil {
+		results, err = a.appendSegmentMatches(ctx, namespace, query, options, results)
+	}
+	if err != nil || len(results) > 0 || !a.caseFallback() {
+		return results, err
+	}
+
+	options.CaseSensitive = false
+	return a.queryResults(ctx, namespace+foldedNamespaceSuffix, query, options, true)
+}
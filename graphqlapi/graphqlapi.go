@@ -0,0 +1,91 @@
+// Package graphqlapi exposes AutoComplete instances through a GraphQL
+// suggestions field. It does not depend on a GraphQL library: Schema holds the
+// type definitions to add to a service's schema, and Resolver.Suggestions
+// resolves the field with the argument and result types gqlgen binds to.
+//
+// With gqlgen, bind the Suggestion type to autocomplete.Result in gqlgen.yml
+// and delegate the generated query resolver to Resolver.Suggestions:
+//
+//	models:
+//	  Suggestion:
+//	    model: github.com/remiges-tech/autocomplete.Result
+//
+// With other libraries, call Resolver.Suggestions from the field's resolve
+// function.
+package graphqlapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/remiges-tech/autocomplete"
+)
+
+// Schema defines the Suggestion type and the suggestions query field. It
+// extends the Query type, which the service's own schema declares.
+const Schema = `"A suggestion for the text typed so far."
+type Suggestion {
+  id: ID!
+  display: String!
+  score: Float!
+  "True when the suggestion comes from the case-insensitive fallback."
+  fallback: Boolean!
+}
+
+extend type Query {
+  "Suggestions matching query, best first. Omitted arguments use the namespace's defaults."
+  suggestions(query: String!, limit: Int, namespace: String): [Suggestion!]!
+}
+`
+
+// ErrUnknownNamespace is returned when a query names a namespace the resolver
+// does not serve.
+var ErrUnknownNamespace = errors.New("unknown namespace")
+
+// Resolver resolves the suggestions field from AutoComplete instances, one per
+// namespace a query may name.
+type Resolver struct {
+	namespaces       map[string]autocomplete.AutoComplete
+	defaultNamespace string
+}
+
+// NewResolver creates a Resolver serving the given instances by namespace name.
+// Queries that omit the namespace use defaultNamespace.
+func NewResolver(namespaces map[string]autocomplete.AutoComplete, defaultNamespace string) *Resolver {
+	return &Resolver{namespaces: namespaces, defaultNamespace: defaultNamespace}
+}
+
+// NewSingleResolver creates a Resolver serving one instance, for queries that
+// omit the namespace or name it.
+func NewSingleResolver(namespace string, ac autocomplete.AutoComplete) *Resolver {
+	return NewResolver(map[string]autocomplete.AutoComplete{namespace: ac}, namespace)
+}
+
+// Suggestions returns the entries matching query in a namespace. A nil limit
+// uses the namespace's Options.DefaultLimit and a nil namespace the resolver's
+// default namespace.
+func (r *Resolver) Suggestions(ctx context.Context, query string, limit *int, namespace *string) ([]autocomplete.Result, error) {
+	name := r.defaultNamespace
+	if namespace != nil {
+		name = *namespace
+	}
+	ac, ok := r.namespaces[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownNamespace, name)
+	}
+
+	var n int
+	if limit != nil {
+		n = *limit
+	}
+	results, err := ac.Query(ctx, query, n)
+	if err != nil {
+		return nil, err
+	}
+	if results == nil {
+		// The field is a non-null list.
+		results = []autocomplete.Result{}
+	}
+	return results, nil
+}
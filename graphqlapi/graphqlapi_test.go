@@ -0,0 +1,73 @@
+package graphqlapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers/memory"
+)
+
+func newTestAutoComplete(t *testing.T, namespace string, entries ...autocomplete.Entry) autocomplete.AutoComplete {
+	t.Helper()
+	config := autocomplete.NewConfig(memory.Config{})
+	config.Options.Namespace = namespace
+	ac, err := autocomplete.New("memory", config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = ac.Close() })
+	for _, result := range ac.IndexBatch(context.Background(), entries) {
+		if result.Err != nil {
+			t.Fatalf("IndexBatch() error = %v", result.Err)
+		}
+	}
+	return ac
+}
+
+func TestResolver_Suggestions(t *testing.T) {
+	resolver := NewResolver(map[string]autocomplete.AutoComplete{
+		"cities": newTestAutoComplete(t, "cities",
+			autocomplete.Entry{ID: "1", Text: "Mumbai", Display: "Mumbai, MH"},
+			autocomplete.Entry{ID: "2", Text: "Navi Mumbai", Display: "Navi Mumbai"}),
+		"states": newTestAutoComplete(t, "states",
+			autocomplete.Entry{ID: "MH", Text: "Maharashtra", Display: "Maharashtra"}),
+	}, "cities")
+	ctx := context.Background()
+	one, states, unknown := 1, "states", "districts"
+
+	tests := []struct {
+		name      string
+		query     string
+		limit     *int
+		namespace *string
+		wantIDs   []string
+		wantErr   error
+	}{
+		{"default namespace", "mum", nil, nil, []string{"1", "2"}, nil},
+		{"limit", "mum", &one, nil, []string{"1"}, nil},
+		{"named namespace", "maha", nil, &states, []string{"MH"}, nil},
+		{"no matches", "pune", nil, nil, []string{}, nil},
+		{"unknown namespace", "mum", nil, &unknown, nil, ErrUnknownNamespace},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := resolver.Suggestions(ctx, tt.query, tt.limit, tt.namespace)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Suggestions() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if results == nil || len(results) != len(tt.wantIDs) {
+				t.Fatalf("Suggestions() = %+v, want IDs %v", results, tt.wantIDs)
+			}
+			for i, id := range tt.wantIDs {
+				if results[i].ID != id {
+					t.Errorf("result %d ID = %q, want %q", i, results[i].ID, id)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package autocomplete
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"strconv"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// contentHash fingerprints everything that affects how an entry is stored:
+// the text, the display, and the options that control tokenization.
+// folded reports whether a case-folded copy is kept for Options.CaseInsensitiveFallback.
+// Fields are length-prefixed so that different splits never collide.
+func contentHash(text, display string, options providers.IndexOptions, folded bool) string {
+	h := sha256.New()
+	writeHashField(h, text)
+	writeHashField(h, display)
+	writeHashField(h, strconv.Itoa(int(options.MatchStrategy)))
+	writeHashField(h, strconv.Itoa(options.NGramSize))
+	writeHashField(h, strconv.FormatBool(options.CaseSensitive))
+	writeHashField(h, strconv.FormatFloat(options.Score, 'g', -1, 64))
+	// Only hashed when set, so hashes stored before multi-strategy indexing stay valid
+	for _, strategy := range options.MatchStrategies {
+		writeHashField(h, strconv.Itoa(int(strategy)))
+	}
+	// Likewise only hashed when set, so enabling the fallback rewrites unchanged entries
+	if folded {
+		writeHashField(h, "folded")
+	}
+	if options.Metadata != "" {
+		writeHashField(h, "metadata")
+		writeHashField(h, options.Metadata)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func writeHashField(h hash.Hash, field string) {
+	var length [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(length[:], uint64(len(field)))
+	h.Write(length[:n])
+	h.Write([]byte(field))
+}
@@ -0,0 +1,93 @@
+package autocomplete
+
+import (
+	"context"
+	"time"
+)
+
+// Hooks are optional callbacks invoked as the index changes or queries
+// degrade, for metrics, logging, and alerting. Callbacks run synchronously on the calling goroutine,
+// so they should return quickly. Nil callbacks are skipped.
+type Hooks struct {
+	// OnIndex is called after each entry is written, with its token fan-out.
+	// Entries skipped as unchanged are not reported.
+	OnIndex func(ctx context.Context, metrics IndexMetrics)
+
+	// OnTruncate is called when an entry's text is truncated to fit
+	// Options.MaxTokensPerEntry under TokenBudgetTruncate.
+	OnTruncate func(ctx context.Context, event TruncateEvent)
+
+	// OnStale is called when a failed query is answered with stale results
+	// kept for Options.StaleCacheSize, with the provider's error.
+	OnStale func(ctx context.Context, event StaleEvent)
+
+	// OnMutation is called after each successful Index, IndexBatch entry,
+	// BatchIndex, IndexAtomic, Delete, DeleteMany, and DeleteAll call, with the write as the caller made
+	// it. Writes made by Renormalize are not reported.
+	OnMutation func(ctx context.Context, mutation Mutation)
+}
+
+// StaleEvent describes a query answered with stale results because the provider failed.
+type StaleEvent struct {
+	// Namespace is the namespace that was queried.
+	Namespace string
+
+	// Query is the normalized query.
+	Query string
+
+	// Err is the provider's error.
+	Err error
+
+	// Age is how long ago the results were fetched.
+	Age time.Duration
+}
+
+// TruncateEvent describes an entry whose text was truncated to fit the token budget.
+type TruncateEvent struct {
+	// Namespace is the namespace the entry was written to.
+	Namespace string
+
+	// ID is the ID of the truncated entry.
+	ID string
+
+	// OriginalLength and IndexedLength are the text lengths in bytes before and after truncation.
+	OriginalLength int
+	IndexedLength  int
+
+	// OriginalTokens and IndexedTokens are the token counts before and after truncation.
+	OriginalTokens int
+	IndexedTokens  int
+}
+
+// IndexMetrics describes the token fan-out of a single indexed entry.
+// Token counts follow the configured match strategy and are estimates for
+// backends that tokenize with their own analyzers.
+type IndexMetrics struct {
+	// Namespace is the namespace the entry was written to.
+	Namespace string
+
+	// ID is the ID of the indexed entry.
+	ID string
+
+	// Tokens is the number of tokens generated for the entry.
+	Tokens int
+
+	// NamespaceTokens is the running total of tokens generated for the namespace
+	// by this instance since it was created.
+	NamespaceTokens int64
+}
+
+// reportIndexed records an entry's token fan-out and invokes the OnIndex hook.
+func (a *autocompleteImpl) reportIndexed(ctx context.Context, entry Entry) {
+	tokens := tokenCount(entry.Text, a.config.Options)
+	total := a.namespaceTokens.Add(int64(tokens))
+
+	if a.config.Options.Hooks.OnIndex != nil {
+		a.config.Options.Hooks.OnIndex(ctx, IndexMetrics{
+			Namespace:       a.config.Options.Namespace,
+			ID:              entry.ID,
+			Tokens:          tokens,
+			NamespaceTokens: total,
+		})
+	}
+}
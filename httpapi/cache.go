@@ -0,0 +1,159 @@
+package httpapi
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/remiges-tech/autocomplete"
+)
+
+// HandlerOption configures optional behavior of NewHandler.
+type HandlerOption func(*handler)
+
+// WithCache makes NewHandler send Cache-Control and ETag headers with suggest
+// responses, so that browsers and CDNs can reuse responses to hot queries, and
+// answer requests whose If-None-Match holds the current ETag with 304 Not
+// Modified without running the query.
+func WithCache(config CacheConfig) HandlerOption {
+	return func(h *handler) {
+		if config.Versions == nil {
+			config.Versions = NewVersions()
+		}
+		h.cache = &config
+	}
+}
+
+// CacheConfig configures the caching headers sent by WithCache.
+type CacheConfig struct {
+	// Namespace is the Options.Namespace of the AutoComplete served. Requests
+	// are cached under it unless their context carries a
+	// RequestOptions.Namespace, e.g. set by tenant middleware.
+	Namespace string
+
+	// MaxAge is how long responses may be reused without revalidation, sent
+	// as Cache-Control max-age. Zero sends no-cache, so that clients
+	// revalidate every reuse with the ETag. Suggestions may lag writes by up
+	// to MaxAge.
+	MaxAge time.Duration
+
+	// MaxAgeByNamespace overrides MaxAge for the namespaces it lists, e.g. a
+	// longer one for a country list that rarely changes.
+	MaxAgeByNamespace map[string]time.Duration
+
+	// Private marks responses private, so that only browsers cache them and
+	// shared caches such as CDNs do not, for namespaces chosen per user.
+	Private bool
+
+	// Versions supplies the namespace versions ETags are derived from. Nil
+	// uses versions bumped only by writes made through the handler; pass
+	// Versions wired to Hooks.OnMutation to cover every write the process
+	// makes.
+	Versions *Versions
+}
+
+// maxAge returns the max-age of responses for namespace.
+func (c *CacheConfig) maxAge(namespace string) time.Duration {
+	if maxAge, ok := c.MaxAgeByNamespace[namespace]; ok {
+		return maxAge
+	}
+	return c.MaxAge
+}
+
+// namespace returns the namespace a request made with ctx queries.
+func (c *CacheConfig) namespace(ctx context.Context) string {
+	if namespace := autocomplete.RequestOptionsFromContext(ctx).Namespace; namespace != "" {
+		return namespace
+	}
+	return c.Namespace
+}
+
+// setHeaders sets the Cache-Control and ETag headers of the suggest response
+// to r and reports whether the client already holds it.
+func (c *CacheConfig) setHeaders(w http.ResponseWriter, r *http.Request) (notModified bool) {
+	namespace := c.namespace(r.Context())
+	h := sha256.New()
+	for _, field := range []string{c.Versions.Version(namespace), namespace, r.URL.Query().Encode()} {
+		h.Write([]byte(strconv.Itoa(len(field)) + ":" + field))
+	}
+	etag := `"` + hex.EncodeToString(h.Sum(nil)[:12]) + `"`
+
+	scope := "public"
+	if c.Private {
+		scope = "private"
+	}
+	cacheControl := scope + ", no-cache"
+	if maxAge := c.maxAge(namespace); maxAge > 0 {
+		cacheControl = scope + ", max-age=" + strconv.Itoa(int(maxAge/time.Second))
+	}
+	w.Header().Set("Cache-Control", cacheControl)
+	w.Header().Set("ETag", etag)
+	return etagMatches(r.Header.Get("If-None-Match"), etag)
+}
+
+// etagMatches reports whether an If-None-Match header lists etag, comparing
+// weakly as RFC 9110 requires.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// Versions counts the writes to each namespace, so that the ETags of suggest
+// responses change whenever their namespace is written. Counts are kept in
+// process and start at a random instance tag, so ETags from processes that
+// saw different writes never match; writes made by other processes only show
+// once cached responses reach their max-age. Safe for concurrent use.
+type Versions struct {
+	instance string
+
+	mu     sync.Mutex
+	counts map[string]uint64
+}
+
+// NewVersions creates Versions with every namespace at its first version. To
+// count every write the process makes, set OnMutation as Hooks.OnMutation:
+//
+//	versions := httpapi.NewVersions()
+//	config.Options.Hooks.OnMutation = versions.OnMutation
+//	ac, err := autocomplete.New("redis", config)
+//	handler := httpapi.NewHandler(ac, httpapi.WithCache(httpapi.CacheConfig{
+//		Namespace: config.Options.Namespace,
+//		MaxAge:    time.Minute,
+//		Versions:  versions,
+//	}))
+func NewVersions() *Versions {
+	var instance [8]byte
+	_, _ = rand.Read(instance[:])
+	return &Versions{instance: hex.EncodeToString(instance[:]), counts: make(map[string]uint64)}
+}
+
+// Bump moves namespace to its next version.
+func (v *Versions) Bump(namespace string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.counts[namespace]++
+}
+
+// OnMutation bumps the namespace of a write; it has the signature of
+// Hooks.OnMutation.
+func (v *Versions) OnMutation(ctx context.Context, mutation autocomplete.Mutation) {
+	v.Bump(mutation.Namespace)
+}
+
+// Version returns the current version of namespace.
+func (v *Versions) Version(namespace string) string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.instance + "." + strconv.FormatUint(v.counts[namespace], 10)
+}
@@ -0,0 +1,249 @@
+package httpapi
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+)
+
+// Compression defaults, used for zero CompressionConfig fields.
+const (
+	defaultCompressionMinSize = 1024
+	defaultBrotliLevel        = 4
+)
+
+// Content codings NewHandler can respond with.
+const (
+	encodingBrotli = "br"
+	encodingGzip   = "gzip"
+)
+
+// CompressionConfig configures the response compression enabled by
+// WithCompression.
+type CompressionConfig struct {
+	// MinSize is the body size in bytes from which responses are compressed;
+	// smaller ones, such as errors and short suggestion lists, cost more to
+	// compress than they save. Zero uses 1024.
+	MinSize int
+
+	// GzipLevel is the gzip compression level, from gzip.BestSpeed to
+	// gzip.BestCompression. Zero, or a level outside that range, uses
+	// gzip.DefaultCompression.
+	GzipLevel int
+
+	// BrotliLevel is the Brotli compression level, from 1 to 11. Zero, or a
+	// level outside that range, uses 4, which suits responses compressed on
+	// every request; higher levels are much slower for little gain.
+	BrotliLevel int
+}
+
+// WithCompression makes NewHandler compress response bodies with Brotli or
+// gzip, whichever the client prefers in Accept-Encoding (Brotli on a tie).
+// Bodies are compressed as they are written, so suggestions are streamed
+// rather than held in memory whole. Compressed responses carry a weak ETag,
+// as their bytes differ from the uncompressed ones, and every response varies
+// by Accept-Encoding, so that caches keep the encodings apart.
+func WithCompression(config CompressionConfig) HandlerOption {
+	return func(h *handler) {
+		h.compressor = newCompressor(config)
+	}
+}
+
+// compressor holds pooled encoders for the configured levels.
+type compressor struct {
+	minSize int
+	gzip    sync.Pool
+	brotli  sync.Pool
+}
+
+// newCompressor returns a compressor for config, applying the defaults.
+func newCompressor(config CompressionConfig) *compressor {
+	c := &compressor{minSize: config.MinSize}
+	if c.minSize <= 0 {
+		c.minSize = defaultCompressionMinSize
+	}
+	gzipLevel := config.GzipLevel
+	if gzipLevel < gzip.BestSpeed || gzipLevel > gzip.BestCompression {
+		gzipLevel = gzip.DefaultCompression
+	}
+	brotliLevel := config.BrotliLevel
+	if brotliLevel < 1 || brotliLevel > brotli.BestCompression {
+		brotliLevel = defaultBrotliLevel
+	}
+	c.gzip.New = func() any {
+		w, _ := gzip.NewWriterLevel(io.Discard, gzipLevel)
+		return w
+	}
+	c.brotli.New = func() any {
+		return brotli.NewWriterLevel(io.Discard, brotliLevel)
+	}
+	return c
+}
+
+// wrap returns next with its responses compressed as negotiated.
+func (c *compressor) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		cw := &compressWriter{ResponseWriter: w, compressor: c, encoding: encoding, status: http.StatusOK}
+		defer cw.close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// negotiateEncoding returns the coding preferred by an Accept-Encoding header
+// among Brotli and gzip, or "" if the client accepts neither.
+func negotiateEncoding(header string) string {
+	best, bestQ := "", 0.0
+	for _, offer := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(offer, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != encodingBrotli && coding != encodingGzip && coding != "*" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+		// q=0 marks a coding as not acceptable
+		if q <= 0 {
+			continue
+		}
+		if coding == "*" {
+			coding = encodingBrotli
+		}
+		if q > bestQ || (q == bestQ && coding == encodingBrotli) {
+			best, bestQ = coding, q
+		}
+	}
+	return best
+}
+
+// compressWriter compresses a response once its body reaches minSize bytes,
+// holding back the status and the body written until then. A response that
+// ends below minSize is sent uncompressed.
+type compressWriter struct {
+	http.ResponseWriter
+	compressor *compressor
+	encoding   string
+
+	status      int
+	wroteHeader bool
+	buf         []byte
+
+	// encoder is set once the response is being compressed, and passthrough
+	// once it is known to be sent as is.
+	encoder     io.WriteCloser
+	passthrough bool
+}
+
+// WriteHeader holds back the status until the body shows whether the
+// response is compressed.
+func (w *compressWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.status = status
+	w.wroteHeader = true
+	// Responses without a body are sent as they are
+	if status == http.StatusNoContent || status == http.StatusNotModified || status < http.StatusOK {
+		w.passthrough = true
+		w.ResponseWriter.WriteHeader(status)
+	}
+}
+
+// Write compresses p, or holds it back until minSize bytes were written.
+func (w *compressWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	switch {
+	case w.encoder != nil:
+		return w.encoder.Write(p)
+	case w.passthrough:
+		return w.ResponseWriter.Write(p)
+	}
+	w.buf = append(w.buf, p...)
+	if len(w.buf) < w.compressor.minSize {
+		return len(p), nil
+	}
+	if err := w.startCompression(); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// startCompression sends the compressed headers and the body held back so far.
+func (w *compressWriter) startCompression() error {
+	header := w.Header()
+	header.Set("Content-Encoding", w.encoding)
+	header.Del("Content-Length")
+	if etag := header.Get("ETag"); strings.HasPrefix(etag, `"`) {
+		header.Set("ETag", "W/"+etag)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+
+	if w.encoding == encodingBrotli {
+		encoder := w.compressor.brotli.Get().(*brotli.Writer)
+		encoder.Reset(w.ResponseWriter)
+		w.encoder = encoder
+	} else {
+		encoder := w.compressor.gzip.Get().(*gzip.Writer)
+		encoder.Reset(w.ResponseWriter)
+		w.encoder = encoder
+	}
+	_, err := w.encoder.Write(w.buf)
+	w.buf = nil
+	return err
+}
+
+// Flush sends what was written so far, compressing it if it has not been
+// decided yet whether to.
+func (w *compressWriter) Flush() {
+	if w.encoder == nil && !w.passthrough && len(w.buf) > 0 {
+		_ = w.startCompression()
+	}
+	if flusher, ok := w.encoder.(interface{ Flush() error }); ok {
+		_ = flusher.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the underlying ResponseWriter, for http.ResponseController.
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// close finishes the response: it ends the compressed stream and returns the
+// encoder to its pool, or sends a response that stayed below minSize as is.
+func (w *compressWriter) close() {
+	switch {
+	case w.encoder != nil:
+		_ = w.encoder.Close()
+		// Pooled encoders must not hold on to the response
+		switch encoder := w.encoder.(type) {
+		case *brotli.Writer:
+			encoder.Reset(io.Discard)
+			w.compressor.brotli.Put(encoder)
+		case *gzip.Writer:
+			encoder.Reset(io.Discard)
+			w.compressor.gzip.Put(encoder)
+		}
+	case !w.passthrough:
+		w.Header().Set("Content-Length", strconv.Itoa(len(w.buf)))
+		w.ResponseWriter.WriteHeader(w.status)
+		_, _ = w.ResponseWriter.Write(w.buf)
+	}
+}
@@ -0,0 +1,73 @@
+// Package echoapi serves the httpapi endpoints from an Echo router:
+//
+//	echoapi.Register(e.Group("/autocomplete"), ac)
+package echoapi
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/httpapi"
+)
+
+// Router is implemented by *echo.Echo and *echo.Group.
+type Router interface {
+	GET(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	POST(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	DELETE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+}
+
+// Register adds the suggest and entries endpoints to router. Middleware, such as
+// authentication, wraps each endpoint in addition to the router's own middleware.
+// Errors are returned as *echo.HTTPError, so the Echo instance's HTTPErrorHandler
+// renders them; the default handler writes {"message": "..."}.
+func Register(router Router, ac autocomplete.AutoComplete, middleware ...echo.MiddlewareFunc) {
+	h := &handler{service: httpapi.NewService(ac)}
+	router.GET(httpapi.SuggestPath, h.suggest, middleware...)
+	router.POST(httpapi.EntriesPath, h.index, middleware...)
+	router.DELETE(httpapi.EntriesPath+"/:id", h.delete, middleware...)
+}
+
+type handler struct {
+	service *httpapi.Service
+}
+
+func (h *handler) suggest(c echo.Context) error {
+	var request httpapi.SuggestRequest
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &request); err != nil {
+		return httpError(httpapi.InvalidRequest(err))
+	}
+
+	response, err := h.service.Suggest(c.Request().Context(), request)
+	if err != nil {
+		return httpError(err)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) index(c echo.Context) error {
+	var request httpapi.IndexRequest
+	if err := c.Bind(&request); err != nil {
+		return httpError(httpapi.InvalidRequest(err))
+	}
+
+	response, err := h.service.Index(c.Request().Context(), request)
+	if err != nil {
+		return httpError(err)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) delete(c echo.Context) error {
+	if err := h.service.Delete(c.Request().Context(), c.Param("id")); err != nil {
+		return httpError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// httpError converts a service error to an Echo error with the matching status.
+func httpError(err error) *echo.HTTPError {
+	return echo.NewHTTPError(httpapi.StatusCode(err), err.Error()).SetInternal(err)
+}
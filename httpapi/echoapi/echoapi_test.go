@@ -0,0 +1,55 @@
+package echoapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers/memory"
+)
+
+func TestRegister(t *testing.T) {
+	ac, err := autocomplete.New("memory", autocomplete.NewConfig(memory.Config{}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer ac.Close()
+
+	router := echo.New()
+	calls := 0
+	Register(router.Group("/autocomplete"), ac, func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			calls++
+			return next(c)
+		}
+	})
+
+	tests := []struct {
+		method, target, body string
+		wantStatus           int
+		wantBody             string
+	}{
+		{http.MethodPost, "/autocomplete/entries", `{"entries": [{"id": "1", "text": "Mumbai", "display": "Mumbai, MH"}]}`, http.StatusOK, `"status":"created"`},
+		{http.MethodGet, "/autocomplete/suggest?q=mum&limit=5", "", http.StatusOK, `"display":"Mumbai, MH"`},
+		{http.MethodGet, "/autocomplete/suggest?q=mum&limit=x", "", http.StatusBadRequest, `"message"`},
+		{http.MethodDelete, "/autocomplete/entries/1", "", http.StatusNoContent, ""},
+		{http.MethodGet, "/autocomplete/suggest?q=mum", "", http.StatusOK, `{"results":[]}`},
+	}
+	for _, tt := range tests {
+		request := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+		request.Header.Set("Content-Type", "application/json")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		if recorder.Code != tt.wantStatus || !strings.Contains(recorder.Body.String(), tt.wantBody) {
+			t.Errorf("%s %s = %d %s, want %d containing %s", tt.method, tt.target, recorder.Code, recorder.Body, tt.wantStatus, tt.wantBody)
+		}
+	}
+	if calls != len(tests) {
+		t.Errorf("middleware ran %d times, want %d", calls, len(tests))
+	}
+}
@@ -0,0 +1,64 @@
+// Package fiberapi serves the httpapi endpoints from a Fiber router:
+//
+//	fiberapi.Register(app.Group("/autocomplete"), ac)
+package fiberapi
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/httpapi"
+)
+
+// Register adds the suggest and entries endpoints to router, which can be the app
+// or a group. Middleware, such as authentication, runs before each endpoint in
+// addition to the middleware already used by router.
+func Register(router fiber.Router, ac autocomplete.AutoComplete, middleware ...fiber.Handler) {
+	h := &handler{service: httpapi.NewService(ac)}
+	router.Get(httpapi.SuggestPath, append(middleware, h.suggest)...)
+	router.Post(httpapi.EntriesPath, append(middleware, h.index)...)
+	router.Delete(httpapi.EntriesPath+"/:id", append(middleware, h.delete)...)
+}
+
+type handler struct {
+	service *httpapi.Service
+}
+
+func (h *handler) suggest(c *fiber.Ctx) error {
+	var request httpapi.SuggestRequest
+	if err := c.QueryParser(&request); err != nil {
+		return respondError(c, httpapi.InvalidRequest(err))
+	}
+
+	response, err := h.service.Suggest(c.UserContext(), request)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(response)
+}
+
+func (h *handler) index(c *fiber.Ctx) error {
+	var request httpapi.IndexRequest
+	if err := c.BodyParser(&request); err != nil {
+		return respondError(c, httpapi.InvalidRequest(err))
+	}
+
+	response, err := h.service.Index(c.UserContext(), request)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(response)
+}
+
+func (h *handler) delete(c *fiber.Ctx) error {
+	// Fiber reuses request buffers, so the ID is copied in case hooks keep it.
+	if err := h.service.Delete(c.UserContext(), utils.CopyString(c.Params("id"))); err != nil {
+		return respondError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func respondError(c *fiber.Ctx, err error) error {
+	return c.Status(httpapi.StatusCode(err)).JSON(httpapi.ErrorResponse{Error: err.Error()})
+}
@@ -0,0 +1,58 @@
+package fiberapi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers/memory"
+)
+
+func TestRegister(t *testing.T) {
+	ac, err := autocomplete.New("memory", autocomplete.NewConfig(memory.Config{}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer ac.Close()
+
+	app := fiber.New()
+	calls := 0
+	Register(app.Group("/autocomplete"), ac, func(c *fiber.Ctx) error {
+		calls++
+		return c.Next()
+	})
+
+	tests := []struct {
+		method, target, body string
+		wantStatus           int
+		wantBody             string
+	}{
+		{http.MethodPost, "/autocomplete/entries", `{"entries": [{"id": "1", "text": "Mumbai", "display": "Mumbai, MH"}]}`, http.StatusOK, `"status":"created"`},
+		{http.MethodGet, "/autocomplete/suggest?q=mum&limit=5", "", http.StatusOK, `"display":"Mumbai, MH"`},
+		{http.MethodGet, "/autocomplete/suggest?q=mum&limit=x", "", http.StatusBadRequest, `"error"`},
+		{http.MethodDelete, "/autocomplete/entries/1", "", http.StatusNoContent, ""},
+		{http.MethodGet, "/autocomplete/suggest?q=mum", "", http.StatusOK, `{"results":[]}`},
+	}
+	for _, tt := range tests {
+		request := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+		request.Header.Set("Content-Type", "application/json")
+		response, err := app.Test(request, -1)
+		if err != nil {
+			t.Fatalf("%s %s error = %v", tt.method, tt.target, err)
+		}
+		body, _ := io.ReadAll(response.Body)
+		_ = response.Body.Close()
+
+		if response.StatusCode != tt.wantStatus || !strings.Contains(string(body), tt.wantBody) {
+			t.Errorf("%s %s = %d %s, want %d containing %s", tt.method, tt.target, response.StatusCode, body, tt.wantStatus, tt.wantBody)
+		}
+	}
+	if calls != len(tests) {
+		t.Errorf("middleware ran %d times, want %d", calls, len(tests))
+	}
+}
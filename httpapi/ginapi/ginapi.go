@@ -0,0 +1,71 @@
+// Package ginapi serves the httpapi endpoints from a Gin router:
+//
+//	ginapi.Register(router.Group("/autocomplete"), ac)
+package ginapi
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/httpapi"
+)
+
+// Register adds the suggest and entries endpoints to routes, which can be the
+// engine or a group. Middleware, such as authentication, runs before each
+// endpoint in addition to the middleware already used by routes.
+func Register(routes gin.IRoutes, ac autocomplete.AutoComplete, middleware ...gin.HandlerFunc) {
+	h := &handler{service: httpapi.NewService(ac)}
+	routes.GET(httpapi.SuggestPath, append(middleware, h.suggest)...)
+	routes.POST(httpapi.EntriesPath, append(middleware, h.index)...)
+	routes.DELETE(httpapi.EntriesPath+"/:id", append(middleware, h.delete)...)
+}
+
+type handler struct {
+	service *httpapi.Service
+}
+
+func (h *handler) suggest(c *gin.Context) {
+	var request httpapi.SuggestRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		abort(c, httpapi.InvalidRequest(err))
+		return
+	}
+
+	response, err := h.service.Suggest(c.Request.Context(), request)
+	if err != nil {
+		abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) index(c *gin.Context) {
+	var request httpapi.IndexRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		abort(c, httpapi.InvalidRequest(err))
+		return
+	}
+
+	response, err := h.service.Index(c.Request.Context(), request)
+	if err != nil {
+		abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) delete(c *gin.Context) {
+	if err := h.service.Delete(c.Request.Context(), c.Param("id")); err != nil {
+		abort(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// abort records err on the context, for logging middleware, and responds with it.
+func abort(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.AbortWithStatusJSON(httpapi.StatusCode(err), httpapi.ErrorResponse{Error: err.Error()})
+}
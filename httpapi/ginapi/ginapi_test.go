@@ -0,0 +1,51 @@
+package ginapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers/memory"
+)
+
+func TestRegister(t *testing.T) {
+	ac, err := autocomplete.New("memory", autocomplete.NewConfig(memory.Config{}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer ac.Close()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	calls := 0
+	Register(router.Group("/autocomplete"), ac, func(c *gin.Context) { calls++ })
+
+	tests := []struct {
+		method, target, body string
+		wantStatus           int
+		wantBody             string
+	}{
+		{http.MethodPost, "/autocomplete/entries", `{"entries": [{"id": "1", "text": "Mumbai", "display": "Mumbai, MH"}]}`, http.StatusOK, `"status":"created"`},
+		{http.MethodGet, "/autocomplete/suggest?q=mum&limit=5", "", http.StatusOK, `"display":"Mumbai, MH"`},
+		{http.MethodGet, "/autocomplete/suggest?q=mum&limit=x", "", http.StatusBadRequest, `"error"`},
+		{http.MethodDelete, "/autocomplete/entries/1", "", http.StatusNoContent, ""},
+		{http.MethodGet, "/autocomplete/suggest?q=mum", "", http.StatusOK, `{"results":[]}`},
+	}
+	for _, tt := range tests {
+		request := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+		request.Header.Set("Content-Type", "application/json")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		if recorder.Code != tt.wantStatus || !strings.Contains(recorder.Body.String(), tt.wantBody) {
+			t.Errorf("%s %s = %d %s, want %d containing %s", tt.method, tt.target, recorder.Code, recorder.Body, tt.wantStatus, tt.wantBody)
+		}
+	}
+	if calls != len(tests) {
+		t.Errorf("middleware ran %d times, want %d", calls, len(tests))
+	}
+}
@@ -0,0 +1,145 @@
+package httpapi
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/remiges-tech/autocomplete"
+)
+
+// maxBodyBytes limits the size of index request bodies.
+const maxBodyBytes = 10 << 20
+
+// NewHandler returns a net/http handler serving the API for an AutoComplete
+// instance. Mount it under a prefix with http.StripPrefix:
+//
+//	mux.Handle("/autocomplete/", http.StripPrefix("/autocomplete", httpapi.NewHandler(ac)))
+func NewHandler(ac autocomplete.AutoComplete, options ...HandlerOption) http.Handler {
+	h := &handler{service: NewService(ac)}
+	for _, option := range options {
+		option(h)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+SuggestPath, h.suggest)
+	mux.HandleFunc("POST "+EntriesPath, h.index)
+	mux.HandleFunc("DELETE "+EntriesPath+"/{id}", h.delete)
+	if h.compressor != nil {
+		return h.compressor.wrap(mux)
+	}
+	return mux
+}
+
+type handler struct {
+	service    *Service
+	cache      *CacheConfig
+	compressor *compressor
+}
+
+func (h *handler) suggest(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	request := SuggestRequest{Query: query.Get("q"), Strategy: query.Get("strategy")}
+	if limit := query.Get("limit"); limit != "" {
+		var err error
+		if request.Limit, err = strconv.Atoi(limit); err != nil {
+			writeError(w, InvalidRequest(err))
+			return
+		}
+	}
+
+	// Headers are set before the query, so that the version read is no newer
+	// than the results
+	if h.cache != nil && h.cache.setHeaders(w, r) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	response, err := h.service.Suggest(r.Context(), request)
+	if err != nil {
+		if h.cache != nil {
+			clearCacheHeaders(w)
+		}
+		writeError(w, err)
+		return
+	}
+	writeResults(w, response.Results)
+}
+
+func (h *handler) index(w http.ResponseWriter, r *http.Request) {
+	var request IndexRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes)).Decode(&request); err != nil {
+		writeError(w, InvalidRequest(err))
+		return
+	}
+
+	response, err := h.service.Index(r.Context(), request)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	h.bumpVersion()
+	writeResults(w, response.Results)
+}
+
+func (h *handler) delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Delete(r.Context(), r.PathValue("id")); err != nil {
+		writeError(w, err)
+		return
+	}
+	h.bumpVersion()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// bumpVersion moves the served namespace to its next version after a write,
+// when caching headers are sent.
+func (h *handler) bumpVersion() {
+	if h.cache != nil {
+		h.cache.Versions.Bump(h.cache.Namespace)
+	}
+}
+
+// clearCacheHeaders removes the caching headers set for a suggest response
+// that turned out to be an error, so that the error is not cached.
+func clearCacheHeaders(w http.ResponseWriter) {
+	w.Header().Del("ETag")
+	w.Header().Set("Cache-Control", "no-store")
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	writeJSON(w, StatusCode(err), ErrorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// writeResults writes a SuggestResponse or IndexResponse holding results,
+// encoding one result at a time rather than the whole body at once, so that
+// large responses with metadata are streamed to the client, compressed if
+// WithCompression is set. The bytes written are those writeJSON writes.
+func writeResults[T any](w http.ResponseWriter, results []T) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	bw := bufio.NewWriter(w)
+	defer func() { _ = bw.Flush() }()
+	if results == nil {
+		_, _ = bw.WriteString(`{"results":null}` + "\n")
+		return
+	}
+	_, _ = bw.WriteString(`{"results":[`)
+	for i := range results {
+		encoded, err := json.Marshal(results[i])
+		if err != nil {
+			// The status is already sent, so the body is left truncated,
+			// which clients reject as invalid JSON
+			return
+		}
+		if i > 0 {
+			_ = bw.WriteByte(',')
+		}
+		_, _ = bw.Write(encoded)
+	}
+	_, _ = bw.WriteString("]}\n")
+}
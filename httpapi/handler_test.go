@@ -0,0 +1,283 @@
+package httpapi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers/memory"
+)
+
+func newTestAutoComplete(t *testing.T) autocomplete.AutoComplete {
+	t.Helper()
+	config := autocomplete.NewConfig(memory.Config{})
+	config.Options.MatchStrategies = []autocomplete.MatchStrategy{autocomplete.MatchSubstring, autocomplete.MatchPrefix}
+	ac, err := autocomplete.New("memory", config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = ac.Close() })
+	return ac
+}
+
+func serve(handler http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestHandler(t *testing.T) {
+	handler := NewHandler(newTestAutoComplete(t))
+
+	recorder := serve(handler, http.MethodPost, "/entries",
+		`{"entries": [{"id": "1", "text": "Mumbai", "display": "Mumbai, MH"}, {"id": "2", "text": "Navi Mumbai", "display": "Navi Mumbai"}, {"id": "", "text": "x", "display": "x"}]}`)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("POST /entries status = %d, body %s", recorder.Code, recorder.Body)
+	}
+	var indexed IndexResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&indexed); err != nil {
+		t.Fatal(err)
+	}
+	if len(indexed.Results) != 3 || indexed.Results[0].Status != "created" || indexed.Results[2].Status != "failed" || indexed.Results[2].Error == "" {
+		t.Errorf("POST /entries results = %+v", indexed.Results)
+	}
+
+	tests := []struct {
+		target     string
+		wantStatus int
+		wantIDs    string
+	}{
+		{"/suggest?q=mum", http.StatusOK, "1 2"},
+		{"/suggest?q=mum&strategy=prefix", http.StatusOK, "1"},
+		{"/suggest?q=mum&limit=1", http.StatusOK, "1"},
+		{"/suggest?q=mum&limit=x", http.StatusBadRequest, ""},
+		{"/suggest?q=mum&strategy=fuzzy", http.StatusBadRequest, ""},
+		{"/suggest?q=mum&strategy=ngram", http.StatusBadRequest, ""},
+		{"/suggest?q=", http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		recorder := serve(handler, http.MethodGet, tt.target, "")
+		if recorder.Code != tt.wantStatus {
+			t.Errorf("GET %s status = %d, want %d (body %s)", tt.target, recorder.Code, tt.wantStatus, recorder.Body)
+			continue
+		}
+		if tt.wantStatus != http.StatusOK {
+			continue
+		}
+		var response SuggestResponse
+		if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		ids := make([]string, len(response.Results))
+		for i, result := range response.Results {
+			ids[i] = result.ID
+		}
+		if got := strings.Join(ids, " "); got != tt.wantIDs {
+			t.Errorf("GET %s = %q, want %q", tt.target, got, tt.wantIDs)
+		}
+	}
+
+	if recorder := serve(handler, http.MethodDelete, "/entries/1", ""); recorder.Code != http.StatusNoContent {
+		t.Errorf("DELETE /entries/1 status = %d", recorder.Code)
+	}
+	if recorder := serve(handler, http.MethodPost, "/entries", `{"entries": []}`); recorder.Code != http.StatusBadRequest {
+		t.Errorf("POST /entries without entries status = %d, want 400", recorder.Code)
+	}
+	if recorder := serve(handler, http.MethodPost, "/entries", `{`); recorder.Code != http.StatusBadRequest {
+		t.Errorf("POST /entries with malformed body status = %d, want 400", recorder.Code)
+	}
+}
+
+func TestHandlerCache(t *testing.T) {
+	versions := NewVersions()
+	config := autocomplete.NewConfig(memory.Config{})
+	config.Options.Namespace = "cities"
+	config.Options.Hooks.OnMutation = versions.OnMutation
+	ac, err := autocomplete.New("memory", config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = ac.Close() })
+	handler := NewHandler(ac, WithCache(CacheConfig{
+		Namespace:         "cities",
+		MaxAge:            time.Minute,
+		MaxAgeByNamespace: map[string]time.Duration{"tenant": 0},
+		Versions:          versions,
+	}))
+	if err := ac.Index(context.Background(), "1", "Mumbai", "Mumbai"); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+
+	first := serve(handler, http.MethodGet, "/suggest?q=mum", "")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" || first.Header().Get("Cache-Control") != "public, max-age=60" {
+		t.Fatalf("GET /suggest = %d, headers %v", first.Code, first.Header())
+	}
+
+	revalidate := func(handler http.Handler, ctx context.Context, etag string) *httptest.ResponseRecorder {
+		request := httptest.NewRequestWithContext(ctx, http.MethodGet, "/suggest?q=mum", nil)
+		request.Header.Set("If-None-Match", `W/"other", `+etag)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+	if recorder := revalidate(handler, context.Background(), etag); recorder.Code != http.StatusNotModified || recorder.Body.Len() != 0 {
+		t.Errorf("GET /suggest with the current ETag = %d, want 304 without a body", recorder.Code)
+	}
+
+	// Writes through the hook and through the handler both change the ETag
+	if err := ac.Index(context.Background(), "2", "Navi Mumbai", "Navi Mumbai"); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if recorder := revalidate(handler, context.Background(), etag); recorder.Code != http.StatusOK || recorder.Header().Get("ETag") == etag {
+		t.Errorf("GET /suggest after a write = %d, ETag %q; want 200 with a new ETag", recorder.Code, recorder.Header().Get("ETag"))
+	}
+	etag = serve(handler, http.MethodGet, "/suggest?q=mum", "").Header().Get("ETag")
+	if recorder := serve(handler, http.MethodDelete, "/entries/2", ""); recorder.Code != http.StatusNoContent {
+		t.Fatalf("DELETE /entries/2 status = %d", recorder.Code)
+	}
+	if recorder := revalidate(handler, context.Background(), etag); recorder.Code != http.StatusOK {
+		t.Errorf("GET /suggest after a delete = %d, want 200", recorder.Code)
+	}
+
+	tenant := autocomplete.WithRequestOptions(context.Background(), autocomplete.RequestOptions{Namespace: "tenant"})
+	recorder := revalidate(handler, tenant, "")
+	if recorder.Header().Get("Cache-Control") != "public, no-cache" || recorder.Header().Get("ETag") == etag {
+		t.Errorf("GET /suggest for another namespace headers = %v, want no-cache and its own ETag", recorder.Header())
+	}
+
+	if recorder := serve(handler, http.MethodGet, "/suggest?q=", ""); recorder.Header().Get("ETag") != "" || recorder.Header().Get("Cache-Control") != "no-store" {
+		t.Errorf("GET /suggest with an error headers = %v, want no-store without an ETag", recorder.Header())
+	}
+}
+
+func TestHandlerCompression(t *testing.T) {
+	ac := newTestAutoComplete(t)
+	handler := NewHandler(ac, WithCompression(CompressionConfig{}), WithCache(CacheConfig{MaxAge: time.Minute}))
+	for i := range 40 {
+		id := strconv.Itoa(i)
+		if err := ac.Index(context.Background(), id, "Mumbai "+id, "Mumbai suburb "+id); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	suggest := func(target, acceptEncoding string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, target, nil)
+		request.Header.Set("Accept-Encoding", acceptEncoding)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+	plain := suggest("/suggest?q=mum&limit=40", "")
+	if plain.Header().Get("Content-Encoding") != "" || plain.Body.Len() < defaultCompressionMinSize {
+		t.Fatalf("GET /suggest without Accept-Encoding headers = %v, %d bytes", plain.Header(), plain.Body.Len())
+	}
+
+	decoders := map[string]func(io.Reader) (io.Reader, error){
+		"gzip": func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+		"br":   func(r io.Reader) (io.Reader, error) { return brotli.NewReader(r), nil },
+	}
+	for _, tt := range []struct{ acceptEncoding, want string }{
+		{"gzip", "gzip"},
+		{"gzip, br", "br"},
+		{"br;q=0.5, gzip", "gzip"},
+	} {
+		recorder := suggest("/suggest?q=mum&limit=40", tt.acceptEncoding)
+		header := recorder.Header()
+		if header.Get("Content-Encoding") != tt.want || header.Get("Vary") != "Accept-Encoding" || header.Get("Content-Length") != "" {
+			t.Errorf("GET /suggest with Accept-Encoding %q headers = %v, want %s", tt.acceptEncoding, header, tt.want)
+			continue
+		}
+		if etag := header.Get("ETag"); etag != "W/"+plain.Header().Get("ETag") {
+			t.Errorf("GET /suggest with Accept-Encoding %q ETag = %q, want the weak form of %q", tt.acceptEncoding, etag, plain.Header().Get("ETag"))
+		}
+		reader, err := decoders[tt.want](recorder.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("decoding %s body: %v", tt.want, err)
+		}
+		if !bytes.Equal(body, plain.Body.Bytes()) {
+			t.Errorf("GET /suggest with Accept-Encoding %q body differs from the uncompressed one", tt.acceptEncoding)
+		}
+	}
+
+	// Bodies below MinSize, and bodiless responses, are sent as they are
+	small := suggest("/suggest?q=mum&limit=1", "gzip")
+	if small.Header().Get("Content-Encoding") != "" || small.Header().Get("Content-Length") != strconv.Itoa(small.Body.Len()) {
+		t.Errorf("GET /suggest with a small body headers = %v", small.Header())
+	}
+	if recorder := suggest("/suggest?q=", "gzip"); recorder.Code != http.StatusBadRequest || recorder.Header().Get("Content-Encoding") != "" {
+		t.Errorf("GET /suggest with an error = %d, headers %v", recorder.Code, recorder.Header())
+	}
+	request := httptest.NewRequest(http.MethodGet, "/suggest?q=mum&limit=40", nil)
+	request.Header.Set("Accept-Encoding", "gzip")
+	request.Header.Set("If-None-Match", plain.Header().Get("ETag"))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusNotModified || recorder.Body.Len() != 0 || recorder.Header().Get("Content-Encoding") != "" {
+		t.Errorf("GET /suggest with the current ETag = %d, headers %v", recorder.Code, recorder.Header())
+	}
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct{ header, want string }{
+		{"", ""},
+		{"identity", ""},
+		{"gzip", "gzip"},
+		{"GZIP", "gzip"},
+		{"gzip, deflate, br", "br"},
+		{"br;q=0.8, gzip;q=0.9", "gzip"},
+		{"br;q=0, gzip", "gzip"},
+		{"br;q=0, gzip;q=0", ""},
+		{"*", "br"},
+		{"gzip;q=x", ""},
+	}
+	for _, tt := range tests {
+		if got := negotiateEncoding(tt.header); got != tt.want {
+			t.Errorf("negotiateEncoding(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestWriteResults(t *testing.T) {
+	results := []autocomplete.Result{
+		{ID: "1", Display: "<Mumbai> & co", Score: 2, Metadata: json.RawMessage(`{"state":"MH"}`)},
+		{ID: "2", Display: "Pune", Score: 1},
+	}
+	for _, response := range []SuggestResponse{{Results: results}, {Results: []autocomplete.Result{}}, {}} {
+		want := httptest.NewRecorder()
+		writeJSON(want, http.StatusOK, response)
+		got := httptest.NewRecorder()
+		writeResults(got, response.Results)
+		if got.Body.String() != want.Body.String() || got.Header().Get("Content-Type") != "application/json" {
+			t.Errorf("writeResults() of %d results = %s, want %s", len(response.Results), got.Body, want.Body)
+		}
+	}
+}
+
+func TestStatusCode(t *testing.T) {
+	service := NewService(newTestAutoComplete(t))
+	_, err := service.Suggest(context.Background(), SuggestRequest{Query: "a", Limit: 100000})
+	if got := StatusCode(err); got != http.StatusBadRequest {
+		t.Errorf("StatusCode(%v) = %d, want 400", err, got)
+	}
+	if got := StatusCode(context.Canceled); got != http.StatusInternalServerError {
+		t.Errorf("StatusCode(context.Canceled) = %d, want 500", got)
+	}
+}
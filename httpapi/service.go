@@ -0,0 +1,176 @@
+// Package httpapi exposes an AutoComplete over HTTP: suggestions for a query,
+// and indexing and deletion of entries. NewHandler serves the endpoints with
+// net/http; the ginapi, echoapi and fiberapi subpackages serve the same
+// endpoints from those frameworks, sharing the request handling in Service.
+//
+// The endpoints, relative to where the handler is mounted, are:
+//
+//	GET    /suggest?q=mum&limit=10&strategy=prefix  -> SuggestResponse
+//	POST   /entries    with an IndexRequest body     -> IndexResponse
+//	DELETE /entries/{id}                             -> 204 No Content
+//
+// Errors are returned as an ErrorResponse with the status given by StatusCode.
+// WithCache adds Cache-Control and ETag headers to the suggest responses of
+// NewHandler, and WithCompression compresses its responses.
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/remiges-tech/autocomplete"
+)
+
+// Paths of the endpoints, relative to where they are mounted.
+const (
+	SuggestPath = "/suggest"
+	EntriesPath = "/entries"
+)
+
+var (
+	// ErrUnknownStrategy is returned when a suggest request names a match
+	// strategy that does not exist.
+	ErrUnknownStrategy = errors.New("unknown match strategy")
+
+	// ErrNoEntries is returned when an index request has no entries.
+	ErrNoEntries = errors.New("no entries to index")
+
+	// ErrInvalidRequest wraps errors decoding a request's parameters or body.
+	ErrInvalidRequest = errors.New("invalid request")
+)
+
+// SuggestRequest holds the parameters of a suggest request. The struct tags
+// let net/http, Gin, Echo and Fiber bind it from the query string.
+type SuggestRequest struct {
+	// Query is the text typed so far.
+	Query string `json:"q" form:"q" query:"q"`
+
+	// Limit is the maximum number of results; zero uses Options.DefaultLimit.
+	Limit int `json:"limit" form:"limit" query:"limit"`
+
+	// Strategy selects one of Options.MatchStrategies by name ("prefix", "ngram",
+	// "n-or-more-gram", "substring", "suffix" or "anchored-substring"). Empty
+	// uses Options.MatchStrategy.
+	Strategy string `json:"strategy" form:"strategy" query:"strategy"`
+}
+
+// SuggestResponse is the body of a successful suggest response.
+type SuggestResponse struct {
+	Results []autocomplete.Result `json:"results"`
+}
+
+// IndexRequest is the body of an index request.
+type IndexRequest struct {
+	Entries []autocomplete.Entry `json:"entries"`
+}
+
+// IndexResponse is the body of an index response, with one result per entry in
+// request order. Entries that failed do not fail the request.
+type IndexResponse struct {
+	Results []EntryResult `json:"results"`
+}
+
+// EntryResult is the outcome of indexing one entry.
+type EntryResult struct {
+	ID string `json:"id"`
+
+	// Status is "created", "updated", "unchanged" or "failed".
+	Status string `json:"status"`
+
+	// Error is the reason the entry failed.
+	Error string `json:"error,omitempty"`
+}
+
+// ErrorResponse is the body of an error response.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// Service handles API requests independently of the HTTP framework serving them.
+type Service struct {
+	ac autocomplete.AutoComplete
+}
+
+// NewService creates a Service for an AutoComplete instance.
+func NewService(ac autocomplete.AutoComplete) *Service {
+	return &Service{ac: ac}
+}
+
+// Suggest returns the entries matching a query.
+func (s *Service) Suggest(ctx context.Context, request SuggestRequest) (SuggestResponse, error) {
+	var results []autocomplete.Result
+	var err error
+	if request.Strategy == "" {
+		results, err = s.ac.Query(ctx, request.Query, request.Limit)
+	} else {
+		strategy, parseErr := parseStrategy(request.Strategy)
+		if parseErr != nil {
+			return SuggestResponse{}, parseErr
+		}
+		results, err = s.ac.QueryStrategy(ctx, strategy, request.Query, request.Limit)
+	}
+	if err != nil {
+		return SuggestResponse{}, err
+	}
+	return SuggestResponse{Results: results}, nil
+}
+
+// Index adds or updates the entries of a request, reporting the outcome of each.
+func (s *Service) Index(ctx context.Context, request IndexRequest) (IndexResponse, error) {
+	if len(request.Entries) == 0 {
+		return IndexResponse{}, ErrNoEntries
+	}
+
+	results := s.ac.IndexBatch(ctx, request.Entries)
+	response := IndexResponse{Results: make([]EntryResult, len(results))}
+	for i, result := range results {
+		response.Results[i] = EntryResult{ID: result.ID, Status: result.Status.String()}
+		if result.Err != nil {
+			response.Results[i].Error = result.Err.Error()
+		}
+	}
+	return response, nil
+}
+
+// Delete removes an entry.
+func (s *Service) Delete(ctx context.Context, id string) error {
+	return s.ac.Delete(ctx, id)
+}
+
+// StatusCode returns the HTTP status for an error returned by Service methods:
+// 400 for invalid requests and 500 for everything else.
+func StatusCode(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidRequest),
+		errors.Is(err, ErrUnknownStrategy),
+		errors.Is(err, ErrNoEntries),
+		errors.Is(err, autocomplete.ErrQueryTooShort),
+		errors.Is(err, autocomplete.ErrLimitExceeded),
+		errors.Is(err, autocomplete.ErrEmptyID),
+		errors.Is(err, autocomplete.ErrStrategyNotIndexed):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// InvalidRequest wraps an error binding request parameters or a body in
+// ErrInvalidRequest, for adapters reporting their framework's binding errors.
+func InvalidRequest(err error) error {
+	return fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+}
+
+// parseStrategy returns the match strategy with the given name.
+func parseStrategy(name string) (autocomplete.MatchStrategy, error) {
+	for _, strategy := range []autocomplete.MatchStrategy{
+		autocomplete.MatchPrefix, autocomplete.MatchNGram, autocomplete.MatchNOrMoreGram, autocomplete.MatchSubstring,
+		autocomplete.MatchSuffix, autocomplete.MatchAnchoredSubstring,
+	} {
+		if strategy.String() == name {
+			return strategy, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q", ErrUnknownStrategy, name)
+}
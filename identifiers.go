@@ -0,0 +1,75 @@
+package autocomplete
+
+import (
+	"strings"
+	"unicode"
+)
+
+// IdentifierOptions returns options for completing code identifiers such as
+// symbols, commands, and configuration keys, the way a language server does:
+//
+//	ac, err := autocomplete.New("memory", autocomplete.NewConfigWithOptions(nil, autocomplete.IdentifierOptions()))
+//
+// Entries match by prefix, case-sensitively first and ignoring case when the
+// exact case finds nothing. NormalizeIdentifier splits camelCase, PascalCase,
+// snake_case, and kebab-case into the same words, so "getUserName",
+// "get_user_name", and "get-user-name" complete each other.
+func IdentifierOptions() Options {
+	options := DefaultOptions()
+	options.MatchStrategy = MatchPrefix
+	options.CaseSensitive = true
+	options.CaseInsensitiveFallback = true
+	options.Normalizer = NormalizeIdentifier
+	return options
+}
+
+// NormalizeIdentifier is a Normalizer that rewrites an identifier as its words,
+// as returned by SplitIdentifier, separated by single spaces: "parseHTTPRequest"
+// becomes "parse HTTP Request" and "MAX_RETRY_COUNT" becomes "MAX RETRY COUNT".
+// Case is kept, so case-sensitive matching still tells "Request" from "request".
+func NormalizeIdentifier(text string) string {
+	return strings.Join(SplitIdentifier(text), " ")
+}
+
+// SplitIdentifier splits an identifier into its words. Every character other
+// than a letter or digit separates words, and a new word starts at an upper-case
+// letter following a lower-case letter or digit ("utf8Decode" is "utf8",
+// "Decode") and at the last upper-case letter of a run followed by a lower-case
+// one ("HTTPRequest" is "HTTP", "Request").
+func SplitIdentifier(identifier string) []string {
+	var words []string
+	runes := []rune(identifier)
+	start := -1
+	for i, r := range runes {
+		if !isWordRune(r) {
+			if start >= 0 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		} else if startsCamelWord(runes, i) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// startsCamelWord reports whether runes[i], which follows another word rune,
+// begins a new word of a camelCase or PascalCase identifier.
+func startsCamelWord(runes []rune, i int) bool {
+	if !unicode.IsUpper(runes[i]) {
+		return false
+	}
+	previous := runes[i-1]
+	if unicode.IsLower(previous) || unicode.IsDigit(previous) {
+		return true
+	}
+	return unicode.IsUpper(previous) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+}
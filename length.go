@@ -0,0 +1,28 @@
+package autocomplete
+
+import (
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+)
+
+// LengthUnit selects how the length of a query is counted against
+// Options.MinPrefixLength.
+type LengthUnit int
+
+const (
+	// LengthRunes counts Unicode code points, so "मु" (two code points, six
+	// bytes) has length 2.
+	LengthRunes LengthUnit = iota
+	// LengthGraphemes counts grapheme clusters, the characters a user perceives
+	// and types, so "मु" (one consonant with a vowel sign) has length 1.
+	LengthGraphemes
+)
+
+// queryLength returns the length of a query in Options.MinPrefixLengthUnit.
+func (a *autocompleteImpl) queryLength(query string) int {
+	if a.tuning().minPrefixLengthUnit == LengthGraphemes {
+		return uniseg.GraphemeClusterCount(query)
+	}
+	return utf8.RuneCountInString(query)
+}
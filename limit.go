@@ -0,0 +1,28 @@
+package autocomplete
+
+// LimitPolicy decides what happens to queries whose limit exceeds Options.MaxLimit.
+type LimitPolicy int
+
+const (
+	// LimitError fails the query with ErrLimitExceeded.
+	LimitError LimitPolicy = iota
+	// LimitClampToMax runs the query with MaxLimit as its limit, so callers can
+	// pass user-supplied limits through without validating them first.
+	LimitClampToMax
+)
+
+// effectiveLimit returns the limit a query runs with, applying DefaultLimit to
+// non-positive limits and Options.LimitPolicy to limits above MaxLimit.
+func (a *autocompleteImpl) effectiveLimit(limit int) (int, error) {
+	t := a.tuning()
+	if limit <= 0 {
+		limit = t.defaultLimit
+	}
+	if limit > t.maxLimit {
+		if t.limitPolicy != LimitClampToMax {
+			return 0, ErrLimitExceeded
+		}
+		limit = t.maxLimit
+	}
+	return limit, nil
+}
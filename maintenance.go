@@ -0,0 +1,81 @@
+package autocomplete
+
+import (
+	"context"
+	"time"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// MaintenanceOptions controls a Maintain run.
+type MaintenanceOptions struct {
+	// BatchSize is the number of stored items examined per batch.
+	// Zero lets the provider choose.
+	BatchSize int
+
+	// BatchDelay is the pause between batches, limiting the load a run puts
+	// on the backend. Zero runs batches back to back.
+	BatchDelay time.Duration
+
+	// Progress, if set, is called after each batch with the totals so far.
+	Progress func(stats MaintenanceStats)
+}
+
+// MaintenanceStats reports the work done by a Maintain run.
+type MaintenanceStats struct {
+	// Scanned is the number of stored items examined.
+	Scanned int64
+
+	// Removed is the number of orphaned items removed.
+	Removed int64
+}
+
+// Maintain removes orphaned data from the namespace and its copies.
+// See AutoComplete.Maintain for details.
+func (a *autocompleteImpl) Maintain(ctx context.Context, options MaintenanceOptions) (MaintenanceStats, error) {
+	maintainer, ok := a.provider.(providers.Maintainer)
+	if !ok {
+		return MaintenanceStats{}, ErrMaintenanceUnsupported
+	}
+
+	// The case-folded and segment copies are maintained after the namespace,
+	// with progress continuing from the totals so far
+	namespaces := []string{a.config.Options.Namespace}
+	if a.caseFallback() {
+		namespaces = append(namespaces, a.foldedNamespace())
+	}
+	if a.config.Options.Segmenter != nil {
+		namespaces = append(namespaces, a.segmentNamespace())
+	}
+
+	var total MaintenanceStats
+	for _, namespace := range namespaces {
+		providerOptions := providers.MaintenanceOptions{
+			BatchSize:  options.BatchSize,
+			BatchDelay: options.BatchDelay,
+		}
+		if options.Progress != nil {
+			done := total
+			providerOptions.Progress = func(stats providers.MaintenanceStats) {
+				options.Progress(MaintenanceStats{Scanned: done.Scanned + stats.Scanned, Removed: done.Removed + stats.Removed})
+			}
+		}
+		stats, err := maintainer.Maintain(ctx, namespace, providerOptions)
+		total.Scanned += stats.Scanned
+		total.Removed += stats.Removed
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// Compact compacts the provider's storage.
+// See AutoComplete.Compact for details.
+func (a *autocompleteImpl) Compact(ctx context.Context) error {
+	compactor, ok := a.provider.(providers.Compactor)
+	if !ok {
+		return ErrCompactionUnsupported
+	}
+	return compactor.Compact(ctx)
+}
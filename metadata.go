@@ -0,0 +1,54 @@
+package autocomplete
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// marshalMetadata encodes a metadata value given to IndexWithMetadata.
+// nil and JSON null encode to no metadata.
+func marshalMetadata(metadata interface{}) (json.RawMessage, error) {
+	if metadata == nil {
+		return nil, nil
+	}
+	payload, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMetadata, err)
+	}
+	if string(payload) == "null" {
+		return nil, nil
+	}
+	return payload, nil
+}
+
+// validateMetadata checks that an entry's metadata, if any, is valid JSON
+// and that the provider can store it.
+func (a *autocompleteImpl) validateMetadata(metadata json.RawMessage) error {
+	if len(metadata) == 0 {
+		return nil
+	}
+	if !json.Valid(metadata) {
+		return ErrInvalidMetadata
+	}
+	if !a.supportsMetadata() {
+		return ErrMetadataUnsupported
+	}
+	return nil
+}
+
+// supportsMetadata reports whether the provider stores metadata and filters by it.
+func (a *autocompleteImpl) supportsMetadata() bool {
+	supporter, ok := a.provider.(providers.MetadataSupporter)
+	return ok && supporter.SupportsMetadata()
+}
+
+// metadataPayload converts a payload stored by a provider to Entry or Result
+// metadata, nil when the entry has none.
+func metadataPayload(stored string) json.RawMessage {
+	if stored == "" {
+		return nil
+	}
+	return json.RawMessage(stored)
+}
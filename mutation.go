@@ -0,0 +1,98 @@
+package autocomplete
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// MutationOp identifies the kind of write a Mutation records.
+type MutationOp int
+
+const (
+	// MutationIndex means Mutation.Entries were indexed.
+	MutationIndex MutationOp = iota
+	// MutationDelete means the entry Mutation.ID was deleted.
+	MutationDelete
+	// MutationDeleteAll means all entries of the namespace were deleted.
+	MutationDeleteAll
+	// MutationDeleteMany means the entries Mutation.IDs were deleted.
+	MutationDeleteMany
+	// MutationUpdateScore means the score of the entry Mutation.ID was set to
+	// Mutation.Score.
+	MutationUpdateScore
+	// MutationIncrementScore means Mutation.Score was added to the score of
+	// the entry Mutation.ID.
+	MutationIncrementScore
+)
+
+// String returns the lowercase name of the operation.
+func (op MutationOp) String() string {
+	switch op {
+	case MutationIndex:
+		return "index"
+	case MutationDelete:
+		return "delete"
+	case MutationDeleteAll:
+		return "delete all"
+	case MutationDeleteMany:
+		return "delete many"
+	case MutationUpdateScore:
+		return "update score"
+	case MutationIncrementScore:
+		return "increment score"
+	default:
+		return "unknown"
+	}
+}
+
+// Mutation is a successful write to the index, reported to Hooks.OnMutation as
+// the caller made it, so that it can be replayed against another instance
+// configured with the same options (see the replication package).
+type Mutation struct {
+	// Namespace is the namespace that was written.
+	Namespace string
+
+	// Op is the kind of write.
+	Op MutationOp
+
+	// Entries holds the entries of a MutationIndex as given to Index,
+	// IndexBatch, BatchIndex, or IndexAtomic, before normalization.
+	Entries []Entry
+
+	// Atomic is set when Entries were written together by IndexAtomic.
+	Atomic bool
+
+	// ID is the ID of the entry removed by a MutationDelete or rescored by a
+	// MutationUpdateScore or MutationIncrementScore.
+	ID string
+
+	// Score is the score set by a MutationUpdateScore, or the amount added by
+	// a MutationIncrementScore.
+	Score float64
+
+	// IDs holds the IDs of the entries removed by a MutationDeleteMany.
+	IDs []string
+
+	// Time is when the write completed.
+	Time time.Time
+}
+
+// reportMutation invokes the OnMutation hook with a completed write.
+func (a *autocompleteImpl) reportMutation(ctx context.Context, mutation Mutation) {
+	if a.config.Options.Hooks.OnMutation == nil {
+		return
+	}
+	mutation.Namespace = a.config.Options.Namespace
+	mutation.Time = a.clock.Now()
+	a.config.Options.Hooks.OnMutation(ctx, mutation)
+}
+
+// reportIndexMutation reports entries written by Index, IndexBatch, BatchIndex, or IndexAtomic.
+// The entries are copied, as the hook may keep them after the caller reuses its slice.
+func (a *autocompleteImpl) reportIndexMutation(ctx context.Context, entries []Entry, atomic bool) {
+	if a.config.Options.Hooks.OnMutation == nil {
+		return
+	}
+	a.reportMutation(ctx, Mutation{Op: MutationIndex, Entries: slices.Clone(entries), Atomic: atomic})
+}
@@ -0,0 +1,70 @@
+package autocomplete
+
+import (
+	"context"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// AliasNamespace points alias and its copies at target.
+// See AutoComplete.AliasNamespace for details.
+func (a *autocompleteImpl) AliasNamespace(ctx context.Context, alias, target string) error {
+	aliaser, ok := a.provider.(providers.NamespaceAliaser)
+	if !ok {
+		return ErrAliasesUnsupported
+	}
+
+	for _, suffix := range a.namespaceSuffixes() {
+		suffixedTarget := ""
+		if target != "" {
+			suffixedTarget = target + suffix
+		}
+		if err := aliaser.AliasNamespace(ctx, alias+suffix, suffixedTarget); err != nil {
+			return err
+		}
+	}
+	a.clearCaches()
+	return nil
+}
+
+// RenameNamespace moves oldName and its copies to newName.
+// See AutoComplete.RenameNamespace for details.
+func (a *autocompleteImpl) RenameNamespace(ctx context.Context, oldName, newName string) error {
+	aliaser, ok := a.provider.(providers.NamespaceAliaser)
+	if !ok {
+		return ErrAliasesUnsupported
+	}
+
+	for _, suffix := range a.namespaceSuffixes() {
+		if err := aliaser.RenameNamespace(ctx, oldName+suffix, newName+suffix); err != nil {
+			return err
+		}
+	}
+	a.clearCaches()
+	return nil
+}
+
+// namespaceSuffixes returns the suffixes of the namespaces an instance writes
+// for each of its namespaces, ending with the empty suffix of the namespace
+// itself, so that it is re-pointed after its copies.
+func (a *autocompleteImpl) namespaceSuffixes() []string {
+	var suffixes []string
+	if a.caseFallback() {
+		suffixes = append(suffixes, foldedNamespaceSuffix)
+	}
+	if a.config.Options.Segmenter != nil {
+		suffixes = append(suffixes, segmentNamespaceSuffix)
+	}
+	return append(suffixes, "")
+}
+
+// clearCaches drops the displays and stale results kept for the namespace,
+// which may now hold other entries.
+func (a *autocompleteImpl) clearCaches() {
+	if a.displays != nil {
+		a.displays.clear()
+	}
+	if a.staleResults != nil {
+		a.staleResults.clear()
+	}
+}
@@ -0,0 +1,172 @@
+package autocomplete
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// defaultRenormalizeBatchSize is the number of entries listed per batch when
+// RenormalizeOptions.BatchSize is zero.
+const defaultRenormalizeBatchSize = 100
+
+// Normalizer transforms text before it is indexed or queried, e.g. folding
+// diacritics or expanding abbreviations. It runs before the provider applies
+// case folding and must be deterministic.
+type Normalizer func(text string) string
+
+// RenormalizeOptions controls a Renormalize run.
+type RenormalizeOptions struct {
+	// BatchSize is the number of entries listed per batch.
+	// Default: 100.
+	BatchSize int
+
+	// BatchDelay is the pause between batches, limiting the load a run puts
+	// on the backend. Zero runs batches back to back.
+	BatchDelay time.Duration
+
+	// Source, if set, returns the original entries for the given IDs, e.g. from
+	// the application's database. Entries it omits, and all entries when Source
+	// is nil, are re-normalized from their stored text, which is the output of the
+	// previous Normalizer; that is only correct if the new Normalizer gives the
+	// same result on already normalized text as on the original.
+	Source func(ctx context.Context, ids []string) map[string]Entry
+
+	// Progress, if set, is called after each batch with the totals so far.
+	Progress func(stats RenormalizeStats)
+}
+
+// RenormalizeStats reports the work done by a Renormalize run.
+type RenormalizeStats struct {
+	// Scanned is the number of entries examined.
+	Scanned int64
+
+	// Upgraded is the number of entries re-indexed with the current Normalizer.
+	Upgraded int64
+}
+
+// normalize applies the configured Normalizer to text.
+func (a *autocompleteImpl) normalize(text string) string {
+	if a.config.Options.Normalizer == nil {
+		return text
+	}
+	return a.config.Options.Normalizer(text)
+}
+
+// versionedHash records the normalizer version in a content hash, so the version
+// each entry was indexed with can be read back from the stored hash.
+// Hashes of version 0 are left unchanged.
+func (a *autocompleteImpl) versionedHash(hash string) string {
+	if a.config.Options.NormalizerVersion == 0 {
+		return hash
+	}
+	return "n" + strconv.Itoa(a.config.Options.NormalizerVersion) + ":" + hash
+}
+
+// normalizerVersion returns the normalizer version recorded in a stored content hash.
+func normalizerVersion(hash string) int {
+	rest, ok := strings.CutPrefix(hash, "n")
+	if !ok {
+		return 0
+	}
+	version, _, ok := strings.Cut(rest, ":")
+	if !ok {
+		return 0
+	}
+	v, err := strconv.Atoi(version)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// Renormalize re-indexes entries indexed with another normalizer version.
+// See AutoComplete.Renormalize for details.
+func (a *autocompleteImpl) Renormalize(ctx context.Context, options RenormalizeOptions) (RenormalizeStats, error) {
+	lister, ok := a.provider.(providers.EntryLister)
+	if !ok {
+		return RenormalizeStats{}, ErrRenormalizeUnsupported
+	}
+	batchSize := options.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultRenormalizeBatchSize
+	}
+
+	var stats RenormalizeStats
+	cursor := ""
+	for {
+		entries, next, err := lister.ListEntries(ctx, a.config.Options.Namespace, cursor, batchSize)
+		if err != nil {
+			return stats, fmt.Errorf("failed to list entries: %w", err)
+		}
+		stats.Scanned += int64(len(entries))
+
+		upgraded, err := a.renormalizeBatch(ctx, entries, options.Source)
+		stats.Upgraded += upgraded
+		if err != nil {
+			return stats, err
+		}
+		if options.Progress != nil {
+			options.Progress(stats)
+		}
+
+		if next == "" {
+			return stats, nil
+		}
+		cursor = next
+
+		if options.BatchDelay > 0 {
+			select {
+			case <-ctx.Done():
+				return stats, ctx.Err()
+			case <-time.After(options.BatchDelay):
+			}
+		}
+	}
+}
+
+// renormalizeBatch re-indexes the entries of a batch whose recorded normalizer
+// version differs from the configured one, returning how many were re-indexed.
+func (a *autocompleteImpl) renormalizeBatch(
+	ctx context.Context, entries []providers.StoredEntry, source func(context.Context, []string) map[string]Entry,
+) (int64, error) {
+	var stale []providers.StoredEntry
+	for _, stored := range entries {
+		if normalizerVersion(stored.ContentHash) != a.config.Options.NormalizerVersion {
+			stale = append(stale, stored)
+		}
+	}
+	if len(stale) == 0 {
+		return 0, nil
+	}
+
+	var originals map[string]Entry
+	if source != nil {
+		ids := make([]string, len(stale))
+		for i, stored := range stale {
+			ids[i] = stored.ID
+		}
+		originals = source(ctx, ids)
+	}
+
+	var upgraded int64
+	for _, stored := range stale {
+		entry, ok := originals[stored.ID]
+		if !ok {
+			entry = Entry{ID: stored.ID, Text: stored.Text, Display: stored.Display, Metadata: metadataPayload(stored.Metadata)}
+		}
+		entry.ID = stored.ID
+		if _, err := a.indexEntry(ctx, entry, false); err != nil {
+			return upgraded, fmt.Errorf("failed to re-index entry %q: %w", stored.ID, err)
+		}
+		if a.displays != nil {
+			a.displays.remove(stored.ID)
+		}
+		upgraded++
+	}
+	return upgraded, nil
+}
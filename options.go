@@ -1,5 +1,10 @@
 package autocomplete
 
+import (
+	"fmt"
+	"time"
+)
+
 // defaultLimit is the default number of results to return.
 const defaultLimit = 10
 
@@ -9,6 +14,18 @@ const defaultMaxLimit = 100
 // defaultNGramSize is the default n-gram size (trigrams).
 const defaultNGramSize = 3
 
+// defaultSnapshotKeepAlive is how long an idle snapshot is retained by default.
+const defaultSnapshotKeepAlive = time.Minute
+
+// defaultDisplayCacheTTL is how long resolved display text is cached by default.
+const defaultDisplayCacheTTL = 5 * time.Minute
+
+// defaultStaleCacheTTL is how long query results are kept for stale serving by default.
+const defaultStaleCacheTTL = 10 * time.Minute
+
+// defaultListPageSize is the number of entries List returns per page by default.
+const defaultListPageSize = 100
+
 // MatchStrategy defines how search terms are matched against indexed text.
 type MatchStrategy int
 
@@ -25,8 +42,39 @@ const (
 	// MatchSubstring matches any substring within the text.
 	// Example: "test" -> ["t", "te", "tes", "test", "e", "es", "est", "s", "st", "t"].
 	MatchSubstring
+	// MatchSuffix matches the end of the text, e.g. the last digits of a vehicle
+	// registration or account number.
+	// Example: "4521" matches "MH12AB4521" but not "MH12AB4521X".
+	// Requires a provider implementing providers.StrategySupporter.
+	MatchSuffix
+	// MatchAnchoredSubstring matches substrings starting at a word start, and
+	// substrings of at least NGramSize bytes within a word: "pun" and "une"
+	// match "Pune India", but "un" and "e i" do not. It stores about half the
+	// tokens of MatchSubstring while keeping in-word matches.
+	// Requires a provider implementing providers.StrategySupporter.
+	MatchAnchoredSubstring
 )
 
+// String returns the name of the match strategy.
+func (s MatchStrategy) String() string {
+	switch s {
+	case MatchPrefix:
+		return "prefix"
+	case MatchNGram:
+		return "ngram"
+	case MatchNOrMoreGram:
+		return "n-or-more-gram"
+	case MatchSubstring:
+		return "substring"
+	case MatchSuffix:
+		return "suffix"
+	case MatchAnchoredSubstring:
+		return "anchored-substring"
+	default:
+		return fmt.Sprintf("MatchStrategy(%d)", int(s))
+	}
+}
+
 // Config holds configuration for the autocomplete instance.
 type Config struct {
 	// ProviderConfig contains provider-specific configuration.
@@ -38,7 +86,8 @@ type Config struct {
 }
 
 // Options contains common autocomplete behavior settings.
-// Use DefaultOptions() for default values.
+// Use DefaultOptions() for default values. Options listed in OptionsPatch can
+// be changed on a live instance with AutoComplete.UpdateOptions.
 type Options struct {
 	// DefaultLimit is the default number of results when limit is not specified.
 	DefaultLimit int
@@ -46,6 +95,11 @@ type Options struct {
 	// MaxLimit is the maximum number of results that can be requested.
 	MaxLimit int
 
+	// LimitPolicy decides whether queries with a limit above MaxLimit fail
+	// with ErrLimitExceeded or are clamped to MaxLimit.
+	// Default: LimitError.
+	LimitPolicy LimitPolicy
+
 	// CaseSensitive determines if searches are case-sensitive.
 	// When false (default), both indexing and querying convert text to lowercase.
 	// When true, text preserves its original case during indexing and queries must match exactly.
@@ -53,10 +107,25 @@ type Options struct {
 	// Default: false.
 	CaseSensitive bool
 
-	// MinPrefixLength is the minimum query length required.
+	// CaseInsensitiveFallback retries a query ignoring case when the exact-case
+	// query returns no results, flagging those results with Result.Fallback.
+	// It keeps a case-folded copy of every entry in a second namespace (Namespace
+	// plus ":folded"), doubling storage. Only used when CaseSensitive is true.
+	// Default: false.
+	CaseInsensitiveFallback bool
+
+	// MinPrefixLength is the minimum query length required, counted in
+	// MinPrefixLengthUnit.
 	// Default: 1.
 	MinPrefixLength int
 
+	// MinPrefixLengthUnit selects whether MinPrefixLength counts code points
+	// or grapheme clusters. Counting grapheme clusters treats scripts such as
+	// Devanagari, where a character may combine several code points, the same
+	// as ASCII.
+	// Default: LengthRunes.
+	MinPrefixLengthUnit LengthUnit
+
 	// Namespace prefixes all keys in the storage backend.
 	// Enables multiple datasets to coexist (e.g., "prod_users", "staging_products").
 	// Default: "autocomplete".
@@ -67,21 +136,151 @@ type Options struct {
 	// Default: MatchSubstring.
 	MatchStrategy MatchStrategy
 
-	// NGramSize is the n-gram size for MatchNGram and MatchNOrMoreGram strategies.
+	// NGramSize is the n-gram size for MatchNGram and MatchNOrMoreGram strategies,
+	// and the minimum length of in-word matches for MatchAnchoredSubstring.
 	// Default: 3 (trigrams). Ignored for other strategies.
 	NGramSize int
+
+	// MatchStrategies indexes every entry under each listed strategy in the same
+	// namespace, storing its text and display only once. MatchStrategy must be
+	// listed and is what Query uses; QueryStrategy selects another listed
+	// strategy per query. Requires a provider implementing
+	// providers.MultiStrategyIndexer; New returns ErrMultiStrategyUnsupported otherwise.
+	// Changing this requires reindexing all data.
+	// Default: empty (entries are indexed under MatchStrategy only).
+	MatchStrategies []MatchStrategy
+
+	// Normalizer, when set, transforms text before indexing and queries before
+	// matching. Normalized text is what providers store.
+	// Default: nil (text is used as given).
+	Normalizer Normalizer
+
+	// NormalizerVersion identifies the current Normalizer. It is recorded with
+	// every entry, so that after the Normalizer changes, bumping the version lets
+	// AutoComplete.Renormalize upgrade existing entries in the background instead
+	// of requiring a full reindex. Until an entry is upgraded, queries normalized
+	// the new way may not match it.
+	// Default: 0.
+	NormalizerVersion int
+
+	// Segmenter, when set, splits entries into segments that prefix queries also
+	// match from the start of, such as the local part and domain of an email
+	// address. Each segment after the first is kept as a separate copy of the
+	// entry in a second namespace (Namespace plus ":segments"), up to 8 per entry.
+	// Query returns entries matched from the start of their text first and fills
+	// the remaining results from the copies. Intended for MatchPrefix; the
+	// copies are not kept for CaseInsensitiveFallback or searched in snapshots.
+	// Default: nil.
+	Segmenter Segmenter
+
+	// DuplicatePolicy decides how an entry matched under several of its
+	// segments, such as several fields joined into one text and split by
+	// Segmenter, is scored and ranked. By default matches from the start of
+	// the text rank first and other matches only fill the remaining results.
+	// Default: DuplicateFirst.
+	DuplicatePolicy DuplicatePolicy
+
+	// Scorer, when set, computes the score each entry is indexed with from its
+	// normalized text, e.g. to rank shorter entries higher. Entries it scores
+	// NaN or infinite are rejected with ErrInvalidScore. Changing it requires
+	// reindexing all data for the new scores to take effect.
+	// Default: nil (every entry scores 1).
+	Scorer Scorer
+
+	// SkipUnchanged makes Index a no-op when the entry's text, display, and
+	// indexing options match what is already stored, so periodic full re-syncs
+	// of mostly static datasets do not rewrite every token.
+	// Requires a provider implementing providers.ChangeDetector; ignored otherwise.
+	// Default: false.
+	SkipUnchanged bool
+
+	// SnapshotKeepAlive is how long the backend retains an idle snapshot opened
+	// with OpenSnapshot. Each snapshot query extends it by the same duration.
+	// Default: 1 minute.
+	SnapshotKeepAlive time.Duration
+
+	// DisplayResolver, when set, supplies display text at query time instead of
+	// (or in addition to) the display stored at index time, so large display
+	// strings need not be duplicated in the backend. With a resolver configured,
+	// Index accepts an empty display.
+	DisplayResolver DisplayResolver
+
+	// DisplayCacheSize is the number of resolved display values cached in memory.
+	// Zero (default) disables caching. Only used with DisplayResolver.
+	DisplayCacheSize int
+
+	// DisplayCacheTTL is how long a resolved display value stays cached.
+	// Default: 5 minutes. Only used with DisplayResolver.
+	DisplayCacheTTL time.Duration
+
+	// StaleCacheSize is the number of recent query results kept in memory to
+	// answer the same queries while the provider fails, e.g. during a brief Redis
+	// failover. Such results are flagged with Result.Stale and returned instead
+	// of the error; they may include entries changed since. Queries whose
+	// context is done, and queries never answered before, still fail.
+	// Zero (default) disables it.
+	StaleCacheSize int
+
+	// StaleCacheTTL is how long after it was fetched a result may be served stale.
+	// Default: 10 minutes. Only used with StaleCacheSize.
+	StaleCacheTTL time.Duration
+
+	// MaxTokensPerEntry caps how many tokens a single entry may generate, protecting
+	// the backend from accidentally indexing long free text with MatchSubstring
+	// (a 5KB description expands to over 12 million substrings).
+	// Zero (default) means unlimited.
+	MaxTokensPerEntry int
+
+	// TokenBudgetPolicy decides whether entries over MaxTokensPerEntry are
+	// rejected or truncated. Default: TokenBudgetReject.
+	TokenBudgetPolicy TokenBudgetPolicy
+
+	// MinScore drops results scoring below it. Providers that support
+	// QueryOptions.MinScore apply it before limiting results; with others,
+	// queries may return fewer results than the limit.
+	// Default: 0 (no results are dropped).
+	MinScore float64
+
+	// MoreCountLimit is how many matches beyond the limit QueryEx fetches to
+	// count them in Response.More, so UIs can show "and 120 more" without a
+	// second query. Counts reaching it are reported as a lower bound. Every
+	// fetched match costs the same as a returned one, including display
+	// resolution with DisplayResolver.
+	// Zero (default) only reports whether results were truncated.
+	MoreCountLimit int
+
+	// Hooks are callbacks invoked as the index changes, e.g. to export token
+	// fan-out metrics and catch strategy misconfigurations early.
+	Hooks Hooks
+
+	// ResultLess, when set, orders results with the same score, which providers
+	// otherwise return in an order of their own. Queries fetch every result tied
+	// with the last one returned, so pages of a Snapshot stay consistent; with
+	// many equally scored matches that means fetching all of them.
+	// Default: nil (ties keep the provider's order).
+	ResultLess ResultLess
+
+	// Clock, when set, is the source of the current time for expiring
+	// DisplayCacheTTL and StaleCacheTTL, aging stale results in
+	// Hooks.OnStale, and stamping Mutation.Time, so that tests can control
+	// time instead of sleeping.
+	// Default: nil (the system clock).
+	Clock Clock
 }
 
 // DefaultOptions returns default options with MatchSubstring strategy.
 func DefaultOptions() Options {
 	return Options{
-		DefaultLimit:    defaultLimit,
-		MaxLimit:        defaultMaxLimit,
-		CaseSensitive:   false,
-		MinPrefixLength: 1,
-		Namespace:       "autocomplete",
-		MatchStrategy:   MatchSubstring,
-		NGramSize:       defaultNGramSize,
+		DefaultLimit:      defaultLimit,
+		MaxLimit:          defaultMaxLimit,
+		CaseSensitive:     false,
+		MinPrefixLength:   1,
+		Namespace:         "autocomplete",
+		MatchStrategy:     MatchSubstring,
+		NGramSize:         defaultNGramSize,
+		SnapshotKeepAlive: defaultSnapshotKeepAlive,
+		DisplayCacheTTL:   defaultDisplayCacheTTL,
+		StaleCacheTTL:     defaultStaleCacheTTL,
 	}
 }
 
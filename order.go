@@ -0,0 +1,84 @@
+package autocomplete
+
+import (
+	"context"
+	"slices"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// ResultLess reports whether result a should be listed before result b when
+// both have the same score, e.g. to order ties alphabetically or by stock level.
+// It must be a strict weak ordering and must not depend on Result.Fallback.
+type ResultLess func(a, b Result) bool
+
+// queryResults queries a namespace and converts the results. With
+// Options.ResultLess set, it fetches every result tied with the last one of
+// the requested page, orders ties with it, and then cuts out the page, so that
+// successive pages of a snapshot neither repeat nor skip tied results.
+//
+//nolint:gocritic // hugeParam: options is passed by value to match the Provider interface
+func (a *autocompleteImpl) queryResults(
+	ctx context.Context, key, query string, options providers.QueryOptions, fallback bool,
+) ([]Result, error) {
+	if a.config.Options.ResultLess == nil {
+		providerResults, err := a.provider.Query(ctx, key, query, options)
+		if err != nil {
+			return nil, err
+		}
+		return a.convertResults(ctx, providerResults, fallback), nil
+	}
+
+	offset, end := options.Offset, options.Offset+options.MaxResults
+	options.Offset = 0
+	options.MaxResults = end
+	var providerResults []providers.ProviderResult
+	for {
+		var err error
+		providerResults, err = a.provider.Query(ctx, key, query, options)
+		if err != nil {
+			return nil, err
+		}
+		// A full fetch whose last result is tied with the end of the page may
+		// have cut the tie short, so fetch twice as many and look again.
+		n := len(providerResults)
+		if n < options.MaxResults || n < end || end == 0 || providerResults[n-1].Score != providerResults[end-1].Score {
+			break
+		}
+		options.MaxResults *= 2
+	}
+
+	results := a.orderTies(a.convertResults(ctx, providerResults, fallback))
+	if offset >= len(results) {
+		return []Result{}, nil
+	}
+	return results[offset:min(end, len(results))], nil
+}
+
+// orderTies sorts each run of adjacent results with the same score with
+// Options.ResultLess, leaving the order of differently scored results as the
+// provider ranked them.
+func (a *autocompleteImpl) orderTies(results []Result) []Result {
+	less := a.config.Options.ResultLess
+	if less == nil {
+		return results
+	}
+	for start := 0; start < len(results); {
+		stop := start + 1
+		for stop < len(results) && results[stop].Score == results[start].Score {
+			stop++
+		}
+		slices.SortStableFunc(results[start:stop], func(x, y Result) int {
+			switch {
+			case less(x, y):
+				return -1
+			case less(y, x):
+				return 1
+			default:
+				return 0
+			}
+		})
+		start = stop
+	}
+	return results
+}
@@ -0,0 +1,426 @@
+// Package outbox keeps an AutoComplete instance in step with an OLTP database
+// through a transactional outbox table, without change-data-capture
+// infrastructure. The application writes a row to the outbox in the same
+// transaction as the data it describes; a Consumer applies the rows in
+// sequence order and records the last applied sequence number in a
+// checkpoint table of the same database.
+//
+// The outbox table needs these columns, with seq assigned in increasing order,
+// e.g. from a sequence or an auto-increment column:
+//
+//	seq     BIGINT        -- order of the mutations
+//	id      VARCHAR(255)  -- entry ID
+//	op      VARCHAR(16)   -- "index", "delete", or "delete_all"
+//	payload TEXT          -- for "index", the entry as JSON: {"text":"...","display":"..."}
+//
+// An index payload may also carry the entry's metadata and score, which are
+// honored as by IndexBatch, e.g.
+// {"text":"...","display":"...","metadata":{"state":"MH"},"score":5}; its id
+// field is ignored in favour of the row's.
+//
+// Each row takes effect exactly once: rows at or below the checkpoint are
+// never read again, and a row applied just before a crash, and so applied
+// again after it, is an idempotent write of the same content.
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/remiges-tech/autocomplete"
+)
+
+const (
+	// defaultTable is the outbox table used when Config.Table is empty.
+	defaultTable = "autocomplete_outbox"
+
+	// defaultCheckpointTable is the checkpoint table used when Config.CheckpointTable is empty.
+	defaultCheckpointTable = "autocomplete_outbox_checkpoints"
+
+	// defaultConsumer is the consumer name used when Config.Consumer is empty.
+	defaultConsumer = "autocomplete"
+
+	// defaultBatchSize is the batch size used when Config.BatchSize is zero.
+	defaultBatchSize = 100
+
+	// defaultPollInterval is the interval used when Config.PollInterval is zero.
+	defaultPollInterval = time.Second
+
+	// defaultGapTimeout is the timeout used when Config.GapTimeout is zero.
+	defaultGapTimeout = 10 * time.Second
+
+	// checkpointSchemaTemplate creates the checkpoint table.
+	checkpointSchemaTemplate = `
+CREATE TABLE IF NOT EXISTS %s (
+	consumer VARCHAR(255) NOT NULL PRIMARY KEY,
+	seq      BIGINT NOT NULL
+)`
+)
+
+// Operations of outbox rows.
+const (
+	// OpIndex indexes the entry in the payload.
+	OpIndex = "index"
+	// OpDelete deletes the entry.
+	OpDelete = "delete"
+	// OpDeleteAll deletes all entries of the namespace; the row's id is ignored.
+	OpDeleteAll = "delete_all"
+)
+
+// tableNamePattern restricts table names to plain, optionally schema-qualified identifiers,
+// since they are interpolated into SQL.
+var tableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// Placeholder selects how statement parameters are written.
+type Placeholder int
+
+const (
+	// PlaceholderQuestion writes parameters as "?", as MySQL, MariaDB, and SQLite expect.
+	PlaceholderQuestion Placeholder = iota
+
+	// PlaceholderDollar writes parameters as "$1", "$2", ..., as PostgreSQL expects.
+	PlaceholderDollar
+)
+
+// Config holds the tables and polling options of a Consumer.
+type Config struct {
+	// Placeholder selects the parameter syntax of the driver.
+	// Default: PlaceholderQuestion
+	Placeholder Placeholder
+
+	// Table is the outbox table.
+	// Default: "autocomplete_outbox"
+	Table string
+
+	// CheckpointTable holds the last applied sequence number of each consumer.
+	// Default: "autocomplete_outbox_checkpoints"
+	CheckpointTable string
+
+	// Consumer names the checkpoint, so that several instances can consume the
+	// same outbox independently. Consumers sharing a name take turns.
+	// Default: "autocomplete"
+	Consumer string
+
+	// BatchSize is the number of rows applied per poll.
+	// Default: 100
+	BatchSize int
+
+	// PollInterval is the pause between polls that find no rows.
+	// Default: 1 second
+	PollInterval time.Duration
+
+	// GapTimeout is how long a missing sequence number is waited for before it
+	// is skipped. Transactions may commit out of sequence order, so a gap can
+	// be a row still being written; it can also be a rolled-back transaction,
+	// which leaves the gap for good.
+	// Default: 10 seconds
+	GapTimeout time.Duration
+
+	// SkipSchemaSetup disables creating the checkpoint table on startup
+	// (see Schema).
+	SkipSchemaSetup bool
+
+	// OnSkip, if set, is called for rows that cannot be applied, such as rows
+	// with an unknown op or an invalid payload, which are skipped.
+	OnSkip func(seq int64, err error)
+
+	// OnError, if set, is called by Run when a poll fails. Run retries after PollInterval.
+	OnError func(err error)
+}
+
+// setDefaults applies default values to config fields.
+func (c *Config) setDefaults() {
+	if c.Table == "" {
+		c.Table = defaultTable
+	}
+	if c.CheckpointTable == "" {
+		c.CheckpointTable = defaultCheckpointTable
+	}
+	if c.Consumer == "" {
+		c.Consumer = defaultConsumer
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = defaultBatchSize
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = defaultPollInterval
+	}
+	if c.GapTimeout <= 0 {
+		c.GapTimeout = defaultGapTimeout
+	}
+}
+
+// Consumer applies outbox rows to an AutoComplete instance.
+// All methods are safe for concurrent use.
+type Consumer struct {
+	db     *sql.DB
+	target autocomplete.AutoComplete
+	config Config
+
+	// mu serializes polls of this consumer.
+	mu sync.Mutex
+
+	// gapSeq is the missing sequence number being waited for since gapSince.
+	gapSeq   int64
+	gapSince time.Time
+}
+
+// row is an outbox row.
+type row struct {
+	seq     int64
+	id      string
+	op      string
+	payload sql.NullString
+}
+
+// New creates a Consumer applying the outbox rows in db to target. Unless
+// SkipSchemaSetup is set, it creates the checkpoint table.
+func New(db *sql.DB, target autocomplete.AutoComplete, config Config) (*Consumer, error) {
+	if db == nil || target == nil {
+		return nil, errors.New("database and target are required")
+	}
+	config.setDefaults()
+	for _, table := range []string{config.Table, config.CheckpointTable} {
+		if !tableNamePattern.MatchString(table) {
+			return nil, fmt.Errorf("invalid table name %q", table)
+		}
+	}
+
+	if !config.SkipSchemaSetup {
+		if _, err := db.ExecContext(context.Background(), Schema(config.CheckpointTable)); err != nil {
+			return nil, fmt.Errorf("failed to create schema: %w", err)
+		}
+	}
+	return &Consumer{db: db, target: target, config: config}, nil
+}
+
+// Schema returns the SQL that creates the checkpoint table, for use in
+// migrations when Config.SkipSchemaSetup is set.
+func Schema(checkpointTable string) string {
+	return fmt.Sprintf(checkpointSchemaTemplate, checkpointTable)
+}
+
+// Run polls the outbox until ctx is done, then returns ctx.Err().
+func (c *Consumer) Run(ctx context.Context) error {
+	for {
+		applied, err := c.Poll(ctx)
+		if err != nil && ctx.Err() == nil && c.config.OnError != nil {
+			c.config.OnError(err)
+		}
+		if err == nil && applied == c.config.BatchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.config.PollInterval):
+		}
+	}
+}
+
+// Checkpoint returns the sequence number of the last applied row. Rows at or
+// below it will not be read again and can be deleted from the outbox.
+func (c *Consumer) Checkpoint(ctx context.Context) (int64, error) {
+	var seq int64
+	err := c.db.QueryRowContext(ctx,
+		fmt.Sprintf("SELECT seq FROM %s WHERE consumer = %s", c.config.CheckpointTable, c.param(1)),
+		c.config.Consumer).Scan(&seq)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	return seq, nil
+}
+
+// Poll applies the next batch of rows after the checkpoint and advances it,
+// returning the number of rows consumed. It stops early at a missing sequence
+// number until GapTimeout passes, and at the first row the target fails to
+// write; rows applied before the failure stay checkpointed.
+func (c *Consumer) Poll(ctx context.Context) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	checkpoint, err := c.lockCheckpoint(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
+	rows, err := c.readRows(ctx, tx, checkpoint)
+	if err != nil {
+		return 0, err
+	}
+
+	last := checkpoint
+	consumed := 0
+	var applyErr error
+	for _, r := range rows {
+		if r.seq != last+1 && !c.gapExpired(last+1) {
+			break
+		}
+		if applyErr = c.apply(ctx, r); applyErr != nil {
+			break
+		}
+		last = r.seq
+		consumed++
+	}
+
+	if last != checkpoint {
+		_, err := tx.ExecContext(ctx,
+			fmt.Sprintf("UPDATE %s SET seq = %s WHERE consumer = %s", c.config.CheckpointTable, c.param(1), c.param(2)),
+			last, c.config.Consumer)
+		if err != nil {
+			return 0, fmt.Errorf("failed to update checkpoint: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit checkpoint: %w", err)
+	}
+	return consumed, applyErr
+}
+
+// lockCheckpoint reads the checkpoint inside tx, creating it if needed. The
+// checkpoint row is written first, so that consumers sharing the name wait
+// for each other, and read again once locked.
+func (c *Consumer) lockCheckpoint(ctx context.Context, tx *sql.Tx) (int64, error) {
+	selectStatement := fmt.Sprintf("SELECT seq FROM %s WHERE consumer = %s", c.config.CheckpointTable, c.param(1))
+	var seq int64
+	err := tx.QueryRowContext(ctx, selectStatement, c.config.Consumer).Scan(&seq)
+	if errors.Is(err, sql.ErrNoRows) {
+		_, err := tx.ExecContext(ctx,
+			fmt.Sprintf("INSERT INTO %s (consumer, seq) VALUES (%s, 0)", c.config.CheckpointTable, c.param(1)),
+			c.config.Consumer)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create checkpoint: %w", err)
+		}
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		fmt.Sprintf("UPDATE %s SET seq = seq WHERE consumer = %s", c.config.CheckpointTable, c.param(1)),
+		c.config.Consumer)
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock checkpoint: %w", err)
+	}
+	if err := tx.QueryRowContext(ctx, selectStatement, c.config.Consumer).Scan(&seq); err != nil {
+		return 0, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	return seq, nil
+}
+
+// readRows reads the next batch of rows after the checkpoint.
+func (c *Consumer) readRows(ctx context.Context, tx *sql.Tx, checkpoint int64) ([]row, error) {
+	result, err := tx.QueryContext(ctx,
+		fmt.Sprintf("SELECT seq, id, op, payload FROM %s WHERE seq > %s ORDER BY seq LIMIT %s",
+			c.config.Table, c.param(1), c.param(2)),
+		checkpoint, c.config.BatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read outbox: %w", err)
+	}
+	defer result.Close()
+
+	var rows []row
+	for result.Next() {
+		var r row
+		if err := result.Scan(&r.seq, &r.id, &r.op, &r.payload); err != nil {
+			return nil, fmt.Errorf("failed to read outbox: %w", err)
+		}
+		rows = append(rows, r)
+	}
+	if err := result.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read outbox: %w", err)
+	}
+	return rows, nil
+}
+
+// gapExpired reports whether the missing sequence number seq has been waited
+// for longer than GapTimeout, starting the wait on the first call for it.
+func (c *Consumer) gapExpired(seq int64) bool {
+	if c.gapSeq != seq {
+		c.gapSeq = seq
+		c.gapSince = time.Now()
+	}
+	return time.Since(c.gapSince) >= c.config.GapTimeout
+}
+
+// apply writes a row to the target. Rows that can never be applied are
+// reported to OnSkip and count as applied.
+func (c *Consumer) apply(ctx context.Context, r row) error {
+	var err error
+	switch r.op {
+	case OpIndex:
+		var entry autocomplete.Entry
+		if err := json.Unmarshal([]byte(r.payload.String), &entry); err != nil {
+			c.skip(r.seq, fmt.Errorf("invalid payload: %w", err))
+			return nil
+		}
+		entry.ID = r.id
+		err = indexEntry(ctx, c.target, entry)
+	case OpDelete:
+		err = c.target.Delete(ctx, r.id)
+	case OpDeleteAll:
+		err = c.target.DeleteAll(ctx)
+	default:
+		c.skip(r.seq, fmt.Errorf("unknown op %q", r.op))
+		return nil
+	}
+
+	if isInvalidEntry(err) {
+		c.skip(r.seq, err)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply outbox row %d: %w", r.seq, err)
+	}
+	return nil
+}
+
+// indexEntry writes the entry of an index row to target, with its metadata and
+// score if it has them.
+func indexEntry(ctx context.Context, target autocomplete.AutoComplete, entry autocomplete.Entry) error {
+	if len(entry.Metadata) > 0 || entry.Score != 0 {
+		return target.IndexBatch(ctx, []autocomplete.Entry{entry})[0].Err
+	}
+	return target.Index(ctx, entry.ID, entry.Text, entry.Display)
+}
+
+// skip reports a row that cannot be applied.
+func (c *Consumer) skip(seq int64, err error) {
+	if c.config.OnSkip != nil {
+		c.config.OnSkip(seq, err)
+	}
+}
+
+// isInvalidEntry reports whether err rejects the entry itself, so that
+// retrying cannot succeed.
+func isInvalidEntry(err error) bool {
+	return errors.Is(err, autocomplete.ErrEmptyID) ||
+		errors.Is(err, autocomplete.ErrEmptyText) ||
+		errors.Is(err, autocomplete.ErrEmptyDisplay) ||
+		errors.Is(err, autocomplete.ErrInvalidMetadata) ||
+		errors.Is(err, autocomplete.ErrTokenBudgetExceeded)
+}
+
+// param returns the placeholder of the nth statement parameter, counted from 1.
+func (c *Consumer) param(n int) string {
+	if c.config.Placeholder == PlaceholderDollar {
+		return "$" + strconv.Itoa(n)
+	}
+	return "?"
+}
@@ -0,0 +1,183 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	// Registers the "sqlite" driver the tests run the consumer on.
+	_ "modernc.org/sqlite"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers/memory"
+)
+
+const outboxSchema = `
+CREATE TABLE autocomplete_outbox (
+	seq     INTEGER PRIMARY KEY,
+	id      TEXT NOT NULL,
+	op      TEXT NOT NULL,
+	payload TEXT
+)`
+
+func newTestDB(t *testing.T) *sql.DB {
+	path := filepath.Join(t.TempDir(), "app.db")
+	db, err := sql.Open("sqlite", fmt.Sprintf("file:%s?_pragma=busy_timeout(5000)", path))
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if _, err := db.Exec(outboxSchema); err != nil {
+		t.Fatalf("failed to create outbox: %v", err)
+	}
+	return db
+}
+
+// newTarget creates an in-memory instance with prefix matching.
+func newTarget(t *testing.T) autocomplete.AutoComplete {
+	options := autocomplete.DefaultOptions()
+	options.MatchStrategy = autocomplete.MatchPrefix
+	ac, err := autocomplete.New("memory", autocomplete.NewConfigWithOptions(memory.Config{}, options))
+	if err != nil {
+		t.Fatalf("autocomplete.New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = ac.Close() })
+	return ac
+}
+
+func insert(t *testing.T, db *sql.DB, seq int64, id, op, payload string) {
+	if _, err := db.Exec("INSERT INTO autocomplete_outbox (seq, id, op, payload) VALUES (?, ?, ?, ?)", seq, id, op, payload); err != nil {
+		t.Fatalf("failed to insert outbox row %d: %v", seq, err)
+	}
+}
+
+func queryIDs(t *testing.T, ac autocomplete.AutoComplete, query string) string {
+	results, err := ac.Query(context.Background(), query, 10)
+	if err != nil {
+		t.Fatalf("Query(%q) error = %v", query, err)
+	}
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+	return fmt.Sprint(ids)
+}
+
+func TestConsumerAppliesRowsInOrder(t *testing.T) {
+	db, target := newTestDB(t), newTarget(t)
+	var skipped []int64
+	consumer, err := New(db, target, Config{BatchSize: 2, OnSkip: func(seq int64, err error) { skipped = append(skipped, seq) }})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	insert(t, db, 1, "1", OpIndex, `{"text":"Mumbai","display":"Mumbai"}`)
+	insert(t, db, 2, "2", OpIndex, `{"text":"Mumbra","display":"Mumbra"}`)
+	insert(t, db, 3, "1", OpIndex, `{"text":"Mumbai Central","display":"Mumbai Central"}`)
+	insert(t, db, 4, "2", OpDelete, "")
+	insert(t, db, 5, "3", "rename", "")
+	insert(t, db, 6, "4", OpIndex, `{"text":"","display":"empty"}`)
+
+	ctx := context.Background()
+	for _, want := range []int{2, 2, 2, 0} {
+		applied, err := consumer.Poll(ctx)
+		if err != nil || applied != want {
+			t.Fatalf("Poll() = %d, %v; want %d rows", applied, err, want)
+		}
+	}
+	if got := queryIDs(t, target, "mum"); got != "[1]" {
+		t.Errorf("Query() = %v, want [1]", got)
+	}
+	if fmt.Sprint(skipped) != "[5 6]" {
+		t.Errorf("skipped rows = %v, want [5 6]", skipped)
+	}
+	if checkpoint, err := consumer.Checkpoint(ctx); err != nil || checkpoint != 6 {
+		t.Errorf("Checkpoint() = %d, %v; want 6", checkpoint, err)
+	}
+
+	// A new consumer with the same name resumes after the checkpoint
+	insert(t, db, 7, "", OpDeleteAll, "")
+	resumed, err := New(db, target, Config{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if applied, err := resumed.Poll(ctx); err != nil || applied != 1 {
+		t.Fatalf("Poll() after restart = %d, %v; want only the new row", applied, err)
+	}
+	if got := queryIDs(t, target, "mum"); got != "[]" {
+		t.Errorf("Query() after delete_all = %v, want []", got)
+	}
+}
+
+func TestConsumerIndexesMetadataAndScore(t *testing.T) {
+	db, target := newTestDB(t), newTarget(t)
+	consumer, err := New(db, target, Config{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	insert(t, db, 1, "1", OpIndex, `{"id":"ignored","text":"Mumbai","display":"Mumbai","metadata":{"state":"MH"}}`)
+	insert(t, db, 2, "2", OpIndex, `{"text":"Mumbra","display":"Mumbra","score":5}`)
+	ctx := context.Background()
+	if applied, err := consumer.Poll(ctx); err != nil || applied != 2 {
+		t.Fatalf("Poll() = %d, %v; want 2 rows", applied, err)
+	}
+	results, err := target.Query(ctx, "mum", 10)
+	if err != nil || len(results) != 2 {
+		t.Fatalf("Query() = %v, %v; want both entries", results, err)
+	}
+	if results[0].ID != "2" || results[0].Score != 5 {
+		t.Errorf("first result = %+v, want entry 2 at the payload's score", results[0])
+	}
+	if results[1].ID != "1" || string(results[1].Metadata) != `{"state":"MH"}` {
+		t.Errorf("second result = %+v, want entry 1 with the payload's metadata", results[1])
+	}
+}
+
+func TestConsumerWaitsForGaps(t *testing.T) {
+	db, target := newTestDB(t), newTarget(t)
+	consumer, err := New(db, target, Config{GapTimeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	// Row 2 is still being written when row 3 commits
+	insert(t, db, 1, "1", OpIndex, `{"text":"Pune","display":"Pune"}`)
+	insert(t, db, 3, "3", OpIndex, `{"text":"Punjab","display":"Punjab"}`)
+	if applied, err := consumer.Poll(ctx); err != nil || applied != 1 {
+		t.Fatalf("Poll() = %d, %v; want to stop at the gap", applied, err)
+	}
+	insert(t, db, 2, "2", OpIndex, `{"text":"Puducherry","display":"Puducherry"}`)
+	if applied, err := consumer.Poll(ctx); err != nil || applied != 2 {
+		t.Fatalf("Poll() = %d, %v; want the filled gap and the row after it", applied, err)
+	}
+
+	// Row 4 was rolled back and never appears
+	insert(t, db, 5, "5", OpIndex, `{"text":"Punalur","display":"Punalur"}`)
+	if applied, _ := consumer.Poll(ctx); applied != 0 {
+		t.Fatalf("Poll() = %d, want to wait for the gap", applied)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if applied, err := consumer.Poll(ctx); err != nil || applied != 1 {
+		t.Fatalf("Poll() after GapTimeout = %d, %v; want the row after the gap", applied, err)
+	}
+	if checkpoint, err := consumer.Checkpoint(ctx); err != nil || checkpoint != 5 {
+		t.Errorf("Checkpoint() = %d, %v; want 5", checkpoint, err)
+	}
+	for _, query := range []string{"pune", "puducherry", "punjab", "punalur"} {
+		if got := queryIDs(t, target, query); got == "[]" {
+			t.Errorf("Query(%q) found nothing, want the entry applied", query)
+		}
+	}
+}
+
+func TestNewRejectsInvalidTable(t *testing.T) {
+	db, target := newTestDB(t), newTarget(t)
+	if _, err := New(db, target, Config{Table: "outbox; DROP TABLE users"}); err == nil {
+		t.Error("New() should reject an invalid table name")
+	}
+}
@@ -0,0 +1,121 @@
+package autocomplete
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// pageCursor is the decoded form of the cursors returned by QueryPage.
+type pageCursor struct {
+	// Query fingerprints the query the cursor was returned for.
+	Query string `json:"q"`
+
+	// Provider is the provider's cursor, when the provider pages the query.
+	Provider string `json:"p,omitempty"`
+
+	// Offset is the number of results before the page, when the query is
+	// paged by fetching them again.
+	Offset int `json:"o,omitempty"`
+}
+
+// QueryPage returns a page of the results of a query and the cursor of the next.
+// See AutoComplete.QueryPage for details.
+func (a *autocompleteImpl) QueryPage(ctx context.Context, query string, limit int, cursor string) ([]Result, string, error) {
+	query = a.normalize(query)
+	options, err := a.queryOptions(ctx, query, limit)
+	if err != nil {
+		return nil, "", err
+	}
+	namespace := a.queryNamespace(ctx)
+	fingerprint := pageFingerprint(namespace, query, options)
+	if a.foldedQuery(options) {
+		namespace += foldedNamespaceSuffix
+	}
+
+	var position pageCursor
+	if cursor != "" {
+		position, err = decodePageCursor(cursor)
+		if err != nil || position.Query != fingerprint {
+			return nil, "", ErrInvalidCursor
+		}
+	}
+
+	if pager, ok := a.provider.(providers.PagedQuerier); ok && position.Offset == 0 {
+		providerResults, next, err := pager.QueryPage(ctx, namespace, query, options, position.Provider)
+		if err == nil {
+			results := boostResults(ctx, a.orderTies(a.convertResults(ctx, providerResults, false)))
+			return results, encodePageCursor(pageCursor{Query: fingerprint, Provider: next}, next != ""), nil
+		}
+		if !errors.Is(err, providers.ErrPagingUnsupported) {
+			return nil, "", err
+		}
+	}
+
+	// Without provider support, the page is cut from the results up to its
+	// end, fetching one more to tell whether another page follows
+	offset := position.Offset
+	end := min(offset+options.MaxResults, a.tuning().maxLimit)
+	options.MaxResults = end + 1
+	results, err := a.queryResults(ctx, namespace, query, options, false)
+	if err != nil {
+		return nil, "", err
+	}
+	more := len(results) > end && end < a.tuning().maxLimit
+	if offset >= len(results) {
+		return []Result{}, "", nil
+	}
+	page := boostResults(ctx, results[offset:min(end, len(results))])
+	return page, encodePageCursor(pageCursor{Query: fingerprint, Offset: end}, more), nil
+}
+
+// pageFingerprint identifies a query among cursors by everything that decides
+// its results other than its limit, which may change from page to page.
+//
+//nolint:gocritic // hugeParam: options is passed by value to match the Provider interface
+func pageFingerprint(namespace, query string, options providers.QueryOptions) string {
+	h := sha256.New()
+	writeHashField(h, namespace)
+	writeHashField(h, query)
+	writeHashField(h, strconv.Itoa(int(options.MatchStrategy)))
+	writeHashField(h, strconv.FormatBool(options.CaseSensitive))
+	writeHashField(h, strconv.FormatFloat(options.MinScore, 'g', -1, 64))
+	if len(options.FilterMetadata) > 0 {
+		// Marshalled maps have sorted keys, so equal filters give equal fingerprints
+		filter, _ := json.Marshal(options.FilterMetadata)
+		writeHashField(h, string(filter))
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// encodePageCursor returns the opaque form of position, or "" when there is
+// no next page.
+func encodePageCursor(position pageCursor, more bool) string {
+	if !more {
+		return ""
+	}
+	encoded, _ := json.Marshal(position)
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+// decodePageCursor parses a cursor returned by QueryPage.
+func decodePageCursor(cursor string) (pageCursor, error) {
+	encoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return pageCursor{}, err
+	}
+	var position pageCursor
+	if err := json.Unmarshal(encoded, &position); err != nil {
+		return pageCursor{}, err
+	}
+	if position.Offset < 0 {
+		return pageCursor{}, errors.New("negative offset")
+	}
+	return position, nil
+}
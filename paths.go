@@ -0,0 +1,44 @@
+package autocomplete
+
+import "strings"
+
+// PathOptions returns options for autocompleting file paths, e.g. in path pickers:
+//
+//	ac, err := autocomplete.New("memory", autocomplete.NewConfigWithOptions(nil, autocomplete.PathOptions()))
+//
+// Entries match by prefix from the start of the path and from the start of
+// each of its "/"-separated segments (PathSegments), so "main" finds
+// "cmd/server/main.go". Shallower paths score higher (PathDepthScore), so
+// providers that rank by score list "docs/" before "docs/api/". Only the first
+// eight segments after the first are matched on their own.
+func PathOptions() Options {
+	options := DefaultOptions()
+	options.MatchStrategy = MatchPrefix
+	options.Segmenter = PathSegments
+	options.Scorer = PathDepthScore
+	return options
+}
+
+// PathSegments is a Segmenter that starts a segment after each "/" of a path,
+// except at the end of the path and within runs of slashes.
+func PathSegments(text string) []int {
+	var offsets []int
+	for i := 0; i < len(text)-1; i++ {
+		if text[i] == '/' && text[i+1] != '/' {
+			offsets = append(offsets, i+1)
+		}
+	}
+	return offsets
+}
+
+// PathDepthScore is a Scorer that scores a path by the inverse of its number of
+// non-empty segments: 1 for "src", 0.5 for "src/main.go", and so on.
+func PathDepthScore(text string) float64 {
+	depth := 0
+	for _, segment := range strings.Split(text, "/") {
+		if segment != "" {
+			depth++
+		}
+	}
+	return 1 / float64(max(depth, 1))
+}
@@ -0,0 +1,48 @@
+package autocomplete
+
+import "strings"
+
+// PhoneOptions returns options for autocompleting phone numbers, e.g. in CRMs:
+//
+//	ac, err := autocomplete.New("memory", autocomplete.NewConfigWithOptions(nil, autocomplete.PhoneOptions(4)))
+//
+// Numbers and queries are reduced to their digits (NormalizePhone), so
+// "+91 98765-43210" and "919876543210" index and query alike, and entries match
+// by prefix. When suffixDigits is positive, entries also match by the start of
+// their last suffixDigits digits (PhoneSuffix), so "3210" finds the number above.
+// Index numbers in one format, with or without the country code, since a
+// prefix query only matches numbers written the same way.
+func PhoneOptions(suffixDigits int) Options {
+	options := DefaultOptions()
+	options.MatchStrategy = MatchPrefix
+	options.Normalizer = NormalizePhone
+	if suffixDigits > 0 {
+		options.Segmenter = PhoneSuffix(suffixDigits)
+	}
+	return options
+}
+
+// NormalizePhone is a Normalizer that keeps only the digits of a phone number,
+// dropping "+", spaces, dashes, dots, and parentheses.
+func NormalizePhone(text string) string {
+	var b strings.Builder
+	b.Grow(len(text))
+	for i := 0; i < len(text); i++ {
+		if text[i] >= '0' && text[i] <= '9' {
+			b.WriteByte(text[i])
+		}
+	}
+	return b.String()
+}
+
+// PhoneSuffix returns a Segmenter that starts a segment n bytes before the end
+// of the text, so that with NormalizePhone prefix queries also match the last
+// n digits of a number. Numbers of n digits or fewer have no such segment.
+func PhoneSuffix(n int) Segmenter {
+	return func(text string) []int {
+		if len(text) <= n {
+			return nil
+		}
+		return []int{len(text) - n}
+	}
+}
@@ -0,0 +1,69 @@
+package autocomplete
+
+import (
+	"fmt"
+	"plugin"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// PluginSymbol is the function a provider plugin exports for LoadPlugin.
+const PluginSymbol = "RegisterAutocompleteProviders"
+
+// MapProviderFactory creates a Provider from a generic configuration map, such
+// as a section of a JSON or YAML configuration file.
+type MapProviderFactory func(config map[string]any) (providers.Provider, error)
+
+// RegisterMapProvider registers a factory taking a configuration map, so that
+// a backend can be configured without its Config type being importable, e.g.
+// a closed-source backend maintained outside this module. New passes it
+// Config.ProviderConfig, which must be a map[string]any or nil:
+//
+//	autocomplete.RegisterMapProvider("inhouse", inhouse.NewFromMap)
+//
+//	config := autocomplete.NewConfig(map[string]any{"endpoint": "http://search.internal"})
+//	ac, err := autocomplete.New("inhouse", config)
+func RegisterMapProvider(name string, factory MapProviderFactory) {
+	RegisterProvider(name, func(config interface{}) (providers.Provider, error) {
+		if config == nil {
+			return factory(map[string]any{})
+		}
+		configMap, ok := config.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("invalid configuration type for %s provider: expected map[string]any, got %T", name, config)
+		}
+		return factory(configMap)
+	})
+}
+
+// LoadPlugin opens a Go plugin, built with "go build -buildmode=plugin", and
+// registers the providers it contains, so that backends can be added to a
+// binary without importing them. The plugin exports a function named
+// PluginSymbol that registers its providers through the callback it is given:
+//
+//	func RegisterAutocompleteProviders(register func(name string, factory func(map[string]any) (providers.Provider, error))) {
+//	    register("inhouse", NewFromMap)
+//	}
+//
+// Providers are registered as by RegisterMapProvider. The plugin must be built
+// with the same Go version and the same version of this module as the binary.
+// Go plugins are supported on Linux, FreeBSD, and macOS with cgo enabled.
+func LoadPlugin(path string) error {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open plugin: %w", err)
+	}
+	symbol, err := p.Lookup(PluginSymbol)
+	if err != nil {
+		return fmt.Errorf("failed to load plugin %s: %w", path, err)
+	}
+	register, ok := symbol.(func(func(string, func(map[string]any) (providers.Provider, error))))
+	if !ok {
+		return fmt.Errorf("failed to load plugin %s: %s has type %T", path, PluginSymbol, symbol)
+	}
+
+	register(func(name string, factory func(map[string]any) (providers.Provider, error)) {
+		RegisterMapProvider(name, factory)
+	})
+	return nil
+}
@@ -0,0 +1,239 @@
+package algolia
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/algolia/algoliasearch-client-go/v4/algolia/call"
+	"github.com/algolia/algoliasearch-client-go/v4/algolia/search"
+	"github.com/algolia/algoliasearch-client-go/v4/algolia/transport"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// defaultMaxResults is the default maximum number of results if not specified.
+const defaultMaxResults = 10
+
+// Provider implements the autocomplete Provider interface using Algolia.
+// Each namespace is stored in its own index, named Config.IndexPrefix + namespace.
+//
+// The match strategy picks Algolia's query type: MatchPrefix treats only the
+// last query word as a prefix, while the substring and n-gram strategies treat
+// every query word as one. Algolia has no infix search, so words only match
+// from their start. Matching is always case-insensitive and typo-tolerant.
+// Results keep Algolia's ranking, with the entry score as custom ranking.
+type Provider struct {
+	client *search.APIClient
+	config Config
+
+	// ready holds the names of indexes whose settings have been applied.
+	ready sync.Map
+}
+
+// New creates an Algolia provider.
+func New(config Config) (*Provider, error) {
+	config.setDefaults()
+
+	clientConfig := search.SearchConfiguration{
+		Configuration: transport.Configuration{AppID: config.AppID, ApiKey: config.APIKey},
+	}
+	for _, host := range config.Hosts {
+		u, err := url.Parse(host)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid Algolia host %q", host)
+		}
+		clientConfig.Hosts = append(clientConfig.Hosts, transport.NewStatefulHost(u.Scheme, u.Host, func(call.Kind) bool { return true }))
+	}
+
+	client, err := search.NewClientWithConfig(clientConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Algolia client: %w", err)
+	}
+	return &Provider{client: client, config: config}, nil
+}
+
+// Index adds or updates an entry in the namespace's index.
+func (p *Provider) Index(ctx context.Context, key, id, text, display string, options providers.IndexOptions) error {
+	name, err := p.ensureIndex(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	object := map[string]any{
+		"text":    text,
+		"display": display,
+		"score":   options.Score,
+	}
+	if options.ContentHash != "" {
+		object["content_hash"] = options.ContentHash
+	}
+	response, err := p.client.AddOrUpdateObject(p.client.NewApiAddOrUpdateObjectRequest(name, id, object), search.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to index object: %w", err)
+	}
+	return p.wait(ctx, name, response.GetTaskID())
+}
+
+// ensureIndex applies the settings of the index holding a namespace, once per
+// index for the lifetime of the provider. Algolia creates indexes on first write.
+func (p *Provider) ensureIndex(ctx context.Context, key string) (string, error) {
+	name := p.indexName(key)
+	if _, ready := p.ready.Load(name); ready {
+		return name, nil
+	}
+
+	settings := search.NewEmptyIndexSettings().
+		SetSearchableAttributes([]string{"text"}).
+		SetCustomRanking([]string{"desc(score)"})
+	response, err := p.client.SetSettings(p.client.NewApiSetSettingsRequest(name, settings), search.WithContext(ctx))
+	if err != nil {
+		return "", fmt.Errorf("failed to configure index %s: %w", name, err)
+	}
+	if err := p.wait(ctx, name, response.TaskID); err != nil {
+		return "", err
+	}
+
+	p.ready.Store(name, struct{}{})
+	return name, nil
+}
+
+// wait waits for a task to be applied if Config.WaitForTasks is set.
+func (p *Provider) wait(ctx context.Context, name string, taskID int64) error {
+	if !p.config.WaitForTasks {
+		return nil
+	}
+	if _, err := p.client.WaitForTask(name, taskID, search.WithContext(ctx)); err != nil {
+		return fmt.Errorf("failed to wait for task %d: %w", taskID, err)
+	}
+	return nil
+}
+
+// ContentHash returns the stored content hash for an entry and whether it exists.
+func (p *Provider) ContentHash(ctx context.Context, key, id string) (string, bool, error) {
+	request := p.client.NewApiGetObjectRequest(p.indexName(key), id).WithAttributesToRetrieve([]string{"content_hash"})
+	object, err := p.client.GetObject(request, search.WithContext(ctx))
+	if hasStatus(err, http.StatusNotFound) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get object: %w", err)
+	}
+	hash, _ := (*object)["content_hash"].(string)
+	return hash, true, nil
+}
+
+// SupportsMatchStrategies reports that entries can be queried under any combination
+// of strategies, since Algolia indexes text the same way for all of them.
+func (p *Provider) SupportsMatchStrategies(strategies []providers.MatchStrategy) bool {
+	return true
+}
+
+// Query searches the namespace for entries matching the query.
+//
+// Results are in Algolia's ranking order. The score of each result is derived
+// from its ranking info: the entry score divided by one plus the number of
+// typos in the match, so exact matches keep their entry score and typo-tolerant
+// matches score lower.
+func (p *Provider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	if strings.TrimSpace(query) == "" {
+		return []providers.ProviderResult{}, nil
+	}
+
+	request := p.client.NewApiSearchSingleIndexRequest(p.indexName(key)).
+		WithSearchParams(search.SearchParamsObjectAsSearchParams(searchParams(query, options)))
+	response, err := p.client.SearchSingleIndex(request, search.WithContext(ctx))
+	if hasStatus(err, http.StatusNotFound) {
+		return []providers.ProviderResult{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute search: %w", err)
+	}
+
+	results := make([]providers.ProviderResult, 0, len(response.Hits))
+	for _, hit := range response.Hits {
+		display, _ := hit.AdditionalProperties["display"].(string)
+		score, _ := hit.AdditionalProperties["score"].(float64)
+		if hit.RankingInfo != nil {
+			score /= float64(1 + hit.RankingInfo.NbTypos)
+		}
+		results = append(results, providers.ProviderResult{ID: hit.ObjectID, Display: display, Score: score})
+	}
+	return results, nil
+}
+
+// searchParams builds the search parameters for a query.
+func searchParams(query string, options providers.QueryOptions) *search.SearchParamsObject {
+	size := options.MaxResults
+	if size <= 0 {
+		size = defaultMaxResults
+	}
+
+	queryType := search.QUERY_TYPE_PREFIX_ALL
+	if options.MatchStrategy == providers.MatchPrefix {
+		queryType = search.QUERY_TYPE_PREFIX_LAST
+	}
+
+	params := search.NewEmptySearchParamsObject().
+		SetQuery(query).
+		SetQueryType(queryType).
+		SetOffset(int32(options.Offset)).
+		SetLength(int32(size)).
+		SetGetRankingInfo(true).
+		SetAttributesToRetrieve([]string{"display", "score"}).
+		SetAttributesToHighlight([]string{})
+	if options.MinScore > 0 {
+		params.SetNumericFilters(search.StringAsNumericFilters("score>=" + strconv.FormatFloat(options.MinScore, 'g', -1, 64)))
+	}
+	return params
+}
+
+// Delete removes an entry from the namespace's index.
+func (p *Provider) Delete(ctx context.Context, key, id string) error {
+	name := p.indexName(key)
+	response, err := p.client.DeleteObject(p.client.NewApiDeleteObjectRequest(name, id), search.WithContext(ctx))
+	if hasStatus(err, http.StatusNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+	return p.wait(ctx, name, response.TaskID)
+}
+
+// DeleteAll removes all entries for a given key namespace by deleting its index.
+// The index is recreated, with its settings, on the next write.
+func (p *Provider) DeleteAll(ctx context.Context, key string) error {
+	name := p.indexName(key)
+	p.ready.Delete(name)
+
+	response, err := p.client.DeleteIndex(p.client.NewApiDeleteIndexRequest(name), search.WithContext(ctx))
+	if hasStatus(err, http.StatusNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete index %s: %w", name, err)
+	}
+	return p.wait(ctx, name, response.TaskID)
+}
+
+// Close is a no-op; the client holds no resources that need releasing.
+func (p *Provider) Close() error {
+	return nil
+}
+
+// indexName returns the name of the index holding a namespace.
+func (p *Provider) indexName(key string) string {
+	return p.config.IndexPrefix + key
+}
+
+// hasStatus reports whether err is an Algolia API error with the given HTTP status.
+func hasStatus(err error, status int) bool {
+	var apiErr *search.APIError
+	return errors.As(err, &apiErr) && apiErr.Status == status
+}
@@ -0,0 +1,199 @@
+package algolia
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// fakeAlgolia implements the Algolia endpoints the provider uses. A search
+// matches objects whose text has a word starting with every query word,
+// case-insensitively, ranked by descending score.
+type fakeAlgolia struct {
+	mu         sync.Mutex
+	settings   map[string]map[string]any
+	indexes    map[string]map[string]map[string]any
+	typos      map[string]int
+	lastSearch map[string]any
+	taskPolls  int
+}
+
+func newFakeAlgolia(t *testing.T) (*fakeAlgolia, *Provider) {
+	t.Helper()
+	f := &fakeAlgolia{
+		settings: map[string]map[string]any{},
+		indexes:  map[string]map[string]map[string]any{},
+		typos:    map[string]int{},
+	}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+
+	provider, err := New(Config{AppID: "APP", APIKey: "key", Hosts: []string{server.URL}, WaitForTasks: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return f, provider
+}
+
+func (f *fakeAlgolia) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/1/indexes/"), "/")
+	index := parts[0]
+	task := map[string]any{"taskID": 1, "updatedAt": "2025-01-01T00:00:00Z", "deletedAt": "2025-01-01T00:00:00Z"}
+
+	switch {
+	case len(parts) == 3 && parts[1] == "task":
+		f.taskPolls++
+		writeJSON(w, http.StatusOK, map[string]any{"status": "published", "pendingTask": false})
+
+	case len(parts) == 2 && parts[1] == "settings":
+		var settings map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&settings)
+		f.settings[index] = settings
+		writeJSON(w, http.StatusOK, task)
+
+	case len(parts) == 2 && parts[1] == "query":
+		_ = json.NewDecoder(r.Body).Decode(&f.lastSearch)
+		f.search(w, index)
+
+	case len(parts) == 2 && r.Method == http.MethodPut:
+		var object map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&object)
+		if f.indexes[index] == nil {
+			f.indexes[index] = map[string]map[string]any{}
+		}
+		f.indexes[index][parts[1]] = object
+		writeJSON(w, http.StatusOK, task)
+
+	case len(parts) == 2 && r.Method == http.MethodGet:
+		object, found := f.indexes[index][parts[1]]
+		if !found {
+			writeJSON(w, http.StatusNotFound, map[string]any{"message": "ObjectID does not exist", "status": 404})
+			return
+		}
+		writeJSON(w, http.StatusOK, object)
+
+	case len(parts) == 2 && r.Method == http.MethodDelete:
+		delete(f.indexes[index], parts[1])
+		writeJSON(w, http.StatusOK, task)
+
+	case len(parts) == 1 && r.Method == http.MethodDelete:
+		delete(f.indexes, index)
+		delete(f.settings, index)
+		writeJSON(w, http.StatusOK, task)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeAlgolia) search(w http.ResponseWriter, index string) {
+	query := strings.Fields(strings.ToLower(f.lastSearch["query"].(string)))
+	var hits []map[string]any
+	for id, object := range f.indexes[index] {
+		words := strings.Fields(strings.ToLower(object["text"].(string)))
+		if !allPrefixed(query, words) {
+			continue
+		}
+		hits = append(hits, map[string]any{
+			"objectID":     id,
+			"display":      object["display"],
+			"score":        object["score"],
+			"_rankingInfo": map[string]any{"nbTypos": f.typos[id], "firstMatchedWord": 0, "geoDistance": 0, "nbExactWords": 1, "userScore": 1},
+		})
+	}
+	sort.Slice(hits, func(i, j int) bool { return hits[i]["score"].(float64) > hits[j]["score"].(float64) })
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"hits": hits, "nbHits": len(hits), "page": 0, "nbPages": 1, "hitsPerPage": 20,
+		"processingTimeMS": 1, "query": f.lastSearch["query"], "params": "", "exhaustiveNbHits": true,
+	})
+}
+
+// allPrefixed reports whether every query word starts one of the words.
+func allPrefixed(query, words []string) bool {
+	for _, q := range query {
+		found := false
+		for _, word := range words {
+			found = found || strings.HasPrefix(word, q)
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func TestAlgoliaProvider(t *testing.T) {
+	fake, provider := newFakeAlgolia(t)
+	ctx := context.Background()
+
+	for _, entry := range []providers.IndexEntry{
+		{ID: "1", Text: "Mumbai", Display: "Mumbai, MH", Options: providers.IndexOptions{Score: 2, ContentHash: "h1"}},
+		{ID: "2", Text: "Navi Mumbai", Display: "Navi Mumbai", Options: providers.IndexOptions{Score: 1}},
+	} {
+		if err := provider.Index(ctx, "in", entry.ID, entry.Text, entry.Display, entry.Options); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+	if ranking := fake.settings["autocomplete_in"]["customRanking"]; len(ranking.([]any)) != 1 {
+		t.Errorf("customRanking = %v", ranking)
+	}
+	if fake.taskPolls == 0 {
+		t.Error("writes did not wait for their tasks")
+	}
+
+	fake.typos["2"] = 1
+	results, err := provider.Query(ctx, "in", "mum", providers.QueryOptions{MatchStrategy: providers.MatchPrefix, MinScore: 0.5})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(results) != 2 || results[0].ID != "1" || results[0].Display != "Mumbai, MH" || results[0].Score != 2 || results[1].Score != 0.5 {
+		t.Errorf("Query() = %+v", results)
+	}
+	if fake.lastSearch["queryType"] != "prefixLast" || fake.lastSearch["numericFilters"] != "score>=0.5" {
+		t.Errorf("search params = %v", fake.lastSearch)
+	}
+
+	if _, err := provider.Query(ctx, "in", "mum", providers.QueryOptions{MatchStrategy: providers.MatchSubstring}); err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if fake.lastSearch["queryType"] != "prefixAll" {
+		t.Errorf("substring queryType = %v, want prefixAll", fake.lastSearch["queryType"])
+	}
+
+	if hash, exists, err := provider.ContentHash(ctx, "in", "1"); err != nil || !exists || hash != "h1" {
+		t.Errorf("ContentHash() = %q, %t, %v; want h1, true", hash, exists, err)
+	}
+	if err := provider.Delete(ctx, "in", "1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, exists, err := provider.ContentHash(ctx, "in", "1"); err != nil || exists {
+		t.Errorf("ContentHash() after delete = %t, %v; want false, nil", exists, err)
+	}
+
+	if err := provider.DeleteAll(ctx, "in"); err != nil {
+		t.Fatalf("DeleteAll() error = %v", err)
+	}
+	if err := provider.Index(ctx, "in", "3", "Pune", "Pune", providers.IndexOptions{}); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if fake.settings["autocomplete_in"] == nil {
+		t.Error("settings not reapplied after DeleteAll")
+	}
+}
@@ -0,0 +1,37 @@
+// Package algolia implements the autocomplete Provider interface using Algolia,
+// a hosted search service with typo-tolerant prefix search.
+package algolia
+
+// defaultIndexPrefix is prepended to namespaces to form index names.
+const defaultIndexPrefix = "autocomplete_"
+
+// Config holds Algolia credentials and provider-specific options.
+type Config struct {
+	// AppID is the Algolia application ID.
+	AppID string
+
+	// APIKey authenticates requests. It needs the search, addObject,
+	// deleteObject, deleteIndex and editSettings ACLs.
+	APIKey string
+
+	// Hosts overrides the Algolia hosts derived from AppID with URLs such as
+	// "https://proxy.internal:8443", e.g. for a proxy or tests.
+	Hosts []string
+
+	// IndexPrefix is prepended to a namespace to form its index name.
+	// Default: "autocomplete_"
+	IndexPrefix string
+
+	// WaitForTasks makes every write wait until Algolia has applied it, so it
+	// is visible to the next query. Algolia applies writes asynchronously, usually
+	// within seconds; waiting slows writes down considerably.
+	// Default: false
+	WaitForTasks bool
+}
+
+// setDefaults applies default values to config fields.
+func (c *Config) setDefaults() {
+	if c.IndexPrefix == "" {
+		c.IndexPrefix = defaultIndexPrefix
+	}
+}
@@ -0,0 +1,29 @@
+package algolia
+
+import (
+	"fmt"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// init registers the Algolia provider. Import this package with a blank identifier
+// to use Algolia as the autocomplete backend:
+//
+//	import _ "github.com/remiges-tech/autocomplete/providers/algolia"
+//
+//nolint:gochecknoinits // init() is the idiomatic pattern for provider registration
+func init() {
+	autocomplete.RegisterProvider("algolia", NewProvider)
+}
+
+// NewProvider creates a new Algolia provider from the given configuration.
+// It implements ProviderFactory and expects config to be of type algolia.Config.
+func NewProvider(config interface{}) (providers.Provider, error) {
+	algoliaConfig, ok := config.(Config)
+	if !ok {
+		return nil, fmt.Errorf("invalid configuration type for Algolia provider: expected algolia.Config, got %T", config)
+	}
+
+	return New(algoliaConfig)
+}
@@ -0,0 +1,49 @@
+package providers
+
+// Span is the byte range [Start, End) of a piece of text.
+type Span struct {
+	Start, End int
+}
+
+// isWordByte reports whether b belongs to a word under MatchAnchoredSubstring:
+// ASCII letters and digits, and every byte of a multi-byte UTF-8 character.
+func isWordByte(b byte) bool {
+	return b >= 0x80 || ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z') || ('0' <= b && b <= '9')
+}
+
+// AnchoredWords returns the words of text as MatchAnchoredSubstring splits it:
+// maximal runs of letters and digits, with any other ASCII byte separating words.
+func AnchoredWords(text string) []Span {
+	var words []Span
+	for i := 0; i < len(text); i++ {
+		if !isWordByte(text[i]) {
+			continue
+		}
+		start := i
+		for i < len(text) && isWordByte(text[i]) {
+			i++
+		}
+		words = append(words, Span{Start: start, End: i})
+	}
+	return words
+}
+
+// AnchoredSubstrings returns the substrings of text matched under
+// MatchAnchoredSubstring: every substring starting at a word start, which may
+// run on into later words, and every substring of at least minLength bytes
+// within a single word. Providers apply it to search text, so a query matches
+// wherever it equals one of these substrings.
+func AnchoredSubstrings(text string, minLength int) []Span {
+	var spans []Span
+	for _, word := range AnchoredWords(text) {
+		for end := word.Start + 1; end <= len(text); end++ {
+			spans = append(spans, Span{Start: word.Start, End: end})
+		}
+		for start := word.Start + 1; start < word.End; start++ {
+			for end := start + minLength; end <= word.End; end++ {
+				spans = append(spans, Span{Start: start, End: end})
+			}
+		}
+	}
+	return spans
+}
@@ -0,0 +1,27 @@
+package providers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnchoredSubstrings(t *testing.T) {
+	text := "pune, in"
+	var got []string
+	for _, span := range AnchoredSubstrings(text, 3) {
+		got = append(got, text[span.Start:span.End])
+	}
+	want := []string{
+		"p", "pu", "pun", "pune", "pune,", "pune, ", "pune, i", "pune, in",
+		"une",
+		"i", "in",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("AnchoredSubstrings(%q, 3) = %q, want %q", text, got, want)
+	}
+
+	words := "navi-mumbai  मुंबई"
+	if got, want := AnchoredWords(words), []Span{{0, 4}, {5, 11}, {13, len(words)}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AnchoredWords(%q) = %v, want %v", words, got, want)
+	}
+}
@@ -0,0 +1,458 @@
+package badger
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+
+	bdb "github.com/dgraph-io/badger/v4"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// defaultNGramSize is the default n-gram size when not specified in options.
+const defaultNGramSize = 3
+
+// Key layout. Both kinds of keys start with the namespace, so a namespace's
+// entries and postings are contiguous and can be scanned or dropped by prefix:
+//
+//	e\x00<namespace>\x00<id>                 -> JSON-encoded storedEntry
+//	t\x00<namespace>\x00<token>\x00<id>      -> earliest position of token (uvarint)
+const (
+	entryKeyPrefix   = "e"
+	postingKeyPrefix = "t"
+	keySeparator     = "\x00"
+)
+
+// Provider implements the autocomplete Provider interface using BadgerDB.
+// Each entry is stored once, with one posting key per token pointing back to it,
+// built with the same tokenization as the Redis and in-memory providers.
+// All methods are safe for concurrent use.
+type Provider struct {
+	db *bdb.DB
+}
+
+// storedEntry is the stored form of an entry.
+type storedEntry struct {
+	Text       string                 `json:"text"`
+	SearchText string                 `json:"search_text"`
+	Display    string                 `json:"display"`
+	Options    providers.IndexOptions `json:"options"`
+}
+
+// token is a single indexed token and the byte offset at which it starts.
+type token struct {
+	text     string
+	position int
+}
+
+// match is a candidate result with the data used to rank it.
+type match struct {
+	id       string
+	position int
+	entry    storedEntry
+}
+
+// New opens (or creates) the BadgerDB database described by config.
+func New(config Config) (*Provider, error) {
+	options := bdb.DefaultOptions(config.Path).
+		WithSyncWrites(config.SyncWrites).
+		WithLoggingLevel(bdb.WARNING)
+	if config.InMemory {
+		options = options.WithDir("").WithValueDir("").WithInMemory(true)
+	}
+
+	db, err := bdb.Open(options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open BadgerDB database: %w", err)
+	}
+	return &Provider{db: db}, nil
+}
+
+// Index adds or updates an entry, replacing the postings of its previous text.
+func (p *Provider) Index(ctx context.Context, key, id, text, display string, options providers.IndexOptions) error {
+	err := p.db.Update(func(txn *bdb.Txn) error {
+		return putEntry(txn, key, id, text, display, options)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to index entry: %w", err)
+	}
+	return nil
+}
+
+// IndexAtomic writes all entries inside a single transaction.
+func (p *Provider) IndexAtomic(ctx context.Context, key string, entries []providers.IndexEntry) error {
+	err := p.db.Update(func(txn *bdb.Txn) error {
+		for _, entry := range entries {
+			if err := putEntry(txn, key, entry.ID, entry.Text, entry.Display, entry.Options); err != nil {
+				return fmt.Errorf("entry %q: %w", entry.ID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to index entries: %w", err)
+	}
+	return nil
+}
+
+// putEntry stores an entry and its postings, removing the postings of the entry's previous text.
+func putEntry(txn *bdb.Txn, key, id, text, display string, options providers.IndexOptions) error {
+	if err := removeEntry(txn, key, id); err != nil {
+		return err
+	}
+
+	searchText := providers.SearchText(text, options.CaseSensitive)
+	value, err := json.Marshal(storedEntry{Text: text, SearchText: searchText, Display: display, Options: options})
+	if err != nil {
+		return err
+	}
+	if err := txn.Set(entryKey(key, id), value); err != nil {
+		return err
+	}
+
+	for tok, position := range earliestPositions(tokenize(searchText, options)) {
+		if err := txn.Set(postingKey(key, tok, id), binary.AppendUvarint(nil, uint64(position))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeEntry deletes an entry and its postings, if it exists.
+func removeEntry(txn *bdb.Txn, key, id string) error {
+	old, exists, err := getEntry(txn, key, id)
+	if err != nil || !exists {
+		return err
+	}
+
+	for tok := range earliestPositions(tokenize(old.SearchText, old.Options)) {
+		if err := txn.Delete(postingKey(key, tok, id)); err != nil {
+			return err
+		}
+	}
+	return txn.Delete(entryKey(key, id))
+}
+
+// getEntry reads an entry and reports whether it exists.
+func getEntry(txn *bdb.Txn, key, id string) (storedEntry, bool, error) {
+	item, err := txn.Get(entryKey(key, id))
+	if errors.Is(err, bdb.ErrKeyNotFound) {
+		return storedEntry{}, false, nil
+	}
+	if err != nil {
+		return storedEntry{}, false, err
+	}
+
+	var entry storedEntry
+	err = item.Value(func(value []byte) error {
+		return json.Unmarshal(value, &entry)
+	})
+	return entry, err == nil, err
+}
+
+// ContentHash returns the stored content hash for an entry and whether it exists.
+func (p *Provider) ContentHash(ctx context.Context, key, id string) (string, bool, error) {
+	var entry storedEntry
+	var exists bool
+	err := p.db.View(func(txn *bdb.Txn) error {
+		var err error
+		entry, exists, err = getEntry(txn, key, id)
+		return err
+	})
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get content hash: %w", err)
+	}
+	return entry.Options.ContentHash, exists, nil
+}
+
+// ListEntries returns up to count entries of the namespace in ID order,
+// starting after the ID given as cursor.
+func (p *Provider) ListEntries(ctx context.Context, key, cursor string, count int) ([]providers.StoredEntry, string, error) {
+	var entries []providers.StoredEntry
+	prefix := entryKey(key, "")
+	err := p.db.View(func(txn *bdb.Txn) error {
+		it := txn.NewIterator(bdb.IteratorOptions{Prefix: prefix, PrefetchValues: true, PrefetchSize: count})
+		defer it.Close()
+
+		for it.Seek(entryKey(key, cursor)); it.ValidForPrefix(prefix) && len(entries) < count; it.Next() {
+			id := string(it.Item().Key()[len(prefix):])
+			if id == cursor {
+				continue
+			}
+			var entry storedEntry
+			if err := it.Item().Value(func(value []byte) error {
+				return json.Unmarshal(value, &entry)
+			}); err != nil {
+				return err
+			}
+			entries = append(entries, providers.StoredEntry{
+				ID: id, Text: entry.Text, Display: entry.Display, ContentHash: entry.Options.ContentHash,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list entries: %w", err)
+	}
+
+	next := ""
+	if count > 0 && len(entries) == count {
+		next = entries[len(entries)-1].ID
+	}
+	return entries, next, nil
+}
+
+// Query searches for entries matching the given query. Results are ranked by
+// entry score, then match position, then text length.
+func (p *Provider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	searchQuery := providers.SearchText(query, options.CaseSensitive)
+
+	tag := ""
+	if len(options.MatchStrategies) > 0 {
+		tag = strategyTag(options.MatchStrategy)
+	}
+	n := getNGramSizeOrDefault(options.NGramSize)
+	if searchQuery == "" || (options.MatchStrategy == providers.MatchNOrMoreGram && len(searchQuery) < n) {
+		return []providers.ProviderResult{}, nil
+	}
+
+	var matches []match
+	err := p.db.View(func(txn *bdb.Txn) error {
+		var err error
+		if options.MatchStrategy == providers.MatchNGram && len(searchQuery) > n {
+			matches, err = intersect(txn, key, tag, searchQuery, n)
+		} else {
+			matches, err = lookup(txn, key, tag+searchQuery)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query autocomplete: %w", err)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.entry.Options.Score != b.entry.Options.Score {
+			return a.entry.Options.Score > b.entry.Options.Score
+		}
+		if a.position != b.position {
+			return a.position < b.position
+		}
+		if len(a.entry.Text) != len(b.entry.Text) {
+			return len(a.entry.Text) < len(b.entry.Text)
+		}
+		return a.id < b.id
+	})
+
+	if options.MaxResults > 0 && len(matches) > options.MaxResults {
+		matches = matches[:options.MaxResults]
+	}
+	results := make([]providers.ProviderResult, len(matches))
+	for i, m := range matches {
+		results[i] = providers.ProviderResult{ID: m.id, Display: m.entry.Display, Score: m.entry.Options.Score}
+	}
+	return results, nil
+}
+
+// lookup returns the entries with a posting for a single token.
+func lookup(txn *bdb.Txn, key, tok string) ([]match, error) {
+	prefix := postingKey(key, tok, "")
+	it := txn.NewIterator(bdb.IteratorOptions{Prefix: prefix, PrefetchValues: true})
+	defer it.Close()
+
+	var matches []match
+	for it.Rewind(); it.ValidForPrefix(prefix); it.Next() {
+		id := string(it.Item().Key()[len(prefix):])
+		var position uint64
+		if err := it.Item().Value(func(value []byte) error {
+			position, _ = binary.Uvarint(value)
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+
+		entry, exists, err := getEntry(txn, key, id)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			matches = append(matches, match{id: id, position: int(position), entry: entry})
+		}
+	}
+	return matches, nil
+}
+
+// intersect returns the entries containing every n-gram of the query,
+// positioned at the query's first n-gram. Tag prefixes each n-gram.
+func intersect(txn *bdb.Txn, key, tag, searchQuery string, n int) ([]match, error) {
+	matches, err := lookup(txn, key, tag+searchQuery[:n])
+	if err != nil {
+		return nil, err
+	}
+	for i := 1; i <= len(searchQuery)-n; i++ {
+		kept := matches[:0]
+		for _, m := range matches {
+			_, err := txn.Get(postingKey(key, tag+searchQuery[i:i+n], m.id))
+			switch {
+			case err == nil:
+				kept = append(kept, m)
+			case !errors.Is(err, bdb.ErrKeyNotFound):
+				return nil, err
+			}
+		}
+		matches = kept
+	}
+	return matches, nil
+}
+
+// Delete removes an entry and its postings from the index.
+func (p *Provider) Delete(ctx context.Context, key, id string) error {
+	err := p.db.Update(func(txn *bdb.Txn) error {
+		return removeEntry(txn, key, id)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete entry: %w", err)
+	}
+	return nil
+}
+
+// DeleteAll removes all entries and postings for a given key.
+func (p *Provider) DeleteAll(ctx context.Context, key string) error {
+	if err := p.db.DropPrefix(entryKey(key, ""), postingKeyPrefixFor(key)); err != nil {
+		return fmt.Errorf("failed to delete entries: %w", err)
+	}
+	return nil
+}
+
+// gcDiscardRatio is the share of a value log file that must be garbage for
+// Compact to rewrite it.
+const gcDiscardRatio = 0.5
+
+// Compact merges the LSM tree into a single level, dropping the keys of deleted
+// and replaced entries and postings, then rewrites value log files until none
+// is at least half garbage. Databases kept in memory have no value log.
+func (p *Provider) Compact(ctx context.Context) error {
+	if err := p.db.Flatten(runtime.GOMAXPROCS(0)); err != nil {
+		return fmt.Errorf("failed to flatten database: %w", err)
+	}
+	if p.db.Opts().InMemory {
+		return nil
+	}
+	for ctx.Err() == nil {
+		err := p.db.RunValueLogGC(gcDiscardRatio)
+		if errors.Is(err, bdb.ErrNoRewrite) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to collect value log garbage: %w", err)
+		}
+	}
+	return ctx.Err()
+}
+
+// Close closes the database, flushing pending writes.
+func (p *Provider) Close() error {
+	return p.db.Close()
+}
+
+// SupportsMatchStrategies reports that entries can be indexed under any
+// combination of strategies.
+func (p *Provider) SupportsMatchStrategies(strategies []providers.MatchStrategy) bool {
+	return true
+}
+
+func entryKey(key, id string) []byte {
+	return []byte(entryKeyPrefix + keySeparator + key + keySeparator + id)
+}
+
+func postingKeyPrefixFor(key string) []byte {
+	return []byte(postingKeyPrefix + keySeparator + key + keySeparator)
+}
+
+func postingKey(key, tok, id string) []byte {
+	return []byte(postingKeyPrefix + keySeparator + key + keySeparator + tok + keySeparator + id)
+}
+
+// earliestPositions maps each distinct token to the earliest position at which it occurs.
+func earliestPositions(tokens []token) map[string]int {
+	positions := make(map[string]int, len(tokens))
+	for _, tok := range tokens {
+		if position, seen := positions[tok.text]; !seen || tok.position < position {
+			positions[tok.text] = tok.position
+		}
+	}
+	return positions
+}
+
+// strategyTag prefixes the tokens of one strategy when an entry is indexed under
+// several, keeping each strategy's tokens apart. It starts with \x01 rather than
+// the \x00 used by the other providers, which separates key components here.
+func strategyTag(strategy providers.MatchStrategy) string {
+	return "\x01" + string(rune('0'+strategy))
+}
+
+// tokenize splits normalized text into the tokens stored for the given match strategy,
+// or for each of options.MatchStrategies with strategy-tagged tokens.
+// MatchNGram also stores the prefixes of each n-gram, so that queries
+// shorter than n match by direct lookup.
+func tokenize(text string, options providers.IndexOptions) []token {
+	if len(options.MatchStrategies) > 0 {
+		var tokens []token
+		for _, strategy := range options.MatchStrategies {
+			single := options
+			single.MatchStrategy = strategy
+			single.MatchStrategies = nil
+			tag := strategyTag(strategy)
+			for _, tok := range tokenize(text, single) {
+				tokens = append(tokens, token{text: tag + tok.text, position: tok.position})
+			}
+		}
+		return tokens
+	}
+
+	var tokens []token
+	switch options.MatchStrategy {
+	case providers.MatchPrefix:
+		for i := 1; i <= len(text); i++ {
+			tokens = append(tokens, token{text: text[:i]})
+		}
+
+	case providers.MatchNGram:
+		n := getNGramSizeOrDefault(options.NGramSize)
+		for start := 0; start <= len(text)-n; start++ {
+			for end := start + 1; end <= start+n; end++ {
+				tokens = append(tokens, token{text: text[start:end], position: start})
+			}
+		}
+
+	case providers.MatchNOrMoreGram:
+		tokens = substringTokens(text, getNGramSizeOrDefault(options.NGramSize))
+
+	case providers.MatchSubstring:
+		tokens = substringTokens(text, 1)
+	}
+	return tokens
+}
+
+// substringTokens returns every substring of text at least minLength bytes long.
+func substringTokens(text string, minLength int) []token {
+	var tokens []token
+	for start := 0; start < len(text); start++ {
+		for end := start + minLength; end <= len(text); end++ {
+			tokens = append(tokens, token{text: text[start:end], position: start})
+		}
+	}
+	return tokens
+}
+
+func getNGramSizeOrDefault(size int) int {
+	if size <= 0 {
+		return defaultNGramSize
+	}
+	return size
+}
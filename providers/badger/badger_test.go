@@ -0,0 +1,302 @@
+package badger
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	bdb "github.com/dgraph-io/badger/v4"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers"
+	"github.com/remiges-tech/autocomplete/stress"
+)
+
+const testKey = "test"
+
+func newTestProvider(t *testing.T) *Provider {
+	t.Helper()
+	provider, err := New(Config{Path: t.TempDir()})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = provider.Close() })
+	return provider
+}
+
+func resultIDs(results []providers.ProviderResult) []string {
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+	return ids
+}
+
+// countKeys returns the number of keys stored with the given prefix.
+func countKeys(t *testing.T, provider *Provider, prefix []byte) int {
+	t.Helper()
+	count := 0
+	err := provider.db.View(func(txn *bdb.Txn) error {
+		it := txn.NewIterator(bdb.IteratorOptions{Prefix: prefix})
+		defer it.Close()
+		for it.Rewind(); it.ValidForPrefix(prefix); it.Next() {
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to count keys: %v", err)
+	}
+	return count
+}
+
+func TestBadgerProvider_MatchStrategies(t *testing.T) {
+	entries := map[string]string{"1": "Mumbai", "2": "Navi Mumbai", "3": "Jammu"}
+
+	tests := []struct {
+		strategy providers.MatchStrategy
+		query    string
+		want     string
+	}{
+		{providers.MatchPrefix, "mum", "[1]"},
+		{providers.MatchNGram, "mu", "[1 2]"},
+		{providers.MatchNGram, "umba", "[1 2]"},
+		{providers.MatchNGram, "mmu", "[3]"},
+		{providers.MatchNOrMoreGram, "mumb", "[1 2]"},
+		{providers.MatchNOrMoreGram, "mu", "[]"},
+		{providers.MatchSubstring, "mu", "[1 3 2]"},
+		{providers.MatchSubstring, "xyz", "[]"},
+	}
+
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("strategy %d query %s", tt.strategy, tt.query), func(t *testing.T) {
+			provider := newTestProvider(t)
+			for id, text := range entries {
+				options := providers.IndexOptions{Score: 1.0, MatchStrategy: tt.strategy, NGramSize: 3}
+				if err := provider.Index(ctx, testKey, id, text, text, options); err != nil {
+					t.Fatalf("Index() error = %v", err)
+				}
+			}
+
+			results, err := provider.Query(ctx, testKey, tt.query, providers.QueryOptions{
+				MaxResults:    10,
+				MatchStrategy: tt.strategy,
+				NGramSize:     3,
+			})
+			if err != nil {
+				t.Fatalf("Query() error = %v", err)
+			}
+			if got := fmt.Sprint(resultIDs(results)); got != tt.want {
+				t.Errorf("Query(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBadgerProvider_UpdateAndDelete(t *testing.T) {
+	provider := newTestProvider(t)
+	ctx := context.Background()
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchPrefix, ContentHash: "h1"}
+	queryOptions := providers.QueryOptions{MaxResults: 10, MatchStrategy: providers.MatchPrefix}
+
+	for _, key := range []string{testKey, "other"} {
+		if err := provider.Index(ctx, key, "1", "Pune", "Pune", options); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+	if err := provider.Index(ctx, testKey, "1", "Nashik", "Nashik", options); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+
+	if results, _ := provider.Query(ctx, testKey, "pun", queryOptions); len(results) != 0 {
+		t.Errorf("previous text still matches after update: %v", resultIDs(results))
+	}
+	if results, _ := provider.Query(ctx, testKey, "nas", queryOptions); len(results) != 1 {
+		t.Errorf("updated text does not match: %v", resultIDs(results))
+	}
+	if hash, exists, _ := provider.ContentHash(ctx, testKey, "1"); !exists || hash != "h1" {
+		t.Errorf("ContentHash() = %q, %t, want h1, true", hash, exists)
+	}
+
+	if err := provider.Delete(ctx, testKey, "1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := provider.Delete(ctx, testKey, "missing"); err != nil {
+		t.Errorf("Delete() of missing entry error = %v", err)
+	}
+	if _, exists, _ := provider.ContentHash(ctx, testKey, "1"); exists {
+		t.Error("ContentHash() reports deleted entry as existing")
+	}
+	if n := countKeys(t, provider, postingKeyPrefixFor(testKey)); n != 0 {
+		t.Errorf("%d postings left behind after delete", n)
+	}
+
+	if err := provider.DeleteAll(ctx, "other"); err != nil {
+		t.Fatalf("DeleteAll() error = %v", err)
+	}
+	if results, _ := provider.Query(ctx, "other", "pun", queryOptions); len(results) != 0 {
+		t.Errorf("entry still matches after DeleteAll: %v", resultIDs(results))
+	}
+}
+
+func TestBadgerProvider_Persistence(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchSubstring}
+
+	provider, err := New(Config{Path: dir})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := provider.Index(ctx, testKey, "1", "Mumbai", "Mumbai, Maharashtra", options); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if err := provider.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	reopened, err := New(Config{Path: dir})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer reopened.Close()
+
+	results, err := reopened.Query(ctx, testKey, "umb", providers.QueryOptions{MaxResults: 10, MatchStrategy: providers.MatchSubstring})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Display != "Mumbai, Maharashtra" {
+		t.Errorf("Query() after reopening = %+v, want Mumbai", results)
+	}
+}
+
+func TestBadgerProvider_AtomicAndListing(t *testing.T) {
+	provider := newTestProvider(t)
+	ctx := context.Background()
+
+	entries := make([]providers.IndexEntry, 0, 3)
+	for _, id := range []string{"3", "1", "2"} {
+		entries = append(entries, providers.IndexEntry{
+			ID: id, Text: "City " + id, Display: "Display " + id,
+			Options: providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchPrefix, ContentHash: "h" + id},
+		})
+	}
+	if err := provider.IndexAtomic(ctx, testKey, entries); err != nil {
+		t.Fatalf("IndexAtomic() error = %v", err)
+	}
+
+	first, next, err := provider.ListEntries(ctx, testKey, "", 2)
+	if err != nil || len(first) != 2 || next != "2" {
+		t.Fatalf("ListEntries() = %v, %q, %v; want 2 entries and cursor \"2\"", first, next, err)
+	}
+	want := providers.StoredEntry{ID: "1", Text: "City 1", Display: "Display 1", ContentHash: "h1"}
+	if first[0] != want {
+		t.Errorf("ListEntries() first entry = %+v, want %+v", first[0], want)
+	}
+	rest, next, err := provider.ListEntries(ctx, testKey, next, 2)
+	if err != nil || len(rest) != 1 || rest[0].ID != "3" || next != "" {
+		t.Errorf("ListEntries() second page = %v, %q, %v; want entry 3 and no cursor", rest, next, err)
+	}
+}
+
+func TestBadgerProvider_MultipleStrategies(t *testing.T) {
+	provider := newTestProvider(t)
+	ctx := context.Background()
+	strategies := []providers.MatchStrategy{providers.MatchPrefix, providers.MatchSubstring}
+
+	for id, text := range map[string]string{"1": "Mumbai", "2": "Navi Mumbai"} {
+		options := providers.IndexOptions{Score: 1.0, MatchStrategies: strategies}
+		if err := provider.Index(ctx, testKey, id, text, text, options); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	for strategy, want := range map[providers.MatchStrategy]string{
+		providers.MatchPrefix:    "[1]",
+		providers.MatchSubstring: "[1 2]",
+	} {
+		results, err := provider.Query(ctx, testKey, "mum", providers.QueryOptions{
+			MaxResults: 10, MatchStrategy: strategy, MatchStrategies: strategies,
+		})
+		if err != nil {
+			t.Fatalf("Query() error = %v", err)
+		}
+		if got := fmt.Sprint(resultIDs(results)); got != want {
+			t.Errorf("strategy %d: Query() = %v, want %v", strategy, got, want)
+		}
+	}
+}
+
+func TestBadgerProvider_Registration(t *testing.T) {
+	ac, err := autocomplete.New("badger", autocomplete.NewConfig(Config{InMemory: true}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer ac.Close()
+
+	ctx := context.Background()
+	if err := ac.Index(ctx, "IN", "India", "India"); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	results, err := ac.Query(ctx, "ind", 10)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Display != "India" {
+		t.Errorf("Query() = %+v, want India", results)
+	}
+}
+
+func TestBadgerProvider_Stress(t *testing.T) {
+	for _, strategy := range []autocomplete.MatchStrategy{autocomplete.MatchPrefix, autocomplete.MatchNGram, autocomplete.MatchSubstring} {
+		t.Run(strategy.String(), func(t *testing.T) {
+			options := autocomplete.DefaultOptions()
+			options.MatchStrategy = strategy
+			ac, err := autocomplete.New("badger", autocomplete.NewConfigWithOptions(Config{InMemory: true}, options))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			defer func() { _ = ac.Close() }()
+
+			if err := stress.Run(context.Background(), ac, stress.Config{}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestBadgerProvider_Compact(t *testing.T) {
+	ctx := context.Background()
+	for name, config := range map[string]Config{"disk": {Path: t.TempDir()}, "in-memory": {InMemory: true}} {
+		provider, err := New(config)
+		if err != nil {
+			t.Fatalf("%s: New() error = %v", name, err)
+		}
+		t.Cleanup(func() { _ = provider.Close() })
+
+		options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchSubstring}
+		for i := 0; i < 200; i++ {
+			text := fmt.Sprintf("city %03d", i)
+			if err := provider.Index(ctx, testKey, fmt.Sprint(i), text, text, options); err != nil {
+				t.Fatalf("%s: Index() error = %v", name, err)
+			}
+		}
+		for i := 10; i < 200; i++ {
+			if err := provider.Delete(ctx, testKey, fmt.Sprint(i)); err != nil {
+				t.Fatalf("%s: Delete() error = %v", name, err)
+			}
+		}
+		if err := provider.Compact(ctx); err != nil {
+			t.Fatalf("%s: Compact() error = %v", name, err)
+		}
+
+		results, err := provider.Query(ctx, testKey, "ty 00", providers.QueryOptions{MaxResults: 20, MatchStrategy: providers.MatchSubstring})
+		if err != nil || len(results) != 10 {
+			t.Errorf("%s: Query() after Compact() = %v, %v; want the 10 remaining entries", name, resultIDs(results), err)
+		}
+		if n := countKeys(t, provider, entryKey(testKey, "")); n != 10 {
+			t.Errorf("%s: entries after Compact() = %d, want 10", name, n)
+		}
+	}
+}
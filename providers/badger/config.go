@@ -0,0 +1,20 @@
+// Package badger implements the autocomplete Provider interface using BadgerDB,
+// an embedded, persistent key-value store. The index survives restarts without
+// any external infrastructure.
+package badger
+
+// Config holds BadgerDB parameters and provider-specific options.
+type Config struct {
+	// Path is the directory holding the database files, created if it does not exist.
+	// Ignored when InMemory is set.
+	Path string
+
+	// InMemory keeps the database in memory only, e.g. for tests.
+	// Default: false
+	InMemory bool
+
+	// SyncWrites makes every write durable before it returns, at the cost of
+	// write throughput. When false, a crash can lose the most recent writes.
+	// Default: false
+	SyncWrites bool
+}
@@ -0,0 +1,29 @@
+package badger
+
+import (
+	"fmt"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// init registers the BadgerDB provider. Import this package with a blank identifier
+// to use BadgerDB as the autocomplete backend:
+//
+//	import _ "github.com/remiges-tech/autocomplete/providers/badger"
+//
+//nolint:gochecknoinits // init() is the idiomatic pattern for provider registration
+func init() {
+	autocomplete.RegisterProvider("badger", NewProvider)
+}
+
+// NewProvider creates a new BadgerDB provider from the given configuration.
+// It implements ProviderFactory and expects config to be of type badger.Config.
+func NewProvider(config interface{}) (providers.Provider, error) {
+	badgerConfig, ok := config.(Config)
+	if !ok {
+		return nil, fmt.Errorf("invalid configuration type for BadgerDB provider: expected badger.Config, got %T", config)
+	}
+
+	return New(badgerConfig)
+}
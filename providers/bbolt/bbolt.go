@@ -0,0 +1,415 @@
+package bbolt
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+const (
+	// defaultNGramSize is the default n-gram size when not specified in options.
+	defaultNGramSize = 3
+
+	// maxSuffixLength caps the length of stored suffix keys. Queries longer than
+	// this scan by their first maxSuffixLength bytes and are verified against the
+	// entry's search text.
+	maxSuffixLength = 256
+
+	// keySeparator separates a suffix from the entry ID in suffix keys.
+	keySeparator = "\x00"
+)
+
+// Bucket layout. Each namespace has its own top-level bucket holding two nested buckets:
+//
+//	<namespace>/entries:  <id>                    -> JSON-encoded storedEntry
+//	<namespace>/suffixes: <suffix>\x00<id>        -> position of suffix (uvarint)
+//
+// Every suffix of an entry's search text is a key, so all match strategies are
+// served by a range scan over the suffixes starting with the query.
+var (
+	entriesBucket  = []byte("entries")
+	suffixesBucket = []byte("suffixes")
+)
+
+// Provider implements the autocomplete Provider interface using bbolt.
+// Entries are stored once per namespace, with one suffix key per byte offset of
+// their search text pointing back to them. All methods are safe for concurrent use;
+// bbolt serializes writes and lets reads run in parallel.
+type Provider struct {
+	db *bolt.DB
+}
+
+// storedEntry is the stored form of an entry.
+type storedEntry struct {
+	Text       string                 `json:"text"`
+	SearchText string                 `json:"search_text"`
+	Display    string                 `json:"display"`
+	Options    providers.IndexOptions `json:"options"`
+}
+
+// match is a candidate result with the data used to rank it.
+type match struct {
+	id       string
+	position int
+	entry    storedEntry
+}
+
+// New opens (or creates) the bbolt database file described by config.
+func New(config Config) (*Provider, error) {
+	config.setDefaults()
+
+	db, err := bolt.Open(config.Path, 0o600, &bolt.Options{Timeout: config.Timeout, NoSync: config.NoSync})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bbolt database: %w", err)
+	}
+	return &Provider{db: db}, nil
+}
+
+// Index adds or updates an entry, replacing the suffixes of its previous text.
+func (p *Provider) Index(ctx context.Context, key, id, text, display string, options providers.IndexOptions) error {
+	err := p.db.Update(func(tx *bolt.Tx) error {
+		return putEntry(tx, key, id, text, display, options)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to index entry: %w", err)
+	}
+	return nil
+}
+
+// IndexAtomic writes all entries inside a single transaction.
+func (p *Provider) IndexAtomic(ctx context.Context, key string, entries []providers.IndexEntry) error {
+	err := p.db.Update(func(tx *bolt.Tx) error {
+		for _, entry := range entries {
+			if err := putEntry(tx, key, entry.ID, entry.Text, entry.Display, entry.Options); err != nil {
+				return fmt.Errorf("entry %q: %w", entry.ID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to index entries: %w", err)
+	}
+	return nil
+}
+
+// putEntry stores an entry and its suffixes, removing the suffixes of the entry's previous text.
+func putEntry(tx *bolt.Tx, key, id, text, display string, options providers.IndexOptions) error {
+	namespace, err := tx.CreateBucketIfNotExists([]byte(key))
+	if err != nil {
+		return err
+	}
+	entries, err := namespace.CreateBucketIfNotExists(entriesBucket)
+	if err != nil {
+		return err
+	}
+	suffixes, err := namespace.CreateBucketIfNotExists(suffixesBucket)
+	if err != nil {
+		return err
+	}
+	if err := removeEntry(entries, suffixes, id); err != nil {
+		return err
+	}
+
+	searchText := providers.SearchText(text, options.CaseSensitive)
+	value, err := json.Marshal(storedEntry{Text: text, SearchText: searchText, Display: display, Options: options})
+	if err != nil {
+		return err
+	}
+	if err := entries.Put([]byte(id), value); err != nil {
+		return err
+	}
+
+	// Suffixes truncated to the same key keep the earliest position, written last.
+	for position := len(searchText) - 1; position >= 0; position-- {
+		if err := suffixes.Put(suffixKey(searchText[position:], id), binary.AppendUvarint(nil, uint64(position))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeEntry deletes an entry and its suffixes, if it exists.
+func removeEntry(entries, suffixes *bolt.Bucket, id string) error {
+	old, exists, err := getEntry(entries, id)
+	if err != nil || !exists {
+		return err
+	}
+
+	for position := range len(old.SearchText) {
+		if err := suffixes.Delete(suffixKey(old.SearchText[position:], id)); err != nil {
+			return err
+		}
+	}
+	return entries.Delete([]byte(id))
+}
+
+// getEntry reads an entry and reports whether it exists.
+func getEntry(entries *bolt.Bucket, id string) (storedEntry, bool, error) {
+	value := entries.Get([]byte(id))
+	if value == nil {
+		return storedEntry{}, false, nil
+	}
+
+	var entry storedEntry
+	if err := json.Unmarshal(value, &entry); err != nil {
+		return storedEntry{}, false, err
+	}
+	return entry, true, nil
+}
+
+// buckets returns the entries and suffixes buckets of a namespace, or nil
+// buckets if nothing has been indexed under it.
+func buckets(tx *bolt.Tx, key string) (*bolt.Bucket, *bolt.Bucket) {
+	namespace := tx.Bucket([]byte(key))
+	if namespace == nil {
+		return nil, nil
+	}
+	return namespace.Bucket(entriesBucket), namespace.Bucket(suffixesBucket)
+}
+
+// ContentHash returns the stored content hash for an entry and whether it exists.
+func (p *Provider) ContentHash(ctx context.Context, key, id string) (string, bool, error) {
+	var entry storedEntry
+	var exists bool
+	err := p.db.View(func(tx *bolt.Tx) error {
+		entries, _ := buckets(tx, key)
+		if entries == nil {
+			return nil
+		}
+		var err error
+		entry, exists, err = getEntry(entries, id)
+		return err
+	})
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get content hash: %w", err)
+	}
+	return entry.Options.ContentHash, exists, nil
+}
+
+// ListEntries returns up to count entries of the namespace in ID order,
+// starting after the ID given as cursor.
+func (p *Provider) ListEntries(ctx context.Context, key, cursor string, count int) ([]providers.StoredEntry, string, error) {
+	var stored []providers.StoredEntry
+	err := p.db.View(func(tx *bolt.Tx) error {
+		entries, _ := buckets(tx, key)
+		if entries == nil {
+			return nil
+		}
+
+		c := entries.Cursor()
+		for k, v := c.Seek([]byte(cursor)); k != nil && len(stored) < count; k, v = c.Next() {
+			if string(k) == cursor {
+				continue
+			}
+			var entry storedEntry
+			if err := json.Unmarshal(v, &entry); err != nil {
+				return err
+			}
+			stored = append(stored, providers.StoredEntry{
+				ID: string(k), Text: entry.Text, Display: entry.Display, ContentHash: entry.Options.ContentHash,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list entries: %w", err)
+	}
+
+	next := ""
+	if count > 0 && len(stored) == count {
+		next = stored[len(stored)-1].ID
+	}
+	return stored, next, nil
+}
+
+// Query searches for entries matching the given query. Results are ranked by
+// entry score, then match position, then text length.
+func (p *Provider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	searchQuery := providers.SearchText(query, options.CaseSensitive)
+
+	n := getNGramSizeOrDefault(options.NGramSize)
+	if searchQuery == "" || (options.MatchStrategy == providers.MatchNOrMoreGram && len(searchQuery) < n) {
+		return []providers.ProviderResult{}, nil
+	}
+
+	var matches []match
+	err := p.db.View(func(tx *bolt.Tx) error {
+		entries, suffixes := buckets(tx, key)
+		if entries == nil || suffixes == nil {
+			return nil
+		}
+		var err error
+		matches, err = scan(entries, suffixes, searchQuery, options.MatchStrategy, n)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query autocomplete: %w", err)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.entry.Options.Score != b.entry.Options.Score {
+			return a.entry.Options.Score > b.entry.Options.Score
+		}
+		if a.position != b.position {
+			return a.position < b.position
+		}
+		if len(a.entry.Text) != len(b.entry.Text) {
+			return len(a.entry.Text) < len(b.entry.Text)
+		}
+		return a.id < b.id
+	})
+
+	if options.MaxResults > 0 && len(matches) > options.MaxResults {
+		matches = matches[:options.MaxResults]
+	}
+	results := make([]providers.ProviderResult, len(matches))
+	for i, m := range matches {
+		results[i] = providers.ProviderResult{ID: m.id, Display: m.entry.Display, Score: m.entry.Options.Score}
+	}
+	return results, nil
+}
+
+// scan returns the entries matching searchQuery under the given strategy, each
+// positioned at its earliest match. It range-scans the suffixes starting with the
+// query (or, for n-gram queries longer than n, with its first n-gram) and applies
+// the strategy's conditions to each candidate:
+//
+//   - MatchPrefix keeps suffixes at position 0.
+//   - MatchSubstring and MatchNOrMoreGram keep every suffix.
+//   - MatchNGram keeps suffixes with a whole n-gram left at their position, and
+//     for queries longer than n, entries containing every n-gram of the query.
+func scan(entries, suffixes *bolt.Bucket, searchQuery string, strategy providers.MatchStrategy, n int) ([]match, error) {
+	pattern := searchQuery
+	intersect := strategy == providers.MatchNGram && len(searchQuery) > n
+	if intersect {
+		pattern = searchQuery[:n]
+	}
+	scanPrefix := []byte(pattern)
+	if len(scanPrefix) > maxSuffixLength {
+		scanPrefix = scanPrefix[:maxSuffixLength]
+	}
+
+	found := make(map[string]*match)
+	loaded := make(map[string]*storedEntry)
+	c := suffixes.Cursor()
+	for k, v := c.Seek(scanPrefix); k != nil && bytes.HasPrefix(k, scanPrefix); k, v = c.Next() {
+		separator := bytes.LastIndex(k, []byte(keySeparator))
+		if separator < 0 {
+			continue
+		}
+		id := string(k[separator+1:])
+		position64, _ := binary.Uvarint(v)
+		position := int(position64)
+		if strategy == providers.MatchPrefix && position != 0 {
+			continue
+		}
+
+		entry, seen := loaded[id]
+		if !seen {
+			stored, exists, err := getEntry(entries, id)
+			if err != nil {
+				return nil, err
+			}
+			if exists {
+				entry = &stored
+			}
+			loaded[id] = entry
+		}
+		if entry == nil || !strings.HasPrefix(entry.SearchText[position:], pattern) {
+			continue
+		}
+		if strategy == providers.MatchNGram && position+n > len(entry.SearchText) {
+			continue
+		}
+		if m, ok := found[id]; !ok || position < m.position {
+			found[id] = &match{id: id, position: position, entry: *entry}
+		}
+	}
+
+	matches := make([]match, 0, len(found))
+	for _, m := range found {
+		if intersect && !containsNGrams(m.entry.SearchText, searchQuery, n) {
+			continue
+		}
+		matches = append(matches, *m)
+	}
+	return matches, nil
+}
+
+// containsNGrams reports whether text contains every n-gram of query.
+func containsNGrams(text, query string, n int) bool {
+	for i := 0; i <= len(query)-n; i++ {
+		if !strings.Contains(text, query[i:i+n]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Delete removes an entry and its suffixes from the index.
+func (p *Provider) Delete(ctx context.Context, key, id string) error {
+	err := p.db.Update(func(tx *bolt.Tx) error {
+		entries, suffixes := buckets(tx, key)
+		if entries == nil || suffixes == nil {
+			return nil
+		}
+		return removeEntry(entries, suffixes, id)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete entry: %w", err)
+	}
+	return nil
+}
+
+// DeleteAll removes all entries and suffixes for a given key by dropping its bucket.
+func (p *Provider) DeleteAll(ctx context.Context, key string) error {
+	err := p.db.Update(func(tx *bolt.Tx) error {
+		err := tx.DeleteBucket([]byte(key))
+		if errors.Is(err, bolt.ErrBucketNotFound) {
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete entries: %w", err)
+	}
+	return nil
+}
+
+// Close closes the database file, releasing its lock.
+func (p *Provider) Close() error {
+	return p.db.Close()
+}
+
+// SupportsMatchStrategies reports that entries can be indexed under any
+// combination of strategies. The suffix keys serve every strategy, so entries
+// need no per-strategy tokens.
+func (p *Provider) SupportsMatchStrategies(strategies []providers.MatchStrategy) bool {
+	return true
+}
+
+// suffixKey returns the key for one suffix of an entry's search text, truncated
+// to maxSuffixLength bytes.
+func suffixKey(suffix, id string) []byte {
+	if len(suffix) > maxSuffixLength {
+		suffix = suffix[:maxSuffixLength]
+	}
+	return []byte(suffix + keySeparator + id)
+}
+
+func getNGramSizeOrDefault(size int) int {
+	if size <= 0 {
+		return defaultNGramSize
+	}
+	return size
+}
@@ -0,0 +1,268 @@
+package bbolt
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers"
+	"github.com/remiges-tech/autocomplete/stress"
+)
+
+const testKey = "test"
+
+func newTestProvider(t *testing.T) *Provider {
+	t.Helper()
+	provider, err := New(Config{Path: filepath.Join(t.TempDir(), "autocomplete.db")})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = provider.Close() })
+	return provider
+}
+
+func resultIDs(results []providers.ProviderResult) []string {
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+	return ids
+}
+
+// countSuffixes returns the number of suffix keys stored for a namespace.
+func countSuffixes(t *testing.T, provider *Provider, key string) int {
+	t.Helper()
+	count := 0
+	err := provider.db.View(func(tx *bolt.Tx) error {
+		_, suffixes := buckets(tx, key)
+		if suffixes != nil {
+			count = suffixes.Stats().KeyN
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to count suffixes: %v", err)
+	}
+	return count
+}
+
+func TestBboltProvider_MatchStrategies(t *testing.T) {
+	entries := map[string]string{"1": "Mumbai", "2": "Navi Mumbai", "3": "Jammu"}
+
+	tests := []struct {
+		strategy providers.MatchStrategy
+		query    string
+		want     string
+	}{
+		{providers.MatchPrefix, "mum", "[1]"},
+		{providers.MatchNGram, "mu", "[1 2]"},
+		{providers.MatchNGram, "umba", "[1 2]"},
+		{providers.MatchNGram, "mmu", "[3]"},
+		{providers.MatchNOrMoreGram, "mumb", "[1 2]"},
+		{providers.MatchNOrMoreGram, "mu", "[]"},
+		{providers.MatchSubstring, "mu", "[1 3 2]"},
+		{providers.MatchSubstring, "xyz", "[]"},
+		{providers.MatchSubstring, "i mumbai", "[2]"},
+	}
+
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("strategy %d query %s", tt.strategy, tt.query), func(t *testing.T) {
+			provider := newTestProvider(t)
+			for id, text := range entries {
+				options := providers.IndexOptions{Score: 1.0, MatchStrategy: tt.strategy, NGramSize: 3}
+				if err := provider.Index(ctx, testKey, id, text, text, options); err != nil {
+					t.Fatalf("Index() error = %v", err)
+				}
+			}
+
+			results, err := provider.Query(ctx, testKey, tt.query, providers.QueryOptions{
+				MaxResults:    10,
+				MatchStrategy: tt.strategy,
+				NGramSize:     3,
+			})
+			if err != nil {
+				t.Fatalf("Query() error = %v", err)
+			}
+			if got := fmt.Sprint(resultIDs(results)); got != tt.want {
+				t.Errorf("Query(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBboltProvider_UpdateAndDelete(t *testing.T) {
+	provider := newTestProvider(t)
+	ctx := context.Background()
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchPrefix, ContentHash: "h1"}
+	queryOptions := providers.QueryOptions{MaxResults: 10, MatchStrategy: providers.MatchPrefix}
+
+	for _, key := range []string{testKey, "other"} {
+		if err := provider.Index(ctx, key, "1", "Pune", "Pune", options); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+	if err := provider.Index(ctx, testKey, "1", "Nashik", "Nashik", options); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+
+	if results, _ := provider.Query(ctx, testKey, "pun", queryOptions); len(results) != 0 {
+		t.Errorf("previous text still matches after update: %v", resultIDs(results))
+	}
+	if results, _ := provider.Query(ctx, testKey, "nas", queryOptions); len(results) != 1 {
+		t.Errorf("updated text does not match: %v", resultIDs(results))
+	}
+	if hash, exists, _ := provider.ContentHash(ctx, testKey, "1"); !exists || hash != "h1" {
+		t.Errorf("ContentHash() = %q, %t, want h1, true", hash, exists)
+	}
+
+	if err := provider.Delete(ctx, testKey, "1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := provider.Delete(ctx, testKey, "missing"); err != nil {
+		t.Errorf("Delete() of missing entry error = %v", err)
+	}
+	if _, exists, _ := provider.ContentHash(ctx, testKey, "1"); exists {
+		t.Error("ContentHash() reports deleted entry as existing")
+	}
+	if n := countSuffixes(t, provider, testKey); n != 0 {
+		t.Errorf("%d suffixes left behind after delete", n)
+	}
+
+	if err := provider.DeleteAll(ctx, "other"); err != nil {
+		t.Fatalf("DeleteAll() error = %v", err)
+	}
+	if results, _ := provider.Query(ctx, "other", "pun", queryOptions); len(results) != 0 {
+		t.Errorf("entry still matches after DeleteAll: %v", resultIDs(results))
+	}
+}
+
+func TestBboltProvider_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "autocomplete.db")
+	ctx := context.Background()
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchSubstring}
+
+	provider, err := New(Config{Path: path})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := provider.Index(ctx, testKey, "1", "Mumbai", "Mumbai, Maharashtra", options); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if err := provider.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	reopened, err := New(Config{Path: path})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer reopened.Close()
+
+	results, err := reopened.Query(ctx, testKey, "umb", providers.QueryOptions{MaxResults: 10, MatchStrategy: providers.MatchSubstring})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Display != "Mumbai, Maharashtra" {
+		t.Errorf("Query() after reopening = %+v, want Mumbai", results)
+	}
+}
+
+func TestBboltProvider_AtomicAndListing(t *testing.T) {
+	provider := newTestProvider(t)
+	ctx := context.Background()
+
+	entries := make([]providers.IndexEntry, 0, 3)
+	for _, id := range []string{"3", "1", "2"} {
+		entries = append(entries, providers.IndexEntry{
+			ID: id, Text: "City " + id, Display: "Display " + id,
+			Options: providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchPrefix, ContentHash: "h" + id},
+		})
+	}
+	if err := provider.IndexAtomic(ctx, testKey, entries); err != nil {
+		t.Fatalf("IndexAtomic() error = %v", err)
+	}
+
+	first, next, err := provider.ListEntries(ctx, testKey, "", 2)
+	if err != nil || len(first) != 2 || next != "2" {
+		t.Fatalf("ListEntries() = %v, %q, %v; want 2 entries and cursor \"2\"", first, next, err)
+	}
+	want := providers.StoredEntry{ID: "1", Text: "City 1", Display: "Display 1", ContentHash: "h1"}
+	if first[0] != want {
+		t.Errorf("ListEntries() first entry = %+v, want %+v", first[0], want)
+	}
+	rest, next, err := provider.ListEntries(ctx, testKey, next, 2)
+	if err != nil || len(rest) != 1 || rest[0].ID != "3" || next != "" {
+		t.Errorf("ListEntries() second page = %v, %q, %v; want entry 3 and no cursor", rest, next, err)
+	}
+}
+
+func TestBboltProvider_MultipleStrategies(t *testing.T) {
+	provider := newTestProvider(t)
+	ctx := context.Background()
+	strategies := []providers.MatchStrategy{providers.MatchPrefix, providers.MatchSubstring}
+
+	for id, text := range map[string]string{"1": "Mumbai", "2": "Navi Mumbai"} {
+		options := providers.IndexOptions{Score: 1.0, MatchStrategies: strategies}
+		if err := provider.Index(ctx, testKey, id, text, text, options); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	for strategy, want := range map[providers.MatchStrategy]string{
+		providers.MatchPrefix:    "[1]",
+		providers.MatchSubstring: "[1 2]",
+	} {
+		results, err := provider.Query(ctx, testKey, "mum", providers.QueryOptions{
+			MaxResults: 10, MatchStrategy: strategy, MatchStrategies: strategies,
+		})
+		if err != nil {
+			t.Fatalf("Query() error = %v", err)
+		}
+		if got := fmt.Sprint(resultIDs(results)); got != want {
+			t.Errorf("strategy %d: Query() = %v, want %v", strategy, got, want)
+		}
+	}
+}
+
+func TestBboltProvider_Registration(t *testing.T) {
+	ac, err := autocomplete.New("bbolt", autocomplete.NewConfig(Config{Path: filepath.Join(t.TempDir(), "autocomplete.db")}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer ac.Close()
+
+	ctx := context.Background()
+	if err := ac.Index(ctx, "IN", "India", "India"); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	results, err := ac.Query(ctx, "ind", 10)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Display != "India" {
+		t.Errorf("Query() = %+v, want India", results)
+	}
+}
+
+func TestBboltProvider_Stress(t *testing.T) {
+	for _, strategy := range []autocomplete.MatchStrategy{autocomplete.MatchPrefix, autocomplete.MatchNGram, autocomplete.MatchSubstring} {
+		t.Run(strategy.String(), func(t *testing.T) {
+			options := autocomplete.DefaultOptions()
+			options.MatchStrategy = strategy
+			ac, err := autocomplete.New("bbolt", autocomplete.NewConfigWithOptions(Config{Path: filepath.Join(t.TempDir(), "stress.db"), NoSync: true}, options))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			defer func() { _ = ac.Close() }()
+
+			if err := stress.Run(context.Background(), ac, stress.Config{}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
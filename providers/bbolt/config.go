@@ -0,0 +1,32 @@
+// Package bbolt implements the autocomplete Provider interface using bbolt, an
+// embedded, pure Go key-value store kept in a single file. It suits small services
+// that need a persistent index without running a database.
+package bbolt
+
+import "time"
+
+// defaultTimeout is how long New waits for the file lock held by another process.
+const defaultTimeout = time.Second
+
+// Config holds bbolt database parameters and provider-specific options.
+type Config struct {
+	// Path is the database file, created if it does not exist.
+	Path string
+
+	// Timeout is how long New waits to obtain the file lock when another
+	// process has the database open. bbolt allows only one process at a time.
+	// Default: 1 second
+	Timeout time.Duration
+
+	// NoSync skips fsync after each commit, trading durability for write
+	// throughput; a crash can then corrupt the database. Intended for bulk loads.
+	// Default: false
+	NoSync bool
+}
+
+// setDefaults applies default values to config fields.
+func (c *Config) setDefaults() {
+	if c.Timeout == 0 {
+		c.Timeout = defaultTimeout
+	}
+}
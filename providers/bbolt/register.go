@@ -0,0 +1,29 @@
+package bbolt
+
+import (
+	"fmt"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// init registers the bbolt provider. Import this package with a blank identifier
+// to use bbolt as the autocomplete backend:
+//
+//	import _ "github.com/remiges-tech/autocomplete/providers/bbolt"
+//
+//nolint:gochecknoinits // init() is the idiomatic pattern for provider registration
+func init() {
+	autocomplete.RegisterProvider("bbolt", NewProvider)
+}
+
+// NewProvider creates a new bbolt provider from the given configuration.
+// It implements ProviderFactory and expects config to be of type bbolt.Config.
+func NewProvider(config interface{}) (providers.Provider, error) {
+	boltConfig, ok := config.(Config)
+	if !ok {
+		return nil, fmt.Errorf("invalid configuration type for bbolt provider: expected bbolt.Config, got %T", config)
+	}
+
+	return New(boltConfig)
+}
@@ -0,0 +1,249 @@
+package bleve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	blevesearch "github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/search/query"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+const (
+	// defaultMaxResults is the default maximum number of results if not specified.
+	defaultMaxResults = 10
+
+	// deleteBatchSize is the number of documents DeleteAll removes per batch.
+	deleteBatchSize = 1000
+)
+
+// Provider implements the autocomplete Provider interface using Bleve.
+// All namespaces share one index, with each document tagged by its namespace.
+//
+// Each match strategy searches its own copy of the text: MatchPrefix an edge
+// n-gram field matching the start of words, MatchNGram an n-gram field, and
+// MatchSubstring and MatchNOrMoreGram a field matching query words inside
+// indexed words. Every query word must match. Results are ranked by Bleve's
+// relevance score, with the entry score breaking ties.
+// All methods are safe for concurrent use.
+type Provider struct {
+	index     blevesearch.Index
+	fuzziness int
+}
+
+// document represents the structure stored in the index. Only the analyzed
+// fields for the entry's case sensitivity are set.
+type document struct {
+	Key         string  `json:"key"`
+	EntryID     string  `json:"entry_id"`
+	Text        string  `json:"text"`
+	Display     string  `json:"display"`
+	Score       float64 `json:"score"`
+	ContentHash string  `json:"content_hash"`
+
+	Prefix         string `json:"prefix,omitempty"`
+	NGram          string `json:"ngram,omitempty"`
+	Substring      string `json:"substring,omitempty"`
+	CasedPrefix    string `json:"cased_prefix,omitempty"`
+	CasedNGram     string `json:"cased_ngram,omitempty"`
+	CasedSubstring string `json:"cased_substring,omitempty"`
+}
+
+// New opens the Bleve index described by config, creating it if it does not exist.
+func New(config Config) (*Provider, error) {
+	config.setDefaults()
+
+	indexMapping, err := newIndexMapping()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build index mapping: %w", err)
+	}
+
+	var index blevesearch.Index
+	switch {
+	case config.InMemory:
+		index, err = blevesearch.NewMemOnly(indexMapping)
+	default:
+		index, err = blevesearch.Open(config.Path)
+		if errors.Is(err, blevesearch.ErrorIndexPathDoesNotExist) {
+			index, err = blevesearch.New(config.Path, indexMapping)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Bleve index: %w", err)
+	}
+	return &Provider{index: index, fuzziness: config.Fuzziness}, nil
+}
+
+// Index adds or updates an entry in the index.
+func (p *Provider) Index(ctx context.Context, key, id, text, display string, options providers.IndexOptions) error {
+	if err := p.index.Index(generateDocumentID(key, id), newDocument(key, id, text, display, options)); err != nil {
+		return fmt.Errorf("failed to index document: %w", err)
+	}
+	return nil
+}
+
+// IndexAtomic indexes entries in a single batch, which becomes visible to
+// searches all at once or, if it fails, not at all.
+func (p *Provider) IndexAtomic(ctx context.Context, key string, entries []providers.IndexEntry) error {
+	batch := p.index.NewBatch()
+	for _, entry := range entries {
+		doc := newDocument(key, entry.ID, entry.Text, entry.Display, entry.Options)
+		if err := batch.Index(generateDocumentID(key, entry.ID), doc); err != nil {
+			return fmt.Errorf("failed to add document to batch: %w", err)
+		}
+	}
+	if err := p.index.Batch(batch); err != nil {
+		return fmt.Errorf("failed to index batch: %w", err)
+	}
+	return nil
+}
+
+// ContentHash returns the stored content hash for an entry and whether it exists.
+func (p *Provider) ContentHash(ctx context.Context, key, id string) (string, bool, error) {
+	request := blevesearch.NewSearchRequest(blevesearch.NewDocIDQuery([]string{generateDocumentID(key, id)}))
+	request.Fields = []string{"content_hash"}
+	response, err := p.index.SearchInContext(ctx, request)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get document: %w", err)
+	}
+	if len(response.Hits) == 0 {
+		return "", false, nil
+	}
+	hash, _ := response.Hits[0].Fields["content_hash"].(string)
+	return hash, true, nil
+}
+
+// SupportsMatchStrategies reports that entries can be queried under any combination
+// of strategies, since every document's text is copied into the field of each.
+func (p *Provider) SupportsMatchStrategies(strategies []providers.MatchStrategy) bool {
+	return true
+}
+
+// Query searches the namespace for entries matching the query.
+func (p *Provider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	if strings.TrimSpace(query) == "" {
+		return []providers.ProviderResult{}, nil
+	}
+
+	size := options.MaxResults
+	if size <= 0 {
+		size = defaultMaxResults
+	}
+	request := blevesearch.NewSearchRequestOptions(p.buildQuery(key, query, options), size, options.Offset, false)
+	request.Fields = []string{"entry_id", "display", "score"}
+	request.SortBy([]string{"-_score", "-score"})
+
+	response, err := p.index.SearchInContext(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute search: %w", err)
+	}
+
+	results := make([]providers.ProviderResult, 0, len(response.Hits))
+	for _, hit := range response.Hits {
+		result := providers.ProviderResult{}
+		result.ID, _ = hit.Fields["entry_id"].(string)
+		result.Display, _ = hit.Fields["display"].(string)
+		result.Score, _ = hit.Fields["score"].(float64)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// buildQuery constructs the Bleve query for a namespace, query text and match strategy.
+func (p *Provider) buildQuery(key, text string, options providers.QueryOptions) query.Query {
+	fields := fieldsFor(options.CaseSensitive)
+
+	match := blevesearch.NewMatchQuery(text)
+	match.SetOperator(query.MatchQueryOperatorAnd)
+	switch options.MatchStrategy {
+	case providers.MatchPrefix:
+		match.SetField(fields.prefix)
+		match.Analyzer = fields.queryAnalyzer
+		match.SetFuzziness(p.fuzziness)
+	case providers.MatchNGram:
+		// The query is cut into n-grams by the field's own analyzer.
+		match.SetField(fields.ngram)
+	default:
+		match.SetField(fields.substring)
+		match.Analyzer = fields.queryAnalyzer
+		match.SetFuzziness(p.fuzziness)
+	}
+
+	namespace := blevesearch.NewTermQuery(key)
+	namespace.SetField("key")
+
+	conjuncts := []query.Query{namespace, match}
+	if options.MinScore > 0 {
+		minScore, inclusive := options.MinScore, true
+		scoreRange := blevesearch.NewNumericRangeInclusiveQuery(&minScore, nil, &inclusive, nil)
+		scoreRange.SetField("score")
+		conjuncts = append(conjuncts, scoreRange)
+	}
+	return blevesearch.NewConjunctionQuery(conjuncts...)
+}
+
+// Delete removes an entry from the index.
+func (p *Provider) Delete(ctx context.Context, key, id string) error {
+	if err := p.index.Delete(generateDocumentID(key, id)); err != nil {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+	return nil
+}
+
+// DeleteAll removes all entries for a given key namespace, a batch at a time.
+func (p *Provider) DeleteAll(ctx context.Context, key string) error {
+	namespace := blevesearch.NewTermQuery(key)
+	namespace.SetField("key")
+
+	for {
+		response, err := p.index.SearchInContext(ctx, blevesearch.NewSearchRequestOptions(namespace, deleteBatchSize, 0, false))
+		if err != nil {
+			return fmt.Errorf("failed to find documents: %w", err)
+		}
+		if len(response.Hits) == 0 {
+			return nil
+		}
+
+		batch := p.index.NewBatch()
+		for _, hit := range response.Hits {
+			batch.Delete(hit.ID)
+		}
+		if err := p.index.Batch(batch); err != nil {
+			return fmt.Errorf("failed to delete documents: %w", err)
+		}
+	}
+}
+
+// Close closes the index.
+func (p *Provider) Close() error {
+	if err := p.index.Close(); err != nil && !errors.Is(err, blevesearch.ErrorIndexClosed) {
+		return err
+	}
+	return nil
+}
+
+// newDocument builds the stored document for an entry.
+func newDocument(key, id, text, display string, options providers.IndexOptions) document {
+	doc := document{
+		Key:         key,
+		EntryID:     id,
+		Text:        text,
+		Display:     display,
+		Score:       options.Score,
+		ContentHash: options.ContentHash,
+	}
+	if options.CaseSensitive {
+		doc.CasedPrefix, doc.CasedNGram, doc.CasedSubstring = text, text, text
+	} else {
+		doc.Prefix, doc.NGram, doc.Substring = text, text, text
+	}
+	return doc
+}
+
+// generateDocumentID creates a unique document ID from key and id.
+func generateDocumentID(key, id string) string {
+	return key + "\x00" + id
+}
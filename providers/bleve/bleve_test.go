@@ -0,0 +1,220 @@
+package bleve
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers"
+	"github.com/remiges-tech/autocomplete/stress"
+)
+
+const testKey = "test"
+
+func newTestProvider(t *testing.T, config Config) *Provider {
+	t.Helper()
+	if config.Path == "" {
+		config.InMemory = true
+	}
+	provider, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = provider.Close() })
+	return provider
+}
+
+func resultIDs(results []providers.ProviderResult) []string {
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+	return ids
+}
+
+func TestBleveProvider_MatchStrategies(t *testing.T) {
+	provider := newTestProvider(t, Config{})
+	ctx := context.Background()
+	entries := []providers.IndexEntry{
+		{ID: "1", Text: "Mumbai", Display: "Mumbai, MH", Options: providers.IndexOptions{Score: 1}},
+		{ID: "2", Text: "Navi Mumbai", Display: "Navi Mumbai", Options: providers.IndexOptions{Score: 2}},
+		{ID: "3", Text: "Jammu", Display: "Jammu", Options: providers.IndexOptions{Score: 3}},
+	}
+	if err := provider.IndexAtomic(ctx, testKey, entries); err != nil {
+		t.Fatalf("IndexAtomic() error = %v", err)
+	}
+	if err := provider.Index(ctx, "other", "9", "Mumbai", "Mumbai", providers.IndexOptions{}); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+
+	tests := []struct {
+		strategy providers.MatchStrategy
+		query    string
+		minScore float64
+		want     string
+	}{
+		{providers.MatchPrefix, "mum", 0, "[1 2]"},
+		{providers.MatchPrefix, "navi mum", 0, "[2]"},
+		{providers.MatchPrefix, "umb", 0, "[]"},
+		{providers.MatchPrefix, "mum", 1.5, "[2]"},
+		{providers.MatchSubstring, "amm", 0, "[3]"},
+		{providers.MatchNGram, "mbai", 0, "[1 2]"},
+		{providers.MatchNGram, "i mu", 0, "[2]"},
+	}
+	for _, tt := range tests {
+		results, err := provider.Query(ctx, testKey, tt.query, providers.QueryOptions{MatchStrategy: tt.strategy, MinScore: tt.minScore})
+		if err != nil {
+			t.Fatalf("Query(%q) error = %v", tt.query, err)
+		}
+		if got := fmt.Sprint(resultIDs(results)); got != tt.want {
+			t.Errorf("strategy %d Query(%q) = %s, want %s", tt.strategy, tt.query, got, tt.want)
+		}
+	}
+
+	// The shorter text is more relevant; the entry score only breaks ties.
+	results, err := provider.Query(ctx, testKey, "Mumbai", providers.QueryOptions{MatchStrategy: providers.MatchPrefix})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(results) != 2 || results[0].Display != "Mumbai, MH" || results[0].Score != 1 {
+		t.Errorf("Query() = %+v", results)
+	}
+}
+
+func TestBleveProvider_CaseSensitive(t *testing.T) {
+	provider := newTestProvider(t, Config{})
+	ctx := context.Background()
+	if err := provider.Index(ctx, testKey, "1", "iPhone", "iPhone", providers.IndexOptions{CaseSensitive: true}); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+
+	for query, want := range map[string]int{"iPh": 1, "iph": 0} {
+		results, err := provider.Query(ctx, testKey, query, providers.QueryOptions{MatchStrategy: providers.MatchPrefix, CaseSensitive: true})
+		if err != nil {
+			t.Fatalf("Query(%q) error = %v", query, err)
+		}
+		if len(results) != want {
+			t.Errorf("Query(%q) returned %d results, want %d", query, len(results), want)
+		}
+	}
+}
+
+func TestBleveProvider_Fuzziness(t *testing.T) {
+	provider := newTestProvider(t, Config{Fuzziness: 1})
+	ctx := context.Background()
+	if err := provider.Index(ctx, testKey, "1", "Mumbai", "Mumbai", providers.IndexOptions{}); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+
+	results, err := provider.Query(ctx, testKey, "mumbay", providers.QueryOptions{MatchStrategy: providers.MatchPrefix})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(results) != 1 {
+		t.Errorf("fuzzy Query() = %v, want entry 1", resultIDs(results))
+	}
+}
+
+func TestBleveProvider_UpdateAndDelete(t *testing.T) {
+	provider := newTestProvider(t, Config{})
+	ctx := context.Background()
+
+	if err := provider.Index(ctx, testKey, "1", "Pune", "Pune", providers.IndexOptions{ContentHash: "h1"}); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if err := provider.Index(ctx, testKey, "1", "Nagpur", "Nagpur", providers.IndexOptions{ContentHash: "h2"}); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if hash, exists, err := provider.ContentHash(ctx, testKey, "1"); err != nil || !exists || hash != "h2" {
+		t.Errorf("ContentHash() = %q, %t, %v; want h2, true", hash, exists, err)
+	}
+	if results, _ := provider.Query(ctx, testKey, "pun", providers.QueryOptions{MatchStrategy: providers.MatchPrefix}); len(results) != 0 {
+		t.Errorf("old text still matches: %v", resultIDs(results))
+	}
+
+	if err := provider.Delete(ctx, testKey, "1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, exists, err := provider.ContentHash(ctx, testKey, "1"); err != nil || exists {
+		t.Errorf("ContentHash() after delete = %t, %v; want false, nil", exists, err)
+	}
+
+	for i := 0; i < deleteBatchSize+5; i++ {
+		if err := provider.Index(ctx, testKey, fmt.Sprint(i), "Pune", "Pune", providers.IndexOptions{}); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+	if err := provider.Index(ctx, "other", "1", "Pune", "Pune", providers.IndexOptions{}); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if err := provider.DeleteAll(ctx, testKey); err != nil {
+		t.Fatalf("DeleteAll() error = %v", err)
+	}
+	if count, _ := provider.index.DocCount(); count != 1 {
+		t.Errorf("DocCount() after DeleteAll = %d, want 1", count)
+	}
+}
+
+func TestBleveProvider_Persistence(t *testing.T) {
+	path := t.TempDir() + "/index"
+	ctx := context.Background()
+
+	provider, err := New(Config{Path: path})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := provider.Index(ctx, testKey, "1", "Chennai", "Chennai", providers.IndexOptions{}); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if err := provider.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	reopened := newTestProvider(t, Config{Path: path})
+	results, err := reopened.Query(ctx, testKey, "chen", providers.QueryOptions{MatchStrategy: providers.MatchPrefix})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(results) != 1 {
+		t.Errorf("Query() after reopen = %v, want entry 1", resultIDs(results))
+	}
+}
+
+func TestBleveProvider_Registration(t *testing.T) {
+	ac, err := autocomplete.New("bleve", autocomplete.NewConfig(Config{InMemory: true}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer ac.Close()
+
+	ctx := context.Background()
+	if err := ac.Index(ctx, "1", "Kolkata", "Kolkata"); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	results, err := ac.Query(ctx, "olk", 10)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "1" {
+		t.Errorf("Query() = %+v, want entry 1", results)
+	}
+}
+
+func TestBleveProvider_Stress(t *testing.T) {
+	for _, strategy := range []autocomplete.MatchStrategy{autocomplete.MatchPrefix, autocomplete.MatchNGram, autocomplete.MatchSubstring} {
+		t.Run(strategy.String(), func(t *testing.T) {
+			options := autocomplete.DefaultOptions()
+			options.MatchStrategy = strategy
+			ac, err := autocomplete.New("bleve", autocomplete.NewConfigWithOptions(Config{InMemory: true}, options))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			defer func() { _ = ac.Close() }()
+
+			if err := stress.Run(context.Background(), ac, stress.Config{}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
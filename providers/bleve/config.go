@@ -0,0 +1,31 @@
+// Package bleve implements the autocomplete Provider interface using Bleve, a
+// pure-Go full-text search library. Like the embedded key-value providers it
+// needs no external server, but it matches with analyzers and fuzzy queries
+// the way Elasticsearch does.
+package bleve
+
+// maxFuzziness is the largest edit distance Bleve supports in fuzzy queries.
+const maxFuzziness = 2
+
+// Config holds Bleve parameters and provider-specific options.
+type Config struct {
+	// Path is the directory holding the index. An existing index is opened;
+	// otherwise a new one is created. Ignored when InMemory is set.
+	Path string
+
+	// InMemory keeps the index in memory only, e.g. for tests.
+	// Default: false
+	InMemory bool
+
+	// Fuzziness is the number of edits (insertions, deletions, or substitutions)
+	// allowed per query word, so "mumbia" still finds "Mumbai". It applies to
+	// MatchPrefix and MatchSubstring; n-gram queries are already tolerant of
+	// typos. At most 2.
+	// Default: 0 (exact matching)
+	Fuzziness int
+}
+
+// setDefaults applies default values to config fields.
+func (c *Config) setDefaults() {
+	c.Fuzziness = min(max(c.Fuzziness, 0), maxFuzziness)
+}
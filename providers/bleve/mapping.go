@@ -0,0 +1,159 @@
+package bleve
+
+import (
+	blevesearch "github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/custom"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/keyword"
+	"github.com/blevesearch/bleve/v2/analysis/token/edgengram"
+	"github.com/blevesearch/bleve/v2/analysis/token/lowercase"
+	"github.com/blevesearch/bleve/v2/analysis/token/ngram"
+	"github.com/blevesearch/bleve/v2/analysis/tokenizer/single"
+	"github.com/blevesearch/bleve/v2/analysis/tokenizer/unicode"
+	"github.com/blevesearch/bleve/v2/mapping"
+)
+
+// documentType is the mapping type of every document in the index.
+const documentType = "entry"
+
+// N-gram sizes of the analyzers, matching the Elasticsearch provider.
+const (
+	minGram     = 3
+	maxGram     = 20
+	maxEdgeGram = 20
+)
+
+// Analyzer names. The folded analyzers lower-case tokens and serve entries
+// indexed case-insensitively; the cased ones keep case.
+const (
+	prefixAnalyzer         = "autocomplete_prefix"
+	ngramAnalyzer          = "autocomplete_ngram"
+	substringAnalyzer      = "autocomplete_substring"
+	queryAnalyzer          = "autocomplete_query"
+	casedPrefixAnalyzer    = "autocomplete_cased_prefix"
+	casedNGramAnalyzer     = "autocomplete_cased_ngram"
+	casedSubstringAnalyzer = "autocomplete_cased_substring"
+	casedQueryAnalyzer     = "autocomplete_cased_query"
+	edgeNGramFilter        = "autocomplete_edge_ngram_filter"
+	ngramFilter            = "autocomplete_ngram_filter"
+)
+
+// strategyFields names the analyzed copies of an entry's text, one per match
+// strategy, and the analyzer for queries against them.
+type strategyFields struct {
+	prefix, ngram, substring string
+	queryAnalyzer            string
+}
+
+var (
+	foldedFields = strategyFields{"prefix", "ngram", "substring", queryAnalyzer}
+	casedFields  = strategyFields{"cased_prefix", "cased_ngram", "cased_substring", casedQueryAnalyzer}
+)
+
+// fieldsFor returns the fields for entries indexed with the given case
+// sensitivity, so a query only searches entries indexed alike.
+func fieldsFor(caseSensitive bool) strategyFields {
+	if caseSensitive {
+		return casedFields
+	}
+	return foldedFields
+}
+
+// newIndexMapping builds the index mapping: stored fields for the entry and one
+// analyzed field per match strategy and case sensitivity, mirroring the
+// Elasticsearch provider's prefix, n-gram and substring analyzers.
+func newIndexMapping() (*mapping.IndexMappingImpl, error) {
+	indexMapping := blevesearch.NewIndexMapping()
+
+	if err := indexMapping.AddCustomTokenFilter(edgeNGramFilter, map[string]any{
+		"type": edgengram.Name, "min": 1.0, "max": float64(maxEdgeGram),
+	}); err != nil {
+		return nil, err
+	}
+	if err := indexMapping.AddCustomTokenFilter(ngramFilter, map[string]any{
+		"type": ngram.Name, "min": float64(minGram), "max": float64(maxGram),
+	}); err != nil {
+		return nil, err
+	}
+
+	analyzers := map[string]map[string]any{
+		prefixAnalyzer:         customAnalyzer(unicode.Name, lowercase.Name, edgeNGramFilter),
+		ngramAnalyzer:          customAnalyzer(single.Name, lowercase.Name, ngramFilter),
+		substringAnalyzer:      customAnalyzer(unicode.Name, lowercase.Name, ngramFilter),
+		queryAnalyzer:          customAnalyzer(unicode.Name, lowercase.Name),
+		casedPrefixAnalyzer:    customAnalyzer(unicode.Name, edgeNGramFilter),
+		casedNGramAnalyzer:     customAnalyzer(single.Name, ngramFilter),
+		casedSubstringAnalyzer: customAnalyzer(unicode.Name, ngramFilter),
+		casedQueryAnalyzer:     customAnalyzer(unicode.Name),
+	}
+	for name, config := range analyzers {
+		if err := indexMapping.AddCustomAnalyzer(name, config); err != nil {
+			return nil, err
+		}
+	}
+
+	entryMapping := mapping.NewDocumentStaticMapping()
+	entryMapping.AddFieldMappingsAt("key", keywordField(false))
+	entryMapping.AddFieldMappingsAt("entry_id", keywordField(true))
+	entryMapping.AddFieldMappingsAt("text", storedField())
+	entryMapping.AddFieldMappingsAt("display", storedField())
+	entryMapping.AddFieldMappingsAt("content_hash", storedField())
+
+	score := mapping.NewNumericFieldMapping()
+	score.IncludeInAll = false
+	entryMapping.AddFieldMappingsAt("score", score)
+
+	fieldAnalyzers := map[string]string{
+		foldedFields.prefix:    prefixAnalyzer,
+		foldedFields.ngram:     ngramAnalyzer,
+		foldedFields.substring: substringAnalyzer,
+		casedFields.prefix:     casedPrefixAnalyzer,
+		casedFields.ngram:      casedNGramAnalyzer,
+		casedFields.substring:  casedSubstringAnalyzer,
+	}
+	for field, analyzer := range fieldAnalyzers {
+		entryMapping.AddFieldMappingsAt(field, analyzedField(analyzer))
+	}
+
+	indexMapping.AddDocumentMapping(documentType, entryMapping)
+	indexMapping.DefaultMapping = mapping.NewDocumentDisabledMapping()
+	indexMapping.DefaultType = documentType
+	return indexMapping, nil
+}
+
+// customAnalyzer returns the definition of a custom analyzer.
+func customAnalyzer(tokenizer string, filters ...string) map[string]any {
+	analyzer := map[string]any{"type": custom.Name, "tokenizer": tokenizer}
+	if len(filters) > 0 {
+		analyzer["token_filters"] = filters
+	}
+	return analyzer
+}
+
+// keywordField maps a field indexed as a single term.
+func keywordField(store bool) *mapping.FieldMapping {
+	field := mapping.NewKeywordFieldMapping()
+	field.Analyzer = keyword.Name
+	field.Store = store
+	field.IncludeInAll = false
+	field.IncludeTermVectors = false
+	return field
+}
+
+// storedField maps a field that is only stored, never searched.
+func storedField() *mapping.FieldMapping {
+	field := mapping.NewTextFieldMapping()
+	field.Index = false
+	field.IncludeInAll = false
+	field.IncludeTermVectors = false
+	return field
+}
+
+// analyzedField maps a searched copy of the text that is not stored.
+func analyzedField(analyzer string) *mapping.FieldMapping {
+	field := mapping.NewTextFieldMapping()
+	field.Analyzer = analyzer
+	field.Store = false
+	field.IncludeInAll = false
+	field.IncludeTermVectors = false
+	return field
+}
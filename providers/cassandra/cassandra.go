@@ -0,0 +1,448 @@
+package cassandra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gocql/gocql"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+const (
+	// defaultNGramSize is the default n-gram size when not specified in options.
+	defaultNGramSize = 3
+
+	// Prefixes tagging the terms of token rows by strategy, so that prefix and
+	// n-gram tokens of the same namespace never share a partition.
+	prefixTerm = "p:"
+	nGramTerm  = "n:"
+)
+
+// keyspacePattern matches the unquoted identifiers accepted as keyspace names,
+// which are interpolated into statements.
+var keyspacePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{0,47}$`)
+
+// Provider implements the autocomplete Provider interface using Cassandra or ScyllaDB.
+// Every token of a namespace is a partition of the tokens table, keyed by
+// namespace and term, whose wide rows are the entries producing the token,
+// clustered by score. A query therefore reads one partition, already ranked.
+// Entries are stored once more in the entries table, spread across Buckets
+// partitions, holding what is needed to recompute their tokens.
+// All methods are safe for concurrent use.
+type Provider struct {
+	session         *gocql.Session
+	keyspace        string
+	read            gocql.Consistency
+	write           gocql.Consistency
+	maxPrefixLength int
+	buckets         int
+}
+
+// entry is the stored form of an entry row.
+type entry struct {
+	searchText string
+	score      float64
+	strategy   providers.MatchStrategy
+	nGramSize  int
+}
+
+// New creates a new Cassandra provider with the given configuration.
+// It connects to the cluster and, if CreateSchema is set, creates the keyspace and tables.
+func New(config Config) (*Provider, error) {
+	config.setDefaults()
+	if !keyspacePattern.MatchString(config.Keyspace) {
+		return nil, fmt.Errorf("invalid keyspace name %q", config.Keyspace)
+	}
+	read, err := gocql.ParseConsistencyWrapper(config.ReadConsistency)
+	if err != nil {
+		return nil, fmt.Errorf("invalid read consistency: %w", err)
+	}
+	write, err := gocql.ParseConsistencyWrapper(config.WriteConsistency)
+	if err != nil {
+		return nil, fmt.Errorf("invalid write consistency: %w", err)
+	}
+
+	cluster := gocql.NewCluster(config.Hosts...)
+	if config.Username != "" {
+		cluster.Authenticator = gocql.PasswordAuthenticator{Username: config.Username, Password: config.Password}
+	}
+	if config.Timeout > 0 {
+		cluster.Timeout = config.Timeout
+		cluster.ConnectTimeout = config.Timeout
+	}
+	session, err := cluster.CreateSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Cassandra: %w", err)
+	}
+
+	p := &Provider{
+		session:         session,
+		keyspace:        config.Keyspace,
+		read:            read,
+		write:           write,
+		maxPrefixLength: config.MaxPrefixLength,
+		buckets:         config.Buckets,
+	}
+	if config.CreateSchema {
+		if err := p.createSchema(context.Background(), config.Replication); err != nil {
+			session.Close()
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// createSchema creates the keyspace and tables unless they already exist.
+func (p *Provider) createSchema(ctx context.Context, replication string) error {
+	statements := []string{
+		fmt.Sprintf("CREATE KEYSPACE IF NOT EXISTS %s WITH replication = %s", p.keyspace, replication),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			namespace text, bucket int, id text,
+			text text, search_text text, display text, score double,
+			strategy int, ngram_size int, content_hash text,
+			PRIMARY KEY ((namespace, bucket), id))`, p.table("entries")),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			namespace text, term text, score double, id text,
+			search_text text, display text,
+			PRIMARY KEY ((namespace, term), score, id))
+			WITH CLUSTERING ORDER BY (score DESC, id ASC)`, p.table("tokens")),
+	}
+	for _, stmt := range statements {
+		if err := p.session.Query(stmt).WithContext(ctx).Exec(); err != nil {
+			return fmt.Errorf("failed to create schema: %w", err)
+		}
+	}
+	return nil
+}
+
+// Index adds or updates an entry.
+// Writes are ordered so that an interrupted Index can be retried, and never
+// leaves token rows that the stored entry does not account for: token rows the
+// new text or score no longer produces are deleted first, then the entry row
+// is written, then the new token rows.
+func (p *Provider) Index(ctx context.Context, key, id, text, display string, options providers.IndexOptions) error {
+	searchText := providers.SearchText(text, options.CaseSensitive)
+	n := getNGramSizeOrDefault(options.NGramSize)
+	newTokens, err := tokenize(searchText, options.MatchStrategy, n, p.maxPrefixLength)
+	if err != nil {
+		return err
+	}
+
+	old, exists, err := p.getEntry(ctx, key, id)
+	if err != nil {
+		return fmt.Errorf("failed to read existing entry: %w", err)
+	}
+	if exists {
+		oldTokens, err := tokenize(old.searchText, old.strategy, old.nGramSize, p.maxPrefixLength)
+		if err != nil {
+			return err
+		}
+		for term := range oldTokens {
+			if _, kept := newTokens[term]; kept && old.score == options.Score {
+				continue
+			}
+			if err := p.deleteToken(ctx, key, term, old.score, id); err != nil {
+				return fmt.Errorf("failed to delete stale token: %w", err)
+			}
+		}
+	}
+
+	err = p.exec(ctx, fmt.Sprintf(`INSERT INTO %s
+		(namespace, bucket, id, text, search_text, display, score, strategy, ngram_size, content_hash)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, p.table("entries")),
+		key, bucket(id, p.buckets), id, text, searchText, display, options.Score,
+		int(options.MatchStrategy), n, options.ContentHash)
+	if err != nil {
+		return fmt.Errorf("failed to index entry: %w", err)
+	}
+
+	insert := fmt.Sprintf(`INSERT INTO %s (namespace, term, score, id, search_text, display)
+		VALUES (?, ?, ?, ?, ?, ?)`, p.table("tokens"))
+	for term := range newTokens {
+		if err := p.exec(ctx, insert, key, term, options.Score, id, searchText, display); err != nil {
+			return fmt.Errorf("failed to index token: %w", err)
+		}
+	}
+	return nil
+}
+
+// getEntry reads the entry row for id and reports whether it exists.
+func (p *Provider) getEntry(ctx context.Context, key, id string) (entry, bool, error) {
+	var e entry
+	var strategy int
+	err := p.session.Query(fmt.Sprintf(`SELECT search_text, score, strategy, ngram_size FROM %s
+		WHERE namespace = ? AND bucket = ? AND id = ?`, p.table("entries")),
+		key, bucket(id, p.buckets), id).
+		WithContext(ctx).Consistency(p.read).
+		Scan(&e.searchText, &e.score, &strategy, &e.nGramSize)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return entry{}, false, nil
+	}
+	if err != nil {
+		return entry{}, false, err
+	}
+	e.strategy = providers.MatchStrategy(strategy)
+	return e, true, nil
+}
+
+// ContentHash returns the stored content hash for an entry and whether it exists.
+func (p *Provider) ContentHash(ctx context.Context, key, id string) (string, bool, error) {
+	var hash string
+	err := p.session.Query(fmt.Sprintf(`SELECT content_hash FROM %s
+		WHERE namespace = ? AND bucket = ? AND id = ?`, p.table("entries")),
+		key, bucket(id, p.buckets), id).
+		WithContext(ctx).Consistency(p.read).
+		Scan(&hash)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get content hash: %w", err)
+	}
+	return hash, true, nil
+}
+
+// Query searches for entries matching the given query.
+// Results are ranked by entry score, then ID, which is the clustering order
+// of token partitions.
+func (p *Provider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	searchQuery := providers.SearchText(query, options.CaseSensitive)
+	if searchQuery == "" {
+		return []providers.ProviderResult{}, nil
+	}
+
+	term, filter, err := queryPlan(searchQuery, options.MatchStrategy, getNGramSizeOrDefault(options.NGramSize), p.maxPrefixLength)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := fmt.Sprintf("SELECT id, display, score, search_text FROM %s WHERE namespace = ? AND term = ?", p.table("tokens"))
+	values := []interface{}{key, term}
+	if options.MinScore > 0 {
+		stmt += " AND score >= ?"
+		values = append(values, options.MinScore)
+	}
+	// A filtered read cannot know how many rows it needs, so it pages through
+	// the partition until enough rows pass the filter
+	if filter == nil && options.MaxResults > 0 {
+		stmt += " LIMIT ?"
+		values = append(values, options.MaxResults)
+	}
+
+	iter := p.session.Query(stmt, values...).WithContext(ctx).Consistency(p.read).Iter()
+	results := []providers.ProviderResult{}
+	var result providers.ProviderResult
+	var searchText string
+	for iter.Scan(&result.ID, &result.Display, &result.Score, &searchText) {
+		if filter != nil && !filter(searchText) {
+			continue
+		}
+		results = append(results, result)
+		if options.MaxResults > 0 && len(results) == options.MaxResults {
+			break
+		}
+	}
+	if err := iter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to query autocomplete: %w", err)
+	}
+	return results, nil
+}
+
+// Delete removes an entry and its tokens from the index.
+func (p *Provider) Delete(ctx context.Context, key, id string) error {
+	old, exists, err := p.getEntry(ctx, key, id)
+	if err != nil {
+		return fmt.Errorf("failed to read existing entry: %w", err)
+	}
+	if !exists {
+		return nil
+	}
+
+	if err := p.deleteTokens(ctx, key, id, old); err != nil {
+		return err
+	}
+	// The entry row goes last, so a failed delete can be retried
+	err = p.exec(ctx, fmt.Sprintf("DELETE FROM %s WHERE namespace = ? AND bucket = ? AND id = ?", p.table("entries")),
+		key, bucket(id, p.buckets), id)
+	if err != nil {
+		return fmt.Errorf("failed to delete entry: %w", err)
+	}
+	return nil
+}
+
+// DeleteAll removes every entry of the namespace, one bucket at a time.
+func (p *Provider) DeleteAll(ctx context.Context, key string) error {
+	list := fmt.Sprintf(`SELECT id, search_text, score, strategy, ngram_size FROM %s
+		WHERE namespace = ? AND bucket = ?`, p.table("entries"))
+	for b := 0; b < p.buckets; b++ {
+		iter := p.session.Query(list, key, b).WithContext(ctx).Consistency(p.read).Iter()
+		var id string
+		var e entry
+		var strategy int
+		for iter.Scan(&id, &e.searchText, &e.score, &strategy, &e.nGramSize) {
+			e.strategy = providers.MatchStrategy(strategy)
+			if err := p.deleteTokens(ctx, key, id, e); err != nil {
+				iter.Close()
+				return err
+			}
+		}
+		if err := iter.Close(); err != nil {
+			return fmt.Errorf("failed to list entries: %w", err)
+		}
+
+		err := p.exec(ctx, fmt.Sprintf("DELETE FROM %s WHERE namespace = ? AND bucket = ?", p.table("entries")), key, b)
+		if err != nil {
+			return fmt.Errorf("failed to delete entries: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close closes the session to the cluster.
+func (p *Provider) Close() error {
+	p.session.Close()
+	return nil
+}
+
+// deleteTokens deletes the token rows of a stored entry.
+func (p *Provider) deleteTokens(ctx context.Context, key, id string, e entry) error {
+	tokens, err := tokenize(e.searchText, e.strategy, e.nGramSize, p.maxPrefixLength)
+	if err != nil {
+		return err
+	}
+	for term := range tokens {
+		if err := p.deleteToken(ctx, key, term, e.score, id); err != nil {
+			return fmt.Errorf("failed to delete token: %w", err)
+		}
+	}
+	return nil
+}
+
+// deleteToken deletes one token row.
+func (p *Provider) deleteToken(ctx context.Context, key, term string, score float64, id string) error {
+	return p.exec(ctx, fmt.Sprintf("DELETE FROM %s WHERE namespace = ? AND term = ? AND score = ? AND id = ?", p.table("tokens")),
+		key, term, score, id)
+}
+
+// exec runs a write statement at the write consistency level.
+func (p *Provider) exec(ctx context.Context, stmt string, values ...interface{}) error {
+	return p.session.Query(stmt, values...).WithContext(ctx).Consistency(p.write).Exec()
+}
+
+// table returns the name of a table qualified by the provider's keyspace.
+func (p *Provider) table(name string) string {
+	return p.keyspace + "." + name
+}
+
+// tokenize returns the terms of the token rows stored for normalized text
+// under the given strategy.
+// MatchPrefix stores each prefix up to maxPrefixLength characters. MatchNGram
+// stores each n-gram together with its shorter prefixes, so that queries up
+// to n characters long find the n-grams they begin in a single partition.
+func tokenize(text string, strategy providers.MatchStrategy, n, maxPrefixLength int) (map[string]struct{}, error) {
+	tokens := make(map[string]struct{})
+	switch strategy {
+	case providers.MatchPrefix:
+		// count is the number of characters in text[:i]
+		count := 0
+		for i := range text {
+			if count > 0 {
+				tokens[prefixTerm+text[:i]] = struct{}{}
+			}
+			if count == maxPrefixLength {
+				return tokens, nil
+			}
+			count++
+		}
+		if text != "" {
+			tokens[prefixTerm+text] = struct{}{}
+		}
+	case providers.MatchNGram:
+		runes := []rune(text)
+		for start := 0; start+n <= len(runes); start++ {
+			for end := start + 1; end <= start+n; end++ {
+				tokens[nGramTerm+string(runes[start:end])] = struct{}{}
+			}
+		}
+	default:
+		return nil, unsupportedStrategy(strategy)
+	}
+	return tokens, nil
+}
+
+// queryPlan returns the term of the token partition a query reads and, when
+// the partition holds more than the matches, a filter on the entries' search text.
+// Prefix queries longer than maxPrefixLength read the partition of their
+// capped prefix; n-gram queries longer than n read the partition of their
+// first n-gram and keep the entries containing every other n-gram.
+func queryPlan(searchQuery string, strategy providers.MatchStrategy, n, maxPrefixLength int) (string, func(string) bool, error) {
+	switch strategy {
+	case providers.MatchPrefix:
+		capped := truncateToRunes(searchQuery, maxPrefixLength)
+		if capped == searchQuery {
+			return prefixTerm + searchQuery, nil, nil
+		}
+		return prefixTerm + capped, func(searchText string) bool {
+			return strings.HasPrefix(searchText, searchQuery)
+		}, nil
+	case providers.MatchNGram:
+		runes := []rune(searchQuery)
+		if len(runes) <= n {
+			return nGramTerm + searchQuery, nil, nil
+		}
+		grams := make([]string, 0, len(runes)-n)
+		for start := 1; start+n <= len(runes); start++ {
+			grams = append(grams, string(runes[start:start+n]))
+		}
+		return nGramTerm + string(runes[:n]), func(searchText string) bool {
+			for _, gram := range grams {
+				if !strings.Contains(searchText, gram) {
+					return false
+				}
+			}
+			return true
+		}, nil
+	default:
+		return "", nil, unsupportedStrategy(strategy)
+	}
+}
+
+// unsupportedStrategy returns the error for strategies this provider cannot serve.
+func unsupportedStrategy(strategy providers.MatchStrategy) error {
+	return fmt.Errorf("match strategy %d is not supported by the Cassandra provider: use MatchPrefix or MatchNGram", strategy)
+}
+
+// truncateToRunes shortens s to at most limit characters.
+func truncateToRunes(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	length := 0
+	for i := range s {
+		if length == limit {
+			return s[:i]
+		}
+		length++
+	}
+	return s
+}
+
+// bucket returns the entries partition holding id.
+func bucket(id string, buckets int) int {
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	return int(h.Sum32() % uint32(buckets))
+}
+
+func getNGramSizeOrDefault(size int) int {
+	if size <= 0 {
+		return defaultNGramSize
+	}
+	return size
+}
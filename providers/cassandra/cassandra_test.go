@@ -0,0 +1,376 @@
+package cassandra
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+const testKey = "test"
+
+// startCassandra starts a Cassandra container and returns its address,
+// skipping the test when Docker is unavailable.
+func startCassandra(t *testing.T) string {
+	t.Helper()
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	ctx := context.Background()
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "cassandra:4.1",
+			ExposedPorts: []string{"9042/tcp"},
+			Env: map[string]string{
+				"MAX_HEAP_SIZE": "512M",
+				"HEAP_NEWSIZE":  "128M",
+			},
+			WaitingFor: wait.ForLog("Starting listening for CQL clients").WithStartupTimeout(3 * time.Minute),
+		},
+		Started: true,
+	})
+	testcontainers.CleanupContainer(t, container)
+	if err != nil {
+		t.Fatalf("failed to start Cassandra container: %v", err)
+	}
+	host, err := container.Host(ctx)
+	if err != nil {
+		t.Fatalf("failed to get container host: %v", err)
+	}
+	port, err := container.MappedPort(ctx, "9042")
+	if err != nil {
+		t.Fatalf("failed to get container port: %v", err)
+	}
+	return fmt.Sprintf("%s:%s", host, port.Port())
+}
+
+// newTestProvider returns a provider with config, closed when the test ends.
+func newTestProvider(t *testing.T, config Config) *Provider {
+	t.Helper()
+	provider, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = provider.Close() })
+	return provider
+}
+
+// queryIDs returns the IDs of the results of a query, in order.
+func queryIDs(t *testing.T, provider *Provider, key, query string, options providers.QueryOptions) []string {
+	t.Helper()
+	results, err := provider.Query(context.Background(), key, query, options)
+	if err != nil {
+		t.Fatalf("Query(%q) error = %v", query, err)
+	}
+	ids := make([]string, len(results))
+	for i, result := range results {
+		ids[i] = result.ID
+	}
+	return ids
+}
+
+func TestCassandraProvider_RoundTrip(t *testing.T) {
+	addr := startCassandra(t)
+	config := Config{Hosts: []string{addr}, CreateSchema: true, MaxPrefixLength: 4, Buckets: 4, Timeout: 10 * time.Second}
+	provider := newTestProvider(t, config)
+	ctx := context.Background()
+
+	entries := []struct {
+		id, text string
+		score    float64
+		strategy providers.MatchStrategy
+	}{
+		{"1", "Mumbai", 2, providers.MatchPrefix},
+		{"2", "Mumbra", 3, providers.MatchPrefix},
+		{"3", "Pune", 1, providers.MatchPrefix},
+		{"4", "Navi Mumbai", 5, providers.MatchPrefix},
+		{"5", "Mumbai Central", 4, providers.MatchNGram},
+		{"6", "Thane", 1, providers.MatchNGram},
+	}
+	for _, entry := range entries {
+		options := providers.IndexOptions{Score: entry.score, MatchStrategy: entry.strategy, NGramSize: 3, ContentHash: "v1-" + entry.id}
+		if err := provider.Index(ctx, testKey, entry.id, entry.text, entry.text, options); err != nil {
+			t.Fatalf("Index(%s) error = %v", entry.id, err)
+		}
+	}
+	// Enough entries to fill every bucket, for DeleteAll to fan out over
+	for i := range 16 {
+		id := "x" + strconv.Itoa(i)
+		if err := provider.Index(ctx, testKey, id, "Extra "+id, id, providers.IndexOptions{Score: 1, MatchStrategy: providers.MatchPrefix}); err != nil {
+			t.Fatalf("Index(%s) error = %v", id, err)
+		}
+	}
+	// The same ID in another namespace must not show up in testKey
+	if err := provider.Index(ctx, "other", "1", "Mumbai", "Mumbai", providers.IndexOptions{Score: 9, MatchStrategy: providers.MatchPrefix}); err != nil {
+		t.Fatalf("Index() in another namespace error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		strategy providers.MatchStrategy
+		limit    int
+		minScore float64
+		want     []string
+	}{
+		{"prefix", "MUM", providers.MatchPrefix, 10, 0, []string{"2", "1"}},
+		{"prefix longer than MaxPrefixLength", "mumbai", providers.MatchPrefix, 10, 0, []string{"1"}},
+		{"prefix limit", "mum", providers.MatchPrefix, 1, 0, []string{"2"}},
+		{"prefix min score", "mum", providers.MatchPrefix, 10, 2.5, []string{"2"}},
+		{"ngram", "umb", providers.MatchNGram, 10, 0, []string{"5"}},
+		{"ngram shorter than n", "th", providers.MatchNGram, 10, 0, []string{"6"}},
+		{"ngram longer than n", "ntral", providers.MatchNGram, 10, 0, []string{"5"}},
+		{"ngram filtered out", "mumbx", providers.MatchNGram, 10, 0, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := providers.QueryOptions{MatchStrategy: tt.strategy, NGramSize: 3, MaxResults: tt.limit, MinScore: tt.minScore}
+			if got := queryIDs(t, provider, testKey, tt.query, options); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Query(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+	if _, err := provider.Query(ctx, testKey, "umb", providers.QueryOptions{MatchStrategy: providers.MatchSubstring}); err == nil {
+		t.Error("Query() with MatchSubstring should fail")
+	}
+
+	// Reindexing with new text and score deletes the old token rows
+	prefix := providers.QueryOptions{MatchStrategy: providers.MatchPrefix, MaxResults: 10}
+	if err := provider.Index(ctx, testKey, "2", "Pune Camp", "Pune Camp", providers.IndexOptions{Score: 0.5, MatchStrategy: providers.MatchPrefix, ContentHash: "v2-2"}); err != nil {
+		t.Fatalf("Index() overwrite error = %v", err)
+	}
+	if got := queryIDs(t, provider, testKey, "mum", prefix); !reflect.DeepEqual(got, []string{"1"}) {
+		t.Errorf("Query(mum) after overwrite = %v, want [1]", got)
+	}
+	if got := queryIDs(t, provider, testKey, "pun", prefix); !reflect.DeepEqual(got, []string{"3", "2"}) {
+		t.Errorf("Query(pun) after overwrite = %v, want [3 2]", got)
+	}
+	if hash, ok, err := provider.ContentHash(ctx, testKey, "2"); err != nil || !ok || hash != "v2-2" {
+		t.Errorf("ContentHash(2) = %q, %v, %v; want v2-2", hash, ok, err)
+	}
+
+	// Delete removes the entry's rows from every token partition
+	if err := provider.Delete(ctx, testKey, "5"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	for _, query := range []string{"mum", "umb", "ntr", "al"} {
+		ngram := providers.QueryOptions{MatchStrategy: providers.MatchNGram, NGramSize: 3, MaxResults: 10}
+		if got := queryIDs(t, provider, testKey, query, ngram); len(got) != 0 {
+			t.Errorf("n-gram Query(%q) after Delete = %v, want none", query, got)
+		}
+	}
+	if _, ok, err := provider.ContentHash(ctx, testKey, "5"); err != nil || ok {
+		t.Errorf("ContentHash(5) after Delete = %v, %v; want not found", ok, err)
+	}
+	if err := provider.Delete(ctx, testKey, "missing"); err != nil {
+		t.Errorf("Delete() of a missing entry error = %v", err)
+	}
+
+	// DeleteAll reads every bucket of the namespace and no other namespace
+	if err := provider.DeleteAll(ctx, testKey); err != nil {
+		t.Fatalf("DeleteAll() error = %v", err)
+	}
+	for _, query := range []string{"m", "p", "n", "e"} {
+		if got := queryIDs(t, provider, testKey, query, prefix); len(got) != 0 {
+			t.Errorf("Query(%q) after DeleteAll = %v, want none", query, got)
+		}
+	}
+	for i := range 16 {
+		id := "x" + strconv.Itoa(i)
+		if _, ok, err := provider.ContentHash(ctx, testKey, id); err != nil || ok {
+			t.Errorf("ContentHash(%s) after DeleteAll = %v, %v; want not found", id, ok, err)
+		}
+	}
+	if got := queryIDs(t, provider, "other", "mum", prefix); !reflect.DeepEqual(got, []string{"1"}) {
+		t.Errorf("Query(mum) in the other namespace after DeleteAll = %v, want [1]", got)
+	}
+
+	// Reads and writes use the configured consistency levels, which a single
+	// replica cannot satisfy at THREE
+	strictRead := config
+	strictRead.CreateSchema, strictRead.ReadConsistency = false, "THREE"
+	if _, err := newTestProvider(t, strictRead).Query(ctx, "other", "mum", prefix); err == nil {
+		t.Error("Query() at read consistency THREE on one replica should fail")
+	}
+	strictWrite := config
+	strictWrite.CreateSchema, strictWrite.WriteConsistency = false, "THREE"
+	err := newTestProvider(t, strictWrite).Index(ctx, "other", "2", "Mumbra", "Mumbra", providers.IndexOptions{MatchStrategy: providers.MatchPrefix})
+	if err == nil {
+		t.Error("Index() at write consistency THREE on one replica should fail")
+	}
+}
+
+func terms(tokens map[string]struct{}) []string {
+	out := make([]string, 0, len(tokens))
+	for term := range tokens {
+		out = append(out, term)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		strategy  providers.MatchStrategy
+		maxPrefix int
+		want      []string
+	}{
+		{"prefix", "pune", providers.MatchPrefix, 20, []string{"p:p", "p:pu", "p:pun", "p:pune"}},
+		{"prefix capped", "mumbai", providers.MatchPrefix, 3, []string{"p:m", "p:mu", "p:mum"}},
+		{"prefix counts characters", "मुंबई", providers.MatchPrefix, 2, []string{"p:म", "p:मु"}},
+		{"prefix empty", "", providers.MatchPrefix, 20, []string{}},
+		{"ngram", "goa", providers.MatchNGram, 20, []string{"n:g", "n:go", "n:goa"}},
+		{"ngram overlapping", "abcd", providers.MatchNGram, 20, []string{"n:a", "n:ab", "n:abc", "n:b", "n:bc", "n:bcd"}},
+		{"ngram too short", "ab", providers.MatchNGram, 20, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := tokenize(tt.text, tt.strategy, 3, tt.maxPrefix)
+			if err != nil {
+				t.Fatalf("tokenize() error = %v", err)
+			}
+			if got := terms(tokens); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokenize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := tokenize("pune", providers.MatchSubstring, 3, 20); err == nil {
+		t.Error("tokenize() with MatchSubstring should fail")
+	}
+}
+
+func TestQueryPlan(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		strategy providers.MatchStrategy
+		wantTerm string
+		matches  []string
+		rejects  []string
+	}{
+		{name: "short prefix", query: "mum", strategy: providers.MatchPrefix, wantTerm: "p:mum"},
+		{
+			name: "long prefix", query: "mumbai c", strategy: providers.MatchPrefix, wantTerm: "p:mumba",
+			matches: []string{"mumbai central"}, rejects: []string{"mumbadevi"},
+		},
+		{name: "short ngram", query: "ba", strategy: providers.MatchNGram, wantTerm: "n:ba"},
+		{
+			name: "long ngram", query: "angal", strategy: providers.MatchNGram, wantTerm: "n:ang",
+			matches: []string{"bangalore"}, rejects: []string{"angola"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			term, filter, err := queryPlan(tt.query, tt.strategy, 3, 5)
+			if err != nil {
+				t.Fatalf("queryPlan() error = %v", err)
+			}
+			if term != tt.wantTerm {
+				t.Errorf("queryPlan() term = %q, want %q", term, tt.wantTerm)
+			}
+			if (filter == nil) != (len(tt.matches) == 0) {
+				t.Fatalf("queryPlan() filter = %v, want filter: %v", filter != nil, len(tt.matches) > 0)
+			}
+			for _, text := range tt.matches {
+				if !filter(text) {
+					t.Errorf("filter(%q) = false, want true", text)
+				}
+			}
+			for _, text := range tt.rejects {
+				if filter(text) {
+					t.Errorf("filter(%q) = true, want false", text)
+				}
+			}
+		})
+	}
+
+	if _, _, err := queryPlan("pune", providers.MatchSubstring, 3, 5); err == nil {
+		t.Error("queryPlan() with MatchSubstring should fail")
+	}
+}
+
+func TestQueryPlanFindsIndexedTokens(t *testing.T) {
+	queries := map[providers.MatchStrategy][]string{
+		providers.MatchPrefix: {"b", "ban", "bangal", "bangalore"},
+		providers.MatchNGram:  {"a", "ang", "angal", "angalore"},
+	}
+	for strategy, list := range queries {
+		tokens, err := tokenize("bangalore", strategy, 3, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, query := range list {
+			term, filter, err := queryPlan(query, strategy, 3, 5)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := tokens[term]; !ok {
+				t.Errorf("%v query %q reads partition %q, which bangalore is not indexed under", strategy, query, term)
+			}
+			if filter != nil && !filter("bangalore") {
+				t.Errorf("%v query %q filters out bangalore", strategy, query)
+			}
+		}
+	}
+}
+
+func TestBucket(t *testing.T) {
+	seen := make(map[int]bool)
+	for _, id := range []string{"1", "2", "3", "4", "5", "6", "7", "8"} {
+		b := bucket(id, 4)
+		if b < 0 || b >= 4 {
+			t.Fatalf("bucket(%q, 4) = %d, out of range", id, b)
+		}
+		if bucket(id, 4) != b {
+			t.Fatalf("bucket(%q, 4) is not stable", id)
+		}
+		seen[b] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("bucket() put 8 IDs in %d bucket(s)", len(seen))
+	}
+}
+
+func TestConfigDefaults(t *testing.T) {
+	var config Config
+	config.setDefaults()
+
+	if !reflect.DeepEqual(config.Hosts, []string{"127.0.0.1"}) {
+		t.Errorf("Hosts = %v", config.Hosts)
+	}
+	if config.Keyspace != defaultKeyspace || config.ReadConsistency != defaultConsistency ||
+		config.WriteConsistency != defaultConsistency || config.Replication != defaultReplication {
+		t.Errorf("unexpected defaults: %+v", config)
+	}
+	if config.MaxPrefixLength != defaultMaxPrefixLength || config.Buckets != defaultBuckets {
+		t.Errorf("MaxPrefixLength = %d, Buckets = %d", config.MaxPrefixLength, config.Buckets)
+	}
+}
+
+func TestNewRejectsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{"keyspace", Config{Keyspace: "ac; DROP KEYSPACE system"}},
+		{"read consistency", Config{ReadConsistency: "MOST"}},
+		{"write consistency", Config{WriteConsistency: "ALMOST_ALL"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.config); err == nil {
+				t.Error("New() should fail")
+			}
+		})
+	}
+}
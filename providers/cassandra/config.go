@@ -0,0 +1,99 @@
+// Package cassandra implements the autocomplete Provider interface using
+// Apache Cassandra or ScyllaDB, for datasets too large for a single Redis node.
+// It supports the MatchPrefix and MatchNGram strategies.
+package cassandra
+
+import "time"
+
+const (
+	// defaultKeyspace is the keyspace used when Config.Keyspace is empty.
+	defaultKeyspace = "autocomplete"
+
+	// defaultConsistency is the consistency level used when none is configured.
+	defaultConsistency = "LOCAL_QUORUM"
+
+	// defaultReplication is the keyspace replication used by CreateSchema when
+	// Config.Replication is empty. It suits a single-node development cluster.
+	defaultReplication = "{'class': 'SimpleStrategy', 'replication_factor': 1}"
+
+	// defaultMaxPrefixLength is the longest prefix, in characters, stored as
+	// its own partition for MatchPrefix.
+	defaultMaxPrefixLength = 20
+
+	// defaultBuckets is the number of partitions entry rows of a namespace are
+	// spread across.
+	defaultBuckets = 16
+)
+
+// Config holds Cassandra connection parameters and provider-specific options.
+type Config struct {
+	// Hosts are the initial contact points of the cluster, e.g. "10.0.0.1:9042".
+	// Default: ["127.0.0.1"]
+	Hosts []string
+
+	// Keyspace holds the provider's tables. It must be a plain identifier.
+	// Default: "autocomplete"
+	Keyspace string
+
+	// Username and Password enable password authentication when Username is set.
+	Username string
+	Password string
+
+	// ReadConsistency and WriteConsistency are the consistency levels of
+	// queries and writes, by name (e.g. "ONE", "LOCAL_QUORUM", "QUORUM").
+	// Default: "LOCAL_QUORUM"
+	ReadConsistency  string
+	WriteConsistency string
+
+	// CreateSchema creates the keyspace and tables if they do not exist.
+	// Schemas managed elsewhere should leave this disabled.
+	// Default: false
+	CreateSchema bool
+
+	// Replication is the replication map CreateSchema gives a new keyspace, e.g.
+	// "{'class': 'NetworkTopologyStrategy', 'dc1': 3}".
+	// Default: SimpleStrategy with a replication factor of 1
+	Replication string
+
+	// MaxPrefixLength is the longest prefix, in characters, stored as its own
+	// partition for MatchPrefix. Longer queries read the partition of their
+	// first MaxPrefixLength characters and filter it. Changing this requires
+	// reindexing all data.
+	// Default: 20
+	MaxPrefixLength int
+
+	// Buckets is the number of partitions entry rows of a namespace are spread
+	// across, keeping partitions of large namespaces small. Changing this
+	// requires reindexing all data.
+	// Default: 16
+	Buckets int
+
+	// Timeout bounds each request to the cluster.
+	// Zero (default) uses the driver's default.
+	Timeout time.Duration
+}
+
+// setDefaults applies default values to config fields.
+func (c *Config) setDefaults() {
+	if len(c.Hosts) == 0 {
+		c.Hosts = []string{"127.0.0.1"}
+	}
+	if c.Keyspace == "" {
+		c.Keyspace = defaultKeyspace
+	}
+	if c.ReadConsistency == "" {
+		c.ReadConsistency = defaultConsistency
+	}
+	if c.WriteConsistency == "" {
+		c.WriteConsistency = defaultConsistency
+	}
+	if c.Replication == "" {
+		c.Replication = defaultReplication
+	}
+	if c.MaxPrefixLength <= 0 {
+		c.MaxPrefixLength = defaultMaxPrefixLength
+	}
+	if c.Buckets <= 0 {
+		c.Buckets = defaultBuckets
+	}
+}
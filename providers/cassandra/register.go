@@ -0,0 +1,29 @@
+package cassandra
+
+import (
+	"fmt"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// init registers the Cassandra provider. Import this package with a blank identifier
+// to use Cassandra as the autocomplete backend:
+//
+//	import _ "github.com/remiges-tech/autocomplete/providers/cassandra"
+//
+//nolint:gochecknoinits // init() is the idiomatic pattern for provider registration
+func init() {
+	autocomplete.RegisterProvider("cassandra", NewProvider)
+}
+
+// NewProvider creates a new Cassandra provider from the given configuration.
+// It implements ProviderFactory and expects config to be of type cassandra.Config.
+func NewProvider(config interface{}) (providers.Provider, error) {
+	cassandraConfig, ok := config.(Config)
+	if !ok {
+		return nil, fmt.Errorf("invalid configuration type for Cassandra provider: expected cassandra.Config, got %T", config)
+	}
+
+	return New(cassandraConfig)
+}
@@ -0,0 +1,333 @@
+package clickhouse
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	// Registers the "clickhouse" database/sql driver.
+	_ "github.com/ClickHouse/clickhouse-go/v2"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+const (
+	// defaultNGramSize is the default n-gram size when not specified in options.
+	defaultNGramSize = 3
+
+	// schemaTemplate creates the entries table. Each Index inserts a new version
+	// of the entry's row, which ReplacingMergeTree collapses in background merges;
+	// until then queries pick the latest version themselves. The ngram bloom
+	// filter lets LIKE patterns with at least three literal characters skip the
+	// granules that cannot contain them.
+	schemaTemplate = `
+CREATE TABLE IF NOT EXISTS %s (
+	key            String,
+	id             String,
+	text           String,
+	search_text    String,
+	display        String,
+	score          Float64 DEFAULT 1,
+	case_sensitive Bool DEFAULT false,
+	content_hash   String DEFAULT '',
+	version        UInt64,
+	INDEX search_text_ngram search_text TYPE ngrambf_v1(3, 65536, 2, 0) GRANULARITY 1
+)
+ENGINE = ReplacingMergeTree(version)
+ORDER BY (key, id)`
+
+	// insertTemplate starts a batch insert of entry rows.
+	insertTemplate = "INSERT INTO %s (key, id, text, search_text, display, score, case_sensitive, content_hash, version)"
+
+	// queryTemplate finds candidate IDs through the skip index, then keeps those
+	// whose latest version still matches, so that a replaced entry's previous
+	// text no longer matches even before its rows are merged.
+	queryTemplate = `
+SELECT id, argMax(display, version) AS latest_display, argMax(score, version) AS latest_score,
+	argMax(search_text, version) AS latest_text
+FROM %[1]s
+WHERE key = ? AND id IN (SELECT id FROM %[1]s WHERE key = ? AND %[2]s)
+GROUP BY id
+HAVING %[3]s
+ORDER BY latest_score DESC, position(latest_text, ?) ASC, lengthUTF8(latest_text) ASC, id ASC
+LIMIT ?`
+)
+
+// tableNamePattern restricts table names to plain, optionally database-qualified identifiers,
+// since they are interpolated into SQL.
+var tableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// likeEscaper escapes LIKE wildcards in user queries. Backslash is the LIKE escape character.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Provider implements the autocomplete Provider interface using ClickHouse.
+// Entries of all namespaces are rows of a single table, isolated by the key
+// column, and matched with LIKE patterns served by an ngram bloom-filter index.
+// All methods are safe for concurrent use.
+type Provider struct {
+	db    *sql.DB
+	table string
+
+	// lastVersion is the row version most recently assigned, which keeps
+	// versions increasing when the clock does not advance between writes.
+	lastVersion atomic.Uint64
+}
+
+// New creates a new ClickHouse provider with the given configuration.
+// It verifies connectivity and, unless SkipSchemaSetup is set, creates the table.
+func New(config Config) (*Provider, error) {
+	config.setDefaults()
+	if !tableNamePattern.MatchString(config.Table) {
+		return nil, fmt.Errorf("invalid table name %q", config.Table)
+	}
+
+	db, err := sql.Open("clickhouse", config.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ClickHouse connection: %w", err)
+	}
+
+	ctx := context.Background()
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to connect to ClickHouse: %w", err)
+	}
+
+	if !config.SkipSchemaSetup {
+		if _, err := db.ExecContext(ctx, Schema(config.Table)); err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("failed to create schema: %w", err)
+		}
+	}
+
+	return &Provider{db: db, table: config.Table}, nil
+}
+
+// Schema returns the SQL that creates the entries table, for use in migrations
+// when Config.SkipSchemaSetup is set. Migrations may tune the parameters of the
+// ngram bloom filter, e.g. a larger filter for long texts, but should keep its
+// n-gram size at most the shortest query worth accelerating.
+func Schema(table string) string {
+	return fmt.Sprintf(schemaTemplate, table)
+}
+
+// Index adds or updates an entry in the autocomplete index.
+func (p *Provider) Index(ctx context.Context, key, id, text, display string, options providers.IndexOptions) error {
+	entries := []providers.IndexEntry{{ID: id, Text: text, Display: display, Options: options}}
+	if err := p.insert(ctx, key, entries); err != nil {
+		return fmt.Errorf("failed to index entry: %w", err)
+	}
+	return nil
+}
+
+// IndexAtomic writes all entries in a single insert, which ClickHouse applies
+// as one block.
+func (p *Provider) IndexAtomic(ctx context.Context, key string, entries []providers.IndexEntry) error {
+	if err := p.insert(ctx, key, entries); err != nil {
+		return fmt.Errorf("failed to index entries: %w", err)
+	}
+	return nil
+}
+
+// insert writes a new version of each entry's row in one batch, storing its
+// text normalized for matching.
+func (p *Provider) insert(ctx context.Context, key string, entries []providers.IndexEntry) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin batch: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.PrepareContext(ctx, fmt.Sprintf(insertTemplate, p.table))
+	if err != nil {
+		return fmt.Errorf("failed to prepare batch: %w", err)
+	}
+	defer func() { _ = stmt.Close() }()
+
+	for _, entry := range entries {
+		options := entry.Options
+		searchText := providers.SearchText(entry.Text, options.CaseSensitive)
+		if _, err := stmt.ExecContext(ctx, key, entry.ID, entry.Text, searchText, entry.Display,
+			options.Score, options.CaseSensitive, options.ContentHash, p.nextVersion()); err != nil {
+			return fmt.Errorf("failed to add entry %q to batch: %w", entry.ID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// nextVersion returns the version of a new row: the current time in
+// nanoseconds, or one more than the previous version if that is not later.
+func (p *Provider) nextVersion() uint64 {
+	for {
+		last := p.lastVersion.Load()
+		version := max(uint64(time.Now().UnixNano()), last+1)
+		if p.lastVersion.CompareAndSwap(last, version) {
+			return version
+		}
+	}
+}
+
+// ContentHash returns the stored content hash for an entry and whether it exists.
+func (p *Provider) ContentHash(ctx context.Context, key, id string) (string, bool, error) {
+	var (
+		count uint64
+		hash  string
+	)
+	err := p.db.QueryRowContext(ctx,
+		fmt.Sprintf("SELECT count(), argMax(content_hash, version) FROM %s WHERE key = ? AND id = ?", p.table), key, id,
+	).Scan(&count, &hash)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get content hash: %w", err)
+	}
+	return hash, count > 0, nil
+}
+
+// ListEntries returns up to count entries of the namespace in ID order,
+// starting after the ID given as cursor.
+func (p *Provider) ListEntries(ctx context.Context, key, cursor string, count int) ([]providers.StoredEntry, string, error) {
+	rows, err := p.db.QueryContext(ctx, fmt.Sprintf(`
+SELECT id, argMax(text, version), argMax(display, version), argMax(content_hash, version) FROM %s
+WHERE key = ? AND id > ?
+GROUP BY id
+ORDER BY id
+LIMIT ?`, p.table), key, cursor, count)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []providers.StoredEntry
+	for rows.Next() {
+		var entry providers.StoredEntry
+		if err := rows.Scan(&entry.ID, &entry.Text, &entry.Display, &entry.ContentHash); err != nil {
+			return nil, "", fmt.Errorf("failed to read entries: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("failed to read entries: %w", err)
+	}
+
+	next := ""
+	if len(entries) == count {
+		next = entries[len(entries)-1].ID
+	}
+	return entries, next, nil
+}
+
+// Query searches for entries matching the given query.
+// Results are ranked by entry score, then match position, then text length.
+func (p *Provider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	searchQuery := providers.SearchText(query, options.CaseSensitive)
+
+	candidates, candidateArgs, ok := matchCondition("search_text", searchQuery, options)
+	if !ok {
+		return []providers.ProviderResult{}, nil
+	}
+	latest, latestArgs, _ := matchCondition("latest_text", searchQuery, options)
+
+	args := []interface{}{key, key}
+	args = append(args, candidateArgs...)
+	args = append(args, latestArgs...)
+	args = append(args, searchQuery, options.MaxResults)
+	statement := fmt.Sprintf(queryTemplate, p.table, candidates, latest)
+
+	rows, err := p.db.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query autocomplete: %w", err)
+	}
+	defer rows.Close()
+
+	results := []providers.ProviderResult{}
+	for rows.Next() {
+		var (
+			result     providers.ProviderResult
+			searchText string
+		)
+		if err := rows.Scan(&result.ID, &result.Display, &result.Score, &searchText); err != nil {
+			return nil, fmt.Errorf("failed to read query results: %w", err)
+		}
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read query results: %w", err)
+	}
+	return results, nil
+}
+
+// matchCondition returns the condition selecting rows whose column matches the
+// query under the given strategy, with its LIKE pattern arguments. It reports
+// false when no entry can match, e.g. a query shorter than the n-gram size
+// under MatchNOrMoreGram.
+func matchCondition(column, searchQuery string, options providers.QueryOptions) (string, []interface{}, bool) {
+	if searchQuery == "" {
+		return "", nil, false
+	}
+	escaped := likeEscaper.Replace(searchQuery)
+	n := options.NGramSize
+	if n <= 0 {
+		n = defaultNGramSize
+	}
+
+	switch options.MatchStrategy {
+	case providers.MatchPrefix:
+		return column + " LIKE ?", []interface{}{escaped + "%"}, true
+
+	case providers.MatchNGram:
+		if len(searchQuery) <= n {
+			// An n-gram starts with the query, so at least n-len(query) characters follow it
+			pattern := "%" + escaped + strings.Repeat("_", n-len(searchQuery)) + "%"
+			return column + " LIKE ?", []interface{}{pattern}, true
+		}
+		// Longer queries match entries containing every n-gram of the query
+		clauses := make([]string, 0, len(searchQuery)-n+1)
+		args := make([]interface{}, 0, len(searchQuery)-n+1)
+		for i := 0; i <= len(searchQuery)-n; i++ {
+			clauses = append(clauses, column+" LIKE ?")
+			args = append(args, "%"+likeEscaper.Replace(searchQuery[i:i+n])+"%")
+		}
+		return "(" + strings.Join(clauses, " AND ") + ")", args, true
+
+	case providers.MatchNOrMoreGram:
+		if len(searchQuery) < n {
+			return "", nil, false
+		}
+		return column + " LIKE ?", []interface{}{"%" + escaped + "%"}, true
+
+	default:
+		return column + " LIKE ?", []interface{}{"%" + escaped + "%"}, true
+	}
+}
+
+// SupportsMatchStrategies reports that entries can be queried under any combination
+// of strategies. Every strategy is a LIKE pattern over the same normalized text,
+// so nothing strategy-specific is stored.
+func (p *Provider) SupportsMatchStrategies(strategies []providers.MatchStrategy) bool {
+	return true
+}
+
+// Delete removes every version of an entry with a lightweight delete.
+func (p *Provider) Delete(ctx context.Context, key, id string) error {
+	_, err := p.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE key = ? AND id = ?", p.table), key, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete entry: %w", err)
+	}
+	return nil
+}
+
+// DeleteAll removes all entries for a given key.
+func (p *Provider) DeleteAll(ctx context.Context, key string) error {
+	_, err := p.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE key = ?", p.table), key)
+	if err != nil {
+		return fmt.Errorf("failed to delete entries: %w", err)
+	}
+	return nil
+}
+
+// Close closes the database connection pool.
+func (p *Provider) Close() error {
+	return p.db.Close()
+}
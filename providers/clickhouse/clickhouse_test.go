@@ -0,0 +1,228 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+const testKey = "test"
+
+// newTestProvider starts a ClickHouse container and returns a provider on it,
+// skipping the test when Docker is unavailable.
+func newTestProvider(t *testing.T) *Provider {
+	t.Helper()
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	ctx := context.Background()
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "clickhouse/clickhouse-server:24.8",
+			ExposedPorts: []string{"9000/tcp", "8123/tcp"},
+			Env: map[string]string{
+				"CLICKHOUSE_DB":       "autocomplete",
+				"CLICKHOUSE_USER":     "autocomplete",
+				"CLICKHOUSE_PASSWORD": "secret",
+			},
+			WaitingFor: wait.ForHTTP("/ping").WithPort("8123/tcp").WithStartupTimeout(time.Minute),
+		},
+		Started: true,
+	})
+	testcontainers.CleanupContainer(t, container)
+	if err != nil {
+		t.Fatalf("failed to start ClickHouse container: %v", err)
+	}
+	host, err := container.Host(ctx)
+	if err != nil {
+		t.Fatalf("failed to get container host: %v", err)
+	}
+	port, err := container.MappedPort(ctx, "9000")
+	if err != nil {
+		t.Fatalf("failed to get container port: %v", err)
+	}
+
+	provider, err := New(Config{DSN: fmt.Sprintf("clickhouse://autocomplete:secret@%s:%s/autocomplete", host, port.Port())})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = provider.Close() })
+	return provider
+}
+
+// queryIDs returns the IDs of the results of a query, in order.
+func queryIDs(t *testing.T, provider *Provider, key, query string, options providers.QueryOptions) []string {
+	t.Helper()
+	results, err := provider.Query(context.Background(), key, query, options)
+	if err != nil {
+		t.Fatalf("Query(%q) error = %v", query, err)
+	}
+	ids := make([]string, len(results))
+	for i, result := range results {
+		ids[i] = result.ID
+	}
+	return ids
+}
+
+func TestClickHouseProvider_RoundTrip(t *testing.T) {
+	provider := newTestProvider(t)
+	ctx := context.Background()
+
+	// With merges stopped, a reindexed entry keeps every version of its row,
+	// which queries must resolve to the latest themselves
+	if _, err := provider.db.ExecContext(ctx, "SYSTEM STOP MERGES "+provider.table); err != nil {
+		t.Fatalf("failed to stop merges: %v", err)
+	}
+
+	if err := provider.IndexAtomic(ctx, testKey, []providers.IndexEntry{
+		{ID: "1", Text: "Mumbai", Display: "Mumbai, MH", Options: providers.IndexOptions{Score: 1, ContentHash: "v1-1"}},
+		{ID: "2", Text: "Navi Mumbai", Display: "Navi Mumbai, MH", Options: providers.IndexOptions{Score: 1, ContentHash: "v1-2"}},
+		{ID: "3", Text: "Mumbra", Display: "Mumbra, MH", Options: providers.IndexOptions{Score: 1, ContentHash: "v1-3"}},
+	}); err != nil {
+		t.Fatalf("IndexAtomic() error = %v", err)
+	}
+	// The same ID in another namespace must not show up in testKey
+	if err := provider.Index(ctx, "other", "1", "Mumbai Other", "Mumbai Other", providers.IndexOptions{Score: 9}); err != nil {
+		t.Fatalf("Index() in another namespace error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		strategy providers.MatchStrategy
+		limit    int
+		want     []string
+	}{
+		{"prefix", "MUM", providers.MatchPrefix, 10, []string{"1", "3"}},
+		{"substring", "umba", providers.MatchSubstring, 10, []string{"1", "2"}},
+		{"ngram", "umba", providers.MatchNGram, 10, []string{"1", "2"}},
+		{"n-or-more", "mumb", providers.MatchNOrMoreGram, 10, []string{"1", "3", "2"}},
+		{"limit", "mumb", providers.MatchNOrMoreGram, 1, []string{"1"}},
+		{"wildcards are literal", "m%", providers.MatchSubstring, 10, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := providers.QueryOptions{MatchStrategy: tt.strategy, NGramSize: 3, MaxResults: tt.limit}
+			if got := queryIDs(t, provider, testKey, tt.query, options); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Query(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+
+	// Reindexing adds a version: the old text stops matching and the new one
+	// matches, with the new display and score
+	if err := provider.Index(ctx, testKey, "1", "Pune", "Pune, MH", providers.IndexOptions{Score: 2, ContentHash: "v2-1"}); err != nil {
+		t.Fatalf("Index() reindex error = %v", err)
+	}
+	var versions uint64
+	if err := provider.db.QueryRowContext(ctx,
+		"SELECT count() FROM "+provider.table+" WHERE key = ? AND id = ?", testKey, "1").Scan(&versions); err != nil || versions != 2 {
+		t.Fatalf("rows of entry 1 = %d, %v; want both versions unmerged", versions, err)
+	}
+	prefix := providers.QueryOptions{MatchStrategy: providers.MatchPrefix, MaxResults: 10}
+	if got := queryIDs(t, provider, testKey, "mum", prefix); !reflect.DeepEqual(got, []string{"3"}) {
+		t.Errorf("Query(mum) after reindex = %v, want [3]", got)
+	}
+	substring := providers.QueryOptions{MatchStrategy: providers.MatchSubstring, MaxResults: 10}
+	if got := queryIDs(t, provider, testKey, "mumbai", substring); !reflect.DeepEqual(got, []string{"2"}) {
+		t.Errorf("Query(mumbai) after reindex = %v, want [2]", got)
+	}
+	results, err := provider.Query(ctx, testKey, "pune", prefix)
+	if err != nil || len(results) != 1 || results[0].Display != "Pune, MH" || results[0].Score != 2 {
+		t.Errorf("Query(pune) after reindex = %v, %v; want the new version", results, err)
+	}
+	if hash, ok, err := provider.ContentHash(ctx, testKey, "1"); err != nil || !ok || hash != "v2-1" {
+		t.Errorf("ContentHash(1) = %q, %v, %v; want v2-1", hash, ok, err)
+	}
+	if _, ok, err := provider.ContentHash(ctx, testKey, "missing"); err != nil || ok {
+		t.Errorf("ContentHash(missing) = %v, %v; want not found", ok, err)
+	}
+	page, next, err := provider.ListEntries(ctx, testKey, "", 2)
+	if err != nil || len(page) != 2 || page[0].ID != "1" || page[0].Text != "Pune" || next != "2" {
+		t.Errorf("ListEntries() = %v, %q, %v; want entries 1 and 2 at their latest version", page, next, err)
+	}
+
+	// Lightweight deletes are mutations, which run with merges
+	if _, err := provider.db.ExecContext(ctx, "SYSTEM START MERGES "+provider.table); err != nil {
+		t.Fatalf("failed to start merges: %v", err)
+	}
+	if err := provider.Delete(ctx, testKey, "1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if got := queryIDs(t, provider, testKey, "pune", prefix); len(got) != 0 {
+		t.Errorf("Query(pune) after Delete = %v, want none", got)
+	}
+	if _, ok, err := provider.ContentHash(ctx, testKey, "1"); err != nil || ok {
+		t.Errorf("ContentHash(1) after Delete = %v, %v; want not found", ok, err)
+	}
+	if err := provider.DeleteAll(ctx, testKey); err != nil {
+		t.Fatalf("DeleteAll() error = %v", err)
+	}
+	if entries, _, err := provider.ListEntries(ctx, testKey, "", 10); err != nil || len(entries) != 0 {
+		t.Errorf("ListEntries() after DeleteAll = %v, %v; want none", entries, err)
+	}
+	if got := queryIDs(t, provider, "other", "mum", prefix); !reflect.DeepEqual(got, []string{"1"}) {
+		t.Errorf("Query(mum) in the other namespace after DeleteAll = %v, want [1]", got)
+	}
+}
+
+func TestMatchCondition(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		strategy  providers.MatchStrategy
+		condition string
+		args      []interface{}
+		ok        bool
+	}{
+		{"prefix", "mum", providers.MatchPrefix, "search_text LIKE ?", []interface{}{"mum%"}, true},
+		{"substring", "mum", providers.MatchSubstring, "search_text LIKE ?", []interface{}{"%mum%"}, true},
+		{"escaped wildcards", `5%_\`, providers.MatchSubstring, "search_text LIKE ?", []interface{}{`%5\%\_\\%`}, true},
+		{"short ngram", "mu", providers.MatchNGram, "search_text LIKE ?", []interface{}{"%mu_%"}, true},
+		{
+			"long ngram", "umba", providers.MatchNGram,
+			"(search_text LIKE ? AND search_text LIKE ?)", []interface{}{"%umb%", "%mba%"}, true,
+		},
+		{"n-or-more", "mumb", providers.MatchNOrMoreGram, "search_text LIKE ?", []interface{}{"%mumb%"}, true},
+		{"n-or-more too short", "mu", providers.MatchNOrMoreGram, "", nil, false},
+		{"empty", "", providers.MatchPrefix, "", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := providers.QueryOptions{MatchStrategy: tt.strategy, NGramSize: 3}
+			condition, args, ok := matchCondition("search_text", tt.query, options)
+			if condition != tt.condition || !reflect.DeepEqual(args, tt.args) || ok != tt.ok {
+				t.Errorf("matchCondition(%q) = %q, %v, %v; want %q, %v, %v",
+					tt.query, condition, args, ok, tt.condition, tt.args, tt.ok)
+			}
+		})
+	}
+}
+
+func TestNextVersionIncreases(t *testing.T) {
+	provider := &Provider{}
+	provider.lastVersion.Store(^uint64(0) - 10)
+
+	// Versions keep increasing even when the clock is behind the last version
+	previous := provider.nextVersion()
+	for i := 0; i < 5; i++ {
+		version := provider.nextVersion()
+		if version <= previous {
+			t.Fatalf("nextVersion() = %d after %d, want a larger version", version, previous)
+		}
+		previous = version
+	}
+}
+
+func TestNewRejectsInvalidTable(t *testing.T) {
+	if _, err := New(Config{DSN: "clickhouse://localhost:9000/db", Table: "entries; DROP TABLE users"}); err == nil {
+		t.Error("New() should reject an invalid table name")
+	}
+}
@@ -0,0 +1,29 @@
+package clickhouse
+
+import (
+	"fmt"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// init registers the ClickHouse provider. Import this package with a blank identifier
+// to use ClickHouse as the autocomplete backend:
+//
+//	import _ "github.com/remiges-tech/autocomplete/providers/clickhouse"
+//
+//nolint:gochecknoinits // init() is the idiomatic pattern for provider registration
+func init() {
+	autocomplete.RegisterProvider("clickhouse", NewProvider)
+}
+
+// NewProvider creates a new ClickHouse provider from the given configuration.
+// It implements ProviderFactory and expects config to be of type clickhouse.Config.
+func NewProvider(config interface{}) (providers.Provider, error) {
+	chConfig, ok := config.(Config)
+	if !ok {
+		return nil, fmt.Errorf("invalid configuration type for ClickHouse provider: expected clickhouse.Config, got %T", config)
+	}
+
+	return New(chConfig)
+}
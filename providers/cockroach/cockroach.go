@@ -0,0 +1,487 @@
+package cockroach
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	// Registers the "pgx" database/sql driver.
+	_ "github.com/jackc/pgx/v5/stdlib"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+const (
+	// defaultNGramSize is the default n-gram size when not specified in options.
+	defaultNGramSize = 3
+
+	// maxGramLength is the length in characters of the longest grams stored in the
+	// tokens table. Queries of at least this length are looked up by their grams of
+	// this length; shorter queries are themselves a stored gram.
+	maxGramLength = 3
+
+	// maxTxAttempts is the number of times a transaction is run before a
+	// serialization failure is returned to the caller.
+	maxTxAttempts = 5
+
+	// retryableErrorCode is the SQLSTATE CockroachDB reports for transactions
+	// that must be retried after a conflict with a concurrent transaction.
+	retryableErrorCode = "40001"
+
+	// schemaTemplate creates the entries table and the tokens table that serves as
+	// its inverted gram index. The prefix index serves MatchPrefix and stores the
+	// ranked columns so that prefix queries need no lookup in the primary index.
+	schemaTemplate = `
+CREATE TABLE IF NOT EXISTS %[1]s (
+	key            STRING NOT NULL,
+	id             STRING NOT NULL,
+	text           STRING NOT NULL,
+	search_text    STRING NOT NULL,
+	display        STRING NOT NULL,
+	score          FLOAT8 NOT NULL DEFAULT 1,
+	case_sensitive BOOL NOT NULL DEFAULT false,
+	content_hash   STRING NOT NULL DEFAULT '',
+	PRIMARY KEY (key, id),
+	INDEX %[2]s_prefix_idx (key, search_text) STORING (text, display, score)
+);
+
+CREATE TABLE IF NOT EXISTS %[1]s_tokens (
+	key   STRING NOT NULL,
+	token STRING NOT NULL,
+	id    STRING NOT NULL,
+	PRIMARY KEY (key, token, id),
+	INDEX %[2]s_tokens_id_idx (key, id)
+);
+`
+
+	// entryColumns lists the columns written for each entry, in argument order.
+	entryColumns = "key, id, text, search_text, display, score, case_sensitive, content_hash"
+
+	// tokenColumns lists the columns written for each token, in argument order.
+	tokenColumns = "key, token, id"
+)
+
+// tableNamePattern restricts table names to plain, optionally schema-qualified identifiers,
+// since they are interpolated into SQL.
+var tableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// likeEscaper escapes LIKE wildcards in user queries. Backslash is the default LIKE escape character.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Provider implements the autocomplete Provider interface using CockroachDB.
+// Entries of all namespaces are rows of a single table, isolated by the key column.
+// Prefix queries scan an index on the normalized text; other strategies first
+// select candidates from a tokens table holding every gram of up to three
+// characters of each entry, then check them with LIKE patterns.
+// All methods are safe for concurrent use.
+type Provider struct {
+	db            *sql.DB
+	table         string
+	batchSize     int
+	followerReads bool
+}
+
+// New creates a new CockroachDB provider with the given configuration.
+// It verifies connectivity and, unless SkipSchemaSetup is set, creates the schema.
+func New(config Config) (*Provider, error) {
+	config.setDefaults()
+	if !tableNamePattern.MatchString(config.Table) {
+		return nil, fmt.Errorf("invalid table name %q", config.Table)
+	}
+
+	db, err := sql.Open("pgx", config.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open CockroachDB connection: %w", err)
+	}
+
+	ctx := context.Background()
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to connect to CockroachDB: %w", err)
+	}
+
+	if !config.SkipSchemaSetup {
+		if _, err := db.ExecContext(ctx, Schema(config.Table)); err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("failed to create schema: %w", err)
+		}
+	}
+
+	return &Provider{
+		db:            db,
+		table:         config.Table,
+		batchSize:     config.BatchSize,
+		followerReads: config.FollowerReads,
+	}, nil
+}
+
+// Schema returns the SQL that creates the entries and tokens tables and their
+// indexes, for use in migrations when Config.SkipSchemaSetup is set. Multi-region
+// clusters can add locality settings, e.g. making both tables GLOBAL, in the migration.
+func Schema(table string) string {
+	indexPrefix := strings.ReplaceAll(table, ".", "_")
+	return fmt.Sprintf(schemaTemplate, table, indexPrefix)
+}
+
+// Index adds or updates an entry in the autocomplete index.
+func (p *Provider) Index(ctx context.Context, key, id, text, display string, options providers.IndexOptions) error {
+	entry := providers.IndexEntry{ID: id, Text: text, Display: display, Options: options}
+	if err := p.write(ctx, key, []providers.IndexEntry{entry}); err != nil {
+		return fmt.Errorf("failed to index entry: %w", err)
+	}
+	return nil
+}
+
+// IndexAtomic writes all entries inside a single transaction, with batched UPSERTs.
+func (p *Provider) IndexAtomic(ctx context.Context, key string, entries []providers.IndexEntry) error {
+	if err := p.write(ctx, key, entries); err != nil {
+		return fmt.Errorf("failed to index entries: %w", err)
+	}
+	return nil
+}
+
+// write upserts the entries and replaces their tokens in one transaction.
+// When an ID appears more than once, the last entry wins.
+func (p *Provider) write(ctx context.Context, key string, entries []providers.IndexEntry) error {
+	latest := make(map[string]int, len(entries))
+	for i, entry := range entries {
+		latest[entry.ID] = i
+	}
+
+	var entryRows, tokenRows [][]interface{}
+	ids := make([]string, 0, len(latest))
+	for i, entry := range entries {
+		if latest[entry.ID] != i {
+			continue
+		}
+		ids = append(ids, entry.ID)
+
+		searchText := providers.SearchText(entry.Text, entry.Options.CaseSensitive)
+		entryRows = append(entryRows, []interface{}{
+			key, entry.ID, entry.Text, searchText, entry.Display,
+			entry.Options.Score, entry.Options.CaseSensitive, entry.Options.ContentHash,
+		})
+		if needsTokens(entry.Options) {
+			for _, gram := range entryGrams(searchText) {
+				tokenRows = append(tokenRows, []interface{}{key, gram, entry.ID})
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return p.runTx(ctx, func(tx *sql.Tx) error {
+		if err := p.upsertRows(ctx, tx, p.table, entryColumns, entryRows); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx,
+			fmt.Sprintf("DELETE FROM %s_tokens WHERE key = $1 AND id = ANY($2)", p.table), key, ids)
+		if err != nil {
+			return err
+		}
+		return p.upsertRows(ctx, tx, p.table+"_tokens", tokenColumns, tokenRows)
+	})
+}
+
+// needsTokens reports whether an entry is queried through the tokens table.
+// Entries indexed only for MatchPrefix are served by the prefix index alone.
+func needsTokens(options providers.IndexOptions) bool {
+	if len(options.MatchStrategies) > 0 {
+		for _, strategy := range options.MatchStrategies {
+			if strategy != providers.MatchPrefix {
+				return true
+			}
+		}
+		return false
+	}
+	return options.MatchStrategy != providers.MatchPrefix
+}
+
+// upsertRows writes rows into table with multi-row UPSERT statements of at most
+// batchSize rows each.
+func (p *Provider) upsertRows(ctx context.Context, tx *sql.Tx, table, columns string, rows [][]interface{}) error {
+	for start := 0; start < len(rows); start += p.batchSize {
+		batch := rows[start:min(start+p.batchSize, len(rows))]
+		args := make([]interface{}, 0, len(batch)*len(batch[0]))
+		for _, row := range batch {
+			args = append(args, row...)
+		}
+		statement := fmt.Sprintf("UPSERT INTO %s (%s) VALUES %s", table, columns, valuesList(len(batch), len(batch[0])))
+		if _, err := tx.ExecContext(ctx, statement, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// valuesList returns the placeholders of a multi-row VALUES clause, numbered from $1.
+func valuesList(rows, columns int) string {
+	var b strings.Builder
+	for row := 0; row < rows; row++ {
+		if row > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteByte('(')
+		for column := 0; column < columns; column++ {
+			if column > 0 {
+				b.WriteString(", ")
+			}
+			fmt.Fprintf(&b, "$%d", row*columns+column+1)
+		}
+		b.WriteByte(')')
+	}
+	return b.String()
+}
+
+// runTx runs fn in a transaction, retrying it when CockroachDB aborts the
+// transaction because of a conflict with a concurrent one.
+func (p *Provider) runTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	for attempt := 1; ; attempt++ {
+		tx, err := p.db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		if err = fn(tx); err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+		if err == nil || !isRetryable(err) || attempt == maxTxAttempts {
+			return err
+		}
+	}
+}
+
+// isRetryable reports whether err is a transaction retry error.
+func isRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == retryableErrorCode
+}
+
+// entryGrams returns the distinct grams of one to maxGramLength characters of
+// the normalized text.
+func entryGrams(searchText string) []string {
+	runes := []rune(searchText)
+	seen := make(map[string]bool)
+	var grams []string
+	for i := range runes {
+		for n := 1; n <= maxGramLength && i+n <= len(runes); n++ {
+			gram := string(runes[i : i+n])
+			if !seen[gram] {
+				seen[gram] = true
+				grams = append(grams, gram)
+			}
+		}
+	}
+	return grams
+}
+
+// queryGrams returns the grams every entry containing the normalized query holds:
+// the query itself when shorter than maxGramLength, otherwise its distinct grams
+// of maxGramLength characters.
+func queryGrams(searchQuery string) []string {
+	runes := []rune(searchQuery)
+	if len(runes) < maxGramLength {
+		return []string{searchQuery}
+	}
+	seen := make(map[string]bool)
+	var grams []string
+	for i := 0; i+maxGramLength <= len(runes); i++ {
+		gram := string(runes[i : i+maxGramLength])
+		if !seen[gram] {
+			seen[gram] = true
+			grams = append(grams, gram)
+		}
+	}
+	return grams
+}
+
+// ContentHash returns the stored content hash for an entry and whether it exists.
+func (p *Provider) ContentHash(ctx context.Context, key, id string) (string, bool, error) {
+	var hash string
+	err := p.db.QueryRowContext(ctx,
+		fmt.Sprintf("SELECT content_hash FROM %s WHERE key = $1 AND id = $2", p.table), key, id,
+	).Scan(&hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get content hash: %w", err)
+	}
+	return hash, true, nil
+}
+
+// ListEntries returns up to count entries of the namespace in ID order,
+// starting after the ID given as cursor.
+func (p *Provider) ListEntries(ctx context.Context, key, cursor string, count int) ([]providers.StoredEntry, string, error) {
+	rows, err := p.db.QueryContext(ctx, fmt.Sprintf(
+		"SELECT id, text, display, content_hash FROM %s WHERE key = $1 AND id > $2 ORDER BY id LIMIT $3", p.table),
+		key, cursor, count)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []providers.StoredEntry
+	for rows.Next() {
+		var entry providers.StoredEntry
+		if err := rows.Scan(&entry.ID, &entry.Text, &entry.Display, &entry.ContentHash); err != nil {
+			return nil, "", fmt.Errorf("failed to read entries: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("failed to read entries: %w", err)
+	}
+
+	next := ""
+	if len(entries) == count {
+		next = entries[len(entries)-1].ID
+	}
+	return entries, next, nil
+}
+
+// Query searches for entries matching the given query.
+// Results are ranked by entry score, then match position, then text length.
+func (p *Provider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	searchQuery := providers.SearchText(query, options.CaseSensitive)
+
+	condition, args, ok := matchCondition(searchQuery, options, 3)
+	if !ok {
+		return []providers.ProviderResult{}, nil
+	}
+
+	// $1 is the namespace, $2 the query used for ranking by match position
+	args = append([]interface{}{key, searchQuery}, args...)
+	from := p.table + " AS e"
+	if options.MatchStrategy != providers.MatchPrefix {
+		grams := queryGrams(searchQuery)
+		args = append(args, grams, len(grams))
+		from += fmt.Sprintf(`
+JOIN (
+	SELECT id FROM %s_tokens WHERE key = $1 AND token = ANY($%d)
+	GROUP BY id HAVING count(*) = $%d
+) AS m ON m.id = e.id`, p.table, len(args)-1, len(args))
+	}
+	if p.followerReads {
+		from += "\nAS OF SYSTEM TIME follower_read_timestamp()"
+	}
+	args = append(args, options.MaxResults)
+	statement := fmt.Sprintf(`
+SELECT e.id, e.display, e.score FROM %s
+WHERE e.key = $1 AND %s
+ORDER BY e.score DESC, strpos(e.search_text, $2) ASC, length(e.text) ASC, e.id ASC
+LIMIT $%d`, from, condition, len(args))
+
+	rows, err := p.db.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query autocomplete: %w", err)
+	}
+	defer rows.Close()
+
+	results := []providers.ProviderResult{}
+	for rows.Next() {
+		var result providers.ProviderResult
+		if err := rows.Scan(&result.ID, &result.Display, &result.Score); err != nil {
+			return nil, fmt.Errorf("failed to read query results: %w", err)
+		}
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read query results: %w", err)
+	}
+	return results, nil
+}
+
+// matchCondition returns the WHERE condition checking that a candidate entry matches
+// the query under the given strategy, with its LIKE pattern arguments numbered from
+// firstArg. It reports false when no entry can match, e.g. a query shorter than the
+// n-gram size under MatchNOrMoreGram.
+func matchCondition(searchQuery string, options providers.QueryOptions, firstArg int) (string, []interface{}, bool) {
+	if searchQuery == "" {
+		return "", nil, false
+	}
+	escaped := likeEscaper.Replace(searchQuery)
+	n := options.NGramSize
+	if n <= 0 {
+		n = defaultNGramSize
+	}
+	length := len([]rune(searchQuery))
+
+	switch options.MatchStrategy {
+	case providers.MatchPrefix:
+		return fmt.Sprintf("e.search_text LIKE $%d", firstArg), []interface{}{escaped + "%"}, true
+
+	case providers.MatchNGram:
+		if length <= n {
+			// An n-gram starts with the query, so at least n-len(query) characters follow it
+			pattern := "%" + escaped + strings.Repeat("_", n-length) + "%"
+			return fmt.Sprintf("e.search_text LIKE $%d", firstArg), []interface{}{pattern}, true
+		}
+		// Longer queries match entries containing every n-gram of the query
+		runes := []rune(searchQuery)
+		clauses := make([]string, 0, length-n+1)
+		args := make([]interface{}, 0, length-n+1)
+		for i := 0; i <= length-n; i++ {
+			clauses = append(clauses, fmt.Sprintf("e.search_text LIKE $%d", firstArg+i))
+			args = append(args, "%"+likeEscaper.Replace(string(runes[i:i+n]))+"%")
+		}
+		return "(" + strings.Join(clauses, " AND ") + ")", args, true
+
+	case providers.MatchNOrMoreGram:
+		if length < n {
+			return "", nil, false
+		}
+		return fmt.Sprintf("e.search_text LIKE $%d", firstArg), []interface{}{"%" + escaped + "%"}, true
+
+	default:
+		return fmt.Sprintf("e.search_text LIKE $%d", firstArg), []interface{}{"%" + escaped + "%"}, true
+	}
+}
+
+// SupportsMatchStrategies reports that entries can be queried under any combination
+// of strategies. The tokens table serves every strategy other than MatchPrefix, and
+// each strategy checks candidates with LIKE patterns over the same normalized text.
+func (p *Provider) SupportsMatchStrategies(strategies []providers.MatchStrategy) bool {
+	return true
+}
+
+// Delete removes an entry and its tokens from the index.
+func (p *Provider) Delete(ctx context.Context, key, id string) error {
+	err := p.runTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE key = $1 AND id = $2", p.table), key, id); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s_tokens WHERE key = $1 AND id = $2", p.table), key, id)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete entry: %w", err)
+	}
+	return nil
+}
+
+// DeleteAll removes all entries and tokens for a given key.
+func (p *Provider) DeleteAll(ctx context.Context, key string) error {
+	err := p.runTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE key = $1", p.table), key); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s_tokens WHERE key = $1", p.table), key)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete entries: %w", err)
+	}
+	return nil
+}
+
+// Close closes the database connection pool.
+func (p *Provider) Close() error {
+	return p.db.Close()
+}
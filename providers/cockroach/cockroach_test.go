@@ -0,0 +1,374 @@
+package cockroach
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+const testKey = "test"
+
+// allStrategies indexes test entries for every strategy, so that they are
+// written to the tokens table.
+var allStrategies = []providers.MatchStrategy{
+	providers.MatchPrefix, providers.MatchSubstring, providers.MatchNGram, providers.MatchNOrMoreGram,
+}
+
+// startCockroach starts a single-node CockroachDB container and returns its DSN,
+// skipping the test when Docker is unavailable.
+func startCockroach(t *testing.T) string {
+	t.Helper()
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	ctx := context.Background()
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "cockroachdb/cockroach:latest-v24.3",
+			Cmd:          []string{"start-single-node", "--insecure"},
+			ExposedPorts: []string{"26257/tcp", "8080/tcp"},
+			WaitingFor: wait.ForHTTP("/health?ready=1").WithPort("8080/tcp").
+				WithStartupTimeout(2 * time.Minute),
+		},
+		Started: true,
+	})
+	testcontainers.CleanupContainer(t, container)
+	if err != nil {
+		t.Fatalf("failed to start CockroachDB container: %v", err)
+	}
+	host, err := container.Host(ctx)
+	if err != nil {
+		t.Fatalf("failed to get container host: %v", err)
+	}
+	port, err := container.MappedPort(ctx, "26257")
+	if err != nil {
+		t.Fatalf("failed to get container port: %v", err)
+	}
+	return fmt.Sprintf("postgresql://root@%s:%s/defaultdb?sslmode=disable", host, port.Port())
+}
+
+// newTestProvider returns a provider with the given configuration, closed when the test ends.
+func newTestProvider(t *testing.T, config Config) *Provider {
+	t.Helper()
+	provider, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = provider.Close() })
+	return provider
+}
+
+// queryIDs returns the IDs of the results of a query, failing the test on error.
+func queryIDs(t *testing.T, provider *Provider, key, query string, options providers.QueryOptions) []string {
+	t.Helper()
+	ids, err := resultIDs(provider, key, query, options)
+	if err != nil {
+		t.Fatalf("Query(%q) error = %v", query, err)
+	}
+	return ids
+}
+
+// resultIDs returns the IDs of the results of a query.
+func resultIDs(provider *Provider, key, query string, options providers.QueryOptions) ([]string, error) {
+	results, err := provider.Query(context.Background(), key, query, options)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(results))
+	for i, result := range results {
+		ids[i] = result.ID
+	}
+	return ids, nil
+}
+
+// tokenCount returns the number of grams stored for an entry.
+func tokenCount(t *testing.T, provider *Provider, key, id string) int {
+	t.Helper()
+	var count int
+	err := provider.db.QueryRowContext(context.Background(),
+		fmt.Sprintf("SELECT count(*) FROM %s_tokens WHERE key = $1 AND id = $2", provider.table), key, id).Scan(&count)
+	if err != nil {
+		t.Fatalf("failed to count tokens of %s: %v", id, err)
+	}
+	return count
+}
+
+func TestCockroachProvider_RoundTrip(t *testing.T) {
+	// A batch size of two splits the writes below into several UPSERT statements
+	provider := newTestProvider(t, Config{DSN: startCockroach(t), BatchSize: 2})
+	ctx := context.Background()
+
+	options := providers.IndexOptions{Score: 1, MatchStrategies: allStrategies}
+	if err := provider.IndexAtomic(ctx, testKey, []providers.IndexEntry{
+		{ID: "1", Text: "Bombay", Display: "Bombay", Options: options},
+		{ID: "2", Text: "Navi Mumbai", Display: "Navi Mumbai, MH", Options: options},
+		{ID: "3", Text: "Mumbra", Display: "Mumbra, MH", Options: options},
+		// The last entry of an ID wins
+		{ID: "1", Text: "Mumbai", Display: "Mumbai, MH", Options: providers.IndexOptions{
+			Score: 1, MatchStrategies: allStrategies, ContentHash: "v1-1",
+		}},
+		// Entries indexed only for MatchPrefix have no tokens
+		{ID: "4", Text: "Thane", Display: "Thane, MH", Options: providers.IndexOptions{
+			Score: 1, MatchStrategies: []providers.MatchStrategy{providers.MatchPrefix},
+		}},
+	}); err != nil {
+		t.Fatalf("IndexAtomic() error = %v", err)
+	}
+	// The same ID in another namespace must not show up in testKey
+	if err := provider.Index(ctx, "other", "1", "Mumbai Other", "Mumbai Other", options); err != nil {
+		t.Fatalf("Index() in another namespace error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		strategy providers.MatchStrategy
+		limit    int
+		want     []string
+	}{
+		{"prefix", "MUM", providers.MatchPrefix, 10, []string{"1", "3"}},
+		{"prefix without tokens", "tha", providers.MatchPrefix, 10, []string{"4"}},
+		{"substring", "umba", providers.MatchSubstring, 10, []string{"1", "2"}},
+		{"short substring", "mu", providers.MatchSubstring, 10, []string{"1", "3", "2"}},
+		{"substring without tokens", "han", providers.MatchSubstring, 10, []string{}},
+		{"ngram", "umba", providers.MatchNGram, 10, []string{"1", "2"}},
+		{"short ngram", "mb", providers.MatchNGram, 10, []string{"1", "3", "2"}},
+		{"n-or-more", "mumb", providers.MatchNOrMoreGram, 10, []string{"1", "3", "2"}},
+		{"n-or-more too short", "mu", providers.MatchNOrMoreGram, 10, []string{}},
+		{"limit", "mumb", providers.MatchNOrMoreGram, 1, []string{"1"}},
+		{"wildcards are literal", "m%", providers.MatchSubstring, 10, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := providers.QueryOptions{MatchStrategy: tt.strategy, NGramSize: 3, MaxResults: tt.limit}
+			if got := queryIDs(t, provider, testKey, tt.query, options); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Query(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+	if hash, ok, err := provider.ContentHash(ctx, testKey, "1"); err != nil || !ok || hash != "v1-1" {
+		t.Errorf("ContentHash(1) = %q, %v, %v; want v1-1", hash, ok, err)
+	}
+
+	// Reindexing an ID replaces its tokens
+	if err := provider.Index(ctx, testKey, "1", "Pune", "Pune, MH", options); err != nil {
+		t.Fatalf("Index() overwrite error = %v", err)
+	}
+	substring := providers.QueryOptions{MatchStrategy: providers.MatchSubstring, MaxResults: 10}
+	if got := queryIDs(t, provider, testKey, "mbai", substring); !reflect.DeepEqual(got, []string{"2"}) {
+		t.Errorf("Query(mbai) after overwrite = %v, want [2]", got)
+	}
+	if got := queryIDs(t, provider, testKey, "une", substring); !reflect.DeepEqual(got, []string{"1"}) {
+		t.Errorf("Query(une) after overwrite = %v, want [1]", got)
+	}
+
+	// Delete removes the entry and its tokens, only in its namespace
+	if err := provider.Delete(ctx, testKey, "1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if got := queryIDs(t, provider, testKey, "une", substring); len(got) != 0 {
+		t.Errorf("Query() after Delete = %v, want none", got)
+	}
+	if n := tokenCount(t, provider, testKey, "1"); n != 0 {
+		t.Errorf("Delete() left %d tokens", n)
+	}
+	if got := queryIDs(t, provider, "other", "mumbai", substring); !reflect.DeepEqual(got, []string{"1"}) {
+		t.Errorf("Query() in the other namespace = %v, want [1]", got)
+	}
+
+	// DeleteAll removes the namespace and its tokens
+	if err := provider.DeleteAll(ctx, testKey); err != nil {
+		t.Fatalf("DeleteAll() error = %v", err)
+	}
+	if entries, _, err := provider.ListEntries(ctx, testKey, "", 10); err != nil || len(entries) != 0 {
+		t.Errorf("ListEntries() after DeleteAll = %v, %v; want none", entries, err)
+	}
+	if n := tokenCount(t, provider, testKey, "2"); n != 0 {
+		t.Errorf("DeleteAll() left %d tokens", n)
+	}
+	if got := queryIDs(t, provider, "other", "mumbai", substring); !reflect.DeepEqual(got, []string{"1"}) {
+		t.Errorf("Query() in the other namespace after DeleteAll = %v, want [1]", got)
+	}
+}
+
+func TestCockroachProvider_ConcurrentWrites(t *testing.T) {
+	provider := newTestProvider(t, Config{DSN: startCockroach(t), BatchSize: 2})
+	ctx := context.Background()
+	options := providers.IndexOptions{Score: 1, MatchStrategies: allStrategies}
+
+	// Concurrent writes of one ID conflict; runTx retries them, and the tokens
+	// must end up matching whichever text was written last
+	texts := []string{"Agra", "Bhopal", "Cochin", "Dehradun"}
+	var wg sync.WaitGroup
+	errs := make([]error, len(texts))
+	for i, text := range texts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = provider.Index(ctx, testKey, "1", text, text, options)
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("Index(%s) error = %v", texts[i], err)
+		}
+	}
+
+	entries, _, err := provider.ListEntries(ctx, testKey, "", 10)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("ListEntries() = %v, %v; want one entry", entries, err)
+	}
+	final := entries[0].Text
+	substring := providers.QueryOptions{MatchStrategy: providers.MatchSubstring, MaxResults: 10}
+	for _, text := range texts {
+		want := []string{}
+		if text == final {
+			want = []string{"1"}
+		}
+		if got := queryIDs(t, provider, testKey, text, substring); !reflect.DeepEqual(got, want) {
+			t.Errorf("Query(%s) with %s stored = %v, want %v", text, final, got, want)
+		}
+	}
+}
+
+func TestCockroachProvider_FollowerReads(t *testing.T) {
+	dsn := startCockroach(t)
+	writer := newTestProvider(t, Config{DSN: dsn})
+	reader := newTestProvider(t, Config{DSN: dsn, SkipSchemaSetup: true, FollowerReads: true})
+	ctx := context.Background()
+
+	options := providers.IndexOptions{Score: 1, MatchStrategies: allStrategies}
+	for _, entry := range []providers.IndexEntry{
+		{ID: "1", Text: "Mumbai", Display: "Mumbai"},
+		{ID: "2", Text: "Navi Mumbai", Display: "Navi Mumbai"},
+	} {
+		if err := writer.Index(ctx, testKey, entry.ID, entry.Text, entry.Display, options); err != nil {
+			t.Fatalf("Index(%s) error = %v", entry.ID, err)
+		}
+	}
+
+	// Follower reads see the data as of a few seconds ago, so poll until the writes
+	// are visible. Until then the tables may not exist yet at the read timestamp.
+	tests := []struct {
+		name     string
+		query    string
+		strategy providers.MatchStrategy
+		want     []string
+	}{
+		{"prefix", "mum", providers.MatchPrefix, []string{"1"}},
+		{"substring", "umba", providers.MatchSubstring, []string{"1", "2"}},
+		{"ngram", "umba", providers.MatchNGram, []string{"1", "2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := providers.QueryOptions{MatchStrategy: tt.strategy, NGramSize: 3, MaxResults: 10}
+			deadline := time.Now().Add(30 * time.Second)
+			for {
+				got, err := resultIDs(reader, testKey, tt.query, options)
+				if err == nil && reflect.DeepEqual(got, tt.want) {
+					return
+				}
+				if time.Now().After(deadline) {
+					t.Fatalf("Query(%q) with follower reads = %v, %v; want %v", tt.query, got, err, tt.want)
+				}
+				time.Sleep(500 * time.Millisecond)
+			}
+		})
+	}
+}
+
+func TestMatchCondition(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		strategy  providers.MatchStrategy
+		condition string
+		args      []interface{}
+		ok        bool
+	}{
+		{"prefix", "mum", providers.MatchPrefix, "e.search_text LIKE $3", []interface{}{"mum%"}, true},
+		{"substring", "mum", providers.MatchSubstring, "e.search_text LIKE $3", []interface{}{"%mum%"}, true},
+		{"escaped wildcards", "5%_", providers.MatchSubstring, "e.search_text LIKE $3", []interface{}{`%5\%\_%`}, true},
+		{"short ngram", "mu", providers.MatchNGram, "e.search_text LIKE $3", []interface{}{"%mu_%"}, true},
+		{"short multibyte ngram", "मुं", providers.MatchNGram, "e.search_text LIKE $3", []interface{}{"%मुं%"}, true},
+		{
+			"long ngram", "umba", providers.MatchNGram,
+			"(e.search_text LIKE $3 AND e.search_text LIKE $4)", []interface{}{"%umb%", "%mba%"}, true,
+		},
+		{"n-or-more", "mumb", providers.MatchNOrMoreGram, "e.search_text LIKE $3", []interface{}{"%mumb%"}, true},
+		{"n-or-more too short", "mu", providers.MatchNOrMoreGram, "", nil, false},
+		{"empty", "", providers.MatchPrefix, "", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := providers.QueryOptions{MatchStrategy: tt.strategy, NGramSize: 3}
+			condition, args, ok := matchCondition(tt.query, options, 3)
+			if condition != tt.condition || !reflect.DeepEqual(args, tt.args) || ok != tt.ok {
+				t.Errorf("matchCondition(%q) = %q, %v, %v; want %q, %v, %v",
+					tt.query, condition, args, ok, tt.condition, tt.args, tt.ok)
+			}
+		})
+	}
+}
+
+func TestGrams(t *testing.T) {
+	if got, want := entryGrams("abab"), []string{"a", "ab", "aba", "b", "ba", "bab"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("entryGrams(abab) = %v, want %v", got, want)
+	}
+	if got, want := queryGrams("mu"), []string{"mu"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("queryGrams(mu) = %v, want %v", got, want)
+	}
+	if got, want := queryGrams("ababa"), []string{"aba", "bab"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("queryGrams(ababa) = %v, want %v", got, want)
+	}
+	// Every gram of a query is a gram of the entries containing it
+	stored := make(map[string]bool)
+	for _, gram := range entryGrams("navi mumbai") {
+		stored[gram] = true
+	}
+	for _, query := range []string{"m", "mu", "mumbai", "i m"} {
+		for _, gram := range queryGrams(query) {
+			if !stored[gram] {
+				t.Errorf("gram %q of query %q is not stored for the entry", gram, query)
+			}
+		}
+	}
+}
+
+func TestNeedsTokens(t *testing.T) {
+	tests := []struct {
+		options providers.IndexOptions
+		want    bool
+	}{
+		{providers.IndexOptions{MatchStrategy: providers.MatchPrefix}, false},
+		{providers.IndexOptions{MatchStrategy: providers.MatchNGram}, true},
+		{providers.IndexOptions{MatchStrategies: []providers.MatchStrategy{providers.MatchPrefix}}, false},
+		{providers.IndexOptions{MatchStrategies: []providers.MatchStrategy{providers.MatchPrefix, providers.MatchSubstring}}, true},
+	}
+	for _, tt := range tests {
+		if got := needsTokens(tt.options); got != tt.want {
+			t.Errorf("needsTokens(%+v) = %v, want %v", tt.options, got, tt.want)
+		}
+	}
+}
+
+func TestValuesList(t *testing.T) {
+	if got, want := valuesList(2, 3), "($1, $2, $3), ($4, $5, $6)"; got != want {
+		t.Errorf("valuesList(2, 3) = %q, want %q", got, want)
+	}
+}
+
+func TestNewRejectsInvalidTable(t *testing.T) {
+	if _, err := New(Config{DSN: "postgresql://localhost:26257/db", Table: "entries; DROP TABLE users"}); err == nil {
+		t.Error("New() should reject an invalid table name")
+	}
+}
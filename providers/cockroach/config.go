@@ -0,0 +1,48 @@
+// Package cockroach implements the autocomplete Provider interface using CockroachDB,
+// with an inverted table of character grams in place of PostgreSQL's pg_trgm indexes.
+package cockroach
+
+const (
+	// defaultTable is the table used when Config.Table is empty.
+	defaultTable = "autocomplete_entries"
+
+	// defaultBatchSize is the number of rows per UPSERT when Config.BatchSize is zero.
+	defaultBatchSize = 500
+)
+
+// Config holds CockroachDB connection parameters and provider-specific options.
+type Config struct {
+	// DSN is the connection string, either as a URL
+	// ("postgresql://user@localhost:26257/db?sslmode=verify-full") or in key=value form.
+	DSN string
+
+	// Table is the name of the table holding autocomplete entries. The gram index
+	// is kept in a second table named after it with a "_tokens" suffix. All
+	// namespaces share the tables and are isolated by their key column.
+	// Default: "autocomplete_entries"
+	Table string
+
+	// SkipSchemaSetup disables creating the tables and indexes on startup. Set it
+	// when the database user lacks the privileges to do so, or when the tables are
+	// created by a migration with multi-region locality settings (see Schema).
+	SkipSchemaSetup bool
+
+	// BatchSize is the maximum number of rows written by a single UPSERT statement.
+	// Default: 500
+	BatchSize int
+
+	// FollowerReads serves queries AS OF SYSTEM TIME follower_read_timestamp(),
+	// so any replica, typically the one in the nearest region, can answer them
+	// without contacting the leaseholder. Results then lag writes by a few seconds.
+	FollowerReads bool
+}
+
+// setDefaults applies default values to config fields.
+func (c *Config) setDefaults() {
+	if c.Table == "" {
+		c.Table = defaultTable
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = defaultBatchSize
+	}
+}
@@ -0,0 +1,29 @@
+package cockroach
+
+import (
+	"fmt"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// init registers the CockroachDB provider. Import this package with a blank identifier
+// to use CockroachDB as the autocomplete backend:
+//
+//	import _ "github.com/remiges-tech/autocomplete/providers/cockroach"
+//
+//nolint:gochecknoinits // init() is the idiomatic pattern for provider registration
+func init() {
+	autocomplete.RegisterProvider("cockroach", NewProvider)
+}
+
+// NewProvider creates a new CockroachDB provider from the given configuration.
+// It implements ProviderFactory and expects config to be of type cockroach.Config.
+func NewProvider(config interface{}) (providers.Provider, error) {
+	crdbConfig, ok := config.(Config)
+	if !ok {
+		return nil, fmt.Errorf("invalid configuration type for CockroachDB provider: expected cockroach.Config, got %T", config)
+	}
+
+	return New(crdbConfig)
+}
@@ -0,0 +1,39 @@
+// Package dynamodb implements the autocomplete Provider interface using Amazon DynamoDB,
+// for serverless deployments on AWS that cannot easily run Redis.
+// It supports the MatchPrefix and MatchNGram strategies.
+package dynamodb
+
+const (
+	// defaultTable is the table used when Config.Table is empty.
+	defaultTable = "autocomplete"
+)
+
+// Config holds DynamoDB connection parameters and provider-specific options.
+// Credentials are resolved by the AWS SDK's default chain (environment,
+// shared config files, or the execution role of a Lambda function).
+type Config struct {
+	// Table is the DynamoDB table holding autocomplete items. It has a string
+	// partition key "pk" and a string sort key "sk"; each namespace is one partition.
+	// Default: "autocomplete"
+	Table string
+
+	// Region is the AWS region of the table.
+	// Empty (default) uses the region from the SDK's default configuration.
+	Region string
+
+	// Endpoint overrides the DynamoDB endpoint, e.g. "http://localhost:8000"
+	// for DynamoDB Local.
+	Endpoint string
+
+	// CreateTable creates the table with on-demand billing if it does not exist.
+	// Tables managed by infrastructure-as-code should leave this disabled.
+	// Default: false
+	CreateTable bool
+}
+
+// setDefaults applies default values to config fields.
+func (c *Config) setDefaults() {
+	if c.Table == "" {
+		c.Table = defaultTable
+	}
+}
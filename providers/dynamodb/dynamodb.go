@@ -0,0 +1,546 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	ddb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+const (
+	// defaultNGramSize is the default n-gram size when not specified in options.
+	defaultNGramSize = 3
+
+	// maxBatchWrite is the most write requests DynamoDB accepts in one BatchWriteItem call.
+	maxBatchWrite = 25
+
+	// maxBatchAttempts bounds the retries of unprocessed items, which DynamoDB
+	// returns when a batch exceeds the table's provisioned or burst throughput.
+	maxBatchAttempts = 8
+
+	// initialBackoff is the pause before the first retry of unprocessed items; it doubles per attempt.
+	initialBackoff = 50 * time.Millisecond
+
+	// maxPrefixTokenBytes caps the prefix token stored for MatchPrefix, keeping
+	// sort keys well under DynamoDB's 1024-byte limit. Only the first
+	// maxPrefixTokenBytes bytes of the text take part in prefix matching.
+	maxPrefixTokenBytes = 512
+
+	// createTableTimeout is how long New waits for a newly created table to become active.
+	createTableTimeout = 2 * time.Minute
+
+	// Sort key prefixes of entry and token items.
+	entryPrefix = "e#"
+	tokenPrefix = "t#"
+
+	// tokenSeparator ends the token in a token item's sort key, so that an exact
+	// token lookup does not also match longer tokens.
+	tokenSeparator = "\x00"
+)
+
+// client is the subset of the DynamoDB API used by the provider.
+type client interface {
+	GetItem(ctx context.Context, params *ddb.GetItemInput, optFns ...func(*ddb.Options)) (*ddb.GetItemOutput, error)
+	Query(ctx context.Context, params *ddb.QueryInput, optFns ...func(*ddb.Options)) (*ddb.QueryOutput, error)
+	BatchWriteItem(ctx context.Context, params *ddb.BatchWriteItemInput, optFns ...func(*ddb.Options)) (*ddb.BatchWriteItemOutput, error)
+}
+
+// Provider implements the autocomplete Provider interface using DynamoDB.
+// Each namespace is one partition of the table. An entry is stored as one entry
+// item plus one token item per token, keyed "t#<token>\x00<id>", so that prefix
+// and n-gram lookups are begins_with queries on the sort key. Token items carry
+// the display and score, so queries need no second round trip.
+// All methods are safe for concurrent use.
+type Provider struct {
+	client client
+	table  string
+}
+
+// entry is the stored form of an entry item, holding what is needed to
+// recompute its tokens when it is updated or deleted.
+type entry struct {
+	searchText string
+	strategy   providers.MatchStrategy
+	nGramSize  int
+}
+
+// match is a candidate result with the data used to rank it.
+type match struct {
+	result   providers.ProviderResult
+	position int
+	length   int
+}
+
+// New creates a new DynamoDB provider with the given configuration.
+// It loads the AWS configuration and, if CreateTable is set, creates the table.
+func New(config Config) (*Provider, error) {
+	config.setDefaults()
+	ctx := context.Background()
+
+	var loadOptions []func(*awsconfig.LoadOptions) error
+	if config.Region != "" {
+		loadOptions = append(loadOptions, awsconfig.WithRegion(config.Region))
+	}
+	awsConfig, err := awsconfig.LoadDefaultConfig(ctx, loadOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+
+	dynamo := ddb.NewFromConfig(awsConfig, func(o *ddb.Options) {
+		if config.Endpoint != "" {
+			o.BaseEndpoint = aws.String(config.Endpoint)
+		}
+	})
+
+	if config.CreateTable {
+		if err := createTable(ctx, dynamo, config.Table); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Provider{client: dynamo, table: config.Table}, nil
+}
+
+// createTable creates the table with on-demand billing unless it already exists,
+// and waits for it to become active.
+func createTable(ctx context.Context, dynamo *ddb.Client, table string) error {
+	_, err := dynamo.DescribeTable(ctx, &ddb.DescribeTableInput{TableName: aws.String(table)})
+	if err == nil {
+		return nil
+	}
+	var notFound *types.ResourceNotFoundException
+	if !errors.As(err, &notFound) {
+		return fmt.Errorf("failed to describe table: %w", err)
+	}
+
+	_, err = dynamo.CreateTable(ctx, &ddb.CreateTableInput{
+		TableName: aws.String(table),
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String("pk"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("sk"), AttributeType: types.ScalarAttributeTypeS},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("pk"), KeyType: types.KeyTypeHash},
+			{AttributeName: aws.String("sk"), KeyType: types.KeyTypeRange},
+		},
+		BillingMode: types.BillingModePayPerRequest,
+	})
+	var inUse *types.ResourceInUseException
+	if err != nil && !errors.As(err, &inUse) {
+		return fmt.Errorf("failed to create table: %w", err)
+	}
+
+	waiter := ddb.NewTableExistsWaiter(dynamo)
+	if err := waiter.Wait(ctx, &ddb.DescribeTableInput{TableName: aws.String(table)}, createTableTimeout); err != nil {
+		return fmt.Errorf("failed waiting for table to become active: %w", err)
+	}
+	return nil
+}
+
+// Index adds or updates an entry. Tokens of the previous text that the new text
+// no longer produces are deleted, and all writes are sent in batches.
+func (p *Provider) Index(ctx context.Context, key, id, text, display string, options providers.IndexOptions) error {
+	searchText := providers.SearchText(text, options.CaseSensitive)
+	n := getNGramSizeOrDefault(options.NGramSize)
+	newTokens, err := tokenize(searchText, options.MatchStrategy, n)
+	if err != nil {
+		return err
+	}
+
+	old, exists, err := p.getEntry(ctx, key, id)
+	if err != nil {
+		return fmt.Errorf("failed to read existing entry: %w", err)
+	}
+
+	var writes []types.WriteRequest
+	if exists {
+		oldTokens, err := tokenize(old.searchText, old.strategy, old.nGramSize)
+		if err != nil {
+			return err
+		}
+		for tok := range oldTokens {
+			if _, kept := newTokens[tok]; !kept {
+				writes = append(writes, deleteRequest(key, tokenSortKey(tok, id)))
+			}
+		}
+	}
+
+	// The entry item is written before its tokens, so that Delete can always
+	// find every token that may have been written
+	writes = append(writes, types.WriteRequest{PutRequest: &types.PutRequest{Item: map[string]types.AttributeValue{
+		"pk":             stringValue(key),
+		"sk":             stringValue(entryPrefix + id),
+		"text":           stringValue(text),
+		"search_text":    stringValue(searchText),
+		"display":        stringValue(display),
+		"score":          numberValue(strconv.FormatFloat(options.Score, 'g', -1, 64)),
+		"case_sensitive": &types.AttributeValueMemberBOOL{Value: options.CaseSensitive},
+		"content_hash":   stringValue(options.ContentHash),
+		"strategy":       numberValue(strconv.Itoa(int(options.MatchStrategy))),
+		"ngram_size":     numberValue(strconv.Itoa(n)),
+	}}})
+
+	for tok, position := range newTokens {
+		writes = append(writes, types.WriteRequest{PutRequest: &types.PutRequest{Item: map[string]types.AttributeValue{
+			"pk":       stringValue(key),
+			"sk":       stringValue(tokenSortKey(tok, id)),
+			"id":       stringValue(id),
+			"display":  stringValue(display),
+			"score":    numberValue(strconv.FormatFloat(options.Score, 'g', -1, 64)),
+			"position": numberValue(strconv.Itoa(position)),
+			"length":   numberValue(strconv.Itoa(len(text))),
+		}}})
+	}
+
+	if err := p.batchWrite(ctx, writes); err != nil {
+		return fmt.Errorf("failed to index entry: %w", err)
+	}
+	return nil
+}
+
+// getEntry reads the entry item for id and reports whether it exists.
+func (p *Provider) getEntry(ctx context.Context, key, id string) (entry, bool, error) {
+	out, err := p.client.GetItem(ctx, &ddb.GetItemInput{
+		TableName:      aws.String(p.table),
+		Key:            itemKey(key, entryPrefix+id),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return entry{}, false, err
+	}
+	if out.Item == nil {
+		return entry{}, false, nil
+	}
+
+	strategy, _ := strconv.Atoi(numberAttribute(out.Item, "strategy"))
+	nGramSize, _ := strconv.Atoi(numberAttribute(out.Item, "ngram_size"))
+	return entry{
+		searchText: stringAttribute(out.Item, "search_text"),
+		strategy:   providers.MatchStrategy(strategy),
+		nGramSize:  nGramSize,
+	}, true, nil
+}
+
+// ContentHash returns the stored content hash for an entry and whether it exists.
+func (p *Provider) ContentHash(ctx context.Context, key, id string) (string, bool, error) {
+	out, err := p.client.GetItem(ctx, &ddb.GetItemInput{
+		TableName:                aws.String(p.table),
+		Key:                      itemKey(key, entryPrefix+id),
+		ProjectionExpression:     aws.String("#hash"),
+		ExpressionAttributeNames: map[string]string{"#hash": "content_hash"},
+	})
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get content hash: %w", err)
+	}
+	if out.Item == nil {
+		return "", false, nil
+	}
+	return stringAttribute(out.Item, "content_hash"), true, nil
+}
+
+// Query searches for entries matching the given query.
+// Results are ranked by entry score, then match position, then text length.
+func (p *Provider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	searchQuery := providers.SearchText(query, options.CaseSensitive)
+	if searchQuery == "" {
+		return []providers.ProviderResult{}, nil
+	}
+
+	var matches map[string]match
+	var err error
+	n := getNGramSizeOrDefault(options.NGramSize)
+	switch options.MatchStrategy {
+	case providers.MatchPrefix:
+		matches, err = p.lookup(ctx, key, truncateToBytes(searchQuery, maxPrefixTokenBytes))
+	case providers.MatchNGram:
+		if len(searchQuery) <= n {
+			matches, err = p.lookup(ctx, key, searchQuery)
+		} else {
+			matches, err = p.intersect(ctx, key, searchQuery, n)
+		}
+	default:
+		return nil, unsupportedStrategy(options.MatchStrategy)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query autocomplete: %w", err)
+	}
+
+	return rank(matches, options.MaxResults), nil
+}
+
+// lookup returns the entries with a token starting with prefix, keyed by ID and
+// positioned at the earliest such token.
+func (p *Provider) lookup(ctx context.Context, key, prefix string) (map[string]match, error) {
+	paginator := ddb.NewQueryPaginator(p.client, &ddb.QueryInput{
+		TableName:              aws.String(p.table),
+		KeyConditionExpression: aws.String("pk = :pk AND begins_with(sk, :prefix)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":     stringValue(key),
+			":prefix": stringValue(tokenPrefix + prefix),
+		},
+		ProjectionExpression: aws.String("#id, #display, #score, #position, #length"),
+		ExpressionAttributeNames: map[string]string{
+			"#id": "id", "#display": "display", "#score": "score", "#position": "position", "#length": "length",
+		},
+	})
+
+	matches := make(map[string]match)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range page.Items {
+			m := tokenMatch(item)
+			if existing, seen := matches[m.result.ID]; !seen || m.position < existing.position {
+				matches[m.result.ID] = m
+			}
+		}
+	}
+	return matches, nil
+}
+
+// intersect returns the entries containing every n-gram of the query,
+// positioned at the query's first n-gram.
+func (p *Provider) intersect(ctx context.Context, key, searchQuery string, n int) (map[string]match, error) {
+	matches, err := p.lookup(ctx, key, searchQuery[:n]+tokenSeparator)
+	if err != nil {
+		return nil, err
+	}
+	for i := 1; i <= len(searchQuery)-n && len(matches) > 0; i++ {
+		others, err := p.lookup(ctx, key, searchQuery[i:i+n]+tokenSeparator)
+		if err != nil {
+			return nil, err
+		}
+		for id := range matches {
+			if _, ok := others[id]; !ok {
+				delete(matches, id)
+			}
+		}
+	}
+	return matches, nil
+}
+
+// rank orders matches by score, position, length, and ID, keeping at most limit.
+func rank(matches map[string]match, limit int) []providers.ProviderResult {
+	sorted := make([]match, 0, len(matches))
+	for _, m := range matches {
+		sorted = append(sorted, m)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.result.Score != b.result.Score {
+			return a.result.Score > b.result.Score
+		}
+		if a.position != b.position {
+			return a.position < b.position
+		}
+		if a.length != b.length {
+			return a.length < b.length
+		}
+		return a.result.ID < b.result.ID
+	})
+
+	if limit > 0 && len(sorted) > limit {
+		sorted = sorted[:limit]
+	}
+	results := make([]providers.ProviderResult, len(sorted))
+	for i, m := range sorted {
+		results[i] = m.result
+	}
+	return results
+}
+
+// Delete removes an entry and its tokens from the index.
+func (p *Provider) Delete(ctx context.Context, key, id string) error {
+	old, exists, err := p.getEntry(ctx, key, id)
+	if err != nil {
+		return fmt.Errorf("failed to read existing entry: %w", err)
+	}
+	if !exists {
+		return nil
+	}
+
+	tokens, err := tokenize(old.searchText, old.strategy, old.nGramSize)
+	if err != nil {
+		return err
+	}
+	writes := make([]types.WriteRequest, 0, len(tokens)+1)
+	for tok := range tokens {
+		writes = append(writes, deleteRequest(key, tokenSortKey(tok, id)))
+	}
+	// The entry item goes last, so a failed delete can be retried
+	writes = append(writes, deleteRequest(key, entryPrefix+id))
+
+	if err := p.batchWrite(ctx, writes); err != nil {
+		return fmt.Errorf("failed to delete entry: %w", err)
+	}
+	return nil
+}
+
+// DeleteAll removes all items of the namespace's partition.
+func (p *Provider) DeleteAll(ctx context.Context, key string) error {
+	paginator := ddb.NewQueryPaginator(p.client, &ddb.QueryInput{
+		TableName:                 aws.String(p.table),
+		KeyConditionExpression:    aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{":pk": stringValue(key)},
+		ProjectionExpression:      aws.String("pk, sk"),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list entries: %w", err)
+		}
+		writes := make([]types.WriteRequest, len(page.Items))
+		for i, item := range page.Items {
+			writes[i] = deleteRequest(key, stringAttribute(item, "sk"))
+		}
+		if err := p.batchWrite(ctx, writes); err != nil {
+			return fmt.Errorf("failed to delete entries: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close releases resources. The DynamoDB client holds no connections that need closing.
+func (p *Provider) Close() error {
+	return nil
+}
+
+// batchWrite sends write requests in batches of maxBatchWrite, retrying
+// unprocessed items with exponential backoff.
+func (p *Provider) batchWrite(ctx context.Context, writes []types.WriteRequest) error {
+	for start := 0; start < len(writes); start += maxBatchWrite {
+		end := min(start+maxBatchWrite, len(writes))
+		pending := map[string][]types.WriteRequest{p.table: writes[start:end]}
+
+		backoff := initialBackoff
+		for attempt := 1; ; attempt++ {
+			out, err := p.client.BatchWriteItem(ctx, &ddb.BatchWriteItemInput{RequestItems: pending})
+			if err != nil {
+				return err
+			}
+			if len(out.UnprocessedItems[p.table]) == 0 {
+				break
+			}
+			if attempt == maxBatchAttempts {
+				return fmt.Errorf("%d items still unprocessed after %d attempts", len(out.UnprocessedItems[p.table]), attempt)
+			}
+			pending = out.UnprocessedItems
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+	}
+	return nil
+}
+
+// tokenize returns the tokens stored for normalized text under the given strategy,
+// mapped to the earliest byte offset at which each occurs.
+// MatchPrefix stores the (capped) text itself, since a begins_with query on it
+// matches every prefix. MatchNGram stores each n-gram; queries up to n bytes long
+// match the n-grams they begin.
+func tokenize(text string, strategy providers.MatchStrategy, n int) (map[string]int, error) {
+	tokens := make(map[string]int)
+	switch strategy {
+	case providers.MatchPrefix:
+		if text != "" {
+			tokens[truncateToBytes(text, maxPrefixTokenBytes)] = 0
+		}
+	case providers.MatchNGram:
+		for start := len(text) - n; start >= 0; start-- {
+			tokens[text[start:start+n]] = start
+		}
+	default:
+		return nil, unsupportedStrategy(strategy)
+	}
+	return tokens, nil
+}
+
+// unsupportedStrategy returns the error for strategies this provider cannot serve.
+func unsupportedStrategy(strategy providers.MatchStrategy) error {
+	return fmt.Errorf("match strategy %d is not supported by the DynamoDB provider: use MatchPrefix or MatchNGram", strategy)
+}
+
+// truncateToBytes shortens s to at most limit bytes without splitting a character.
+func truncateToBytes(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	for limit > 0 && !utf8.RuneStart(s[limit]) {
+		limit--
+	}
+	return s[:limit]
+}
+
+// tokenMatch converts a token item into a match.
+func tokenMatch(item map[string]types.AttributeValue) match {
+	score, _ := strconv.ParseFloat(numberAttribute(item, "score"), 64)
+	position, _ := strconv.Atoi(numberAttribute(item, "position"))
+	length, _ := strconv.Atoi(numberAttribute(item, "length"))
+	return match{
+		result: providers.ProviderResult{
+			ID:      stringAttribute(item, "id"),
+			Display: stringAttribute(item, "display"),
+			Score:   score,
+		},
+		position: position,
+		length:   length,
+	}
+}
+
+func tokenSortKey(tok, id string) string {
+	return tokenPrefix + tok + tokenSeparator + id
+}
+
+func itemKey(key, sortKey string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{"pk": stringValue(key), "sk": stringValue(sortKey)}
+}
+
+func deleteRequest(key, sortKey string) types.WriteRequest {
+	return types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: itemKey(key, sortKey)}}
+}
+
+func stringValue(s string) types.AttributeValue {
+	return &types.AttributeValueMemberS{Value: s}
+}
+
+func numberValue(n string) types.AttributeValue {
+	return &types.AttributeValueMemberN{Value: n}
+}
+
+func stringAttribute(item map[string]types.AttributeValue, name string) string {
+	if v, ok := item[name].(*types.AttributeValueMemberS); ok {
+		return v.Value
+	}
+	return ""
+}
+
+func numberAttribute(item map[string]types.AttributeValue, name string) string {
+	if v, ok := item[name].(*types.AttributeValueMemberN); ok {
+		return v.Value
+	}
+	return ""
+}
+
+func getNGramSizeOrDefault(size int) int {
+	if size <= 0 {
+		return defaultNGramSize
+	}
+	return size
+}
@@ -0,0 +1,225 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	ddb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+const testKey = "test"
+
+// fakeClient is an in-memory stand-in for DynamoDB that serves the key conditions
+// used by the provider. Queries return pages of at most pageSize items, and the
+// first write of every batch is reported unprocessed once, exercising retries.
+type fakeClient struct {
+	items       map[string]map[string]map[string]types.AttributeValue
+	pageSize    int
+	batchWrites int
+	retried     map[string]bool
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		items:    make(map[string]map[string]map[string]types.AttributeValue),
+		pageSize: 2,
+		retried:  make(map[string]bool),
+	}
+}
+
+func (f *fakeClient) GetItem(ctx context.Context, params *ddb.GetItemInput, optFns ...func(*ddb.Options)) (*ddb.GetItemOutput, error) {
+	pk, sk := stringAttribute(params.Key, "pk"), stringAttribute(params.Key, "sk")
+	return &ddb.GetItemOutput{Item: f.items[pk][sk]}, nil
+}
+
+func (f *fakeClient) Query(ctx context.Context, params *ddb.QueryInput, optFns ...func(*ddb.Options)) (*ddb.QueryOutput, error) {
+	pk := stringAttribute(params.ExpressionAttributeValues, ":pk")
+	prefix := stringAttribute(params.ExpressionAttributeValues, ":prefix")
+	start := stringAttribute(params.ExclusiveStartKey, "sk")
+
+	var keys []string
+	for sk := range f.items[pk] {
+		if strings.HasPrefix(sk, prefix) && sk > start {
+			keys = append(keys, sk)
+		}
+	}
+	sort.Strings(keys)
+
+	out := &ddb.QueryOutput{}
+	for _, sk := range keys {
+		if len(out.Items) == f.pageSize {
+			out.LastEvaluatedKey = itemKey(pk, stringAttribute(out.Items[len(out.Items)-1], "sk"))
+			break
+		}
+		out.Items = append(out.Items, f.items[pk][sk])
+	}
+	return out, nil
+}
+
+func (f *fakeClient) BatchWriteItem(ctx context.Context, params *ddb.BatchWriteItemInput, optFns ...func(*ddb.Options)) (*ddb.BatchWriteItemOutput, error) {
+	f.batchWrites++
+	out := &ddb.BatchWriteItemOutput{UnprocessedItems: map[string][]types.WriteRequest{}}
+	for table, writes := range params.RequestItems {
+		if len(writes) > maxBatchWrite {
+			return nil, fmt.Errorf("batch of %d writes exceeds limit", len(writes))
+		}
+		for i, w := range writes {
+			var key map[string]types.AttributeValue
+			if w.PutRequest != nil {
+				key = w.PutRequest.Item
+			} else {
+				key = w.DeleteRequest.Key
+			}
+			pk, sk := stringAttribute(key, "pk"), stringAttribute(key, "sk")
+
+			if i == 0 && !f.retried[pk+sk] {
+				f.retried[pk+sk] = true
+				out.UnprocessedItems[table] = append(out.UnprocessedItems[table], w)
+				continue
+			}
+			if w.PutRequest != nil {
+				if f.items[pk] == nil {
+					f.items[pk] = make(map[string]map[string]types.AttributeValue)
+				}
+				f.items[pk][sk] = w.PutRequest.Item
+			} else {
+				delete(f.items[pk], sk)
+			}
+		}
+	}
+	return out, nil
+}
+
+func newTestProvider() (*Provider, *fakeClient) {
+	fake := newFakeClient()
+	return &Provider{client: fake, table: "autocomplete"}, fake
+}
+
+func resultIDs(results []providers.ProviderResult) []string {
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+	return ids
+}
+
+func TestDynamoDBProvider_MatchStrategies(t *testing.T) {
+	ctx := context.Background()
+	entries := map[string]string{"1": "Mumbai", "2": "Navi Mumbai", "3": "Jammu", "4": "Mumbra"}
+
+	tests := []struct {
+		strategy providers.MatchStrategy
+		query    string
+		want     string
+	}{
+		{providers.MatchPrefix, "mum", "[1 4]"},
+		{providers.MatchPrefix, "navi m", "[2]"},
+		{providers.MatchPrefix, "umb", "[]"},
+		{providers.MatchNGram, "mu", "[1 4 2]"},
+		{providers.MatchNGram, "umba", "[1 2]"},
+		{providers.MatchNGram, "mumbai", "[1 2]"},
+		{providers.MatchNGram, "xyz", "[]"},
+	}
+
+	for _, tt := range tests {
+		provider, _ := newTestProvider()
+		options := providers.IndexOptions{Score: 1.0, MatchStrategy: tt.strategy, NGramSize: 3}
+		for id, text := range entries {
+			if err := provider.Index(ctx, testKey, id, text, text, options); err != nil {
+				t.Fatalf("Index() error = %v", err)
+			}
+		}
+
+		results, err := provider.Query(ctx, testKey, tt.query, providers.QueryOptions{
+			MaxResults:    10,
+			MatchStrategy: tt.strategy,
+			NGramSize:     3,
+		})
+		if err != nil {
+			t.Fatalf("strategy %d: Query(%q) error = %v", tt.strategy, tt.query, err)
+		}
+		if got := fmt.Sprint(resultIDs(results)); got != tt.want {
+			t.Errorf("strategy %d: Query(%q) = %v, want %v", tt.strategy, tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestDynamoDBProvider_UnsupportedStrategy(t *testing.T) {
+	provider, _ := newTestProvider()
+	ctx := context.Background()
+
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchSubstring}
+	if err := provider.Index(ctx, testKey, "1", "Mumbai", "Mumbai", options); err == nil {
+		t.Error("Index() with MatchSubstring should fail")
+	}
+	if _, err := provider.Query(ctx, testKey, "mum", providers.QueryOptions{MatchStrategy: providers.MatchSubstring}); err == nil {
+		t.Error("Query() with MatchSubstring should fail")
+	}
+}
+
+func TestDynamoDBProvider_UpdateAndDelete(t *testing.T) {
+	provider, fake := newTestProvider()
+	ctx := context.Background()
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchNGram, NGramSize: 3, ContentHash: "h1"}
+
+	if err := provider.Index(ctx, testKey, "1", "Mumbai", "Mumbai", options); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	options.ContentHash = "h2"
+	if err := provider.Index(ctx, testKey, "1", "Pune", "Pune", options); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+
+	// Only the entry item and the tokens of "pune" remain
+	if got := len(fake.items[testKey]); got != 3 {
+		t.Errorf("partition has %d items after update, want 3", got)
+	}
+	hash, exists, err := provider.ContentHash(ctx, testKey, "1")
+	if err != nil || !exists || hash != "h2" {
+		t.Errorf("ContentHash() = %q, %v, %v; want h2, true, nil", hash, exists, err)
+	}
+
+	if err := provider.Delete(ctx, testKey, "1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if got := len(fake.items[testKey]); got != 0 {
+		t.Errorf("partition has %d items after delete, want 0", got)
+	}
+	if _, exists, _ := provider.ContentHash(ctx, testKey, "1"); exists {
+		t.Error("ContentHash() should not find a deleted entry")
+	}
+}
+
+func TestDynamoDBProvider_DeleteAllBatches(t *testing.T) {
+	provider, fake := newTestProvider()
+	ctx := context.Background()
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchNGram, NGramSize: 3}
+
+	// A long text produces more token items than fit in one batch
+	text := "Chhatrapati Shivaji Maharaj Terminus"
+	if err := provider.Index(ctx, testKey, "1", text, text, options); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if err := provider.Index(ctx, "other", "1", text, text, options); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if fake.batchWrites < 2 {
+		t.Errorf("expected writes to be split into batches, got %d calls", fake.batchWrites)
+	}
+
+	if err := provider.DeleteAll(ctx, testKey); err != nil {
+		t.Fatalf("DeleteAll() error = %v", err)
+	}
+	if got := len(fake.items[testKey]); got != 0 {
+		t.Errorf("partition has %d items after DeleteAll, want 0", got)
+	}
+	if len(fake.items["other"]) == 0 {
+		t.Error("DeleteAll() should not affect other namespaces")
+	}
+}
@@ -0,0 +1,29 @@
+package dynamodb
+
+import (
+	"fmt"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// init registers the DynamoDB provider. Import this package with a blank identifier
+// to use DynamoDB as the autocomplete backend:
+//
+//	import _ "github.com/remiges-tech/autocomplete/providers/dynamodb"
+//
+//nolint:gochecknoinits // init() is the idiomatic pattern for provider registration
+func init() {
+	autocomplete.RegisterProvider("dynamodb", NewProvider)
+}
+
+// NewProvider creates a new DynamoDB provider from the given configuration.
+// It implements ProviderFactory and expects config to be of type dynamodb.Config.
+func NewProvider(config interface{}) (providers.Provider, error) {
+	dynamoConfig, ok := config.(Config)
+	if !ok {
+		return nil, fmt.Errorf("invalid configuration type for DynamoDB provider: expected dynamodb.Config, got %T", config)
+	}
+
+	return New(dynamoConfig)
+}
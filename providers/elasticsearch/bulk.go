@@ -0,0 +1,244 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// batchSize is the number of entries BatchIndex and DeleteMany send per bulk request.
+const batchSize = 1000
+
+// bulkItemResult is the outcome of a single action in a bulk response.
+type bulkItemResult struct {
+	ID     string `json:"_id"`
+	Status int    `json:"status"`
+	Error  *struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"error,omitempty"`
+}
+
+// bulkResponse represents the Elasticsearch bulk API response.
+type bulkResponse struct {
+	Errors bool                        `json:"errors"`
+	Items  []map[string]bulkItemResult `json:"items"`
+}
+
+// mgetResponse represents the Elasticsearch multi-get response.
+type mgetResponse struct {
+	Docs []getResponse `json:"docs"`
+}
+
+// bulkBody accumulates newline-delimited bulk actions.
+type bulkBody struct {
+	buf bytes.Buffer
+}
+
+// index appends an index action for the given document.
+func (b *bulkBody) index(docID string, doc *document) error {
+	return b.write(map[string]interface{}{"index": map[string]string{"_id": docID}}, doc)
+}
+
+// delete appends a delete action for the given document ID.
+func (b *bulkBody) delete(docID string) error {
+	return b.write(map[string]interface{}{"delete": map[string]string{"_id": docID}})
+}
+
+func (b *bulkBody) write(lines ...interface{}) error {
+	encoder := json.NewEncoder(&b.buf)
+	for _, line := range lines {
+		if err := encoder.Encode(line); err != nil {
+			return fmt.Errorf("failed to encode bulk action: %w", err)
+		}
+	}
+	return nil
+}
+
+// BatchIndex writes entries with bulk requests of up to batchSize entries.
+// Unlike IndexAtomic, entries written before a failure are kept.
+func (p *Provider) BatchIndex(ctx context.Context, key string, entries []providers.IndexEntry) error {
+	for start := 0; start < len(entries); start += batchSize {
+		var body bulkBody
+		for _, entry := range entries[start:min(start+batchSize, len(entries))] {
+			doc := newDocument(key, entry.ID, entry.Text, entry.Display, entry.Options)
+			if err := body.index(generateDocumentID(key, entry.ID), &doc); err != nil {
+				return err
+			}
+		}
+
+		_, failures, err := p.executeBulk(ctx, &body)
+		if err != nil {
+			return err
+		}
+		if len(failures) > 0 {
+			return fmt.Errorf("batch index failed: %s", strings.Join(failures, "; "))
+		}
+	}
+	return nil
+}
+
+// DeleteMany removes entries with bulk requests of up to batchSize entries.
+// Entries that do not exist are not reported as failures by the bulk API.
+func (p *Provider) DeleteMany(ctx context.Context, key string, ids []string) error {
+	for start := 0; start < len(ids); start += batchSize {
+		var body bulkBody
+		for _, id := range ids[start:min(start+batchSize, len(ids))] {
+			if err := body.delete(generateDocumentID(key, id)); err != nil {
+				return err
+			}
+		}
+
+		_, failures, err := p.executeBulk(ctx, &body)
+		if err != nil {
+			return err
+		}
+		if len(failures) > 0 {
+			return fmt.Errorf("batch delete failed: %s", strings.Join(failures, "; "))
+		}
+	}
+	return nil
+}
+
+// IndexAtomic writes all entries in a single bulk request. Elasticsearch has no
+// multi-document transactions, so if any entry fails the entries that were written
+// are restored to their previous state (or deleted if they were new). With the
+// default refresh policy the whole bulk becomes searchable in the same refresh.
+func (p *Provider) IndexAtomic(ctx context.Context, key string, entries []providers.IndexEntry) error {
+	docIDs := make([]string, len(entries))
+	var body bulkBody
+	for i, entry := range entries {
+		docIDs[i] = generateDocumentID(key, entry.ID)
+		doc := newDocument(key, entry.ID, entry.Text, entry.Display, entry.Options)
+		if err := body.index(docIDs[i], &doc); err != nil {
+			return err
+		}
+	}
+
+	previous, err := p.fetchDocuments(ctx, docIDs)
+	if err != nil {
+		return err
+	}
+
+	written, failures, err := p.executeBulk(ctx, &body)
+	if err != nil {
+		return err
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+
+	if err := p.rollback(ctx, written, previous); err != nil {
+		return fmt.Errorf("atomic index failed (%s) and rollback failed: %w", strings.Join(failures, "; "), err)
+	}
+	return fmt.Errorf("atomic index failed and was rolled back: %s", strings.Join(failures, "; "))
+}
+
+// fetchDocuments returns the currently stored documents for the given IDs.
+// Documents that do not exist are absent from the returned map.
+func (p *Provider) fetchDocuments(ctx context.Context, docIDs []string) (map[string]document, error) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(map[string]interface{}{"ids": docIDs}); err != nil {
+		return nil, fmt.Errorf("failed to encode multi-get request: %w", err)
+	}
+
+	req := esapi.MgetRequest{
+		Index: p.index,
+		Body:  &buf,
+	}
+
+	res, err := req.Do(ctx, p.client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch documents: %w", err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.IsError() {
+		return nil, fmt.Errorf("failed to fetch documents: %s", res.String())
+	}
+
+	var response mgetResponse
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	docs := make(map[string]document, len(response.Docs))
+	for _, doc := range response.Docs {
+		if doc.Found {
+			docs[doc.ID] = doc.Source
+		}
+	}
+	return docs, nil
+}
+
+// executeBulk sends a bulk request and returns the IDs of successful actions
+// and a description of each failed action.
+func (p *Provider) executeBulk(ctx context.Context, body *bulkBody) (succeeded, failures []string, err error) {
+	req := esapi.BulkRequest{
+		Index:   p.index,
+		Body:    &body.buf,
+		Refresh: p.refreshPolicy,
+	}
+
+	res, err := req.Do(ctx, p.client)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to execute bulk request: %w", err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.IsError() {
+		return nil, nil, fmt.Errorf("bulk request failed: %s", res.String())
+	}
+
+	var response bulkResponse
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	for _, item := range response.Items {
+		for _, result := range item {
+			if result.Error != nil {
+				failures = append(failures, fmt.Sprintf("%s: %s", result.ID, result.Error.Reason))
+				continue
+			}
+			succeeded = append(succeeded, result.ID)
+		}
+	}
+	return succeeded, failures, nil
+}
+
+// rollback restores written documents to their previous state.
+func (p *Provider) rollback(ctx context.Context, written []string, previous map[string]document) error {
+	if len(written) == 0 {
+		return nil
+	}
+
+	var body bulkBody
+	for _, docID := range written {
+		var err error
+		if doc, existed := previous[docID]; existed {
+			err = body.index(docID, &doc)
+		} else {
+			err = body.delete(docID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	_, failures, err := p.executeBulk(ctx, &body)
+	if err != nil {
+		return err
+	}
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, "; "))
+	}
+	return nil
+}
@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/elastic/go-elasticsearch/v8"
@@ -87,7 +89,9 @@ const (
 				},
 				"display": {"type": "text"},
 				"score": {"type": "float"},
-				"case_sensitive": {"type": "boolean"}
+				"case_sensitive": {"type": "boolean"},
+				"content_hash": {"type": "keyword", "index": false},
+				"metadata": {"type": "flattened"}
 			}
 		}
 	}`
@@ -108,12 +112,28 @@ type document struct {
 	Display       string  `json:"display"`
 	Score         float64 `json:"score"`
 	CaseSensitive bool    `json:"case_sensitive"`
+	ContentHash   string  `json:"content_hash,omitempty"`
+
+	// Metadata is stored as a flattened field, so each of its values can be
+	// filtered with a term query on metadata.<field>.
+	Metadata json.RawMessage `json:"metadata,omitempty"`
+}
+
+// getResponse represents the Elasticsearch get document response.
+type getResponse struct {
+	ID     string   `json:"_id"`
+	Found  bool     `json:"found"`
+	Source document `json:"_source"`
 }
 
 // searchHit represents a single search result from Elasticsearch.
 type searchHit struct {
 	Score  float64  `json:"_score"`
 	Source document `json:"_source"`
+
+	// Sort holds the hit's sort values when the search is sorted, to continue
+	// after it with search_after.
+	Sort []interface{} `json:"sort,omitempty"`
 }
 
 // searchResponse represents the Elasticsearch search response.
@@ -126,6 +146,11 @@ type searchResponse struct {
 	} `json:"hits"`
 }
 
+// countResponse represents the Elasticsearch count response.
+type countResponse struct {
+	Count int64 `json:"count"`
+}
+
 // New creates a new Elasticsearch provider with the given configuration.
 func New(config *Config) (*Provider, error) {
 	config.setDefaults()
@@ -218,14 +243,7 @@ func (p *Provider) indexExists() (bool, error) {
 
 // Index adds or updates an entry in the Elasticsearch autocomplete index.
 func (p *Provider) Index(ctx context.Context, key, id, text, display string, options providers.IndexOptions) error {
-	doc := document{
-		ID:            id,
-		Key:           key,
-		Text:          text,
-		Display:       display,
-		Score:         options.Score,
-		CaseSensitive: options.CaseSensitive,
-	}
+	doc := newDocument(key, id, text, display, options)
 
 	// Prepare document for indexing
 	docJSON, err := json.Marshal(doc)
@@ -254,6 +272,74 @@ func (p *Provider) Index(ctx context.Context, key, id, text, display string, opt
 	return nil
 }
 
+// ContentHash returns the stored content hash for an entry and whether it exists.
+func (p *Provider) ContentHash(ctx context.Context, key, id string) (string, bool, error) {
+	req := esapi.GetRequest{
+		Index:          p.index,
+		DocumentID:     generateDocumentID(key, id),
+		SourceIncludes: []string{"content_hash"},
+	}
+
+	res, err := req.Do(ctx, p.client)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get document: %w", err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	const httpNotFound = 404
+	if res.StatusCode == httpNotFound {
+		return "", false, nil
+	}
+	if res.IsError() {
+		return "", false, fmt.Errorf("failed to get document: %s", res.String())
+	}
+
+	var response getResponse
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return "", false, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return response.Source.ContentHash, response.Found, nil
+}
+
+// CountEntries returns the number of documents of the namespace with a count
+// request filtered by key.
+func (p *Provider) CountEntries(ctx context.Context, key string) (int64, error) {
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"term": map[string]interface{}{
+				"key": key,
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(query); err != nil {
+		return 0, fmt.Errorf("failed to encode query: %w", err)
+	}
+
+	req := esapi.CountRequest{
+		Index: []string{p.index},
+		Body:  &buf,
+	}
+
+	res, err := req.Do(ctx, p.client)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count documents: %w", err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.IsError() {
+		return 0, fmt.Errorf("failed to count documents: %s", res.String())
+	}
+
+	var response countResponse
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return 0, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return response.Count, nil
+}
+
 // Query searches for entries matching the given query.
 func (p *Provider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
 	// Build query based on match strategy
@@ -276,6 +362,12 @@ func (p *Provider) Query(ctx context.Context, key, query string, options provide
 		Body:  &buf,
 		Size:  &size,
 	}
+	if options.Offset > 0 {
+		req.From = &options.Offset
+	}
+	if options.SnapshotID != "" {
+		req.Index = nil
+	}
 
 	res, err := req.Do(ctx, p.client)
 	if err != nil {
@@ -309,10 +401,7 @@ func (p *Provider) buildQuery(key, query string, options providers.QueryOptions)
 	}
 
 	// Prepare query text
-	queryText := query
-	if !options.CaseSensitive {
-		queryText = strings.ToLower(query)
-	}
+	queryText := providers.SearchText(query, options.CaseSensitive)
 
 	// Add match query based on strategy
 	var matchQuery map[string]interface{}
@@ -358,11 +447,24 @@ func (p *Provider) buildQuery(key, query string, options providers.QueryOptions)
 		boolQuery["must"] = []interface{}{matchQuery}
 	}
 
+	// Fields are sorted so that the same filter always builds the same request
+	for _, field := range slices.Sorted(maps.Keys(options.FilterMetadata)) {
+		boolQuery["filter"] = append(boolQuery["filter"].([]interface{}), map[string]interface{}{
+			"term": map[string]interface{}{
+				"metadata." + field: options.FilterMetadata[field],
+			},
+		})
+	}
+
 	// Add minimum score filter if specified
 	if options.MinScore > 0 {
 		baseQuery["min_score"] = options.MinScore
 	}
 
+	if options.SnapshotID != "" {
+		baseQuery["pit"] = pointInTime(options.SnapshotID, options.SnapshotKeepAlive)
+	}
+
 	return baseQuery
 }
 
@@ -373,17 +475,23 @@ func (p *Provider) parseSearchResponse(body io.Reader) ([]providers.ProviderResu
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	results := make([]providers.ProviderResult, 0, len(response.Hits.Hits))
-	for _, hit := range response.Hits.Hits {
+	return resultsFromHits(response.Hits.Hits), nil
+}
+
+// resultsFromHits converts search hits into provider results.
+func resultsFromHits(hits []searchHit) []providers.ProviderResult {
+	results := make([]providers.ProviderResult, 0, len(hits))
+	for _, hit := range hits {
 		result := providers.ProviderResult{
-			ID:      hit.Source.ID,
-			Display: hit.Source.Display,
-			Score:   hit.Score,
+			ID:       hit.Source.ID,
+			Display:  hit.Source.Display,
+			Score:    hit.Score,
+			Metadata: string(hit.Source.Metadata),
 		}
 		results = append(results, result)
 	}
 
-	return results, nil
+	return results
 }
 
 // Delete removes an entry from the index.
@@ -443,6 +551,23 @@ func (p *Provider) DeleteAll(ctx context.Context, key string) error {
 	return nil
 }
 
+// SupportsMatchStrategies reports that entries can be queried under any combination
+// of strategies. Every document is analyzed into the prefix, n-gram, and substring
+// subfields of its text regardless of the strategy it was indexed with, so queries
+// select a strategy simply by searching the corresponding subfield.
+func (p *Provider) SupportsMatchStrategies(strategies []providers.MatchStrategy) bool {
+	return true
+}
+
+// SupportsMetadata reports that metadata payloads are stored with documents and
+// that queries filter by them. Filters are term queries on the flattened
+// metadata field, so they match a field's value exactly, with numbers and
+// booleans compared as their string form. Indexes created before metadata was
+// supported need the metadata mapping added before filtering by it.
+func (p *Provider) SupportsMetadata() bool {
+	return true
+}
+
 // Close closes the provider connection.
 func (p *Provider) Close() error {
 	// The Elasticsearch Go client doesn't have a Close method
@@ -450,6 +575,29 @@ func (p *Provider) Close() error {
 	return nil
 }
 
+// newDocument builds the stored document for an entry.
+func newDocument(key, id, text, display string, options providers.IndexOptions) document {
+	return document{
+		ID:            id,
+		Key:           key,
+		Text:          text,
+		Display:       display,
+		Score:         options.Score,
+		CaseSensitive: options.CaseSensitive,
+		ContentHash:   options.ContentHash,
+		Metadata:      metadataField(options.Metadata),
+	}
+}
+
+// metadataField returns the metadata payload to store in a document, nil when
+// the entry has none.
+func metadataField(metadata string) json.RawMessage {
+	if metadata == "" {
+		return nil
+	}
+	return json.RawMessage(metadata)
+}
+
 // generateDocumentID creates a unique document ID from key and id.
 func generateDocumentID(key, id string) string {
 	return fmt.Sprintf("%s:%s", key, id)
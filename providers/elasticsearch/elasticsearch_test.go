@@ -0,0 +1,200 @@
+package elasticsearch
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/remiges-tech/autocomplete/providers"
+	"github.com/remiges-tech/autocomplete/providers/providertest"
+)
+
+// newGoldenProvider returns a provider talking to a server replaying the
+// fixture at path. The fixtures are golden files of the requests the provider
+// sends; re-record them against a live cluster with the RecordEnv variable
+// described in package providertest.
+func newGoldenProvider(t *testing.T, path string) *Provider {
+	t.Helper()
+	server := providertest.NewServer(t, path)
+	provider, err := New(&Config{
+		URLs:          []string{server.URL},
+		Index:         "autocomplete-golden",
+		RefreshPolicy: "true",
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return provider
+}
+
+func resultIDs(results []providers.ProviderResult) []string {
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+	return ids
+}
+
+func TestGolden_IndexAndQuery(t *testing.T) {
+	p := newGoldenProvider(t, "testdata/index_and_query.json")
+	ctx := context.Background()
+	const key = "cities"
+
+	entries := []providers.IndexEntry{
+		{ID: "1", Text: "Mumbai", Display: "Mumbai, Maharashtra", Options: providers.IndexOptions{Score: 1}},
+		{ID: "2", Text: "Navi Mumbai", Display: "Navi Mumbai, Maharashtra", Options: providers.IndexOptions{Score: 1}},
+		{ID: "3", Text: "Pune", Display: "Pune, Maharashtra", Options: providers.IndexOptions{Score: 1, ContentHash: "pune-v1"}},
+	}
+	if err := p.IndexAtomic(ctx, key, entries); err != nil {
+		t.Fatalf("IndexAtomic() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		options providers.QueryOptions
+		want    []string
+	}{
+		{"prefix", "mum", providers.QueryOptions{MatchStrategy: providers.MatchPrefix, MaxResults: 10}, []string{"1", "2"}},
+		{"ngram", "umb", providers.QueryOptions{MatchStrategy: providers.MatchNGram, MaxResults: 10}, []string{"1", "2"}},
+		{"substring with offset", "mumbai", providers.QueryOptions{MatchStrategy: providers.MatchSubstring, MaxResults: 1, Offset: 1}, []string{"2"}},
+		{"min score", "pune", providers.QueryOptions{MatchStrategy: providers.MatchPrefix, MinScore: 0.5}, []string{"3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := p.Query(ctx, key, tt.query, tt.options)
+			if err != nil {
+				t.Fatalf("Query() error = %v", err)
+			}
+			if got := resultIDs(results); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Query(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+
+	hash, found, err := p.ContentHash(ctx, key, "3")
+	if err != nil || !found || hash != "pune-v1" {
+		t.Errorf("ContentHash() = %q, %v, %v; want pune-v1, true, nil", hash, found, err)
+	}
+	if err := p.Delete(ctx, key, "3"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, found, err := p.ContentHash(ctx, key, "3"); err != nil || found {
+		t.Errorf("ContentHash() after Delete found = %v, err = %v", found, err)
+	}
+	if count, err := p.CountEntries(ctx, key); err != nil || count != 2 {
+		t.Errorf("CountEntries() = %d, %v; want 2", count, err)
+	}
+	if err := p.DeleteAll(ctx, key); err != nil {
+		t.Fatalf("DeleteAll() error = %v", err)
+	}
+}
+
+func TestGolden_Snapshot(t *testing.T) {
+	p := newGoldenProvider(t, "testdata/snapshot.json")
+	ctx := context.Background()
+	const key = "cities"
+
+	if err := p.Index(ctx, key, "1", "Chennai", "Chennai, Tamil Nadu", providers.IndexOptions{Score: 1}); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	snapshotID, err := p.OpenSnapshot(ctx, key, time.Minute)
+	if err != nil {
+		t.Fatalf("OpenSnapshot() error = %v", err)
+	}
+	if err := p.Delete(ctx, key, "1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	results, err := p.Query(ctx, key, "chen", providers.QueryOptions{
+		MatchStrategy:     providers.MatchPrefix,
+		MaxResults:        10,
+		SnapshotID:        snapshotID,
+		SnapshotKeepAlive: time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if got := resultIDs(results); len(got) != 1 || got[0] != "1" {
+		t.Errorf("Query() in snapshot = %v, want [1]", got)
+	}
+	if err := p.CloseSnapshot(ctx, snapshotID); err != nil {
+		t.Fatalf("CloseSnapshot() error = %v", err)
+	}
+}
+
+func TestGolden_QueryPage(t *testing.T) {
+	p := newGoldenProvider(t, "testdata/query_page.json")
+	ctx := context.Background()
+	const key = "stations"
+
+	for _, e := range []struct{ id, text string }{
+		{"1", "Pune"},
+		{"2", "Pune Cantonment"},
+		{"3", "Pune Station"},
+	} {
+		if err := p.Index(ctx, key, e.id, e.text, e.text+", Maharashtra", providers.IndexOptions{Score: 1}); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	options := providers.QueryOptions{MatchStrategy: providers.MatchPrefix, MaxResults: 2}
+	results, cursor, err := p.QueryPage(ctx, key, "pune", options, "")
+	if err != nil {
+		t.Fatalf("QueryPage() error = %v", err)
+	}
+	if got := resultIDs(results); !reflect.DeepEqual(got, []string{"1", "2"}) || cursor != `[0.9,"2"]` {
+		t.Fatalf("QueryPage() first page = %v, %q; want [1 2] and the sort values of 2", got, cursor)
+	}
+
+	results, cursor, err = p.QueryPage(ctx, key, "pune", options, cursor)
+	if err != nil {
+		t.Fatalf("QueryPage() error = %v", err)
+	}
+	if got := resultIDs(results); !reflect.DeepEqual(got, []string{"3"}) || cursor != "" {
+		t.Errorf("QueryPage() second page = %v, %q; want [3] and no cursor", got, cursor)
+	}
+
+	if _, _, err := p.QueryPage(ctx, key, "pune", options, "not a cursor"); err == nil {
+		t.Error("QueryPage() with an invalid cursor succeeded, want an error")
+	}
+}
+
+func TestGolden_UpdateAndIncrementScore(t *testing.T) {
+	p := newGoldenProvider(t, "testdata/score.json")
+	ctx := context.Background()
+	const key = "cities"
+
+	if err := p.UpdateScore(ctx, key, "1", 3); err != nil {
+		t.Fatalf("UpdateScore() error = %v", err)
+	}
+	if err := p.UpdateScore(ctx, key, "missing", 3); err != nil {
+		t.Errorf("UpdateScore() of unknown ID error = %v", err)
+	}
+	if err := p.IncrementScore(ctx, key, "1", 2); err != nil {
+		t.Fatalf("IncrementScore() error = %v", err)
+	}
+	if err := p.IncrementScore(ctx, key, "missing", 2); err != nil {
+		t.Errorf("IncrementScore() of unknown ID error = %v", err)
+	}
+}
+
+func TestBuildQueryFilterMetadata(t *testing.T) {
+	p := &Provider{}
+	options := providers.QueryOptions{
+		MatchStrategy:  providers.MatchPrefix,
+		FilterMetadata: map[string]interface{}{"state": "Maharashtra", "category": "electronics"},
+	}
+	query := p.buildQuery("cities", "mum", options)
+
+	filters := query["query"].(map[string]interface{})["bool"].(map[string]interface{})["filter"].([]interface{})
+	want := []interface{}{
+		map[string]interface{}{"term": map[string]interface{}{"key": "cities"}},
+		map[string]interface{}{"term": map[string]interface{}{"metadata.category": "electronics"}},
+		map[string]interface{}{"term": map[string]interface{}{"metadata.state": "Maharashtra"}},
+	}
+	if !reflect.DeepEqual(filters, want) {
+		t.Errorf("buildQuery() filters = %v, want %v", filters, want)
+	}
+}
@@ -0,0 +1,82 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// pageSort orders paged searches by relevance, breaking ties by entry ID, so
+// that every hit has distinct sort values to continue after.
+var pageSort = []interface{}{
+	map[string]interface{}{"_score": "desc"},
+	map[string]interface{}{"id": "asc"},
+}
+
+// QueryPage returns a page of the results of a query and the cursor of the
+// next, which holds the sort values of the page's last hit for search_after.
+// Unlike paging with from, it costs the same for every page. The cursor is
+// only meaningful for the same query, and pages may shift if entries are
+// written in between, unless QueryOptions.SnapshotID pins the index.
+func (p *Provider) QueryPage(
+	ctx context.Context, key, query string, options providers.QueryOptions, cursor string,
+) ([]providers.ProviderResult, string, error) {
+	esQuery := p.buildQuery(key, query, options)
+	esQuery["sort"] = pageSort
+	if cursor != "" {
+		var after []interface{}
+		if err := json.Unmarshal([]byte(cursor), &after); err != nil || len(after) != len(pageSort) {
+			return nil, "", fmt.Errorf("invalid page cursor %q", cursor)
+		}
+		esQuery["search_after"] = after
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(esQuery); err != nil {
+		return nil, "", fmt.Errorf("failed to encode query: %w", err)
+	}
+
+	// One hit past the page is fetched to tell whether another follows
+	size := options.MaxResults
+	if size <= 0 {
+		size = defaultMaxResults
+	}
+	fetch := size + 1
+	req := esapi.SearchRequest{
+		Index: []string{p.index},
+		Body:  &buf,
+		Size:  &fetch,
+	}
+	if options.SnapshotID != "" {
+		req.Index = nil
+	}
+
+	res, err := req.Do(ctx, p.client)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to execute search: %w", err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.IsError() {
+		return nil, "", fmt.Errorf("search failed: %s", res.String())
+	}
+
+	var response searchResponse
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	hits := response.Hits.Hits
+	if len(hits) <= size {
+		return resultsFromHits(hits), "", nil
+	}
+	next, err := json.Marshal(hits[size-1].Sort)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode page cursor: %w", err)
+	}
+	return resultsFromHits(hits[:size]), string(next), nil
+}
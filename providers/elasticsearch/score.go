@@ -0,0 +1,73 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+)
+
+// scoreUpdateRetries is how many times Elasticsearch retries a score update
+// that conflicts with a concurrent change of the same document.
+const scoreUpdateRetries = 3
+
+// UpdateScore sets the score field of a stored document with a scripted
+// update, leaving its text and analysis untouched. Unknown IDs are ignored.
+func (p *Provider) UpdateScore(ctx context.Context, key, id string, score float64) error {
+	params := map[string]interface{}{"score": score}
+	if err := p.updateScore(ctx, key, id, "ctx._source.score = params.score", params); err != nil {
+		return fmt.Errorf("failed to update score: %w", err)
+	}
+	return nil
+}
+
+// IncrementScore adds delta to the score field of a stored document with a
+// scripted update. Elasticsearch runs the script on the latest version of the
+// document, retrying it on up to scoreUpdateRetries version conflicts, so
+// concurrent increments that succeed all count. Unknown IDs are ignored.
+func (p *Provider) IncrementScore(ctx context.Context, key, id string, delta float64) error {
+	params := map[string]interface{}{"delta": delta}
+	if err := p.updateScore(ctx, key, id, "ctx._source.score += params.delta", params); err != nil {
+		return fmt.Errorf("failed to increment score: %w", err)
+	}
+	return nil
+}
+
+// updateScore runs a painless script with the given params on a stored
+// document. A missing document is not an error.
+func (p *Provider) updateScore(ctx context.Context, key, id, source string, params map[string]interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"script": map[string]interface{}{
+			"source": source,
+			"lang":   "painless",
+			"params": params,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode script: %w", err)
+	}
+
+	retries := scoreUpdateRetries
+	req := esapi.UpdateRequest{
+		Index:           p.index,
+		DocumentID:      generateDocumentID(key, id),
+		Body:            bytes.NewReader(body),
+		Refresh:         p.refreshPolicy,
+		RetryOnConflict: &retries,
+	}
+
+	res, err := req.Do(ctx, p.client)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	// 404 means the document does not exist, which is ignored
+	const httpNotFound = 404
+	if res.IsError() && res.StatusCode != httpNotFound {
+		return fmt.Errorf("%s", res.String())
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+)
+
+// pointInTimeResponse represents the Elasticsearch open point-in-time response.
+type pointInTimeResponse struct {
+	ID string `json:"id"`
+}
+
+// OpenSnapshot opens an Elasticsearch point in time on the provider's index.
+// Searches that reference it see the index as it was when it was opened.
+func (p *Provider) OpenSnapshot(ctx context.Context, key string, keepAlive time.Duration) (string, error) {
+	req := esapi.OpenPointInTimeRequest{
+		Index:     []string{p.index},
+		KeepAlive: formatKeepAlive(keepAlive),
+	}
+
+	res, err := req.Do(ctx, p.client)
+	if err != nil {
+		return "", fmt.Errorf("failed to open point in time: %w", err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.IsError() {
+		return "", fmt.Errorf("failed to open point in time: %s", res.String())
+	}
+
+	var response pointInTimeResponse
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return response.ID, nil
+}
+
+// CloseSnapshot closes an Elasticsearch point in time.
+func (p *Provider) CloseSnapshot(ctx context.Context, snapshotID string) error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(map[string]string{"id": snapshotID}); err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req := esapi.ClosePointInTimeRequest{
+		Body: &buf,
+	}
+
+	res, err := req.Do(ctx, p.client)
+	if err != nil {
+		return fmt.Errorf("failed to close point in time: %w", err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	// 404 means the point in time already expired
+	const httpNotFound = 404
+	if res.IsError() && res.StatusCode != httpNotFound {
+		return fmt.Errorf("failed to close point in time: %s", res.String())
+	}
+
+	return nil
+}
+
+// pointInTime builds the "pit" clause of a search request.
+func pointInTime(snapshotID string, keepAlive time.Duration) map[string]interface{} {
+	pit := map[string]interface{}{"id": snapshotID}
+	if keepAlive > 0 {
+		pit["keep_alive"] = formatKeepAlive(keepAlive)
+	}
+	return pit
+}
+
+// formatKeepAlive converts a duration into an Elasticsearch time unit string.
+func formatKeepAlive(keepAlive time.Duration) string {
+	const minKeepAlive = time.Second
+	if keepAlive < minKeepAlive {
+		keepAlive = minKeepAlive
+	}
+	return fmt.Sprintf("%ds", int(keepAlive/time.Second))
+}
@@ -0,0 +1,67 @@
+// Package etcd implements the autocomplete Provider interface using etcd,
+// for small datasets shared by control-plane services that have etcd but no
+// other datastore. It supports the MatchPrefix and MatchNGram strategies.
+package etcd
+
+import (
+	"crypto/tls"
+	"time"
+)
+
+const (
+	// defaultKeyPrefix is the key prefix used when Config.KeyPrefix is empty.
+	defaultKeyPrefix = "autocomplete/"
+
+	// defaultDialTimeout is the dial timeout used when Config.DialTimeout is zero.
+	defaultDialTimeout = 5 * time.Second
+
+	// defaultMaxTxnOps matches etcd's default --max-txn-ops.
+	defaultMaxTxnOps = 128
+)
+
+// Config holds etcd connection parameters and provider-specific options.
+type Config struct {
+	// Endpoints are the etcd cluster members, e.g. "10.0.0.1:2379".
+	// Default: ["127.0.0.1:2379"]
+	Endpoints []string
+
+	// Username and Password authenticate with etcd's role-based access control
+	// when Username is set.
+	Username string
+	Password string
+
+	// TLSConfig, when set, connects to the cluster with TLS. Nil connects
+	// without TLS.
+	TLSConfig *tls.Config
+
+	// DialTimeout is the timeout for connecting to the cluster.
+	// Default: 5 seconds
+	DialTimeout time.Duration
+
+	// KeyPrefix prefixes every key the provider writes, keeping its keys apart
+	// from other data in the cluster.
+	// Default: "autocomplete/"
+	KeyPrefix string
+
+	// MaxTxnOps is the most operations the provider puts in one transaction,
+	// which must not exceed the server's --max-txn-ops. Updates of entries with
+	// more tokens are split into several transactions.
+	// Default: 128
+	MaxTxnOps int
+}
+
+// setDefaults applies default values to config fields.
+func (c *Config) setDefaults() {
+	if len(c.Endpoints) == 0 {
+		c.Endpoints = []string{"127.0.0.1:2379"}
+	}
+	if c.DialTimeout == 0 {
+		c.DialTimeout = defaultDialTimeout
+	}
+	if c.KeyPrefix == "" {
+		c.KeyPrefix = defaultKeyPrefix
+	}
+	if c.MaxTxnOps <= 0 {
+		c.MaxTxnOps = defaultMaxTxnOps
+	}
+}
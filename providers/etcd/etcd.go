@@ -0,0 +1,460 @@
+package etcd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+const (
+	// defaultNGramSize is the default n-gram size when not specified in options.
+	defaultNGramSize = 3
+
+	// maxConflictRetries bounds how often Index and Delete retry after a
+	// concurrent update of the same entry.
+	maxConflictRetries = 5
+
+	// Key segments of entry and token keys within a namespace.
+	entrySegment = "e/"
+	tokenSegment = "t/"
+
+	// tokenSeparator ends the token in a token key, so that an exact token
+	// lookup does not also match longer tokens.
+	tokenSeparator = "\x00"
+)
+
+// kv is the subset of the etcd KV API used by the provider.
+type kv interface {
+	Get(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error)
+	Txn(ctx context.Context) clientv3.Txn
+}
+
+// Provider implements the autocomplete Provider interface using etcd.
+// Each namespace is a key prefix. An entry is stored as one entry key plus one
+// token key per token, "<namespace>/t/<token>\x00<id>", so that prefix and
+// n-gram lookups are range reads. Token values carry the display and score,
+// so queries need no second round trip.
+// All methods are safe for concurrent use.
+type Provider struct {
+	client    *clientv3.Client
+	kv        kv
+	keyPrefix string
+	maxTxnOps int
+}
+
+// storedEntry is the value of an entry key, holding what is needed to
+// recompute its tokens when it is updated or deleted.
+type storedEntry struct {
+	Text        string  `json:"text"`
+	SearchText  string  `json:"search_text"`
+	Display     string  `json:"display"`
+	Score       float64 `json:"score"`
+	Strategy    int     `json:"strategy"`
+	NGramSize   int     `json:"ngram_size"`
+	ContentHash string  `json:"content_hash,omitempty"`
+}
+
+// storedToken is the value of a token key.
+type storedToken struct {
+	ID       string  `json:"id"`
+	Display  string  `json:"display"`
+	Score    float64 `json:"score"`
+	Position int     `json:"position"`
+	Length   int     `json:"length"`
+}
+
+// match is a candidate result with the data used to rank it.
+type match struct {
+	result   providers.ProviderResult
+	position int
+	length   int
+}
+
+// New creates a new etcd provider with the given configuration.
+// It connects to the cluster and checks that it is reachable.
+func New(config Config) (*Provider, error) {
+	config.setDefaults()
+
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   config.Endpoints,
+		Username:    config.Username,
+		Password:    config.Password, // pragma: allowlist secret
+		TLS:         config.TLSConfig,
+		DialTimeout: config.DialTimeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create etcd client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.DialTimeout)
+	defer cancel()
+	if _, err := client.Get(ctx, config.KeyPrefix, clientv3.WithCountOnly()); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("failed to connect to etcd: %w", err)
+	}
+
+	p := newProvider(client, config)
+	p.client = client
+	return p, nil
+}
+
+// newProvider creates a provider on a KV client; config must have its defaults applied.
+func newProvider(client kv, config Config) *Provider {
+	return &Provider{kv: client, keyPrefix: config.KeyPrefix, maxTxnOps: config.MaxTxnOps}
+}
+
+// Index adds or updates an entry.
+// The writes go in one transaction, conditional on the entry not having
+// changed since it was read, and are retried if it has. Updates needing more
+// than MaxTxnOps operations are split into several transactions, ordered so
+// that an interrupted Index can be retried and never leaves token keys that
+// the stored entry does not account for: stale token keys are deleted first,
+// then the entry key is written, then the new token keys.
+func (p *Provider) Index(ctx context.Context, key, id, text, display string, options providers.IndexOptions) error {
+	searchText := providers.SearchText(text, options.CaseSensitive)
+	n := getNGramSizeOrDefault(options.NGramSize)
+	newTokens, err := tokenize(searchText, options.MatchStrategy, n)
+	if err != nil {
+		return err
+	}
+
+	value, err := json.Marshal(storedEntry{
+		Text:        text,
+		SearchText:  searchText,
+		Display:     display,
+		Score:       options.Score,
+		Strategy:    int(options.MatchStrategy),
+		NGramSize:   n,
+		ContentHash: options.ContentHash,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal entry: %w", err)
+	}
+
+	var tokenOps []clientv3.Op
+	for tok, position := range newTokens {
+		tokenValue, err := json.Marshal(storedToken{
+			ID: id, Display: display, Score: options.Score, Position: position, Length: len(text),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to marshal token: %w", err)
+		}
+		tokenOps = append(tokenOps, clientv3.OpPut(p.tokenKey(key, tok, id), string(tokenValue)))
+	}
+
+	for attempt := 0; attempt < maxConflictRetries; attempt++ {
+		old, revision, err := p.getEntry(ctx, key, id)
+		if err != nil {
+			return fmt.Errorf("failed to read existing entry: %w", err)
+		}
+
+		var ops []clientv3.Op
+		if revision != 0 {
+			oldTokens, err := tokenize(old.SearchText, providers.MatchStrategy(old.Strategy), old.NGramSize)
+			if err != nil {
+				return err
+			}
+			for tok := range oldTokens {
+				if _, kept := newTokens[tok]; !kept {
+					ops = append(ops, clientv3.OpDelete(p.tokenKey(key, tok, id)))
+				}
+			}
+		}
+		ops = append(ops, clientv3.OpPut(p.entryKey(key, id), string(value)))
+		ops = append(ops, tokenOps...)
+
+		committed, err := p.commit(ctx, p.entryKey(key, id), revision, ops)
+		if err != nil {
+			return fmt.Errorf("failed to index entry: %w", err)
+		}
+		if committed {
+			return nil
+		}
+	}
+	return fmt.Errorf("failed to index entry: %q was concurrently updated %d times", id, maxConflictRetries)
+}
+
+// getEntry reads the entry key for id and returns its modification revision,
+// which is zero if the entry does not exist.
+func (p *Provider) getEntry(ctx context.Context, key, id string) (storedEntry, int64, error) {
+	resp, err := p.kv.Get(ctx, p.entryKey(key, id))
+	if err != nil {
+		return storedEntry{}, 0, err
+	}
+	if len(resp.Kvs) == 0 {
+		return storedEntry{}, 0, nil
+	}
+
+	var e storedEntry
+	if err := json.Unmarshal(resp.Kvs[0].Value, &e); err != nil {
+		return storedEntry{}, 0, fmt.Errorf("failed to unmarshal entry: %w", err)
+	}
+	return e, resp.Kvs[0].ModRevision, nil
+}
+
+// commit applies ops in transactions of at most maxTxnOps operations. The first
+// is conditional on entryKey's modification revision still being revision; if
+// it is not, nothing is written and commit reports false.
+func (p *Provider) commit(ctx context.Context, entryKey string, revision int64, ops []clientv3.Op) (bool, error) {
+	for start := 0; start < len(ops); start += p.maxTxnOps {
+		end := min(start+p.maxTxnOps, len(ops))
+		txn := p.kv.Txn(ctx)
+		if start == 0 {
+			txn = txn.If(clientv3.Compare(clientv3.ModRevision(entryKey), "=", revision))
+		}
+		resp, err := txn.Then(ops[start:end]...).Commit()
+		if err != nil {
+			return false, err
+		}
+		if !resp.Succeeded {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// ContentHash returns the stored content hash for an entry and whether it exists.
+func (p *Provider) ContentHash(ctx context.Context, key, id string) (string, bool, error) {
+	e, revision, err := p.getEntry(ctx, key, id)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get content hash: %w", err)
+	}
+	return e.ContentHash, revision != 0, nil
+}
+
+// Query searches for entries matching the given query.
+// Results are ranked by entry score, then match position, then text length.
+func (p *Provider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	searchQuery := providers.SearchText(query, options.CaseSensitive)
+	if searchQuery == "" {
+		return []providers.ProviderResult{}, nil
+	}
+
+	var matches map[string]match
+	var err error
+	n := getNGramSizeOrDefault(options.NGramSize)
+	switch options.MatchStrategy {
+	case providers.MatchPrefix:
+		matches, err = p.lookup(ctx, key, searchQuery)
+	case providers.MatchNGram:
+		grams := nGrams(searchQuery, n)
+		if len(grams) <= 1 {
+			matches, err = p.lookup(ctx, key, searchQuery)
+		} else {
+			matches, err = p.intersect(ctx, key, grams)
+		}
+	default:
+		return nil, unsupportedStrategy(options.MatchStrategy)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query autocomplete: %w", err)
+	}
+
+	return rank(matches, options.MaxResults), nil
+}
+
+// lookup returns the entries with a token starting with prefix, keyed by ID and
+// positioned at the earliest such token.
+func (p *Provider) lookup(ctx context.Context, key, prefix string) (map[string]match, error) {
+	resp, err := p.kv.Get(ctx, p.namespace(key)+tokenSegment+prefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make(map[string]match, len(resp.Kvs))
+	for _, item := range resp.Kvs {
+		var tok storedToken
+		if err := json.Unmarshal(item.Value, &tok); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal token: %w", err)
+		}
+		if existing, seen := matches[tok.ID]; !seen || tok.Position < existing.position {
+			matches[tok.ID] = match{
+				result:   providers.ProviderResult{ID: tok.ID, Display: tok.Display, Score: tok.Score},
+				position: tok.Position,
+				length:   tok.Length,
+			}
+		}
+	}
+	return matches, nil
+}
+
+// intersect returns the entries containing every one of the query's n-grams,
+// positioned at its first n-gram.
+func (p *Provider) intersect(ctx context.Context, key string, grams []string) (map[string]match, error) {
+	matches, err := p.lookup(ctx, key, grams[0]+tokenSeparator)
+	if err != nil {
+		return nil, err
+	}
+	for _, gram := range grams[1:] {
+		if len(matches) == 0 {
+			break
+		}
+		others, err := p.lookup(ctx, key, gram+tokenSeparator)
+		if err != nil {
+			return nil, err
+		}
+		for id := range matches {
+			if _, ok := others[id]; !ok {
+				delete(matches, id)
+			}
+		}
+	}
+	return matches, nil
+}
+
+// rank orders matches by score, position, length, and ID, keeping at most limit.
+func rank(matches map[string]match, limit int) []providers.ProviderResult {
+	sorted := make([]match, 0, len(matches))
+	for _, m := range matches {
+		sorted = append(sorted, m)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.result.Score != b.result.Score {
+			return a.result.Score > b.result.Score
+		}
+		if a.position != b.position {
+			return a.position < b.position
+		}
+		if a.length != b.length {
+			return a.length < b.length
+		}
+		return a.result.ID < b.result.ID
+	})
+
+	if limit > 0 && len(sorted) > limit {
+		sorted = sorted[:limit]
+	}
+	results := make([]providers.ProviderResult, len(sorted))
+	for i, m := range sorted {
+		results[i] = m.result
+	}
+	return results
+}
+
+// Delete removes an entry and its tokens from the index.
+func (p *Provider) Delete(ctx context.Context, key, id string) error {
+	for attempt := 0; attempt < maxConflictRetries; attempt++ {
+		old, revision, err := p.getEntry(ctx, key, id)
+		if err != nil {
+			return fmt.Errorf("failed to read existing entry: %w", err)
+		}
+		if revision == 0 {
+			return nil
+		}
+
+		tokens, err := tokenize(old.SearchText, providers.MatchStrategy(old.Strategy), old.NGramSize)
+		if err != nil {
+			return err
+		}
+		ops := make([]clientv3.Op, 0, len(tokens)+1)
+		for tok := range tokens {
+			ops = append(ops, clientv3.OpDelete(p.tokenKey(key, tok, id)))
+		}
+		// The entry key goes last, so a delete split across transactions can be retried
+		ops = append(ops, clientv3.OpDelete(p.entryKey(key, id)))
+
+		committed, err := p.commit(ctx, p.entryKey(key, id), revision, ops)
+		if err != nil {
+			return fmt.Errorf("failed to delete entry: %w", err)
+		}
+		if committed {
+			return nil
+		}
+	}
+	return fmt.Errorf("failed to delete entry: %q was concurrently updated %d times", id, maxConflictRetries)
+}
+
+// DeleteAll removes every key of the namespace in a single range delete.
+func (p *Provider) DeleteAll(ctx context.Context, key string) error {
+	_, err := p.kv.Txn(ctx).Then(clientv3.OpDelete(p.namespace(key), clientv3.WithPrefix())).Commit()
+	if err != nil {
+		return fmt.Errorf("failed to delete entries: %w", err)
+	}
+	return nil
+}
+
+// Close closes the connection to the cluster.
+func (p *Provider) Close() error {
+	if p.client == nil {
+		return nil
+	}
+	return p.client.Close()
+}
+
+// namespace returns the key prefix of a namespace. The namespace is escaped so
+// that one containing "/" cannot overlap the keys of another.
+func (p *Provider) namespace(key string) string {
+	return p.keyPrefix + url.PathEscape(key) + "/"
+}
+
+func (p *Provider) entryKey(key, id string) string {
+	return p.namespace(key) + entrySegment + id
+}
+
+func (p *Provider) tokenKey(key, tok, id string) string {
+	return p.namespace(key) + tokenSegment + tok + tokenSeparator + id
+}
+
+// tokenize returns the tokens stored for normalized text under the given strategy,
+// mapped to the earliest byte offset at which each occurs.
+// MatchPrefix stores the text itself, since a range read on it matches every
+// prefix. MatchNGram stores each n-gram; queries up to n characters long
+// match the n-grams they begin.
+func tokenize(text string, strategy providers.MatchStrategy, n int) (map[string]int, error) {
+	tokens := make(map[string]int)
+	switch strategy {
+	case providers.MatchPrefix:
+		if text != "" {
+			tokens[text] = 0
+		}
+	case providers.MatchNGram:
+		offsets := runeOffsets(text)
+		for i := len(offsets) - 1 - n; i >= 0; i-- {
+			tokens[text[offsets[i]:offsets[i+n]]] = offsets[i]
+		}
+	default:
+		return nil, unsupportedStrategy(strategy)
+	}
+	return tokens, nil
+}
+
+// nGrams returns the n-grams of s in order, or s itself if it is shorter than n characters.
+func nGrams(s string, n int) []string {
+	offsets := runeOffsets(s)
+	if len(offsets)-1 <= n {
+		return []string{s}
+	}
+	grams := make([]string, 0, len(offsets)-n)
+	for i := 0; i+n < len(offsets); i++ {
+		grams = append(grams, s[offsets[i]:offsets[i+n]])
+	}
+	return grams
+}
+
+// runeOffsets returns the byte offset of each character of s, followed by len(s).
+func runeOffsets(s string) []int {
+	offsets := make([]int, 0, len(s)+1)
+	for i := range s {
+		offsets = append(offsets, i)
+	}
+	return append(offsets, len(s))
+}
+
+// unsupportedStrategy returns the error for strategies this provider cannot serve.
+func unsupportedStrategy(strategy providers.MatchStrategy) error {
+	return fmt.Errorf("match strategy %d is not supported by the etcd provider: use MatchPrefix or MatchNGram", strategy)
+}
+
+func getNGramSizeOrDefault(size int) int {
+	if size <= 0 {
+		return defaultNGramSize
+	}
+	return size
+}
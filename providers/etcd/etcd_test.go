@@ -0,0 +1,326 @@
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+const testKey = "test"
+
+// fakeKV is an in-memory stand-in for etcd serving the reads and transactions
+// used by the provider. Transactions with more than maxTxnOps operations fail,
+// as on a server, and beforeCommit, when set, runs before each transaction.
+type fakeKV struct {
+	items        map[string]*mvccpb.KeyValue
+	revision     int64
+	maxTxnOps    int
+	txns         int
+	beforeCommit func()
+}
+
+func newFakeKV() *fakeKV {
+	return &fakeKV{items: make(map[string]*mvccpb.KeyValue), maxTxnOps: defaultMaxTxnOps}
+}
+
+func (f *fakeKV) Get(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	op := clientv3.OpGet(key, opts...)
+	resp := &clientv3.GetResponse{}
+	for _, k := range f.keys(op) {
+		resp.Kvs = append(resp.Kvs, f.items[k])
+	}
+	resp.Count = int64(len(resp.Kvs))
+	return resp, nil
+}
+
+func (f *fakeKV) Txn(ctx context.Context) clientv3.Txn {
+	return &fakeTxn{kv: f}
+}
+
+// keys returns the stored keys an operation ranges over, in order.
+func (f *fakeKV) keys(op clientv3.Op) []string {
+	start, end := string(op.KeyBytes()), string(op.RangeBytes())
+	var keys []string
+	for k := range f.items {
+		if k == start || (end != "" && k >= start && k < end) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (f *fakeKV) put(key, value string) {
+	f.revision++
+	f.items[key] = &mvccpb.KeyValue{Key: []byte(key), Value: []byte(value), ModRevision: f.revision}
+}
+
+// count returns the number of stored keys starting with prefix.
+func (f *fakeKV) count(prefix string) int {
+	n := 0
+	for k := range f.items {
+		if strings.HasPrefix(k, prefix) {
+			n++
+		}
+	}
+	return n
+}
+
+type fakeTxn struct {
+	kv   *fakeKV
+	cmps []clientv3.Cmp
+	ops  []clientv3.Op
+}
+
+func (t *fakeTxn) If(cs ...clientv3.Cmp) clientv3.Txn {
+	t.cmps = append(t.cmps, cs...)
+	return t
+}
+
+func (t *fakeTxn) Then(ops ...clientv3.Op) clientv3.Txn {
+	t.ops = append(t.ops, ops...)
+	return t
+}
+
+func (t *fakeTxn) Else(ops ...clientv3.Op) clientv3.Txn {
+	return t
+}
+
+func (t *fakeTxn) Commit() (*clientv3.TxnResponse, error) {
+	f := t.kv
+	if f.beforeCommit != nil {
+		f.beforeCommit()
+	}
+	f.txns++
+	if len(t.ops) > f.maxTxnOps {
+		return nil, fmt.Errorf("too many operations in txn request: %d", len(t.ops))
+	}
+
+	for _, cmp := range t.cmps {
+		var current int64
+		if item, ok := f.items[string(cmp.KeyBytes())]; ok {
+			current = item.ModRevision
+		}
+		want := cmp.TargetUnion.(*pb.Compare_ModRevision).ModRevision
+		if cmp.Target != pb.Compare_MOD || cmp.Result != pb.Compare_EQUAL {
+			return nil, fmt.Errorf("unsupported comparison %v", cmp)
+		}
+		if current != want {
+			return &clientv3.TxnResponse{Succeeded: false}, nil
+		}
+	}
+
+	for _, op := range t.ops {
+		switch {
+		case op.IsPut():
+			f.put(string(op.KeyBytes()), string(op.ValueBytes()))
+		case op.IsDelete():
+			for _, k := range f.keys(op) {
+				delete(f.items, k)
+			}
+		}
+	}
+	return &clientv3.TxnResponse{Succeeded: true}, nil
+}
+
+func newTestProvider() (*Provider, *fakeKV) {
+	fake := newFakeKV()
+	config := Config{}
+	config.setDefaults()
+	return newProvider(fake, config), fake
+}
+
+func resultIDs(results []providers.ProviderResult) []string {
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+	return ids
+}
+
+func TestEtcdProvider_MatchStrategies(t *testing.T) {
+	ctx := context.Background()
+	entries := map[string]string{"1": "Mumbai", "2": "Navi Mumbai", "3": "Jammu", "4": "Mumbra"}
+
+	tests := []struct {
+		strategy providers.MatchStrategy
+		query    string
+		want     string
+	}{
+		{providers.MatchPrefix, "mum", "[1 4]"},
+		{providers.MatchPrefix, "navi m", "[2]"},
+		{providers.MatchPrefix, "umb", "[]"},
+		{providers.MatchNGram, "mu", "[1 4 2]"},
+		{providers.MatchNGram, "umba", "[1 2]"},
+		{providers.MatchNGram, "mumbai", "[1 2]"},
+		{providers.MatchNGram, "xyz", "[]"},
+	}
+
+	for _, tt := range tests {
+		provider, _ := newTestProvider()
+		options := providers.IndexOptions{Score: 1.0, MatchStrategy: tt.strategy, NGramSize: 3}
+		for id, text := range entries {
+			if err := provider.Index(ctx, testKey, id, text, text, options); err != nil {
+				t.Fatalf("Index() error = %v", err)
+			}
+		}
+
+		results, err := provider.Query(ctx, testKey, tt.query, providers.QueryOptions{
+			MaxResults:    10,
+			MatchStrategy: tt.strategy,
+			NGramSize:     3,
+		})
+		if err != nil {
+			t.Fatalf("strategy %d: Query(%q) error = %v", tt.strategy, tt.query, err)
+		}
+		if got := fmt.Sprint(resultIDs(results)); got != tt.want {
+			t.Errorf("strategy %d: Query(%q) = %v, want %v", tt.strategy, tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestEtcdProvider_UnsupportedStrategy(t *testing.T) {
+	provider, _ := newTestProvider()
+	ctx := context.Background()
+
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchSubstring}
+	if err := provider.Index(ctx, testKey, "1", "Mumbai", "Mumbai", options); err == nil {
+		t.Error("Index() with MatchSubstring should fail")
+	}
+	if _, err := provider.Query(ctx, testKey, "mum", providers.QueryOptions{MatchStrategy: providers.MatchSubstring}); err == nil {
+		t.Error("Query() with MatchSubstring should fail")
+	}
+}
+
+func TestEtcdProvider_UpdateAndDelete(t *testing.T) {
+	provider, fake := newTestProvider()
+	ctx := context.Background()
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchNGram, NGramSize: 3, ContentHash: "h1"}
+	namespace := provider.namespace(testKey)
+
+	if err := provider.Index(ctx, testKey, "1", "Mumbai", "Mumbai", options); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	options.ContentHash = "h2"
+	if err := provider.Index(ctx, testKey, "1", "Pune", "Pune", options); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+
+	// Only the entry key and the tokens of "pune" remain
+	if got := fake.count(namespace); got != 3 {
+		t.Errorf("namespace has %d keys after update, want 3", got)
+	}
+	hash, exists, err := provider.ContentHash(ctx, testKey, "1")
+	if err != nil || !exists || hash != "h2" {
+		t.Errorf("ContentHash() = %q, %v, %v; want h2, true, nil", hash, exists, err)
+	}
+
+	if err := provider.Delete(ctx, testKey, "1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if got := fake.count(namespace); got != 0 {
+		t.Errorf("namespace has %d keys after delete, want 0", got)
+	}
+	if _, exists, _ := provider.ContentHash(ctx, testKey, "1"); exists {
+		t.Error("ContentHash() should not find a deleted entry")
+	}
+}
+
+func TestEtcdProvider_SplitTransactions(t *testing.T) {
+	provider, fake := newTestProvider()
+	ctx := context.Background()
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchNGram, NGramSize: 3}
+	provider.maxTxnOps = 4
+	fake.maxTxnOps = 4
+
+	// A long text produces more token keys than fit in one transaction
+	text := "Chhatrapati Shivaji Maharaj Terminus"
+	if err := provider.Index(ctx, testKey, "1", text, text, options); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if fake.txns < 2 {
+		t.Errorf("expected writes to be split into transactions, got %d", fake.txns)
+	}
+	results, err := provider.Query(ctx, testKey, "shivaji", providers.QueryOptions{MatchStrategy: providers.MatchNGram, NGramSize: 3})
+	if err != nil || len(results) != 1 {
+		t.Errorf("Query() = %v, %v; want one result", results, err)
+	}
+
+	if err := provider.Delete(ctx, testKey, "1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if got := fake.count(provider.namespace(testKey)); got != 0 {
+		t.Errorf("namespace has %d keys after delete, want 0", got)
+	}
+}
+
+func TestEtcdProvider_ConcurrentUpdate(t *testing.T) {
+	provider, fake := newTestProvider()
+	ctx := context.Background()
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchPrefix}
+
+	if err := provider.Index(ctx, testKey, "1", "Mumbai", "Mumbai", options); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+
+	// Another writer replaces the entry between the read and the write of the
+	// next update, which must then be retried against the new entry
+	concurrent := true
+	fake.beforeCommit = func() {
+		if concurrent {
+			concurrent = false
+			other, _ := newTestProvider()
+			other.kv = fake
+			fake.beforeCommit = nil
+			if err := other.Index(ctx, testKey, "1", "Mumbra", "Mumbra", options); err != nil {
+				t.Errorf("concurrent Index() error = %v", err)
+			}
+		}
+	}
+	if err := provider.Index(ctx, testKey, "1", "Pune", "Pune", options); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+
+	// Neither "mumbai" nor "mumbra" is left behind
+	if got := fake.count(provider.namespace(testKey)); got != 2 {
+		t.Errorf("namespace has %d keys, want 2", got)
+	}
+	results, _ := provider.Query(ctx, testKey, "mum", providers.QueryOptions{MatchStrategy: providers.MatchPrefix})
+	if len(results) != 0 {
+		t.Errorf("Query(mum) = %v, want no results", results)
+	}
+}
+
+func TestEtcdProvider_DeleteAll(t *testing.T) {
+	provider, fake := newTestProvider()
+	ctx := context.Background()
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchPrefix}
+
+	// "test/t" would share the key prefix of "test" if namespaces were not escaped
+	for _, key := range []string{testKey, testKey + "/t", "other"} {
+		if err := provider.Index(ctx, key, "1", "Mumbai", "Mumbai", options); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	if err := provider.DeleteAll(ctx, testKey); err != nil {
+		t.Fatalf("DeleteAll() error = %v", err)
+	}
+	if got := fake.count(provider.namespace(testKey)); got != 0 {
+		t.Errorf("namespace has %d keys after DeleteAll, want 0", got)
+	}
+	for _, key := range []string{testKey + "/t", "other"} {
+		results, err := provider.Query(ctx, key, "mum", providers.QueryOptions{MatchStrategy: providers.MatchPrefix})
+		if err != nil || len(results) != 1 {
+			t.Errorf("DeleteAll() should not affect namespace %q: Query() = %v, %v", key, results, err)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package etcd
+
+import (
+	"fmt"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// init registers the etcd provider. Import this package with a blank identifier
+// to use etcd as the autocomplete backend:
+//
+//	import _ "github.com/remiges-tech/autocomplete/providers/etcd"
+//
+//nolint:gochecknoinits // init() is the idiomatic pattern for provider registration
+func init() {
+	autocomplete.RegisterProvider("etcd", NewProvider)
+}
+
+// NewProvider creates a new etcd provider from the given configuration.
+// It implements ProviderFactory and expects config to be of type etcd.Config.
+func NewProvider(config interface{}) (providers.Provider, error) {
+	etcdConfig, ok := config.(Config)
+	if !ok {
+		return nil, fmt.Errorf("invalid configuration type for etcd provider: expected etcd.Config, got %T", config)
+	}
+
+	return New(etcdConfig)
+}
@@ -0,0 +1,57 @@
+// Package failover implements the autocomplete Provider interface over a
+// primary and a secondary provider, such as Elasticsearch backed by Redis.
+// Every write goes to both, and queries the primary fails or does not answer
+// in time are answered by the secondary, keeping suggestions available
+// during maintenance windows of either backend.
+package failover
+
+import (
+	"time"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+const (
+	// defaultTimeout is the primary query timeout used when Config.Timeout is zero.
+	defaultTimeout = time.Second
+
+	// defaultRetryInterval is the interval used when Config.RetryInterval is zero.
+	defaultRetryInterval = 30 * time.Second
+)
+
+// Config holds the providers and failover options of a failover provider.
+type Config struct {
+	// Primary is the provider queries are normally answered by.
+	// The failover provider takes ownership of it and closes it on Close.
+	Primary providers.Provider
+
+	// Secondary is the provider queries fail over to. It receives every write,
+	// so it holds the same entries as Primary.
+	// The failover provider takes ownership of it and closes it on Close.
+	Secondary providers.Provider
+
+	// Timeout bounds how long a query waits for the primary provider before
+	// failing over. Negative disables the timeout.
+	// Default: 1 second
+	Timeout time.Duration
+
+	// RetryInterval is how long queries go straight to the secondary provider
+	// after the primary fails one, sparing them the wait for a backend that is
+	// down. Negative tries the primary on every query.
+	// Default: 30 seconds
+	RetryInterval time.Duration
+
+	// OnFailover, if set, is called with the primary's error when queries
+	// start failing over to the secondary provider.
+	OnFailover func(err error)
+}
+
+// setDefaults applies default values to config fields.
+func (c *Config) setDefaults() {
+	if c.Timeout == 0 {
+		c.Timeout = defaultTimeout
+	}
+	if c.RetryInterval == 0 {
+		c.RetryInterval = defaultRetryInterval
+	}
+}
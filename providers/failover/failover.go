@@ -0,0 +1,144 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// Provider implements the autocomplete Provider interface over a primary and
+// a secondary provider. Writes go to both providers and fail if either does,
+// so a failed write can be retried until both hold it. Queries fall back to
+// the secondary provider when the primary returns an error or times out.
+// All methods are safe for concurrent use.
+type Provider struct {
+	primary       providers.Provider
+	secondary     providers.Provider
+	timeout       time.Duration
+	retryInterval time.Duration
+	onFailover    func(err error)
+
+	mu sync.Mutex
+	// failing is set while queries fail over, from a primary failure until
+	// the primary answers a query again.
+	failing bool
+	// retryAt is when queries may try the primary provider again.
+	retryAt time.Time
+}
+
+// New creates a new failover provider over config.Primary and config.Secondary.
+func New(config Config) (*Provider, error) {
+	if config.Primary == nil || config.Secondary == nil {
+		return nil, errors.New("primary and secondary providers are required")
+	}
+	config.setDefaults()
+
+	return &Provider{
+		primary:       config.Primary,
+		secondary:     config.Secondary,
+		timeout:       config.Timeout,
+		retryInterval: config.RetryInterval,
+		onFailover:    config.OnFailover,
+	}, nil
+}
+
+// Index writes an entry to both providers.
+func (p *Provider) Index(ctx context.Context, key, id, text, display string, options providers.IndexOptions) error {
+	return p.writeBoth(func(provider providers.Provider) error {
+		return provider.Index(ctx, key, id, text, display, options)
+	})
+}
+
+// Query searches the primary provider, or the secondary one if the primary
+// fails, times out, or failed another query within the retry interval.
+// Queries whose context is done fail without failing over.
+func (p *Provider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	if p.primaryAvailable() {
+		results, err := p.queryPrimary(ctx, key, query, options)
+		if err == nil {
+			p.primarySucceeded()
+			return results, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		p.primaryFailed(err)
+	}
+	return p.secondary.Query(ctx, key, query, options)
+}
+
+// queryPrimary queries the primary provider within the timeout.
+func (p *Provider) queryPrimary(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+	return p.primary.Query(ctx, key, query, options)
+}
+
+// Delete removes an entry from both providers.
+func (p *Provider) Delete(ctx context.Context, key, id string) error {
+	return p.writeBoth(func(provider providers.Provider) error {
+		return provider.Delete(ctx, key, id)
+	})
+}
+
+// DeleteAll removes all entries for a given key from both providers.
+func (p *Provider) DeleteAll(ctx context.Context, key string) error {
+	return p.writeBoth(func(provider providers.Provider) error {
+		return provider.DeleteAll(ctx, key)
+	})
+}
+
+// Close closes both providers.
+func (p *Provider) Close() error {
+	return errors.Join(p.primary.Close(), p.secondary.Close())
+}
+
+// writeBoth applies a write to the primary and then the secondary provider.
+// The secondary is written even if the primary fails, so it stays complete
+// while the primary is down.
+func (p *Provider) writeBoth(write func(provider providers.Provider) error) error {
+	var errs []error
+	if err := write(p.primary); err != nil {
+		errs = append(errs, fmt.Errorf("primary provider: %w", err))
+	}
+	if err := write(p.secondary); err != nil {
+		errs = append(errs, fmt.Errorf("secondary provider: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// primaryAvailable reports whether queries may try the primary provider.
+func (p *Provider) primaryAvailable() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return !time.Now().Before(p.retryAt)
+}
+
+// primarySucceeded records that the primary provider answered a query.
+func (p *Provider) primarySucceeded() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.failing = false
+}
+
+// primaryFailed records a failed primary query, reporting the start of a failover.
+func (p *Provider) primaryFailed(err error) {
+	p.mu.Lock()
+	started := !p.failing
+	p.failing = true
+	if p.retryInterval > 0 {
+		p.retryAt = time.Now().Add(p.retryInterval)
+	}
+	p.mu.Unlock()
+
+	if started && p.onFailover != nil {
+		p.onFailover(err)
+	}
+}
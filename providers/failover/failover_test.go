@@ -0,0 +1,210 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers"
+	"github.com/remiges-tech/autocomplete/providers/memory"
+	"github.com/remiges-tech/autocomplete/stress"
+)
+
+const testKey = "test"
+
+var errDown = errors.New("backend down")
+
+// flakyProvider is an in-memory provider that can be made to fail or stall.
+type flakyProvider struct {
+	*memory.Provider
+	down    atomic.Bool
+	stalled atomic.Bool
+	queries atomic.Int64
+}
+
+func newFlakyProvider() *flakyProvider {
+	provider, _ := memory.New(memory.Config{})
+	return &flakyProvider{Provider: provider}
+}
+
+func (f *flakyProvider) Index(ctx context.Context, key, id, text, display string, options providers.IndexOptions) error {
+	if f.down.Load() {
+		return errDown
+	}
+	return f.Provider.Index(ctx, key, id, text, display, options)
+}
+
+func (f *flakyProvider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	f.queries.Add(1)
+	if f.stalled.Load() {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if f.down.Load() {
+		return nil, errDown
+	}
+	return f.Provider.Query(ctx, key, query, options)
+}
+
+func index(provider providers.Provider, id, text string) error {
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchPrefix}
+	return provider.Index(context.Background(), testKey, id, text, text, options)
+}
+
+func queryIDs(ctx context.Context, provider providers.Provider, query string) (string, error) {
+	results, err := provider.Query(ctx, testKey, query, providers.QueryOptions{MaxResults: 10, MatchStrategy: providers.MatchPrefix})
+	if err != nil {
+		return "", err
+	}
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+	return fmt.Sprint(ids), nil
+}
+
+func TestFailoverProvider_QueriesFailOver(t *testing.T) {
+	primary, secondary := newFlakyProvider(), newFlakyProvider()
+	var failovers []error
+	provider, err := New(Config{
+		Primary:       primary,
+		Secondary:     secondary,
+		Timeout:       20 * time.Millisecond,
+		RetryInterval: -1,
+		OnFailover:    func(err error) { failovers = append(failovers, err) },
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := index(provider, "1", "Mumbai"); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+
+	ctx := context.Background()
+	for _, fail := range []func(){
+		func() { primary.down.Store(true) },
+		func() { primary.down.Store(false); primary.stalled.Store(true) },
+	} {
+		fail()
+		if got, err := queryIDs(ctx, provider, "mum"); err != nil || got != "[1]" {
+			t.Errorf("Query() = %v, %v; want [1] from the secondary provider", got, err)
+		}
+	}
+	if secondary.queries.Load() != 2 {
+		t.Errorf("secondary queries = %d, want 2", secondary.queries.Load())
+	}
+	if len(failovers) != 1 || !errors.Is(failovers[0], errDown) {
+		t.Errorf("OnFailover calls = %v, want one for the first failure", failovers)
+	}
+
+	// A recovered primary answers again, and a later failure is reported again
+	primary.stalled.Store(false)
+	if got, err := queryIDs(ctx, provider, "mum"); err != nil || got != "[1]" {
+		t.Errorf("Query() after recovery = %v, %v; want [1]", got, err)
+	}
+	if secondary.queries.Load() != 2 {
+		t.Error("Query() after recovery should be answered by the primary provider")
+	}
+	primary.down.Store(true)
+	_, _ = queryIDs(ctx, provider, "mum")
+	if len(failovers) != 2 {
+		t.Errorf("OnFailover calls = %d, want 2 after the primary failed again", len(failovers))
+	}
+
+	// A cancelled query fails rather than failing over
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := queryIDs(cancelled, provider, "mum"); err == nil {
+		t.Error("Query() with a cancelled context should fail")
+	}
+}
+
+func TestFailoverProvider_RetryInterval(t *testing.T) {
+	primary, secondary := newFlakyProvider(), newFlakyProvider()
+	provider, err := New(Config{Primary: primary, Secondary: secondary, RetryInterval: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx := context.Background()
+	primary.down.Store(true)
+	for i := 0; i < 3; i++ {
+		if _, err := queryIDs(ctx, provider, "mum"); err != nil {
+			t.Fatalf("Query() error = %v", err)
+		}
+	}
+	if primary.queries.Load() != 1 {
+		t.Errorf("primary queries = %d, want 1 within the retry interval", primary.queries.Load())
+	}
+
+	primary.down.Store(false)
+	time.Sleep(60 * time.Millisecond)
+	if _, err := queryIDs(ctx, provider, "mum"); err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if primary.queries.Load() != 2 {
+		t.Errorf("primary queries = %d, want the primary retried after the interval", primary.queries.Load())
+	}
+}
+
+func TestFailoverProvider_WritesToBoth(t *testing.T) {
+	primary, secondary := newFlakyProvider(), newFlakyProvider()
+	provider, err := New(Config{Primary: primary, Secondary: secondary})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx := context.Background()
+	primary.down.Store(true)
+	if err := index(provider, "1", "Mumbai"); !errors.Is(err, errDown) {
+		t.Errorf("Index() error = %v, want the primary's error", err)
+	}
+	if got, _ := queryIDs(ctx, secondary.Provider, "mum"); got != "[1]" {
+		t.Errorf("secondary entries = %v, want the entry written despite the primary failing", got)
+	}
+
+	primary.down.Store(false)
+	if err := index(provider, "1", "Mumbai"); err != nil {
+		t.Fatalf("Index() retry error = %v", err)
+	}
+	if err := provider.Delete(ctx, testKey, "1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	for name, p := range map[string]*flakyProvider{"primary": primary, "secondary": secondary} {
+		if got, _ := queryIDs(ctx, p.Provider, "mum"); got != "[]" {
+			t.Errorf("%s entries after Delete() = %v, want []", name, got)
+		}
+	}
+}
+
+func TestNewRequiresBothProviders(t *testing.T) {
+	primary, _ := memory.New(memory.Config{})
+	if _, err := New(Config{Primary: primary}); err == nil {
+		t.Error("New() should require a secondary provider")
+	}
+}
+
+func TestFailoverProvider_Stress(t *testing.T) {
+	for _, strategy := range []autocomplete.MatchStrategy{autocomplete.MatchPrefix, autocomplete.MatchNGram, autocomplete.MatchSubstring} {
+		t.Run(strategy.String(), func(t *testing.T) {
+			options := autocomplete.DefaultOptions()
+			options.MatchStrategy = strategy
+			primary, _ := memory.New(memory.Config{})
+			secondary, _ := memory.New(memory.Config{})
+			config := Config{Primary: primary, Secondary: secondary}
+			ac, err := autocomplete.New("failover", autocomplete.NewConfigWithOptions(config, options))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			defer func() { _ = ac.Close() }()
+
+			if err := stress.Run(context.Background(), ac, stress.Config{}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package failover
+
+import (
+	"fmt"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// init registers the failover provider. Import this package with a blank identifier
+// to fail over between a primary and a secondary provider:
+//
+//	import _ "github.com/remiges-tech/autocomplete/providers/failover"
+//
+//nolint:gochecknoinits // init() is the idiomatic pattern for provider registration
+func init() {
+	autocomplete.RegisterProvider("failover", NewProvider)
+}
+
+// NewProvider creates a new failover provider from the given configuration.
+// It implements ProviderFactory and expects config to be of type failover.Config.
+func NewProvider(config interface{}) (providers.Provider, error) {
+	failoverConfig, ok := config.(Config)
+	if !ok {
+		return nil, fmt.Errorf("invalid configuration type for failover provider: expected failover.Config, got %T", config)
+	}
+
+	return New(failoverConfig)
+}
@@ -0,0 +1,35 @@
+// Package filestore implements the autocomplete Provider interface as an
+// in-process index persisted to a directory. Every write is appended to a log
+// file, and the log is periodically folded into a compressed snapshot of all
+// entries, so a restarted process loads its index from disk and serves queries
+// immediately, without reindexing from the source data.
+package filestore
+
+// defaultCompactAfter is the number of log records used when Config.CompactAfter is zero.
+const defaultCompactAfter = 10000
+
+// Config holds the location and durability options of a filestore provider.
+type Config struct {
+	// Dir is the directory holding the snapshot and log files, created if it
+	// does not exist. Only one provider may use a directory at a time.
+	Dir string
+
+	// CompactAfter is the number of writes appended to the log after which the
+	// log is folded into a new snapshot. Negative disables automatic compaction;
+	// snapshots are then written only by Compact and Close.
+	// Default: 10000
+	CompactAfter int
+
+	// NoSync skips fsync after each write, trading durability for write
+	// throughput; a crash can then lose the most recent writes, but never
+	// leaves the index inconsistent. Intended for bulk loads.
+	// Default: false
+	NoSync bool
+}
+
+// setDefaults applies default values to config fields.
+func (c *Config) setDefaults() {
+	if c.CompactAfter == 0 {
+		c.CompactAfter = defaultCompactAfter
+	}
+}
@@ -0,0 +1,258 @@
+package filestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/remiges-tech/autocomplete/providers"
+	"github.com/remiges-tech/autocomplete/providers/memory"
+)
+
+// errClosed is returned by writes after Close.
+var errClosed = errors.New("filestore provider is closed")
+
+// Provider implements the autocomplete Provider interface with an in-memory
+// index whose writes are logged to disk. Queries are answered by the in-memory
+// index alone and never touch the disk.
+// All methods are safe for concurrent use.
+type Provider struct {
+	index        *memory.Provider
+	dir          string
+	compactAfter int
+	noSync       bool
+
+	// mu serializes writes, so that the log holds them in the order they were
+	// applied to the index.
+	mu sync.Mutex
+
+	// options holds the index options of every entry, which the in-memory
+	// index does not list, so that snapshots can restore them.
+	options map[string]map[string]providers.IndexOptions
+
+	log        *os.File
+	logSize    int64
+	logRecords int
+	closed     bool
+}
+
+// New opens the directory in config.Dir, loading the entries persisted by a
+// previous provider, or creates it.
+func New(config Config) (*Provider, error) {
+	if config.Dir == "" {
+		return nil, errors.New("filestore directory is required")
+	}
+	config.setDefaults()
+	if err := os.MkdirAll(config.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	index, err := memory.New(memory.Config{})
+	if err != nil {
+		return nil, err
+	}
+	p := &Provider{
+		index:        index,
+		dir:          config.Dir,
+		compactAfter: config.CompactAfter,
+		noSync:       config.NoSync,
+		options:      make(map[string]map[string]providers.IndexOptions),
+	}
+
+	ctx := context.Background()
+	if err := p.loadSnapshot(ctx); err != nil {
+		return nil, err
+	}
+	if err := p.openLog(ctx); err != nil {
+		return nil, err
+	}
+	if p.compactAfter > 0 && p.logRecords >= p.compactAfter {
+		if err := p.compact(ctx); err != nil {
+			_ = p.log.Close()
+			return nil, fmt.Errorf("failed to compact: %w", err)
+		}
+	}
+	return p, nil
+}
+
+// Index adds or updates an entry.
+func (p *Provider) Index(ctx context.Context, key, id, text, display string, options providers.IndexOptions) error {
+	return p.write(ctx, record{
+		Op:      opIndex,
+		Key:     key,
+		Entries: []entryRecord{{ID: id, Text: text, Display: display, Options: options}},
+	})
+}
+
+// IndexAtomic writes all entries as a single log record, so that after a crash
+// either all or none of them are restored.
+func (p *Provider) IndexAtomic(ctx context.Context, key string, entries []providers.IndexEntry) error {
+	r := record{Op: opIndex, Key: key, Entries: make([]entryRecord, len(entries))}
+	for i, e := range entries {
+		r.Entries[i] = entryRecord{ID: e.ID, Text: e.Text, Display: e.Display, Options: e.Options}
+	}
+	return p.write(ctx, r)
+}
+
+// Query searches the in-memory index.
+func (p *Provider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	return p.index.Query(ctx, key, query, options)
+}
+
+// Delete removes an entry from the index.
+func (p *Provider) Delete(ctx context.Context, key, id string) error {
+	return p.write(ctx, record{Op: opDelete, Key: key, ID: id})
+}
+
+// DeleteAll removes all entries for a given key.
+func (p *Provider) DeleteAll(ctx context.Context, key string) error {
+	return p.write(ctx, record{Op: opDeleteAll, Key: key})
+}
+
+// ContentHash returns the stored content hash for an entry and whether it exists.
+func (p *Provider) ContentHash(ctx context.Context, key, id string) (string, bool, error) {
+	return p.index.ContentHash(ctx, key, id)
+}
+
+// CountEntries returns the number of entries in the namespace.
+func (p *Provider) CountEntries(ctx context.Context, key string) (int64, error) {
+	return p.index.CountEntries(ctx, key)
+}
+
+// IndexStats returns the size of the namespace in the in-memory index, with
+// the size of the write log, which all namespaces share, in Details.
+func (p *Provider) IndexStats(ctx context.Context, key string) (providers.IndexStats, error) {
+	stats, err := p.index.IndexStats(ctx, key)
+	if err != nil {
+		return stats, err
+	}
+	p.mu.Lock()
+	stats.Details["log_bytes"] = strconv.FormatInt(p.logSize, 10)
+	stats.Details["log_records"] = strconv.Itoa(p.logRecords)
+	p.mu.Unlock()
+	return stats, nil
+}
+
+// GetEntry returns a stored entry and whether it exists.
+func (p *Provider) GetEntry(ctx context.Context, key, id string) (providers.StoredEntry, bool, error) {
+	return p.index.GetEntry(ctx, key, id)
+}
+
+// ListEntries returns up to count entries of the namespace in ID order,
+// starting after the ID given as cursor.
+func (p *Provider) ListEntries(ctx context.Context, key, cursor string, count int) ([]providers.StoredEntry, string, error) {
+	return p.index.ListEntries(ctx, key, cursor, count)
+}
+
+// ListChanges returns the entries indexed and deleted since cursor. Cursors
+// issued before the provider was opened list every entry.
+func (p *Provider) ListChanges(ctx context.Context, key, cursor string) (providers.ChangeSet, error) {
+	return p.index.ListChanges(ctx, key, cursor)
+}
+
+// SupportsMatchStrategies reports that entries can be indexed under any
+// combination of strategies.
+func (p *Provider) SupportsMatchStrategies(strategies []providers.MatchStrategy) bool {
+	return p.index.SupportsMatchStrategies(strategies)
+}
+
+// Compact writes a snapshot of all entries and empties the log, so that the
+// next New loads the snapshot alone.
+func (p *Provider) Compact(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return errClosed
+	}
+	if err := p.compact(ctx); err != nil {
+		return fmt.Errorf("failed to compact: %w", err)
+	}
+	return nil
+}
+
+// Close writes a snapshot of all entries, so that the next New starts
+// quickly, and closes the log. Further writes fail.
+func (p *Provider) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+
+	var errs []error
+	if err := p.compact(context.Background()); err != nil {
+		errs = append(errs, fmt.Errorf("failed to compact: %w", err))
+	}
+	if err := p.log.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to close log: %w", err))
+	}
+	errs = append(errs, p.index.Close())
+	return errors.Join(errs...)
+}
+
+// write logs a write and applies it to the index. A failed compaction leaves
+// the write in the log and is retried after the next write.
+func (p *Provider) write(ctx context.Context, r record) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return errClosed
+	}
+	if err := p.appendLog(r); err != nil {
+		return err
+	}
+	if err := p.apply(ctx, r); err != nil {
+		return err
+	}
+	if p.compactAfter > 0 && p.logRecords >= p.compactAfter {
+		_ = p.compact(ctx)
+	}
+	return nil
+}
+
+// apply applies a logged write to the index. The caller must hold mu or be
+// loading the index in New.
+func (p *Provider) apply(ctx context.Context, r record) error {
+	switch r.Op {
+	case opIndex:
+		options := p.options[r.Key]
+		if options == nil {
+			options = make(map[string]providers.IndexOptions)
+			p.options[r.Key] = options
+		}
+		entries := make([]providers.IndexEntry, len(r.Entries))
+		for i, e := range r.Entries {
+			entries[i] = providers.IndexEntry{ID: e.ID, Text: e.Text, Display: e.Display, Options: e.Options}
+			options[e.ID] = e.Options
+		}
+		return p.index.IndexAtomic(ctx, r.Key, entries)
+	case opDelete:
+		delete(p.options[r.Key], r.ID)
+		return p.index.Delete(ctx, r.Key, r.ID)
+	case opDeleteAll:
+		delete(p.options, r.Key)
+		return p.index.DeleteAll(ctx, r.Key)
+	default:
+		return fmt.Errorf("unknown record operation %q", r.Op)
+	}
+}
+
+// keys returns the namespaces holding entries in sorted order.
+func (p *Provider) keys() []string {
+	keys := make([]string, 0, len(p.options))
+	for key, options := range p.options {
+		if len(options) > 0 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
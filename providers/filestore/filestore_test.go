@@ -0,0 +1,198 @@
+package filestore
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers"
+	"github.com/remiges-tech/autocomplete/stress"
+)
+
+const testKey = "test"
+
+func openProvider(t *testing.T, config Config) *Provider {
+	t.Helper()
+	provider, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return provider
+}
+
+// crash abandons a provider without writing a snapshot, as a process crash would.
+func crash(provider *Provider) {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	provider.closed = true
+	_ = provider.log.Close()
+}
+
+func queryIDs(t *testing.T, provider *Provider, query string, strategy providers.MatchStrategy) string {
+	t.Helper()
+	results, err := provider.Query(context.Background(), testKey, query, providers.QueryOptions{MaxResults: 10, MatchStrategy: strategy})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+	return fmt.Sprint(ids)
+}
+
+func TestFilestoreProvider_RestoresEntries(t *testing.T) {
+	ctx := context.Background()
+	for name, restart := range map[string]func(*Provider) error{
+		"snapshot": func(p *Provider) error { return p.Close() },
+		"log":      func(p *Provider) error { crash(p); return nil },
+	} {
+		t.Run(name, func(t *testing.T) {
+			config := Config{Dir: t.TempDir()}
+			provider := openProvider(t, config)
+			prefix := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchPrefix, ContentHash: "h1"}
+			substring := providers.IndexOptions{Score: 2.0, MatchStrategy: providers.MatchSubstring}
+			steps := []error{
+				provider.Index(ctx, testKey, "1", "mumbai", "Mumbai", prefix),
+				provider.Index(ctx, testKey, "2", "navi mumbai", "Navi Mumbai", substring),
+				provider.IndexAtomic(ctx, testKey, []providers.IndexEntry{
+					{ID: "3", Text: "mumbra", Display: "Mumbra", Options: prefix},
+					{ID: "4", Text: "pune", Display: "Pune", Options: prefix},
+				}),
+				provider.Delete(ctx, testKey, "4"),
+				provider.Index(ctx, "other", "5", "mulund", "Mulund", prefix),
+				provider.DeleteAll(ctx, "other"),
+			}
+			for i, err := range steps {
+				if err != nil {
+					t.Fatalf("write %d error = %v", i, err)
+				}
+			}
+			if err := restart(provider); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			reopened := openProvider(t, config)
+			defer func() { _ = reopened.Close() }()
+			// Entry 2 keeps its substring tokens and ranks first by its higher score
+			if got := queryIDs(t, reopened, "mum", providers.MatchPrefix); got != "[2 1 3]" {
+				t.Errorf("Query() after restart = %v, want [2 1 3]", got)
+			}
+			if got := queryIDs(t, reopened, "avi", providers.MatchSubstring); got != "[2]" {
+				t.Errorf("substring Query() after restart = %v, want [2]", got)
+			}
+			if hash, exists, _ := reopened.ContentHash(ctx, testKey, "1"); !exists || hash != "h1" {
+				t.Errorf("ContentHash() after restart = %q, %v; want h1", hash, exists)
+			}
+			if entry, exists, _ := reopened.GetEntry(ctx, testKey, "1"); !exists || entry.ContentHash != "h1" || entry.Score != 1 {
+				t.Errorf("GetEntry() after restart = %+v, %v; want hash h1 and score 1", entry, exists)
+			}
+			for _, id := range []string{"4", "5"} {
+				key := testKey
+				if id == "5" {
+					key = "other"
+				}
+				if _, exists, _ := reopened.ContentHash(ctx, key, id); exists {
+					t.Errorf("deleted entry %s exists after restart", id)
+				}
+			}
+		})
+	}
+}
+
+func TestFilestoreProvider_DiscardsTornWrite(t *testing.T) {
+	ctx := context.Background()
+	config := Config{Dir: t.TempDir()}
+	provider := openProvider(t, config)
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchPrefix}
+	if err := provider.Index(ctx, testKey, "1", "mumbai", "Mumbai", options); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	crash(provider)
+
+	// A crash in the middle of appending the next record
+	log, err := os.OpenFile(filepath.Join(config.Dir, logFile), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = log.WriteString(`{"op":"index","key":"test","entries":[{"id":"2","te`)
+	_ = log.Close()
+
+	reopened := openProvider(t, config)
+	if got := queryIDs(t, reopened, "m", providers.MatchPrefix); got != "[1]" {
+		t.Errorf("Query() after torn write = %v, want [1]", got)
+	}
+	if err := reopened.Index(ctx, testKey, "2", "mulund", "Mulund", options); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	crash(reopened)
+
+	again := openProvider(t, config)
+	defer func() { _ = again.Close() }()
+	if got := queryIDs(t, again, "m", providers.MatchPrefix); got != "[1 2]" {
+		t.Errorf("Query() after writing past the torn record = %v, want [1 2]", got)
+	}
+}
+
+func TestFilestoreProvider_CompactsLog(t *testing.T) {
+	ctx := context.Background()
+	config := Config{Dir: t.TempDir(), CompactAfter: 3}
+	provider := openProvider(t, config)
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchPrefix}
+	for i := 1; i <= 4; i++ {
+		if err := provider.Index(ctx, testKey, fmt.Sprint(i), fmt.Sprint("pune ", i), "Pune", options); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+	if provider.logRecords != 1 {
+		t.Errorf("log records after compaction = %d, want 1", provider.logRecords)
+	}
+	if _, err := os.Stat(filepath.Join(config.Dir, SnapshotFile)); err != nil {
+		t.Errorf("snapshot missing after compaction: %v", err)
+	}
+	crash(provider)
+
+	reopened := openProvider(t, config)
+	defer func() { _ = reopened.Close() }()
+	if got := queryIDs(t, reopened, "pune", providers.MatchPrefix); got != "[1 2 3 4]" {
+		t.Errorf("Query() after restart = %v, want [1 2 3 4]", got)
+	}
+	if err := reopened.Compact(ctx); err != nil || reopened.logRecords != 0 {
+		t.Errorf("Compact() = %v, log records %d; want an empty log", err, reopened.logRecords)
+	}
+}
+
+func TestFilestoreProvider_ClosedRejectsWrites(t *testing.T) {
+	provider := openProvider(t, Config{Dir: t.TempDir()})
+	if err := provider.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := provider.Delete(context.Background(), testKey, "1"); err == nil {
+		t.Error("Delete() after Close() should fail")
+	}
+	if _, err := New(Config{}); err == nil {
+		t.Error("New() should require a directory")
+	}
+}
+
+func TestFilestoreProvider_Stress(t *testing.T) {
+	for _, strategy := range []autocomplete.MatchStrategy{autocomplete.MatchPrefix, autocomplete.MatchNGram, autocomplete.MatchSubstring} {
+		t.Run(strategy.String(), func(t *testing.T) {
+			options := autocomplete.DefaultOptions()
+			options.MatchStrategy = strategy
+			config := Config{Dir: t.TempDir(), CompactAfter: 100, NoSync: true}
+			ac, err := autocomplete.New("filestore", autocomplete.NewConfigWithOptions(config, options))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			defer func() { _ = ac.Close() }()
+
+			if err := stress.Run(context.Background(), ac, stress.Config{}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package filestore
+
+import (
+	"fmt"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// init registers the filestore provider. Import this package with a blank identifier
+// to keep an in-memory index persisted to files:
+//
+//	import _ "github.com/remiges-tech/autocomplete/providers/filestore"
+//
+//nolint:gochecknoinits // init() is the idiomatic pattern for provider registration
+func init() {
+	autocomplete.RegisterProvider("filestore", NewProvider)
+}
+
+// NewProvider creates a new filestore provider from the given configuration.
+// It implements ProviderFactory and expects config to be of type filestore.Config.
+func NewProvider(config interface{}) (providers.Provider, error) {
+	fileConfig, ok := config.(Config)
+	if !ok {
+		return nil, fmt.Errorf("invalid configuration type for filestore provider: expected filestore.Config, got %T", config)
+	}
+
+	return New(fileConfig)
+}
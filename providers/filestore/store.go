@@ -0,0 +1,249 @@
+package filestore
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// The snapshot holds every entry at the time it was written, as gzip-compressed
+// JSON lines of index records, one per entry. It is replaced atomically by
+// renaming a new snapshot over it. The log holds the writes made since, as
+// uncompressed JSON lines, one record per write.
+const (
+	// SnapshotFile is the name of the snapshot within the directory. A copy of
+	// it can be read with ReadSnapshot, e.g. to serve a prebuilt index.
+	SnapshotFile = "snapshot.jsonl.gz"
+
+	logFile = "log.jsonl"
+)
+
+// Record operations.
+const (
+	opIndex     = "index"
+	opDelete    = "delete"
+	opDeleteAll = "delete_all"
+)
+
+// record is a single write as stored in the snapshot and the log.
+type record struct {
+	Op      string        `json:"op"`
+	Key     string        `json:"key"`
+	ID      string        `json:"id,omitempty"`
+	Entries []entryRecord `json:"entries,omitempty"`
+}
+
+// entryRecord is an indexed entry with the options it was indexed with.
+type entryRecord struct {
+	ID      string                 `json:"id"`
+	Text    string                 `json:"text"`
+	Display string                 `json:"display"`
+	Options providers.IndexOptions `json:"options"`
+}
+
+// ReadSnapshot reads a snapshot written by the provider, calling fn with the
+// key of each entry and the entry as it was indexed. It stops at the first
+// error fn returns.
+func ReadSnapshot(r io.Reader, fn func(key string, entry providers.IndexEntry) error) error {
+	gz, err := gzip.NewReader(bufio.NewReader(r))
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	decoder := json.NewDecoder(gz)
+	for {
+		var r record
+		if err := decoder.Decode(&r); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to read snapshot: %w", err)
+		}
+		if r.Op != opIndex {
+			return fmt.Errorf("failed to read snapshot: unexpected record operation %q", r.Op)
+		}
+		for _, e := range r.Entries {
+			if err := fn(r.Key, providers.IndexEntry{ID: e.ID, Text: e.Text, Display: e.Display, Options: e.Options}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// loadSnapshot loads the entries of the snapshot, if there is one.
+func (p *Provider) loadSnapshot(ctx context.Context) error {
+	f, err := os.Open(filepath.Join(p.dir, SnapshotFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	return ReadSnapshot(f, func(key string, e providers.IndexEntry) error {
+		r := record{Op: opIndex, Key: key, Entries: []entryRecord{{ID: e.ID, Text: e.Text, Display: e.Display, Options: e.Options}}}
+		if err := p.apply(ctx, r); err != nil {
+			return fmt.Errorf("failed to load snapshot: %w", err)
+		}
+		return nil
+	})
+}
+
+// openLog replays the log over the loaded snapshot and opens it for appending.
+// A partly written record at the end, left by a crash during a write that was
+// therefore never acknowledged, is discarded.
+func (p *Provider) openLog(ctx context.Context) error {
+	f, err := os.OpenFile(filepath.Join(p.dir, logFile), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log: %w", err)
+	}
+
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(line) > 0 {
+				err = f.Truncate(p.logSize)
+			} else {
+				err = nil
+			}
+			if err != nil {
+				_ = f.Close()
+				return fmt.Errorf("failed to truncate log: %w", err)
+			}
+			break
+		}
+		if err != nil {
+			_ = f.Close()
+			return fmt.Errorf("failed to read log: %w", err)
+		}
+
+		var r record
+		if err := json.Unmarshal(line, &r); err != nil {
+			_ = f.Close()
+			return fmt.Errorf("failed to read log at offset %d: %w", p.logSize, err)
+		}
+		if err := p.apply(ctx, r); err != nil {
+			_ = f.Close()
+			return fmt.Errorf("failed to replay log at offset %d: %w", p.logSize, err)
+		}
+		p.logSize += int64(len(line))
+		p.logRecords++
+	}
+
+	p.log = f
+	return nil
+}
+
+// appendLog appends a record to the log and, unless NoSync is set, flushes it
+// to disk. A failed append is cut off, so that later records stay readable.
+func (p *Provider) appendLog(r record) error {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to encode record: %w", err)
+	}
+	line = append(line, '\n')
+
+	if _, err := p.log.Write(line); err != nil {
+		_ = p.log.Truncate(p.logSize)
+		return fmt.Errorf("failed to write log: %w", err)
+	}
+	if !p.noSync {
+		if err := p.log.Sync(); err != nil {
+			_ = p.log.Truncate(p.logSize)
+			return fmt.Errorf("failed to sync log: %w", err)
+		}
+	}
+	p.logSize += int64(len(line))
+	p.logRecords++
+	return nil
+}
+
+// compact writes a snapshot of all entries, renames it over the previous one,
+// and empties the log. A crash between the rename and emptying the log replays
+// the log over the new snapshot on the next New, which leaves every entry as
+// the log's last write of it left it, so no state is lost or resurrected.
+// The caller must hold mu.
+func (p *Provider) compact(ctx context.Context) error {
+	path := filepath.Join(p.dir, SnapshotFile)
+	tmp := path + ".tmp"
+	if err := p.writeSnapshot(ctx, tmp); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to replace snapshot: %w", err)
+	}
+	if !p.noSync {
+		if err := syncDir(p.dir); err != nil {
+			return err
+		}
+	}
+
+	if err := p.log.Truncate(0); err != nil {
+		return fmt.Errorf("failed to truncate log: %w", err)
+	}
+	p.logSize = 0
+	p.logRecords = 0
+	return nil
+}
+
+// writeSnapshot writes every entry to a new snapshot file at path.
+func (p *Provider) writeSnapshot(ctx context.Context, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	buffered := bufio.NewWriter(f)
+	gz := gzip.NewWriter(buffered)
+	encoder := json.NewEncoder(gz)
+	for _, key := range p.keys() {
+		entries, _, err := p.index.ListEntries(ctx, key, "", 0)
+		if err != nil {
+			return fmt.Errorf("failed to list entries: %w", err)
+		}
+		for _, e := range entries {
+			entry := entryRecord{ID: e.ID, Text: e.Text, Display: e.Display, Options: p.options[key][e.ID]}
+			if err := encoder.Encode(record{Op: opIndex, Key: key, Entries: []entryRecord{entry}}); err != nil {
+				return fmt.Errorf("failed to write snapshot: %w", err)
+			}
+		}
+	}
+
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := buffered.Flush(); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if !p.noSync {
+		if err := f.Sync(); err != nil {
+			return fmt.Errorf("failed to sync snapshot: %w", err)
+		}
+	}
+	return f.Close()
+}
+
+// syncDir flushes a directory, making a rename within it durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open directory: %w", err)
+	}
+	defer func() { _ = d.Close() }()
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("failed to sync directory: %w", err)
+	}
+	return nil
+}
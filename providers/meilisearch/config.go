@@ -0,0 +1,71 @@
+// Package meilisearch implements the autocomplete Provider interface using Meilisearch,
+// a search engine built for instant, typo-tolerant search.
+package meilisearch
+
+import (
+	"time"
+
+	meili "github.com/meilisearch/meilisearch-go"
+)
+
+const (
+	// defaultIndexPrefix is prepended to namespaces to form index UIDs.
+	defaultIndexPrefix = "autocomplete_"
+
+	// defaultTaskPollInterval is how often task status is polled while waiting.
+	defaultTaskPollInterval = 50 * time.Millisecond
+)
+
+// defaultRankingRules are Meilisearch's built-in ranking rules followed by the
+// entry score, which breaks ties between equally relevant entries.
+var defaultRankingRules = []string{"words", "typo", "proximity", "attribute", "sort", "exactness", "score:desc"}
+
+// Config holds Meilisearch connection parameters and provider-specific options.
+type Config struct {
+	// Host is the URL of the Meilisearch server, e.g. "http://localhost:7700".
+	Host string
+
+	// APIKey authenticates requests. It needs search, document and index
+	// permissions; leave empty for servers running without a master key.
+	APIKey string
+
+	// IndexPrefix is prepended to a namespace to form its index UID. Characters
+	// not allowed in index UIDs are replaced with underscores.
+	// Default: "autocomplete_"
+	IndexPrefix string
+
+	// IndexNames maps namespaces to index UIDs, overriding IndexPrefix for them.
+	// Use it to serve a namespace from an existing index.
+	IndexNames map[string]string
+
+	// TypoTolerance is applied to every index the provider sets up. Nil keeps
+	// Meilisearch's defaults (one typo from 5 characters, two from 9).
+	TypoTolerance *meili.TypoTolerance
+
+	// RankingRules are applied to every index the provider sets up.
+	// Default: Meilisearch's built-in rules followed by "score:desc"
+	RankingRules []string
+
+	// WaitForTasks makes writes wait until Meilisearch has processed them, so
+	// they are visible to the next query and failures are returned. Otherwise
+	// writes return once enqueued.
+	// Default: false
+	WaitForTasks bool
+
+	// TaskPollInterval is how often task status is polled while waiting.
+	// Default: 50 milliseconds
+	TaskPollInterval time.Duration
+}
+
+// setDefaults applies default values to config fields.
+func (c *Config) setDefaults() {
+	if c.IndexPrefix == "" {
+		c.IndexPrefix = defaultIndexPrefix
+	}
+	if c.RankingRules == nil {
+		c.RankingRules = defaultRankingRules
+	}
+	if c.TaskPollInterval == 0 {
+		c.TaskPollInterval = defaultTaskPollInterval
+	}
+}
@@ -0,0 +1,309 @@
+package meilisearch
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	meili "github.com/meilisearch/meilisearch-go"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+const (
+	// defaultMaxResults is the default maximum number of results if not specified.
+	defaultMaxResults = 10
+
+	// primaryKey is the document field holding the encoded entry ID.
+	primaryKey = "doc_id"
+)
+
+// Provider implements the autocomplete Provider interface using Meilisearch.
+// Each namespace is stored in its own index, with one document per entry.
+//
+// Queries use Meilisearch's own matching whatever the match strategy: query words
+// match indexed words and, for the last word, word prefixes, with typo tolerance.
+// Matching is always case-insensitive. Results are ranked by Meilisearch's
+// relevancy rules, with the entry score breaking ties (see Config.RankingRules).
+type Provider struct {
+	client meili.ServiceManager
+	config Config
+
+	// ready holds the UIDs of indexes whose settings have been applied.
+	ready sync.Map
+}
+
+// document is the structure stored in Meilisearch.
+type document struct {
+	DocID       string  `json:"doc_id"`
+	ID          string  `json:"id"`
+	Text        string  `json:"text"`
+	Display     string  `json:"display"`
+	Score       float64 `json:"score"`
+	ContentHash string  `json:"content_hash,omitempty"`
+}
+
+// New creates a Meilisearch provider and checks that the server is reachable.
+func New(config Config) (*Provider, error) {
+	config.setDefaults()
+
+	var options []meili.Option
+	if config.APIKey != "" {
+		options = append(options, meili.WithAPIKey(config.APIKey))
+	}
+	client := meili.New(config.Host, options...)
+
+	if _, err := client.Health(); err != nil {
+		return nil, fmt.Errorf("failed to connect to Meilisearch: %w", err)
+	}
+
+	return &Provider{client: client, config: config}, nil
+}
+
+// Index adds or updates an entry in the namespace's index.
+func (p *Provider) Index(ctx context.Context, key, id, text, display string, options providers.IndexOptions) error {
+	docs := []document{newDocument(id, text, display, options)}
+	if err := p.addDocuments(ctx, key, docs, p.config.WaitForTasks); err != nil {
+		return fmt.Errorf("failed to index document: %w", err)
+	}
+	return nil
+}
+
+// IndexAtomic adds all entries in a single document task, which Meilisearch
+// applies entirely or not at all. It always waits for the task to finish.
+func (p *Provider) IndexAtomic(ctx context.Context, key string, entries []providers.IndexEntry) error {
+	docs := make([]document, len(entries))
+	for i, entry := range entries {
+		docs[i] = newDocument(entry.ID, entry.Text, entry.Display, entry.Options)
+	}
+	if err := p.addDocuments(ctx, key, docs, true); err != nil {
+		return fmt.Errorf("failed to index documents: %w", err)
+	}
+	return nil
+}
+
+// addDocuments sets up the namespace's index if needed and adds documents to it.
+func (p *Provider) addDocuments(ctx context.Context, key string, docs []document, wait bool) error {
+	uid, err := p.ensureIndex(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	pk := primaryKey
+	task, err := p.client.Index(uid).AddDocumentsWithContext(ctx, docs, &meili.DocumentOptions{PrimaryKey: &pk})
+	if err != nil {
+		return err
+	}
+	if wait {
+		return p.waitForTask(ctx, task)
+	}
+	return nil
+}
+
+// ensureIndex creates the namespace's index and applies the provider's settings
+// to it, once per index for the lifetime of the provider.
+func (p *Provider) ensureIndex(ctx context.Context, key string) (string, error) {
+	uid := p.indexUID(key)
+	if _, ready := p.ready.Load(uid); ready {
+		return uid, nil
+	}
+
+	task, err := p.client.CreateIndexWithContext(ctx, &meili.IndexConfig{Uid: uid, PrimaryKey: primaryKey})
+	if err != nil {
+		return "", fmt.Errorf("failed to create index %s: %w", uid, err)
+	}
+	if err := p.waitForTask(ctx, task); err != nil && !isAPIError(err, "index_already_exists") {
+		return "", fmt.Errorf("failed to create index %s: %w", uid, err)
+	}
+
+	task, err = p.client.Index(uid).UpdateSettingsWithContext(ctx, p.settings())
+	if err == nil {
+		err = p.waitForTask(ctx, task)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to update settings of index %s: %w", uid, err)
+	}
+
+	p.ready.Store(uid, struct{}{})
+	return uid, nil
+}
+
+// settings returns the index settings applied to every namespace's index.
+func (p *Provider) settings() *meili.Settings {
+	return &meili.Settings{
+		SearchableAttributes: []string{"text"},
+		RankingRules:         p.config.RankingRules,
+		TypoTolerance:        p.config.TypoTolerance,
+	}
+}
+
+// waitForTask waits for a task to finish and returns its error if it failed.
+func (p *Provider) waitForTask(ctx context.Context, info *meili.TaskInfo) error {
+	task, err := p.client.WaitForTaskWithContext(ctx, info.TaskUID, p.config.TaskPollInterval)
+	if err != nil {
+		return err
+	}
+	if task.Status != meili.TaskStatusSucceeded {
+		return &taskError{status: task.Status, code: task.Error.Code, message: task.Error.Message}
+	}
+	return nil
+}
+
+// ContentHash returns the stored content hash for an entry and whether it exists.
+func (p *Provider) ContentHash(ctx context.Context, key, id string) (string, bool, error) {
+	var doc document
+	err := p.client.Index(p.indexUID(key)).GetDocumentWithContext(ctx, documentID(id),
+		&meili.DocumentQuery{Fields: []string{"content_hash"}}, &doc)
+	if isNotFound(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get document: %w", err)
+	}
+	return doc.ContentHash, true, nil
+}
+
+// Query searches the namespace's index for entries matching the query.
+func (p *Provider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	if query == "" {
+		return []providers.ProviderResult{}, nil
+	}
+
+	response, err := p.client.Index(p.indexUID(key)).SearchWithContext(ctx, query, newSearchRequest(options))
+	if isNotFound(err) {
+		return []providers.ProviderResult{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute search: %w", err)
+	}
+
+	results := make([]providers.ProviderResult, 0, len(response.Hits))
+	for _, hit := range response.Hits {
+		var doc document
+		if err := hit.DecodeInto(&doc); err != nil {
+			return nil, fmt.Errorf("failed to decode search hit: %w", err)
+		}
+		if doc.Score < options.MinScore {
+			continue
+		}
+		results = append(results, providers.ProviderResult{ID: doc.ID, Display: doc.Display, Score: doc.Score})
+	}
+	return results, nil
+}
+
+// newSearchRequest builds the search request for a query.
+func newSearchRequest(options providers.QueryOptions) *meili.SearchRequest {
+	limit := options.MaxResults
+	if limit <= 0 {
+		limit = defaultMaxResults
+	}
+	return &meili.SearchRequest{
+		Limit:                int64(limit),
+		Offset:               int64(options.Offset),
+		AttributesToRetrieve: []string{"id", "display", "score"},
+		MatchingStrategy:     meili.All,
+	}
+}
+
+// Delete removes an entry from the namespace's index.
+func (p *Provider) Delete(ctx context.Context, key, id string) error {
+	task, err := p.client.Index(p.indexUID(key)).DeleteDocumentWithContext(ctx, documentID(id), nil)
+	if err == nil && p.config.WaitForTasks {
+		err = p.waitForTask(ctx, task)
+	}
+	if err != nil && !isNotFound(err) {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+	return nil
+}
+
+// DeleteAll removes all entries of a namespace by deleting its index.
+func (p *Provider) DeleteAll(ctx context.Context, key string) error {
+	uid := p.indexUID(key)
+	task, err := p.client.DeleteIndexWithContext(ctx, uid)
+	if err == nil {
+		// The index must be gone before it can be set up again on the next write.
+		err = p.waitForTask(ctx, task)
+	}
+	p.ready.Delete(uid)
+	if err != nil && !isNotFound(err) {
+		return fmt.Errorf("failed to delete index %s: %w", uid, err)
+	}
+	return nil
+}
+
+// Close releases the client's idle connections.
+func (p *Provider) Close() error {
+	p.client.Close()
+	return nil
+}
+
+// indexUID returns the UID of the index holding a namespace.
+func (p *Provider) indexUID(key string) string {
+	if uid, ok := p.config.IndexNames[key]; ok {
+		return uid
+	}
+	return p.config.IndexPrefix + sanitizeUID(key)
+}
+
+// sanitizeUID replaces the characters not allowed in index UIDs with underscores.
+func sanitizeUID(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// documentID encodes an entry ID as a document primary key, which may only
+// contain ASCII letters, digits, hyphens and underscores.
+func documentID(id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(id))
+}
+
+// newDocument builds the stored document for an entry.
+func newDocument(id, text, display string, options providers.IndexOptions) document {
+	return document{
+		DocID:       documentID(id),
+		ID:          id,
+		Text:        text,
+		Display:     display,
+		Score:       options.Score,
+		ContentHash: options.ContentHash,
+	}
+}
+
+// taskError reports a Meilisearch task that did not succeed.
+type taskError struct {
+	status  meili.TaskStatus
+	code    string
+	message string
+}
+
+func (e *taskError) Error() string {
+	return fmt.Sprintf("task %s: %s (%s)", e.status, e.message, e.code)
+}
+
+// isAPIError reports whether err is a failed task or API response with the given error code.
+func isAPIError(err error, code string) bool {
+	var taskErr *taskError
+	if errors.As(err, &taskErr) {
+		return taskErr.code == code
+	}
+	var apiErr *meili.Error
+	return errors.As(err, &apiErr) && apiErr.MeilisearchApiError.Code == code
+}
+
+// isNotFound reports whether err means the index or document does not exist.
+func isNotFound(err error) bool {
+	var apiErr *meili.Error
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return true
+	}
+	return isAPIError(err, "index_not_found") || isAPIError(err, "document_not_found")
+}
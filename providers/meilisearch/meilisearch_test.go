@@ -0,0 +1,279 @@
+package meilisearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	meili "github.com/meilisearch/meilisearch-go"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+const testKey = "test"
+
+// fakeServer implements the parts of the Meilisearch API the provider uses.
+// Tasks complete immediately; search matches documents whose text has a word
+// starting with every query word, without typo tolerance.
+type fakeServer struct {
+	mu       sync.Mutex
+	indexes  map[string]map[string]document
+	settings map[string]meili.Settings
+	tasks    []map[string]any
+}
+
+func newFakeServer(t *testing.T) (*fakeServer, *httptest.Server) {
+	t.Helper()
+	f := &fakeServer{indexes: map[string]map[string]document{}, settings: map[string]meili.Settings{}}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func (f *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.URL.Path == "/health":
+		writeJSON(w, http.StatusOK, map[string]string{"status": "available"})
+
+	case parts[0] == "tasks" && len(parts) == 2:
+		var uid int
+		fmt.Sscan(parts[1], &uid)
+		writeJSON(w, http.StatusOK, f.tasks[uid])
+
+	case r.Method == http.MethodPost && r.URL.Path == "/indexes":
+		var config struct{ UID string }
+		_ = json.NewDecoder(r.Body).Decode(&config)
+		if _, exists := f.indexes[config.UID]; exists {
+			f.enqueue(w, "failed", "index_already_exists")
+			return
+		}
+		f.indexes[config.UID] = map[string]document{}
+		f.enqueue(w, "succeeded", "")
+
+	case r.Method == http.MethodDelete && len(parts) == 2:
+		if _, exists := f.indexes[parts[1]]; !exists {
+			f.enqueue(w, "failed", "index_not_found")
+			return
+		}
+		delete(f.indexes, parts[1])
+		f.enqueue(w, "succeeded", "")
+
+	case len(parts) == 3 && parts[2] == "settings":
+		var settings meili.Settings
+		_ = json.NewDecoder(r.Body).Decode(&settings)
+		f.settings[parts[1]] = settings
+		f.enqueue(w, "succeeded", "")
+
+	case len(parts) >= 3 && parts[2] == "documents":
+		f.documents(w, r, parts)
+
+	case len(parts) == 3 && parts[2] == "search":
+		f.search(w, r, parts[1])
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeServer) documents(w http.ResponseWriter, r *http.Request, parts []string) {
+	docs, exists := f.indexes[parts[1]]
+	switch {
+	case r.Method == http.MethodPost:
+		if !exists {
+			docs = map[string]document{}
+			f.indexes[parts[1]] = docs
+		}
+		var added []document
+		_ = json.NewDecoder(r.Body).Decode(&added)
+		for _, doc := range added {
+			docs[doc.DocID] = doc
+		}
+		f.enqueue(w, "succeeded", "")
+
+	case r.Method == http.MethodGet && len(parts) == 4:
+		doc, found := docs[parts[3]]
+		if !found {
+			writeJSON(w, http.StatusNotFound, map[string]string{"code": "document_not_found", "message": "not found"})
+			return
+		}
+		writeJSON(w, http.StatusOK, doc)
+
+	case r.Method == http.MethodDelete && len(parts) == 4:
+		delete(docs, parts[3])
+		f.enqueue(w, "succeeded", "")
+	}
+}
+
+func (f *fakeServer) search(w http.ResponseWriter, r *http.Request, uid string) {
+	docs, exists := f.indexes[uid]
+	if !exists {
+		writeJSON(w, http.StatusNotFound, map[string]string{"code": "index_not_found", "message": "not found"})
+		return
+	}
+	var request meili.SearchRequest
+	_ = json.NewDecoder(r.Body).Decode(&request)
+
+	var hits []document
+	for _, doc := range docs {
+		if matchesWords(doc.Text, request.Query) {
+			hits = append(hits, doc)
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	if int64(len(hits)) > request.Limit {
+		hits = hits[:request.Limit]
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"hits": hits})
+}
+
+// enqueue records a finished task and responds with its summary.
+func (f *fakeServer) enqueue(w http.ResponseWriter, status, code string) {
+	uid := len(f.tasks)
+	task := map[string]any{"uid": uid, "status": status}
+	if code != "" {
+		task["error"] = map[string]string{"code": code, "message": code}
+	}
+	f.tasks = append(f.tasks, task)
+	writeJSON(w, http.StatusAccepted, map[string]any{"taskUid": uid, "status": "enqueued"})
+}
+
+func matchesWords(text, query string) bool {
+	words := strings.Fields(strings.ToLower(text))
+	for _, q := range strings.Fields(strings.ToLower(query)) {
+		found := false
+		for _, word := range words {
+			if strings.HasPrefix(word, q) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func newTestProvider(t *testing.T, config Config) (*fakeServer, *Provider) {
+	t.Helper()
+	fake, server := newFakeServer(t)
+	config.Host = server.URL
+	config.WaitForTasks = true
+	provider, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = provider.Close() })
+	return fake, provider
+}
+
+func TestMeilisearchProvider_IndexQueryDelete(t *testing.T) {
+	fake, provider := newTestProvider(t, Config{TypoTolerance: &meili.TypoTolerance{Enabled: false}})
+	ctx := context.Background()
+
+	for id, text := range map[string]string{"IN/MH": "Mumbai Maharashtra", "IN/KA": "Bangalore Karnataka", "IN/NM": "Navi Mumbai"} {
+		options := providers.IndexOptions{Score: 1.0, ContentHash: "h-" + id}
+		if id == "IN/NM" {
+			options.Score = 2.0
+		}
+		if err := provider.Index(ctx, testKey, id, text, text+" (display)", options); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	settings := fake.settings["autocomplete_test"]
+	if settings.TypoTolerance == nil || settings.TypoTolerance.Enabled {
+		t.Errorf("typo tolerance not passed through: %+v", settings.TypoTolerance)
+	}
+	if got := fmt.Sprint(settings.RankingRules); !strings.HasSuffix(got, "score:desc]") {
+		t.Errorf("ranking rules = %v, want score:desc last", got)
+	}
+
+	results, err := provider.Query(ctx, testKey, "mum", providers.QueryOptions{MaxResults: 10})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(results) != 2 || results[0].ID != "IN/NM" || results[1].Display != "Mumbai Maharashtra (display)" {
+		t.Errorf("Query() = %+v, want Navi Mumbai then Mumbai", results)
+	}
+
+	if hash, exists, err := provider.ContentHash(ctx, testKey, "IN/MH"); err != nil || !exists || hash != "h-IN/MH" {
+		t.Errorf("ContentHash() = %q, %t, %v; want h-IN/MH, true", hash, exists, err)
+	}
+	if err := provider.Delete(ctx, testKey, "IN/MH"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, exists, err := provider.ContentHash(ctx, testKey, "IN/MH"); err != nil || exists {
+		t.Errorf("ContentHash() after delete = %t, %v; want false, nil", exists, err)
+	}
+
+	if err := provider.DeleteAll(ctx, testKey); err != nil {
+		t.Fatalf("DeleteAll() error = %v", err)
+	}
+	if results, err := provider.Query(ctx, testKey, "mum", providers.QueryOptions{}); err != nil || len(results) != 0 {
+		t.Errorf("Query() after DeleteAll = %v, %v; want no results", results, err)
+	}
+	if err := provider.DeleteAll(ctx, testKey); err != nil {
+		t.Errorf("DeleteAll() of missing index error = %v", err)
+	}
+}
+
+func TestMeilisearchProvider_IndexAtomic(t *testing.T) {
+	fake, provider := newTestProvider(t, Config{IndexNames: map[string]string{testKey: "cities"}})
+	ctx := context.Background()
+
+	entries := []providers.IndexEntry{
+		{ID: "1", Text: "Pune", Display: "Pune", Options: providers.IndexOptions{Score: 1.0}},
+		{ID: "2", Text: "Nashik", Display: "Nashik", Options: providers.IndexOptions{Score: 1.0}},
+	}
+	if err := provider.IndexAtomic(ctx, testKey, entries); err != nil {
+		t.Fatalf("IndexAtomic() error = %v", err)
+	}
+	if n := len(fake.indexes["cities"]); n != 2 {
+		t.Errorf("index cities holds %d documents, want 2", n)
+	}
+
+	// A second provider finds the index already created.
+	other, err := New(Config{Host: provider.config.Host, IndexNames: provider.config.IndexNames})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := other.IndexAtomic(ctx, testKey, entries[:1]); err != nil {
+		t.Errorf("IndexAtomic() on existing index error = %v", err)
+	}
+}
+
+func TestIndexUID(t *testing.T) {
+	provider := &Provider{config: Config{IndexPrefix: "ac_", IndexNames: map[string]string{"legacy": "old-index"}}}
+	for key, want := range map[string]string{
+		"cities":       "ac_cities",
+		"in:pincodes":  "ac_in_pincodes",
+		"legacy":       "old-index",
+		"cities:fold€": "ac_cities_fold_",
+	} {
+		if got := provider.indexUID(key); got != want {
+			t.Errorf("indexUID(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
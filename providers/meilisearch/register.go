@@ -0,0 +1,29 @@
+package meilisearch
+
+import (
+	"fmt"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// init registers the Meilisearch provider. Import this package with a blank identifier
+// to use Meilisearch as the autocomplete backend:
+//
+//	import _ "github.com/remiges-tech/autocomplete/providers/meilisearch"
+//
+//nolint:gochecknoinits // init() is the idiomatic pattern for provider registration
+func init() {
+	autocomplete.RegisterProvider("meilisearch", NewProvider)
+}
+
+// NewProvider creates a new Meilisearch provider from the given configuration.
+// It implements ProviderFactory and expects config to be of type meilisearch.Config.
+func NewProvider(config interface{}) (providers.Provider, error) {
+	meiliConfig, ok := config.(Config)
+	if !ok {
+		return nil, fmt.Errorf("invalid configuration type for Meilisearch provider: expected meilisearch.Config, got %T", config)
+	}
+
+	return New(meiliConfig)
+}
@@ -0,0 +1,74 @@
+// Package memcached implements the autocomplete Provider interface using
+// memcached, for environments where memcached is the only shared store
+// available. It supports the MatchPrefix and MatchNGram strategies.
+//
+// memcached has no range scans or key enumeration, so every token an entry
+// can be found by is precomputed: each token is an item holding a serialized
+// postings list of the entries containing it. memcached may also evict items
+// under memory pressure; give it enough memory to hold the index, or reindex
+// periodically.
+package memcached
+
+import "time"
+
+const (
+	// defaultKeyPrefix is the key prefix used when Config.KeyPrefix is empty.
+	defaultKeyPrefix = "autocomplete:"
+
+	// defaultTimeout is the socket timeout used when Config.Timeout is zero.
+	defaultTimeout = 500 * time.Millisecond
+
+	// defaultMaxPostings is the postings list size used when Config.MaxPostings is zero.
+	defaultMaxPostings = 1000
+
+	// defaultMaxPrefixLength is the prefix length used when Config.MaxPrefixLength is zero.
+	defaultMaxPrefixLength = 20
+)
+
+// Config holds memcached connection parameters and provider-specific options.
+type Config struct {
+	// Servers are the memcached servers, e.g. "10.0.0.1:11211". Keys are
+	// spread across them.
+	// Default: ["127.0.0.1:11211"]
+	Servers []string
+
+	// Timeout is the socket read and write timeout.
+	// Default: 500 milliseconds
+	Timeout time.Duration
+
+	// KeyPrefix prefixes every key the provider writes, keeping its items
+	// apart from other data in the cache.
+	// Default: "autocomplete:"
+	KeyPrefix string
+
+	// MaxPostings is the most entries a token's postings list holds. Lists
+	// keep the highest-scored entries, so short, common tokens still find the
+	// best matches while their items stay below memcached's item size limit.
+	// Default: 1000
+	MaxPostings int
+
+	// MaxPrefixLength is the longest prefix, in characters, precomputed under
+	// MatchPrefix. Longer queries are answered from the postings of their first
+	// MaxPrefixLength characters.
+	// Default: 20
+	MaxPrefixLength int
+}
+
+// setDefaults applies default values to config fields.
+func (c *Config) setDefaults() {
+	if len(c.Servers) == 0 {
+		c.Servers = []string{"127.0.0.1:11211"}
+	}
+	if c.Timeout == 0 {
+		c.Timeout = defaultTimeout
+	}
+	if c.KeyPrefix == "" {
+		c.KeyPrefix = defaultKeyPrefix
+	}
+	if c.MaxPostings <= 0 {
+		c.MaxPostings = defaultMaxPostings
+	}
+	if c.MaxPrefixLength <= 0 {
+		c.MaxPrefixLength = defaultMaxPrefixLength
+	}
+}
@@ -0,0 +1,636 @@
+package memcached
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+const (
+	// defaultNGramSize is the default n-gram size when not specified in options.
+	defaultNGramSize = 3
+
+	// maxCASRetries bounds how often a read-modify-write of an item is retried
+	// after a concurrent update of the same item.
+	maxCASRetries = 100
+
+	// maxKeyPrefixLength leaves room for the hashed part of keys within
+	// memcached's limit of 250 bytes.
+	maxKeyPrefixLength = 200
+)
+
+// Provider implements the autocomplete Provider interface using memcached.
+// Each entry is an item holding its text, display, and options, and each token
+// is an item holding the postings list of the entries containing it. Keys are
+// hashed, so namespaces, IDs, and tokens of any length and content can be used.
+//
+// As items cannot be enumerated, DeleteAll moves the namespace to a new
+// generation, part of every key, and leaves the old items to be evicted.
+// Postings lists are updated with compare-and-swap, but entry and postings
+// are separate items, so queries check each candidate against its entry and
+// skip postings a concurrent update has not removed yet.
+// All methods are safe for concurrent use.
+type Provider struct {
+	client          *memcache.Client
+	keyPrefix       string
+	maxPostings     int
+	maxPrefixLength int
+}
+
+// storedEntry is the value of an entry item.
+type storedEntry struct {
+	Text        string  `json:"text"`
+	SearchText  string  `json:"search_text"`
+	Display     string  `json:"display"`
+	Score       float64 `json:"score"`
+	Strategy    int     `json:"strategy"`
+	NGramSize   int     `json:"ngram_size"`
+	ContentHash string  `json:"content_hash,omitempty"`
+}
+
+// posting is an element of a postings list, which is kept sorted by score.
+type posting struct {
+	ID    string  `json:"i"`
+	Score float64 `json:"s"`
+}
+
+// match is a candidate result with the data used to rank it.
+type match struct {
+	result   providers.ProviderResult
+	position int
+	length   int
+}
+
+// New creates a new memcached provider with the given configuration.
+// It checks that every server is reachable.
+func New(config Config) (*Provider, error) {
+	config.setDefaults()
+	if len(config.KeyPrefix) > maxKeyPrefixLength || strings.ContainsFunc(config.KeyPrefix, invalidKeyRune) {
+		return nil, fmt.Errorf("invalid key prefix %q: memcached keys allow at most %d bytes without spaces or control characters",
+			config.KeyPrefix, maxKeyPrefixLength)
+	}
+
+	client := memcache.New(config.Servers...)
+	client.Timeout = config.Timeout
+	if err := client.Ping(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("failed to connect to memcached: %w", err)
+	}
+
+	return &Provider{
+		client:          client,
+		keyPrefix:       config.KeyPrefix,
+		maxPostings:     config.MaxPostings,
+		maxPrefixLength: config.MaxPrefixLength,
+	}, nil
+}
+
+// Index adds or updates an entry. The entry item is written first, then the
+// entry is added to the postings of its tokens and removed from those of
+// tokens its previous text had but the new text lacks.
+func (p *Provider) Index(ctx context.Context, key, id, text, display string, options providers.IndexOptions) error {
+	searchText := providers.SearchText(text, options.CaseSensitive)
+	n := getNGramSizeOrDefault(options.NGramSize)
+	newTokens, err := p.tokenize(searchText, options.MatchStrategy, n)
+	if err != nil {
+		return err
+	}
+	value, err := json.Marshal(storedEntry{
+		Text:        text,
+		SearchText:  searchText,
+		Display:     display,
+		Score:       options.Score,
+		Strategy:    int(options.MatchStrategy),
+		NGramSize:   n,
+		ContentHash: options.ContentHash,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal entry: %w", err)
+	}
+
+	generation, err := p.generation(key)
+	if err != nil {
+		return fmt.Errorf("failed to read namespace generation: %w", err)
+	}
+	old, err := p.swapEntry(p.entryKey(key, generation, id), value)
+	if err != nil {
+		return fmt.Errorf("failed to index entry: %w", err)
+	}
+
+	for tok := range newTokens {
+		err := p.updatePostings(p.tokenKey(key, generation, tok), func(list []posting) []posting {
+			return p.addPosting(list, posting{ID: id, Score: options.Score})
+		})
+		if err != nil {
+			return fmt.Errorf("failed to index entry: %w", err)
+		}
+	}
+	if old == nil {
+		return nil
+	}
+	oldTokens, err := p.tokenize(old.SearchText, providers.MatchStrategy(old.Strategy), old.NGramSize)
+	if err != nil {
+		return err
+	}
+	for tok := range oldTokens {
+		if _, kept := newTokens[tok]; kept {
+			continue
+		}
+		if err := p.removePosting(key, generation, tok, id); err != nil {
+			return fmt.Errorf("failed to index entry: %w", err)
+		}
+	}
+	return nil
+}
+
+// swapEntry writes an entry item and returns the entry it replaced, if any.
+func (p *Provider) swapEntry(itemKey string, value []byte) (*storedEntry, error) {
+	for attempt := 0; attempt < maxCASRetries; attempt++ {
+		item, err := p.client.Get(itemKey)
+		if errors.Is(err, memcache.ErrCacheMiss) {
+			err = p.client.Add(&memcache.Item{Key: itemKey, Value: value})
+			if errors.Is(err, memcache.ErrNotStored) {
+				continue
+			}
+			return nil, err
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var old storedEntry
+		if err := json.Unmarshal(item.Value, &old); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal entry: %w", err)
+		}
+		item.Value = value
+		err = p.client.CompareAndSwap(item)
+		if errors.Is(err, memcache.ErrCASConflict) || errors.Is(err, memcache.ErrNotStored) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &old, nil
+	}
+	return nil, fmt.Errorf("entry was concurrently updated %d times", maxCASRetries)
+}
+
+// getEntry reads an entry item; it returns nil if the entry does not exist.
+func (p *Provider) getEntry(itemKey string) (*storedEntry, error) {
+	item, err := p.client.Get(itemKey)
+	if errors.Is(err, memcache.ErrCacheMiss) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var e storedEntry
+	if err := json.Unmarshal(item.Value, &e); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal entry: %w", err)
+	}
+	return &e, nil
+}
+
+// updatePostings applies change to the postings list stored under itemKey with
+// compare-and-swap, retrying after concurrent updates. An empty result deletes
+// the item.
+func (p *Provider) updatePostings(itemKey string, change func([]posting) []posting) error {
+	for attempt := 0; attempt < maxCASRetries; attempt++ {
+		item, err := p.client.Get(itemKey)
+		if errors.Is(err, memcache.ErrCacheMiss) {
+			list := change(nil)
+			if len(list) == 0 {
+				return nil
+			}
+			value, err := json.Marshal(list)
+			if err != nil {
+				return fmt.Errorf("failed to marshal postings: %w", err)
+			}
+			err = p.client.Add(&memcache.Item{Key: itemKey, Value: value})
+			if errors.Is(err, memcache.ErrNotStored) {
+				continue
+			}
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		var list []posting
+		if err := json.Unmarshal(item.Value, &list); err != nil {
+			return fmt.Errorf("failed to unmarshal postings: %w", err)
+		}
+		list = change(list)
+		if len(list) == 0 {
+			// Deleting cannot be made conditional; an empty list is stored instead
+			item.Value = []byte("[]")
+		} else if item.Value, err = json.Marshal(list); err != nil {
+			return fmt.Errorf("failed to marshal postings: %w", err)
+		}
+		err = p.client.CompareAndSwap(item)
+		if errors.Is(err, memcache.ErrCASConflict) || errors.Is(err, memcache.ErrNotStored) {
+			continue
+		}
+		return err
+	}
+	return fmt.Errorf("postings were concurrently updated %d times", maxCASRetries)
+}
+
+// addPosting adds or replaces the posting of an entry, keeping the list sorted
+// by score and at most maxPostings long.
+func (p *Provider) addPosting(list []posting, entry posting) []posting {
+	list = withoutPosting(list, entry.ID)
+	i := sort.Search(len(list), func(i int) bool { return before(entry, list[i]) })
+	if i >= p.maxPostings {
+		return list
+	}
+	list = append(list, posting{})
+	copy(list[i+1:], list[i:])
+	list[i] = entry
+	if len(list) > p.maxPostings {
+		list = list[:p.maxPostings]
+	}
+	return list
+}
+
+// removePosting removes an entry from the postings of a token.
+func (p *Provider) removePosting(key, generation, tok, id string) error {
+	return p.updatePostings(p.tokenKey(key, generation, tok), func(list []posting) []posting {
+		return withoutPosting(list, id)
+	})
+}
+
+// withoutPosting returns list without the posting of id.
+func withoutPosting(list []posting, id string) []posting {
+	for i, existing := range list {
+		if existing.ID == id {
+			return append(list[:i], list[i+1:]...)
+		}
+	}
+	return list
+}
+
+// before reports whether a sorts before b in a postings list.
+func before(a, b posting) bool {
+	if a.Score != b.Score {
+		return a.Score > b.Score
+	}
+	return a.ID < b.ID
+}
+
+// ContentHash returns the stored content hash for an entry and whether it exists.
+func (p *Provider) ContentHash(ctx context.Context, key, id string) (string, bool, error) {
+	generation, err := p.generation(key)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get content hash: %w", err)
+	}
+	e, err := p.getEntry(p.entryKey(key, generation, id))
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get content hash: %w", err)
+	}
+	if e == nil {
+		return "", false, nil
+	}
+	return e.ContentHash, true, nil
+}
+
+// Query searches for entries matching the given query. Candidates are read
+// from the postings of the query's tokens and checked against their entries.
+// Results are ranked by entry score, then match position, then text length.
+func (p *Provider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	searchQuery := providers.SearchText(query, options.CaseSensitive)
+	if searchQuery == "" {
+		return []providers.ProviderResult{}, nil
+	}
+
+	var lookups []string
+	n := getNGramSizeOrDefault(options.NGramSize)
+	switch options.MatchStrategy {
+	case providers.MatchPrefix:
+		offsets := runeOffsets(searchQuery)
+		lookups = []string{searchQuery[:offsets[min(len(offsets)-1, p.maxPrefixLength)]]}
+	case providers.MatchNGram:
+		lookups = nGrams(searchQuery, n)
+	default:
+		return nil, unsupportedStrategy(options.MatchStrategy)
+	}
+
+	generation, err := p.generation(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query autocomplete: %w", err)
+	}
+	candidates, err := p.candidates(key, generation, lookups)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query autocomplete: %w", err)
+	}
+	matches, err := p.check(key, generation, candidates, options.MatchStrategy, searchQuery, lookups)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query autocomplete: %w", err)
+	}
+	return rank(matches, options.MaxResults), nil
+}
+
+// candidates returns the IDs in the postings of every token in lookups.
+func (p *Provider) candidates(key, generation string, lookups []string) ([]string, error) {
+	keys := make([]string, len(lookups))
+	for i, tok := range lookups {
+		keys[i] = p.tokenKey(key, generation, tok)
+	}
+	items, err := p.client.GetMulti(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for i, itemKey := range keys {
+		item, ok := items[itemKey]
+		if !ok {
+			return nil, nil
+		}
+		var list []posting
+		if err := json.Unmarshal(item.Value, &list); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal postings: %w", err)
+		}
+		if i == 0 {
+			for _, entry := range list {
+				ids = append(ids, entry.ID)
+			}
+			continue
+		}
+		contained := make(map[string]bool, len(list))
+		for _, entry := range list {
+			contained[entry.ID] = true
+		}
+		kept := ids[:0]
+		for _, id := range ids {
+			if contained[id] {
+				kept = append(kept, id)
+			}
+		}
+		ids = kept
+	}
+	return ids, nil
+}
+
+// check reads the entries of the candidates and keeps those whose current text
+// matches the query.
+func (p *Provider) check(
+	key, generation string, ids []string, strategy providers.MatchStrategy, searchQuery string, lookups []string,
+) ([]match, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = p.entryKey(key, generation, id)
+	}
+	items, err := p.client.GetMulti(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]match, 0, len(items))
+	for i, id := range ids {
+		item, ok := items[keys[i]]
+		if !ok {
+			continue
+		}
+		var e storedEntry
+		if err := json.Unmarshal(item.Value, &e); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal entry: %w", err)
+		}
+		if providers.MatchStrategy(e.Strategy) != strategy {
+			continue
+		}
+
+		position := 0
+		switch strategy {
+		case providers.MatchPrefix:
+			if !strings.HasPrefix(e.SearchText, searchQuery) {
+				continue
+			}
+		case providers.MatchNGram:
+			tokens, err := p.tokenize(e.SearchText, strategy, e.NGramSize)
+			if err != nil {
+				return nil, err
+			}
+			if !containsAll(tokens, lookups) {
+				continue
+			}
+			position = tokens[lookups[0]]
+		}
+		matches = append(matches, match{
+			result:   providers.ProviderResult{ID: id, Display: e.Display, Score: e.Score},
+			position: position,
+			length:   len(e.Text),
+		})
+	}
+	return matches, nil
+}
+
+// containsAll reports whether tokens holds every token of lookups.
+func containsAll(tokens map[string]int, lookups []string) bool {
+	for _, tok := range lookups {
+		if _, ok := tokens[tok]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// rank orders matches by score, position, length, and ID, keeping at most limit.
+func rank(matches []match, limit int) []providers.ProviderResult {
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.result.Score != b.result.Score {
+			return a.result.Score > b.result.Score
+		}
+		if a.position != b.position {
+			return a.position < b.position
+		}
+		if a.length != b.length {
+			return a.length < b.length
+		}
+		return a.result.ID < b.result.ID
+	})
+
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	results := make([]providers.ProviderResult, len(matches))
+	for i, m := range matches {
+		results[i] = m.result
+	}
+	return results
+}
+
+// Delete removes an entry item and then the entry from the postings of its tokens.
+func (p *Provider) Delete(ctx context.Context, key, id string) error {
+	generation, err := p.generation(key)
+	if err != nil {
+		return fmt.Errorf("failed to read namespace generation: %w", err)
+	}
+	entryKey := p.entryKey(key, generation, id)
+	old, err := p.getEntry(entryKey)
+	if err != nil {
+		return fmt.Errorf("failed to read existing entry: %w", err)
+	}
+	if old == nil {
+		return nil
+	}
+	if err := p.client.Delete(entryKey); err != nil && !errors.Is(err, memcache.ErrCacheMiss) {
+		return fmt.Errorf("failed to delete entry: %w", err)
+	}
+
+	tokens, err := p.tokenize(old.SearchText, providers.MatchStrategy(old.Strategy), old.NGramSize)
+	if err != nil {
+		return err
+	}
+	for tok := range tokens {
+		if err := p.removePosting(key, generation, tok, id); err != nil {
+			return fmt.Errorf("failed to delete entry: %w", err)
+		}
+	}
+	return nil
+}
+
+// DeleteAll moves the namespace to a new generation, so that none of its
+// items are found any more. memcached evicts the old items over time.
+func (p *Provider) DeleteAll(ctx context.Context, key string) error {
+	if _, err := p.generation(key); err != nil {
+		return fmt.Errorf("failed to delete entries: %w", err)
+	}
+	if _, err := p.client.Increment(p.generationKey(key), 1); err != nil {
+		return fmt.Errorf("failed to delete entries: %w", err)
+	}
+	return nil
+}
+
+// Close closes the connections to the servers.
+func (p *Provider) Close() error {
+	return p.client.Close()
+}
+
+// generation returns the current generation of a namespace, starting one if
+// the namespace has none. New generations start at the current time, so that
+// a namespace whose generation item was evicted does not resume an old one.
+func (p *Provider) generation(key string) (string, error) {
+	generationKey := p.generationKey(key)
+	for attempt := 0; attempt < maxCASRetries; attempt++ {
+		item, err := p.client.Get(generationKey)
+		if err == nil {
+			return string(item.Value), nil
+		}
+		if !errors.Is(err, memcache.ErrCacheMiss) {
+			return "", err
+		}
+		start := strconv.FormatInt(time.Now().UnixNano(), 10)
+		err = p.client.Add(&memcache.Item{Key: generationKey, Value: []byte(start)})
+		if err == nil {
+			return start, nil
+		}
+		if !errors.Is(err, memcache.ErrNotStored) {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("generation of namespace %q was concurrently evicted %d times", key, maxCASRetries)
+}
+
+// hashedKey returns a memcached key for the given parts. Hashing keeps keys
+// within memcached's length limit and free of spaces and control characters.
+func (p *Provider) hashedKey(parts ...string) string {
+	h := sha1.New() //nolint:gosec // SHA-1 spreads keys; it does not protect anything
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return p.keyPrefix + hex.EncodeToString(h.Sum(nil))
+}
+
+func (p *Provider) generationKey(key string) string {
+	return p.hashedKey("g", key)
+}
+
+func (p *Provider) entryKey(key, generation, id string) string {
+	return p.hashedKey("e", key, generation, id)
+}
+
+func (p *Provider) tokenKey(key, generation, tok string) string {
+	return p.hashedKey("t", key, generation, tok)
+}
+
+// tokenize returns the tokens stored for normalized text under the given strategy,
+// mapped to the earliest byte offset at which each occurs.
+// MatchPrefix stores each prefix of up to maxPrefixLength characters.
+// MatchNGram stores each n-gram and the prefixes of each n-gram, so that
+// queries shorter than n characters match by direct lookup.
+func (p *Provider) tokenize(text string, strategy providers.MatchStrategy, n int) (map[string]int, error) {
+	tokens := make(map[string]int)
+	offsets := runeOffsets(text)
+	switch strategy {
+	case providers.MatchPrefix:
+		for i := 1; i < len(offsets) && i <= p.maxPrefixLength; i++ {
+			tokens[text[:offsets[i]]] = 0
+		}
+	case providers.MatchNGram:
+		for i := len(offsets) - 1 - n; i >= 0; i-- {
+			for length := 1; length <= n; length++ {
+				tokens[text[offsets[i]:offsets[i+length]]] = offsets[i]
+			}
+		}
+	default:
+		return nil, unsupportedStrategy(strategy)
+	}
+	return tokens, nil
+}
+
+// nGrams returns the n-grams of s in order, or s itself if it is at most n characters long.
+func nGrams(s string, n int) []string {
+	offsets := runeOffsets(s)
+	if len(offsets)-1 <= n {
+		return []string{s}
+	}
+	grams := make([]string, 0, len(offsets)-n)
+	for i := 0; i+n < len(offsets); i++ {
+		grams = append(grams, s[offsets[i]:offsets[i+n]])
+	}
+	return grams
+}
+
+// runeOffsets returns the byte offset of each character of s, followed by len(s).
+func runeOffsets(s string) []int {
+	offsets := make([]int, 0, len(s)+1)
+	for i := range s {
+		offsets = append(offsets, i)
+	}
+	return append(offsets, len(s))
+}
+
+// invalidKeyRune reports whether r cannot appear in a memcached key.
+func invalidKeyRune(r rune) bool {
+	return r <= ' ' || r == 0x7f
+}
+
+// unsupportedStrategy returns the error for strategies this provider cannot serve.
+func unsupportedStrategy(strategy providers.MatchStrategy) error {
+	return fmt.Errorf("match strategy %d is not supported by the memcached provider: use MatchPrefix or MatchNGram", strategy)
+}
+
+func getNGramSizeOrDefault(size int) int {
+	if size <= 0 {
+		return defaultNGramSize
+	}
+	return size
+}
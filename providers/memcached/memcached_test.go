@@ -0,0 +1,257 @@
+package memcached
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/daangn/minimemcached"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers"
+	"github.com/remiges-tech/autocomplete/stress"
+)
+
+const testKey = "test"
+
+// startServer runs an in-process memcached server for the duration of the test.
+func startServer(t *testing.T) Config {
+	t.Helper()
+	server, err := minimemcached.Run(&minimemcached.Config{Port: 0})
+	if err != nil {
+		t.Fatalf("failed to start memcached: %v", err)
+	}
+	t.Cleanup(server.Close)
+	return Config{Servers: []string{fmt.Sprintf("127.0.0.1:%d", server.Port())}}
+}
+
+func newTestProvider(t *testing.T, config Config) *Provider {
+	t.Helper()
+	provider, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = provider.Close() })
+	return provider
+}
+
+func queryIDs(t *testing.T, provider *Provider, key, query string, strategy providers.MatchStrategy) string {
+	t.Helper()
+	results, err := provider.Query(context.Background(), key, query, providers.QueryOptions{
+		MaxResults:    10,
+		MatchStrategy: strategy,
+		NGramSize:     3,
+	})
+	if err != nil {
+		t.Fatalf("Query(%q) error = %v", query, err)
+	}
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+	return fmt.Sprint(ids)
+}
+
+func TestMemcachedProvider_MatchStrategies(t *testing.T) {
+	ctx := context.Background()
+	entries := map[string]string{"1": "Mumbai", "2": "Navi Mumbai", "3": "Jammu", "4": "Mumbra"}
+
+	tests := []struct {
+		strategy providers.MatchStrategy
+		query    string
+		want     string
+	}{
+		{providers.MatchPrefix, "mum", "[1 4]"},
+		{providers.MatchPrefix, "navi m", "[2]"},
+		{providers.MatchPrefix, "umb", "[]"},
+		{providers.MatchNGram, "mu", "[1 4 2]"},
+		{providers.MatchNGram, "umba", "[1 2]"},
+		{providers.MatchNGram, "mumbai", "[1 2]"},
+		{providers.MatchNGram, "xyz", "[]"},
+	}
+
+	config := startServer(t)
+	for i, tt := range tests {
+		provider := newTestProvider(t, config)
+		key := fmt.Sprintf("%s%d", testKey, i)
+		options := providers.IndexOptions{Score: 1.0, MatchStrategy: tt.strategy, NGramSize: 3}
+		for id, text := range entries {
+			if err := provider.Index(ctx, key, id, text, text, options); err != nil {
+				t.Fatalf("Index() error = %v", err)
+			}
+		}
+
+		if got := queryIDs(t, provider, key, tt.query, tt.strategy); got != tt.want {
+			t.Errorf("strategy %d: Query(%q) = %v, want %v", tt.strategy, tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestMemcachedProvider_UnsupportedStrategy(t *testing.T) {
+	provider := newTestProvider(t, startServer(t))
+	ctx := context.Background()
+
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchSubstring}
+	if err := provider.Index(ctx, testKey, "1", "Mumbai", "Mumbai", options); err == nil {
+		t.Error("Index() with MatchSubstring should fail")
+	}
+	if _, err := provider.Query(ctx, testKey, "mum", providers.QueryOptions{MatchStrategy: providers.MatchSubstring}); err == nil {
+		t.Error("Query() with MatchSubstring should fail")
+	}
+}
+
+func TestMemcachedProvider_UpdateAndDelete(t *testing.T) {
+	provider := newTestProvider(t, startServer(t))
+	ctx := context.Background()
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchNGram, NGramSize: 3, ContentHash: "h1"}
+
+	if err := provider.Index(ctx, testKey, "1", "Mumbai", "Mumbai", options); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	options.ContentHash = "h2"
+	if err := provider.Index(ctx, testKey, "1", "Pune", "Pune", options); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+
+	// The postings of "mumbai" no longer hold the entry
+	generation, _ := provider.generation(testKey)
+	item, err := provider.client.Get(provider.tokenKey(testKey, generation, "mum"))
+	if err != nil || string(item.Value) != "[]" {
+		t.Errorf("postings of mum after update = %v, %v; want an empty list", item, err)
+	}
+	if got := queryIDs(t, provider, testKey, "pun", providers.MatchNGram); got != "[1]" {
+		t.Errorf("Query(pun) = %v, want [1]", got)
+	}
+	hash, exists, err := provider.ContentHash(ctx, testKey, "1")
+	if err != nil || !exists || hash != "h2" {
+		t.Errorf("ContentHash() = %q, %v, %v; want h2, true, nil", hash, exists, err)
+	}
+
+	if err := provider.Delete(ctx, testKey, "1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if got := queryIDs(t, provider, testKey, "pun", providers.MatchNGram); got != "[]" {
+		t.Errorf("Query(pun) after delete = %v, want []", got)
+	}
+	if _, exists, _ := provider.ContentHash(ctx, testKey, "1"); exists {
+		t.Error("ContentHash() should not find a deleted entry")
+	}
+}
+
+func TestMemcachedProvider_SkipsStalePostings(t *testing.T) {
+	provider := newTestProvider(t, startServer(t))
+	ctx := context.Background()
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchPrefix}
+
+	if err := provider.Index(ctx, testKey, "1", "Mumbai", "Mumbai", options); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+
+	// An update that stopped after writing the entry leaves "mum" pointing at it
+	generation, _ := provider.generation(testKey)
+	if _, err := provider.swapEntry(provider.entryKey(testKey, generation, "1"), []byte(`{"text":"Pune","search_text":"pune","score":1}`)); err != nil {
+		t.Fatalf("swapEntry() error = %v", err)
+	}
+	if got := queryIDs(t, provider, testKey, "mum", providers.MatchPrefix); got != "[]" {
+		t.Errorf("Query(mum) = %v, want the stale posting skipped", got)
+	}
+}
+
+func TestMemcachedProvider_LongPrefixAndPostingsLimit(t *testing.T) {
+	config := startServer(t)
+	config.MaxPrefixLength = 4
+	config.MaxPostings = 2
+	provider := newTestProvider(t, config)
+	ctx := context.Background()
+
+	entries := map[string]struct {
+		text  string
+		score float64
+	}{
+		"1": {"Mumbai Central", 3},
+		"2": {"Mumbai CST", 2},
+		"3": {"Mumbra", 1},
+	}
+	for id, e := range entries {
+		options := providers.IndexOptions{Score: e.score, MatchStrategy: providers.MatchPrefix}
+		if err := provider.Index(ctx, testKey, id, e.text, e.text, options); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	// Queries longer than the stored prefixes are checked against the entries
+	if got := queryIDs(t, provider, testKey, "mumbai c", providers.MatchPrefix); got != "[1 2]" {
+		t.Errorf("Query(mumbai c) = %v, want [1 2]", got)
+	}
+	if got := queryIDs(t, provider, testKey, "mumbai cs", providers.MatchPrefix); got != "[2]" {
+		t.Errorf("Query(mumbai cs) = %v, want [2]", got)
+	}
+	// Postings keep only the highest scoring entries
+	if got := queryIDs(t, provider, testKey, "mum", providers.MatchPrefix); got != "[1 2]" {
+		t.Errorf("Query(mum) = %v, want the two highest scoring entries", got)
+	}
+	if got := queryIDs(t, provider, testKey, "mumbr", providers.MatchPrefix); got != "[]" {
+		t.Errorf("Query(mumbr) = %v, want the entry dropped from the postings of mumb", got)
+	}
+}
+
+func TestMemcachedProvider_DeleteAll(t *testing.T) {
+	provider := newTestProvider(t, startServer(t))
+	ctx := context.Background()
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchPrefix}
+
+	for _, key := range []string{testKey, "other"} {
+		if err := provider.Index(ctx, key, "1", "Mumbai", "Mumbai", options); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	if err := provider.DeleteAll(ctx, testKey); err != nil {
+		t.Fatalf("DeleteAll() error = %v", err)
+	}
+	if got := queryIDs(t, provider, testKey, "mum", providers.MatchPrefix); got != "[]" {
+		t.Errorf("Query() after DeleteAll = %v, want []", got)
+	}
+	if got := queryIDs(t, provider, "other", "mum", providers.MatchPrefix); got != "[1]" {
+		t.Errorf("Query() of another namespace = %v, want [1]", got)
+	}
+
+	// The namespace can be filled again
+	if err := provider.Index(ctx, testKey, "2", "Mumbra", "Mumbra", options); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if got := queryIDs(t, provider, testKey, "mum", providers.MatchPrefix); got != "[2]" {
+		t.Errorf("Query() after reindexing = %v, want [2]", got)
+	}
+}
+
+func TestNewRejectsInvalidKeyPrefix(t *testing.T) {
+	config := startServer(t)
+	for _, prefix := range []string{"auto complete:", strings.Repeat("a", maxKeyPrefixLength+1)} {
+		config.KeyPrefix = prefix
+		if _, err := New(config); err == nil {
+			t.Errorf("New() with key prefix %q should fail", prefix)
+		}
+	}
+}
+
+func TestMemcachedProvider_Stress(t *testing.T) {
+	config := startServer(t)
+	for _, strategy := range []autocomplete.MatchStrategy{autocomplete.MatchPrefix, autocomplete.MatchNGram} {
+		t.Run(strategy.String(), func(t *testing.T) {
+			options := autocomplete.DefaultOptions()
+			options.MatchStrategy = strategy
+			config.KeyPrefix = "stress-" + strategy.String() + ":"
+			ac, err := autocomplete.New("memcached", autocomplete.NewConfigWithOptions(config, options))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			defer func() { _ = ac.Close() }()
+
+			if err := stress.Run(context.Background(), ac, stress.Config{}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package memcached
+
+import (
+	"fmt"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// init registers the memcached provider. Import this package with a blank identifier
+// to use memcached as the autocomplete backend:
+//
+//	import _ "github.com/remiges-tech/autocomplete/providers/memcached"
+//
+//nolint:gochecknoinits // init() is the idiomatic pattern for provider registration
+func init() {
+	autocomplete.RegisterProvider("memcached", NewProvider)
+}
+
+// NewProvider creates a new memcached provider from the given configuration.
+// It implements ProviderFactory and expects config to be of type memcached.Config.
+func NewProvider(config interface{}) (providers.Provider, error) {
+	memcachedConfig, ok := config.(Config)
+	if !ok {
+		return nil, fmt.Errorf("invalid configuration type for memcached provider: expected memcached.Config, got %T", config)
+	}
+
+	return New(memcachedConfig)
+}
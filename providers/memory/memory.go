@@ -0,0 +1,713 @@
+// Package memory implements the autocomplete Provider interface entirely in process.
+// It needs no external service and suits small datasets such as country or state
+// lists, tests, and embedded use. Data is lost when the process exits unless
+// Config.SnapshotFile is set.
+package memory
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// defaultNGramSize is the default n-gram size when not specified in options.
+const defaultNGramSize = 3
+
+// Config holds in-memory provider options.
+type Config struct {
+	// SnapshotFile, if set, is the file the provider's entries and aliases are
+	// written to on Close and loaded from by New, so that a restarted process
+	// keeps its index without an external store. Writes made after the last
+	// Close are lost if the process exits without closing the provider; the
+	// filestore provider persists every write.
+	// Default: "" (no persistence)
+	SnapshotFile string
+}
+
+// Provider implements the autocomplete Provider interface with in-process maps.
+// Each namespace keeps its entries and an inverted index from token to the IDs
+// containing it, built with the same tokenization as the Redis provider.
+// Every write is numbered, and the IDs of deleted entries are remembered until
+// their namespace is cleared, so that ListChanges can report them.
+// Aliases map namespace names to the namespace every operation acts on.
+// All methods are safe for concurrent use.
+type Provider struct {
+	mu         sync.RWMutex
+	namespaces map[string]*namespace
+
+	// epoch identifies this provider's sequence numbers in cursors, so that a
+	// cursor from another instance, e.g. before a restart, is not resumed.
+	epoch string
+
+	// seq numbers writes; cleared holds the number of each key's last DeleteAll.
+	seq     uint64
+	cleared map[string]uint64
+
+	// aliases maps each alias to its target namespace.
+	aliases map[string]string
+
+	// snapshotFile is Config.SnapshotFile. closedSeq is the value of seq when
+	// Close last wrote it, so that closing twice in a row does not replace the
+	// snapshot with the empty provider.
+	snapshotFile string
+	closed       bool
+	closedSeq    uint64
+}
+
+// namespace holds the entries and token index of a single key.
+type namespace struct {
+	entries map[string]*entry
+
+	// tokens maps each token to the IDs containing it and the earliest position
+	// at which it occurs in their text.
+	tokens map[string]map[string]int
+
+	// deleted maps the IDs of deleted entries to the number of their deletion.
+	deleted map[string]uint64
+}
+
+// entry is a stored autocomplete entry.
+type entry struct {
+	text       string
+	searchText string
+	display    string
+	options    providers.IndexOptions
+	seq        uint64
+}
+
+// token is a single indexed token and the byte offset at which it starts.
+type token struct {
+	text     string
+	position int
+}
+
+// match is a candidate result with the data used to rank it.
+type match struct {
+	id       string
+	position int
+	entry    *entry
+}
+
+// New creates a new in-memory provider, holding the entries of
+// config.SnapshotFile if it is set and exists, and empty otherwise.
+func New(config Config) (*Provider, error) {
+	p := &Provider{
+		namespaces:   make(map[string]*namespace),
+		epoch:        rand.Text(),
+		cleared:      make(map[string]uint64),
+		aliases:      make(map[string]string),
+		snapshotFile: config.SnapshotFile,
+	}
+	if p.snapshotFile != "" {
+		if err := p.loadSnapshot(p.snapshotFile); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// Index adds or updates an entry, replacing the tokens of its previous text.
+func (p *Provider) Index(ctx context.Context, key, id, text, display string, options providers.IndexOptions) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.index(p.resolve(key), id, text, display, options)
+	return nil
+}
+
+// IndexAtomic writes all entries under a single lock, so queries observe
+// either none or all of them.
+func (p *Provider) IndexAtomic(ctx context.Context, key string, entries []providers.IndexEntry) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key = p.resolve(key)
+	for _, e := range entries {
+		p.index(key, e.ID, e.Text, e.Display, e.Options)
+	}
+	return nil
+}
+
+// index stores an entry. The caller must hold the write lock.
+func (p *Provider) index(key, id, text, display string, options providers.IndexOptions) {
+	ns := p.namespaces[key]
+	if ns == nil {
+		ns = &namespace{
+			entries: make(map[string]*entry),
+			tokens:  make(map[string]map[string]int),
+			deleted: make(map[string]uint64),
+		}
+		p.namespaces[key] = ns
+	}
+	ns.remove(id)
+	delete(ns.deleted, id)
+
+	p.seq++
+	searchText := providers.SearchText(text, options.CaseSensitive)
+	ns.entries[id] = &entry{text: text, searchText: searchText, display: display, options: options, seq: p.seq}
+
+	for _, tok := range tokenize(searchText, options) {
+		ids := ns.tokens[tok.text]
+		if ids == nil {
+			ids = make(map[string]int)
+			ns.tokens[tok.text] = ids
+		}
+		if position, seen := ids[id]; !seen || tok.position < position {
+			ids[id] = tok.position
+		}
+	}
+}
+
+// remove deletes an entry and its tokens from the namespace.
+func (ns *namespace) remove(id string) {
+	old, exists := ns.entries[id]
+	if !exists {
+		return
+	}
+
+	for _, tok := range tokenize(old.searchText, old.options) {
+		ids := ns.tokens[tok.text]
+		delete(ids, id)
+		if len(ids) == 0 {
+			delete(ns.tokens, tok.text)
+		}
+	}
+	delete(ns.entries, id)
+}
+
+// UpdateScore sets the score of a stored entry. Unknown IDs are ignored.
+func (p *Provider) UpdateScore(ctx context.Context, key, id string, score float64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if ns := p.namespaces[p.resolve(key)]; ns != nil && ns.entries[id] != nil {
+		ns.entries[id].options.Score = score
+	}
+	return nil
+}
+
+// IncrementScore adds delta to the score of a stored entry. Unknown IDs are ignored.
+func (p *Provider) IncrementScore(ctx context.Context, key, id string, delta float64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if ns := p.namespaces[p.resolve(key)]; ns != nil && ns.entries[id] != nil {
+		ns.entries[id].options.Score += delta
+	}
+	return nil
+}
+
+// ContentHash returns the stored content hash for an entry and whether it exists.
+func (p *Provider) ContentHash(ctx context.Context, key, id string) (string, bool, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	ns := p.namespaces[p.resolve(key)]
+	if ns == nil {
+		return "", false, nil
+	}
+	e, exists := ns.entries[id]
+	if !exists {
+		return "", false, nil
+	}
+	return e.options.ContentHash, true, nil
+}
+
+// Exists reports whether an entry is stored.
+func (p *Provider) Exists(ctx context.Context, key, id string) (bool, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	ns := p.namespaces[p.resolve(key)]
+	if ns == nil {
+		return false, nil
+	}
+	_, exists := ns.entries[id]
+	return exists, nil
+}
+
+// ListEntries returns up to count entries of the namespace in ID order,
+// starting after the ID given as cursor.
+func (p *Provider) ListEntries(ctx context.Context, key, cursor string, count int) ([]providers.StoredEntry, string, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	ns := p.namespaces[p.resolve(key)]
+	if ns == nil {
+		return nil, "", nil
+	}
+	ids := make([]string, 0, len(ns.entries))
+	for id := range ns.entries {
+		if id > cursor {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	next := ""
+	if count > 0 && len(ids) > count {
+		ids = ids[:count]
+		next = ids[count-1]
+	}
+	entries := make([]providers.StoredEntry, len(ids))
+	for i, id := range ids {
+		entries[i] = ns.entries[id].stored(id)
+	}
+	return entries, next, nil
+}
+
+// CountEntries returns the number of entries in the namespace.
+func (p *Provider) CountEntries(ctx context.Context, key string) (int64, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if ns := p.namespaces[p.resolve(key)]; ns != nil {
+		return int64(len(ns.entries)), nil
+	}
+	return 0, nil
+}
+
+// GetEntry returns a stored entry and whether it exists.
+func (p *Provider) GetEntry(ctx context.Context, key, id string) (providers.StoredEntry, bool, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	ns := p.namespaces[p.resolve(key)]
+	if ns == nil || ns.entries[id] == nil {
+		return providers.StoredEntry{}, false, nil
+	}
+	return ns.entries[id].stored(id), true, nil
+}
+
+// stored returns the entry as listed by ListEntries and GetEntry.
+func (e *entry) stored(id string) providers.StoredEntry {
+	return providers.StoredEntry{
+		ID:          id,
+		Text:        e.text,
+		Display:     e.display,
+		ContentHash: e.options.ContentHash,
+		Metadata:    e.options.Metadata,
+		Score:       e.options.Score,
+	}
+}
+
+// postingBytes approximates the overhead of one ID in a token's posting map,
+// beyond the bytes of the ID itself: the position and the map slot.
+const postingBytes = 16
+
+// IndexStats returns the size of the namespace. Bytes sums the lengths of the
+// stored strings and the token index; it leaves out Go's map and struct
+// overhead, so the process uses more. Details reports the number of distinct
+// tokens.
+func (p *Provider) IndexStats(ctx context.Context, key string) (providers.IndexStats, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	stats := providers.IndexStats{Details: map[string]string{"distinct_tokens": "0"}}
+	ns := p.namespaces[p.resolve(key)]
+	if ns == nil {
+		return stats, nil
+	}
+
+	stats.Entries = int64(len(ns.entries))
+	for id, e := range ns.entries {
+		stats.Bytes += int64(len(id) + len(e.text) + len(e.searchText) + len(e.display) +
+			len(e.options.ContentHash) + len(e.options.Metadata))
+	}
+	for tok, ids := range ns.tokens {
+		stats.Tokens += int64(len(ids))
+		stats.Bytes += int64(len(tok))
+		for id := range ids {
+			stats.Bytes += int64(len(id) + postingBytes)
+		}
+	}
+	stats.Details["distinct_tokens"] = strconv.Itoa(len(ns.tokens))
+	return stats, nil
+}
+
+// ListChanges returns the entries indexed and deleted since cursor, which
+// holds the provider's epoch and the number of the last write it covers.
+// A cursor of another provider instance lists every entry.
+func (p *Provider) ListChanges(ctx context.Context, key, cursor string) (providers.ChangeSet, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	since, resumable, err := p.parseCursor(cursor)
+	if err != nil {
+		return providers.ChangeSet{}, err
+	}
+	changes := providers.ChangeSet{Cursor: p.epoch + ":" + strconv.FormatUint(p.seq, 10)}
+	target := p.resolve(key)
+	if !resumable || since < p.cleared[key] || since < p.cleared[target] {
+		changes.Reset = true
+		since = 0
+	}
+
+	ns := p.namespaces[target]
+	if ns == nil {
+		return changes, nil
+	}
+	var ids []string
+	for id, e := range ns.entries {
+		if e.seq > since {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		e := ns.entries[id]
+		changes.Entries = append(changes.Entries, providers.StoredEntry{
+			ID: id, Text: e.text, Display: e.display, ContentHash: e.options.ContentHash, Metadata: e.options.Metadata,
+		})
+	}
+	if !changes.Reset {
+		for id, seq := range ns.deleted {
+			if seq > since {
+				changes.Deleted = append(changes.Deleted, id)
+			}
+		}
+		sort.Strings(changes.Deleted)
+	}
+	return changes, nil
+}
+
+// parseCursor returns the write number held by a cursor and whether it can be
+// resumed by this provider. The caller must hold the lock.
+func (p *Provider) parseCursor(cursor string) (uint64, bool, error) {
+	if cursor == "" {
+		return 0, false, nil
+	}
+	epoch, number, found := strings.Cut(cursor, ":")
+	seq, err := strconv.ParseUint(number, 10, 64)
+	if !found || err != nil {
+		return 0, false, fmt.Errorf("invalid change cursor %q", cursor)
+	}
+	return seq, epoch == p.epoch, nil
+}
+
+// Query searches for entries matching the given query. Results are ranked by
+// entry score, then match position, then text length.
+func (p *Provider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	searchQuery := providers.SearchText(query, options.CaseSensitive)
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	ns := p.namespaces[p.resolve(key)]
+	if ns == nil || searchQuery == "" {
+		return []providers.ProviderResult{}, nil
+	}
+
+	tag := ""
+	if len(options.MatchStrategies) > 0 {
+		tag = strategyTag(options.MatchStrategy)
+	}
+
+	n := getNGramSizeOrDefault(options.NGramSize)
+	var matches []match
+	switch {
+	case options.MatchStrategy == providers.MatchNOrMoreGram && len(searchQuery) < n:
+		return []providers.ProviderResult{}, nil
+	case options.MatchStrategy == providers.MatchNGram && len(searchQuery) > n:
+		matches = ns.intersect(tag, searchQuery, n)
+	default:
+		matches = ns.lookup(tag + searchQuery)
+	}
+
+	if len(options.FilterMetadata) > 0 {
+		matches = slices.DeleteFunc(matches, func(m match) bool {
+			return !providers.MatchesMetadata(m.entry.options.Metadata, options.FilterMetadata)
+		})
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.entry.options.Score != b.entry.options.Score {
+			return a.entry.options.Score > b.entry.options.Score
+		}
+		if a.position != b.position {
+			return a.position < b.position
+		}
+		if len(a.entry.text) != len(b.entry.text) {
+			return len(a.entry.text) < len(b.entry.text)
+		}
+		return a.id < b.id
+	})
+
+	if options.MaxResults > 0 && len(matches) > options.MaxResults {
+		matches = matches[:options.MaxResults]
+	}
+	results := make([]providers.ProviderResult, len(matches))
+	for i, m := range matches {
+		results[i] = providers.ProviderResult{ID: m.id, Display: m.entry.display, Score: m.entry.options.Score, Metadata: m.entry.options.Metadata}
+		if options.ExplainScores {
+			results[i].Breakdown = &providers.ScoreBreakdown{Entry: m.entry.options.Score}
+		}
+	}
+	return results, nil
+}
+
+// lookup returns the entries containing a single token.
+func (ns *namespace) lookup(tok string) []match {
+	ids := ns.tokens[tok]
+	matches := make([]match, 0, len(ids))
+	for id, position := range ids {
+		matches = append(matches, match{id: id, position: position, entry: ns.entries[id]})
+	}
+	return matches
+}
+
+// intersect returns the entries containing every n-gram of the query,
+// positioned at the query's first n-gram. Tag prefixes each n-gram.
+func (ns *namespace) intersect(tag, searchQuery string, n int) []match {
+	matches := ns.lookup(tag + searchQuery[:n])
+	for i := 1; i <= len(searchQuery)-n; i++ {
+		ids := ns.tokens[tag+searchQuery[i:i+n]]
+		kept := matches[:0]
+		for _, m := range matches {
+			if _, ok := ids[m.id]; ok {
+				kept = append(kept, m)
+			}
+		}
+		matches = kept
+	}
+	return matches
+}
+
+// Delete removes an entry from the index.
+func (p *Provider) Delete(ctx context.Context, key, id string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if ns := p.namespaces[p.resolve(key)]; ns != nil && ns.entries[id] != nil {
+		ns.remove(id)
+		p.seq++
+		ns.deleted[id] = p.seq
+	}
+	return nil
+}
+
+// DeleteAll removes all entries for a given key.
+func (p *Provider) DeleteAll(ctx context.Context, key string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key = p.resolve(key)
+	delete(p.namespaces, key)
+	p.seq++
+	p.cleared[key] = p.seq
+	return nil
+}
+
+// AliasNamespace makes operations on alias act on target. Change cursors of
+// the alias issued before list every entry of the new target.
+func (p *Provider) AliasNamespace(ctx context.Context, alias, target string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if target == "" {
+		delete(p.aliases, alias)
+	} else {
+		if target == alias {
+			return fmt.Errorf("namespace %q cannot be an alias of itself", alias)
+		}
+		if _, isAlias := p.aliases[target]; isAlias {
+			return fmt.Errorf("alias target %q is itself an alias", target)
+		}
+		if ns := p.namespaces[alias]; ns != nil && len(ns.entries) > 0 {
+			return fmt.Errorf("namespace %q holds entries and cannot become an alias", alias)
+		}
+		p.aliases[alias] = target
+	}
+	p.seq++
+	p.cleared[alias] = p.seq
+	return nil
+}
+
+// RenameNamespace moves the entries of from to to and re-points the aliases of from.
+func (p *Provider) RenameNamespace(ctx context.Context, from, to string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, key := range []string{from, to} {
+		if _, isAlias := p.aliases[key]; isAlias {
+			return fmt.Errorf("namespace %q is an alias and cannot be renamed", key)
+		}
+	}
+	if from == to {
+		return nil
+	}
+
+	if ns := p.namespaces[from]; ns != nil {
+		p.namespaces[to] = ns
+	} else {
+		delete(p.namespaces, to)
+	}
+	delete(p.namespaces, from)
+	for alias, target := range p.aliases {
+		if target == from {
+			p.aliases[alias] = to
+		}
+	}
+	p.seq++
+	p.cleared[from] = p.seq
+	p.cleared[to] = p.seq
+	return nil
+}
+
+// resolve returns the namespace an operation on key acts on. The caller must
+// hold the lock.
+func (p *Provider) resolve(key string) string {
+	if target, isAlias := p.aliases[key]; isAlias {
+		return target
+	}
+	return key
+}
+
+// Close writes Config.SnapshotFile, if set, and releases all stored data. The
+// provider can still be used afterwards and starts out empty. If the snapshot
+// cannot be written, the data is kept and an error returned.
+func (p *Provider) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.snapshotFile != "" && (!p.closed || p.seq != p.closedSeq) {
+		if err := p.writeSnapshot(p.snapshotFile); err != nil {
+			return err
+		}
+	}
+	p.closed = true
+	p.closedSeq = p.seq
+
+	p.namespaces = make(map[string]*namespace)
+	p.epoch = rand.Text()
+	p.cleared = make(map[string]uint64)
+	p.aliases = make(map[string]string)
+	return nil
+}
+
+// Compact copies the entries and token index of every namespace into maps
+// sized for their contents, releasing the memory that maps keep after their
+// elements are deleted. The IDs of deleted entries are kept for ListChanges.
+func (p *Provider) Compact(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for key, ns := range p.namespaces {
+		if len(ns.entries) == 0 && len(ns.deleted) == 0 {
+			delete(p.namespaces, key)
+			continue
+		}
+		ns.entries = maps.Clone(ns.entries)
+		tokens := make(map[string]map[string]int, len(ns.tokens))
+		for tok, ids := range ns.tokens {
+			tokens[tok] = maps.Clone(ids)
+		}
+		ns.tokens = tokens
+		ns.deleted = maps.Clone(ns.deleted)
+	}
+	return nil
+}
+
+// SupportsMatchStrategies reports that entries can be indexed under any
+// combination of strategies.
+func (p *Provider) SupportsMatchStrategies(strategies []providers.MatchStrategy) bool {
+	return true
+}
+
+// SupportsMatchStrategy reports that every match strategy, including
+// MatchSuffix and MatchAnchoredSubstring, is supported.
+func (p *Provider) SupportsMatchStrategy(strategy providers.MatchStrategy) bool {
+	return true
+}
+
+// SupportsMetadata reports that metadata payloads are stored with entries and
+// that queries filter by them.
+func (p *Provider) SupportsMetadata() bool {
+	return true
+}
+
+// strategyTag prefixes the tokens of one strategy when an entry is indexed under
+// several, keeping each strategy's tokens apart in the shared token index.
+func strategyTag(strategy providers.MatchStrategy) string {
+	return "\x00" + string(rune('0'+strategy))
+}
+
+// tokenize splits normalized text into the tokens stored for the given match strategy,
+// or for each of options.MatchStrategies with strategy-tagged tokens.
+// MatchNGram also stores the prefixes of each n-gram, so that queries
+// shorter than n match by direct lookup.
+func tokenize(text string, options providers.IndexOptions) []token {
+	if len(options.MatchStrategies) > 0 {
+		var tokens []token
+		for _, strategy := range options.MatchStrategies {
+			single := options
+			single.MatchStrategy = strategy
+			single.MatchStrategies = nil
+			tag := strategyTag(strategy)
+			for _, tok := range tokenize(text, single) {
+				tokens = append(tokens, token{text: tag + tok.text, position: tok.position})
+			}
+		}
+		return tokens
+	}
+
+	var tokens []token
+	switch options.MatchStrategy {
+	case providers.MatchPrefix:
+		for i := 1; i <= len(text); i++ {
+			tokens = append(tokens, token{text: text[:i]})
+		}
+
+	case providers.MatchNGram:
+		n := getNGramSizeOrDefault(options.NGramSize)
+		for start := 0; start <= len(text)-n; start++ {
+			for end := start + 1; end <= start+n; end++ {
+				tokens = append(tokens, token{text: text[start:end], position: start})
+			}
+		}
+
+	case providers.MatchNOrMoreGram:
+		tokens = substringTokens(text, getNGramSizeOrDefault(options.NGramSize))
+
+	case providers.MatchSubstring:
+		tokens = substringTokens(text, 1)
+
+	case providers.MatchSuffix:
+		for i := 0; i < len(text); i++ {
+			tokens = append(tokens, token{text: text[i:], position: i})
+		}
+
+	case providers.MatchAnchoredSubstring:
+		for _, span := range providers.AnchoredSubstrings(text, getNGramSizeOrDefault(options.NGramSize)) {
+			tokens = append(tokens, token{text: text[span.Start:span.End], position: span.Start})
+		}
+	}
+	return tokens
+}
+
+// substringTokens returns every substring of text at least minLength bytes long.
+func substringTokens(text string, minLength int) []token {
+	var tokens []token
+	for start := 0; start < len(text); start++ {
+		for end := start + minLength; end <= len(text); end++ {
+			tokens = append(tokens, token{text: text[start:end], position: start})
+		}
+	}
+	return tokens
+}
+
+func getNGramSizeOrDefault(size int) int {
+	if size <= 0 {
+		return defaultNGramSize
+	}
+	return size
+}
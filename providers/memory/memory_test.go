@@ -0,0 +1,723 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers"
+	"github.com/remiges-tech/autocomplete/stress"
+)
+
+const testKey = "test"
+
+func resultIDs(results []providers.ProviderResult) []string {
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+	return ids
+}
+
+func TestMemoryProvider_MatchStrategies(t *testing.T) {
+	entries := map[string]string{
+		"1": "Mumbai",
+		"2": "Navi Mumbai",
+		"3": "Jammu",
+	}
+
+	tests := []struct {
+		strategy providers.MatchStrategy
+		query    string
+		want     []string
+	}{
+		{providers.MatchPrefix, "mum", []string{"1"}},
+		{providers.MatchPrefix, "mu", []string{"1"}},
+		{providers.MatchNGram, "mum", []string{"1", "2"}},
+		{providers.MatchNGram, "mu", []string{"1", "2"}},
+		{providers.MatchNGram, "umba", []string{"1", "2"}},
+		{providers.MatchNGram, "mmu", []string{"3"}},
+		{providers.MatchNOrMoreGram, "mumb", []string{"1", "2"}},
+		{providers.MatchNOrMoreGram, "mu", []string{}},
+		{providers.MatchSubstring, "mu", []string{"1", "3", "2"}},
+		{providers.MatchSubstring, "xyz", []string{}},
+		{providers.MatchSuffix, "mumbai", []string{"1", "2"}},
+		{providers.MatchSuffix, "mmu", []string{"3"}},
+		{providers.MatchSuffix, "mum", []string{}},
+		{providers.MatchAnchoredSubstring, "mu", []string{"1", "2"}},
+		{providers.MatchAnchoredSubstring, "umb", []string{"1", "2"}},
+		{providers.MatchAnchoredSubstring, "navi mum", []string{"2"}},
+		{providers.MatchAnchoredSubstring, "i mum", []string{}},
+	}
+
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("strategy %d query %s", tt.strategy, tt.query), func(t *testing.T) {
+			provider, _ := New(Config{})
+			for id, text := range entries {
+				options := providers.IndexOptions{Score: 1.0, MatchStrategy: tt.strategy, NGramSize: 3}
+				if err := provider.Index(ctx, testKey, id, text, text, options); err != nil {
+					t.Fatalf("Index() error = %v", err)
+				}
+			}
+
+			results, err := provider.Query(ctx, testKey, tt.query, providers.QueryOptions{
+				MaxResults:    10,
+				MatchStrategy: tt.strategy,
+				NGramSize:     3,
+			})
+			if err != nil {
+				t.Fatalf("Query() error = %v", err)
+			}
+			if got := resultIDs(results); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Query(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryProvider_CaseSensitivity(t *testing.T) {
+	provider, _ := New(Config{})
+	ctx := context.Background()
+
+	if err := provider.Index(ctx, testKey, "1", "Mumbai", "Mumbai", providers.IndexOptions{
+		Score: 1.0, MatchStrategy: providers.MatchPrefix, CaseSensitive: true,
+	}); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+
+	for query, want := range map[string]int{"Mum": 1, "mum": 0} {
+		results, err := provider.Query(ctx, testKey, query, providers.QueryOptions{
+			MaxResults: 10, MatchStrategy: providers.MatchPrefix, CaseSensitive: true,
+		})
+		if err != nil {
+			t.Fatalf("Query() error = %v", err)
+		}
+		if len(results) != want {
+			t.Errorf("Query(%q) returned %d results, want %d", query, len(results), want)
+		}
+	}
+}
+
+func TestMemoryProvider_UpdateAndDelete(t *testing.T) {
+	provider, _ := New(Config{})
+	ctx := context.Background()
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchPrefix, ContentHash: "h1"}
+	queryOptions := providers.QueryOptions{MaxResults: 10, MatchStrategy: providers.MatchPrefix}
+
+	if err := provider.Index(ctx, testKey, "1", "Pune", "Pune", options); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if err := provider.Index(ctx, "other", "1", "Pune", "Pune", options); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if err := provider.Index(ctx, testKey, "1", "Nashik", "Nashik", options); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+
+	if results, _ := provider.Query(ctx, testKey, "pun", queryOptions); len(results) != 0 {
+		t.Errorf("previous text still matches after update: %v", resultIDs(results))
+	}
+	if results, _ := provider.Query(ctx, testKey, "nas", queryOptions); len(results) != 1 {
+		t.Errorf("updated text does not match: %v", resultIDs(results))
+	}
+	if hash, exists, _ := provider.ContentHash(ctx, testKey, "1"); !exists || hash != "h1" {
+		t.Errorf("ContentHash() = %q, %t, want h1, true", hash, exists)
+	}
+
+	if err := provider.Delete(ctx, testKey, "1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := provider.Delete(ctx, testKey, "missing"); err != nil {
+		t.Errorf("Delete() of missing entry error = %v", err)
+	}
+	if results, _ := provider.Query(ctx, testKey, "nas", queryOptions); len(results) != 0 {
+		t.Errorf("deleted entry still matches: %v", resultIDs(results))
+	}
+	if _, exists, _ := provider.ContentHash(ctx, testKey, "1"); exists {
+		t.Error("ContentHash() reports deleted entry as existing")
+	}
+	if len(provider.namespaces[testKey].tokens) != 0 {
+		t.Errorf("tokens left behind after delete: %v", provider.namespaces[testKey].tokens)
+	}
+
+	if err := provider.DeleteAll(ctx, "other"); err != nil {
+		t.Fatalf("DeleteAll() error = %v", err)
+	}
+	if results, _ := provider.Query(ctx, "other", "pun", queryOptions); len(results) != 0 {
+		t.Errorf("entry still matches after DeleteAll: %v", resultIDs(results))
+	}
+}
+
+func TestMemoryProvider_Ranking(t *testing.T) {
+	provider, _ := New(Config{})
+	ctx := context.Background()
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchSubstring}
+
+	for id, text := range map[string]string{"1": "Mumbai Central", "2": "Navi Mumbai", "3": "Mumbai"} {
+		if err := provider.Index(ctx, testKey, id, text, text, options); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	results, err := provider.Query(ctx, testKey, "mumbai", providers.QueryOptions{MaxResults: 2, MatchStrategy: providers.MatchSubstring})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if got := resultIDs(results); fmt.Sprint(got) != "[3 1]" {
+		t.Errorf("Query() = %v, want [3 1] (earliest match, then shortest text, limited to 2)", got)
+	}
+}
+
+func TestMemoryProvider_Concurrency(t *testing.T) {
+	provider, _ := New(Config{})
+	ctx := context.Background()
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchSubstring}
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for j := range 50 {
+				id := fmt.Sprintf("%d-%d", worker, j)
+				if err := provider.Index(ctx, testKey, id, "City "+id, "City "+id, options); err != nil {
+					t.Errorf("Index() error = %v", err)
+				}
+				if _, err := provider.Query(ctx, testKey, "city", providers.QueryOptions{MaxResults: 5}); err != nil {
+					t.Errorf("Query() error = %v", err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	results, err := provider.Query(ctx, testKey, "city", providers.QueryOptions{MaxResults: 1000, MatchStrategy: providers.MatchSubstring})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(results) != 400 {
+		t.Errorf("Query() returned %d results, want 400", len(results))
+	}
+}
+
+func TestMemoryProvider_Registration(t *testing.T) {
+	ac, err := autocomplete.New("memory", autocomplete.NewConfig(Config{}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer ac.Close()
+
+	ctx := context.Background()
+	if err := ac.Index(ctx, "IN", "India", "India"); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	results, err := ac.Query(ctx, "ind", 10)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Display != "India" {
+		t.Errorf("Query() = %+v, want India", results)
+	}
+}
+
+func TestMemoryProvider_MultipleStrategies(t *testing.T) {
+	provider, _ := New(Config{})
+	ctx := context.Background()
+	strategies := []providers.MatchStrategy{providers.MatchPrefix, providers.MatchSubstring}
+
+	for id, text := range map[string]string{"1": "Mumbai", "2": "Navi Mumbai"} {
+		options := providers.IndexOptions{Score: 1.0, MatchStrategies: strategies}
+		if err := provider.Index(ctx, testKey, id, text, text, options); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	for strategy, want := range map[providers.MatchStrategy]string{
+		providers.MatchPrefix:    "[1]",
+		providers.MatchSubstring: "[1 2]",
+	} {
+		results, err := provider.Query(ctx, testKey, "mum", providers.QueryOptions{
+			MaxResults: 10, MatchStrategy: strategy, MatchStrategies: strategies,
+		})
+		if err != nil {
+			t.Fatalf("Query() error = %v", err)
+		}
+		if got := fmt.Sprint(resultIDs(results)); got != want {
+			t.Errorf("strategy %d: Query() = %v, want %v", strategy, got, want)
+		}
+	}
+
+	if err := provider.Delete(ctx, testKey, "2"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := provider.Delete(ctx, testKey, "1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if len(provider.namespaces[testKey].tokens) != 0 {
+		t.Errorf("tokens left behind after delete: %d", len(provider.namespaces[testKey].tokens))
+	}
+}
+
+func TestMemoryProvider_ListEntries(t *testing.T) {
+	provider, _ := New(Config{})
+	ctx := context.Background()
+
+	for _, id := range []string{"3", "1", "2"} {
+		options := providers.IndexOptions{Score: 1.0, ContentHash: "h" + id}
+		if err := provider.Index(ctx, testKey, id, "City "+id, "Display "+id, options); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	first, next, err := provider.ListEntries(ctx, testKey, "", 2)
+	if err != nil || len(first) != 2 || next != "2" {
+		t.Fatalf("ListEntries() = %v, %q, %v; want 2 entries and cursor \"2\"", first, next, err)
+	}
+	want := providers.StoredEntry{ID: "1", Text: "City 1", Display: "Display 1", ContentHash: "h1", Score: 1}
+	if first[0] != want {
+		t.Errorf("ListEntries() first entry = %+v, want %+v", first[0], want)
+	}
+
+	rest, next, err := provider.ListEntries(ctx, testKey, next, 2)
+	if err != nil || len(rest) != 1 || rest[0].ID != "3" || next != "" {
+		t.Errorf("ListEntries() second page = %v, %q, %v; want entry 3 and no cursor", rest, next, err)
+	}
+}
+
+func TestMemoryProvider_Metadata(t *testing.T) {
+	provider, _ := New(Config{})
+	ctx := context.Background()
+
+	options := providers.IndexOptions{Score: 1.0, Metadata: `{"state":"MH"}`}
+	if err := provider.Index(ctx, testKey, "1", "Mumbai", "Mumbai", options); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	results, err := provider.Query(ctx, testKey, "mum", providers.QueryOptions{MaxResults: 10})
+	if err != nil || len(results) != 1 || results[0].Metadata != options.Metadata {
+		t.Errorf("Query() = %+v, %v; want entry 1 with metadata %s", results, err, options.Metadata)
+	}
+	entries, _, err := provider.ListEntries(ctx, testKey, "", 10)
+	if err != nil || len(entries) != 1 || entries[0].Metadata != options.Metadata {
+		t.Errorf("ListEntries() = %+v, %v; want entry 1 with metadata %s", entries, err, options.Metadata)
+	}
+
+	filter := providers.QueryOptions{MaxResults: 10, FilterMetadata: map[string]interface{}{"state": "MH"}}
+	if err := provider.Index(ctx, testKey, "2", "Mumbra", "Mumbra", providers.IndexOptions{Score: 2.0}); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	results, err = provider.Query(ctx, testKey, "mum", filter)
+	if err != nil || len(results) != 1 || results[0].ID != "1" {
+		t.Errorf("Query() with filter = %+v, %v; want only entry 1", results, err)
+	}
+	filter.FilterMetadata["state"] = "KA"
+	if results, _ = provider.Query(ctx, testKey, "mum", filter); len(results) != 0 {
+		t.Errorf("Query() with unmatched filter = %+v, want no results", results)
+	}
+
+	options.Metadata = ""
+	if err := provider.Index(ctx, testKey, "1", "Mumbai", "Mumbai", options); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	results, _ = provider.Query(ctx, testKey, "mumbai", providers.QueryOptions{MaxResults: 10})
+	if len(results) != 1 || results[0].Metadata != "" {
+		t.Errorf("Query() after re-indexing without metadata = %+v, want no metadata", results)
+	}
+}
+
+func TestMemoryProvider_ListChanges(t *testing.T) {
+	provider, _ := New(Config{})
+	ctx := context.Background()
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchPrefix}
+	for _, id := range []string{"1", "2", "3"} {
+		if err := provider.Index(ctx, testKey, id, "City "+id, "City "+id, options); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	full, err := provider.ListChanges(ctx, testKey, "")
+	if err != nil || !full.Reset || len(full.Entries) != 3 {
+		t.Fatalf("ListChanges() = %+v, %v; want a reset listing every entry", full, err)
+	}
+
+	_ = provider.Index(ctx, testKey, "2", "City two", "City two", options)
+	_ = provider.Delete(ctx, testKey, "3")
+	_ = provider.Delete(ctx, testKey, "missing")
+	_ = provider.Index(ctx, "other", "4", "City 4", "City 4", options)
+	delta, err := provider.ListChanges(ctx, testKey, full.Cursor)
+	if err != nil || delta.Reset || len(delta.Entries) != 1 || delta.Entries[0].Text != "City two" || fmt.Sprint(delta.Deleted) != "[3]" {
+		t.Errorf("ListChanges() = %+v, %v; want entry 2 updated and entry 3 deleted", delta, err)
+	}
+	if none, _ := provider.ListChanges(ctx, testKey, delta.Cursor); len(none.Entries)+len(none.Deleted) != 0 || none.Reset {
+		t.Errorf("ListChanges() without writes = %+v, want no changes", none)
+	}
+
+	_ = provider.DeleteAll(ctx, testKey)
+	_ = provider.Index(ctx, testKey, "5", "City 5", "City 5", options)
+	if cleared, _ := provider.ListChanges(ctx, testKey, delta.Cursor); !cleared.Reset || len(cleared.Entries) != 1 {
+		t.Errorf("ListChanges() after DeleteAll = %+v, want a reset listing entry 5", cleared)
+	}
+
+	restarted, _ := New(Config{})
+	if changes, err := restarted.ListChanges(ctx, testKey, delta.Cursor); err != nil || !changes.Reset {
+		t.Errorf("ListChanges() with another provider's cursor = %+v, %v; want a reset", changes, err)
+	}
+	if _, err := provider.ListChanges(ctx, testKey, "garbage"); err == nil {
+		t.Error("ListChanges() should reject a malformed cursor")
+	}
+}
+
+func TestMemoryProvider_AliasAndRenameNamespace(t *testing.T) {
+	provider, _ := New(Config{})
+	ctx := context.Background()
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchPrefix}
+	query := func(key string) string {
+		t.Helper()
+		results, err := provider.Query(ctx, key, "c", providers.QueryOptions{MatchStrategy: providers.MatchPrefix})
+		if err != nil {
+			t.Fatalf("Query() error = %v", err)
+		}
+		return fmt.Sprint(resultIDs(results))
+	}
+	_ = provider.Index(ctx, "products_v1", "1", "Chair", "Chair", options)
+	_ = provider.Index(ctx, "products_v2", "2", "Couch", "Couch", options)
+
+	if err := provider.AliasNamespace(ctx, "products", "products_v1"); err != nil {
+		t.Fatalf("AliasNamespace() error = %v", err)
+	}
+	if got := query("products"); got != "[1]" {
+		t.Errorf("Query() through alias = %v, want [1]", got)
+	}
+	cursor, _ := provider.ListChanges(ctx, "products", "")
+
+	// Re-pointing the alias switches every operation to the new target
+	if err := provider.AliasNamespace(ctx, "products", "products_v2"); err != nil {
+		t.Fatalf("AliasNamespace() error = %v", err)
+	}
+	if got := query("products"); got != "[2]" {
+		t.Errorf("Query() after re-pointing = %v, want [2]", got)
+	}
+	if changes, _ := provider.ListChanges(ctx, "products", cursor.Cursor); !changes.Reset || len(changes.Entries) != 1 {
+		t.Errorf("ListChanges() after re-pointing = %+v, want a reset listing the new target", changes)
+	}
+	_ = provider.Index(ctx, "products", "3", "Cot", "Cot", options)
+	if got := query("products_v2"); got != "[3 2]" {
+		t.Errorf("Query() of target = %v, want the entry written through the alias", got)
+	}
+
+	if err := provider.AliasNamespace(ctx, "products_v1", "products_v2"); err == nil {
+		t.Error("AliasNamespace() should refuse a namespace holding entries as alias")
+	}
+	if err := provider.AliasNamespace(ctx, "shop", "products"); err == nil {
+		t.Error("AliasNamespace() should refuse an alias as target")
+	}
+	if err := provider.RenameNamespace(ctx, "products", "shop"); err == nil {
+		t.Error("RenameNamespace() should refuse to rename an alias")
+	}
+
+	// Renaming replaces the entries of the new name and carries the alias along
+	if err := provider.RenameNamespace(ctx, "products_v2", "products_v1"); err != nil {
+		t.Fatalf("RenameNamespace() error = %v", err)
+	}
+	if got := query("products_v1"); got != "[3 2]" {
+		t.Errorf("Query() of new name = %v, want [3 2]", got)
+	}
+	if got := query("products_v2"); got != "[]" {
+		t.Errorf("Query() of old name = %v, want []", got)
+	}
+	if got := query("products"); got != "[3 2]" {
+		t.Errorf("Query() through alias after rename = %v, want [3 2]", got)
+	}
+
+	if err := provider.AliasNamespace(ctx, "products", ""); err != nil {
+		t.Fatalf("AliasNamespace() error = %v", err)
+	}
+	if got := query("products"); got != "[]" {
+		t.Errorf("Query() after removing the alias = %v, want []", got)
+	}
+}
+
+func TestMemoryProvider_NormalizationParity(t *testing.T) {
+	provider, _ := New(Config{})
+	ctx := context.Background()
+
+	texts := []string{"Navi Mumbai", "ÉCOLE Élémentaire", "Москва", "मुंबई"}
+	strategies := []providers.MatchStrategy{
+		providers.MatchPrefix, providers.MatchNGram, providers.MatchNOrMoreGram, providers.MatchSubstring,
+	}
+	for _, strategy := range strategies {
+		if err := provider.DeleteAll(ctx, testKey); err != nil {
+			t.Fatalf("DeleteAll() error = %v", err)
+		}
+		for i, text := range texts {
+			options := providers.IndexOptions{Score: 1.0, MatchStrategy: strategy, NGramSize: 3}
+			if err := provider.Index(ctx, testKey, fmt.Sprint(i), text, text, options); err != nil {
+				t.Fatalf("Index() error = %v", err)
+			}
+		}
+
+		// Each text, queried in any case, finds its own entry.
+		for i, text := range texts {
+			for _, query := range []string{text, strings.ToUpper(text), strings.ToLower(text)} {
+				results, err := provider.Query(ctx, testKey, query, providers.QueryOptions{
+					MaxResults: 10, MatchStrategy: strategy, NGramSize: 3,
+				})
+				if err != nil {
+					t.Fatalf("Query() error = %v", err)
+				}
+				if got := resultIDs(results); len(got) != 1 || got[0] != fmt.Sprint(i) {
+					t.Errorf("strategy %d: Query(%q) = %v, want [%d]", strategy, query, got, i)
+				}
+			}
+		}
+	}
+}
+
+func TestMemoryProvider_Stress(t *testing.T) {
+	for _, strategy := range []autocomplete.MatchStrategy{autocomplete.MatchPrefix, autocomplete.MatchNGram, autocomplete.MatchSubstring} {
+		t.Run(strategy.String(), func(t *testing.T) {
+			options := autocomplete.DefaultOptions()
+			options.MatchStrategy = strategy
+			ac, err := autocomplete.New("memory", autocomplete.NewConfigWithOptions(Config{}, options))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			defer func() { _ = ac.Close() }()
+
+			if err := stress.Run(context.Background(), ac, stress.Config{}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestMemoryProvider_Exists(t *testing.T) {
+	provider, _ := New(Config{})
+	ctx := context.Background()
+
+	if exists, err := provider.Exists(ctx, testKey, "1"); err != nil || exists {
+		t.Errorf("Exists() before Index = %v, %v; want false", exists, err)
+	}
+	if err := provider.Index(ctx, testKey, "1", "Mumbai", "Mumbai", providers.IndexOptions{Score: 1.0}); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if exists, err := provider.Exists(ctx, testKey, "1"); err != nil || !exists {
+		t.Errorf("Exists() after Index = %v, %v; want true", exists, err)
+	}
+	if exists, _ := provider.Exists(ctx, "other", "1"); exists {
+		t.Error("Exists() in another namespace = true, want false")
+	}
+	if count, err := provider.CountEntries(ctx, testKey); err != nil || count != 1 {
+		t.Errorf("CountEntries() = %d, %v; want 1", count, err)
+	}
+	if count, _ := provider.CountEntries(ctx, "other"); count != 0 {
+		t.Errorf("CountEntries() of another namespace = %d, want 0", count)
+	}
+	if err := provider.Delete(ctx, testKey, "1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if exists, err := provider.Exists(ctx, testKey, "1"); err != nil || exists {
+		t.Errorf("Exists() after Delete = %v, %v; want false", exists, err)
+	}
+}
+
+func TestMemoryProvider_IndexStats(t *testing.T) {
+	provider, _ := New(Config{})
+	ctx := context.Background()
+
+	empty, err := provider.IndexStats(ctx, testKey)
+	if err != nil || empty.Entries != 0 || empty.Tokens != 0 || empty.Bytes != 0 {
+		t.Errorf("IndexStats() of empty namespace = %+v, %v; want zero", empty, err)
+	}
+
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchPrefix}
+	for id, city := range map[string]string{"1": "Pune", "2": "Puri"} {
+		if err := provider.Index(ctx, testKey, id, city, city, options); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+	stats, err := provider.IndexStats(ctx, testKey)
+	if err != nil {
+		t.Fatalf("IndexStats() error = %v", err)
+	}
+	if stats.Entries != 2 || stats.Tokens != 8 || stats.Details["distinct_tokens"] != "6" {
+		t.Errorf("IndexStats() = %+v; want 2 entries, 8 tokens, 6 distinct", stats)
+	}
+
+	if err := provider.Delete(ctx, testKey, "1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	shrunk, err := provider.IndexStats(ctx, testKey)
+	if err != nil {
+		t.Fatalf("IndexStats() error = %v", err)
+	}
+	if shrunk.Entries != 1 || shrunk.Tokens != 4 || shrunk.Bytes >= stats.Bytes {
+		t.Errorf("IndexStats() after Delete = %+v; want 1 entry, 4 tokens, and fewer than %d bytes", shrunk, stats.Bytes)
+	}
+}
+
+func TestMemoryProvider_GetEntry(t *testing.T) {
+	provider, _ := New(Config{})
+	ctx := context.Background()
+
+	options := providers.IndexOptions{Score: 1.0, ContentHash: "h1", Metadata: `{"state":"MH"}`}
+	if err := provider.Index(ctx, testKey, "1", "Mumbai", "Mumbai GPO", options); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if err := provider.IncrementScore(ctx, testKey, "1", 2); err != nil {
+		t.Fatalf("IncrementScore() error = %v", err)
+	}
+
+	entry, exists, err := provider.GetEntry(ctx, testKey, "1")
+	want := providers.StoredEntry{ID: "1", Text: "Mumbai", Display: "Mumbai GPO", ContentHash: "h1", Metadata: `{"state":"MH"}`, Score: 3}
+	if err != nil || !exists || entry != want {
+		t.Errorf("GetEntry() = %+v, %t, %v; want %+v, true", entry, exists, err, want)
+	}
+	if _, exists, err := provider.GetEntry(ctx, testKey, "2"); err != nil || exists {
+		t.Errorf("GetEntry() of unknown ID exists = %t, %v; want false", exists, err)
+	}
+}
+
+func TestMemoryProvider_Compact(t *testing.T) {
+	provider, _ := New(Config{})
+	ctx := context.Background()
+
+	for i := 0; i < 100; i++ {
+		text := fmt.Sprintf("City %03d", i)
+		if err := provider.Index(ctx, testKey, fmt.Sprint(i), text, text, providers.IndexOptions{Score: 1.0}); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+	before, err := provider.ListChanges(ctx, testKey, "")
+	if err != nil {
+		t.Fatalf("ListChanges() error = %v", err)
+	}
+	for i := 10; i < 100; i++ {
+		if err := provider.Delete(ctx, testKey, fmt.Sprint(i)); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+	}
+	if err := provider.Index(ctx, "emptied", "1", "Pune", "Pune", providers.IndexOptions{Score: 1.0}); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if err := provider.DeleteAll(ctx, "emptied"); err != nil {
+		t.Fatalf("DeleteAll() error = %v", err)
+	}
+	if err := provider.Compact(ctx); err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+
+	results, err := provider.Query(ctx, testKey, "city 0", providers.QueryOptions{MaxResults: 20})
+	if err != nil || len(results) != 10 {
+		t.Errorf("Query() after Compact() = %v, %v; want the 10 remaining entries", resultIDs(results), err)
+	}
+	changes, err := provider.ListChanges(ctx, testKey, before.Cursor)
+	if err != nil || len(changes.Deleted) != 90 {
+		t.Errorf("ListChanges() after Compact() = %d deleted, %v; want 90", len(changes.Deleted), err)
+	}
+}
+
+func TestMemoryProvider_SnapshotFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.gob")
+	ctx := context.Background()
+
+	provider, err := New(Config{SnapshotFile: path})
+	if err != nil {
+		t.Fatalf("New() without snapshot error = %v", err)
+	}
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchSubstring, ContentHash: "h1", Metadata: `{"state":"MH"}`}
+	if err := provider.Index(ctx, testKey, "1", "Mumbai", "Mumbai, MH", options); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if err := provider.Index(ctx, testKey, "2", "Navi Mumbai", "Navi Mumbai", providers.IndexOptions{Score: 1.0}); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if err := provider.UpdateScore(ctx, testKey, "2", 5); err != nil {
+		t.Fatalf("UpdateScore() error = %v", err)
+	}
+	if err := provider.AliasNamespace(ctx, "live", testKey); err != nil {
+		t.Fatalf("AliasNamespace() error = %v", err)
+	}
+	if err := provider.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := provider.Close(); err != nil {
+		t.Fatalf("second Close() error = %v", err)
+	}
+
+	reopened, err := New(Config{SnapshotFile: path})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	results, err := reopened.Query(ctx, "live", "umb", providers.QueryOptions{MaxResults: 10})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "1" || results[0].Display != "Mumbai, MH" || results[0].Metadata != `{"state":"MH"}` {
+		t.Errorf("substring Query() through alias after reopening = %+v, want entry 1 with its display and metadata", results)
+	}
+	results, _ = reopened.Query(ctx, testKey, "navi", providers.QueryOptions{MaxResults: 10})
+	if len(results) != 1 || results[0].Score != 5 {
+		t.Errorf("prefix Query() after reopening = %+v, want entry 2 with score 5", results)
+	}
+	if hash, exists, _ := reopened.ContentHash(ctx, testKey, "1"); !exists || hash != "h1" {
+		t.Errorf("ContentHash() after reopening = %q, %t, want h1, true", hash, exists)
+	}
+
+	if err := os.WriteFile(path, []byte("not a snapshot"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := New(Config{SnapshotFile: path}); err == nil {
+		t.Error("New() with a corrupt snapshot should fail")
+	}
+}
+
+func TestMemoryProvider_UpdateScore(t *testing.T) {
+	provider, _ := New(Config{})
+	ctx := context.Background()
+
+	for id, city := range map[string]string{"1": "Mumbai", "2": "Mumbra"} {
+		if err := provider.Index(ctx, testKey, id, city, city, providers.IndexOptions{Score: 1.0}); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+	if err := provider.UpdateScore(ctx, testKey, "2", 5); err != nil {
+		t.Fatalf("UpdateScore() error = %v", err)
+	}
+	if err := provider.UpdateScore(ctx, testKey, "missing", 5); err != nil {
+		t.Errorf("UpdateScore() of unknown ID error = %v", err)
+	}
+	if err := provider.IncrementScore(ctx, testKey, "1", 3); err != nil {
+		t.Fatalf("IncrementScore() error = %v", err)
+	}
+	if err := provider.IncrementScore(ctx, testKey, "missing", 3); err != nil {
+		t.Errorf("IncrementScore() of unknown ID error = %v", err)
+	}
+
+	results, err := provider.Query(ctx, testKey, "mum", providers.QueryOptions{MaxResults: 10})
+	if err != nil || len(results) != 2 || results[0].ID != "2" || results[0].Score != 5 || results[1].Score != 4 {
+		t.Errorf("Query() = %+v, %v; want entry 2 with score 5, then entry 1 with score 4", results, err)
+	}
+}
+
+func TestMemoryProvider_ExplainScores(t *testing.T) {
+	provider, _ := New(Config{})
+	ctx := context.Background()
+
+	if err := provider.Index(ctx, testKey, "1", "Mumbai", "Mumbai", providers.IndexOptions{Score: 4}); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	results, err := provider.Query(ctx, testKey, "mum", providers.QueryOptions{MaxResults: 10})
+	if err != nil || len(results) != 1 || results[0].Breakdown != nil {
+		t.Errorf("Query() = %+v, %v; want one result without breakdown", results, err)
+	}
+	results, err = provider.Query(ctx, testKey, "mum", providers.QueryOptions{MaxResults: 10, ExplainScores: true})
+	if err != nil || len(results) != 1 || results[0].Breakdown == nil || *results[0].Breakdown != (providers.ScoreBreakdown{Entry: 4}) {
+		t.Errorf("Query() with ExplainScores = %+v, %v; want entry score 4 in the breakdown", results, err)
+	}
+}
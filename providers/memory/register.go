@@ -0,0 +1,32 @@
+package memory
+
+import (
+	"fmt"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// init registers the in-memory provider. Import this package with a blank identifier
+// to use it as the autocomplete backend:
+//
+//	import _ "github.com/remiges-tech/autocomplete/providers/memory"
+//
+//nolint:gochecknoinits // init() is the idiomatic pattern for provider registration
+func init() {
+	autocomplete.RegisterProvider("memory", NewProvider)
+}
+
+// NewProvider creates a new in-memory provider from the given configuration.
+// It implements ProviderFactory and expects config to be of type memory.Config or nil.
+func NewProvider(config interface{}) (providers.Provider, error) {
+	if config == nil {
+		return New(Config{})
+	}
+	memoryConfig, ok := config.(Config)
+	if !ok {
+		return nil, fmt.Errorf("invalid configuration type for memory provider: expected memory.Config, got %T", config)
+	}
+
+	return New(memoryConfig)
+}
@@ -0,0 +1,111 @@
+package memory
+
+import (
+	"cmp"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// snapshotVersion is written at the start of every snapshot file, so that a
+// file written in another format is rejected rather than misread.
+const snapshotVersion = 1
+
+// snapshot is the content of a snapshot file.
+type snapshot struct {
+	Version    int
+	Namespaces map[string][]snapshotEntry
+	Aliases    map[string]string
+}
+
+// snapshotEntry is a stored entry with the options it was indexed with.
+type snapshotEntry struct {
+	ID      string
+	Text    string
+	Display string
+	Options providers.IndexOptions
+}
+
+// loadSnapshot indexes the entries and restores the aliases of the snapshot
+// at path, if there is one.
+func (p *Provider) loadSnapshot(path string) error {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var s snapshot
+	if err := gob.NewDecoder(f).Decode(&s); err != nil {
+		return fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	if s.Version != snapshotVersion {
+		return fmt.Errorf("failed to read snapshot: unsupported version %d", s.Version)
+	}
+	for key, entries := range s.Namespaces {
+		for _, e := range entries {
+			p.index(key, e.ID, e.Text, e.Display, e.Options)
+		}
+	}
+	for alias, target := range s.Aliases {
+		p.aliases[alias] = target
+	}
+	return nil
+}
+
+// writeSnapshot writes every entry and alias to path, replacing the previous
+// snapshot only once the new one is complete. Entries are written in the order
+// they were last written, which loading them again keeps. The caller must hold
+// the lock.
+func (p *Provider) writeSnapshot(path string) error {
+	s := snapshot{
+		Version:    snapshotVersion,
+		Namespaces: make(map[string][]snapshotEntry, len(p.namespaces)),
+		Aliases:    p.aliases,
+	}
+	for key, ns := range p.namespaces {
+		ids := make([]string, 0, len(ns.entries))
+		for id := range ns.entries {
+			ids = append(ids, id)
+		}
+		slices.SortFunc(ids, func(a, b string) int {
+			return cmp.Compare(ns.entries[a].seq, ns.entries[b].seq)
+		})
+		entries := make([]snapshotEntry, len(ids))
+		for i, id := range ids {
+			e := ns.entries[id]
+			entries[i] = snapshotEntry{ID: id, Text: e.text, Display: e.display, Options: e.options}
+		}
+		s.Namespaces[key] = entries
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if err := gob.NewEncoder(tmp).Encode(s); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to sync snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace snapshot: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package providers
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// MatchesMetadata reports whether a metadata payload satisfies a
+// QueryOptions.FilterMetadata filter: every filter key must name a top-level
+// field of the payload whose value equals the filter value, compared as JSON,
+// so that 400001 matches 400001.0. An empty filter matches every entry; a
+// non-empty one never matches entries without metadata.
+func MatchesMetadata(metadata string, filter map[string]interface{}) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(metadata), &fields); err != nil {
+		return false
+	}
+	for name, want := range filter {
+		field, ok := fields[name]
+		if !ok || !jsonEqual(field, want) {
+			return false
+		}
+	}
+	return true
+}
+
+// jsonEqual reports whether a raw JSON value equals value once encoded as JSON.
+func jsonEqual(raw json.RawMessage, value interface{}) bool {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+	var got, want interface{}
+	if json.Unmarshal(raw, &got) != nil || json.Unmarshal(encoded, &want) != nil {
+		return false
+	}
+	return reflect.DeepEqual(got, want)
+}
+
+// FilterByMetadata returns the results whose metadata satisfies filter, in
+// order, for providers that filter after fetching candidates.
+func FilterByMetadata(results []ProviderResult, filter map[string]interface{}) []ProviderResult {
+	if len(filter) == 0 {
+		return results
+	}
+	filtered := results[:0]
+	for _, result := range results {
+		if MatchesMetadata(result.Metadata, filter) {
+			filtered = append(filtered, result)
+		}
+	}
+	return filtered
+}
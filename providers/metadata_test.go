@@ -0,0 +1,32 @@
+package providers
+
+import "testing"
+
+func TestMatchesMetadata(t *testing.T) {
+	const metadata = `{"state":"Maharashtra","pin":400001,"metro":true,"tags":["coastal"]}`
+	tests := []struct {
+		name     string
+		metadata string
+		filter   map[string]interface{}
+		want     bool
+	}{
+		{"no filter", "", nil, true},
+		{"string", metadata, map[string]interface{}{"state": "Maharashtra"}, true},
+		{"number", metadata, map[string]interface{}{"pin": 400001}, true},
+		{"float number", metadata, map[string]interface{}{"pin": 400001.0}, true},
+		{"bool", metadata, map[string]interface{}{"metro": true}, true},
+		{"array", metadata, map[string]interface{}{"tags": []string{"coastal"}}, true},
+		{"all fields", metadata, map[string]interface{}{"state": "Maharashtra", "metro": true}, true},
+		{"one field differs", metadata, map[string]interface{}{"state": "Maharashtra", "metro": false}, false},
+		{"different case", metadata, map[string]interface{}{"state": "maharashtra"}, false},
+		{"number as string", metadata, map[string]interface{}{"pin": "400001"}, false},
+		{"missing field", metadata, map[string]interface{}{"district": "Mumbai"}, false},
+		{"no metadata", "", map[string]interface{}{"state": "Maharashtra"}, false},
+		{"not an object", `[1,2]`, map[string]interface{}{"state": "Maharashtra"}, false},
+	}
+	for _, tt := range tests {
+		if got := MatchesMetadata(tt.metadata, tt.filter); got != tt.want {
+			t.Errorf("%s: MatchesMetadata(%s, %v) = %t, want %t", tt.name, tt.metadata, tt.filter, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+// Package mongodb implements the autocomplete Provider interface using MongoDB,
+// with regular expression queries over an indexed field and optional Atlas Search.
+package mongodb
+
+const (
+	// defaultDatabase is the database used when Config.Database is empty.
+	defaultDatabase = "autocomplete"
+
+	// defaultCollection is the collection used when Config.Collection is empty.
+	defaultCollection = "entries"
+)
+
+// Config holds MongoDB connection parameters and provider-specific options.
+type Config struct {
+	// URI is the MongoDB connection string, e.g. "mongodb://localhost:27017".
+	URI string
+
+	// Database is the database holding the entries collection.
+	// Default: "autocomplete"
+	Database string
+
+	// Collection is the collection holding autocomplete entries. All namespaces
+	// share the collection and are isolated by its key field.
+	// Default: "entries"
+	Collection string
+
+	// AtlasSearchIndex is the name of an Atlas Search index on the collection,
+	// defined as in AtlasSearchIndexDefinition. When set, queries first narrow
+	// candidates with the Atlas Search autocomplete operator and then apply the
+	// same match rules as the regular expression path, so results are identical.
+	// Empty (default) uses regular expression queries only, which work on any deployment.
+	AtlasSearchIndex string
+}
+
+// setDefaults applies default values to config fields.
+func (c *Config) setDefaults() {
+	if c.Database == "" {
+		c.Database = defaultDatabase
+	}
+	if c.Collection == "" {
+		c.Collection = defaultCollection
+	}
+}
@@ -0,0 +1,290 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+const (
+	// defaultNGramSize is the default n-gram size when not specified in options.
+	defaultNGramSize = 3
+
+	// atlasMinGrams and atlasMaxGrams bound the query lengths, in characters, that the
+	// autocomplete mapping in AtlasSearchIndexDefinition can answer. Other queries
+	// fall back to the regular expression path.
+	atlasMinGrams = 2
+	atlasMaxGrams = 15
+
+	// AtlasSearchIndexDefinition is the Atlas Search index definition expected by
+	// Config.AtlasSearchIndex. It maps search_text as an nGram autocomplete field,
+	// so that candidates for prefix, substring, and n-gram queries can be found.
+	AtlasSearchIndexDefinition = `{
+  "mappings": {
+    "dynamic": false,
+    "fields": {
+      "key": {"type": "token"},
+      "search_text": {"type": "autocomplete", "tokenization": "nGram", "minGrams": 2, "maxGrams": 15, "foldDiacritics": false}
+    }
+  }
+}`
+)
+
+// upsertOptions makes ReplaceOne insert the entry when none exists with the same key and id.
+var upsertOptions = options.Replace().SetUpsert(true)
+
+// entryDocument is the stored form of an autocomplete entry.
+type entryDocument struct {
+	Key           string  `bson:"key"`
+	ID            string  `bson:"id"`
+	Text          string  `bson:"text"`
+	SearchText    string  `bson:"search_text"`
+	Display       string  `bson:"display"`
+	Score         float64 `bson:"score"`
+	CaseSensitive bool    `bson:"case_sensitive"`
+	ContentHash   string  `bson:"content_hash"`
+}
+
+// Provider implements the autocomplete Provider interface using MongoDB.
+// Entries of all namespaces are documents of a single collection, isolated by the key
+// field, and matched with anchored or unanchored regular expressions on the normalized
+// search_text field. When an Atlas Search index is configured, it narrows the
+// candidates before the regular expression is applied.
+// All methods are safe for concurrent use.
+type Provider struct {
+	client      *mongo.Client
+	collection  *mongo.Collection
+	atlasSearch string
+}
+
+// New creates a new MongoDB provider with the given configuration.
+// It verifies connectivity and creates the collection's indexes.
+func New(config Config) (*Provider, error) {
+	config.setDefaults()
+
+	client, err := mongo.Connect(options.Client().ApplyURI(config.URI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create MongoDB client: %w", err)
+	}
+
+	ctx := context.Background()
+	if err := client.Ping(ctx, nil); err != nil {
+		_ = client.Disconnect(ctx)
+		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
+	}
+
+	collection := client.Database(config.Database).Collection(config.Collection)
+	_, err = collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "key", Value: 1}, {Key: "id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			// Serves anchored prefix regular expressions within a namespace
+			Keys: bson.D{{Key: "key", Value: 1}, {Key: "search_text", Value: 1}},
+		},
+	})
+	if err != nil {
+		_ = client.Disconnect(ctx)
+		return nil, fmt.Errorf("failed to create indexes: %w", err)
+	}
+
+	return &Provider{client: client, collection: collection, atlasSearch: config.AtlasSearchIndex}, nil
+}
+
+// Index adds or updates an entry in the autocomplete index.
+func (p *Provider) Index(ctx context.Context, key, id, text, display string, options providers.IndexOptions) error {
+	searchText := providers.SearchText(text, options.CaseSensitive)
+
+	doc := entryDocument{
+		Key:           key,
+		ID:            id,
+		Text:          text,
+		SearchText:    searchText,
+		Display:       display,
+		Score:         options.Score,
+		CaseSensitive: options.CaseSensitive,
+		ContentHash:   options.ContentHash,
+	}
+	filter := bson.D{{Key: "key", Value: key}, {Key: "id", Value: id}}
+	if _, err := p.collection.ReplaceOne(ctx, filter, doc, upsertOptions); err != nil {
+		return fmt.Errorf("failed to index entry: %w", err)
+	}
+	return nil
+}
+
+// ContentHash returns the stored content hash for an entry and whether it exists.
+func (p *Provider) ContentHash(ctx context.Context, key, id string) (string, bool, error) {
+	var doc struct {
+		ContentHash string `bson:"content_hash"`
+	}
+	err := p.collection.FindOne(ctx,
+		bson.D{{Key: "key", Value: key}, {Key: "id", Value: id}},
+		options.FindOne().SetProjection(bson.D{{Key: "content_hash", Value: 1}}),
+	).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get content hash: %w", err)
+	}
+	return doc.ContentHash, true, nil
+}
+
+// Query searches for entries matching the given query.
+// Results are ranked by entry score, then match position, then text length.
+func (p *Provider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	searchQuery := providers.SearchText(query, options.CaseSensitive)
+
+	filter, ok := matchFilter(searchQuery, options)
+	if !ok {
+		return []providers.ProviderResult{}, nil
+	}
+
+	cursor, err := p.collection.Aggregate(ctx, p.pipeline(key, searchQuery, filter, options.MaxResults))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query autocomplete: %w", err)
+	}
+
+	var docs []entryDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("failed to read query results: %w", err)
+	}
+
+	results := make([]providers.ProviderResult, len(docs))
+	for i, doc := range docs {
+		results[i] = providers.ProviderResult{ID: doc.ID, Display: doc.Display, Score: doc.Score}
+	}
+	return results, nil
+}
+
+// pipeline builds the aggregation that selects matching entries of a namespace and
+// ranks them. Entries whose search_text does not contain the query verbatim (n-gram
+// matches of long queries) rank after those that do.
+func (p *Provider) pipeline(key, searchQuery string, filter bson.D, limit int) mongo.Pipeline {
+	var stages mongo.Pipeline
+
+	if p.atlasSearch != "" {
+		if length := utf8.RuneCountInString(searchQuery); length >= atlasMinGrams && length <= atlasMaxGrams {
+			stages = append(stages, bson.D{{Key: "$search", Value: bson.D{
+				{Key: "index", Value: p.atlasSearch},
+				{Key: "autocomplete", Value: bson.D{
+					{Key: "query", Value: searchQuery},
+					{Key: "path", Value: "search_text"},
+				}},
+			}}})
+		}
+	}
+
+	match := append(bson.D{{Key: "key", Value: key}}, filter...)
+	position := bson.D{{Key: "$indexOfBytes", Value: bson.A{"$search_text", searchQuery}}}
+	stages = append(stages,
+		bson.D{{Key: "$match", Value: match}},
+		bson.D{{Key: "$addFields", Value: bson.D{
+			{Key: "_position", Value: bson.D{{Key: "$cond", Value: bson.A{
+				bson.D{{Key: "$lt", Value: bson.A{position, 0}}}, math.MaxInt32, position,
+			}}}},
+			{Key: "_length", Value: bson.D{{Key: "$strLenBytes", Value: "$text"}}},
+		}}},
+		bson.D{{Key: "$sort", Value: bson.D{
+			{Key: "score", Value: -1},
+			{Key: "_position", Value: 1},
+			{Key: "_length", Value: 1},
+			{Key: "id", Value: 1},
+		}}},
+	)
+	if limit > 0 {
+		stages = append(stages, bson.D{{Key: "$limit", Value: limit}})
+	}
+	return append(stages, bson.D{{Key: "$project", Value: bson.D{
+		{Key: "_id", Value: 0},
+		{Key: "id", Value: 1},
+		{Key: "display", Value: 1},
+		{Key: "score", Value: 1},
+	}}})
+}
+
+// matchFilter returns the filter selecting entries whose search_text matches the query
+// under the given strategy. It reports false when no entry can match, e.g. a query
+// shorter than the n-gram size under MatchNOrMoreGram.
+func matchFilter(searchQuery string, options providers.QueryOptions) (bson.D, bool) {
+	if searchQuery == "" {
+		return nil, false
+	}
+	quoted := regexp.QuoteMeta(searchQuery)
+	n := options.NGramSize
+	if n <= 0 {
+		n = defaultNGramSize
+	}
+
+	switch options.MatchStrategy {
+	case providers.MatchPrefix:
+		return searchTextRegex("^" + quoted), true
+
+	case providers.MatchNGram:
+		if len(searchQuery) <= n {
+			// An n-gram starts with the query, so at least n-len(query) characters follow it
+			return searchTextRegex(quoted + fmt.Sprintf(".{%d}", n-len(searchQuery))), true
+		}
+		// Longer queries match entries containing every n-gram of the query
+		clauses := make(bson.A, 0, len(searchQuery)-n+1)
+		for i := 0; i <= len(searchQuery)-n; i++ {
+			clauses = append(clauses, searchTextRegex(regexp.QuoteMeta(searchQuery[i:i+n])))
+		}
+		return bson.D{{Key: "$and", Value: clauses}}, true
+
+	case providers.MatchNOrMoreGram:
+		if len(searchQuery) < n {
+			return nil, false
+		}
+		return searchTextRegex(quoted), true
+
+	default:
+		return searchTextRegex(quoted), true
+	}
+}
+
+// searchTextRegex returns a filter matching search_text against pattern.
+// The "s" option lets "." match newlines, so n-gram patterns count every character.
+func searchTextRegex(pattern string) bson.D {
+	return bson.D{{Key: "search_text", Value: bson.Regex{Pattern: pattern, Options: "s"}}}
+}
+
+// SupportsMatchStrategies reports that entries can be queried under any combination
+// of strategies. Every strategy is a regular expression over the same normalized text,
+// so nothing strategy-specific is stored.
+func (p *Provider) SupportsMatchStrategies(strategies []providers.MatchStrategy) bool {
+	return true
+}
+
+// Delete removes an entry from the index.
+func (p *Provider) Delete(ctx context.Context, key, id string) error {
+	_, err := p.collection.DeleteOne(ctx, bson.D{{Key: "key", Value: key}, {Key: "id", Value: id}})
+	if err != nil {
+		return fmt.Errorf("failed to delete entry: %w", err)
+	}
+	return nil
+}
+
+// DeleteAll removes all entries for a given key.
+func (p *Provider) DeleteAll(ctx context.Context, key string) error {
+	_, err := p.collection.DeleteMany(ctx, bson.D{{Key: "key", Value: key}})
+	if err != nil {
+		return fmt.Errorf("failed to delete entries: %w", err)
+	}
+	return nil
+}
+
+// Close disconnects the MongoDB client.
+func (p *Provider) Close() error {
+	return p.client.Disconnect(context.Background())
+}
@@ -0,0 +1,208 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"go.mongodb.org/mongo-driver/v2/bson"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+const testKey = "test"
+
+// newTestProvider starts a MongoDB container and returns a provider on it,
+// using the regular expression path, and skips the test when Docker is
+// unavailable.
+func newTestProvider(t *testing.T) *Provider {
+	t.Helper()
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	ctx := context.Background()
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "mongo:7",
+			ExposedPorts: []string{"27017/tcp"},
+			WaitingFor:   wait.ForLog("Waiting for connections").WithStartupTimeout(time.Minute),
+		},
+		Started: true,
+	})
+	testcontainers.CleanupContainer(t, container)
+	if err != nil {
+		t.Fatalf("failed to start MongoDB container: %v", err)
+	}
+	host, err := container.Host(ctx)
+	if err != nil {
+		t.Fatalf("failed to get container host: %v", err)
+	}
+	port, err := container.MappedPort(ctx, "27017")
+	if err != nil {
+		t.Fatalf("failed to get container port: %v", err)
+	}
+
+	provider, err := New(Config{URI: fmt.Sprintf("mongodb://%s:%s", host, port.Port())})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = provider.Close() })
+	return provider
+}
+
+// queryIDs returns the IDs of the results of a query, in order.
+func queryIDs(t *testing.T, provider *Provider, key, query string, options providers.QueryOptions) []string {
+	t.Helper()
+	results, err := provider.Query(context.Background(), key, query, options)
+	if err != nil {
+		t.Fatalf("Query(%q) error = %v", query, err)
+	}
+	ids := make([]string, len(results))
+	for i, result := range results {
+		ids[i] = result.ID
+	}
+	return ids
+}
+
+func TestMongoDBProvider_RoundTrip(t *testing.T) {
+	provider := newTestProvider(t)
+	ctx := context.Background()
+
+	for _, entry := range []struct{ id, text string }{{"1", "Mumbai"}, {"2", "Navi Mumbai"}, {"3", "Mumbra"}} {
+		options := providers.IndexOptions{Score: 1, ContentHash: "v1-" + entry.id}
+		if err := provider.Index(ctx, testKey, entry.id, entry.text, entry.text+", MH", options); err != nil {
+			t.Fatalf("Index(%s) error = %v", entry.id, err)
+		}
+	}
+	// The same IDs in another namespace must not show up in testKey
+	if err := provider.Index(ctx, "other", "1", "Mumbai Other", "Mumbai Other", providers.IndexOptions{Score: 1}); err != nil {
+		t.Fatalf("Index() in another namespace error = %v", err)
+	}
+	if err := provider.Index(ctx, "other", "4", "Mumbai Extra", "Mumbai Extra", providers.IndexOptions{Score: 9}); err != nil {
+		t.Fatalf("Index() in another namespace error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		strategy providers.MatchStrategy
+		limit    int
+		want     []string
+	}{
+		{"prefix", "MUM", providers.MatchPrefix, 10, []string{"1", "3"}},
+		{"substring", "umba", providers.MatchSubstring, 10, []string{"1", "2"}},
+		{"short ngram", "mb", providers.MatchNGram, 10, []string{"1", "3", "2"}},
+		{"long ngram", "umba", providers.MatchNGram, 10, []string{"1", "2"}},
+		{"n-or-more", "mumb", providers.MatchNOrMoreGram, 10, []string{"1", "3", "2"}},
+		{"n-or-more too short", "mu", providers.MatchNOrMoreGram, 10, []string{}},
+		{"limit", "mumb", providers.MatchNOrMoreGram, 1, []string{"1"}},
+		{"metacharacters are literal", "m.m", providers.MatchSubstring, 10, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := providers.QueryOptions{MatchStrategy: tt.strategy, NGramSize: 3, MaxResults: tt.limit}
+			if got := queryIDs(t, provider, testKey, tt.query, options); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Query(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+
+	// Reindexing an ID replaces the document rather than adding one
+	if err := provider.Index(ctx, testKey, "1", "Pune", "Pune, MH", providers.IndexOptions{Score: 1, ContentHash: "v2-1"}); err != nil {
+		t.Fatalf("Index() overwrite error = %v", err)
+	}
+	prefix := providers.QueryOptions{MatchStrategy: providers.MatchPrefix, MaxResults: 10}
+	if got := queryIDs(t, provider, testKey, "mum", prefix); !reflect.DeepEqual(got, []string{"3"}) {
+		t.Errorf("Query(mum) after overwrite = %v, want [3]", got)
+	}
+	results, err := provider.Query(ctx, testKey, "pune", prefix)
+	if err != nil || len(results) != 1 || results[0].Display != "Pune, MH" || results[0].Score != 1 {
+		t.Errorf("Query(pune) after overwrite = %v, %v; want the new entry", results, err)
+	}
+	if hash, ok, err := provider.ContentHash(ctx, testKey, "1"); err != nil || !ok || hash != "v2-1" {
+		t.Errorf("ContentHash(1) = %q, %v, %v; want v2-1", hash, ok, err)
+	}
+
+	// Delete and DeleteAll touch only their namespace
+	if err := provider.Delete(ctx, testKey, "3"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if got := queryIDs(t, provider, testKey, "mum", prefix); len(got) != 0 {
+		t.Errorf("Query(mum) after Delete = %v, want none", got)
+	}
+	if err := provider.DeleteAll(ctx, testKey); err != nil {
+		t.Fatalf("DeleteAll() error = %v", err)
+	}
+	substring := providers.QueryOptions{MatchStrategy: providers.MatchSubstring, MaxResults: 10}
+	if got := queryIDs(t, provider, testKey, "u", substring); len(got) != 0 {
+		t.Errorf("Query() after DeleteAll = %v, want none", got)
+	}
+	if _, ok, err := provider.ContentHash(ctx, testKey, "1"); err != nil || ok {
+		t.Errorf("ContentHash(1) after DeleteAll = %v, %v; want not found", ok, err)
+	}
+	if got := queryIDs(t, provider, "other", "mumbai", prefix); !reflect.DeepEqual(got, []string{"4", "1"}) {
+		t.Errorf("Query() in the other namespace after DeleteAll = %v, want [4 1]", got)
+	}
+}
+
+func TestMatchFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		strategy providers.MatchStrategy
+		filter   bson.D
+		ok       bool
+	}{
+		{"prefix", "mum", providers.MatchPrefix, searchTextRegex("^mum"), true},
+		{"substring", "mum", providers.MatchSubstring, searchTextRegex("mum"), true},
+		{"escaped metacharacters", "a.b(", providers.MatchSubstring, searchTextRegex(`a\.b\(`), true},
+		{"short ngram", "mu", providers.MatchNGram, searchTextRegex("mu.{1}"), true},
+		{
+			"long ngram", "umba", providers.MatchNGram,
+			bson.D{{Key: "$and", Value: bson.A{searchTextRegex("umb"), searchTextRegex("mba")}}}, true,
+		},
+		{"n-or-more", "mumb", providers.MatchNOrMoreGram, searchTextRegex("mumb"), true},
+		{"n-or-more too short", "mu", providers.MatchNOrMoreGram, nil, false},
+		{"empty", "", providers.MatchPrefix, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := providers.QueryOptions{MatchStrategy: tt.strategy, NGramSize: 3}
+			filter, ok := matchFilter(tt.query, options)
+			if !reflect.DeepEqual(filter, tt.filter) || ok != tt.ok {
+				t.Errorf("matchFilter(%q) = %v, %v; want %v, %v", tt.query, filter, ok, tt.filter, tt.ok)
+			}
+		})
+	}
+}
+
+func TestPipelineAtlasSearch(t *testing.T) {
+	p := &Provider{atlasSearch: "autocomplete"}
+	filter := searchTextRegex("^m")
+
+	tests := []struct {
+		query  string
+		search bool
+	}{
+		{"m", false},
+		{"mu", true},
+		{"mumbai suburban", true},
+		{"mumbai suburban district", false},
+	}
+
+	for _, tt := range tests {
+		stages := p.pipeline("cities", tt.query, filter, 10)
+		if got := stages[0][0].Key == "$search"; got != tt.search {
+			t.Errorf("pipeline(%q) uses $search = %v, want %v", tt.query, got, tt.search)
+		}
+	}
+
+	stages := (&Provider{}).pipeline("cities", "mu", filter, 10)
+	if stages[0][0].Key != "$match" {
+		t.Errorf("pipeline without Atlas Search should start with $match, got %s", stages[0][0].Key)
+	}
+}
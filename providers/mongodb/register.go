@@ -0,0 +1,29 @@
+package mongodb
+
+import (
+	"fmt"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// init registers the MongoDB provider. Import this package with a blank identifier
+// to use MongoDB as the autocomplete backend:
+//
+//	import _ "github.com/remiges-tech/autocomplete/providers/mongodb"
+//
+//nolint:gochecknoinits // init() is the idiomatic pattern for provider registration
+func init() {
+	autocomplete.RegisterProvider("mongodb", NewProvider)
+}
+
+// NewProvider creates a new MongoDB provider from the given configuration.
+// It implements ProviderFactory and expects config to be of type mongodb.Config.
+func NewProvider(config interface{}) (providers.Provider, error) {
+	mongoConfig, ok := config.(Config)
+	if !ok {
+		return nil, fmt.Errorf("invalid configuration type for MongoDB provider: expected mongodb.Config, got %T", config)
+	}
+
+	return New(mongoConfig)
+}
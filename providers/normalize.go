@@ -0,0 +1,15 @@
+package providers
+
+import "strings"
+
+// SearchText returns the form of text that providers store tokens for and
+// match against: text as given when caseSensitive is set, lowercased
+// otherwise. Providers apply it to indexed text and to queries alike, so a
+// query always meets indexed text normalized the same way; normalizing the two
+// paths differently silently loses matches.
+func SearchText(text string, caseSensitive bool) string {
+	if caseSensitive {
+		return text
+	}
+	return strings.ToLower(text)
+}
@@ -0,0 +1,43 @@
+package providers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSearchText(t *testing.T) {
+	tests := []struct {
+		text          string
+		caseSensitive bool
+		want          string
+	}{
+		{"Navi Mumbai", false, "navi mumbai"},
+		{"Navi Mumbai", true, "Navi Mumbai"},
+		{"ÉCOLE Élémentaire", false, "école élémentaire"},
+		{"МОСКВА", false, "москва"},
+		{"मुंबई", false, "मुंबई"},
+	}
+	for _, tt := range tests {
+		if got := SearchText(tt.text, tt.caseSensitive); got != tt.want {
+			t.Errorf("SearchText(%q, %t) = %q, want %q", tt.text, tt.caseSensitive, got, tt.want)
+		}
+	}
+}
+
+// TestSearchTextParity checks that text normalizes the same however it is
+// cased, and that normalizing stored text again leaves it unchanged, since
+// providers normalize both indexed text and queries, and sometimes text they
+// stored already normalized.
+func TestSearchTextParity(t *testing.T) {
+	for _, text := range []string{"Navi Mumbai", "ÉCOLE Élémentaire", "Москва", "Ærøskøbing", "मुंबई"} {
+		want := SearchText(text, false)
+		for _, variant := range []string{strings.ToUpper(text), strings.ToLower(text), strings.ToTitle(text)} {
+			if got := SearchText(variant, false); got != want {
+				t.Errorf("SearchText(%q) = %q, want %q as for %q", variant, got, want, text)
+			}
+		}
+		if got := SearchText(want, false); got != want {
+			t.Errorf("SearchText is not idempotent on %q: got %q", want, got)
+		}
+	}
+}
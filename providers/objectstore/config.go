@@ -0,0 +1,37 @@
+// Package objectstore implements a read-only autocomplete Provider serving a
+// prebuilt index artifact downloaded from object storage, such as Amazon S3 or
+// Google Cloud Storage. It suits static datasets such as postal codes or bank
+// branch codes: the artifact is built once, e.g. with the filestore provider,
+// and every instance loads it into memory at startup and answers all queries
+// from memory, reloading it when it changes.
+package objectstore
+
+import "time"
+
+// defaultRefreshInterval is the interval used when Config.RefreshInterval is zero.
+const defaultRefreshInterval = 5 * time.Minute
+
+// Config holds the artifact source and refresh options of an objectstore provider.
+type Config struct {
+	// Source fetches the artifact, a snapshot in the format written by the
+	// filestore provider (its filestore.SnapshotFile). Use NewS3Source for S3
+	// and S3-compatible stores, and HTTPSource for Google Cloud Storage and
+	// other HTTP servers.
+	Source Source
+
+	// RefreshInterval is how often the source is checked for a changed
+	// artifact, which then replaces the served index. Negative disables refreshing.
+	// Default: 5 minutes
+	RefreshInterval time.Duration
+
+	// OnRefreshError, if set, is called when fetching or loading a changed
+	// artifact fails. The previous index keeps being served.
+	OnRefreshError func(err error)
+}
+
+// setDefaults applies default values to config fields.
+func (c *Config) setDefaults() {
+	if c.RefreshInterval == 0 {
+		c.RefreshInterval = defaultRefreshInterval
+	}
+}
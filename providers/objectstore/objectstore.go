@@ -0,0 +1,172 @@
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/remiges-tech/autocomplete/providers"
+	"github.com/remiges-tech/autocomplete/providers/filestore"
+	"github.com/remiges-tech/autocomplete/providers/memory"
+)
+
+// ErrReadOnly is returned by writes: the index is only changed by publishing
+// a new artifact.
+var ErrReadOnly = errors.New("objectstore provider is read-only")
+
+// Provider implements the autocomplete Provider interface by serving the
+// artifact of a Source from memory. A refresh loads a changed artifact into a
+// new in-memory index and then swaps it in, so queries never see a partly
+// loaded artifact.
+// All methods are safe for concurrent use.
+type Provider struct {
+	source         Source
+	onRefreshError func(err error)
+
+	// index is the in-memory index of the loaded artifact.
+	index atomic.Pointer[memory.Provider]
+
+	// version is the version of the loaded artifact; only the refreshing
+	// goroutine accesses it after New.
+	version string
+
+	// ctx is cancelled by Close, stopping the refresh loop.
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New creates a new objectstore provider, loading the artifact before it
+// returns, and starts the refresh loop.
+func New(config Config) (*Provider, error) {
+	if config.Source == nil {
+		return nil, errors.New("artifact source is required")
+	}
+	config.setDefaults()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Provider{
+		source:         config.Source,
+		onRefreshError: config.OnRefreshError,
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+	if err := p.refresh(ctx); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	if config.RefreshInterval > 0 {
+		p.wg.Add(1)
+		go p.refreshLoop(config.RefreshInterval)
+	}
+	return p, nil
+}
+
+// Index fails with ErrReadOnly.
+func (p *Provider) Index(ctx context.Context, key, id, text, display string, options providers.IndexOptions) error {
+	return ErrReadOnly
+}
+
+// Query searches the loaded artifact.
+func (p *Provider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	return p.index.Load().Query(ctx, key, query, options)
+}
+
+// Delete fails with ErrReadOnly.
+func (p *Provider) Delete(ctx context.Context, key, id string) error {
+	return ErrReadOnly
+}
+
+// DeleteAll fails with ErrReadOnly.
+func (p *Provider) DeleteAll(ctx context.Context, key string) error {
+	return ErrReadOnly
+}
+
+// ContentHash returns the content hash an entry of the artifact was indexed
+// with and whether it exists.
+func (p *Provider) ContentHash(ctx context.Context, key, id string) (string, bool, error) {
+	return p.index.Load().ContentHash(ctx, key, id)
+}
+
+// CountEntries returns the number of entries of the namespace in the loaded artifact.
+func (p *Provider) CountEntries(ctx context.Context, key string) (int64, error) {
+	return p.index.Load().CountEntries(ctx, key)
+}
+
+// IndexStats returns the size of the namespace in the loaded artifact.
+func (p *Provider) IndexStats(ctx context.Context, key string) (providers.IndexStats, error) {
+	return p.index.Load().IndexStats(ctx, key)
+}
+
+// GetEntry returns an entry of the loaded artifact and whether it exists.
+func (p *Provider) GetEntry(ctx context.Context, key, id string) (providers.StoredEntry, bool, error) {
+	return p.index.Load().GetEntry(ctx, key, id)
+}
+
+// ListEntries returns up to count entries of the loaded artifact in ID order,
+// starting after the ID given as cursor.
+func (p *Provider) ListEntries(ctx context.Context, key, cursor string, count int) ([]providers.StoredEntry, string, error) {
+	return p.index.Load().ListEntries(ctx, key, cursor, count)
+}
+
+// SupportsMatchStrategies reports that artifacts can hold entries indexed
+// under any combination of strategies.
+func (p *Provider) SupportsMatchStrategies(strategies []providers.MatchStrategy) bool {
+	return true
+}
+
+// Close stops the refresh loop. The loaded artifact is still served afterwards.
+func (p *Provider) Close() error {
+	p.cancel()
+	p.wg.Wait()
+	return nil
+}
+
+// refreshLoop reloads the artifact when it changes until the provider is closed.
+func (p *Provider) refreshLoop(interval time.Duration) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := p.refresh(p.ctx); err != nil && p.ctx.Err() == nil && p.onRefreshError != nil {
+			p.onRefreshError(err)
+		}
+	}
+}
+
+// refresh loads the artifact if it changed since the last load.
+func (p *Provider) refresh(ctx context.Context) error {
+	artifact, version, err := p.source.Fetch(ctx, p.version)
+	if errors.Is(err, ErrNotModified) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer func() { _ = artifact.Close() }()
+
+	index, err := memory.New(memory.Config{})
+	if err != nil {
+		return err
+	}
+	err = filestore.ReadSnapshot(artifact, func(key string, entry providers.IndexEntry) error {
+		return index.Index(ctx, key, entry.ID, entry.Text, entry.Display, entry.Options)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load artifact: %w", err)
+	}
+
+	p.index.Store(index)
+	p.version = version
+	return nil
+}
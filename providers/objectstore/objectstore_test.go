@@ -0,0 +1,208 @@
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/remiges-tech/autocomplete/providers"
+	"github.com/remiges-tech/autocomplete/providers/filestore"
+)
+
+const testKey = "test"
+
+// buildArtifact indexes entries with the filestore provider and returns its snapshot.
+func buildArtifact(t *testing.T, entries map[string]string) []byte {
+	t.Helper()
+	dir := t.TempDir()
+	builder, err := filestore.New(filestore.Config{Dir: dir})
+	if err != nil {
+		t.Fatalf("filestore.New() error = %v", err)
+	}
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchPrefix}
+	for id, text := range entries {
+		if err := builder.Index(context.Background(), testKey, id, text, text, options); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+	if err := builder.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	artifact, err := os.ReadFile(filepath.Join(dir, filestore.SnapshotFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return artifact
+}
+
+// artifactServer serves an artifact over HTTP with its version as ETag.
+type artifactServer struct {
+	mu       sync.Mutex
+	artifact []byte
+	version  int
+	fail     bool
+	fetches  atomic.Int64
+}
+
+func (s *artifactServer) publish(artifact []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.artifact = artifact
+	s.version++
+}
+
+func (s *artifactServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fetches.Add(1)
+	if s.fail {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	etag := fmt.Sprintf(`"v%d"`, s.version)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	_, _ = w.Write(s.artifact)
+}
+
+func queryIDs(t *testing.T, provider *Provider, query string) string {
+	t.Helper()
+	results, err := provider.Query(context.Background(), testKey, query, providers.QueryOptions{MaxResults: 10, MatchStrategy: providers.MatchPrefix})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+	return fmt.Sprint(ids)
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestObjectstoreProvider_ServesAndRefreshesArtifact(t *testing.T) {
+	server := &artifactServer{}
+	server.publish(buildArtifact(t, map[string]string{"400001": "400001 mumbai gpo", "411001": "411001 pune"}))
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	var mu sync.Mutex
+	var refreshErrs []error
+	provider, err := New(Config{
+		Source:          HTTPSource{URL: httpServer.URL},
+		RefreshInterval: 5 * time.Millisecond,
+		OnRefreshError: func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			refreshErrs = append(refreshErrs, err)
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer func() { _ = provider.Close() }()
+
+	if got := queryIDs(t, provider, "4"); got != "[411001 400001]" {
+		t.Errorf("Query() = %v, want both entries of the artifact", got)
+	}
+	if err := provider.Index(context.Background(), testKey, "1", "x", "x", providers.IndexOptions{}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Index() error = %v, want %v", err, ErrReadOnly)
+	}
+
+	// Checks that find the artifact unchanged keep the loaded index
+	fetches := server.fetches.Load()
+	waitFor(t, func() bool { return server.fetches.Load() > fetches+2 })
+	if got := queryIDs(t, provider, "4"); got != "[411001 400001]" {
+		t.Errorf("Query() while unchanged = %v, want both entries", got)
+	}
+
+	server.publish(buildArtifact(t, map[string]string{"560001": "560001 bengaluru"}))
+	waitFor(t, func() bool { return queryIDs(t, provider, "5") == "[560001]" })
+	if got := queryIDs(t, provider, "4"); got != "[]" {
+		t.Errorf("Query() after refresh = %v, want entries of the old artifact gone", got)
+	}
+
+	// A failing refresh keeps the loaded artifact
+	server.mu.Lock()
+	server.fail = true
+	server.mu.Unlock()
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(refreshErrs) > 0
+	})
+	if got := queryIDs(t, provider, "5"); got != "[560001]" {
+		t.Errorf("Query() after a failed refresh = %v, want [560001]", got)
+	}
+}
+
+func TestNewFailsWithoutArtifact(t *testing.T) {
+	httpServer := httptest.NewServer(http.NotFoundHandler())
+	defer httpServer.Close()
+
+	if _, err := New(Config{Source: HTTPSource{URL: httpServer.URL}}); err == nil {
+		t.Error("New() should fail when the artifact cannot be fetched")
+	}
+	if _, err := New(Config{}); err == nil {
+		t.Error("New() should require a source")
+	}
+}
+
+func TestS3Source(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_REGION", "ap-south-1")
+
+	artifact := []byte("artifact")
+	var paths []string
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write(artifact)
+	}))
+	defer httpServer.Close()
+
+	source, err := NewS3Source(S3Config{Bucket: "datasets", Key: "pincodes.jsonl.gz", Endpoint: httpServer.URL, UsePathStyle: true})
+	if err != nil {
+		t.Fatalf("NewS3Source() error = %v", err)
+	}
+	ctx := context.Background()
+	body, version, err := source.Fetch(ctx, "")
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	got, _ := io.ReadAll(body)
+	_ = body.Close()
+	if !bytes.Equal(got, artifact) || version != `"v1"` || paths[0] != "/datasets/pincodes.jsonl.gz" {
+		t.Errorf("Fetch() = %q, %q from %v; want the object with its ETag", got, version, paths)
+	}
+	if _, _, err := source.Fetch(ctx, version); !errors.Is(err, ErrNotModified) {
+		t.Errorf("Fetch() of an unchanged object error = %v, want %v", err, ErrNotModified)
+	}
+}
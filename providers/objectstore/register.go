@@ -0,0 +1,29 @@
+package objectstore
+
+import (
+	"fmt"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// init registers the objectstore provider. Import this package with a blank identifier
+// to serve a prebuilt index from object storage:
+//
+//	import _ "github.com/remiges-tech/autocomplete/providers/objectstore"
+//
+//nolint:gochecknoinits // init() is the idiomatic pattern for provider registration
+func init() {
+	autocomplete.RegisterProvider("objectstore", NewProvider)
+}
+
+// NewProvider creates a new objectstore provider from the given configuration.
+// It implements ProviderFactory and expects config to be of type objectstore.Config.
+func NewProvider(config interface{}) (providers.Provider, error) {
+	objectstoreConfig, ok := config.(Config)
+	if !ok {
+		return nil, fmt.Errorf("invalid configuration type for objectstore provider: expected objectstore.Config, got %T", config)
+	}
+
+	return New(objectstoreConfig)
+}
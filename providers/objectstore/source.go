@@ -0,0 +1,152 @@
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// ErrNotModified is returned by Source.Fetch when the artifact is unchanged.
+var ErrNotModified = errors.New("artifact not modified")
+
+// Source fetches the index artifact.
+type Source interface {
+	// Fetch returns the artifact and its version, such as its ETag. If version
+	// is not empty and the artifact still has that version, Fetch returns
+	// ErrNotModified instead. The caller closes the returned reader.
+	Fetch(ctx context.Context, version string) (artifact io.ReadCloser, newVersion string, err error)
+}
+
+// HTTPSource fetches the artifact with HTTP GET requests, using ETag to skip
+// unchanged artifacts. It serves objects of Google Cloud Storage, at
+// https://storage.googleapis.com/BUCKET/OBJECT, presigned S3 URLs, and any
+// other HTTP server.
+type HTTPSource struct {
+	// URL is the address of the artifact.
+	URL string
+
+	// Client sends the requests.
+	// Default: http.DefaultClient
+	Client *http.Client
+
+	// Authorize, if set, is called with every request before it is sent, to add
+	// credentials such as an OAuth access token for a private GCS object.
+	Authorize func(req *http.Request) error
+}
+
+// Fetch downloads the artifact unless its ETag equals version.
+func (s HTTPSource) Fetch(ctx context.Context, version string) (io.ReadCloser, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create request: %w", err)
+	}
+	if version != "" {
+		req.Header.Set("If-None-Match", version)
+	}
+	if s.Authorize != nil {
+		if err := s.Authorize(req); err != nil {
+			return nil, "", fmt.Errorf("failed to authorize request: %w", err)
+		}
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch artifact: %w", err)
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, resp.Header.Get("ETag"), nil
+	case http.StatusNotModified:
+		_ = resp.Body.Close()
+		return nil, "", ErrNotModified
+	default:
+		_ = resp.Body.Close()
+		return nil, "", fmt.Errorf("failed to fetch artifact: unexpected status %s", resp.Status)
+	}
+}
+
+// S3Config identifies an artifact stored in Amazon S3 or an S3-compatible store.
+// Credentials are resolved by the AWS SDK's default chain (environment,
+// shared config files, or the role of the instance or task).
+type S3Config struct {
+	// Bucket is the bucket holding the artifact.
+	Bucket string
+
+	// Key is the object key of the artifact.
+	Key string
+
+	// Region is the AWS region of the bucket.
+	// Empty (default) uses the region from the SDK's default configuration.
+	Region string
+
+	// Endpoint overrides the S3 endpoint, e.g. "http://localhost:9000" for MinIO.
+	Endpoint string
+
+	// UsePathStyle addresses the bucket in the URL path rather than the host
+	// name, as most S3-compatible stores require.
+	UsePathStyle bool
+}
+
+// S3Source fetches the artifact from S3, using the object's ETag to skip
+// unchanged artifacts.
+type S3Source struct {
+	client *s3.Client
+	bucket string
+	key    string
+}
+
+// NewS3Source creates a source for the object in config. It loads the AWS
+// configuration but does not contact S3.
+func NewS3Source(config S3Config) (*S3Source, error) {
+	if config.Bucket == "" || config.Key == "" {
+		return nil, errors.New("S3 bucket and key are required")
+	}
+
+	var loadOptions []func(*awsconfig.LoadOptions) error
+	if config.Region != "" {
+		loadOptions = append(loadOptions, awsconfig.WithRegion(config.Region))
+	}
+	awsConfig, err := awsconfig.LoadDefaultConfig(context.Background(), loadOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+
+	client := s3.NewFromConfig(awsConfig, func(o *s3.Options) {
+		if config.Endpoint != "" {
+			o.BaseEndpoint = aws.String(config.Endpoint)
+		}
+		o.UsePathStyle = config.UsePathStyle
+		// Artifacts are gzip-compressed, which verifies them when loading.
+		o.DisableLogOutputChecksumValidationSkipped = true
+	})
+	return &S3Source{client: client, bucket: config.Bucket, key: config.Key}, nil
+}
+
+// Fetch downloads the object unless its ETag equals version.
+func (s *S3Source) Fetch(ctx context.Context, version string) (io.ReadCloser, string, error) {
+	input := &s3.GetObjectInput{Bucket: aws.String(s.bucket), Key: aws.String(s.key)}
+	if version != "" {
+		input.IfNoneMatch = aws.String(version)
+	}
+
+	output, err := s.client.GetObject(ctx, input)
+	var responseErr *awshttp.ResponseError
+	if errors.As(err, &responseErr) && responseErr.HTTPStatusCode() == http.StatusNotModified {
+		return nil, "", ErrNotModified
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch artifact: %w", err)
+	}
+	return output.Body, aws.ToString(output.ETag), nil
+}
@@ -0,0 +1,155 @@
+package opensearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/opensearch-project/opensearch-go/v4/opensearchapi"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// bulkBody accumulates newline-delimited bulk actions.
+type bulkBody struct {
+	buf bytes.Buffer
+}
+
+// index appends an index action for the given document.
+func (b *bulkBody) index(docID string, doc *document) error {
+	return b.write(map[string]interface{}{"index": map[string]string{"_id": docID}}, doc)
+}
+
+// delete appends a delete action for the given document ID.
+func (b *bulkBody) delete(docID string) error {
+	return b.write(map[string]interface{}{"delete": map[string]string{"_id": docID}})
+}
+
+func (b *bulkBody) write(lines ...interface{}) error {
+	encoder := json.NewEncoder(&b.buf)
+	for _, line := range lines {
+		if err := encoder.Encode(line); err != nil {
+			return fmt.Errorf("failed to encode bulk action: %w", err)
+		}
+	}
+	return nil
+}
+
+// IndexAtomic writes all entries in a single bulk request. OpenSearch has no
+// multi-document transactions, so if any entry fails the entries that were written
+// are restored to their previous state (or deleted if they were new).
+func (p *Provider) IndexAtomic(ctx context.Context, key string, entries []providers.IndexEntry) error {
+	docIDs := make([]string, len(entries))
+	var body bulkBody
+	for i, entry := range entries {
+		docIDs[i] = generateDocumentID(key, entry.ID)
+		doc := newDocument(key, entry.ID, entry.Text, entry.Display, entry.Options)
+		if err := body.index(docIDs[i], &doc); err != nil {
+			return err
+		}
+	}
+
+	previous, err := p.fetchDocuments(ctx, docIDs)
+	if err != nil {
+		return err
+	}
+
+	written, failures, err := p.executeBulk(ctx, &body)
+	if err != nil {
+		return err
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+
+	if err := p.rollback(ctx, written, previous); err != nil {
+		return fmt.Errorf("atomic index failed (%s) and rollback failed: %w", strings.Join(failures, "; "), err)
+	}
+	return fmt.Errorf("atomic index failed and was rolled back: %s", strings.Join(failures, "; "))
+}
+
+// fetchDocuments returns the currently stored documents for the given IDs.
+// Documents that do not exist are absent from the returned map.
+func (p *Provider) fetchDocuments(ctx context.Context, docIDs []string) (map[string]document, error) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(map[string]interface{}{"ids": docIDs}); err != nil {
+		return nil, fmt.Errorf("failed to encode multi-get request: %w", err)
+	}
+
+	res, err := p.client.MGet(ctx, opensearchapi.MGetReq{
+		Index: p.index,
+		Body:  &buf,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch documents: %w", err)
+	}
+
+	docs := make(map[string]document, len(res.Docs))
+	for _, found := range res.Docs {
+		if !found.Found {
+			continue
+		}
+		var doc document
+		if err := json.Unmarshal(found.Source, &doc); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		docs[found.ID] = doc
+	}
+	return docs, nil
+}
+
+// executeBulk sends a bulk request and returns the IDs of successful actions
+// and a description of each failed action.
+func (p *Provider) executeBulk(ctx context.Context, body *bulkBody) (succeeded, failures []string, err error) {
+	res, err := p.client.Bulk(ctx, opensearchapi.BulkReq{
+		Index:  p.index,
+		Body:   &body.buf,
+		Params: opensearchapi.BulkParams{Refresh: p.refreshPolicy},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to execute bulk request: %w", err)
+	}
+
+	for _, item := range res.Items {
+		for _, result := range item {
+			if result.Error != nil {
+				failures = append(failures, fmt.Sprintf("%s: %s", result.ID, result.Error.Reason))
+				continue
+			}
+			succeeded = append(succeeded, result.ID)
+		}
+	}
+	return succeeded, failures, nil
+}
+
+// rollback restores written documents to their previous state.
+func (p *Provider) rollback(ctx context.Context, written []string, previous map[string]document) error {
+	if len(written) == 0 {
+		return nil
+	}
+
+	var body bulkBody
+	for _, docID := range written {
+		var err error
+		if doc, existed := previous[docID]; existed {
+			err = body.index(docID, &doc)
+		} else {
+			err = body.delete(docID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	_, failures, err := p.executeBulk(ctx, &body)
+	if err != nil {
+		return err
+	}
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+// Package opensearch implements the autocomplete Provider interface using OpenSearch,
+// including Amazon OpenSearch Service domains.
+package opensearch
+
+import "github.com/opensearch-project/opensearch-go/v4/signer"
+
+// Config holds OpenSearch connection parameters and provider-specific options.
+type Config struct {
+	// URLs is the list of OpenSearch node URLs.
+	URLs []string
+
+	// Index is the name of the OpenSearch index to use for autocomplete data.
+	Index string
+
+	// Username for basic authentication.
+	Username string
+
+	// Password for basic authentication.
+	Password string
+
+	// Signer signs every request, e.g. with AWS Signature Version 4 for Amazon
+	// OpenSearch Service (see the signer/awsv2 package of opensearch-go).
+	Signer signer.Signer
+
+	// RefreshPolicy controls when changes are visible to search.
+	// Options: "true" (immediate), "false" (default), "wait_for" (wait for next refresh).
+	RefreshPolicy string
+
+	// NumberOfShards configures the number of primary shards for the index.
+	// This setting is ONLY used when the index is automatically created by the provider.
+	// If the index already exists, this setting is ignored.
+	// Default: 1
+	NumberOfShards int
+
+	// NumberOfReplicas configures the number of replica shards.
+	// This setting is ONLY used when the index is automatically created by the provider.
+	// If the index already exists, this setting is ignored.
+	// Default: 0
+	NumberOfReplicas int
+}
+
+// setDefaults applies default values to config fields.
+func (c *Config) setDefaults() {
+	if c.RefreshPolicy == "" {
+		c.RefreshPolicy = "false"
+	}
+	if c.NumberOfShards == 0 {
+		c.NumberOfShards = 1
+	}
+}
@@ -0,0 +1,397 @@
+package opensearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	opensearchgo "github.com/opensearch-project/opensearch-go/v4"
+	"github.com/opensearch-project/opensearch-go/v4/opensearchapi"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+const (
+	// defaultMaxResults is the default maximum number of results if not specified.
+	defaultMaxResults = 10
+
+	// indexMappingTemplate is the OpenSearch index mapping for autocomplete.
+	indexMappingTemplate = `{
+		"settings": {
+			"number_of_shards": %d,
+			"number_of_replicas": %d,
+			"index.max_ngram_diff": 20,
+			"analysis": {
+				"analyzer": {
+					"prefix_analyzer": {
+						"tokenizer": "standard",
+						"filter": ["lowercase", "edge_ngram_filter"]
+					},
+					"ngram_analyzer": {
+						"tokenizer": "ngram_tokenizer",
+						"filter": ["lowercase"]
+					},
+					"substring_analyzer": {
+						"tokenizer": "standard",
+						"filter": ["lowercase", "substring_filter"]
+					}
+				},
+				"tokenizer": {
+					"ngram_tokenizer": {
+						"type": "ngram",
+						"min_gram": 3,
+						"max_gram": 20
+					}
+				},
+				"filter": {
+					"edge_ngram_filter": {
+						"type": "edge_ngram",
+						"min_gram": 1,
+						"max_gram": 20
+					},
+					"substring_filter": {
+						"type": "ngram",
+						"min_gram": 3,
+						"max_gram": 20
+					}
+				}
+			}
+		},
+		"mappings": {
+			"properties": {
+				"id": {"type": "keyword"},
+				"key": {"type": "keyword"},
+				"text": {
+					"type": "text",
+					"fields": {
+						"prefix": {
+							"type": "text",
+							"analyzer": "prefix_analyzer",
+							"search_analyzer": "standard"
+						},
+						"ngram": {
+							"type": "text",
+							"analyzer": "ngram_analyzer"
+						},
+						"substring": {
+							"type": "text",
+							"analyzer": "substring_analyzer"
+						},
+						"keyword": {
+							"type": "keyword"
+						}
+					}
+				},
+				"display": {"type": "text"},
+				"score": {"type": "float"},
+				"case_sensitive": {"type": "boolean"},
+				"content_hash": {"type": "keyword", "index": false}
+			}
+		}
+	}`
+)
+
+// Provider implements the autocomplete Provider interface using OpenSearch.
+// It uses the same index mapping and queries as the Elasticsearch provider, through
+// the OpenSearch client, which accepts OpenSearch clusters and can sign requests
+// for Amazon OpenSearch Service.
+type Provider struct {
+	client        *opensearchapi.Client
+	index         string
+	refreshPolicy string
+}
+
+// document represents the structure stored in OpenSearch.
+type document struct {
+	ID            string  `json:"id"`
+	Key           string  `json:"key"`
+	Text          string  `json:"text"`
+	Display       string  `json:"display"`
+	Score         float64 `json:"score"`
+	CaseSensitive bool    `json:"case_sensitive"`
+	ContentHash   string  `json:"content_hash,omitempty"`
+}
+
+// New creates a new OpenSearch provider with the given configuration.
+func New(config Config) (*Provider, error) {
+	config.setDefaults()
+
+	client, err := opensearchapi.NewClient(opensearchapi.Config{
+		Client: opensearchgo.Config{
+			Addresses: config.URLs,
+			Username:  config.Username,
+			Password:  config.Password,
+			Signer:    config.Signer,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OpenSearch client: %w", err)
+	}
+
+	// Test connection
+	if _, err := client.Info(context.Background(), nil); err != nil {
+		return nil, fmt.Errorf("failed to connect to OpenSearch: %w", err)
+	}
+
+	provider := &Provider{
+		client:        client,
+		index:         config.Index,
+		refreshPolicy: config.RefreshPolicy,
+	}
+
+	// Create index if it doesn't exist
+	if err := provider.createIndexIfNotExists(config); err != nil {
+		return nil, fmt.Errorf("failed to create index: %w", err)
+	}
+
+	return provider, nil
+}
+
+// createIndexIfNotExists creates the index with appropriate mappings if it doesn't exist.
+func (p *Provider) createIndexIfNotExists(config Config) error {
+	res, err := p.client.Indices.Exists(context.Background(), opensearchapi.IndicesExistsReq{
+		Indices: []string{p.index},
+	})
+	if err == nil {
+		return nil
+	}
+	if statusCode(res) != http.StatusNotFound {
+		return err
+	}
+
+	mapping := fmt.Sprintf(indexMappingTemplate, config.NumberOfShards, config.NumberOfReplicas)
+	_, err = p.client.Indices.Create(context.Background(), opensearchapi.IndicesCreateReq{
+		Index: p.index,
+		Body:  strings.NewReader(mapping),
+	})
+	return err
+}
+
+// Index adds or updates an entry in the OpenSearch autocomplete index.
+func (p *Provider) Index(ctx context.Context, key, id, text, display string, options providers.IndexOptions) error {
+	docJSON, err := json.Marshal(newDocument(key, id, text, display, options))
+	if err != nil {
+		return fmt.Errorf("failed to marshal document: %w", err)
+	}
+
+	_, err = p.client.Index(ctx, opensearchapi.IndexReq{
+		Index:      p.index,
+		DocumentID: url.PathEscape(generateDocumentID(key, id)),
+		Body:       bytes.NewReader(docJSON),
+		Params:     opensearchapi.IndexParams{Refresh: p.refreshPolicy},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to index document: %w", err)
+	}
+
+	return nil
+}
+
+// ContentHash returns the stored content hash for an entry and whether it exists.
+func (p *Provider) ContentHash(ctx context.Context, key, id string) (string, bool, error) {
+	res, err := p.client.Document.Get(ctx, opensearchapi.DocumentGetReq{
+		Index:      p.index,
+		DocumentID: url.PathEscape(generateDocumentID(key, id)),
+		Params:     opensearchapi.DocumentGetParams{SourceIncludes: []string{"content_hash"}},
+	})
+	if res != nil && statusCode(res.Inspect().Response) == http.StatusNotFound {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get document: %w", err)
+	}
+
+	var doc document
+	if err := json.Unmarshal(res.Source, &doc); err != nil {
+		return "", false, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return doc.ContentHash, res.Found, nil
+}
+
+// Query searches for entries matching the given query.
+func (p *Provider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(buildQuery(key, query, options)); err != nil {
+		return nil, fmt.Errorf("failed to encode query: %w", err)
+	}
+
+	size := options.MaxResults
+	if size <= 0 {
+		size = defaultMaxResults
+	}
+
+	req := &opensearchapi.SearchReq{
+		Indices: []string{p.index},
+		Body:    &buf,
+		Params:  opensearchapi.SearchParams{Size: &size},
+	}
+	if options.Offset > 0 {
+		req.Params.From = &options.Offset
+	}
+	if options.SnapshotID != "" {
+		// A point in time already names its index.
+		req.Indices = nil
+	}
+
+	res, err := p.client.Search(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute search: %w", err)
+	}
+
+	results := make([]providers.ProviderResult, 0, len(res.Hits.Hits))
+	for _, hit := range res.Hits.Hits {
+		var doc document
+		if err := json.Unmarshal(hit.Source, &doc); err != nil {
+			return nil, fmt.Errorf("failed to decode search hit: %w", err)
+		}
+		results = append(results, providers.ProviderResult{
+			ID:      doc.ID,
+			Display: doc.Display,
+			Score:   float64(hit.Score),
+		})
+	}
+
+	return results, nil
+}
+
+// buildQuery constructs the OpenSearch query based on match strategy.
+func buildQuery(key, query string, options providers.QueryOptions) map[string]interface{} {
+	boolQuery := map[string]interface{}{
+		"filter": []interface{}{
+			map[string]interface{}{
+				"term": map[string]interface{}{
+					"key": key,
+				},
+			},
+		},
+	}
+	searchQuery := map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": boolQuery,
+		},
+	}
+
+	queryText := providers.SearchText(query, options.CaseSensitive)
+
+	if query != "" {
+		boolQuery["must"] = []interface{}{
+			map[string]interface{}{
+				"match": map[string]interface{}{
+					strategyField(options.MatchStrategy): queryText,
+				},
+			},
+		}
+	}
+
+	if options.MinScore > 0 {
+		searchQuery["min_score"] = options.MinScore
+	}
+
+	if options.SnapshotID != "" {
+		searchQuery["pit"] = pointInTime(options.SnapshotID, options.SnapshotKeepAlive)
+	}
+
+	return searchQuery
+}
+
+// strategyField returns the text subfield searched for a match strategy.
+func strategyField(strategy providers.MatchStrategy) string {
+	switch strategy {
+	case providers.MatchPrefix:
+		return "text.prefix"
+	case providers.MatchNGram:
+		return "text.ngram"
+	case providers.MatchSubstring, providers.MatchNOrMoreGram:
+		// Use substring matching for variable-length n-grams
+		return "text.substring"
+	default:
+		return "text"
+	}
+}
+
+// Delete removes an entry from the index.
+func (p *Provider) Delete(ctx context.Context, key, id string) error {
+	res, err := p.client.Document.Delete(ctx, opensearchapi.DocumentDeleteReq{
+		Index:      p.index,
+		DocumentID: url.PathEscape(generateDocumentID(key, id)),
+		Params:     opensearchapi.DocumentDeleteParams{Refresh: p.refreshPolicy},
+	})
+	// 404 is not an error for delete (idempotent)
+	if err != nil && (res == nil || statusCode(res.Inspect().Response) != http.StatusNotFound) {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteAll removes all entries for a given key namespace.
+func (p *Provider) DeleteAll(ctx context.Context, key string) error {
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"term": map[string]interface{}{
+				"key": key,
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(query); err != nil {
+		return fmt.Errorf("failed to encode query: %w", err)
+	}
+
+	refresh := p.refreshPolicy == "true"
+	_, err := p.client.Document.DeleteByQuery(ctx, opensearchapi.DocumentDeleteByQueryReq{
+		Indices: []string{p.index},
+		Body:    &buf,
+		Params:  opensearchapi.DocumentDeleteByQueryParams{Refresh: &refresh},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete by query: %w", err)
+	}
+
+	return nil
+}
+
+// SupportsMatchStrategies reports that entries can be queried under any combination
+// of strategies, since every document is analyzed into the prefix, n-gram, and
+// substring subfields of its text.
+func (p *Provider) SupportsMatchStrategies(strategies []providers.MatchStrategy) bool {
+	return true
+}
+
+// Close closes the provider connection.
+func (p *Provider) Close() error {
+	// The OpenSearch client uses standard HTTP connections managed by Go's http package
+	return nil
+}
+
+// newDocument builds the stored document for an entry.
+func newDocument(key, id, text, display string, options providers.IndexOptions) document {
+	return document{
+		ID:            id,
+		Key:           key,
+		Text:          text,
+		Display:       display,
+		Score:         options.Score,
+		CaseSensitive: options.CaseSensitive,
+		ContentHash:   options.ContentHash,
+	}
+}
+
+// generateDocumentID creates a unique document ID from key and id.
+func generateDocumentID(key, id string) string {
+	return fmt.Sprintf("%s:%s", key, id)
+}
+
+// statusCode returns the HTTP status of a response, or 0 if there was none.
+func statusCode(res *opensearchgo.Response) int {
+	if res == nil {
+		return 0
+	}
+	return res.StatusCode
+}
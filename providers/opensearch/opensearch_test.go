@@ -0,0 +1,174 @@
+package opensearch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// fakeCluster implements the document and search endpoints of a single-index
+// OpenSearch cluster. Search returns every stored document and records the
+// request body.
+type fakeCluster struct {
+	mu         sync.Mutex
+	index      string
+	mapping    map[string]any
+	docs       map[string]document
+	lastSearch map[string]any
+}
+
+func newFakeCluster(t *testing.T) (*fakeCluster, *httptest.Server) {
+	t.Helper()
+	f := &fakeCluster{docs: map[string]document{}}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func (f *fakeCluster) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	parts := strings.SplitN(strings.Trim(r.URL.Path, "/"), "/", 3)
+	switch {
+	case r.URL.Path == "/":
+		writeJSON(w, http.StatusOK, map[string]any{
+			"name":    "node-1",
+			"version": map[string]string{"distribution": "opensearch", "number": "2.19.0"},
+		})
+
+	case len(parts) == 1 && r.Method == http.MethodHead:
+		if f.index != parts[0] {
+			w.WriteHeader(http.StatusNotFound)
+		}
+
+	case len(parts) == 1 && r.Method == http.MethodPut:
+		f.index = parts[0]
+		_ = json.NewDecoder(r.Body).Decode(&f.mapping)
+		writeJSON(w, http.StatusOK, map[string]any{"acknowledged": true, "index": parts[0]})
+
+	case len(parts) == 3 && parts[1] == "_doc":
+		f.document(w, r, parts[2])
+
+	case len(parts) == 2 && parts[1] == "_search":
+		_ = json.NewDecoder(r.Body).Decode(&f.lastSearch)
+		var hits []map[string]any
+		for _, doc := range f.docs {
+			hits = append(hits, map[string]any{"_id": doc.Key + ":" + doc.ID, "_score": doc.Score, "_source": doc})
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"hits": map[string]any{"hits": hits}})
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeCluster) document(w http.ResponseWriter, r *http.Request, docID string) {
+	doc, found := f.docs[docID]
+	switch r.Method {
+	case http.MethodPut, http.MethodPost:
+		_ = json.NewDecoder(r.Body).Decode(&doc)
+		f.docs[docID] = doc
+		writeJSON(w, http.StatusCreated, map[string]any{"_id": docID, "result": "created"})
+
+	case http.MethodGet:
+		if !found {
+			writeJSON(w, http.StatusNotFound, map[string]any{"_id": docID, "found": false})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"_id": docID, "found": true, "_source": doc})
+
+	case http.MethodDelete:
+		delete(f.docs, docID)
+		status := http.StatusOK
+		if !found {
+			status = http.StatusNotFound
+		}
+		writeJSON(w, status, map[string]any{"_id": docID, "result": "deleted"})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func TestOpenSearchProvider(t *testing.T) {
+	fake, server := newFakeCluster(t)
+	provider, err := New(Config{URLs: []string{server.URL}, Index: "autocomplete"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	if fake.index != "autocomplete" || fake.mapping["mappings"] == nil {
+		t.Fatalf("index not created with mapping: %q, %v", fake.index, fake.mapping)
+	}
+
+	if err := provider.Index(ctx, "cities", "IN/MH", "Mumbai", "Mumbai, MH", providers.IndexOptions{Score: 2.0, ContentHash: "h1"}); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if _, stored := fake.docs["cities:IN/MH"]; !stored {
+		t.Fatalf("document ID not escaped into one path segment: %v", fake.docs)
+	}
+
+	if hash, exists, err := provider.ContentHash(ctx, "cities", "IN/MH"); err != nil || !exists || hash != "h1" {
+		t.Errorf("ContentHash() = %q, %t, %v; want h1, true", hash, exists, err)
+	}
+
+	results, err := provider.Query(ctx, "cities", "Mum", providers.QueryOptions{MatchStrategy: providers.MatchPrefix})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "IN/MH" || results[0].Display != "Mumbai, MH" || results[0].Score != 2.0 {
+		t.Errorf("Query() = %+v", results)
+	}
+	must := fake.lastSearch["query"].(map[string]any)["bool"].(map[string]any)["must"]
+	if got, _ := json.Marshal(must); string(got) != `[{"match":{"text.prefix":"mum"}}]` {
+		t.Errorf("query must clause = %s", got)
+	}
+
+	if err := provider.Delete(ctx, "cities", "IN/MH"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, exists, err := provider.ContentHash(ctx, "cities", "IN/MH"); err != nil || exists {
+		t.Errorf("ContentHash() after delete = %t, %v; want false, nil", exists, err)
+	}
+	if err := provider.Delete(ctx, "cities", "IN/MH"); err != nil {
+		t.Errorf("Delete() of missing document error = %v", err)
+	}
+
+	// A second provider finds the index already created.
+	fake.mapping = nil
+	if _, err := New(Config{URLs: []string{server.URL}, Index: "autocomplete"}); err != nil || fake.mapping != nil {
+		t.Errorf("New() on existing index = %v, recreated: %t", err, fake.mapping != nil)
+	}
+}
+
+func TestBuildQuery(t *testing.T) {
+	query := buildQuery("cities", "Pune", providers.QueryOptions{
+		MatchStrategy:     providers.MatchNOrMoreGram,
+		CaseSensitive:     true,
+		MinScore:          0.5,
+		SnapshotID:        "pit-1",
+		SnapshotKeepAlive: 90 * time.Second,
+	})
+
+	got, err := json.Marshal(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"min_score":0.5,"pit":{"id":"pit-1","keep_alive":"90s"},` +
+		`"query":{"bool":{"filter":[{"term":{"key":"cities"}}],"must":[{"match":{"text.substring":"Pune"}}]}}}`
+	if string(got) != want {
+		t.Errorf("buildQuery() = %s\nwant %s", got, want)
+	}
+}
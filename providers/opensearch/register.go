@@ -0,0 +1,29 @@
+package opensearch
+
+import (
+	"fmt"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// init registers the OpenSearch provider. Import this package with a blank identifier
+// to use OpenSearch as the autocomplete backend:
+//
+//	import _ "github.com/remiges-tech/autocomplete/providers/opensearch"
+//
+//nolint:gochecknoinits // init() is the idiomatic pattern for provider registration
+func init() {
+	autocomplete.RegisterProvider("opensearch", NewProvider)
+}
+
+// NewProvider creates a new OpenSearch provider from the given configuration.
+// It implements ProviderFactory and expects config to be of type opensearch.Config.
+func NewProvider(config interface{}) (providers.Provider, error) {
+	osConfig, ok := config.(Config)
+	if !ok {
+		return nil, fmt.Errorf("invalid configuration type for OpenSearch provider: expected opensearch.Config, got %T", config)
+	}
+
+	return New(osConfig)
+}
@@ -0,0 +1,54 @@
+package opensearch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/opensearch-project/opensearch-go/v4/opensearchapi"
+)
+
+// minKeepAlive is the shortest keep-alive requested for a point in time.
+const minKeepAlive = time.Second
+
+// OpenSnapshot creates an OpenSearch point in time on the provider's index.
+// Searches that reference it see the index as it was when it was created.
+func (p *Provider) OpenSnapshot(ctx context.Context, key string, keepAlive time.Duration) (string, error) {
+	res, err := p.client.PointInTime.Create(ctx, opensearchapi.PointInTimeCreateReq{
+		Indices: []string{p.index},
+		Params:  opensearchapi.PointInTimeCreateParams{KeepAlive: max(keepAlive, minKeepAlive)},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create point in time: %w", err)
+	}
+
+	return res.PitID, nil
+}
+
+// CloseSnapshot deletes an OpenSearch point in time.
+func (p *Provider) CloseSnapshot(ctx context.Context, snapshotID string) error {
+	res, err := p.client.PointInTime.Delete(ctx, opensearchapi.PointInTimeDeleteReq{
+		PitID: []string{snapshotID},
+	})
+	// 404 means the point in time already expired
+	if err != nil && (res == nil || statusCode(res.Inspect().Response) != http.StatusNotFound) {
+		return fmt.Errorf("failed to delete point in time: %w", err)
+	}
+
+	return nil
+}
+
+// pointInTime builds the "pit" clause of a search request.
+func pointInTime(snapshotID string, keepAlive time.Duration) map[string]interface{} {
+	pit := map[string]interface{}{"id": snapshotID}
+	if keepAlive > 0 {
+		pit["keep_alive"] = formatKeepAlive(keepAlive)
+	}
+	return pit
+}
+
+// formatKeepAlive converts a duration into an OpenSearch time unit string.
+func formatKeepAlive(keepAlive time.Duration) string {
+	return fmt.Sprintf("%ds", int(max(keepAlive, minKeepAlive)/time.Second))
+}
@@ -0,0 +1,21 @@
+// Package pebble implements the autocomplete Provider interface using Pebble,
+// the embedded, persistent key-value store of CockroachDB. Keys are kept in
+// order, so prefix queries are range scans over the stored text, much like
+// ZRANGEBYLEX in the Redis provider, without running a server.
+package pebble
+
+// Config holds Pebble parameters and provider-specific options.
+type Config struct {
+	// Path is the directory holding the database files, created if it does not exist.
+	// Ignored when InMemory is set.
+	Path string
+
+	// InMemory keeps the database in memory only, e.g. for tests.
+	// Default: false
+	InMemory bool
+
+	// SyncWrites makes every write durable before it returns, at the cost of
+	// write throughput. When false, a crash can lose the most recent writes.
+	// Default: false
+	SyncWrites bool
+}
@@ -0,0 +1,496 @@
+package pebble
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	pdb "github.com/cockroachdb/pebble/v2"
+	"github.com/cockroachdb/pebble/v2/vfs"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// defaultNGramSize is the default n-gram size when not specified in options.
+const defaultNGramSize = 3
+
+// Key layout. Both kinds of keys start with the namespace, so a namespace's
+// entries and postings are contiguous and can be scanned or dropped by range:
+//
+//	e\x00<namespace>\x00<id>                 -> JSON-encoded storedEntry
+//	t\x00<namespace>\x00<token>\x00<id>      -> position of token (uvarint)
+//
+// Tokens are stored whole rather than with each of their prefixes, and a query
+// scans the range of tokens starting with it: under MatchPrefix the token is
+// the entry's text, under MatchNGram its n-grams, and under MatchSubstring and
+// MatchNOrMoreGram its suffixes, as a substring occurs wherever a suffix
+// starts with it.
+const (
+	entryKeyPrefix   = "e"
+	postingKeyPrefix = "t"
+	keySeparator     = "\x00"
+)
+
+// Provider implements the autocomplete Provider interface using Pebble.
+// Each entry is stored once, with one posting key per token pointing back to
+// it. Writes are applied in batches, so each is atomic, and queries read from
+// a snapshot, so they never see a write half applied.
+// All methods are safe for concurrent use.
+type Provider struct {
+	db           *pdb.DB
+	writeOptions *pdb.WriteOptions
+
+	// mu serializes writes, which read the previous tokens of an entry before
+	// replacing them.
+	mu sync.Mutex
+}
+
+// storedEntry is the stored form of an entry.
+type storedEntry struct {
+	Text       string                 `json:"text"`
+	SearchText string                 `json:"search_text"`
+	Display    string                 `json:"display"`
+	Options    providers.IndexOptions `json:"options"`
+}
+
+// token is a single indexed token and the byte offset at which it starts.
+type token struct {
+	text     string
+	position int
+}
+
+// match is a candidate result with the data used to rank it.
+type match struct {
+	id       string
+	position int
+	entry    storedEntry
+}
+
+// New opens (or creates) the Pebble database described by config.
+func New(config Config) (*Provider, error) {
+	options := &pdb.Options{Logger: warningLogger{}}
+	if config.InMemory {
+		options.FS = vfs.NewMem()
+	} else if config.Path == "" {
+		return nil, errors.New("database path is required unless InMemory is set")
+	}
+
+	db, err := pdb.Open(config.Path, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Pebble database: %w", err)
+	}
+	writeOptions := pdb.NoSync
+	if config.SyncWrites {
+		writeOptions = pdb.Sync
+	}
+	return &Provider{db: db, writeOptions: writeOptions}, nil
+}
+
+// Index adds or updates an entry, replacing the postings of its previous text.
+func (p *Provider) Index(ctx context.Context, key, id, text, display string, options providers.IndexOptions) error {
+	err := p.update(func(batch *pdb.Batch) error {
+		return putEntry(batch, key, id, text, display, options)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to index entry: %w", err)
+	}
+	return nil
+}
+
+// IndexAtomic writes all entries in a single batch.
+func (p *Provider) IndexAtomic(ctx context.Context, key string, entries []providers.IndexEntry) error {
+	err := p.update(func(batch *pdb.Batch) error {
+		for _, entry := range entries {
+			if err := putEntry(batch, key, entry.ID, entry.Text, entry.Display, entry.Options); err != nil {
+				return fmt.Errorf("entry %q: %w", entry.ID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to index entries: %w", err)
+	}
+	return nil
+}
+
+// update commits the writes fn makes to a batch, which reads its own writes.
+func (p *Provider) update(fn func(batch *pdb.Batch) error) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	batch := p.db.NewIndexedBatch()
+	defer func() { _ = batch.Close() }()
+	if err := fn(batch); err != nil {
+		return err
+	}
+	return batch.Commit(p.writeOptions)
+}
+
+// putEntry stores an entry and its postings, removing the postings of the entry's previous text.
+func putEntry(batch *pdb.Batch, key, id, text, display string, options providers.IndexOptions) error {
+	if err := removeEntry(batch, key, id); err != nil {
+		return err
+	}
+
+	searchText := providers.SearchText(text, options.CaseSensitive)
+	value, err := json.Marshal(storedEntry{Text: text, SearchText: searchText, Display: display, Options: options})
+	if err != nil {
+		return err
+	}
+	if err := batch.Set(entryKey(key, id), value, nil); err != nil {
+		return err
+	}
+
+	for tok, position := range earliestPositions(tokenize(searchText, options)) {
+		if err := batch.Set(postingKey(key, tok, id), binary.AppendUvarint(nil, uint64(position)), nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeEntry deletes an entry and its postings, if it exists.
+func removeEntry(batch *pdb.Batch, key, id string) error {
+	old, exists, err := getEntry(batch, key, id)
+	if err != nil || !exists {
+		return err
+	}
+
+	for tok := range earliestPositions(tokenize(old.SearchText, old.Options)) {
+		if err := batch.Delete(postingKey(key, tok, id), nil); err != nil {
+			return err
+		}
+	}
+	return batch.Delete(entryKey(key, id), nil)
+}
+
+// getEntry reads an entry and reports whether it exists.
+func getEntry(reader pdb.Reader, key, id string) (storedEntry, bool, error) {
+	value, closer, err := reader.Get(entryKey(key, id))
+	if errors.Is(err, pdb.ErrNotFound) {
+		return storedEntry{}, false, nil
+	}
+	if err != nil {
+		return storedEntry{}, false, err
+	}
+	defer func() { _ = closer.Close() }()
+
+	var entry storedEntry
+	err = json.Unmarshal(value, &entry)
+	return entry, err == nil, err
+}
+
+// ContentHash returns the stored content hash for an entry and whether it exists.
+func (p *Provider) ContentHash(ctx context.Context, key, id string) (string, bool, error) {
+	entry, exists, err := getEntry(p.db, key, id)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get content hash: %w", err)
+	}
+	return entry.Options.ContentHash, exists, nil
+}
+
+// ListEntries returns up to count entries of the namespace in ID order,
+// starting after the ID given as cursor.
+func (p *Provider) ListEntries(ctx context.Context, key, cursor string, count int) ([]providers.StoredEntry, string, error) {
+	prefix := entryKey(key, "")
+	iter, err := p.db.NewIter(&pdb.IterOptions{LowerBound: entryKey(key, cursor), UpperBound: prefixEnd(prefix)})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list entries: %w", err)
+	}
+
+	var entries []providers.StoredEntry
+	for iter.First(); iter.Valid() && len(entries) < count; iter.Next() {
+		id := string(iter.Key()[len(prefix):])
+		if id == cursor {
+			continue
+		}
+		var entry storedEntry
+		if err := json.Unmarshal(iter.Value(), &entry); err != nil {
+			_ = iter.Close()
+			return nil, "", fmt.Errorf("failed to list entries: %w", err)
+		}
+		entries = append(entries, providers.StoredEntry{
+			ID: id, Text: entry.Text, Display: entry.Display, ContentHash: entry.Options.ContentHash,
+		})
+	}
+	if err := iter.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to list entries: %w", err)
+	}
+
+	next := ""
+	if count > 0 && len(entries) == count {
+		next = entries[len(entries)-1].ID
+	}
+	return entries, next, nil
+}
+
+// Query searches for entries matching the given query. Results are ranked by
+// entry score, then match position, then text length.
+func (p *Provider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	searchQuery := providers.SearchText(query, options.CaseSensitive)
+
+	tag := ""
+	if len(options.MatchStrategies) > 0 {
+		tag = strategyTag(options.MatchStrategy)
+	}
+	n := getNGramSizeOrDefault(options.NGramSize)
+	if searchQuery == "" || (options.MatchStrategy == providers.MatchNOrMoreGram && len(searchQuery) < n) {
+		return []providers.ProviderResult{}, nil
+	}
+
+	snapshot := p.db.NewSnapshot()
+	defer func() { _ = snapshot.Close() }()
+
+	var matches []match
+	var err error
+	if options.MatchStrategy == providers.MatchNGram && len(searchQuery) > n {
+		matches, err = intersect(snapshot, key, tag, searchQuery, n)
+	} else {
+		matches, err = scan(snapshot, key, tag+searchQuery)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query autocomplete: %w", err)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.entry.Options.Score != b.entry.Options.Score {
+			return a.entry.Options.Score > b.entry.Options.Score
+		}
+		if a.position != b.position {
+			return a.position < b.position
+		}
+		if len(a.entry.Text) != len(b.entry.Text) {
+			return len(a.entry.Text) < len(b.entry.Text)
+		}
+		return a.id < b.id
+	})
+
+	if options.MaxResults > 0 && len(matches) > options.MaxResults {
+		matches = matches[:options.MaxResults]
+	}
+	results := make([]providers.ProviderResult, len(matches))
+	for i, m := range matches {
+		results[i] = providers.ProviderResult{ID: m.id, Display: m.entry.Display, Score: m.entry.Options.Score}
+	}
+	return results, nil
+}
+
+// scan returns the entries with a token starting with prefix, positioned at
+// the earliest such token.
+func scan(reader pdb.Reader, key, prefix string) ([]match, error) {
+	base := postingKeyPrefixFor(key)
+	lower := append(postingKeyPrefixFor(key), prefix...)
+	iter, err := reader.NewIter(&pdb.IterOptions{LowerBound: lower, UpperBound: prefixEnd(lower)})
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make(map[string]int)
+	for iter.First(); iter.Valid(); iter.Next() {
+		rest := iter.Key()[len(base):]
+		separator := bytes.IndexByte(rest, keySeparator[0])
+		if separator < 0 {
+			continue
+		}
+		id := string(rest[separator+1:])
+		value, _ := binary.Uvarint(iter.Value())
+		if position, seen := positions[id]; !seen || int(value) < position {
+			positions[id] = int(value)
+		}
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+
+	matches := make([]match, 0, len(positions))
+	for id, position := range positions {
+		entry, exists, err := getEntry(reader, key, id)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			matches = append(matches, match{id: id, position: position, entry: entry})
+		}
+	}
+	return matches, nil
+}
+
+// intersect returns the entries containing every n-gram of the query,
+// positioned at the query's first n-gram. Tag prefixes each n-gram.
+func intersect(reader pdb.Reader, key, tag, searchQuery string, n int) ([]match, error) {
+	matches, err := scan(reader, key, tag+searchQuery[:n])
+	if err != nil {
+		return nil, err
+	}
+	for i := 1; i <= len(searchQuery)-n; i++ {
+		kept := matches[:0]
+		for _, m := range matches {
+			_, closer, err := reader.Get(postingKey(key, tag+searchQuery[i:i+n], m.id))
+			switch {
+			case err == nil:
+				_ = closer.Close()
+				kept = append(kept, m)
+			case !errors.Is(err, pdb.ErrNotFound):
+				return nil, err
+			}
+		}
+		matches = kept
+	}
+	return matches, nil
+}
+
+// Delete removes an entry and its postings from the index.
+func (p *Provider) Delete(ctx context.Context, key, id string) error {
+	err := p.update(func(batch *pdb.Batch) error {
+		return removeEntry(batch, key, id)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete entry: %w", err)
+	}
+	return nil
+}
+
+// DeleteAll removes all entries and postings for a given key with two range deletions.
+func (p *Provider) DeleteAll(ctx context.Context, key string) error {
+	err := p.update(func(batch *pdb.Batch) error {
+		for _, prefix := range [][]byte{entryKey(key, ""), postingKeyPrefixFor(key)} {
+			if err := batch.DeleteRange(prefix, prefixEnd(prefix), nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete entries: %w", err)
+	}
+	return nil
+}
+
+// Close closes the database, flushing pending writes.
+func (p *Provider) Close() error {
+	return p.db.Close()
+}
+
+// SupportsMatchStrategies reports that entries can be indexed under any
+// combination of strategies.
+func (p *Provider) SupportsMatchStrategies(strategies []providers.MatchStrategy) bool {
+	return true
+}
+
+// warningLogger drops Pebble's informational messages, such as the WAL
+// replay report of every open, and logs its errors.
+type warningLogger struct{}
+
+func (warningLogger) Infof(format string, args ...interface{}) {}
+
+func (warningLogger) Errorf(format string, args ...interface{}) {
+	log.Printf("pebble: "+format, args...)
+}
+
+func (warningLogger) Fatalf(format string, args ...interface{}) {
+	log.Fatalf("pebble: "+format, args...)
+}
+
+func entryKey(key, id string) []byte {
+	return []byte(entryKeyPrefix + keySeparator + key + keySeparator + id)
+}
+
+func postingKeyPrefixFor(key string) []byte {
+	return []byte(postingKeyPrefix + keySeparator + key + keySeparator)
+}
+
+func postingKey(key, tok, id string) []byte {
+	return []byte(postingKeyPrefix + keySeparator + key + keySeparator + tok + keySeparator + id)
+}
+
+// prefixEnd returns the smallest key greater than every key starting with prefix.
+func prefixEnd(prefix []byte) []byte {
+	end := bytes.Clone(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
+
+// earliestPositions maps each distinct token to the earliest position at which it occurs.
+func earliestPositions(tokens []token) map[string]int {
+	positions := make(map[string]int, len(tokens))
+	for _, tok := range tokens {
+		if position, seen := positions[tok.text]; !seen || tok.position < position {
+			positions[tok.text] = tok.position
+		}
+	}
+	return positions
+}
+
+// strategyTag prefixes the tokens of one strategy when an entry is indexed under
+// several, keeping each strategy's tokens apart. It starts with \x01 rather than
+// the \x00 used by the other providers, which separates key components here.
+func strategyTag(strategy providers.MatchStrategy) string {
+	return "\x01" + string(rune('0'+strategy))
+}
+
+// tokenize splits normalized text into the tokens stored for the given match strategy,
+// or for each of options.MatchStrategies with strategy-tagged tokens.
+func tokenize(text string, options providers.IndexOptions) []token {
+	if len(options.MatchStrategies) > 0 {
+		var tokens []token
+		for _, strategy := range options.MatchStrategies {
+			single := options
+			single.MatchStrategy = strategy
+			single.MatchStrategies = nil
+			tag := strategyTag(strategy)
+			for _, tok := range tokenize(text, single) {
+				tokens = append(tokens, token{text: tag + tok.text, position: tok.position})
+			}
+		}
+		return tokens
+	}
+
+	var tokens []token
+	switch options.MatchStrategy {
+	case providers.MatchPrefix:
+		if text != "" {
+			tokens = append(tokens, token{text: text})
+		}
+
+	case providers.MatchNGram:
+		n := getNGramSizeOrDefault(options.NGramSize)
+		for start := 0; start <= len(text)-n; start++ {
+			tokens = append(tokens, token{text: text[start : start+n], position: start})
+		}
+
+	case providers.MatchNOrMoreGram:
+		tokens = suffixTokens(text, getNGramSizeOrDefault(options.NGramSize))
+
+	case providers.MatchSubstring:
+		tokens = suffixTokens(text, 1)
+	}
+	return tokens
+}
+
+// suffixTokens returns every suffix of text at least minLength bytes long.
+func suffixTokens(text string, minLength int) []token {
+	var tokens []token
+	for start := 0; start <= len(text)-minLength; start++ {
+		tokens = append(tokens, token{text: text[start:], position: start})
+	}
+	return tokens
+}
+
+func getNGramSizeOrDefault(size int) int {
+	if size <= 0 {
+		return defaultNGramSize
+	}
+	return size
+}
@@ -0,0 +1,29 @@
+package pebble
+
+import (
+	"fmt"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// init registers the Pebble provider. Import this package with a blank identifier
+// to use Pebble as the autocomplete backend:
+//
+//	import _ "github.com/remiges-tech/autocomplete/providers/pebble"
+//
+//nolint:gochecknoinits // init() is the idiomatic pattern for provider registration
+func init() {
+	autocomplete.RegisterProvider("pebble", NewProvider)
+}
+
+// NewProvider creates a new Pebble provider from the given configuration.
+// It implements ProviderFactory and expects config to be of type pebble.Config.
+func NewProvider(config interface{}) (providers.Provider, error) {
+	pebbleConfig, ok := config.(Config)
+	if !ok {
+		return nil, fmt.Errorf("invalid configuration type for Pebble provider: expected pebble.Config, got %T", config)
+	}
+
+	return New(pebbleConfig)
+}
@@ -0,0 +1,309 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	// Registers the "pgx" database/sql driver.
+	_ "github.com/jackc/pgx/v5/stdlib"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+const (
+	// defaultNGramSize is the default n-gram size when not specified in options.
+	defaultNGramSize = 3
+
+	// schemaTemplate creates the pg_trgm extension, the entries table, and its indexes.
+	// The GIN trigram index serves prefix, substring, and n-gram LIKE patterns; the
+	// text_pattern_ops index additionally serves short prefixes, which have too few
+	// trigrams for the GIN index to be selective.
+	schemaTemplate = `
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE TABLE IF NOT EXISTS %[1]s (
+	key            TEXT NOT NULL,
+	id             TEXT NOT NULL,
+	text           TEXT NOT NULL,
+	search_text    TEXT NOT NULL,
+	display        TEXT NOT NULL,
+	score          DOUBLE PRECISION NOT NULL DEFAULT 1,
+	case_sensitive BOOLEAN NOT NULL DEFAULT FALSE,
+	content_hash   TEXT NOT NULL DEFAULT '',
+	PRIMARY KEY (key, id)
+);
+
+CREATE INDEX IF NOT EXISTS %[2]s_trgm_idx ON %[1]s USING GIN (search_text gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS %[2]s_prefix_idx ON %[1]s (key, search_text text_pattern_ops);
+`
+
+	// upsertTemplate inserts an entry or replaces the stored entry with the same key and id.
+	upsertTemplate = `
+INSERT INTO %s (key, id, text, search_text, display, score, case_sensitive, content_hash)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (key, id) DO UPDATE SET
+	text = EXCLUDED.text,
+	search_text = EXCLUDED.search_text,
+	display = EXCLUDED.display,
+	score = EXCLUDED.score,
+	case_sensitive = EXCLUDED.case_sensitive,
+	content_hash = EXCLUDED.content_hash`
+)
+
+// tableNamePattern restricts table names to plain, optionally schema-qualified identifiers,
+// since they are interpolated into SQL.
+var tableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// likeEscaper escapes LIKE wildcards in user queries. Backslash is the default LIKE escape character.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Provider implements the autocomplete Provider interface using PostgreSQL.
+// Entries of all namespaces are rows of a single table, isolated by the key column,
+// and matched with LIKE patterns served by a pg_trgm GIN index.
+// All methods are safe for concurrent use.
+type Provider struct {
+	db    *sql.DB
+	table string
+}
+
+// New creates a new PostgreSQL provider with the given configuration.
+// It verifies connectivity and, unless SkipSchemaSetup is set, creates the schema.
+func New(config Config) (*Provider, error) {
+	config.setDefaults()
+	if !tableNamePattern.MatchString(config.Table) {
+		return nil, fmt.Errorf("invalid table name %q", config.Table)
+	}
+
+	db, err := sql.Open("pgx", config.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PostgreSQL connection: %w", err)
+	}
+
+	ctx := context.Background()
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to connect to PostgreSQL: %w", err)
+	}
+
+	if !config.SkipSchemaSetup {
+		if _, err := db.ExecContext(ctx, Schema(config.Table)); err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("failed to create schema: %w", err)
+		}
+	}
+
+	return &Provider{db: db, table: config.Table}, nil
+}
+
+// Schema returns the SQL that creates the pg_trgm extension, the entries table,
+// and its indexes, for use in migrations when Config.SkipSchemaSetup is set.
+func Schema(table string) string {
+	indexPrefix := strings.ReplaceAll(table, ".", "_")
+	return fmt.Sprintf(schemaTemplate, table, indexPrefix)
+}
+
+// Index adds or updates an entry in the autocomplete index.
+func (p *Provider) Index(ctx context.Context, key, id, text, display string, options providers.IndexOptions) error {
+	if err := p.upsert(ctx, p.db, key, id, text, display, options); err != nil {
+		return fmt.Errorf("failed to index entry: %w", err)
+	}
+	return nil
+}
+
+// IndexAtomic writes all entries inside a single transaction.
+func (p *Provider) IndexAtomic(ctx context.Context, key string, entries []providers.IndexEntry) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	for _, entry := range entries {
+		if err := p.upsert(ctx, tx, key, entry.ID, entry.Text, entry.Display, entry.Options); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to index entry %q: %w", entry.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit index transaction: %w", err)
+	}
+	return nil
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// upsert writes a single entry, storing its text normalized for matching.
+func (p *Provider) upsert(
+	ctx context.Context, db execer, key, id, text, display string, options providers.IndexOptions,
+) error {
+	searchText := providers.SearchText(text, options.CaseSensitive)
+
+	_, err := db.ExecContext(ctx, fmt.Sprintf(upsertTemplate, p.table),
+		key, id, text, searchText, display, options.Score, options.CaseSensitive, options.ContentHash)
+	return err
+}
+
+// ContentHash returns the stored content hash for an entry and whether it exists.
+func (p *Provider) ContentHash(ctx context.Context, key, id string) (string, bool, error) {
+	var hash string
+	err := p.db.QueryRowContext(ctx,
+		fmt.Sprintf("SELECT content_hash FROM %s WHERE key = $1 AND id = $2", p.table), key, id,
+	).Scan(&hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get content hash: %w", err)
+	}
+	return hash, true, nil
+}
+
+// ListEntries returns up to count entries of the namespace in ID order,
+// starting after the ID given as cursor.
+func (p *Provider) ListEntries(ctx context.Context, key, cursor string, count int) ([]providers.StoredEntry, string, error) {
+	rows, err := p.db.QueryContext(ctx, fmt.Sprintf(
+		"SELECT id, text, display, content_hash FROM %s WHERE key = $1 AND id > $2 ORDER BY id LIMIT $3", p.table),
+		key, cursor, count)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []providers.StoredEntry
+	for rows.Next() {
+		var entry providers.StoredEntry
+		if err := rows.Scan(&entry.ID, &entry.Text, &entry.Display, &entry.ContentHash); err != nil {
+			return nil, "", fmt.Errorf("failed to read entries: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("failed to read entries: %w", err)
+	}
+
+	next := ""
+	if len(entries) == count {
+		next = entries[len(entries)-1].ID
+	}
+	return entries, next, nil
+}
+
+// Query searches for entries matching the given query.
+// Results are ranked by entry score, then match position, then text length.
+func (p *Provider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	searchQuery := providers.SearchText(query, options.CaseSensitive)
+
+	condition, args, ok := matchCondition(searchQuery, options, 3)
+	if !ok {
+		return []providers.ProviderResult{}, nil
+	}
+
+	// $1 is the namespace, $2 the query used for ranking by match position
+	args = append([]interface{}{key, searchQuery}, args...)
+	args = append(args, options.MaxResults)
+	statement := fmt.Sprintf(`
+SELECT id, display, score FROM %s
+WHERE key = $1 AND %s
+ORDER BY score DESC, strpos(search_text, $2) ASC, length(text) ASC, id ASC
+LIMIT $%d`, p.table, condition, len(args))
+
+	rows, err := p.db.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query autocomplete: %w", err)
+	}
+	defer rows.Close()
+
+	results := []providers.ProviderResult{}
+	for rows.Next() {
+		var result providers.ProviderResult
+		if err := rows.Scan(&result.ID, &result.Display, &result.Score); err != nil {
+			return nil, fmt.Errorf("failed to read query results: %w", err)
+		}
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read query results: %w", err)
+	}
+	return results, nil
+}
+
+// matchCondition returns the WHERE condition selecting entries that match the query
+// under the given strategy, with its LIKE pattern arguments numbered from firstArg.
+// It reports false when no entry can match, e.g. a query shorter than the n-gram size
+// under MatchNOrMoreGram.
+func matchCondition(searchQuery string, options providers.QueryOptions, firstArg int) (string, []interface{}, bool) {
+	if searchQuery == "" {
+		return "", nil, false
+	}
+	escaped := likeEscaper.Replace(searchQuery)
+	n := options.NGramSize
+	if n <= 0 {
+		n = defaultNGramSize
+	}
+
+	switch options.MatchStrategy {
+	case providers.MatchPrefix:
+		return fmt.Sprintf("search_text LIKE $%d", firstArg), []interface{}{escaped + "%"}, true
+
+	case providers.MatchNGram:
+		if len(searchQuery) <= n {
+			// An n-gram starts with the query, so at least n-len(query) characters follow it
+			pattern := "%" + escaped + strings.Repeat("_", n-len(searchQuery)) + "%"
+			return fmt.Sprintf("search_text LIKE $%d", firstArg), []interface{}{pattern}, true
+		}
+		// Longer queries match entries containing every n-gram of the query
+		clauses := make([]string, 0, len(searchQuery)-n+1)
+		args := make([]interface{}, 0, len(searchQuery)-n+1)
+		for i := 0; i <= len(searchQuery)-n; i++ {
+			clauses = append(clauses, fmt.Sprintf("search_text LIKE $%d", firstArg+i))
+			args = append(args, "%"+likeEscaper.Replace(searchQuery[i:i+n])+"%")
+		}
+		return "(" + strings.Join(clauses, " AND ") + ")", args, true
+
+	case providers.MatchNOrMoreGram:
+		if len(searchQuery) < n {
+			return "", nil, false
+		}
+		return fmt.Sprintf("search_text LIKE $%d", firstArg), []interface{}{"%" + escaped + "%"}, true
+
+	default:
+		return fmt.Sprintf("search_text LIKE $%d", firstArg), []interface{}{"%" + escaped + "%"}, true
+	}
+}
+
+// SupportsMatchStrategies reports that entries can be queried under any combination
+// of strategies. Every strategy is a LIKE pattern over the same normalized text,
+// so nothing strategy-specific is stored.
+func (p *Provider) SupportsMatchStrategies(strategies []providers.MatchStrategy) bool {
+	return true
+}
+
+// Delete removes an entry from the index.
+func (p *Provider) Delete(ctx context.Context, key, id string) error {
+	_, err := p.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE key = $1 AND id = $2", p.table), key, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete entry: %w", err)
+	}
+	return nil
+}
+
+// DeleteAll removes all entries for a given key.
+func (p *Provider) DeleteAll(ctx context.Context, key string) error {
+	_, err := p.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE key = $1", p.table), key)
+	if err != nil {
+		return fmt.Errorf("failed to delete entries: %w", err)
+	}
+	return nil
+}
+
+// Close closes the database connection pool.
+func (p *Provider) Close() error {
+	return p.db.Close()
+}
@@ -0,0 +1,254 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+const testKey = "test"
+
+// newTestProvider starts a PostgreSQL container and returns a provider on it,
+// skipping the test when Docker is unavailable.
+func newTestProvider(t *testing.T) *Provider {
+	t.Helper()
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	ctx := context.Background()
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "postgres:16-alpine",
+			ExposedPorts: []string{"5432/tcp"},
+			Env: map[string]string{
+				"POSTGRES_PASSWORD": "secret",
+				"POSTGRES_DB":       "autocomplete",
+			},
+			// The server restarts once after initializing the database
+			WaitingFor: wait.ForLog("database system is ready to accept connections").
+				WithOccurrence(2).WithStartupTimeout(time.Minute),
+		},
+		Started: true,
+	})
+	testcontainers.CleanupContainer(t, container)
+	if err != nil {
+		t.Fatalf("failed to start PostgreSQL container: %v", err)
+	}
+	host, err := container.Host(ctx)
+	if err != nil {
+		t.Fatalf("failed to get container host: %v", err)
+	}
+	port, err := container.MappedPort(ctx, "5432")
+	if err != nil {
+		t.Fatalf("failed to get container port: %v", err)
+	}
+
+	provider, err := New(Config{
+		DSN: fmt.Sprintf("postgres://postgres:secret@%s:%s/autocomplete?sslmode=disable", host, port.Port()),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = provider.Close() })
+	return provider
+}
+
+// queryIDs returns the IDs of the results of a query, in order.
+func queryIDs(t *testing.T, provider *Provider, key, query string, options providers.QueryOptions) []string {
+	t.Helper()
+	results, err := provider.Query(context.Background(), key, query, options)
+	if err != nil {
+		t.Fatalf("Query(%q) error = %v", query, err)
+	}
+	ids := make([]string, len(results))
+	for i, result := range results {
+		ids[i] = result.ID
+	}
+	return ids
+}
+
+func TestPostgresProvider_RoundTrip(t *testing.T) {
+	provider := newTestProvider(t)
+	ctx := context.Background()
+
+	entries := []providers.IndexEntry{
+		{ID: "1", Text: "Mumbai", Display: "Mumbai, MH"},
+		{ID: "2", Text: "Navi Mumbai", Display: "Navi Mumbai, MH"},
+		{ID: "3", Text: "Mumbra", Display: "Mumbra, MH"},
+	}
+	for _, entry := range entries {
+		options := providers.IndexOptions{Score: 1, ContentHash: "v1-" + entry.ID}
+		if err := provider.Index(ctx, testKey, entry.ID, entry.Text, entry.Display, options); err != nil {
+			t.Fatalf("Index(%s) error = %v", entry.ID, err)
+		}
+	}
+	// The same IDs in another namespace must not show up in testKey
+	if err := provider.Index(ctx, "other", "1", "Mumbai Other", "Mumbai Other", providers.IndexOptions{Score: 1}); err != nil {
+		t.Fatalf("Index() in another namespace error = %v", err)
+	}
+	if err := provider.Index(ctx, "other", "4", "Mumbai Extra", "Mumbai Extra", providers.IndexOptions{Score: 9}); err != nil {
+		t.Fatalf("Index() in another namespace error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		strategy providers.MatchStrategy
+		limit    int
+		want     []string
+	}{
+		{"prefix", "MUM", providers.MatchPrefix, 10, []string{"1", "3"}},
+		{"substring", "umba", providers.MatchSubstring, 10, []string{"1", "2"}},
+		{"ngram", "umba", providers.MatchNGram, 10, []string{"1", "2"}},
+		{"n-or-more", "mumb", providers.MatchNOrMoreGram, 10, []string{"1", "3", "2"}},
+		{"n-or-more too short", "mu", providers.MatchNOrMoreGram, 10, []string{}},
+		{"limit", "mumb", providers.MatchNOrMoreGram, 1, []string{"1"}},
+		{"wildcards are literal", "m%", providers.MatchSubstring, 10, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := providers.QueryOptions{MatchStrategy: tt.strategy, NGramSize: 3, MaxResults: tt.limit}
+			if got := queryIDs(t, provider, testKey, tt.query, options); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Query(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+
+	// Score ranks ahead of match position
+	if err := provider.Index(ctx, testKey, "2", "Navi Mumbai", "Navi Mumbai, MH", providers.IndexOptions{Score: 5}); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	substring := providers.QueryOptions{MatchStrategy: providers.MatchSubstring, MaxResults: 10}
+	if got := queryIDs(t, provider, testKey, "mumbai", substring); !reflect.DeepEqual(got, []string{"2", "1"}) {
+		t.Errorf("Query() after raising the score of 2 = %v, want [2 1]", got)
+	}
+
+	// Reindexing an ID overwrites its text, display and hash
+	if err := provider.Index(ctx, testKey, "1", "Pune", "Pune, MH", providers.IndexOptions{Score: 1, ContentHash: "v2-1"}); err != nil {
+		t.Fatalf("Index() overwrite error = %v", err)
+	}
+	prefix := providers.QueryOptions{MatchStrategy: providers.MatchPrefix, MaxResults: 10}
+	if got := queryIDs(t, provider, testKey, "mum", prefix); !reflect.DeepEqual(got, []string{"3"}) {
+		t.Errorf("Query(mum) after overwrite = %v, want [3]", got)
+	}
+	results, err := provider.Query(ctx, testKey, "pune", prefix)
+	if err != nil || len(results) != 1 || results[0].Display != "Pune, MH" {
+		t.Errorf("Query(pune) after overwrite = %v, %v; want the new display", results, err)
+	}
+	if hash, ok, err := provider.ContentHash(ctx, testKey, "1"); err != nil || !ok || hash != "v2-1" {
+		t.Errorf("ContentHash(1) = %q, %v, %v; want v2-1", hash, ok, err)
+	}
+	if _, ok, err := provider.ContentHash(ctx, testKey, "missing"); err != nil || ok {
+		t.Errorf("ContentHash(missing) = %v, %v; want not found", ok, err)
+	}
+
+	// ListEntries pages in ID order within the namespace
+	page, next, err := provider.ListEntries(ctx, testKey, "", 2)
+	if err != nil || len(page) != 2 || page[0].ID != "1" || page[1].ID != "2" || next != "2" {
+		t.Fatalf("ListEntries() first page = %v, %q, %v", page, next, err)
+	}
+	page, next, err = provider.ListEntries(ctx, testKey, next, 2)
+	if err != nil || len(page) != 1 || page[0].ID != "3" || page[0].ContentHash != "v1-3" || next != "" {
+		t.Errorf("ListEntries() last page = %v, %q, %v", page, next, err)
+	}
+
+	// Delete and DeleteAll touch only their namespace
+	if err := provider.Delete(ctx, testKey, "3"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if got := queryIDs(t, provider, testKey, "mum", prefix); len(got) != 0 {
+		t.Errorf("Query() after Delete = %v, want none", got)
+	}
+	if got := queryIDs(t, provider, "other", "mumbai", prefix); !reflect.DeepEqual(got, []string{"4", "1"}) {
+		t.Errorf("Query() in the other namespace = %v, want [4 1]", got)
+	}
+	if err := provider.DeleteAll(ctx, testKey); err != nil {
+		t.Fatalf("DeleteAll() error = %v", err)
+	}
+	if entries, _, err := provider.ListEntries(ctx, testKey, "", 10); err != nil || len(entries) != 0 {
+		t.Errorf("ListEntries() after DeleteAll = %v, %v; want none", entries, err)
+	}
+	if entries, _, err := provider.ListEntries(ctx, "other", "", 10); err != nil || len(entries) != 2 {
+		t.Errorf("ListEntries() of the other namespace after DeleteAll = %v, %v; want both entries", entries, err)
+	}
+}
+
+func TestPostgresProvider_IndexAtomic(t *testing.T) {
+	provider := newTestProvider(t)
+	ctx := context.Background()
+	prefix := providers.QueryOptions{MatchStrategy: providers.MatchPrefix, MaxResults: 10}
+
+	if err := provider.IndexAtomic(ctx, testKey, []providers.IndexEntry{
+		{ID: "1", Text: "Mumbai", Display: "Mumbai", Options: providers.IndexOptions{Score: 1}},
+		{ID: "2", Text: "Mumbra", Display: "Mumbra", Options: providers.IndexOptions{Score: 1}},
+	}); err != nil {
+		t.Fatalf("IndexAtomic() error = %v", err)
+	}
+	if got := queryIDs(t, provider, testKey, "mum", prefix); !reflect.DeepEqual(got, []string{"1", "2"}) {
+		t.Errorf("Query() after IndexAtomic = %v, want [1 2]", got)
+	}
+
+	// PostgreSQL rejects NUL bytes in text, failing the batch after its first
+	// entry was written; the rollback must undo that write
+	err := provider.IndexAtomic(ctx, testKey, []providers.IndexEntry{
+		{ID: "1", Text: "Munnar", Display: "Munnar", Options: providers.IndexOptions{Score: 1}},
+		{ID: "3", Text: "Mundra", Display: "Mundra", Options: providers.IndexOptions{Score: 1}},
+		{ID: "4", Text: "Mun\x00ger", Display: "Munger", Options: providers.IndexOptions{Score: 1}},
+	})
+	if err == nil {
+		t.Fatal("IndexAtomic() with an invalid entry should fail")
+	}
+	if got := queryIDs(t, provider, testKey, "mun", prefix); len(got) != 0 {
+		t.Errorf("Query(mun) after a failed IndexAtomic = %v, want none", got)
+	}
+	results, err := provider.Query(ctx, testKey, "mum", prefix)
+	if err != nil || len(results) != 2 || results[0].Display != "Mumbai" {
+		t.Errorf("Query(mum) after a failed IndexAtomic = %v, %v; want the entries as before", results, err)
+	}
+}
+
+func TestMatchCondition(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		strategy  providers.MatchStrategy
+		condition string
+		args      []interface{}
+		ok        bool
+	}{
+		{"prefix", "mum", providers.MatchPrefix, "search_text LIKE $3", []interface{}{"mum%"}, true},
+		{"substring", "mum", providers.MatchSubstring, "search_text LIKE $3", []interface{}{"%mum%"}, true},
+		{"escaped wildcards", "5%_", providers.MatchSubstring, "search_text LIKE $3", []interface{}{`%5\%\_%`}, true},
+		{"short ngram", "mu", providers.MatchNGram, "search_text LIKE $3", []interface{}{"%mu_%"}, true},
+		{
+			"long ngram", "umba", providers.MatchNGram,
+			"(search_text LIKE $3 AND search_text LIKE $4)", []interface{}{"%umb%", "%mba%"}, true,
+		},
+		{"n-or-more", "mumb", providers.MatchNOrMoreGram, "search_text LIKE $3", []interface{}{"%mumb%"}, true},
+		{"n-or-more too short", "mu", providers.MatchNOrMoreGram, "", nil, false},
+		{"empty", "", providers.MatchPrefix, "", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := providers.QueryOptions{MatchStrategy: tt.strategy, NGramSize: 3}
+			condition, args, ok := matchCondition(tt.query, options, 3)
+			if condition != tt.condition || !reflect.DeepEqual(args, tt.args) || ok != tt.ok {
+				t.Errorf("matchCondition(%q) = %q, %v, %v; want %q, %v, %v",
+					tt.query, condition, args, ok, tt.condition, tt.args, tt.ok)
+			}
+		})
+	}
+}
+
+func TestNewRejectsInvalidTable(t *testing.T) {
+	if _, err := New(Config{DSN: "postgres://localhost/db", Table: "entries; DROP TABLE users"}); err == nil {
+		t.Error("New() should reject an invalid table name")
+	}
+}
@@ -0,0 +1,29 @@
+package postgres
+
+import (
+	"fmt"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// init registers the PostgreSQL provider. Import this package with a blank identifier
+// to use PostgreSQL as the autocomplete backend:
+//
+//	import _ "github.com/remiges-tech/autocomplete/providers/postgres"
+//
+//nolint:gochecknoinits // init() is the idiomatic pattern for provider registration
+func init() {
+	autocomplete.RegisterProvider("postgres", NewProvider)
+}
+
+// NewProvider creates a new PostgreSQL provider from the given configuration.
+// It implements ProviderFactory and expects config to be of type postgres.Config.
+func NewProvider(config interface{}) (providers.Provider, error) {
+	pgConfig, ok := config.(Config)
+	if !ok {
+		return nil, fmt.Errorf("invalid configuration type for PostgreSQL provider: expected postgres.Config, got %T", config)
+	}
+
+	return New(pgConfig)
+}
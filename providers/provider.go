@@ -3,6 +3,8 @@ package providers
 
 import (
 	"context"
+	"errors"
+	"time"
 )
 
 // MatchStrategy defines how search terms are matched against indexed text.
@@ -21,6 +23,15 @@ const (
 
 	// MatchSubstring matches any substring within the text.
 	MatchSubstring
+
+	// MatchSuffix matches the end of the text. Only used with providers
+	// implementing StrategySupporter.
+	MatchSuffix
+
+	// MatchAnchoredSubstring matches substrings starting at a word start, and
+	// substrings of at least NGramSize bytes within a word (see AnchoredSubstrings).
+	// Only used with providers implementing StrategySupporter.
+	MatchAnchoredSubstring
 )
 
 // IndexOptions contains options for indexing operations.
@@ -32,12 +43,26 @@ type IndexOptions struct {
 	// MatchStrategy determines how the text should be tokenized.
 	MatchStrategy MatchStrategy
 
-	// NGramSize is the n-gram size for MatchNGram and MatchNOrMoreGram strategies.
+	// NGramSize is the n-gram size for MatchNGram and MatchNOrMoreGram strategies,
+	// and the minimum length of in-word matches for MatchAnchoredSubstring.
 	// Ignored for MatchPrefix and MatchSubstring.
 	NGramSize int
 
 	// CaseSensitive determines if the indexed text preserves case.
 	CaseSensitive bool
+
+	// ContentHash fingerprints the entry's text, display, and indexing options.
+	// Providers implementing ChangeDetector store it alongside the entry.
+	ContentHash string
+
+	// MatchStrategies, if non-empty, indexes the entry under each listed strategy,
+	// keeping the tokens of each strategy apart so a query can select one.
+	// MatchStrategy is ignored. Only set for providers implementing MultiStrategyIndexer.
+	MatchStrategies []MatchStrategy
+
+	// Metadata is a JSON payload stored alongside the entry and returned with it
+	// in ProviderResult.Metadata. Only set for providers implementing MetadataSupporter.
+	Metadata string
 }
 
 // QueryOptions contains options for query operations.
@@ -55,7 +80,13 @@ type QueryOptions struct {
 	// IncludeScores determines if result scores should be populated.
 	IncludeScores bool
 
-	// FilterMetadata allows provider-specific filtering (currently unused).
+	// ExplainScores asks providers that can break their scores down to set
+	// ProviderResult.Breakdown.
+	ExplainScores bool
+
+	// FilterMetadata restricts results to entries whose metadata has these
+	// top-level field values (see MatchesMetadata), applied before MaxResults.
+	// Only set for providers implementing MetadataSupporter.
 	FilterMetadata map[string]interface{}
 
 	// MatchStrategy must match the strategy used during indexing.
@@ -63,6 +94,21 @@ type QueryOptions struct {
 
 	// NGramSize must match the size used during indexing.
 	NGramSize int
+
+	// MatchStrategies is set when entries were indexed with IndexOptions.MatchStrategies
+	// and holds the same list. MatchStrategy then selects which strategy's tokens to query.
+	MatchStrategies []MatchStrategy
+
+	// SnapshotID runs the query against a snapshot opened with Snapshotter.OpenSnapshot.
+	// Only set for providers implementing Snapshotter.
+	SnapshotID string
+
+	// SnapshotKeepAlive extends the snapshot's lifetime by this duration.
+	SnapshotKeepAlive time.Duration
+
+	// Offset skips this many results, for paging through a snapshot.
+	// Only set for providers implementing Snapshotter.
+	Offset int
 }
 
 // Provider defines the interface that all autocomplete providers must implement.
@@ -92,6 +138,243 @@ type Provider interface {
 	Close() error
 }
 
+// ChangeDetector is implemented by providers that persist IndexOptions.ContentHash.
+// It lets callers skip rewriting entries whose content has not changed.
+type ChangeDetector interface {
+	// ContentHash returns the hash stored for an entry and whether the entry exists.
+	// Entries indexed without a hash report an empty hash with exists set to true.
+	ContentHash(ctx context.Context, key, id string) (hash string, exists bool, err error)
+}
+
+// EntryChecker is implemented by providers that can tell whether an entry is
+// stored more cheaply than ChangeDetector, which also reads its hash.
+type EntryChecker interface {
+	// Exists reports whether an entry with the given ID is stored.
+	Exists(ctx context.Context, key, id string) (bool, error)
+}
+
+// IndexEntry is a single entry to be written as part of a multi-entry operation.
+type IndexEntry struct {
+	// ID is the unique identifier of the entry.
+	ID string
+
+	// Text is the text to tokenize and match against queries.
+	Text string
+
+	// Display is the text shown in results.
+	Display string
+
+	// Options controls how the entry is indexed.
+	Options IndexOptions
+}
+
+// TransactionalIndexer is implemented by providers that can write several entries
+// atomically, so that a logical record spread over multiple entries is never
+// partially visible to queries.
+type TransactionalIndexer interface {
+	// IndexAtomic writes all entries or none of them. If any entry fails,
+	// the provider must leave previously stored entries as they were.
+	IndexAtomic(ctx context.Context, key string, entries []IndexEntry) error
+}
+
+// BatchIndexer is implemented by providers that can write many entries in a few
+// round trips, such as a Redis pipeline or an Elasticsearch bulk request, for
+// bulk loads. Unlike IndexAtomic, a failed batch may leave some entries written.
+type BatchIndexer interface {
+	// BatchIndex writes all entries, replacing stored entries with the same IDs.
+	BatchIndex(ctx context.Context, key string, entries []IndexEntry) error
+}
+
+// BatchDeleter is implemented by providers that can remove many entries in a
+// few round trips, such as a Redis pipeline or an Elasticsearch bulk request.
+type BatchDeleter interface {
+	// DeleteMany removes the entries with the given IDs. IDs that do not exist
+	// are ignored. A failed call may leave some of the entries removed.
+	DeleteMany(ctx context.Context, key string, ids []string) error
+}
+
+// Snapshotter is implemented by providers that can run queries against a
+// point-in-time view of a namespace, so that paging stays consistent while
+// entries are being indexed.
+type Snapshotter interface {
+	// OpenSnapshot opens a snapshot of the namespace that is retained for at
+	// least keepAlive and returns its identifier.
+	OpenSnapshot(ctx context.Context, key string, keepAlive time.Duration) (string, error)
+
+	// CloseSnapshot releases a snapshot. Closing an expired snapshot succeeds.
+	CloseSnapshot(ctx context.Context, snapshotID string) error
+}
+
+// ErrPagingUnsupported is returned by PagedQuerier.QueryPage for queries the
+// provider cannot resume, e.g. under some match strategies. Callers then page
+// by fetching the results before the page again.
+var ErrPagingUnsupported = errors.New("provider cannot page this query")
+
+// PagedQuerier is implemented by providers that can resume a query where a
+// previous page of its results ended, without fetching those results again.
+type PagedQuerier interface {
+	// QueryPage returns up to options.MaxResults results following the page
+	// that returned cursor, and the cursor of the next page. An empty cursor
+	// starts from the first result; an empty next cursor means there are no
+	// more results. Cursors are only valid for the query, key, and options
+	// they were returned for. Returns ErrPagingUnsupported for queries the
+	// provider cannot page.
+	QueryPage(ctx context.Context, key, query string, options QueryOptions, cursor string) (results []ProviderResult, next string, err error)
+}
+
+// StrategySupporter is implemented by providers that support match strategies
+// beyond MatchPrefix, MatchNGram, MatchNOrMoreGram, and MatchSubstring, which
+// every provider supports.
+type StrategySupporter interface {
+	// SupportsMatchStrategy reports whether entries can be indexed and queried
+	// under the given strategy.
+	SupportsMatchStrategy(strategy MatchStrategy) bool
+}
+
+// MetadataSupporter is implemented by providers that store IndexOptions.Metadata,
+// return it with query results and listed entries, and filter queries by it
+// with QueryOptions.FilterMetadata.
+type MetadataSupporter interface {
+	// SupportsMetadata reports whether metadata payloads are stored.
+	SupportsMetadata() bool
+}
+
+// ScoreUpdater is implemented by providers that can change the score of a
+// stored entry without indexing its text again.
+type ScoreUpdater interface {
+	// UpdateScore sets the score of the entry with the given ID, which then
+	// ranks as if it had been indexed with IndexOptions.Score set to score.
+	// IDs that do not exist are ignored.
+	UpdateScore(ctx context.Context, key, id string, score float64) error
+}
+
+// ScoreIncrementer is implemented by providers that can add to the score of a
+// stored entry atomically, so that concurrent increments all count.
+type ScoreIncrementer interface {
+	// IncrementScore adds delta, which may be negative, to the score of the
+	// entry with the given ID. IDs that do not exist are ignored.
+	IncrementScore(ctx context.Context, key, id string, delta float64) error
+}
+
+// MultiStrategyIndexer is implemented by providers that can index an entry under
+// several match strategies in one namespace (IndexOptions.MatchStrategies), storing
+// its text and display once, and answer queries for any one of those strategies.
+type MultiStrategyIndexer interface {
+	// SupportsMatchStrategies reports whether entries can be indexed under all of
+	// the given strategies at once.
+	SupportsMatchStrategies(strategies []MatchStrategy) bool
+}
+
+// MaintenanceOptions controls a maintenance run.
+type MaintenanceOptions struct {
+	// BatchSize is the number of stored items examined per batch.
+	// Zero lets the provider choose.
+	BatchSize int
+
+	// BatchDelay is the pause between batches, limiting the load a run puts on the backend.
+	BatchDelay time.Duration
+
+	// Progress, if set, is called after each batch with the totals so far.
+	Progress func(stats MaintenanceStats)
+}
+
+// MaintenanceStats reports the work done by a maintenance run.
+type MaintenanceStats struct {
+	// Scanned is the number of stored items examined.
+	Scanned int64
+
+	// Removed is the number of orphaned items removed.
+	Removed int64
+}
+
+// Maintainer is implemented by providers whose storage can accumulate orphaned
+// data, such as tokens left behind when entries are updated.
+type Maintainer interface {
+	// Maintain scans the namespace and removes orphaned data. It stops early,
+	// returning the stats so far, when ctx is cancelled.
+	Maintain(ctx context.Context, key string, options MaintenanceOptions) (MaintenanceStats, error)
+}
+
+// Compactor is implemented by embedded providers whose storage fragments as
+// entries are updated and deleted.
+type Compactor interface {
+	// Compact merges fragmented index data and reclaims the space left by
+	// deleted and replaced entries, in every namespace. It can take as long as
+	// rewriting the index and may hold up writes while it runs.
+	Compact(ctx context.Context) error
+}
+
+// StoredEntry is an entry as stored by a provider.
+type StoredEntry struct {
+	// ID is the unique identifier provided during indexing.
+	ID string
+
+	// Text is the text as given to Index.
+	Text string
+
+	// Display is the display text as given to Index.
+	Display string
+
+	// ContentHash is the content hash recorded at index time, if any.
+	ContentHash string
+
+	// Metadata is the payload given in IndexOptions.Metadata, if any.
+	Metadata string
+
+	// Score is the entry's current score. Providers that do not report scores
+	// leave it 0.
+	Score float64
+}
+
+// EntryGetter is implemented by providers that can look up a stored entry by ID.
+type EntryGetter interface {
+	// GetEntry returns the entry with the given ID and whether it exists,
+	// including its score.
+	GetEntry(ctx context.Context, key, id string) (entry StoredEntry, exists bool, err error)
+}
+
+// EntryCounter is implemented by providers that can count the entries of a
+// namespace without listing them.
+type EntryCounter interface {
+	// CountEntries returns the number of entries stored in the namespace.
+	CountEntries(ctx context.Context, key string) (int64, error)
+}
+
+// IndexStats describes the size of a namespace's index.
+type IndexStats struct {
+	// Entries is the number of entries stored in the namespace.
+	Entries int64
+
+	// Tokens is the number of token postings in the namespace's index,
+	// counting a token once for each entry it was indexed for (or, where the
+	// provider stores positions, once for each position).
+	Tokens int64
+
+	// Bytes approximates the storage used by the namespace's entries and
+	// index. How it is measured differs between providers, so it is meant for
+	// watching growth rather than comparing providers.
+	Bytes int64
+
+	// Details holds provider-specific figures, e.g. the storage layout.
+	Details map[string]string
+}
+
+// StatsReporter is implemented by providers that can describe the size of a
+// namespace's index.
+type StatsReporter interface {
+	// IndexStats returns the size of the namespace's index.
+	IndexStats(ctx context.Context, key string) (IndexStats, error)
+}
+
+// EntryLister is implemented by providers that can enumerate the entries of a namespace.
+type EntryLister interface {
+	// ListEntries returns up to count entries of the namespace starting at cursor,
+	// and the cursor for the next call. An empty cursor starts from the beginning;
+	// an empty next cursor means there are no more entries. Entries written during
+	// a listing may or may not be returned, and may be returned more than once.
+	ListEntries(ctx context.Context, key, cursor string, count int) (entries []StoredEntry, next string, err error)
+}
+
 // ProviderResult represents a single search result from a provider.
 type ProviderResult struct {
 	// ID is the unique identifier provided during indexing.
@@ -102,4 +385,66 @@ type ProviderResult struct {
 
 	// Score indicates relevance (higher is better).
 	Score float64
+
+	// Metadata is the payload given in IndexOptions.Metadata, if any.
+	Metadata string
+
+	// Breakdown holds the components of Score when QueryOptions.ExplainScores
+	// is set and the provider reports them, and is nil otherwise.
+	Breakdown *ScoreBreakdown
+}
+
+// ScoreBreakdown holds the components a provider added up into a result's score.
+type ScoreBreakdown struct {
+	// Entry is the score the entry was indexed with (IndexOptions.Score), as
+	// changed by any ScoreUpdater.UpdateScore since.
+	Entry float64
+
+	// Proximity is the share of the score from how closely the query's tokens
+	// occur together in the entry (see ProximityScore).
+	Proximity float64
+}
+
+// ChangeSet lists the writes made to a namespace since a cursor.
+type ChangeSet struct {
+	// Reset is set when the changes are not relative to the cursor, because it
+	// was empty, was issued before the namespace was cleared, or cannot be
+	// resumed, e.g. after a restart. Entries then lists every entry of the
+	// namespace, and entries not listed no longer exist.
+	Reset bool
+
+	// Entries holds the entries indexed since the cursor, as they are now.
+	Entries []StoredEntry
+
+	// Deleted holds the IDs of the entries deleted since the cursor.
+	Deleted []string
+
+	// Cursor is passed to the next call to list the writes made after this one.
+	Cursor string
+}
+
+// ChangeLister is implemented by providers that record when each entry was last
+// written, so that the writes made since a checkpoint can be listed without
+// reading every entry.
+type ChangeLister interface {
+	// ListChanges returns the writes made to the namespace since cursor. An empty
+	// cursor lists every entry. Each entry appears at most once, as it is now.
+	ListChanges(ctx context.Context, key, cursor string) (ChangeSet, error)
+}
+
+// NamespaceAliaser is implemented by providers that can re-point namespaces,
+// so that a dataset built under a new name can replace the one clients query
+// without them changing configuration.
+type NamespaceAliaser interface {
+	// AliasNamespace makes every operation on the alias act on target instead,
+	// replacing the alias's previous target in a single step. An empty target
+	// removes the alias. It fails if the alias holds entries of its own or
+	// target is itself an alias.
+	AliasNamespace(ctx context.Context, alias, target string) error
+
+	// RenameNamespace moves the entries of namespace from to namespace to in a
+	// single step, replacing the entries to held, and re-points the aliases of
+	// from to to. Renaming a namespace without entries empties to. It fails if
+	// either namespace is an alias.
+	RenameNamespace(ctx context.Context, from, to string) error
 }
@@ -0,0 +1,265 @@
+// Package providertest provides recorded-fixture (VCR-style) testing for
+// providers that talk to their backend over HTTP.
+//
+// A test points its provider at a Server instead of a live backend. By
+// default the Server replays a fixture file: each request the provider makes
+// must match the next recorded request, which makes the fixture a golden file
+// for the provider's query building, and is answered with the recorded
+// response. Setting RecordEnv to the URL of a live backend instead proxies
+// every request to it and rewrites the fixture when the test passes:
+//
+//	AUTOCOMPLETE_RECORD_URL=http://localhost:9200 go test ./providers/elasticsearch -run TestGolden
+//
+// Request headers are forwarded but never recorded, so credentials used while
+// recording stay out of fixtures.
+package providertest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// RecordEnv names the environment variable holding the URL of the live
+// backend to record fixtures from.
+const RecordEnv = "AUTOCOMPLETE_RECORD_URL"
+
+// skippedHeaders are response headers that vary between runs and are not recorded.
+var skippedHeaders = map[string]bool{"Date": true, "Content-Length": true}
+
+// Fixture is the recorded exchange between a provider and its backend.
+type Fixture struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one recorded request and its response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded request. Path includes the query string.
+type Request struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Body
+}
+
+// Response is a recorded response.
+type Response struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body
+}
+
+// Body holds a recorded body: JSON bodies are stored as JSON, so fixtures stay
+// readable and diffable, and any other body (such as newline-delimited bulk
+// requests) as a string.
+type Body struct {
+	JSON json.RawMessage `json:"body,omitempty"`
+	Raw  string          `json:"raw_body,omitempty"`
+}
+
+// newBody stores b as JSON when it is a single JSON value.
+func newBody(b []byte) Body {
+	trimmed := bytes.TrimSpace(b)
+	if len(trimmed) > 0 && json.Valid(trimmed) {
+		return Body{JSON: json.RawMessage(trimmed)}
+	}
+	return Body{Raw: string(b)}
+}
+
+// bytes returns the body's content.
+func (b Body) bytes() []byte {
+	if b.JSON != nil {
+		return b.JSON
+	}
+	return []byte(b.Raw)
+}
+
+// String returns the body on one line, for error messages.
+func (b Body) String() string {
+	var buf bytes.Buffer
+	if b.JSON != nil && json.Compact(&buf, b.JSON) == nil {
+		return buf.String()
+	}
+	return b.Raw
+}
+
+// equal reports whether b holds the same content as other, comparing JSON
+// bodies by value so that key order and whitespace do not matter.
+func (b Body) equal(other Body) bool {
+	if b.JSON == nil || other.JSON == nil {
+		return b.Raw == other.Raw && (b.JSON == nil) == (other.JSON == nil)
+	}
+	var x, y interface{}
+	if json.Unmarshal(b.JSON, &x) != nil || json.Unmarshal(other.JSON, &y) != nil {
+		return false
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+// Server is an HTTP server standing in for a provider's backend, replaying or
+// recording a fixture.
+type Server struct {
+	// URL is the base URL of the server, to configure the provider with.
+	URL string
+
+	t        testing.TB
+	path     string
+	upstream string
+
+	mu      sync.Mutex
+	fixture Fixture
+	next    int
+}
+
+// NewServer starts a Server for the fixture at path. It replays the fixture,
+// or records it when RecordEnv is set. The server is closed when the test
+// ends; in replay mode the test then fails if recorded requests were not made.
+func NewServer(t testing.TB, path string) *Server {
+	t.Helper()
+	s := &Server{t: t, path: path, upstream: strings.TrimSuffix(os.Getenv(RecordEnv), "/")}
+
+	if s.upstream == "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read fixture (record it by setting %s): %v", RecordEnv, err)
+		}
+		if err := json.Unmarshal(data, &s.fixture); err != nil {
+			t.Fatalf("failed to parse fixture %s: %v", path, err)
+		}
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = server.URL
+	t.Cleanup(func() {
+		server.Close()
+		s.finish()
+	})
+	return s
+}
+
+// Recording reports whether the server is recording from a live backend.
+func (s *Server) Recording() bool {
+	return s.upstream != ""
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req := Request{Method: r.Method, Path: r.URL.RequestURI(), Body: newBody(body)}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var resp Response
+	if s.Recording() {
+		resp, err = s.forward(r, body)
+		if err != nil {
+			s.t.Errorf("failed to forward %s %s: %v", req.Method, req.Path, err)
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		s.fixture.Interactions = append(s.fixture.Interactions, Interaction{Request: req, Response: resp})
+	} else {
+		resp, err = s.replay(req)
+		if err != nil {
+			s.t.Error(err)
+			http.Error(w, err.Error(), http.StatusNotImplemented)
+			return
+		}
+	}
+
+	for name, values := range resp.Header {
+		w.Header()[name] = values
+	}
+	w.WriteHeader(resp.Status)
+	_, _ = w.Write(resp.bytes())
+}
+
+// replay matches req against the next recorded request and returns its response.
+func (s *Server) replay(req Request) (Response, error) {
+	if s.next == len(s.fixture.Interactions) {
+		return Response{}, fmt.Errorf("unexpected request %d: %s %s %s", s.next, req.Method, req.Path, req.Body)
+	}
+	want := s.fixture.Interactions[s.next]
+	s.next++
+
+	if req.Method != want.Request.Method || req.Path != want.Request.Path || !req.Body.equal(want.Request.Body) {
+		return Response{}, fmt.Errorf("request %d does not match fixture %s\ngot:  %s %s %s\nwant: %s %s %s",
+			s.next-1, s.path, req.Method, req.Path, req.Body, want.Request.Method, want.Request.Path, want.Request.Body)
+	}
+	return want.Response, nil
+}
+
+// forward sends the request to the live backend and returns its response.
+func (s *Server) forward(r *http.Request, body []byte) (Response, error) {
+	out, err := http.NewRequestWithContext(r.Context(), r.Method, s.upstream+r.URL.RequestURI(), bytes.NewReader(body))
+	if err != nil {
+		return Response{}, err
+	}
+	out.Header = r.Header.Clone()
+	// Leave compression to the transport, so recorded bodies are decoded
+	out.Header.Del("Accept-Encoding")
+
+	res, err := http.DefaultClient.Do(out)
+	if err != nil {
+		return Response{}, err
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return Response{}, err
+	}
+	header := http.Header{}
+	for name, values := range res.Header {
+		if !skippedHeaders[name] {
+			header[name] = values
+		}
+	}
+	return Response{Status: res.StatusCode, Header: header, Body: newBody(data)}, nil
+}
+
+// finish writes a recorded fixture, or checks that every recorded request was replayed.
+func (s *Server) finish() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.Recording() {
+		if remaining := len(s.fixture.Interactions) - s.next; remaining > 0 && !s.t.Failed() {
+			s.t.Errorf("%d recorded request(s) in %s were not made, starting with %s %s",
+				remaining, s.path, s.fixture.Interactions[s.next].Request.Method, s.fixture.Interactions[s.next].Request.Path)
+		}
+		return
+	}
+	if s.t.Failed() {
+		return
+	}
+
+	data, err := json.MarshalIndent(s.fixture, "", "  ")
+	if err != nil {
+		s.t.Errorf("failed to encode fixture: %v", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		s.t.Errorf("failed to create fixture directory: %v", err)
+		return
+	}
+	if err := os.WriteFile(s.path, append(data, '\n'), 0o644); err != nil {
+		s.t.Errorf("failed to write fixture: %v", err)
+	}
+}
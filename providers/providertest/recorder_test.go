@@ -0,0 +1,140 @@
+package providertest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingT captures the failures a Server reports, so that tests can
+// exercise mismatches without failing themselves.
+type recordingT struct {
+	testing.TB
+	mu       sync.Mutex
+	errors   []string
+	cleanups []func()
+}
+
+func (r *recordingT) Errorf(format string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingT) Error(args ...interface{}) {
+	r.Errorf("%s", fmt.Sprint(args...))
+}
+
+func (r *recordingT) Failed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.errors) > 0
+}
+
+func (r *recordingT) Cleanup(f func()) {
+	r.cleanups = append(r.cleanups, f)
+}
+
+func (r *recordingT) finish() {
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		r.cleanups[i]()
+	}
+}
+
+func post(t *testing.T, url, body string) (int, string) {
+	t.Helper()
+	res, err := http.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = res.Body.Close() }()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.StatusCode, string(data)
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Backend", "live")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprintf(w, `{"path": %q, "echo": %s}`, r.URL.RequestURI(), body)
+	}))
+	defer backend.Close()
+	path := filepath.Join(t.TempDir(), "fixture.json")
+
+	recorder := &recordingT{TB: t}
+	t.Setenv(RecordEnv, backend.URL)
+	server := NewServer(recorder, path)
+	if !server.Recording() {
+		t.Fatal("Recording() = false with RecordEnv set")
+	}
+	status, recorded := post(t, server.URL+"/search?size=2", `{"query": "mum"}`)
+	if status != http.StatusCreated {
+		t.Fatalf("recording status = %d", status)
+	}
+	recorder.finish()
+	if recorder.Failed() {
+		t.Fatalf("recording failed: %v", recorder.errors)
+	}
+
+	replayer := &recordingT{TB: t}
+	t.Setenv(RecordEnv, "")
+	server = NewServer(replayer, path)
+	backend.Close()
+
+	// The same request with different formatting matches the recording
+	status, replayed := post(t, server.URL+"/search?size=2", `{ "query" : "mum" }`)
+	if status != http.StatusCreated || !(Body{JSON: []byte(replayed)}).equal(Body{JSON: []byte(recorded)}) {
+		t.Errorf("replayed %d %s, want %d %s", status, replayed, http.StatusCreated, recorded)
+	}
+	replayer.finish()
+	if replayer.Failed() {
+		t.Errorf("replay failed: %v", replayer.errors)
+	}
+}
+
+func TestReplayReportsMismatches(t *testing.T) {
+	t.Setenv(RecordEnv, "")
+	path := filepath.Join("testdata", "fixture.json")
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		errors int
+	}{
+		{"matching", "/search?size=2", `{"query": "mum"}`, 0},
+		{"different body", "/search?size=2", `{"query": "pune"}`, 1},
+		{"different path", "/search?size=3", `{"query": "mum"}`, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replayer := &recordingT{TB: t}
+			server := NewServer(replayer, path)
+			status, _ := post(t, server.URL+tt.path, tt.body)
+			replayer.finish()
+			if len(replayer.errors) != tt.errors {
+				t.Errorf("errors = %v, want %d", replayer.errors, tt.errors)
+			}
+			if tt.errors > 0 && status != http.StatusNotImplemented {
+				t.Errorf("status = %d, want %d", status, http.StatusNotImplemented)
+			}
+		})
+	}
+
+	t.Run("unmade requests", func(t *testing.T) {
+		replayer := &recordingT{TB: t}
+		NewServer(replayer, path)
+		replayer.finish()
+		if len(replayer.errors) != 1 {
+			t.Errorf("errors = %v, want 1", replayer.errors)
+		}
+	})
+}
@@ -0,0 +1,53 @@
+package providers
+
+// ProximityScore scores how closely the tokens of a query occur together in
+// an entry's text. positions[i] lists the byte offsets at which the query's
+// i-th token occurs in the text, and offsets[i] is that token's offset in the
+// query. The score is 1 when every token occurs at its query offset from a
+// common start, as in an exact phrase match, and 1/(1+d) otherwise, where d is
+// the least total distance of the tokens from their query offsets over every
+// start at which one of them occurs. It is 0 if some token does not occur.
+func ProximityScore(positions [][]int, offsets []int) float64 {
+	if len(positions) == 0 || len(positions) != len(offsets) {
+		return 0
+	}
+	for _, occurrences := range positions {
+		if len(occurrences) == 0 {
+			return 0
+		}
+	}
+
+	best := -1
+	for i, occurrences := range positions {
+		for _, position := range occurrences {
+			start := position - offsets[i]
+			distance := 0
+			for j, others := range positions {
+				distance += nearestDistance(others, start+offsets[j])
+				if best >= 0 && distance >= best {
+					break
+				}
+			}
+			if best < 0 || distance < best {
+				best = distance
+			}
+		}
+	}
+	return 1 / float64(1+best)
+}
+
+// nearestDistance returns the distance from target to the nearest of positions,
+// which must not be empty.
+func nearestDistance(positions []int, target int) int {
+	nearest := -1
+	for _, position := range positions {
+		distance := position - target
+		if distance < 0 {
+			distance = -distance
+		}
+		if nearest < 0 || distance < nearest {
+			nearest = distance
+		}
+	}
+	return nearest
+}
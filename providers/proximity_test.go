@@ -0,0 +1,24 @@
+package providers
+
+import "testing"
+
+func TestProximityScore(t *testing.T) {
+	offsets := []int{0, 1, 2}
+	tests := []struct {
+		name      string
+		positions [][]int
+		want      float64
+	}{
+		{"phrase", [][]int{{4}, {5}, {6}}, 1},
+		{"phrase among other occurrences", [][]int{{0, 9}, {3, 10}, {11}}, 1},
+		{"one token displaced", [][]int{{0}, {1}, {5}}, 1.0 / 4},
+		{"tokens apart", [][]int{{0}, {6}, {12}}, 1.0 / 11},
+		{"token missing", [][]int{{0}, {}, {2}}, 0},
+		{"token count differs from offsets", [][]int{{0}}, 0},
+	}
+	for _, tt := range tests {
+		if got := ProximityScore(tt.positions, offsets); got != tt.want {
+			t.Errorf("%s: ProximityScore(%v) = %v, want %v", tt.name, tt.positions, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+// Package readwrite implements the autocomplete Provider interface over
+// separate write and read backends, such as a Redis primary that a batch job
+// indexes into and its read replicas that serve queries.
+package readwrite
+
+import "github.com/remiges-tech/autocomplete/providers"
+
+// Config holds the providers of a read/write-split provider.
+type Config struct {
+	// Writer is the provider Index, Delete, and DeleteAll calls go to.
+	// The read/write-split provider takes ownership of it and closes it on Close.
+	Writer providers.Provider
+
+	// Readers are the providers queries go to, in turn. They must see the
+	// writes made to Writer, e.g. as replicas of the same database.
+	// The read/write-split provider takes ownership of them and closes them on Close.
+	Readers []providers.Provider
+}
@@ -0,0 +1,72 @@
+package readwrite
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// Provider implements the autocomplete Provider interface by sending writes to
+// one provider and spreading queries over others round-robin. Writes become
+// visible to queries once the readers have caught up with the writer.
+// All methods are safe for concurrent use.
+type Provider struct {
+	writer  providers.Provider
+	readers []providers.Provider
+
+	// next counts queries, selecting the reader of the next one.
+	next atomic.Uint64
+}
+
+// New creates a new read/write-split provider over config.Writer and config.Readers.
+func New(config Config) (*Provider, error) {
+	if config.Writer == nil {
+		return nil, errors.New("writer provider is required")
+	}
+	if len(config.Readers) == 0 {
+		return nil, errors.New("at least one reader provider is required")
+	}
+	if slices.Contains(config.Readers, nil) {
+		return nil, errors.New("reader providers must not be nil")
+	}
+
+	return &Provider{writer: config.Writer, readers: slices.Clone(config.Readers)}, nil
+}
+
+// Index adds or updates an entry through the writer.
+func (p *Provider) Index(ctx context.Context, key, id, text, display string, options providers.IndexOptions) error {
+	return p.writer.Index(ctx, key, id, text, display, options)
+}
+
+// Query searches the next reader in turn.
+func (p *Provider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	return p.reader().Query(ctx, key, query, options)
+}
+
+// Delete removes an entry through the writer.
+func (p *Provider) Delete(ctx context.Context, key, id string) error {
+	return p.writer.Delete(ctx, key, id)
+}
+
+// DeleteAll removes all entries for a given key through the writer.
+func (p *Provider) DeleteAll(ctx context.Context, key string) error {
+	return p.writer.DeleteAll(ctx, key)
+}
+
+// Close closes the writer and every reader.
+func (p *Provider) Close() error {
+	errs := []error{p.writer.Close()}
+	for _, reader := range p.readers {
+		errs = append(errs, reader.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// reader returns the reader of the next query.
+func (p *Provider) reader() providers.Provider {
+	n := p.next.Add(1) - 1
+	return p.readers[n%uint64(len(p.readers))]
+}
@@ -0,0 +1,102 @@
+package readwrite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers"
+	"github.com/remiges-tech/autocomplete/providers/memory"
+	"github.com/remiges-tech/autocomplete/stress"
+)
+
+const testKey = "test"
+
+// replica serves queries from a shared in-memory provider, counting them,
+// and fails the test if it is written to.
+type replica struct {
+	*memory.Provider
+	t       *testing.T
+	queries int
+}
+
+func (r *replica) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	r.queries++
+	return r.Provider.Query(ctx, key, query, options)
+}
+
+func (r *replica) Index(ctx context.Context, key, id, text, display string, options providers.IndexOptions) error {
+	r.t.Error("Index() reached a reader")
+	return nil
+}
+
+func (r *replica) Delete(ctx context.Context, key, id string) error {
+	r.t.Error("Delete() reached a reader")
+	return nil
+}
+
+func TestReadWriteProvider_RoutesWritesAndQueries(t *testing.T) {
+	primary, _ := memory.New(memory.Config{})
+	readers := []*replica{{Provider: primary, t: t}, {Provider: primary, t: t}}
+	provider, err := New(Config{Writer: primary, Readers: []providers.Provider{readers[0], readers[1]}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx := context.Background()
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchPrefix}
+	if err := provider.Index(ctx, testKey, "1", "Mumbai", "Mumbai", options); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if err := provider.Index(ctx, testKey, "2", "Pune", "Pune", options); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if err := provider.Delete(ctx, testKey, "2"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	queryOptions := providers.QueryOptions{MaxResults: 10, MatchStrategy: providers.MatchPrefix}
+	for i := 0; i < 4; i++ {
+		results, err := provider.Query(ctx, testKey, "mum", queryOptions)
+		if err != nil || len(results) != 1 || results[0].ID != "1" {
+			t.Fatalf("Query() = %v, %v; want entry 1", results, err)
+		}
+	}
+	if readers[0].queries != 2 || readers[1].queries != 2 {
+		t.Errorf("reader queries = %d, %d; want queries spread round-robin", readers[0].queries, readers[1].queries)
+	}
+}
+
+func TestNewRejectsInvalidConfig(t *testing.T) {
+	writer, _ := memory.New(memory.Config{})
+	if _, err := New(Config{Writer: writer}); err == nil {
+		t.Error("New() should require a reader")
+	}
+	if _, err := New(Config{Writer: writer, Readers: []providers.Provider{nil}}); err == nil {
+		t.Error("New() should reject a nil reader")
+	}
+	if _, err := New(Config{Readers: []providers.Provider{writer}}); err == nil {
+		t.Error("New() should require a writer")
+	}
+}
+
+func TestReadWriteProvider_Stress(t *testing.T) {
+	for _, strategy := range []autocomplete.MatchStrategy{autocomplete.MatchPrefix, autocomplete.MatchNGram, autocomplete.MatchSubstring} {
+		t.Run(strategy.String(), func(t *testing.T) {
+			options := autocomplete.DefaultOptions()
+			options.MatchStrategy = strategy
+			// The same provider reads and writes, as a replica without lag would
+			primary, _ := memory.New(memory.Config{})
+			config := Config{Writer: primary, Readers: []providers.Provider{primary, primary}}
+			ac, err := autocomplete.New("readwrite", autocomplete.NewConfigWithOptions(config, options))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			defer func() { _ = ac.Close() }()
+
+			if err := stress.Run(context.Background(), ac, stress.Config{}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package readwrite
+
+import (
+	"fmt"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// init registers the read/write-split provider. Import this package with a blank identifier
+// to route writes and queries to separate providers:
+//
+//	import _ "github.com/remiges-tech/autocomplete/providers/readwrite"
+//
+//nolint:gochecknoinits // init() is the idiomatic pattern for provider registration
+func init() {
+	autocomplete.RegisterProvider("readwrite", NewProvider)
+}
+
+// NewProvider creates a new read/write-split provider from the given configuration.
+// It implements ProviderFactory and expects config to be of type readwrite.Config.
+func NewProvider(config interface{}) (providers.Provider, error) {
+	readwriteConfig, ok := config.(Config)
+	if !ok {
+		return nil, fmt.Errorf("invalid configuration type for read/write-split provider: expected readwrite.Config, got %T", config)
+	}
+
+	return New(readwriteConfig)
+}
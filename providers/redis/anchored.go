@@ -0,0 +1,95 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// inWordTokenMarker starts the in-word tokens of MatchAnchoredSubstring in
+// LayoutLexicographic. Each word contributes one token per position inside it,
+// holding the rest of the word, and each word start one token holding the rest
+// of the text; a ZRANGEBYLEX prefix scan over these finds every anchored
+// substring. The marker keeps in-word tokens apart so that queries shorter
+// than the minimum in-word length skip them. Strategy tags are digits, so it
+// cannot be mistaken for one.
+const inWordTokenMarker = strategyTagMarker + "<"
+
+// addAnchoredTokenCommands queues the sorted set members of an entry indexed
+// under MatchAnchoredSubstring, prefixed with tag
+func addAnchoredTokenCommands(
+	pipe redis.Pipeliner, ctx context.Context, key, id, text, tag string, options providers.IndexOptions,
+) {
+	textToIndex := providers.SearchText(text, options.CaseSensitive)
+	n := getNGramSizeOrDefault(options.NGramSize)
+	for _, word := range providers.AnchoredWords(textToIndex) {
+		pipe.ZAdd(ctx, prefixSet+key, &redis.Z{
+			Score:  lexMemberScore,
+			Member: createPositionalMember(tag+textToIndex[word.Start:], id, word.Start),
+		})
+		for start := word.Start + 1; start <= word.End-n; start++ {
+			pipe.ZAdd(ctx, prefixSet+key, &redis.Z{
+				Score:  lexMemberScore,
+				Member: createPositionalMember(tag+inWordTokenMarker+textToIndex[start:word.End], id, start),
+			})
+		}
+	}
+}
+
+// removeAnchoredMembers removes the in-word MatchAnchoredSubstring tokens of
+// text whatever the minimum length they were indexed with; its word start
+// tokens are removed with the positional members
+func removeAnchoredMembers(pipe redis.Pipeliner, ctx context.Context, key, tag, text, id string) {
+	for _, word := range providers.AnchoredWords(text) {
+		for start := word.Start + 1; start < word.End; start++ {
+			pipe.ZRem(ctx, key, createPositionalMember(tag+inWordTokenMarker+text[start:word.End], id, start))
+		}
+	}
+}
+
+// inWordOfToken returns the text of a stored in-word MatchAnchoredSubstring
+// token and whether the token is one, with or without a strategy tag
+func inWordOfToken(tok string) (string, bool) {
+	if !strings.HasPrefix(tok, inWordTokenMarker) {
+		tok = stripStrategyTag(tok)
+	}
+	return strings.CutPrefix(tok, inWordTokenMarker)
+}
+
+// queryAnchored answers a MatchAnchoredSubstring query in LayoutLexicographic,
+// scanning the word start tokens and, for queries at least the minimum in-word
+// length long, the in-word tokens. Word start matches rank first
+//
+//nolint:gocritic // hugeParam: options is passed by value to match the Provider interface
+func (p *Provider) queryAnchored(
+	ctx context.Context, key, searchQuery string, options providers.QueryOptions, stats *QueryStats,
+) ([]providers.ProviderResult, error) {
+	tags := []string{queryTag(options)}
+	if len(searchQuery) >= getNGramSizeOrDefault(options.NGramSize) {
+		tags = append(tags, queryTag(options)+inWordTokenMarker)
+	}
+
+	pipe := p.client.Pipeline()
+	cmds := make([]*redis.StringSliceCmd, len(tags))
+	for i, tag := range tags {
+		cmds[i] = pipe.ZRangeByLex(ctx, prefixSet+key, &redis.ZRangeBy{
+			Min:   createLexicographicStartKey(tag + searchQuery),
+			Max:   createLexicographicEndKey(tag + searchQuery),
+			Count: int64(options.MaxResults * resultMultiplierForDuplicates),
+		})
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to query autocomplete: %w", err)
+	}
+
+	var members []string
+	for _, cmd := range cmds {
+		members = append(members, cmd.Val()...)
+	}
+	stats.add(searchQuery, len(extractIDsFromResults(members, minMemberPartsForPositionalID)))
+	return p.rankMembers(ctx, key, members, options)
+}
@@ -0,0 +1,85 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// NewWithClient creates a Redis provider that uses an existing client, e.g. one
+// shared with the rest of the application and instrumented there, instead of
+// opening a connection pool of its own. The connection fields of config (Addr,
+// ClusterAddrs, MasterName, credentials, TLS and timeouts) are ignored; the
+// other fields apply as in New. A *redis.ClusterClient enables the hash-tagged
+// key names described at Config.ClusterAddrs. Close leaves the client open.
+func NewWithClient(client redis.UniversalClient, config Config) (*Provider, error) {
+	if client == nil {
+		return nil, errors.New("redis client is nil")
+	}
+	_, cluster := client.(*redis.ClusterClient)
+	return newProvider(client, config, cluster)
+}
+
+// newProvider creates a provider on top of client after verifying connectivity
+// with a PING command. hashTags selects the key names for Redis Cluster.
+func newProvider(client redis.UniversalClient, config Config, hashTags bool) (*Provider, error) {
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+
+	provider := &Provider{
+		client:   client,
+		hashTags: hashTags,
+		layout:   config.Layout,
+		codec:    newValueCodec(client, config.Compression, config.CompressionThreshold),
+
+		onQueryStats: config.OnQueryStats,
+	}
+	if config.RankingScript != "" {
+		provider.rankingScript = redis.NewScript(config.RankingScript)
+	}
+	return provider, nil
+}
+
+// newClient creates the client for the deployment described by config: a
+// Redis Cluster, a Sentinel-monitored master, or a single node.
+func newClient(config Config) (redis.UniversalClient, error) {
+	options := &redis.UniversalOptions{
+		Username:     config.Username,
+		Password:     config.Password, // pragma: allowlist secret
+		DB:           config.DB,
+		TLSConfig:    config.TLSConfig,
+		DialTimeout:  config.DialTimeout,
+		ReadTimeout:  config.ReadTimeout,
+		WriteTimeout: config.WriteTimeout,
+	}
+	if config.ClientName != "" {
+		options.OnConnect = func(ctx context.Context, cn *redis.Conn) error {
+			return cn.ClientSetName(ctx, config.ClientName).Err()
+		}
+	}
+
+	switch {
+	case len(config.ClusterAddrs) > 0 && config.MasterName != "":
+		return nil, errors.New("redis config cannot set both ClusterAddrs and MasterName")
+	case len(config.ClusterAddrs) > 0:
+		if config.DB != 0 {
+			return nil, fmt.Errorf("redis cluster only supports DB 0, got DB %d", config.DB)
+		}
+		options.Addrs = config.ClusterAddrs
+		return redis.NewClusterClient(options.Cluster()), nil
+	case config.MasterName != "":
+		if len(config.SentinelAddrs) == 0 {
+			return nil, errors.New("redis config sets MasterName without SentinelAddrs")
+		}
+		options.Addrs = config.SentinelAddrs
+		options.MasterName = config.MasterName
+		options.SentinelPassword = config.SentinelPassword // pragma: allowlist secret
+		return redis.NewFailoverClient(options.Failover()), nil
+	default:
+		options.Addrs = []string{config.Addr}
+		return redis.NewClient(options.Simple()), nil
+	}
+}
@@ -0,0 +1,351 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/klauspost/compress/dict"
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression selects how large stored values are compressed.
+type Compression int
+
+const (
+	// CompressionNone stores values as plain strings.
+	CompressionNone Compression = iota
+
+	// CompressionSnappy compresses values with Snappy: fast, moderate ratio.
+	CompressionSnappy
+
+	// CompressionZstd compresses values with Zstandard: slower, better ratio.
+	CompressionZstd
+
+	// CompressionZstdDict compresses values with Zstandard using a dictionary
+	// trained per namespace by Provider.TrainDictionary. Datasets with highly
+	// repetitive display templates shrink much further than with plain Zstd.
+	// Until a namespace has a dictionary, values are compressed with plain Zstd.
+	CompressionZstdDict
+)
+
+const (
+	// defaultCompressionThreshold is the minimum value size in bytes that gets compressed.
+	defaultCompressionThreshold = 128
+
+	// compressedValueMarker starts every compressed value. Plain display text never
+	// starts with a NUL byte, so values written before compression was enabled
+	// remain readable.
+	compressedValueMarker = '\x00'
+
+	// compressedHeaderSize is the marker byte plus the algorithm byte.
+	compressedHeaderSize = 2
+
+	// prefixDictionary is the Redis key prefix for hash maps storing dictionary ID → dictionary.
+	prefixDictionary = "ac:dict:"
+
+	// currentDictionaryField is the dictionary hash field naming the dictionary used for new values.
+	currentDictionaryField = "current"
+
+	// dictionaryRefreshInterval is how often a namespace's dictionaries are reloaded,
+	// so that dictionaries trained by another instance are picked up.
+	dictionaryRefreshInterval = time.Minute
+
+	// maxDictionarySize is the maximum size of a trained dictionary in bytes.
+	maxDictionarySize = 64 << 10
+
+	// dictionaryHashBytes is the minimum match length indexed while training.
+	dictionaryHashBytes = 6
+
+	// dictionarySampleScanCount is the HSCAN batch size used when sampling displays.
+	dictionarySampleScanCount = 500
+)
+
+// ErrNotEnoughSamples is returned by TrainDictionary when the namespace has too
+// few display values to train a useful dictionary.
+var ErrNotEnoughSamples = errors.New("not enough display values to train a dictionary")
+
+// minDictionarySamples is the minimum number of display values needed for training.
+const minDictionarySamples = 8
+
+// valueCodec transparently compresses and decompresses stored values.
+// Decoding works regardless of the configured compression, so the setting can
+// be changed without reindexing.
+type valueCodec struct {
+	client      redis.Cmdable
+	compression Compression
+	threshold   int
+
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+	zstdErr     error
+
+	dictMu       sync.Mutex
+	dictionaries map[string]*dictionarySet
+}
+
+// dictionarySet holds the zstd coders for a namespace's trained dictionaries.
+type dictionarySet struct {
+	encoder  *zstd.Encoder
+	decoder  *zstd.Decoder
+	loadedAt time.Time
+}
+
+// newValueCodec creates a codec for the given compression settings.
+// The client is used to load per-namespace dictionaries.
+func newValueCodec(client redis.Cmdable, compression Compression, threshold int) *valueCodec {
+	if threshold <= 0 {
+		threshold = defaultCompressionThreshold
+	}
+	return &valueCodec{
+		client:       client,
+		compression:  compression,
+		threshold:    threshold,
+		dictionaries: make(map[string]*dictionarySet),
+	}
+}
+
+// encode compresses a value if compression is enabled and the value is large
+// enough for it to pay off. Values that do not shrink are stored as is.
+func (c *valueCodec) encode(ctx context.Context, key, value string) (string, error) {
+	if c.compression == CompressionNone || len(value) < c.threshold {
+		return value, nil
+	}
+
+	algorithm := c.compression
+	var compressed []byte
+	switch c.compression {
+	case CompressionSnappy:
+		compressed = s2.EncodeSnappy(nil, []byte(value))
+	case CompressionZstd, CompressionZstdDict:
+		encoder, err := c.encoderFor(ctx, key)
+		if err != nil {
+			return "", err
+		}
+		if encoder == c.zstdEncoder {
+			algorithm = CompressionZstd
+		}
+		compressed = encoder.EncodeAll([]byte(value), nil)
+	default:
+		return "", fmt.Errorf("unknown compression %d", c.compression)
+	}
+
+	if len(compressed)+compressedHeaderSize >= len(value) {
+		return value, nil
+	}
+	return string([]byte{compressedValueMarker, byte(algorithm)}) + string(compressed), nil
+}
+
+// decode returns the original value, decompressing it if it carries the compressed marker.
+func (c *valueCodec) decode(ctx context.Context, key, value string) (string, error) {
+	if len(value) < compressedHeaderSize || value[0] != compressedValueMarker {
+		return value, nil
+	}
+
+	payload := []byte(value[compressedHeaderSize:])
+	var decoded []byte
+	var err error
+	switch Compression(value[1]) {
+	case CompressionSnappy:
+		decoded, err = s2.Decode(nil, payload)
+	case CompressionZstd:
+		if err = c.initZstd(); err == nil {
+			decoded, err = c.zstdDecoder.DecodeAll(payload, nil)
+		}
+	case CompressionZstdDict:
+		decoded, err = c.decodeWithDictionary(ctx, key, payload)
+	default:
+		return "", fmt.Errorf("unknown compression %d in stored value", value[1])
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to decompress value: %w", err)
+	}
+	return string(decoded), nil
+}
+
+// initZstd lazily creates the dictionary-less zstd encoder and decoder,
+// which are safe for concurrent use.
+func (c *valueCodec) initZstd() error {
+	c.zstdOnce.Do(func() {
+		c.zstdEncoder, c.zstdErr = zstd.NewWriter(nil)
+		if c.zstdErr != nil {
+			return
+		}
+		c.zstdDecoder, c.zstdErr = zstd.NewReader(nil)
+	})
+	return c.zstdErr
+}
+
+// encoderFor returns the zstd encoder for a namespace: its dictionary encoder when
+// dictionary compression is enabled and a dictionary exists, the plain one otherwise.
+func (c *valueCodec) encoderFor(ctx context.Context, key string) (*zstd.Encoder, error) {
+	if err := c.initZstd(); err != nil {
+		return nil, err
+	}
+	if c.compression != CompressionZstdDict {
+		return c.zstdEncoder, nil
+	}
+
+	set, err := c.dictionarySet(ctx, key, false)
+	if err != nil {
+		return nil, err
+	}
+	if set.encoder == nil {
+		return c.zstdEncoder, nil
+	}
+	return set.encoder, nil
+}
+
+// decodeWithDictionary decodes a dictionary-compressed payload, reloading the
+// namespace's dictionaries once if the payload references an unknown one.
+func (c *valueCodec) decodeWithDictionary(ctx context.Context, key string, payload []byte) ([]byte, error) {
+	set, err := c.dictionarySet(ctx, key, false)
+	if err != nil {
+		return nil, err
+	}
+	if set.decoder != nil {
+		decoded, decodeErr := set.decoder.DecodeAll(payload, nil)
+		if !errors.Is(decodeErr, zstd.ErrUnknownDictionary) {
+			return decoded, decodeErr
+		}
+	}
+
+	set, err = c.dictionarySet(ctx, key, true)
+	if err != nil {
+		return nil, err
+	}
+	if set.decoder == nil {
+		return nil, zstd.ErrUnknownDictionary
+	}
+	return set.decoder.DecodeAll(payload, nil)
+}
+
+// dictionarySet returns the cached coders for a namespace, loading them from
+// Redis when missing, stale, or when reload is requested.
+func (c *valueCodec) dictionarySet(ctx context.Context, key string, reload bool) (*dictionarySet, error) {
+	c.dictMu.Lock()
+	defer c.dictMu.Unlock()
+
+	set, cached := c.dictionaries[key]
+	if cached && !reload && time.Since(set.loadedAt) < dictionaryRefreshInterval {
+		return set, nil
+	}
+
+	stored, err := c.client.HGetAll(ctx, prefixDictionary+key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load compression dictionaries: %w", err)
+	}
+	set, err = newDictionarySet(stored)
+	if err != nil {
+		return nil, err
+	}
+	if cached {
+		c.dictionaries[key].close()
+	}
+	c.dictionaries[key] = set
+	return set, nil
+}
+
+// newDictionarySet builds coders from a dictionary hash as stored in Redis.
+func newDictionarySet(stored map[string]string) (*dictionarySet, error) {
+	set := &dictionarySet{loadedAt: time.Now()}
+	current := stored[currentDictionaryField]
+	if current == "" {
+		return set, nil
+	}
+
+	var all [][]byte
+	for field, value := range stored {
+		if field != currentDictionaryField {
+			all = append(all, []byte(value))
+		}
+	}
+
+	var err error
+	if set.encoder, err = zstd.NewWriter(nil, zstd.WithEncoderDict([]byte(stored[current]))); err != nil {
+		return nil, fmt.Errorf("failed to load compression dictionary %s: %w", current, err)
+	}
+	if set.decoder, err = zstd.NewReader(nil, zstd.WithDecoderDicts(all...)); err != nil {
+		return nil, fmt.Errorf("failed to load compression dictionaries: %w", err)
+	}
+	return set, nil
+}
+
+// close releases the resources held by the set's coders.
+func (s *dictionarySet) close() {
+	if s.encoder != nil {
+		_ = s.encoder.Close()
+	}
+	if s.decoder != nil {
+		s.decoder.Close()
+	}
+}
+
+// TrainDictionary builds a Zstandard dictionary from up to sampleSize display
+// values of the namespace and makes it the dictionary for newly written values.
+// Previous dictionaries are kept so existing values stay readable; values are
+// only recompressed when reindexed. Other provider instances pick up the new
+// dictionary within a minute. Dictionaries survive DeleteAll so a namespace can
+// be repopulated with the same dictionary. Only used with CompressionZstdDict.
+func (p *Provider) TrainDictionary(ctx context.Context, key string, sampleSize int) error {
+	key = p.namespace(key)
+	samples, err := p.sampleDisplays(ctx, key, sampleSize)
+	if err != nil {
+		return err
+	}
+	if len(samples) < minDictionarySamples {
+		return ErrNotEnoughSamples
+	}
+
+	trained, err := dict.BuildZstdDict(samples, dict.Options{
+		MaxDictSize: maxDictionarySize,
+		HashBytes:   dictionaryHashBytes,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to train dictionary: %w", err)
+	}
+	info, err := zstd.InspectDictionary(trained)
+	if err != nil {
+		return fmt.Errorf("failed to inspect trained dictionary: %w", err)
+	}
+
+	id := strconv.FormatUint(uint64(info.ID()), 10)
+	err = p.client.HSet(ctx, prefixDictionary+key, id, trained, currentDictionaryField, id).Err()
+	if err != nil {
+		return fmt.Errorf("failed to store dictionary: %w", err)
+	}
+
+	_, err = p.codec.dictionarySet(ctx, key, true)
+	return err
+}
+
+// sampleDisplays returns up to sampleSize decoded display values of a namespace
+func (p *Provider) sampleDisplays(ctx context.Context, key string, sampleSize int) ([][]byte, error) {
+	var samples [][]byte
+	var cursor uint64
+	for len(samples) < sampleSize {
+		fields, next, err := p.client.HScan(ctx, prefixDisplay+key, cursor, "", dictionarySampleScanCount).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to sample display values: %w", err)
+		}
+		for i := 1; i < len(fields) && len(samples) < sampleSize; i += 2 {
+			display, err := p.codec.decode(ctx, key, fields[i])
+			if err != nil {
+				return nil, err
+			}
+			samples = append(samples, []byte(display))
+		}
+
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+	return samples, nil
+}
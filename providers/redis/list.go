@@ -0,0 +1,194 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// ListEntries returns entries of the namespace by scanning its text hash with
+// HSCAN, so count is a hint and the cursor is the HSCAN cursor
+func (p *Provider) ListEntries(ctx context.Context, key, cursor string, count int) ([]providers.StoredEntry, string, error) {
+	key = p.namespace(key)
+	var scanCursor uint64
+	if cursor != "" {
+		var err error
+		if scanCursor, err = strconv.ParseUint(cursor, 10, 64); err != nil {
+			return nil, "", fmt.Errorf("invalid cursor %q: %w", cursor, err)
+		}
+	}
+
+	// HSCAN returns field/value pairs
+	pairs, next, err := p.client.HScan(ctx, prefixText+key, scanCursor, "", int64(count)).Result()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to scan entries: %w", err)
+	}
+
+	entries := make([]providers.StoredEntry, 0, len(pairs)/2)
+	ids := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		ids = append(ids, pairs[i])
+		entries = append(entries, providers.StoredEntry{ID: pairs[i], Text: pairs[i+1]})
+	}
+
+	if len(ids) > 0 {
+		pipe := p.client.Pipeline()
+		displayCmd := pipe.HMGet(ctx, prefixDisplay+key, ids...)
+		hashCmd := pipe.HMGet(ctx, prefixHash+key, ids...)
+		payloadCmd := pipe.HMGet(ctx, prefixPayload+key, ids...)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return nil, "", fmt.Errorf("failed to fetch entry data: %w", err)
+		}
+
+		displays, hashes := displayCmd.Val(), hashCmd.Val()
+		for i := range entries {
+			if stored, ok := displays[i].(string); ok {
+				display, err := p.codec.decode(ctx, key, stored)
+				if err != nil {
+					return nil, "", err
+				}
+				entries[i].Display = display
+			}
+			entries[i].ContentHash, _ = hashes[i].(string)
+			entries[i].Metadata = payloadAt(payloadCmd.Val(), i)
+		}
+	}
+
+	if next == 0 {
+		return entries, "", nil
+	}
+	return entries, strconv.FormatUint(next, 10), nil
+}
+
+// CountEntries returns the number of entries in the namespace, the length of
+// its text hash (HLEN)
+func (p *Provider) CountEntries(ctx context.Context, key string) (int64, error) {
+	count, err := p.client.HLen(ctx, prefixText+p.namespace(key)).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count entries: %w", err)
+	}
+	return count, nil
+}
+
+// GetEntry returns a stored entry and whether it exists, with its score read
+// from the score hash
+func (p *Provider) GetEntry(ctx context.Context, key, id string) (providers.StoredEntry, bool, error) {
+	key = p.namespace(key)
+	read := p.client.Pipeline()
+	textCmd := read.HGet(ctx, prefixText+key, id)
+	displayCmd := read.HGet(ctx, prefixDisplay+key, id)
+	hashCmd := read.HGet(ctx, prefixHash+key, id)
+	payloadCmd := read.HGet(ctx, prefixPayload+key, id)
+	scoreCmd := read.HGet(ctx, prefixScore+key, id)
+	if _, err := read.Exec(ctx); err != nil && err != redis.Nil {
+		return providers.StoredEntry{}, false, fmt.Errorf("failed to get entry: %w", err)
+	}
+	text := textCmd.Val()
+	if text == "" {
+		return providers.StoredEntry{}, false, nil
+	}
+
+	display, err := p.codec.decode(ctx, key, displayCmd.Val())
+	if err != nil {
+		return providers.StoredEntry{}, false, err
+	}
+	return providers.StoredEntry{
+		ID:          id,
+		Text:        text,
+		Display:     display,
+		ContentHash: hashCmd.Val(),
+		Metadata:    payloadCmd.Val(),
+		Score:       parseScore(scoreCmd.Val()),
+	}, true, nil
+}
+
+// IndexStats returns the size of the namespace. Entries is the length of its
+// text hash (HLEN). In LayoutLexicographic, Tokens is the number of members of
+// its sorted set, one per token and position; in LayoutScored, it is the total
+// cardinality of its token sets, whose number Details reports as
+// "distinct_tokens". Bytes sums MEMORY USAGE of the namespace's keys, which
+// Redis estimates by sampling large keys. Details also reports the layout
+func (p *Provider) IndexStats(ctx context.Context, key string) (providers.IndexStats, error) {
+	key = p.namespace(key)
+	pipe := p.client.Pipeline()
+	entriesCmd := pipe.HLen(ctx, prefixText+key)
+	setCmd := pipe.ZCard(ctx, prefixSet+key)
+	var usage []*redis.IntCmd
+	for _, prefix := range []string{
+		prefixSet, prefixText, prefixDisplay, prefixMeta, prefixHash,
+		prefixStrategies, prefixPayload, prefixScore, prefixDictionary, prefixTokenIndex,
+	} {
+		usage = append(usage, pipe.MemoryUsage(ctx, prefix+key))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return providers.IndexStats{}, fmt.Errorf("failed to get index stats: %w", err)
+	}
+
+	stats := providers.IndexStats{
+		Entries: entriesCmd.Val(),
+		Tokens:  setCmd.Val(),
+		Bytes:   sumUsage(usage),
+		Details: map[string]string{"layout": "lexicographic"},
+	}
+	if p.layout == LayoutScored {
+		tokens, distinct, bytes, err := p.scoredTokenStats(ctx, key)
+		if err != nil {
+			return providers.IndexStats{}, err
+		}
+		stats.Tokens = tokens
+		stats.Bytes += bytes
+		stats.Details["layout"] = "scored"
+		stats.Details["distinct_tokens"] = strconv.FormatInt(distinct, 10)
+	}
+	return stats, nil
+}
+
+// scoredTokenStats scans the namespace's token index and returns the total
+// cardinality of its token sets, their number, and their memory usage
+func (p *Provider) scoredTokenStats(ctx context.Context, key string) (tokens, distinct, bytes int64, err error) {
+	var cursor uint64
+	for {
+		names, next, err := p.client.SScan(ctx, prefixTokenIndex+key, cursor, "", tokenSetScanCount).Result()
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("failed to scan token index: %w", err)
+		}
+
+		if len(names) > 0 {
+			pipe := p.client.Pipeline()
+			cards := make([]*redis.IntCmd, len(names))
+			usage := make([]*redis.IntCmd, len(names))
+			for i, tok := range names {
+				cards[i] = pipe.ZCard(ctx, tokenSetKey(key, tok))
+				usage[i] = pipe.MemoryUsage(ctx, tokenSetKey(key, tok))
+			}
+			if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+				return 0, 0, 0, fmt.Errorf("failed to get token set stats: %w", err)
+			}
+			for _, card := range cards {
+				if card.Val() > 0 {
+					tokens += card.Val()
+					distinct++
+				}
+			}
+			bytes += sumUsage(usage)
+		}
+
+		cursor = next
+		if cursor == 0 {
+			return tokens, distinct, bytes, nil
+		}
+	}
+}
+
+// sumUsage adds up MEMORY USAGE replies, skipping the keys that do not exist
+func sumUsage(usage []*redis.IntCmd) int64 {
+	var total int64
+	for _, cmd := range usage {
+		total += cmd.Val()
+	}
+	return total
+}
@@ -0,0 +1,316 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// defaultMaintenanceBatchSize is the SCAN batch size used when MaintenanceOptions.BatchSize is zero.
+const defaultMaintenanceBatchSize = 500
+
+// removeOrphansScript removes sorted set members found orphaned, skipping those
+// whose entry was re-indexed or deleted since its text was read, as the member
+// may match the new text. KEYS are the sorted set and the text and metadata
+// hashes; ARGV holds a member, its entry ID, and the entry's text and metadata
+// as read for each orphan, with an empty text standing for a missing entry.
+// It returns the number of members removed
+var removeOrphansScript = redis.NewScript(`
+local removed = 0
+for i = 1, #ARGV, 4 do
+	local text = redis.call('HGET', KEYS[2], ARGV[i + 1]) or ''
+	local meta = redis.call('HGET', KEYS[3], ARGV[i + 1]) or ''
+	if text == ARGV[i + 2] and meta == ARGV[i + 3] then
+		removed = removed + redis.call('ZREM', KEYS[1], ARGV[i])
+	end
+end
+return removed
+`)
+
+// removeTokenScript drops a token from the token index unless its token set
+// was recreated since it was found empty
+var removeTokenScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[2]) == 0 then
+	redis.call('SREM', KEYS[1], ARGV[1])
+end
+return 0
+`)
+
+// Maintain removes tokens that no longer match their entry's stored text. Index
+// does not remove the tokens of an entry's previous text, and interrupted
+// deletes can leave tokens without an entry; both accumulate in long-lived
+// namespaces and are cleaned up here. It is safe to run while the namespace is
+// in use: tokens of entries changed during a batch are left for the next run
+func (p *Provider) Maintain(
+	ctx context.Context, key string, options providers.MaintenanceOptions,
+) (providers.MaintenanceStats, error) {
+	key = p.namespace(key)
+	if options.BatchSize <= 0 {
+		options.BatchSize = defaultMaintenanceBatchSize
+	}
+	if p.layout == LayoutScored {
+		return p.maintainScored(ctx, key, options)
+	}
+	return p.maintainLexicographic(ctx, key, options)
+}
+
+// maintainLexicographic scans the namespace's sorted set and removes members whose
+// token does not occur at the recorded position of the entry's current text
+func (p *Provider) maintainLexicographic(
+	ctx context.Context, key string, options providers.MaintenanceOptions,
+) (providers.MaintenanceStats, error) {
+	var stats providers.MaintenanceStats
+	var cursor uint64
+	for {
+		// ZSCAN returns member/score pairs
+		pairs, next, err := p.client.ZScan(ctx, prefixSet+key, cursor, "", int64(options.BatchSize)).Result()
+		if err != nil {
+			return stats, fmt.Errorf("failed to scan tokens: %w", err)
+		}
+
+		members := make([]string, 0, len(pairs)/2)
+		for i := 0; i < len(pairs); i += 2 {
+			members = append(members, pairs[i])
+		}
+		texts, err := p.normalizedTexts(ctx, key, memberIDs(members))
+		if err != nil {
+			return stats, err
+		}
+
+		var orphans []interface{}
+		for _, member := range members {
+			if isOrphanedMember(member, texts) {
+				id := extractIDFromMember(member, minMemberPartsForID)
+				orphans = append(orphans, member, id, texts[id].text, texts[id].meta)
+			}
+		}
+		removed, err := p.removeOrphans(ctx, key, prefixSet+key, orphans)
+		if err != nil {
+			return stats, err
+		}
+
+		stats.Scanned += int64(len(members))
+		stats.Removed += removed
+		if options.Progress != nil {
+			options.Progress(stats)
+		}
+
+		cursor = next
+		if cursor == 0 {
+			return stats, nil
+		}
+		if err := pause(ctx, options.BatchDelay); err != nil {
+			return stats, err
+		}
+	}
+}
+
+// maintainScored scans the namespace's token index and removes IDs from token sets
+// whose entry text no longer contains the token, dropping emptied tokens from the index
+func (p *Provider) maintainScored(
+	ctx context.Context, key string, options providers.MaintenanceOptions,
+) (providers.MaintenanceStats, error) {
+	var stats providers.MaintenanceStats
+	var cursor uint64
+	for {
+		tokens, next, err := p.client.SScan(ctx, prefixTokenIndex+key, cursor, "", int64(options.BatchSize)).Result()
+		if err != nil {
+			return stats, fmt.Errorf("failed to scan token index: %w", err)
+		}
+
+		for _, tok := range tokens {
+			scanned, removed, err := p.maintainTokenSet(ctx, key, tok, options.BatchSize)
+			if err != nil {
+				return stats, err
+			}
+			stats.Scanned += scanned
+			stats.Removed += removed
+		}
+		if options.Progress != nil {
+			options.Progress(stats)
+		}
+
+		cursor = next
+		if cursor == 0 {
+			return stats, nil
+		}
+		if err := pause(ctx, options.BatchDelay); err != nil {
+			return stats, err
+		}
+	}
+}
+
+// maintainTokenSet removes orphaned IDs from a single token set
+func (p *Provider) maintainTokenSet(ctx context.Context, key, tok string, batchSize int) (int64, int64, error) {
+	var scanned, removed int64
+	var cursor uint64
+	for {
+		pairs, next, err := p.client.ZScan(ctx, tokenSetKey(key, tok), cursor, "", int64(batchSize)).Result()
+		if err != nil {
+			return scanned, removed, fmt.Errorf("failed to scan token set: %w", err)
+		}
+
+		ids := make([]string, 0, len(pairs)/2)
+		for i := 0; i < len(pairs); i += 2 {
+			ids = append(ids, pairs[i])
+		}
+		texts, err := p.normalizedTexts(ctx, key, ids)
+		if err != nil {
+			return scanned, removed, err
+		}
+
+		var orphans []interface{}
+		for _, id := range ids {
+			text, ok := texts[id]
+			if !ok || !strings.Contains(text.normalized, stripStrategyTag(tok)) {
+				orphans = append(orphans, id, id, text.text, text.meta)
+			}
+		}
+		n, err := p.removeOrphans(ctx, key, tokenSetKey(key, tok), orphans)
+		if err != nil {
+			return scanned, removed, err
+		}
+		scanned += int64(len(ids))
+		removed += n
+
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+
+	remaining, err := p.client.Exists(ctx, tokenSetKey(key, tok)).Result()
+	if err != nil {
+		return scanned, removed, fmt.Errorf("failed to check token set: %w", err)
+	}
+	if remaining == 0 {
+		keys := []string{prefixTokenIndex + key, tokenSetKey(key, tok)}
+		if err := removeTokenScript.Run(ctx, p.client, keys, tok).Err(); err != nil {
+			return scanned, removed, fmt.Errorf("failed to update token index: %w", err)
+		}
+	}
+	return scanned, removed, nil
+}
+
+// removeOrphans runs removeOrphansScript on a sorted set of the namespace with
+// orphans given as its ARGV, returning the number of members removed
+func (p *Provider) removeOrphans(ctx context.Context, key, set string, orphans []interface{}) (int64, error) {
+	if len(orphans) == 0 {
+		return 0, nil
+	}
+	keys := []string{set, prefixText + key, prefixMeta + key}
+	removed, err := removeOrphansScript.Run(ctx, p.client, keys, orphans...).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("failed to remove orphaned tokens: %w", err)
+	}
+	return removed, nil
+}
+
+// storedText is an entry's text and case sensitivity metadata as stored, and
+// the text in the form its tokens were built from
+type storedText struct {
+	text       string
+	meta       string
+	normalized string
+}
+
+// normalizedTexts returns the stored text of each existing ID, along with the
+// text lowercased unless the entry was indexed case-sensitively
+func (p *Provider) normalizedTexts(ctx context.Context, key string, ids []string) (map[string]storedText, error) {
+	texts := make(map[string]storedText, len(ids))
+	if len(ids) == 0 {
+		return texts, nil
+	}
+
+	pipe := p.client.Pipeline()
+	textCmd := pipe.HMGet(ctx, prefixText+key, ids...)
+	metaCmd := pipe.HMGet(ctx, prefixMeta+key, ids...)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to fetch entry texts: %w", err)
+	}
+
+	metas := metaCmd.Val()
+	for i, value := range textCmd.Val() {
+		text, ok := value.(string)
+		if !ok {
+			continue
+		}
+		meta, _ := metas[i].(string)
+		texts[ids[i]] = storedText{text: text, meta: meta, normalized: providers.SearchText(text, meta == "1")}
+	}
+	return texts, nil
+}
+
+// memberIDs returns the distinct entry IDs referenced by sorted set members
+func memberIDs(members []string) []string {
+	seen := make(map[string]bool, len(members))
+	ids := make([]string, 0, len(members))
+	for _, member := range members {
+		id := extractIDFromMember(member, minMemberPartsForID)
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// isOrphanedMember reports whether a token:id or token:id:position member no longer
+// matches its entry's text. Members that cannot be parsed are kept
+func isOrphanedMember(member string, texts map[string]storedText) bool {
+	parts := strings.Split(member, ":")
+	if len(parts) == minMemberPartsForID {
+		if suffixed, ok := suffixOfToken(parts[0]); ok {
+			text, ok := texts[parts[1]]
+			return !ok || text.normalized != suffixed
+		}
+	}
+	if inWord, ok := inWordOfToken(parts[0]); ok {
+		parts[0] = inWord
+	} else {
+		parts[0] = stripStrategyTag(parts[0])
+	}
+	switch len(parts) {
+	case minMemberPartsForID:
+		text, ok := texts[parts[1]]
+		return !ok || !strings.HasPrefix(text.normalized, parts[0])
+
+	case minMemberPartsForPositionalID:
+		position, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return false
+		}
+		stored, ok := texts[parts[1]]
+		if !ok {
+			return true
+		}
+		text := stored.normalized
+		end := position + len(parts[0])
+		return position < 0 || end > len(text) || text[position:end] != parts[0]
+
+	default:
+		return false
+	}
+}
+
+// pause waits between maintenance batches, returning early if ctx is cancelled
+func pause(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
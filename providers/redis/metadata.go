@@ -0,0 +1,56 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// SupportsMetadata reports that metadata payloads are stored with entries and
+// that queries filter by them. A filtered query fetches filterCandidateMultiplier
+// times its limit in candidates and keeps those whose metadata matches, so it
+// may return fewer results than match when the filter excludes most candidates
+func (p *Provider) SupportsMetadata() bool {
+	return true
+}
+
+// fetchEntryData fetches the stored display texts, metadata payloads, and
+// entry scores of the given IDs in one pipeline, in the order of ids
+func (p *Provider) fetchEntryData(ctx context.Context, key string, ids []string) (displays, payloads, scores []interface{}, err error) {
+	pipe := p.client.Pipeline()
+	displayCmd := pipe.HMGet(ctx, prefixDisplay+key, ids...)
+	payloadCmd := pipe.HMGet(ctx, prefixPayload+key, ids...)
+	scoreCmd := pipe.HMGet(ctx, prefixScore+key, ids...)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to fetch display texts: %w", err)
+	}
+	return displayCmd.Val(), payloadCmd.Val(), scoreCmd.Val(), nil
+}
+
+// attachMetadata sets the metadata payloads of results whose display text was
+// fetched some other way, such as by the ranking script
+func (p *Provider) attachMetadata(ctx context.Context, key string, results []providers.ProviderResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+	ids := make([]string, len(results))
+	for i := range results {
+		ids[i] = results[i].ID
+	}
+	payloads, err := p.client.HMGet(ctx, prefixPayload+key, ids...).Result()
+	if err != nil {
+		return fmt.Errorf("failed to fetch metadata: %w", err)
+	}
+	for i := range results {
+		results[i].Metadata = payloadAt(payloads, i)
+	}
+	return nil
+}
+
+// payloadAt returns the metadata payload at index i of an HMGET reply, empty
+// for entries stored without one
+func payloadAt(payloads []interface{}, i int) string {
+	payload, _ := payloads[i].(string)
+	return payload
+}
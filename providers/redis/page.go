@@ -0,0 +1,183 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// QueryPage returns a page of the results of a query and the cursor of the
+// next, which is the offset into the sorted set the page ended at.
+//
+// In LayoutScored, pages follow the global ranking of the token set. In
+// LayoutLexicographic, the members matching the query are read with
+// ZRANGEBYLEX offsets, so pages follow entry ID order and each page is ranked
+// by score on its own; paging reaches every matching entry, unlike Query,
+// which ranks only the members it reads. Queries filtered by metadata,
+// answered by Config.RankingScript, or matched with MatchAnchoredSubstring,
+// and MatchNGram queries of other than n bytes in LayoutLexicographic, return
+// providers.ErrPagingUnsupported.
+//
+//nolint:gocritic // hugeParam: options is passed by value to match the Provider interface
+func (p *Provider) QueryPage(
+	ctx context.Context, key, query string, options providers.QueryOptions, cursor string,
+) ([]providers.ProviderResult, string, error) {
+	if len(options.FilterMetadata) > 0 || options.MatchStrategy == providers.MatchAnchoredSubstring {
+		return nil, "", providers.ErrPagingUnsupported
+	}
+	offset := 0
+	if cursor != "" {
+		var err error
+		offset, err = strconv.Atoi(cursor)
+		if err != nil || offset < 0 {
+			return nil, "", fmt.Errorf("invalid page cursor %q", cursor)
+		}
+	}
+
+	searchQuery := providers.SearchText(query, options.CaseSensitive)
+	stats := p.newQueryStats(key, searchQuery, options)
+	key = p.namespace(key)
+	var results []providers.ProviderResult
+	var next int
+	var err error
+	if p.layout == LayoutScored {
+		results, next, err = p.queryScoredPage(ctx, key, searchQuery, options, offset, stats)
+	} else {
+		results, next, err = p.queryLexicographicPage(ctx, key, searchQuery, options, offset, stats)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	p.reportQueryStats(ctx, stats, results)
+	if next == 0 {
+		return results, "", nil
+	}
+	return results, strconv.Itoa(next), nil
+}
+
+// queryScoredPage returns the page of ranked IDs starting at offset and the
+// offset of the next page, or 0 if there is none
+//
+//nolint:gocritic // hugeParam: options is passed by value to match the Provider interface
+func (p *Provider) queryScoredPage(
+	ctx context.Context, key, searchQuery string, options providers.QueryOptions, offset int, stats *QueryStats,
+) ([]providers.ProviderResult, int, error) {
+	n := getNGramSizeOrDefault(options.NGramSize)
+	if searchQuery == "" || (options.MatchStrategy == providers.MatchNOrMoreGram && len(searchQuery) < n) {
+		return []providers.ProviderResult{}, 0, nil
+	}
+
+	tag := queryTag(options)
+	var top []redis.Z
+	if options.MatchStrategy == providers.MatchNGram && len(searchQuery) > n {
+		ngrams := make([]string, 0, len(searchQuery)-n+1)
+		keys := make([]string, 0, len(searchQuery)-n+1)
+		for i := 0; i <= len(searchQuery)-n; i++ {
+			ngrams = append(ngrams, searchQuery[i:i+n])
+			keys = append(keys, tokenSetKey(key, tag+searchQuery[i:i+n]))
+		}
+		if err := p.addScoredTokenStats(ctx, key, tag, ngrams, stats); err != nil {
+			return nil, 0, err
+		}
+		matches, err := p.client.ZInterWithScores(ctx, &redis.ZStore{Keys: keys, Aggregate: "MIN"}).Result()
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to intersect n-grams: %w", err)
+		}
+		sort.SliceStable(matches, func(i, j int) bool {
+			return matches[i].Score > matches[j].Score
+		})
+		top = matches[min(offset, len(matches)):min(offset+options.MaxResults+1, len(matches))]
+	} else {
+		var err error
+		top, err = p.client.ZRevRangeWithScores(ctx, tokenSetKey(key, tag+searchQuery),
+			int64(offset), int64(offset+options.MaxResults)).Result()
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to query autocomplete: %w", err)
+		}
+		if err := p.addScoredTokenStats(ctx, key, tag, []string{searchQuery}, stats); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	// One result past the page is read to tell whether another follows
+	next := 0
+	if len(top) > options.MaxResults {
+		top = top[:options.MaxResults]
+		next = offset + options.MaxResults
+	}
+	results, err := p.fetchScoredResults(ctx, key, top)
+	return results, next, err
+}
+
+// queryLexicographicPage reads the members of the query's token from offset
+// until MaxResults entries are found, ranks those entries, and returns them
+// with the offset of the first member of the next page, or 0 if there is none.
+// Unlike Query, it reads the members of the exact token, in which every
+// entry appears once, or once per position, rather than those of every token
+// the query is a prefix of; MatchSuffix tokens hold whole texts reversed, so
+// for them the prefix scan is kept.
+//
+//nolint:gocritic // hugeParam: options is passed by value to match the Provider interface
+func (p *Provider) queryLexicographicPage(
+	ctx context.Context, key, searchQuery string, options providers.QueryOptions, offset int, stats *QueryStats,
+) ([]providers.ProviderResult, int, error) {
+	n := getNGramSizeOrDefault(options.NGramSize)
+	switch {
+	case searchQuery == "" || (options.MatchStrategy == providers.MatchNOrMoreGram && len(searchQuery) < n):
+		return []providers.ProviderResult{}, 0, nil
+	case options.MatchStrategy == providers.MatchNGram && len(searchQuery) != n:
+		return nil, 0, providers.ErrPagingUnsupported
+	case options.MatchStrategy != providers.MatchSuffix && p.rankingScript != nil:
+		return nil, 0, providers.ErrPagingUnsupported
+	}
+
+	start := createLexicographicStartKey(queryTag(options) + searchQuery + ":")
+	end := createLexicographicEndKey(queryTag(options) + searchQuery + ":")
+	if options.MatchStrategy == providers.MatchSuffix {
+		tok := queryTag(options) + reversedToken(searchQuery)
+		start, end = createLexicographicStartKey(tok), createLexicographicEndKey(tok)
+	}
+
+	// Members of an entry are adjacent, so the page ends at the first member
+	// of the entry past MaxResults
+	minParts := getMinPartsForStrategy(options.MatchStrategy)
+	window := (options.MaxResults + 1) * resultMultiplierForDuplicates
+	var page []string
+	entries, last, next := 0, "", 0
+	for read := offset; next == 0; {
+		members, err := p.client.ZRangeByLex(ctx, prefixSet+key, &redis.ZRangeBy{
+			Min:    start,
+			Max:    end,
+			Offset: int64(read),
+			Count:  int64(window),
+		}).Result()
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to query autocomplete: %w", err)
+		}
+		for i, member := range members {
+			id := extractIDFromMember(member, minParts)
+			if id != last {
+				entries++
+				last = id
+			}
+			if entries > options.MaxResults {
+				next = read + i
+				break
+			}
+			page = append(page, member)
+		}
+		if len(members) < window {
+			break
+		}
+		read += len(members)
+	}
+
+	stats.add(searchQuery, len(extractIDsFromResults(page, minParts)))
+	results, err := p.rankMembers(ctx, key, page, options)
+	return results, next, err
+}
@@ -0,0 +1,107 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// groupMembersByID groups positional members (token:id:position) by entry ID
+func groupMembersByID(members []string) map[string][]string {
+	byID := make(map[string][]string)
+	for _, member := range members {
+		if id := extractIDFromMember(member, minMemberPartsForPositionalID); id != "" {
+			byID[id] = append(byID[id], member)
+		}
+	}
+	return byID
+}
+
+// memberPositions returns the positions encoded in positional members
+func memberPositions(members []string) []int {
+	positions := make([]int, 0, len(members))
+	for _, member := range members {
+		parts := strings.Split(member, ":")
+		if len(parts) < minMemberPartsForPositionalID {
+			continue
+		}
+		if position, err := strconv.Atoi(parts[2]); err == nil {
+			positions = append(positions, position)
+		}
+	}
+	return positions
+}
+
+// rankByProximity ranks the entries with members for every query n-gram, given
+// in query order, by entry score, read from the score hash, plus
+// providers.ProximityScore of the n-gram positions, so entries containing the
+// query as written rank above those containing its n-grams apart. Ties are
+// broken by ID
+//
+//nolint:gocritic // hugeParam: options is passed by value to match the Provider interface
+func (p *Provider) rankByProximity(
+	ctx context.Context, key string, ngramMembers []map[string][]string, options providers.QueryOptions,
+) ([]providers.ProviderResult, error) {
+	var ids []string
+	for _, id := range slices.Sorted(maps.Keys(ngramMembers[0])) {
+		if containsID(ngramMembers[1:], id) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return []providers.ProviderResult{}, nil
+	}
+
+	scores, err := p.client.HMGet(ctx, prefixScore+key, ids...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch scores: %w", err)
+	}
+
+	offsets := make([]int, len(ngramMembers))
+	for i := range offsets {
+		offsets[i] = i
+	}
+	breakdowns := make(map[string]providers.ScoreBreakdown, len(ids))
+	rank := make(map[string]float64, len(ids))
+	for i, id := range ids {
+		positions := make([][]int, len(ngramMembers))
+		for j, members := range ngramMembers {
+			positions[j] = memberPositions(members[id])
+		}
+		breakdown := providers.ScoreBreakdown{Entry: scoreAt(scores, i), Proximity: providers.ProximityScore(positions, offsets)}
+		breakdowns[id] = breakdown
+		rank[id] = breakdown.Entry + breakdown.Proximity
+	}
+	sort.SliceStable(ids, func(i, j int) bool {
+		return rank[ids[i]] > rank[ids[j]]
+	})
+
+	results, err := p.fetchProviderResults(ctx, key, limitResults(ids, options.MaxResults))
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].Score = rank[results[i].ID]
+		if options.ExplainScores {
+			breakdown := breakdowns[results[i].ID]
+			results[i].Breakdown = &breakdown
+		}
+	}
+	return results, nil
+}
+
+// containsID reports whether every member group has members for id
+func containsID(groups []map[string][]string, id string) bool {
+	for _, group := range groups {
+		if len(group[id]) == 0 {
+			return false
+		}
+	}
+	return true
+}
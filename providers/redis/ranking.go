@@ -0,0 +1,164 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// rankingCandidateMultiplier is how many sorted set members per requested result
+// the ranking script inspects. Candidates never leave Redis, so it can afford to
+// look much further than the client-side path.
+const rankingCandidateMultiplier = 50
+
+// rankedResultFields is the number of array elements per result returned by a ranking script.
+const rankedResultFields = 3
+
+// DefaultRankingScript is a Lua ranking script that scores candidates inside Redis.
+// Each candidate ID is scored as
+//
+//	entryScore + 1/(1+position) + queryLength/textLength
+//
+// where entryScore is read from the score hash, and position is the earliest
+// offset at which the query matched, so entries matching near the start and
+// short entries rank higher. Ties are broken by ID.
+//
+// Custom scripts must follow the same contract:
+//
+//	KEYS[1]  sorted set of tokens (ac:set:<namespace>)
+//	KEYS[2]  hash of ID → display text
+//	KEYS[3]  hash of ID → original text
+//	KEYS[4]  hash of ID → entry score (entries without a field score 1)
+//	ARGV[1]  ZRANGEBYLEX min
+//	ARGV[2]  ZRANGEBYLEX max
+//	ARGV[3]  maximum number of members to inspect
+//	ARGV[4]  number of results to return (K)
+//	ARGV[5]  query length in bytes
+//
+// and return a flat array of {id, score, display} triples, best first, with
+// the score encoded as a string.
+const DefaultRankingScript = `
+local function split(member)
+  local parts, start = {}, 1
+  while true do
+    local i = string.find(member, ':', start, true)
+    if not i then
+      table.insert(parts, string.sub(member, start))
+      return parts
+    end
+    table.insert(parts, string.sub(member, start, i - 1))
+    start = i + 1
+  end
+end
+
+local members = redis.call('ZRANGEBYLEX', KEYS[1], ARGV[1], ARGV[2], 'LIMIT', 0, tonumber(ARGV[3]))
+local limit = tonumber(ARGV[4])
+local queryLength = tonumber(ARGV[5])
+
+local byID, candidates = {}, {}
+for _, member in ipairs(members) do
+  local parts = split(member)
+  local id = parts[2]
+  if id then
+    local position = tonumber(parts[3]) or 0
+    local candidate = byID[id]
+    if not candidate then
+      candidate = {id = id, position = position, score = tonumber(redis.call('HGET', KEYS[4], id)) or 1}
+      byID[id] = candidate
+      table.insert(candidates, candidate)
+    elseif position < candidate.position then
+      candidate.position = position
+    end
+  end
+end
+
+for _, candidate in ipairs(candidates) do
+  local textLength = math.max(redis.call('HSTRLEN', KEYS[3], candidate.id), 1)
+  candidate.rank = candidate.score + 1 / (1 + candidate.position) + queryLength / textLength
+end
+
+table.sort(candidates, function(a, b)
+  if a.rank ~= b.rank then
+    return a.rank > b.rank
+  end
+  return a.id < b.id
+end)
+
+local results = {}
+for _, candidate in ipairs(candidates) do
+  if #results >= limit * 3 then
+    break
+  end
+  local display = redis.call('HGET', KEYS[2], candidate.id)
+  if display then
+    table.insert(results, candidate.id)
+    table.insert(results, tostring(candidate.rank))
+    table.insert(results, display)
+  end
+end
+return results
+`
+
+// queryWithRankingScript runs the configured ranking script for a lexicographic
+// token range and returns its top results
+func (p *Provider) queryWithRankingScript(
+	ctx context.Context, key, searchQuery string, options providers.QueryOptions,
+) ([]providers.ProviderResult, error) {
+	keys := []string{prefixSet + key, prefixDisplay + key, prefixText + key, prefixScore + key}
+	tag := queryTag(options)
+	args := []interface{}{
+		createLexicographicStartKey(tag + searchQuery),
+		createLexicographicEndKey(tag + searchQuery),
+		options.MaxResults * rankingCandidateMultiplier,
+		options.MaxResults,
+		len(searchQuery),
+	}
+
+	reply, err := p.rankingScript.Run(ctx, p.client, keys, args...).Slice()
+	if err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to run ranking script: %w", err)
+	}
+
+	results, err := parseRankedResults(reply)
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		if results[i].Display, err = p.codec.decode(ctx, key, results[i].Display); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.attachMetadata(ctx, key, results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// parseRankedResults converts a flat {id, score, display} reply into provider results
+func parseRankedResults(reply []interface{}) ([]providers.ProviderResult, error) {
+	if len(reply)%rankedResultFields != 0 {
+		return nil, fmt.Errorf("ranking script returned %d values, want a multiple of %d", len(reply), rankedResultFields)
+	}
+
+	results := make([]providers.ProviderResult, 0, len(reply)/rankedResultFields)
+	for i := 0; i < len(reply); i += rankedResultFields {
+		id, idOK := reply[i].(string)
+		scoreText, scoreOK := reply[i+1].(string)
+		display, displayOK := reply[i+2].(string)
+		if !idOK || !scoreOK || !displayOK {
+			return nil, fmt.Errorf("ranking script returned unexpected value types at result %d", i/rankedResultFields)
+		}
+
+		score, err := strconv.ParseFloat(scoreText, 64)
+		if err != nil {
+			return nil, fmt.Errorf("ranking script returned invalid score %q: %w", scoreText, err)
+		}
+
+		results = append(results, providers.ProviderResult{ID: id, Display: display, Score: score})
+	}
+	return results, nil
+}
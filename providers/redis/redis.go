@@ -5,8 +5,11 @@ package redis
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/go-redis/redis/v8"
 
@@ -14,7 +17,8 @@ import (
 )
 
 const (
-	// prefixSet is the Redis key prefix for sorted sets storing tokens → IDs with scores.
+	// prefixSet is the Redis key prefix for sorted sets storing tokens → IDs, all
+	// with score lexMemberScore so that ZRANGEBYLEX can scan them.
 	prefixSet = "ac:set:"
 
 	// prefixDisplay is the Redis key prefix for hash maps storing ID → display text.
@@ -26,6 +30,31 @@ const (
 	// prefixMeta is the Redis key prefix for hash maps storing ID → metadata.
 	prefixMeta = "ac:meta:"
 
+	// prefixHash is the Redis key prefix for hash maps storing ID → content hash.
+	prefixHash = "ac:hash:"
+
+	// prefixStrategies is the Redis key prefix for hash maps storing ID → the match
+	// strategies an entry was indexed under, for entries indexed under several.
+	prefixStrategies = "ac:strategies:"
+
+	// prefixPayload is the Redis key prefix for hash maps storing ID → metadata
+	// payload given in IndexOptions.Metadata.
+	prefixPayload = "ac:payload:"
+
+	// prefixScore is the Redis key prefix for hash maps storing ID → entry score,
+	// which ranks entries in LayoutLexicographic and which UpdateScore needs to
+	// separate from token scores in LayoutScored.
+	prefixScore = "ac:score:"
+
+	// defaultEntryScore is the score of entries with no score hash field, which
+	// were indexed before entry scores were stored there, all with score 1.
+	defaultEntryScore = 1.0
+
+	// lexMemberScore is the score of every member of a LayoutLexicographic
+	// sorted set. ZRANGEBYLEX is only defined on members of equal score, so
+	// entry scores are kept in the score hash instead.
+	lexMemberScore = 1.0
+
 	// defaultNGramSize is the default n-gram size when not specified in options.
 	defaultNGramSize = 3
 
@@ -49,71 +78,158 @@ const (
 
 	// minMemberPartsForPositionalID is the minimum parts for positional format.
 	minMemberPartsForPositionalID = 3
+
+	// batchSize is the number of entries BatchIndex and DeleteMany send per pipeline.
+	batchSize = 1000
+
+	// filterCandidateMultiplier widens the candidates of a query with
+	// QueryOptions.FilterMetadata, which is applied once their metadata is fetched.
+	filterCandidateMultiplier = 10
 )
 
 // Provider implements the autocomplete Provider interface using Redis.
 // It uses Redis sorted sets for storage and retrieval of autocomplete entries.
 // All methods are safe for concurrent use.
 type Provider struct {
-	client *redis.Client
+	client        redis.UniversalClient
+	ownsClient    bool
+	hashTags      bool
+	rankingScript *redis.Script
+	layout        Layout
+	codec         *valueCodec
+	onQueryStats  func(ctx context.Context, stats QueryStats)
 }
 
 // Config holds Redis connection parameters.
 type Config struct {
 	// Addr is the Redis server address in the format "host:port".
+	// Ignored when ClusterAddrs or MasterName is set.
 	Addr string
 
+	// ClusterAddrs, when set, connects to a Redis Cluster through these seed
+	// node addresses in the format "host:port". All keys of a namespace then
+	// carry the namespace as a hash tag, e.g. "ac:set:{cities}", so they map to
+	// one slot and pipelines, transactions and ranking scripts keep working.
+	// Switching between single-node and cluster mode requires reindexing all data.
+	ClusterAddrs []string
+
+	// MasterName, when set, connects through Redis Sentinel to the master of
+	// this name, following failovers to a new master without recreating the
+	// provider. Cannot be combined with ClusterAddrs.
+	MasterName string
+
+	// SentinelAddrs are the Sentinel addresses in the format "host:port".
+	// Required with MasterName.
+	SentinelAddrs []string
+
+	// SentinelPassword is the password for the Sentinels, if different from
+	// the Redis password (empty string for no password).
+	SentinelPassword string
+
+	// Username is the ACL user to authenticate as (empty string for the
+	// default user, authenticated with Password alone).
+	Username string
+
 	// Password is the Redis password (empty string for no password).
 	Password string
 
+	// TLSConfig, when set, encrypts connections with TLS, as required by
+	// managed offerings such as ElastiCache with in-transit encryption or
+	// Azure Cache for Redis. Nil connects without TLS.
+	TLSConfig *tls.Config
+
+	// ClientName is set as the name of every connection (CLIENT SETNAME), so
+	// the provider's connections can be told apart in CLIENT LIST.
+	ClientName string
+
+	// DialTimeout is the timeout for establishing new connections.
+	// Default: 5 seconds.
+	DialTimeout time.Duration
+
+	// ReadTimeout is the timeout for socket reads. A negative value disables it.
+	// Default: 3 seconds.
+	ReadTimeout time.Duration
+
+	// WriteTimeout is the timeout for socket writes. A negative value disables it.
+	// Default: ReadTimeout.
+	WriteTimeout time.Duration
+
 	// DB is the Redis database number (0-15, default is 0).
 	// Redis Cluster only supports DB 0.
 	DB int
+
+	// RankingScript is a Lua script that ranks query candidates inside Redis and
+	// returns only the top results, instead of transferring candidate members to Go.
+	// Use DefaultRankingScript for position, score, and length based ranking, or
+	// supply a script following the contract documented there.
+	// Empty (default) keeps client-side ranking. N-gram queries longer than the
+	// n-gram size always use client-side intersection.
+	RankingScript string
+
+	// Layout selects how tokens are stored. LayoutLexicographic (default) keeps all
+	// tokens of a namespace in one sorted set scanned with ZRANGEBYLEX.
+	// LayoutScored trades memory for latency; see LayoutScored.
+	// Changing the layout requires reindexing all data.
+	Layout Layout
+
+	// Compression compresses display values larger than CompressionThreshold,
+	// which dominate hash memory for large catalogs. Stored values are tagged,
+	// so compression can be enabled or changed without reindexing.
+	// Default: CompressionNone.
+	Compression Compression
+
+	// CompressionThreshold is the minimum display size in bytes that gets compressed.
+	// Default: 128.
+	CompressionThreshold int
+
+	// OnQueryStats, when set, is called after every successful query with the
+	// number of candidates each query token or n-gram matched before
+	// intersection, e.g. to find the token that makes a multi-word query return
+	// nothing. It is a debugging aid: in LayoutScored it costs an extra command
+	// per token. Called synchronously, so it should return quickly.
+	// Default: nil.
+	OnQueryStats func(ctx context.Context, stats QueryStats)
 }
 
 // New creates a new Redis provider with the given configuration.
 // It establishes a connection to Redis and verifies connectivity with a PING command.
 func New(config Config) (*Provider, error) {
-	client := redis.NewClient(&redis.Options{
-		Addr:     config.Addr,
-		Password: config.Password, // pragma: allowlist secret
-		DB:       config.DB,
-	})
-
-	ctx := context.Background()
-	if err := client.Ping(ctx).Err(); err != nil {
-		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	client, err := newClient(config)
+	if err != nil {
+		return nil, err
 	}
 
-	return &Provider{
-		client: client,
-	}, nil
+	provider, err := newProvider(client, config, len(config.ClusterAddrs) > 0)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	provider.ownsClient = true
+	return provider, nil
 }
 
-// intersectIDSets returns IDs that appear in all sets
-func intersectIDSets(sets []map[string]bool) []string {
-	if len(sets) == 0 {
-		return []string{}
+// namespace returns the form of a namespace used in Redis key names. With hash
+// tags enabled it is wrapped in braces, so that Redis Cluster hashes only the
+// namespace and all of its keys land on the same slot
+func (p *Provider) namespace(key string) string {
+	if !p.hashTags {
+		return key
 	}
-	if len(sets) == 1 {
-		return extractKeysFromSet(sets[0])
-	}
-
-	intersection := copySet(sets[0])
-	removeNonIntersectingIDs(intersection, sets[1:])
-	return extractKeysFromSet(intersection)
+	return "{" + key + "}"
 }
 
-// queryNGramSlidingWindow performs sliding window search for n-gram queries longer than n
+// queryNGramSlidingWindow performs sliding window search for n-gram queries longer than n,
+// ranking the entries containing every n-gram by proximity (see rankByProximity)
 func (p *Provider) queryNGramSlidingWindow(
-	ctx context.Context, key, searchQuery string, n int, options providers.QueryOptions,
+	ctx context.Context, key, searchQuery string, n int, options providers.QueryOptions, stats *QueryStats,
 ) ([]providers.ProviderResult, error) {
-	var ngramSets []map[string]bool
+	var ngramMembers []map[string][]string
 
+	tag := queryTag(options)
 	for i := 0; i <= len(searchQuery)-n; i++ {
 		ngram := searchQuery[i : i+n]
-		start := createLexicographicStartKey(ngram)
-		end := createLexicographicEndKey(ngram)
+		start := createLexicographicStartKey(tag + ngram)
+		end := createLexicographicEndKey(tag + ngram)
 
 		results, err := p.client.ZRangeByLex(ctx, prefixSet+key, &redis.ZRangeBy{
 			Min:    start,
@@ -125,19 +241,64 @@ func (p *Provider) queryNGramSlidingWindow(
 		if err != nil {
 			return nil, fmt.Errorf("failed to query n-gram '%s': %w", ngram, err)
 		}
-		idSet := extractIDsFromResults(results, minMemberPartsForPositionalID)
-		if isEmptySet(idSet) {
+		members := groupMembersByID(results)
+		stats.add(ngram, len(members))
+		if len(members) == 0 {
 			return []providers.ProviderResult{}, nil
 		}
 
-		ngramSets = append(ngramSets, idSet)
+		ngramMembers = append(ngramMembers, members)
+	}
+	return p.rankByProximity(ctx, key, ngramMembers, options)
+}
+
+// rankMembers ranks the entries of scanned members by entry score, read from
+// the score hash, highest first, keeping the scan order among equal scores,
+// and fetches the top MaxResults. Only the members a query reads are ranked,
+// so a high-scoring entry beyond them is not found
+//
+//nolint:gocritic // hugeParam: options is passed by value to match the Provider interface
+func (p *Provider) rankMembers(
+	ctx context.Context, key string, members []string, options providers.QueryOptions,
+) ([]providers.ProviderResult, error) {
+	minParts := getMinPartsForStrategy(options.MatchStrategy)
+	seen := make(map[string]bool)
+	var ids []string
+	for _, member := range members {
+		if id := extractIDFromMember(member, minParts); id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return []providers.ProviderResult{}, nil
+	}
+	stored, err := p.client.HMGet(ctx, prefixScore+key, ids...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch scores: %w", err)
+	}
+
+	scores := make(map[string]float64, len(ids))
+	for i, id := range ids {
+		scores[id] = scoreAt(stored, i)
+	}
+	sort.SliceStable(ids, func(i, j int) bool {
+		return scores[ids[i]] > scores[ids[j]]
+	})
+
+	results, err := p.fetchProviderResults(ctx, key, limitResults(ids, options.MaxResults))
+	if err != nil {
+		return nil, err
+	}
+	if options.ExplainScores {
+		for i := range results {
+			results[i].Breakdown = &providers.ScoreBreakdown{Entry: results[i].Score}
+		}
 	}
-	ids := intersectIDSets(ngramSets)
-	ids = limitResults(ids, options.MaxResults)
-	return p.fetchProviderResults(ctx, key, ids)
+	return results, nil
 }
 
-// fetchProviderResults fetches full data for given IDs
+// fetchProviderResults fetches full data, with entry scores, for given IDs
 func (p *Provider) fetchProviderResults(
 	ctx context.Context, key string, ids []string,
 ) ([]providers.ProviderResult, error) {
@@ -147,24 +308,29 @@ func (p *Provider) fetchProviderResults(
 
 	providerResults := make([]providers.ProviderResult, 0, len(ids))
 
-	displayList, err := p.client.HMGet(ctx, prefixDisplay+key, ids...).Result()
+	displayList, payloads, scores, err := p.fetchEntryData(ctx, key, ids)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch display texts: %w", err)
+		return nil, err
 	}
 	for i, id := range ids {
 		if displayList[i] == nil {
 			continue
 		}
 
-		display, ok := displayList[i].(string)
+		storedDisplay, ok := displayList[i].(string)
 		if !ok {
 			continue
 		}
+		display, err := p.codec.decode(ctx, key, storedDisplay)
+		if err != nil {
+			return nil, err
+		}
 
 		result := providers.ProviderResult{
-			ID:      id,
-			Display: display,
-			Score:   1.0,
+			ID:       id,
+			Display:  display,
+			Score:    scoreAt(scores, i),
+			Metadata: payloadAt(payloads, i),
 		}
 
 		providerResults = append(providerResults, result)
@@ -175,61 +341,104 @@ func (p *Provider) fetchProviderResults(
 
 // Index adds or updates an entry in the Redis autocomplete index
 func (p *Provider) Index(ctx context.Context, key, id, text, display string, options providers.IndexOptions) error {
+	key = p.namespace(key)
 	pipe := p.client.Pipeline()
-
-	// Store both original and lowercase versions if needed
-	textToIndex := text
-	if !options.CaseSensitive {
-		textToIndex = strings.ToLower(text)
+	if err := p.addEntryCommands(pipe, ctx, key, id, text, display, options); err != nil {
+		return err
 	}
 
-	switch options.MatchStrategy {
-	case providers.MatchPrefix:
-		for i := 1; i <= len(textToIndex); i++ {
-			prefix := textToIndex[:i]
-			member := createPrefixMember(prefix, id)
-			pipe.ZAdd(ctx, prefixSet+key, &redis.Z{
-				Score:  options.Score,
-				Member: member,
-			})
-		}
+	_, err := pipe.Exec(ctx)
+	return err
+}
 
-	case providers.MatchNGram:
-		n := getNGramSizeOrDefault(options.NGramSize)
-		for i := 0; i <= len(textToIndex)-n; i++ {
-			ngram := textToIndex[i : i+n]
-			member := createPositionalMember(ngram, id, i)
-			pipe.ZAdd(ctx, prefixSet+key, &redis.Z{
-				Score:  options.Score,
-				Member: member,
-			})
+// IndexAtomic writes all entries inside a single MULTI/EXEC transaction so that
+// queries observe either none or all of them
+func (p *Provider) IndexAtomic(ctx context.Context, key string, entries []providers.IndexEntry) error {
+	key = p.namespace(key)
+	pipe := p.client.TxPipeline()
+	for _, entry := range entries {
+		if err := p.addEntryCommands(pipe, ctx, key, entry.ID, entry.Text, entry.Display, entry.Options); err != nil {
+			return err
 		}
+	}
 
-	case providers.MatchNOrMoreGram:
-		n := getNGramSizeOrDefault(options.NGramSize)
-		for start := 0; start < len(textToIndex); start++ {
-			for end := start + n; end <= len(textToIndex); end++ {
-				substring := textToIndex[start:end]
-				member := createPositionalMember(substring, id, start)
-				pipe.ZAdd(ctx, prefixSet+key, &redis.Z{
-					Score:  options.Score,
-					Member: member,
-				})
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to execute index transaction: %w", err)
+	}
+	return nil
+}
+
+// BatchIndex writes entries through non-transactional pipelines of up to
+// batchSize entries, so a bulk load takes one round trip per pipeline
+func (p *Provider) BatchIndex(ctx context.Context, key string, entries []providers.IndexEntry) error {
+	key = p.namespace(key)
+	for start := 0; start < len(entries); start += batchSize {
+		pipe := p.client.Pipeline()
+		for _, entry := range entries[start:min(start+batchSize, len(entries))] {
+			if err := p.addEntryCommands(pipe, ctx, key, entry.ID, entry.Text, entry.Display, entry.Options); err != nil {
+				return err
 			}
 		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return fmt.Errorf("failed to execute index pipeline: %w", err)
+		}
+	}
+	return nil
+}
 
-	case providers.MatchSubstring:
-		for start := 0; start < len(textToIndex); start++ {
-			for end := start + 1; end <= len(textToIndex); end++ {
-				substring := textToIndex[start:end]
-				member := createPositionalMember(substring, id, start)
-				pipe.ZAdd(ctx, prefixSet+key, &redis.Z{
-					Score:  options.Score,
-					Member: member,
-				})
-			}
+// addEntryCommands queues the commands that store a single entry in the configured layout
+func (p *Provider) addEntryCommands(
+	pipe redis.Pipeliner, ctx context.Context, key, id, text, display string, options providers.IndexOptions,
+) error {
+	storedDisplay, err := p.codec.encode(ctx, key, display)
+	if err != nil {
+		return err
+	}
+
+	// Entry data is written before tokens so that Maintain never sees a token
+	// whose entry text has not been stored yet
+	addEntryDataCommands(pipe, ctx, key, id, text, storedDisplay, options)
+	pipe.HSet(ctx, prefixScore+key, id, formatScore(options.Score))
+	for _, tagged := range strategyOptions(options) {
+		if p.layout == LayoutScored {
+			addScoredTokenCommands(pipe, ctx, key, id, text, tagged.tag, tagged.options)
+		} else {
+			addTokenCommands(pipe, ctx, key, id, text, tagged.tag, tagged.options)
+		}
+	}
+	return nil
+}
+
+// addTokenCommands queues the sorted set members for an entry's tokens, prefixed with tag
+func addTokenCommands(pipe redis.Pipeliner, ctx context.Context, key, id, text, tag string, options providers.IndexOptions) {
+	if options.MatchStrategy == providers.MatchSuffix {
+		// A single reversed token per entry: scanning it by prefix finds every suffix
+		pipe.ZAdd(ctx, prefixSet+key, &redis.Z{
+			Score:  lexMemberScore,
+			Member: createPrefixMember(tag+reversedToken(providers.SearchText(text, options.CaseSensitive)), id),
+		})
+		return
+	}
+	if options.MatchStrategy == providers.MatchAnchoredSubstring {
+		addAnchoredTokenCommands(pipe, ctx, key, id, text, tag, options)
+		return
+	}
+	for _, tok := range tokenize(text, options) {
+		member := createPositionalMember(tag+tok.text, id, tok.position)
+		if options.MatchStrategy == providers.MatchPrefix {
+			member = createPrefixMember(tag+tok.text, id)
 		}
+		pipe.ZAdd(ctx, prefixSet+key, &redis.Z{
+			Score:  lexMemberScore,
+			Member: member,
+		})
 	}
+}
+
+// addEntryDataCommands queues the hash fields holding an entry's text, display, and metadata
+func addEntryDataCommands(
+	pipe redis.Pipeliner, ctx context.Context, key, id, text, display string, options providers.IndexOptions,
+) {
 	pipe.HSet(ctx, prefixText+key, id, text)
 	pipe.HSet(ctx, prefixDisplay+key, id, display)
 	// Store case sensitivity metadata
@@ -238,18 +447,77 @@ func (p *Provider) Index(ctx context.Context, key, id, text, display string, opt
 	} else {
 		pipe.HDel(ctx, prefixMeta+key, id)
 	}
+	if options.ContentHash != "" {
+		pipe.HSet(ctx, prefixHash+key, id, options.ContentHash)
+	} else {
+		pipe.HDel(ctx, prefixHash+key, id)
+	}
+	if len(options.MatchStrategies) > 0 {
+		pipe.HSet(ctx, prefixStrategies+key, id, formatStrategies(options.MatchStrategies))
+	} else {
+		pipe.HDel(ctx, prefixStrategies+key, id)
+	}
+	if options.Metadata != "" {
+		pipe.HSet(ctx, prefixPayload+key, id, options.Metadata)
+	} else {
+		pipe.HDel(ctx, prefixPayload+key, id)
+	}
+}
 
-	_, err := pipe.Exec(ctx)
-	return err
+// ContentHash returns the stored content hash for an entry and whether it exists
+func (p *Provider) ContentHash(ctx context.Context, key, id string) (string, bool, error) {
+	key = p.namespace(key)
+	pipe := p.client.Pipeline()
+	existsCmd := pipe.HExists(ctx, prefixText+key, id)
+	hashCmd := pipe.HGet(ctx, prefixHash+key, id)
+
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return "", false, fmt.Errorf("failed to get content hash: %w", err)
+	}
+	if !existsCmd.Val() {
+		return "", false, nil
+	}
+	return hashCmd.Val(), true, nil
+}
+
+// Exists reports whether an entry is stored
+func (p *Provider) Exists(ctx context.Context, key, id string) (bool, error) {
+	exists, err := p.client.HExists(ctx, prefixText+p.namespace(key), id).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check entry: %w", err)
+	}
+	return exists, nil
 }
 
 // Query searches for entries matching the given query
 func (p *Provider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
-	searchQuery := query
-	if !options.CaseSensitive {
-		searchQuery = strings.ToLower(query)
+	searchQuery := providers.SearchText(query, options.CaseSensitive)
+	stats := p.newQueryStats(key, searchQuery, options)
+	limit := options.MaxResults
+	if len(options.FilterMetadata) > 0 {
+		options.MaxResults *= filterCandidateMultiplier
 	}
+	results, err := p.query(ctx, p.namespace(key), searchQuery, options, stats)
+	if err != nil {
+		return nil, err
+	}
+	if len(options.FilterMetadata) > 0 {
+		results = providers.FilterByMetadata(results, options.FilterMetadata)
+		results = results[:min(len(results), limit)]
+	}
+	p.reportQueryStats(ctx, stats, results)
+	return results, nil
+}
 
+// query answers a query in the configured layout, recording token candidates in stats
+//
+//nolint:gocritic // hugeParam: options is passed by value to match the Provider interface
+func (p *Provider) query(
+	ctx context.Context, key, searchQuery string, options providers.QueryOptions, stats *QueryStats,
+) ([]providers.ProviderResult, error) {
+	if p.layout == LayoutScored {
+		return p.queryScored(ctx, key, searchQuery, options, stats)
+	}
 	if options.MatchStrategy == providers.MatchNGram {
 		n := getNGramSizeOrDefault(options.NGramSize)
 
@@ -257,7 +525,7 @@ func (p *Provider) Query(ctx context.Context, key, query string, options provide
 			return []providers.ProviderResult{}, nil
 		}
 		if len(searchQuery) > n {
-			return p.queryNGramSlidingWindow(ctx, key, searchQuery, n, options)
+			return p.queryNGramSlidingWindow(ctx, key, searchQuery, n, options, stats)
 		}
 	}
 	if options.MatchStrategy == providers.MatchNOrMoreGram {
@@ -266,8 +534,17 @@ func (p *Provider) Query(ctx context.Context, key, query string, options provide
 			return []providers.ProviderResult{}, nil
 		}
 	}
-	start := createLexicographicStartKey(searchQuery)
-	end := createLexicographicEndKey(searchQuery)
+	if options.MatchStrategy == providers.MatchAnchoredSubstring {
+		return p.queryAnchored(ctx, key, searchQuery, options, stats)
+	}
+	tok := queryTag(options) + searchQuery
+	if options.MatchStrategy == providers.MatchSuffix {
+		tok = queryTag(options) + reversedToken(searchQuery)
+	} else if p.rankingScript != nil {
+		return p.queryWithRankingScript(ctx, key, searchQuery, options)
+	}
+	start := createLexicographicStartKey(tok)
+	end := createLexicographicEndKey(tok)
 	results, err := p.client.ZRangeByLex(ctx, prefixSet+key, &redis.ZRangeBy{
 		Min:    start,
 		Max:    end,
@@ -278,12 +555,13 @@ func (p *Provider) Query(ctx context.Context, key, query string, options provide
 	if err != nil {
 		return nil, fmt.Errorf("failed to query autocomplete: %w", err)
 	}
-	ids := extractUniqueIDsFromResults(results, options)
-	return p.fetchProviderResults(ctx, key, ids)
+	stats.add(searchQuery, len(extractIDsFromResults(results, getMinPartsForStrategy(options.MatchStrategy))))
+	return p.rankMembers(ctx, key, results, options)
 }
 
 // Delete removes an entry from the index
 func (p *Provider) Delete(ctx context.Context, key, id string) error {
+	key = p.namespace(key)
 	pipe := p.client.Pipeline()
 
 	text, err := p.client.HGet(ctx, prefixText+key, id).Result()
@@ -291,31 +569,84 @@ func (p *Provider) Delete(ctx context.Context, key, id string) error {
 		return fmt.Errorf("failed to get text for deletion: %w", err)
 	}
 
-	if text != "" {
-		// Check if entry was indexed with case sensitivity
-		caseSensitive := false
-		meta, metaErr := p.client.HGet(ctx, prefixMeta+key, id).Result()
-		if metaErr == nil && meta == "1" {
-			caseSensitive = true
+	// Check if entry was indexed with case sensitivity
+	meta, _ := p.client.HGet(ctx, prefixMeta+key, id).Result()
+
+	strategies, err := p.client.HGet(ctx, prefixStrategies+key, id).Result()
+	if err != nil && err != redis.Nil {
+		return fmt.Errorf("failed to get strategies for deletion: %w", err)
+	}
+	p.addRemovalCommands(pipe, ctx, key, id, text, meta == "1", strategies)
+
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// DeleteMany removes entries in batches of up to batchSize, reading the stored
+// entries of each batch in one pipeline and removing them in another
+func (p *Provider) DeleteMany(ctx context.Context, key string, ids []string) error {
+	key = p.namespace(key)
+	for start := 0; start < len(ids); start += batchSize {
+		batch := ids[start:min(start+batchSize, len(ids))]
+
+		read := p.client.Pipeline()
+		texts := read.HMGet(ctx, prefixText+key, batch...)
+		metas := read.HMGet(ctx, prefixMeta+key, batch...)
+		strategies := read.HMGet(ctx, prefixStrategies+key, batch...)
+		if _, err := read.Exec(ctx); err != nil {
+			return fmt.Errorf("failed to get entries for deletion: %w", err)
 		}
 
-		textToDelete := text
-		if !caseSensitive {
-			textToDelete = strings.ToLower(text)
+		pipe := p.client.Pipeline()
+		for i, id := range batch {
+			text, _ := texts.Val()[i].(string)
+			meta, _ := metas.Val()[i].(string)
+			tags, _ := strategies.Val()[i].(string)
+			p.addRemovalCommands(pipe, ctx, key, id, text, meta == "1", tags)
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return fmt.Errorf("failed to execute delete pipeline: %w", err)
+		}
+	}
+	return nil
+}
+
+// addRemovalCommands queues the commands that remove an entry's tokens, given
+// its stored text, and its hash fields
+func (p *Provider) addRemovalCommands(
+	pipe redis.Pipeliner, ctx context.Context, key, id, text string, caseSensitive bool, strategies string,
+) {
+	if text != "" {
+		textToDelete := providers.SearchText(text, caseSensitive)
+		for _, tag := range deletionTags(strategies) {
+			if p.layout == LayoutScored {
+				removeScoredMembers(pipe, ctx, key, tag, textToDelete, id)
+			} else {
+				removePrefixMembers(pipe, ctx, prefixSet+key, tag, textToDelete, id)
+				removePositionalMembers(pipe, ctx, prefixSet+key, tag, textToDelete, id)
+				removeSuffixMembers(pipe, ctx, prefixSet+key, tag, textToDelete, id)
+				removeAnchoredMembers(pipe, ctx, prefixSet+key, tag, textToDelete, id)
+			}
 		}
-		removePrefixMembers(pipe, ctx, prefixSet+key, textToDelete, id)
-		removePositionalMembers(pipe, ctx, prefixSet+key, textToDelete, id)
 	}
 	pipe.HDel(ctx, prefixText+key, id)
 	pipe.HDel(ctx, prefixDisplay+key, id)
 	pipe.HDel(ctx, prefixMeta+key, id)
-
-	_, err = pipe.Exec(ctx)
-	return err
+	pipe.HDel(ctx, prefixHash+key, id)
+	pipe.HDel(ctx, prefixStrategies+key, id)
+	pipe.HDel(ctx, prefixPayload+key, id)
+	pipe.HDel(ctx, prefixScore+key, id)
 }
 
 // DeleteAll removes all entries for a given key
 func (p *Provider) DeleteAll(ctx context.Context, key string) error {
+	key = p.namespace(key)
+	if p.layout == LayoutScored {
+		if err := p.deleteScoredTokenSets(ctx, key); err != nil {
+			return err
+		}
+	}
+
 	pipe := p.client.Pipeline()
 
 	deleteAllKeysForNamespace(pipe, ctx, key)
@@ -324,11 +655,69 @@ func (p *Provider) DeleteAll(ctx context.Context, key string) error {
 	return err
 }
 
-// Close closes the Redis connection
+// Close closes the Redis connection. A client passed to NewWithClient is left
+// open for its owner to close
 func (p *Provider) Close() error {
+	if !p.ownsClient {
+		return nil
+	}
 	return p.client.Close()
 }
 
+// token is a single indexed token and the byte offset at which it starts
+type token struct {
+	text     string
+	position int
+}
+
+// tokenize splits text into the tokens stored for the given match strategy
+func tokenize(text string, options providers.IndexOptions) []token {
+	textToIndex := providers.SearchText(text, options.CaseSensitive)
+
+	var tokens []token
+	switch options.MatchStrategy {
+	case providers.MatchPrefix:
+		for i := 1; i <= len(textToIndex); i++ {
+			tokens = append(tokens, token{text: textToIndex[:i]})
+		}
+
+	case providers.MatchNGram:
+		n := getNGramSizeOrDefault(options.NGramSize)
+		for i := 0; i <= len(textToIndex)-n; i++ {
+			tokens = append(tokens, token{text: textToIndex[i : i+n], position: i})
+		}
+
+	case providers.MatchNOrMoreGram:
+		n := getNGramSizeOrDefault(options.NGramSize)
+		tokens = substringTokens(textToIndex, n)
+
+	case providers.MatchSubstring:
+		tokens = substringTokens(textToIndex, 1)
+
+	case providers.MatchSuffix:
+		for i := 0; i < len(textToIndex); i++ {
+			tokens = append(tokens, token{text: textToIndex[i:], position: i})
+		}
+
+	case providers.MatchAnchoredSubstring:
+		for _, span := range providers.AnchoredSubstrings(textToIndex, getNGramSizeOrDefault(options.NGramSize)) {
+			tokens = append(tokens, token{text: textToIndex[span.Start:span.End], position: span.Start})
+		}
+	}
+	return tokens
+}
+
+// substringTokens returns every substring of text at least minLength bytes long
+func substringTokens(text string, minLength int) []token {
+	var tokens []token
+	for start := 0; start < len(text); start++ {
+		for end := start + minLength; end <= len(text); end++ {
+			tokens = append(tokens, token{text: text[start:end], position: start})
+		}
+	}
+	return tokens
+}
+
 func createLexicographicStartKey(query string) string {
 	return fmt.Sprintf("[%s", query)
 }
@@ -370,10 +759,6 @@ func extractIDFromMember(member string, minParts int) string {
 	return ""
 }
 
-func isEmptySet(set map[string]bool) bool {
-	return len(set) == 0
-}
-
 func limitResults(ids []string, maxResults int) []string {
 	if len(ids) > maxResults {
 		return ids[:maxResults]
@@ -381,47 +766,26 @@ func limitResults(ids []string, maxResults int) []string {
 	return ids
 }
 
-func extractUniqueIDsFromResults(results []string, options providers.QueryOptions) []string {
-	uniqueIDs := make(map[string]bool)
-	var ids []string
-
-	minParts := getMinPartsForStrategy(options.MatchStrategy)
-
-	for _, result := range results {
-		id := extractIDFromMember(result, minParts)
-
-		if id != "" && !uniqueIDs[id] {
-			uniqueIDs[id] = true
-			ids = append(ids, id)
-			if len(ids) >= options.MaxResults {
-				break
-			}
-		}
-	}
-
-	return ids
-}
-
 func getMinPartsForStrategy(strategy providers.MatchStrategy) int {
-	if strategy == providers.MatchPrefix {
+	if strategy == providers.MatchPrefix || strategy == providers.MatchSuffix {
 		return minMemberPartsForID
 	}
 	return minMemberPartsForPositionalID
 }
 
-func removePrefixMembers(pipe redis.Pipeliner, ctx context.Context, key, text, id string) {
+func removePrefixMembers(pipe redis.Pipeliner, ctx context.Context, key, tag, text, id string) {
 	for i := 1; i <= len(text); i++ {
 		prefix := text[:i]
-		member := createPrefixMember(prefix, id)
+		member := createPrefixMember(tag+prefix, id)
 		pipe.ZRem(ctx, key, member)
 	}
 }
 
-func removePositionalMembers(pipe redis.Pipeliner, ctx context.Context, key, text, id string) {
+func removePositionalMembers(pipe redis.Pipeliner, ctx context.Context, key, tag, text, id string) {
 	for start := 0; start < len(text); start++ {
 		for end := start + 1; end <= len(text); end++ {
 			substring := text[start:end]
-			member := createPositionalMember(substring, id, start)
+			member := createPositionalMember(tag+substring, id, start)
 			pipe.ZRem(ctx, key, member)
 		}
 	}
@@ -432,30 +796,8 @@ func deleteAllKeysForNamespace(pipe redis.Pipeliner, ctx context.Context, key st
 	pipe.Del(ctx, prefixText+key)
 	pipe.Del(ctx, prefixDisplay+key)
 	pipe.Del(ctx, prefixMeta+key)
-}
-
-func copySet(source map[string]bool) map[string]bool {
-	result := make(map[string]bool)
-	for id := range source {
-		result[id] = true
-	}
-	return result
-}
-
-func removeNonIntersectingIDs(intersection map[string]bool, remainingSets []map[string]bool) {
-	for _, set := range remainingSets {
-		for id := range intersection {
-			if !set[id] {
-				delete(intersection, id)
-			}
-		}
-	}
-}
-
-func extractKeysFromSet(set map[string]bool) []string {
-	result := make([]string, 0, len(set))
-	for id := range set {
-		result = append(result, id)
-	}
-	return result
+	pipe.Del(ctx, prefixHash+key)
+	pipe.Del(ctx, prefixStrategies+key)
+	pipe.Del(ctx, prefixPayload+key)
+	pipe.Del(ctx, prefixScore+key)
 }
@@ -2,16 +2,25 @@ package redis
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"math"
 	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
 
+	"github.com/remiges-tech/autocomplete"
 	"github.com/remiges-tech/autocomplete/providers"
+	"github.com/remiges-tech/autocomplete/stress"
 )
 
 const testKey = "test"
@@ -910,3 +919,1480 @@ func TestRedisProvider_BackwardCompatibility(t *testing.T) {
 		}
 	})
 }
+
+func TestRedisProvider_ContentHash(t *testing.T) {
+	provider := getTestRedisClient(t)
+
+	ctx := context.Background()
+	key := testKey
+
+	_, exists, err := provider.ContentHash(ctx, key, "1")
+	if err != nil {
+		t.Fatalf("ContentHash() error = %v", err)
+	}
+	if exists {
+		t.Errorf("ContentHash() exists = true for missing entry")
+	}
+
+	err = provider.Index(ctx, key, "1", "John Doe", "John Doe", providers.IndexOptions{
+		Score:         1.0,
+		MatchStrategy: providers.MatchSubstring,
+		ContentHash:   "abc123",
+	})
+	if err != nil {
+		t.Fatalf("Failed to index entry: %v", err)
+	}
+
+	hash, exists, err := provider.ContentHash(ctx, key, "1")
+	if err != nil {
+		t.Fatalf("ContentHash() error = %v", err)
+	}
+	if !exists || hash != "abc123" {
+		t.Errorf("ContentHash() = (%q, %v), want (%q, true)", hash, exists, "abc123")
+	}
+
+	if err := provider.Delete(ctx, key, "1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	hash, exists, err = provider.ContentHash(ctx, key, "1")
+	if err != nil {
+		t.Fatalf("ContentHash() error = %v", err)
+	}
+	if exists || hash != "" {
+		t.Errorf("ContentHash() after delete = (%q, %v), want (\"\", false)", hash, exists)
+	}
+}
+
+func TestRedisProvider_GetEntry(t *testing.T) {
+	shared := getTestRedisClient(t)
+	scored := &Provider{client: shared.client, layout: LayoutScored, codec: shared.codec}
+
+	ctx := context.Background()
+	for name, provider := range map[string]*Provider{"lexicographic": shared, "scored": scored} {
+		if err := provider.DeleteAll(ctx, testKey); err != nil {
+			t.Fatalf("DeleteAll() error = %v", err)
+		}
+		options := providers.IndexOptions{Score: 4, MatchStrategy: providers.MatchSubstring, ContentHash: "h1", Metadata: `{"state":"MH"}`}
+		if err := provider.Index(ctx, testKey, "1", "Mumbai", "Mumbai GPO", options); err != nil {
+			t.Fatalf("Failed to index entry: %v", err)
+		}
+		if err := provider.IncrementScore(ctx, testKey, "1", 2); err != nil {
+			t.Fatalf("IncrementScore() error = %v", err)
+		}
+
+		entry, exists, err := provider.GetEntry(ctx, testKey, "1")
+		want := providers.StoredEntry{ID: "1", Text: "Mumbai", Display: "Mumbai GPO", ContentHash: "h1", Metadata: `{"state":"MH"}`, Score: 6}
+		if err != nil || !exists || entry != want {
+			t.Errorf("%s: GetEntry() = %+v, %t, %v; want %+v, true", name, entry, exists, err, want)
+		}
+		if _, exists, err := provider.GetEntry(ctx, testKey, "2"); err != nil || exists {
+			t.Errorf("%s: GetEntry() of unknown ID exists = %t, %v; want false", name, exists, err)
+		}
+	}
+}
+
+func TestRedisProvider_Exists(t *testing.T) {
+	provider := getTestRedisClient(t)
+	ctx := context.Background()
+
+	if exists, err := provider.Exists(ctx, testKey, "1"); err != nil || exists {
+		t.Errorf("Exists() before Index = %v, %v; want false", exists, err)
+	}
+	if err := provider.Index(ctx, testKey, "1", "Mumbai", "Mumbai", providers.IndexOptions{Score: 1.0}); err != nil {
+		t.Fatalf("Failed to index entry: %v", err)
+	}
+	if exists, err := provider.Exists(ctx, testKey, "1"); err != nil || !exists {
+		t.Errorf("Exists() after Index = %v, %v; want true", exists, err)
+	}
+	if count, err := provider.CountEntries(ctx, testKey); err != nil || count != 1 {
+		t.Errorf("CountEntries() = %d, %v; want 1", count, err)
+	}
+	if err := provider.Delete(ctx, testKey, "1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if exists, err := provider.Exists(ctx, testKey, "1"); err != nil || exists {
+		t.Errorf("Exists() after Delete = %v, %v; want false", exists, err)
+	}
+	if count, err := provider.CountEntries(ctx, testKey); err != nil || count != 0 {
+		t.Errorf("CountEntries() after Delete = %d, %v; want 0", count, err)
+	}
+}
+
+func TestRedisProvider_IndexStats(t *testing.T) {
+	shared := getTestRedisClient(t)
+	scored := &Provider{client: shared.client, layout: LayoutScored, codec: shared.codec}
+
+	ctx := context.Background()
+	for name, provider := range map[string]*Provider{"lexicographic": shared, "scored": scored} {
+		if err := provider.DeleteAll(ctx, testKey); err != nil {
+			t.Fatalf("DeleteAll() error = %v", err)
+		}
+		empty, err := provider.IndexStats(ctx, testKey)
+		if err != nil || empty.Entries != 0 || empty.Tokens != 0 || empty.Bytes != 0 {
+			t.Errorf("%s: IndexStats() of empty namespace = %+v, %v; want zero", name, empty, err)
+		}
+
+		options := providers.IndexOptions{Score: 1, MatchStrategy: providers.MatchPrefix}
+		if err := provider.Index(ctx, testKey, "1", "Pune", "Pune", options); err != nil {
+			t.Fatalf("Failed to index entry: %v", err)
+		}
+		stats, err := provider.IndexStats(ctx, testKey)
+		if err != nil {
+			t.Fatalf("%s: IndexStats() error = %v", name, err)
+		}
+		if stats.Entries != 1 || stats.Tokens == 0 || stats.Bytes == 0 || stats.Details["layout"] != name {
+			t.Errorf("%s: IndexStats() = %+v; want 1 entry, tokens, bytes, and the layout", name, stats)
+		}
+		if name == "scored" && (stats.Tokens != 4 || stats.Details["distinct_tokens"] != "4") {
+			t.Errorf("%s: IndexStats() = %+v; want 4 token sets of one entry", name, stats)
+		}
+	}
+}
+
+func TestRedisProvider_IndexAtomic(t *testing.T) {
+	provider := getTestRedisClient(t)
+
+	ctx := context.Background()
+	key := testKey
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchSubstring}
+
+	err := provider.IndexAtomic(ctx, key, []providers.IndexEntry{
+		{ID: "1", Text: "Mumbai", Display: "Mumbai", Options: options},
+		{ID: "2", Text: "Bombay", Display: "Mumbai (Bombay)", Options: options},
+	})
+	if err != nil {
+		t.Fatalf("IndexAtomic() error = %v", err)
+	}
+
+	results, err := provider.Query(ctx, key, "mba", providers.QueryOptions{
+		MaxResults:    10,
+		MatchStrategy: providers.MatchSubstring,
+	})
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("Expected 2 results after IndexAtomic, got %d", len(results))
+	}
+}
+
+func TestRedisProvider_BatchIndex(t *testing.T) {
+	provider := getTestRedisClient(t)
+
+	ctx := context.Background()
+	key := testKey
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchPrefix}
+
+	entries := make([]providers.IndexEntry, batchSize+1)
+	for i := range entries {
+		id := strconv.Itoa(i)
+		entries[i] = providers.IndexEntry{ID: id, Text: "pin " + id, Display: "PIN " + id, Options: options}
+	}
+	if err := provider.BatchIndex(ctx, key, entries); err != nil {
+		t.Fatalf("BatchIndex() error = %v", err)
+	}
+
+	last := strconv.Itoa(batchSize)
+	results, err := provider.Query(ctx, key, "pin "+last, providers.QueryOptions{
+		MaxResults:    10,
+		MatchStrategy: providers.MatchPrefix,
+	})
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != last {
+		t.Errorf("Query() for the entry in the second pipeline = %+v, want entry %s", results, last)
+	}
+}
+
+func TestRedisProvider_DeleteMany(t *testing.T) {
+	provider := getTestRedisClient(t)
+
+	ctx := context.Background()
+	key := testKey
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchSubstring}
+	for _, city := range []string{"Mumbai", "Navi Mumbai", "Pune"} {
+		if err := provider.Index(ctx, key, city, city, city, options); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	if err := provider.DeleteMany(ctx, key, []string{"Mumbai", "Pune", "missing"}); err != nil {
+		t.Fatalf("DeleteMany() error = %v", err)
+	}
+
+	results, err := provider.Query(ctx, key, "u", providers.QueryOptions{
+		MaxResults:    10,
+		MatchStrategy: providers.MatchSubstring,
+	})
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "Navi Mumbai" {
+		t.Errorf("Query() after DeleteMany = %+v, want only Navi Mumbai", results)
+	}
+	if _, exists, err := provider.ContentHash(ctx, key, "Pune"); err != nil || exists {
+		t.Errorf("ContentHash() after DeleteMany = %v, %v; want a missing entry", exists, err)
+	}
+}
+
+func TestRedisProvider_QueryStats(t *testing.T) {
+	shared := getTestRedisClient(t)
+	var stats []QueryStats
+	provider := &Provider{
+		client: shared.client,
+		codec:  newValueCodec(shared.client, CompressionNone, 0),
+		onQueryStats: func(ctx context.Context, s QueryStats) {
+			stats = append(stats, s)
+		},
+	}
+
+	ctx := context.Background()
+	key := testKey
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchNGram, NGramSize: 3}
+	for _, city := range []string{"Mumbai", "Navi Mumbai", "Pune"} {
+		if err := provider.Index(ctx, key, city, city, city, options); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	results, err := provider.Query(ctx, key, "mumx", providers.QueryOptions{
+		MaxResults:    10,
+		MatchStrategy: providers.MatchNGram,
+		NGramSize:     3,
+	})
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if len(results) != 0 || len(stats) != 1 {
+		t.Fatalf("Query() = %+v with %d stats reports, want no results and 1 report", results, len(stats))
+	}
+	want := []TokenStats{{Token: "mum", Candidates: 2}, {Token: "umx", Candidates: 0}}
+	if !reflect.DeepEqual(stats[0].Tokens, want) || stats[0].Query != "mumx" || stats[0].Results != 0 {
+		t.Errorf("QueryStats = %+v, want tokens %+v", stats[0], want)
+	}
+}
+
+func TestRedisProvider_NGramProximity(t *testing.T) {
+	provider := getTestRedisClient(t)
+
+	ctx := context.Background()
+	key := testKey
+	if err := provider.DeleteAll(ctx, key); err != nil {
+		t.Fatalf("DeleteAll() error = %v", err)
+	}
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchNGram, NGramSize: 3}
+	// Every entry holds both n-grams of "ab12"; only "3" holds them together.
+	for id, text := range map[string]string{"1": "AB1 XB12", "2": "AB1 B12", "3": "MH AB12"} {
+		if err := provider.Index(ctx, key, id, text, text, options); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	results, err := provider.Query(ctx, key, "ab12", providers.QueryOptions{
+		MaxResults:    10,
+		MatchStrategy: providers.MatchNGram,
+		NGramSize:     3,
+	})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	var got []string
+	var scores []float64
+	for _, result := range results {
+		got = append(got, result.ID)
+		scores = append(scores, result.Score)
+	}
+	if want := []string{"3", "2", "1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Query() IDs = %v, want %v", got, want)
+	}
+	if want := []float64{2, 1.25, 1.2}; !reflect.DeepEqual(scores, want) {
+		t.Errorf("Query() scores = %v, want %v", scores, want)
+	}
+
+	results, err = provider.Query(ctx, key, "ab12", providers.QueryOptions{
+		MaxResults:    1,
+		MatchStrategy: providers.MatchNGram,
+		NGramSize:     3,
+		ExplainScores: true,
+	})
+	if err != nil {
+		t.Fatalf("Query() with ExplainScores error = %v", err)
+	}
+	want := providers.ScoreBreakdown{Entry: 1, Proximity: 1}
+	if len(results) != 1 || results[0].Breakdown == nil || *results[0].Breakdown != want {
+		t.Errorf("Query() with ExplainScores = %+v, want breakdown %+v", results, want)
+	}
+
+	// The entry score comes from the score hash and outweighs proximity
+	options.Score = 2
+	if err := provider.Index(ctx, key, "1", "AB1 XB12", "AB1 XB12", options); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	results, err = provider.Query(ctx, key, "ab12", providers.QueryOptions{
+		MaxResults:    10,
+		MatchStrategy: providers.MatchNGram,
+		NGramSize:     3,
+		ExplainScores: true,
+	})
+	if err != nil {
+		t.Fatalf("Query() after rescoring error = %v", err)
+	}
+	if got, want := getResultIDs(results), []string{"1", "3", "2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Query() after rescoring = %v, want %v", got, want)
+	}
+	want = providers.ScoreBreakdown{Entry: 2, Proximity: 0.2}
+	if len(results) == 0 || results[0].Breakdown == nil || *results[0].Breakdown != want {
+		t.Errorf("Query() after rescoring = %+v, want breakdown %+v first", results, want)
+	}
+}
+
+func TestRedisProvider_MatchSuffix(t *testing.T) {
+	provider := getTestRedisClient(t)
+
+	ctx := context.Background()
+	key := testKey
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchSuffix}
+	for id, plate := range map[string]string{"1": "MH12AB4521", "2": "KA01CD4521", "3": "MH12AB4522"} {
+		if err := provider.Index(ctx, key, id, plate, plate, options); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	queryOptions := providers.QueryOptions{MaxResults: 10, MatchStrategy: providers.MatchSuffix}
+	results, err := provider.Query(ctx, key, "4521", queryOptions)
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("Query(4521) returned %d results, want 2", len(results))
+	}
+
+	if err := provider.Delete(ctx, key, "1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	results, err = provider.Query(ctx, key, "4521", queryOptions)
+	if err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "2" {
+		t.Errorf("Query(4521) after delete = %+v, want only entry 2", results)
+	}
+
+	stats, err := provider.Maintain(ctx, key, providers.MaintenanceOptions{})
+	if err != nil {
+		t.Fatalf("Maintain() error = %v", err)
+	}
+	if stats.Removed != 0 {
+		t.Errorf("Maintain() removed %d current suffix tokens, want 0", stats.Removed)
+	}
+}
+
+func TestRedisProvider_ScoreRanking(t *testing.T) {
+	provider := getTestRedisClient(t)
+	ctx := context.Background()
+	key := testKey
+	if err := provider.DeleteAll(ctx, key); err != nil {
+		t.Fatalf("DeleteAll() error = %v", err)
+	}
+
+	entries := []struct {
+		id, text string
+		score    float64
+	}{
+		{"1", "Mumbai", 1},
+		{"2", "Mumbra", 50},
+		{"3", "Mundra", 7},
+	}
+	for _, e := range entries {
+		options := providers.IndexOptions{Score: e.score, MatchStrategy: providers.MatchPrefix}
+		if err := provider.Index(ctx, key, e.id, e.text, e.text, options); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	results, err := provider.Query(ctx, key, "mu", providers.QueryOptions{MaxResults: 2, MatchStrategy: providers.MatchPrefix})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	var got []string
+	for _, result := range results {
+		got = append(got, result.ID)
+	}
+	if want := []string{"2", "3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Query() IDs = %v, want %v", got, want)
+	}
+	if len(results) > 0 && results[0].Score != 50 {
+		t.Errorf("Query() top score = %v, want 50", results[0].Score)
+	}
+}
+
+func TestRedisProvider_ScoresAcrossPrefixes(t *testing.T) {
+	shared := getTestRedisClient(t)
+	ranked := &Provider{client: shared.client, rankingScript: redis.NewScript(DefaultRankingScript), codec: shared.codec}
+
+	ctx := context.Background()
+	key := testKey
+	// High and low scores on both sides of the queried ranges, which a scan of a
+	// sorted set with mixed member scores would wrongly include or miss
+	entries := []struct {
+		id, text string
+		score    float64
+	}{
+		{"1", "Agra", 100},
+		{"2", "Mumbai", 1},
+		{"3", "Mumbra", 3},
+		{"4", "Mundra", 0.25},
+		{"5", "Nagpur", 50},
+		{"6", "Pune", 7},
+		{"7", "Zirakpur", -5},
+	}
+	for name, provider := range map[string]*Provider{"client-side": shared, "ranking script": ranked} {
+		if err := provider.DeleteAll(ctx, key); err != nil {
+			t.Fatalf("DeleteAll() error = %v", err)
+		}
+		for _, e := range entries {
+			options := providers.IndexOptions{Score: e.score, MatchStrategy: providers.MatchPrefix}
+			if err := provider.Index(ctx, key, e.id, e.text, e.text, options); err != nil {
+				t.Fatalf("Index() error = %v", err)
+			}
+		}
+
+		members, err := provider.client.ZRangeWithScores(ctx, prefixSet+key, 0, -1).Result()
+		if err != nil {
+			t.Fatalf("ZRangeWithScores() error = %v", err)
+		}
+		for _, member := range members {
+			if member.Score != lexMemberScore {
+				t.Fatalf("%s: member %v has score %v, want %v", name, member.Member, member.Score, lexMemberScore)
+			}
+		}
+
+		for query, want := range map[string][]string{
+			"mu": {"3", "2", "4"},
+			"a":  {"1"},
+			"n":  {"5"},
+			"p":  {"6"},
+			"z":  {"7"},
+		} {
+			results, err := provider.Query(ctx, key, query, providers.QueryOptions{MaxResults: 10, MatchStrategy: providers.MatchPrefix})
+			if err != nil {
+				t.Fatalf("%s: Query(%q) error = %v", name, query, err)
+			}
+			if got := getResultIDs(results); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: Query(%q) = %v, want %v", name, query, got, want)
+			}
+		}
+	}
+
+	results, err := shared.Query(ctx, key, "mu", providers.QueryOptions{MaxResults: 10, MatchStrategy: providers.MatchPrefix})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	for i, want := range []float64{3, 1, 0.25} {
+		if i < len(results) && results[i].Score != want {
+			t.Errorf("Query() score of %s = %v, want the entry score %v", results[i].ID, results[i].Score, want)
+		}
+	}
+}
+
+func TestRedisProvider_UpdateAndIncrementScore(t *testing.T) {
+	shared := getTestRedisClient(t)
+	scored := &Provider{client: shared.client, layout: LayoutScored, codec: shared.codec}
+
+	ctx := context.Background()
+	key := testKey
+	for name, provider := range map[string]*Provider{"lexicographic": shared, "scored": scored} {
+		strategies := map[string]providers.MatchStrategy{
+			"prefix": providers.MatchPrefix, "substring": providers.MatchSubstring, "suffix": providers.MatchSuffix,
+		}
+		for strategyName, strategy := range strategies {
+			t.Run(name+"/"+strategyName, func(t *testing.T) {
+				if err := provider.DeleteAll(ctx, key); err != nil {
+					t.Fatalf("DeleteAll() error = %v", err)
+				}
+				options := providers.IndexOptions{Score: 0.5, MatchStrategy: strategy}
+				for id, city := range map[string]string{"1": "Mumbai", "2": "Mumbra"} {
+					if err := provider.Index(ctx, key, id, city, city, options); err != nil {
+						t.Fatalf("Index() error = %v", err)
+					}
+				}
+				if err := provider.UpdateScore(ctx, key, "2", 3); err != nil {
+					t.Fatalf("UpdateScore() error = %v", err)
+				}
+				if err := provider.UpdateScore(ctx, key, "missing", 3); err != nil {
+					t.Errorf("UpdateScore() of unknown ID error = %v", err)
+				}
+
+				query := "mu"
+				if strategy == providers.MatchSuffix {
+					query = "a"
+				}
+				results, err := provider.Query(ctx, key, query, providers.QueryOptions{MaxResults: 10, MatchStrategy: strategy})
+				if err != nil {
+					t.Fatalf("Query() error = %v", err)
+				}
+				if len(results) != 2 || results[0].ID != "2" || results[0].Score < 3 || results[0].Score >= 4 {
+					t.Errorf("Query() = %+v, want entry 2 first with score 3", results)
+				}
+
+				for range 2 {
+					if err := provider.IncrementScore(ctx, key, "1", 2); err != nil {
+						t.Fatalf("IncrementScore() error = %v", err)
+					}
+				}
+				if err := provider.IncrementScore(ctx, key, "missing", 2); err != nil {
+					t.Errorf("IncrementScore() of unknown ID error = %v", err)
+				}
+				results, err = provider.Query(ctx, key, query, providers.QueryOptions{MaxResults: 10, MatchStrategy: strategy})
+				if err != nil {
+					t.Fatalf("Query() after IncrementScore error = %v", err)
+				}
+				if len(results) != 2 || results[0].ID != "1" || results[0].Score < 4.5 || results[0].Score >= 5.5 {
+					t.Errorf("Query() after IncrementScore = %+v, want entry 1 first with score 4.5", results)
+				}
+			})
+		}
+	}
+}
+
+func TestRedisProvider_UpdateScoreUsesScoreHash(t *testing.T) {
+	shared := getTestRedisClient(t)
+	scored := &Provider{client: shared.client, layout: LayoutScored, codec: shared.codec}
+
+	ctx := context.Background()
+	key := testKey
+	for name, provider := range map[string]*Provider{"lexicographic": shared, "scored": scored} {
+		if err := provider.DeleteAll(ctx, key); err != nil {
+			t.Fatalf("DeleteAll() error = %v", err)
+		}
+		options := providers.IndexOptions{Score: 1, MatchStrategy: providers.MatchPrefix}
+		for id, city := range map[string]string{"1": "Agra", "2": "Mumbai", "3": "Mumbra", "4": "Pune"} {
+			if err := provider.Index(ctx, key, id, city, city, options); err != nil {
+				t.Fatalf("Index() error = %v", err)
+			}
+		}
+		for id, score := range map[string]float64{"1": 90, "2": 0.5, "3": 1.0 / 3, "4": -1} {
+			if err := provider.UpdateScore(ctx, key, id, score); err != nil {
+				t.Fatalf("%s: UpdateScore(%s) error = %v", name, id, err)
+			}
+		}
+
+		for query, want := range map[string][]string{"mu": {"2", "3"}, "a": {"1"}, "p": {"4"}} {
+			results, err := provider.Query(ctx, key, query, providers.QueryOptions{MaxResults: 10, MatchStrategy: providers.MatchPrefix})
+			if err != nil {
+				t.Fatalf("%s: Query(%q) error = %v", name, query, err)
+			}
+			if got := getResultIDs(results); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: Query(%q) after UpdateScore = %v, want %v", name, query, got, want)
+			}
+		}
+		if entry, _, err := provider.GetEntry(ctx, key, "3"); err != nil || entry.Score != 1.0/3 {
+			t.Errorf("%s: GetEntry() score = %v, %v; want 1/3", name, entry.Score, err)
+		}
+
+		// Updating a deleted entry must not recreate its score
+		if err := provider.Delete(ctx, key, "4"); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if err := provider.UpdateScore(ctx, key, "4", 5); err != nil {
+			t.Fatalf("%s: UpdateScore() of a deleted entry error = %v", name, err)
+		}
+		if exists, err := provider.client.HExists(ctx, prefixScore+key, "4").Result(); err != nil || exists {
+			t.Errorf("%s: UpdateScore() of a deleted entry stored a score: %v, %v", name, exists, err)
+		}
+
+		if provider.layout == LayoutLexicographic {
+			members, err := provider.client.ZRangeWithScores(ctx, prefixSet+key, 0, -1).Result()
+			if err != nil {
+				t.Fatalf("ZRangeWithScores() error = %v", err)
+			}
+			for _, member := range members {
+				if member.Score != lexMemberScore {
+					t.Errorf("member %v has score %v after UpdateScore, want %v", member.Member, member.Score, lexMemberScore)
+				}
+			}
+		}
+	}
+}
+
+func TestRedisProvider_IncrementScoreUsesScoreHash(t *testing.T) {
+	shared := getTestRedisClient(t)
+	scored := &Provider{client: shared.client, layout: LayoutScored, codec: shared.codec}
+
+	ctx := context.Background()
+	key := testKey
+	for name, provider := range map[string]*Provider{"lexicographic": shared, "scored": scored} {
+		if err := provider.DeleteAll(ctx, key); err != nil {
+			t.Fatalf("DeleteAll() error = %v", err)
+		}
+		options := providers.IndexOptions{Score: 1, MatchStrategy: providers.MatchPrefix}
+		for id, city := range map[string]string{"1": "Agra", "2": "Mumbai", "3": "Mumbra", "4": "Pune"} {
+			if err := provider.Index(ctx, key, id, city, city, options); err != nil {
+				t.Fatalf("Index() error = %v", err)
+			}
+		}
+
+		// Concurrent increments all count
+		var wg sync.WaitGroup
+		errs := make(chan error, 20)
+		for range 20 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs <- provider.IncrementScore(ctx, key, "3", 0.5)
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			if err != nil {
+				t.Fatalf("%s: IncrementScore() error = %v", name, err)
+			}
+		}
+		if entry, _, err := provider.GetEntry(ctx, key, "3"); err != nil || entry.Score != 11 {
+			t.Errorf("%s: GetEntry() score after 20 increments = %v, %v; want 11", name, entry.Score, err)
+		}
+
+		// An entry without a score hash field has the default score
+		if err := provider.client.HDel(ctx, prefixScore+key, "1").Err(); err != nil {
+			t.Fatalf("HDel() error = %v", err)
+		}
+		if err := provider.IncrementScore(ctx, key, "1", 2); err != nil {
+			t.Fatalf("%s: IncrementScore() error = %v", name, err)
+		}
+		if entry, _, err := provider.GetEntry(ctx, key, "1"); err != nil || entry.Score != 3 {
+			t.Errorf("%s: GetEntry() score of an entry without a stored score = %v, %v; want 3", name, entry.Score, err)
+		}
+
+		for query, want := range map[string][]string{"mu": {"3", "2"}, "a": {"1"}, "p": {"4"}} {
+			results, err := provider.Query(ctx, key, query, providers.QueryOptions{MaxResults: 10, MatchStrategy: providers.MatchPrefix})
+			if err != nil {
+				t.Fatalf("%s: Query(%q) error = %v", name, query, err)
+			}
+			if got := getResultIDs(results); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: Query(%q) after IncrementScore = %v, want %v", name, query, got, want)
+			}
+		}
+
+		// Incrementing a deleted entry must not recreate its score
+		if err := provider.Delete(ctx, key, "4"); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if err := provider.IncrementScore(ctx, key, "4", 1); err != nil {
+			t.Fatalf("%s: IncrementScore() of a deleted entry error = %v", name, err)
+		}
+		if exists, err := provider.client.HExists(ctx, prefixScore+key, "4").Result(); err != nil || exists {
+			t.Errorf("%s: IncrementScore() of a deleted entry stored a score: %v, %v", name, exists, err)
+		}
+	}
+}
+
+func TestRedisProvider_Metadata(t *testing.T) {
+	shared := getTestRedisClient(t)
+	ranked := &Provider{client: shared.client, rankingScript: redis.NewScript(DefaultRankingScript), codec: shared.codec}
+	scored := &Provider{client: shared.client, layout: LayoutScored, codec: shared.codec}
+
+	ctx := context.Background()
+	key := testKey
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchPrefix, Metadata: `{"state":"MH"}`}
+	queryOptions := providers.QueryOptions{MaxResults: 10, MatchStrategy: providers.MatchPrefix}
+
+	for name, provider := range map[string]*Provider{"lexicographic": shared, "ranked": ranked, "scored": scored} {
+		t.Run(name, func(t *testing.T) {
+			if err := provider.DeleteAll(ctx, key); err != nil {
+				t.Fatalf("DeleteAll() error = %v", err)
+			}
+			if err := provider.Index(ctx, key, "1", "Mumbai", "Mumbai", options); err != nil {
+				t.Fatalf("Index() error = %v", err)
+			}
+			if err := provider.Index(ctx, key, "2", "Mumbra", "Mumbra", providers.IndexOptions{Score: 1.0}); err != nil {
+				t.Fatalf("Index() error = %v", err)
+			}
+
+			results, err := provider.Query(ctx, key, "mum", queryOptions)
+			if err != nil {
+				t.Fatalf("Query() error = %v", err)
+			}
+			got := make(map[string]string)
+			for _, result := range results {
+				got[result.ID] = result.Metadata
+			}
+			if want := map[string]string{"1": options.Metadata, "2": ""}; !reflect.DeepEqual(got, want) {
+				t.Errorf("Query() metadata = %v, want %v", got, want)
+			}
+
+			filtered := queryOptions
+			filtered.MaxResults = 1
+			filtered.FilterMetadata = map[string]interface{}{"state": "MH"}
+			results, err = provider.Query(ctx, key, "mum", filtered)
+			if err != nil {
+				t.Fatalf("Query() with filter error = %v", err)
+			}
+			if len(results) != 1 || results[0].ID != "1" {
+				t.Errorf("Query() with filter = %+v, want only entry 1", results)
+			}
+
+			if err := provider.Delete(ctx, key, "1"); err != nil {
+				t.Fatalf("Delete() error = %v", err)
+			}
+			if n := provider.client.HLen(ctx, prefixPayload+key).Val(); n != 0 {
+				t.Errorf("metadata payloads left after delete: %d", n)
+			}
+		})
+	}
+}
+
+func TestRedisProvider_MatchAnchoredSubstring(t *testing.T) {
+	shared := getTestRedisClient(t)
+	scored := &Provider{client: shared.client, layout: LayoutScored, codec: shared.codec}
+
+	ctx := context.Background()
+	key := testKey
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchAnchoredSubstring, NGramSize: 3}
+	queryOptions := providers.QueryOptions{MaxResults: 10, MatchStrategy: providers.MatchAnchoredSubstring, NGramSize: 3}
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"pun", []string{"1"}},
+		{"une", []string{"1"}},
+		{"pune ind", []string{"1"}},
+		{"un", []string{}},
+		{"e i", []string{}},
+	}
+
+	for name, provider := range map[string]*Provider{"lexicographic": shared, "scored": scored} {
+		t.Run(name, func(t *testing.T) {
+			if err := provider.DeleteAll(ctx, key); err != nil {
+				t.Fatalf("DeleteAll() error = %v", err)
+			}
+			if err := provider.Index(ctx, key, "1", "Pune India", "Pune India", options); err != nil {
+				t.Fatalf("Index() error = %v", err)
+			}
+			for _, tt := range tests {
+				results, err := provider.Query(ctx, key, tt.query, queryOptions)
+				if err != nil {
+					t.Fatalf("Query(%q) error = %v", tt.query, err)
+				}
+				got := make([]string, len(results))
+				for i, result := range results {
+					got[i] = result.ID
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("Query(%q) = %v, want %v", tt.query, got, tt.want)
+				}
+			}
+
+			stats, err := provider.Maintain(ctx, key, providers.MaintenanceOptions{})
+			if err != nil {
+				t.Fatalf("Maintain() error = %v", err)
+			}
+			if stats.Removed != 0 {
+				t.Errorf("Maintain() removed %d current tokens, want 0", stats.Removed)
+			}
+			if err := provider.Delete(ctx, key, "1"); err != nil {
+				t.Fatalf("Delete() error = %v", err)
+			}
+			if name == "lexicographic" {
+				if n := provider.client.ZCard(ctx, prefixSet+key).Val(); n != 0 {
+					t.Errorf("members left after delete: %d", n)
+				}
+			}
+		})
+	}
+}
+
+func TestRedisProvider_RankingScript(t *testing.T) {
+	shared := getTestRedisClient(t)
+	provider := &Provider{
+		client:        shared.client,
+		rankingScript: redis.NewScript(DefaultRankingScript),
+		codec:         newValueCodec(shared.client, CompressionNone, 0),
+	}
+
+	ctx := context.Background()
+	key := testKey
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchSubstring}
+	for _, e := range []struct{ id, text string }{
+		{"1", "Navi Mumbai"},
+		{"2", "Mumbai"},
+		{"3", "Mumbai Central Station"},
+	} {
+		if err := provider.Index(ctx, key, e.id, e.text, e.text, options); err != nil {
+			t.Fatalf("Failed to index entry: %v", err)
+		}
+	}
+
+	results, err := provider.Query(ctx, key, "mum", providers.QueryOptions{
+		MaxResults:    2,
+		MatchStrategy: providers.MatchSubstring,
+	})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+
+	gotIDs := getResultIDs(results)
+	wantIDs := []string{"2", "3"}
+	if len(gotIDs) != len(wantIDs) || gotIDs[0] != wantIDs[0] || gotIDs[1] != wantIDs[1] {
+		t.Errorf("Query() with ranking script IDs = %v, want %v", gotIDs, wantIDs)
+	}
+	if results[0].Score <= results[1].Score {
+		t.Errorf("Query() with ranking script scores not descending: %v", results)
+	}
+}
+
+func TestRedisProvider_ScoredLayout(t *testing.T) {
+	shared := getTestRedisClient(t)
+	provider := &Provider{client: shared.client, layout: LayoutScored, codec: newValueCodec(shared.client, CompressionNone, 0)}
+
+	ctx := context.Background()
+	key := testKey
+
+	strategies := []providers.MatchStrategy{
+		providers.MatchPrefix,
+		providers.MatchNGram,
+		providers.MatchNOrMoreGram,
+		providers.MatchSubstring,
+	}
+	queries := map[providers.MatchStrategy]string{
+		providers.MatchPrefix:      "mum",
+		providers.MatchNGram:       "umba",
+		providers.MatchNOrMoreGram: "mumb",
+		providers.MatchSubstring:   "mum",
+	}
+
+	for _, strategy := range strategies {
+		if err := provider.DeleteAll(ctx, key); err != nil {
+			t.Fatalf("DeleteAll() error = %v", err)
+		}
+
+		options := providers.IndexOptions{Score: 1.0, MatchStrategy: strategy, NGramSize: 3}
+		for _, e := range []struct{ id, text string }{
+			{"1", "Mumbai Central Station"},
+			{"2", "Mumbai"},
+			{"3", "Navi Mumbai"},
+		} {
+			if err := provider.Index(ctx, key, e.id, e.text, e.text, options); err != nil {
+				t.Fatalf("Failed to index entry: %v", err)
+			}
+		}
+
+		results, err := provider.Query(ctx, key, queries[strategy], providers.QueryOptions{
+			MaxResults:    10,
+			MatchStrategy: strategy,
+			NGramSize:     3,
+		})
+		if err != nil {
+			t.Fatalf("strategy %d: Query() error = %v", strategy, err)
+		}
+		if len(results) == 0 || results[0].ID != "2" {
+			t.Errorf("strategy %d: Query() = %v, want entry 2 ranked first", strategy, getResultIDs(results))
+		}
+
+		if err := provider.Delete(ctx, key, "2"); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		results, err = provider.Query(ctx, key, queries[strategy], providers.QueryOptions{
+			MaxResults:    10,
+			MatchStrategy: strategy,
+			NGramSize:     3,
+		})
+		if err != nil {
+			t.Fatalf("strategy %d: Query() after delete error = %v", strategy, err)
+		}
+		for _, r := range results {
+			if r.ID == "2" {
+				t.Errorf("strategy %d: deleted entry 2 still returned", strategy)
+			}
+		}
+	}
+
+	if err := provider.DeleteAll(ctx, key); err != nil {
+		t.Fatalf("DeleteAll() error = %v", err)
+	}
+	keys, err := provider.client.Keys(ctx, "ac:tok*").Result()
+	if err != nil {
+		t.Fatalf("Keys() error = %v", err)
+	}
+	if len(keys) != 0 {
+		t.Errorf("DeleteAll() left %d token keys behind", len(keys))
+	}
+}
+
+func TestRedisProvider_ScoredLayoutEntryScores(t *testing.T) {
+	shared := getTestRedisClient(t)
+	provider := &Provider{client: shared.client, layout: LayoutScored, codec: shared.codec}
+
+	ctx := context.Background()
+	key := testKey
+	if err := provider.DeleteAll(ctx, key); err != nil {
+		t.Fatalf("DeleteAll() error = %v", err)
+	}
+	// Fractional entry scores outrank an earlier match position and a shorter text
+	for _, e := range []struct {
+		id, text string
+		score    float64
+	}{
+		{"1", "Mumbai", 1.0 / 3},
+		{"2", "Navi Mumbai Central", 0.5},
+		{"3", "Mumbra", -2},
+	} {
+		options := providers.IndexOptions{Score: e.score, MatchStrategy: providers.MatchSubstring}
+		if err := provider.Index(ctx, key, e.id, e.text, e.text, options); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	results, err := provider.Query(ctx, key, "mu", providers.QueryOptions{MaxResults: 10, MatchStrategy: providers.MatchSubstring})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if got, want := getResultIDs(results), []string{"2", "1", "3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Query() = %v, want %v", got, want)
+	}
+	wantScores := map[string]float64{"1": 1.0 / 3, "2": 0.5, "3": -2}
+	for _, result := range results {
+		if result.Score != wantScores[result.ID] {
+			t.Errorf("Query() score of %s = %v, want the entry score %v", result.ID, result.Score, wantScores[result.ID])
+		}
+	}
+}
+
+func TestScoreCode(t *testing.T) {
+	scores := []float64{
+		math.Inf(-1), -1e300, -5, -1, -0.5, -1e-300, 0, 1e-300, 1e-7,
+		1.0 / 3, 0.5, 1, 1 + 1e-6, 2, 1e6, 1e300, math.Inf(1),
+	}
+	for i := 1; i < len(scores); i++ {
+		lower, higher := scoreCode(scores[i-1]), scoreCode(scores[i])
+		if lower > higher {
+			t.Errorf("scoreCode(%v) = %v > scoreCode(%v) = %v", scores[i-1], lower, scores[i], higher)
+		}
+		if math.Abs(scores[i-1]) < 1e38 && math.Abs(scores[i]) < 1e38 && math.Abs(scores[i]) > 1e-37 && lower == higher {
+			t.Errorf("scoreCode(%v) = scoreCode(%v) = %v, want distinct codes", scores[i-1], scores[i], lower)
+		}
+	}
+	// Composite scores must be integers that float64 holds exactly
+	for _, score := range scores {
+		composite := compositeScore(score, 0, 0)
+		if composite != math.Trunc(composite) || composite < 0 || composite >= 1<<53 {
+			t.Errorf("compositeScore(%v) = %v, want an integer in [0, 2^53)", score, composite)
+		}
+	}
+	if got, want := compositeScore(0.5, 900, 900), compositeScore(1.0/3, 0, 1); got <= want {
+		t.Errorf("compositeScore(0.5, ...) = %v <= compositeScore(1/3, ...) = %v", got, want)
+	}
+}
+
+func TestRedisProvider_Compression(t *testing.T) {
+	shared := getTestRedisClient(t)
+
+	ctx := context.Background()
+	key := testKey
+	longDisplay := strings.Repeat("400001 - Mumbai GPO, Mumbai, Maharashtra; ", 10)
+
+	for _, compression := range []Compression{CompressionSnappy, CompressionZstd} {
+		provider := &Provider{client: shared.client, codec: newValueCodec(shared.client, compression, 0)}
+		if err := provider.DeleteAll(ctx, key); err != nil {
+			t.Fatalf("DeleteAll() error = %v", err)
+		}
+
+		options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchPrefix}
+		if err := provider.Index(ctx, key, "long", "Mumbai GPO", longDisplay, options); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+		if err := provider.Index(ctx, key, "short", "Mumbai", "Mumbai", options); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+
+		stored, err := provider.client.HGet(ctx, prefixDisplay+key, "long").Result()
+		if err != nil {
+			t.Fatalf("HGet() error = %v", err)
+		}
+		if len(stored) >= len(longDisplay) {
+			t.Errorf("compression %d: stored display is %d bytes, want fewer than %d", compression, len(stored), len(longDisplay))
+		}
+
+		results, err := provider.Query(ctx, key, "mumbai", providers.QueryOptions{
+			MaxResults:    10,
+			MatchStrategy: providers.MatchPrefix,
+		})
+		if err != nil {
+			t.Fatalf("Query() error = %v", err)
+		}
+		displays := make(map[string]string)
+		for _, r := range results {
+			displays[r.ID] = r.Display
+		}
+		if displays["long"] != longDisplay || displays["short"] != "Mumbai" {
+			t.Errorf("compression %d: Query() displays = %v, want originals", compression, displays)
+		}
+	}
+}
+
+func TestRedisProvider_DictionaryCompression(t *testing.T) {
+	shared := getTestRedisClient(t)
+	provider := &Provider{client: shared.client, codec: newValueCodec(shared.client, CompressionZstdDict, 32)}
+
+	ctx := context.Background()
+	key := testKey
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchPrefix}
+	display := func(i int) string {
+		return fmt.Sprintf("%06d - Post Office %d, District %d (State of Maharashtra), Delivery: Yes, Region: Mumbai", 400000+i, i, i%36)
+	}
+
+	for i := 0; i < 200; i++ {
+		if err := provider.Index(ctx, key, fmt.Sprint(i), fmt.Sprint(400000+i), display(i), options); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+	beforeTraining, err := provider.client.HGet(ctx, prefixDisplay+key, "1").Result()
+	if err != nil {
+		t.Fatalf("HGet() error = %v", err)
+	}
+
+	if err := provider.TrainDictionary(ctx, key, 200); err != nil {
+		t.Fatalf("TrainDictionary() error = %v", err)
+	}
+	if err := provider.Index(ctx, key, "1", "400001", display(1), options); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	afterTraining, err := provider.client.HGet(ctx, prefixDisplay+key, "1").Result()
+	if err != nil {
+		t.Fatalf("HGet() error = %v", err)
+	}
+	if len(afterTraining) >= len(beforeTraining) {
+		t.Errorf("dictionary-compressed display is %d bytes, want fewer than %d", len(afterTraining), len(beforeTraining))
+	}
+
+	restarted := &Provider{client: shared.client, codec: newValueCodec(shared.client, CompressionZstdDict, 32)}
+	results, err := restarted.Query(ctx, key, "40000", providers.QueryOptions{
+		MaxResults:    10,
+		MatchStrategy: providers.MatchPrefix,
+	})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(results) == 0 {
+		t.Fatal("Query() returned no results")
+	}
+	for _, r := range results {
+		var i int
+		if _, err := fmt.Sscan(r.ID, &i); err != nil || r.Display != display(i) {
+			t.Errorf("Query() display for %s = %q, want %q", r.ID, r.Display, display(i))
+		}
+	}
+}
+
+func TestRedisProvider_Maintain(t *testing.T) {
+	shared := getTestRedisClient(t)
+	ctx := context.Background()
+	key := testKey
+
+	for _, layout := range []Layout{LayoutLexicographic, LayoutScored} {
+		provider := &Provider{client: shared.client, layout: layout, codec: newValueCodec(shared.client, CompressionNone, 0)}
+		if err := provider.DeleteAll(ctx, key); err != nil {
+			t.Fatalf("DeleteAll() error = %v", err)
+		}
+
+		options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchPrefix}
+		if err := provider.Index(ctx, key, "1", "Pune", "Pune", options); err != nil {
+			t.Fatalf("Failed to index entry: %v", err)
+		}
+		if err := provider.Index(ctx, key, "1", "Nashik", "Nashik", options); err != nil {
+			t.Fatalf("Failed to update entry: %v", err)
+		}
+
+		queryOptions := providers.QueryOptions{MaxResults: 10, MatchStrategy: providers.MatchPrefix}
+		results, err := provider.Query(ctx, key, "pun", queryOptions)
+		if err != nil {
+			t.Fatalf("layout %d: Query() error = %v", layout, err)
+		}
+		if len(results) != 1 {
+			t.Fatalf("layout %d: expected stale token to match before maintenance, got %v", layout, getResultIDs(results))
+		}
+
+		var progressCalls int
+		stats, err := provider.Maintain(ctx, key, providers.MaintenanceOptions{
+			BatchSize: 100,
+			Progress:  func(providers.MaintenanceStats) { progressCalls++ },
+		})
+		if err != nil {
+			t.Fatalf("layout %d: Maintain() error = %v", layout, err)
+		}
+		if stats.Scanned != 10 || stats.Removed != 4 {
+			t.Errorf("layout %d: Maintain() stats = %+v, want 10 scanned, 4 removed", layout, stats)
+		}
+		if progressCalls == 0 {
+			t.Errorf("layout %d: Progress was not called", layout)
+		}
+
+		results, err = provider.Query(ctx, key, "pun", queryOptions)
+		if err != nil {
+			t.Fatalf("layout %d: Query() after maintenance error = %v", layout, err)
+		}
+		if len(results) != 0 {
+			t.Errorf("layout %d: stale token still matches: %v", layout, getResultIDs(results))
+		}
+		results, err = provider.Query(ctx, key, "nas", queryOptions)
+		if err != nil {
+			t.Fatalf("layout %d: Query() after maintenance error = %v", layout, err)
+		}
+		if len(results) != 1 || results[0].ID != "1" {
+			t.Errorf("layout %d: current text no longer matches: %v", layout, getResultIDs(results))
+		}
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	provider := &Provider{client: shared.client, codec: newValueCodec(shared.client, CompressionNone, 0)}
+	if _, err := provider.Maintain(cancelled, key, providers.MaintenanceOptions{}); err == nil {
+		t.Error("Maintain() with cancelled context should fail")
+	}
+}
+
+func TestRedisProvider_MaintainSkipsChangedEntries(t *testing.T) {
+	shared := getTestRedisClient(t)
+	ctx := context.Background()
+	key := testKey
+	provider := &Provider{client: shared.client, codec: newValueCodec(shared.client, CompressionNone, 0)}
+	if err := provider.DeleteAll(ctx, key); err != nil {
+		t.Fatalf("DeleteAll() error = %v", err)
+	}
+
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchPrefix}
+	if err := provider.Index(ctx, key, "1", "Pune", "Pune", options); err != nil {
+		t.Fatalf("Failed to index entry: %v", err)
+	}
+
+	// Maintain read the text "Nashik" and found the tokens of "Pune" orphaned,
+	// but the entry was indexed as "Pune" again before they were removed
+	orphans := []interface{}{createPrefixMember("pun", "1"), "1", "Nashik", ""}
+	removed, err := provider.removeOrphans(ctx, key, prefixSet+key, orphans)
+	if err != nil {
+		t.Fatalf("removeOrphans() error = %v", err)
+	}
+	if removed != 0 {
+		t.Errorf("removeOrphans() removed %d tokens of a changed entry, want 0", removed)
+	}
+
+	orphans = []interface{}{createPrefixMember("pun", "1"), "1", "Pune", ""}
+	if removed, err := provider.removeOrphans(ctx, key, prefixSet+key, orphans); err != nil || removed != 1 {
+		t.Errorf("removeOrphans() = %d, %v; want 1, nil for an unchanged entry", removed, err)
+	}
+}
+
+func TestRedisProvider_MultipleStrategies(t *testing.T) {
+	shared := getTestRedisClient(t)
+	ctx := context.Background()
+	key := testKey
+	strategies := []providers.MatchStrategy{providers.MatchPrefix, providers.MatchNGram, providers.MatchSubstring}
+
+	for _, layout := range []Layout{LayoutLexicographic, LayoutScored} {
+		provider := &Provider{client: shared.client, layout: layout, codec: newValueCodec(shared.client, CompressionNone, 0)}
+		if err := provider.DeleteAll(ctx, key); err != nil {
+			t.Fatalf("DeleteAll() error = %v", err)
+		}
+
+		options := providers.IndexOptions{Score: 1.0, NGramSize: 3, MatchStrategies: strategies}
+		for _, e := range []struct{ id, text string }{{"1", "Mumbai"}, {"2", "Navi Mumbai"}} {
+			if err := provider.Index(ctx, key, e.id, e.text, e.text, options); err != nil {
+				t.Fatalf("Failed to index entry: %v", err)
+			}
+		}
+
+		tests := []struct {
+			strategy providers.MatchStrategy
+			query    string
+			want     int
+		}{
+			{providers.MatchPrefix, "mum", 1},
+			{providers.MatchPrefix, "navi m", 1},
+			{providers.MatchNGram, "umba", 2},
+			{providers.MatchSubstring, "mum", 2},
+			{providers.MatchSubstring, "i m", 1},
+		}
+		for _, tt := range tests {
+			results, err := provider.Query(ctx, key, tt.query, providers.QueryOptions{
+				MaxResults:      10,
+				MatchStrategy:   tt.strategy,
+				NGramSize:       3,
+				MatchStrategies: strategies,
+			})
+			if err != nil {
+				t.Fatalf("layout %d strategy %d: Query() error = %v", layout, tt.strategy, err)
+			}
+			if len(results) != tt.want {
+				t.Errorf("layout %d strategy %d: Query(%q) = %v, want %d results",
+					layout, tt.strategy, tt.query, getResultIDs(results), tt.want)
+			}
+		}
+
+		if err := provider.Delete(ctx, key, "1"); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		for _, strategy := range strategies {
+			results, err := provider.Query(ctx, key, "mum", providers.QueryOptions{
+				MaxResults: 10, MatchStrategy: strategy, NGramSize: 3, MatchStrategies: strategies,
+			})
+			if err != nil {
+				t.Fatalf("Query() after delete error = %v", err)
+			}
+			for _, r := range results {
+				if r.ID == "1" {
+					t.Errorf("layout %d strategy %d: deleted entry still returned", layout, strategy)
+				}
+			}
+		}
+	}
+}
+
+func TestRedisProvider_ListEntries(t *testing.T) {
+	shared := getTestRedisClient(t)
+	ctx := context.Background()
+	key := testKey
+
+	provider := &Provider{client: shared.client, codec: newValueCodec(shared.client, CompressionZstd, 1)}
+	if err := provider.DeleteAll(ctx, key); err != nil {
+		t.Fatalf("DeleteAll() error = %v", err)
+	}
+
+	want := map[string]providers.StoredEntry{}
+	for i := 0; i < 25; i++ {
+		id := fmt.Sprintf("%d", i)
+		entry := providers.StoredEntry{ID: id, Text: "City " + id, Display: "City " + id + ", India", ContentHash: "h" + id}
+		options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchPrefix, ContentHash: entry.ContentHash}
+		if err := provider.Index(ctx, key, id, entry.Text, entry.Display, options); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+		want[id] = entry
+	}
+
+	got := map[string]providers.StoredEntry{}
+	cursor := ""
+	for {
+		entries, next, err := provider.ListEntries(ctx, key, cursor, 10)
+		if err != nil {
+			t.Fatalf("ListEntries() error = %v", err)
+		}
+		for _, entry := range entries {
+			got[entry.ID] = entry
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("ListEntries() returned %d entries, want %d", len(got), len(want))
+	}
+	for id, entry := range want {
+		if got[id] != entry {
+			t.Errorf("ListEntries() entry %s = %+v, want %+v", id, got[id], entry)
+		}
+	}
+}
+
+func TestRedisProvider_QueryPage(t *testing.T) {
+	shared := getTestRedisClient(t)
+	scored := &Provider{client: shared.client, layout: LayoutScored, codec: shared.codec}
+	queries := map[providers.MatchStrategy]string{
+		providers.MatchPrefix:    "pune",
+		providers.MatchSubstring: "une",
+		providers.MatchNGram:     "une",
+		providers.MatchSuffix:    "station",
+	}
+
+	ctx := context.Background()
+	for name, provider := range map[string]*Provider{"lexicographic": shared, "scored": scored} {
+		for strategy, query := range queries {
+			if err := provider.DeleteAll(ctx, testKey); err != nil {
+				t.Fatalf("DeleteAll() error = %v", err)
+			}
+			indexOptions := providers.IndexOptions{MatchStrategy: strategy, NGramSize: 3}
+			for i := 0; i < 7; i++ {
+				text := fmt.Sprintf("pune %c station", 'a'+i)
+				indexOptions.Score = float64(i + 1)
+				if err := provider.Index(ctx, testKey, strconv.Itoa(i), text, text, indexOptions); err != nil {
+					t.Fatalf("Index() error = %v", err)
+				}
+			}
+
+			options := providers.QueryOptions{MaxResults: 3, MatchStrategy: strategy, NGramSize: 3}
+			seen := map[string]bool{}
+			pages := 0
+			for cursor := ""; pages == 0 || cursor != ""; pages++ {
+				results, next, err := provider.QueryPage(ctx, testKey, query, options, cursor)
+				if err != nil {
+					t.Fatalf("%s %v: QueryPage() error = %v", name, strategy, err)
+				}
+				if len(results) > options.MaxResults {
+					t.Errorf("%s %v: QueryPage() returned %d results, want at most %d", name, strategy, len(results), options.MaxResults)
+				}
+				for _, r := range results {
+					if seen[r.ID] {
+						t.Errorf("%s %v: QueryPage() returned %s twice", name, strategy, r.ID)
+					}
+					seen[r.ID] = true
+				}
+				cursor = next
+			}
+			if len(seen) != 7 || pages != 3 {
+				t.Errorf("%s %v: QueryPage() returned %d entries in %d pages, want 7 in 3", name, strategy, len(seen), pages)
+			}
+		}
+
+		_, _, err := provider.QueryPage(ctx, testKey, "pune", providers.QueryOptions{
+			MaxResults:     3,
+			MatchStrategy:  providers.MatchPrefix,
+			FilterMetadata: map[string]interface{}{"state": "MH"},
+		}, "")
+		if !errors.Is(err, providers.ErrPagingUnsupported) {
+			t.Errorf("%s: QueryPage() with a metadata filter error = %v, want ErrPagingUnsupported", name, err)
+		}
+	}
+}
+
+func TestRedisProvider_HashTags(t *testing.T) {
+	shared := getTestRedisClient(t)
+	ctx := context.Background()
+
+	for _, layout := range []Layout{LayoutLexicographic, LayoutScored} {
+		provider := &Provider{client: shared.client, hashTags: true, layout: layout, codec: newValueCodec(shared.client, CompressionNone, 0)}
+		options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchPrefix}
+		if err := provider.Index(ctx, testKey, "1", "Mumbai", "Mumbai", options); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+
+		keys, err := shared.client.Keys(ctx, "*").Result()
+		if err != nil {
+			t.Fatalf("Keys() error = %v", err)
+		}
+		for _, k := range keys {
+			if !strings.Contains(k, "{"+testKey+"}") {
+				t.Errorf("layout %d: key %q does not carry the namespace hash tag", layout, k)
+			}
+		}
+
+		results, err := provider.Query(ctx, testKey, "mum", providers.QueryOptions{MaxResults: 10, MatchStrategy: providers.MatchPrefix})
+		if err != nil {
+			t.Fatalf("Query() error = %v", err)
+		}
+		if len(results) != 1 || results[0].ID != "1" {
+			t.Errorf("layout %d: Query() = %v, want entry 1", layout, results)
+		}
+
+		if err := provider.DeleteAll(ctx, testKey); err != nil {
+			t.Fatalf("DeleteAll() error = %v", err)
+		}
+		if n, err := shared.client.DBSize(ctx).Result(); err != nil || n != 0 {
+			t.Errorf("layout %d: %d keys left after DeleteAll, error = %v", layout, n, err)
+		}
+	}
+}
+
+func TestNewClientRejectsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{"cluster with DB", Config{ClusterAddrs: []string{"localhost:7000"}, DB: 1}},
+		{"cluster and sentinel", Config{ClusterAddrs: []string{"localhost:7000"}, MasterName: "mymaster"}},
+		{"sentinel without addresses", Config{MasterName: "mymaster"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if client, err := newClient(tt.config); err == nil {
+				client.Close()
+				t.Error("newClient() succeeded, want error")
+			}
+		})
+	}
+
+	client, err := newClient(Config{MasterName: "mymaster", SentinelAddrs: []string{"localhost:26379"}})
+	if err != nil {
+		t.Fatalf("newClient() with Sentinel error = %v", err)
+	}
+	client.Close()
+}
+
+func TestNewWithClientName(t *testing.T) {
+	shared := getTestRedisClient(t)
+	addr := shared.client.(*redis.Client).Options().Addr
+
+	provider, err := New(Config{Addr: addr, ClientName: "autocomplete-test", DialTimeout: time.Second})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer provider.Close()
+
+	name, err := provider.client.(*redis.Client).ClientGetName(context.Background()).Result()
+	if err != nil {
+		t.Fatalf("ClientGetName() error = %v", err)
+	}
+	if name != "autocomplete-test" {
+		t.Errorf("ClientGetName() = %q, want %q", name, "autocomplete-test")
+	}
+}
+
+func TestNewWithClient(t *testing.T) {
+	shared := getTestRedisClient(t)
+	ctx := context.Background()
+
+	if _, err := NewWithClient(nil, Config{}); err == nil {
+		t.Error("NewWithClient(nil) succeeded, want error")
+	}
+
+	provider, err := NewWithClient(shared.client, Config{Layout: LayoutScored})
+	if err != nil {
+		t.Fatalf("NewWithClient() error = %v", err)
+	}
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchPrefix}
+	if err := provider.Index(ctx, testKey, "1", "Mumbai", "Mumbai", options); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	results, err := provider.Query(ctx, testKey, "mum", providers.QueryOptions{MaxResults: 10, MatchStrategy: providers.MatchPrefix})
+	if err != nil || len(results) != 1 {
+		t.Errorf("Query() = %v, %v; want entry 1", results, err)
+	}
+
+	if err := provider.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := shared.client.Ping(ctx).Err(); err != nil {
+		t.Errorf("shared client unusable after Close(): %v", err)
+	}
+}
+
+func TestRedisProvider_Stress(t *testing.T) {
+	addr := getTestRedisClient(t).client.(*redis.Client).Options().Addr
+
+	layouts := []struct {
+		name   string
+		layout Layout
+	}{
+		{"lexicographic", LayoutLexicographic},
+		{"scored", LayoutScored},
+	}
+	for _, l := range layouts {
+		for _, strategy := range []autocomplete.MatchStrategy{autocomplete.MatchPrefix, autocomplete.MatchNGram, autocomplete.MatchSubstring} {
+			t.Run(l.name+"/"+strategy.String(), func(t *testing.T) {
+				options := autocomplete.DefaultOptions()
+				options.MatchStrategy = strategy
+				options.Namespace = "stress"
+				ac, err := autocomplete.New("redis", autocomplete.NewConfigWithOptions(Config{Addr: addr, Layout: l.layout}, options))
+				if err != nil {
+					t.Fatalf("New() error = %v", err)
+				}
+				defer func() { _ = ac.Close() }()
+
+				// Updates leave stale tokens until Maintain removes them
+				if err := stress.Run(context.Background(), ac, stress.Config{StaleMatches: true}); err != nil {
+					t.Error(err)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// formatScore encodes an entry score for the score hash
+func formatScore(score float64) string {
+	return strconv.FormatFloat(score, 'g', -1, 64)
+}
+
+// parseScore decodes an entry score read from the score hash. Entries without
+// one count as having defaultEntryScore
+func parseScore(stored string) float64 {
+	score, err := strconv.ParseFloat(stored, 64)
+	if err != nil {
+		return defaultEntryScore
+	}
+	return score
+}
+
+// scoreAt returns the entry score at index i of an HMGET reply of the score hash
+func scoreAt(scores []interface{}, i int) float64 {
+	stored, _ := scores[i].(string)
+	return parseScore(stored)
+}
+
+// scoredEntry is what UpdateScore and IncrementScore read of a stored entry to
+// find its token sets in LayoutScored
+type scoredEntry struct {
+	searchText string
+	tags       []string
+}
+
+// readScoredEntry reads the stored text and strategies of an entry, reporting
+// whether it exists
+func (p *Provider) readScoredEntry(ctx context.Context, key, id string) (scoredEntry, bool, error) {
+	read := p.client.Pipeline()
+	textCmd := read.HGet(ctx, prefixText+key, id)
+	metaCmd := read.HGet(ctx, prefixMeta+key, id)
+	strategiesCmd := read.HGet(ctx, prefixStrategies+key, id)
+	if _, err := read.Exec(ctx); err != nil && err != redis.Nil {
+		return scoredEntry{}, false, fmt.Errorf("failed to get entry for score update: %w", err)
+	}
+	text := textCmd.Val()
+	if text == "" {
+		return scoredEntry{}, false, nil
+	}
+	return scoredEntry{
+		searchText: providers.SearchText(text, metaCmd.Val() == "1"),
+		tags:       deletionTags(strategiesCmd.Val()),
+	}, true, nil
+}
+
+// scoreScript sets the score of an entry in the score hash, or adds to it with
+// HINCRBYFLOAT, and, in LayoutScored, replaces the entry score code (see
+// scoreCode, which it mirrors) of each of the entry's composite token scores,
+// keeping their position and length ranks. An entry that no longer exists is
+// left alone, so a deleted entry's score and tokens are never recreated. KEYS
+// are the text and score hashes followed by the token sets the entry can
+// belong to (LayoutScored only). ARGV are the entry ID and its new score, or,
+// to increment, the entry ID, the delta, and the score of an entry without a
+// score hash field after the increment. It returns 1 if the entry exists
+var scoreScript = redis.NewScript(fmt.Sprintf(`
+local function scoreCode(score)
+	if score == 0 then
+		return %[1]d
+	end
+	local frac, exp = math.frexp(math.abs(score))
+	local magnitude
+	if score == math.huge or score == -math.huge or exp > %[3]d then
+		magnitude = %[1]d - 1
+	elseif exp < %[2]d then
+		magnitude = 0
+	else
+		magnitude = (exp - (%[2]d)) * %[4]d + math.floor((frac - 0.5) * %[5]d)
+	end
+	if score < 0 then
+		return %[1]d - 1 - magnitude
+	end
+	return %[1]d + 1 + magnitude
+end
+
+if redis.call('HEXISTS', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+local score
+if ARGV[3] and redis.call('HEXISTS', KEYS[2], ARGV[1]) == 1 then
+	score = redis.call('HINCRBYFLOAT', KEYS[2], ARGV[1], ARGV[2])
+else
+	score = ARGV[3] or ARGV[2]
+	redis.call('HSET', KEYS[2], ARGV[1], score)
+end
+local code = scoreCode(tonumber(score))
+for i = 3, #KEYS do
+	local composite = redis.call('ZSCORE', KEYS[i], ARGV[1])
+	if composite then
+		local tie = tonumber(composite) %% %[6]d
+		redis.call('ZADD', KEYS[i], 'XX', string.format('%%.0f', code * %[6]d + tie), ARGV[1])
+	end
+end
+return 1
+`, scoreCodeZero, scoreCodeMinExp, scoreCodeMaxExp,
+	1<<scoreCodeMantissaBits, 1<<(scoreCodeMantissaBits+1), compositeTieScale))
+
+// UpdateScore sets the score of a stored entry without tokenizing its text
+// again, in its score hash field and, in LayoutScored, in its composite token
+// scores, with one run of scoreScript. Unknown IDs are ignored
+func (p *Provider) UpdateScore(ctx context.Context, key, id string, score float64) error {
+	key = p.namespace(key)
+	keys, err := p.scoreScriptKeys(ctx, key, id)
+	if err != nil {
+		return err
+	}
+	if err := scoreScript.Run(ctx, p.client, keys, id, formatScore(score)).Err(); err != nil {
+		return fmt.Errorf("failed to update score: %w", err)
+	}
+	return nil
+}
+
+// scoreScriptKeys returns the KEYS of scoreScript for an entry. In
+// LayoutScored, as with Delete, the stored text gives the token sets the entry
+// can belong to
+func (p *Provider) scoreScriptKeys(ctx context.Context, key, id string) ([]string, error) {
+	keys := []string{prefixText + key, prefixScore + key}
+	if p.layout != LayoutScored {
+		return keys, nil
+	}
+	entry, found, err := p.readScoredEntry(ctx, key, id)
+	if err != nil || !found {
+		return keys, err
+	}
+	return append(keys, scoredTokenKeys(key, entry)...), nil
+}
+
+// IncrementScore adds delta to the score of a stored entry with one run of
+// scoreScript, which increments its score hash field with HINCRBYFLOAT, so
+// concurrent increments all count, and rewrites its composite token scores in
+// LayoutScored. Unknown IDs are ignored
+func (p *Provider) IncrementScore(ctx context.Context, key, id string, delta float64) error {
+	key = p.namespace(key)
+	keys, err := p.scoreScriptKeys(ctx, key, id)
+	if err != nil {
+		return err
+	}
+	err = scoreScript.Run(ctx, p.client, keys, id, formatScore(delta), formatScore(defaultEntryScore+delta)).Err()
+	if err != nil {
+		return fmt.Errorf("failed to increment score: %w", err)
+	}
+	return nil
+}
+
+// scoredTokenKeys returns the distinct token sets an entry can belong to in
+// LayoutScored under any of its strategies
+func scoredTokenKeys(key string, entry scoredEntry) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, tag := range entry.tags {
+		for _, tok := range substringTokens(entry.searchText, 1) {
+			if k := tokenSetKey(key, tag+tok.text); !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	return keys
+}
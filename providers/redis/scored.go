@@ -0,0 +1,283 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// Layout selects how the Redis provider stores tokens.
+type Layout int
+
+const (
+	// LayoutLexicographic stores every token of a namespace as a member of a single
+	// sorted set and finds matches with ZRANGEBYLEX, deduplicating and ranking
+	// candidates on the client. This is the default and the most memory efficient.
+	LayoutLexicographic Layout = iota
+
+	// LayoutScored stores one sorted set per token whose members are entry IDs and
+	// whose scores encode a composite relevance key (entry score, match position,
+	// text length). Top-K retrieval is then a single ZRANGE ... REV with no
+	// client-side deduplication or sorting. It uses more keys and memory and
+	// ignores RankingScript; intended for ultra-low-latency deployments.
+	LayoutScored
+)
+
+const (
+	// prefixTokenSet is the Redis key prefix for per-token sorted sets (scored layout).
+	prefixTokenSet = "ac:tok:"
+
+	// prefixTokenIndex is the Redis key prefix for sets listing a namespace's tokens (scored layout).
+	prefixTokenIndex = "ac:tokens:"
+
+	// compositeSlotBits is the number of bits of a composite score holding the
+	// match position rank, and again the text length rank.
+	compositeSlotBits = 10
+
+	// compositeSlots is the number of distinct positions and lengths the composite key distinguishes.
+	compositeSlots = 1 << compositeSlotBits
+
+	// compositeTieScale separates the entry score code from the position and length ranks.
+	compositeTieScale = compositeSlots * compositeSlots
+
+	// scoreCodeZero is the code of an entry score of 0. Positive scores code above
+	// it and negative scores below it.
+	scoreCodeZero = 1 << 31
+
+	// scoreCodeMantissaBits is the number of significant bits of an entry score
+	// kept by its code, besides the leading one, as in a float32.
+	scoreCodeMantissaBits = 23
+
+	// scoreCodeMinExp and scoreCodeMaxExp bound the binary exponents told apart
+	// by score codes. Smaller magnitudes code like the smallest, larger like the largest.
+	scoreCodeMinExp = -126
+	scoreCodeMaxExp = 129
+
+	// tokenSetScanCount is the SSCAN batch size used when deleting a namespace's token sets.
+	tokenSetScanCount = 500
+)
+
+// scoreCode maps an entry score to an integer in [0, 2^32] that orders like the
+// score, keeping it to 24 significant bits. Codes of scores that differ in
+// their first seven or so significant digits differ, so fractional scores such
+// as PathDepthScore's keep their order, and composite scores stay integers
+// below 2^53, which sorted set scores hold exactly.
+func scoreCode(score float64) float64 {
+	if score == 0 {
+		return scoreCodeZero
+	}
+	frac, exp := math.Frexp(math.Abs(score))
+	var magnitude float64
+	switch {
+	case math.IsInf(score, 0) || exp > scoreCodeMaxExp:
+		magnitude = scoreCodeZero - 1
+	case exp < scoreCodeMinExp:
+		magnitude = 0
+	default:
+		// frac is in [0.5, 1), so its bits after the leading one fill the mantissa
+		magnitude = float64(exp-scoreCodeMinExp)*(1<<scoreCodeMantissaBits) +
+			math.Floor((frac-0.5)*(1<<(scoreCodeMantissaBits+1)))
+	}
+	if score < 0 {
+		return scoreCodeZero - 1 - magnitude
+	}
+	return scoreCodeZero + 1 + magnitude
+}
+
+// compositeScore packs the entry score, match position, and text length into a
+// single sorted set score: the entry score's code (see scoreCode) above the
+// position and length ranks. Higher entry scores dominate, then earlier
+// matches, then shorter texts.
+func compositeScore(entryScore float64, position, textLength int) float64 {
+	return scoreCode(entryScore)*compositeTieScale + compositeTie(position, textLength)
+}
+
+// compositeTie returns the position and length ranks of a composite score.
+func compositeTie(position, textLength int) float64 {
+	positionRank := compositeSlots - 1 - min(position, compositeSlots-1)
+	lengthRank := compositeSlots - 1 - min(textLength, compositeSlots-1)
+	return float64(positionRank*compositeSlots + lengthRank)
+}
+
+// tokenSetKey returns the sorted set key holding the IDs that contain a token.
+func tokenSetKey(key, tok string) string {
+	return prefixTokenSet + key + ":" + tok
+}
+
+// addScoredTokenCommands queues one ZADD per distinct token, prefixed with tag,
+// keeping each token's best (earliest) position for the entry
+func addScoredTokenCommands(pipe redis.Pipeliner, ctx context.Context, key, id, text, tag string, options providers.IndexOptions) {
+	best := make(map[string]int)
+	var order []string
+	for _, tok := range tokenize(text, options) {
+		tagged := tag + tok.text
+		position, seen := best[tagged]
+		if !seen {
+			order = append(order, tagged)
+		}
+		if !seen || tok.position < position {
+			best[tagged] = tok.position
+		}
+	}
+
+	for _, tok := range order {
+		pipe.ZAdd(ctx, tokenSetKey(key, tok), &redis.Z{
+			Score:  compositeScore(options.Score, best[tok], len(text)),
+			Member: id,
+		})
+		pipe.SAdd(ctx, prefixTokenIndex+key, tok)
+	}
+}
+
+// queryScored returns the top results for a query from the per-token sorted sets
+func (p *Provider) queryScored(
+	ctx context.Context, key, searchQuery string, options providers.QueryOptions, stats *QueryStats,
+) ([]providers.ProviderResult, error) {
+	if searchQuery == "" {
+		return []providers.ProviderResult{}, nil
+	}
+
+	n := getNGramSizeOrDefault(options.NGramSize)
+	switch {
+	case options.MatchStrategy == providers.MatchNOrMoreGram && len(searchQuery) < n:
+		return []providers.ProviderResult{}, nil
+	case options.MatchStrategy == providers.MatchNGram && len(searchQuery) > n:
+		return p.queryScoredIntersection(ctx, key, searchQuery, n, options, stats)
+	}
+
+	tok := queryTag(options) + searchQuery
+	top, err := p.client.ZRevRangeWithScores(ctx, tokenSetKey(key, tok), 0, int64(options.MaxResults-1)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query autocomplete: %w", err)
+	}
+	if err := p.addScoredTokenStats(ctx, key, queryTag(options), []string{searchQuery}, stats); err != nil {
+		return nil, err
+	}
+	return p.fetchScoredResults(ctx, key, top)
+}
+
+// queryScoredIntersection returns IDs containing every n-gram of the query, ranked
+// by their weakest n-gram score. This path sorts the intersection on the client.
+func (p *Provider) queryScoredIntersection(
+	ctx context.Context, key, searchQuery string, n int, options providers.QueryOptions, stats *QueryStats,
+) ([]providers.ProviderResult, error) {
+	tag := queryTag(options)
+	ngrams := make([]string, 0, len(searchQuery)-n+1)
+	keys := make([]string, 0, len(searchQuery)-n+1)
+	for i := 0; i <= len(searchQuery)-n; i++ {
+		ngrams = append(ngrams, searchQuery[i:i+n])
+		keys = append(keys, tokenSetKey(key, tag+searchQuery[i:i+n]))
+	}
+	if err := p.addScoredTokenStats(ctx, key, tag, ngrams, stats); err != nil {
+		return nil, err
+	}
+
+	matches, err := p.client.ZInterWithScores(ctx, &redis.ZStore{Keys: keys, Aggregate: "MIN"}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to intersect n-grams: %w", err)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	return p.fetchScoredResults(ctx, key, matches[:min(len(matches), options.MaxResults)])
+}
+
+// addScoredTokenStats records the size of the token set of each token, which
+// is only read when stats are recorded
+func (p *Provider) addScoredTokenStats(ctx context.Context, key, tag string, tokens []string, stats *QueryStats) error {
+	if stats == nil {
+		return nil
+	}
+	pipe := p.client.Pipeline()
+	counts := make([]*redis.IntCmd, len(tokens))
+	for i, tok := range tokens {
+		counts[i] = pipe.ZCard(ctx, tokenSetKey(key, tag+tok))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to count token candidates: %w", err)
+	}
+	for i, tok := range tokens {
+		stats.add(tok, int(counts[i].Val()))
+	}
+	return nil
+}
+
+// fetchScoredResults attaches display text and entry scores to ranked IDs,
+// preserving their order. A result's score is its entry score, not the
+// composite score it was ranked by
+func (p *Provider) fetchScoredResults(ctx context.Context, key string, top []redis.Z) ([]providers.ProviderResult, error) {
+	if len(top) == 0 {
+		return []providers.ProviderResult{}, nil
+	}
+
+	ids := make([]string, len(top))
+	for i, z := range top {
+		ids[i], _ = z.Member.(string)
+	}
+
+	displayList, payloads, scores, err := p.fetchEntryData(ctx, key, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]providers.ProviderResult, 0, len(ids))
+	for i, id := range ids {
+		storedDisplay, ok := displayList[i].(string)
+		if !ok {
+			continue
+		}
+		display, err := p.codec.decode(ctx, key, storedDisplay)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, providers.ProviderResult{
+			ID:       id,
+			Display:  display,
+			Score:    scoreAt(scores, i),
+			Metadata: payloadAt(payloads, i),
+		})
+	}
+	return results, nil
+}
+
+// removeScoredMembers removes an ID from the token set of every substring of its text,
+// prefixed with tag, which covers the tokens of all match strategies
+func removeScoredMembers(pipe redis.Pipeliner, ctx context.Context, key, tag, text, id string) {
+	for _, tok := range substringTokens(text, 1) {
+		pipe.ZRem(ctx, tokenSetKey(key, tag+tok.text), id)
+	}
+}
+
+// deleteScoredTokenSets deletes every per-token sorted set listed in the namespace's token index
+func (p *Provider) deleteScoredTokenSets(ctx context.Context, key string) error {
+	var cursor uint64
+	for {
+		tokens, next, err := p.client.SScan(ctx, prefixTokenIndex+key, cursor, "", tokenSetScanCount).Result()
+		if err != nil {
+			return fmt.Errorf("failed to scan token index: %w", err)
+		}
+
+		if len(tokens) > 0 {
+			pipe := p.client.Pipeline()
+			for _, tok := range tokens {
+				pipe.Del(ctx, tokenSetKey(key, tok))
+			}
+			if _, err := pipe.Exec(ctx); err != nil {
+				return fmt.Errorf("failed to delete token sets: %w", err)
+			}
+		}
+
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+
+	return p.client.Del(ctx, prefixTokenIndex+key).Err()
+}
@@ -0,0 +1,68 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// TokenStats is the number of candidate entries a single query token matched
+type TokenStats struct {
+	// Token is the query token or n-gram, without its strategy tag.
+	Token string
+
+	// Candidates is the number of distinct entries the token matched before
+	// intersection with the other tokens. In LayoutLexicographic, it counts only
+	// the members a query reads, which are capped at a multiple of its limit.
+	Candidates int
+}
+
+// QueryStats describes how a query was answered, token by token, for debugging
+// queries that return fewer results than expected. See Config.OnQueryStats.
+type QueryStats struct {
+	// Key is the namespace that was queried.
+	Key string
+
+	// Query is the query after case normalization.
+	Query string
+
+	// Strategy is the match strategy the query ran with.
+	Strategy providers.MatchStrategy
+
+	// Tokens lists the tokens the query looked up, in query order. In
+	// LayoutLexicographic, a query stops at the first token without candidates,
+	// which is then the last one listed. Queries answered by RankingScript list none.
+	Tokens []TokenStats
+
+	// Results is the number of results returned.
+	Results int
+}
+
+// add records the candidates of a token; it does nothing on nil stats, so
+// query paths can record unconditionally
+func (s *QueryStats) add(token string, candidates int) {
+	if s == nil {
+		return
+	}
+	s.Tokens = append(s.Tokens, TokenStats{Token: token, Candidates: candidates})
+}
+
+// newQueryStats returns the stats to record for a query, or nil when
+// Config.OnQueryStats is not set
+//
+//nolint:gocritic // hugeParam: options is read-only here
+func (p *Provider) newQueryStats(key, searchQuery string, options providers.QueryOptions) *QueryStats {
+	if p.onQueryStats == nil {
+		return nil
+	}
+	return &QueryStats{Key: key, Query: searchQuery, Strategy: options.MatchStrategy}
+}
+
+// reportQueryStats passes the stats of a successful query to Config.OnQueryStats
+func (p *Provider) reportQueryStats(ctx context.Context, stats *QueryStats, results []providers.ProviderResult) {
+	if stats == nil {
+		return
+	}
+	stats.Results = len(results)
+	p.onQueryStats(ctx, *stats)
+}
@@ -0,0 +1,94 @@
+package redis
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// strategyTagMarker starts the tag that prefixes the tokens of one strategy when
+// an entry is indexed under several. Indexed text never contains it, so tagged
+// and untagged tokens cannot collide.
+const strategyTagMarker = "\x00"
+
+// taggedOptions are the indexing options and token tag for one strategy of an entry.
+type taggedOptions struct {
+	tag     string
+	options providers.IndexOptions
+}
+
+// strategyOptions splits indexing options into one set per strategy the entry is
+// indexed under, each with the tag its tokens are stored with
+//
+//nolint:gocritic // hugeParam: options is copied once per strategy anyway
+func strategyOptions(options providers.IndexOptions) []taggedOptions {
+	if len(options.MatchStrategies) == 0 {
+		return []taggedOptions{{options: options}}
+	}
+
+	split := make([]taggedOptions, len(options.MatchStrategies))
+	for i, strategy := range options.MatchStrategies {
+		single := options
+		single.MatchStrategy = strategy
+		single.MatchStrategies = nil
+		split[i] = taggedOptions{tag: strategyTag(strategy), options: single}
+	}
+	return split
+}
+
+// strategyTag returns the token prefix for a strategy
+func strategyTag(strategy providers.MatchStrategy) string {
+	return strategyTagMarker + strconv.Itoa(int(strategy))
+}
+
+// queryTag returns the token prefix to query with: the tag of the selected
+// strategy for namespaces indexed under several strategies, otherwise none
+//
+//nolint:gocritic // hugeParam: options is read-only here
+func queryTag(options providers.QueryOptions) string {
+	if len(options.MatchStrategies) == 0 {
+		return ""
+	}
+	return strategyTag(options.MatchStrategy)
+}
+
+// stripStrategyTag removes a strategy tag from a stored token
+func stripStrategyTag(tok string) string {
+	if !strings.HasPrefix(tok, strategyTagMarker) || len(tok) < len(strategyTagMarker)+1 {
+		return tok
+	}
+	return tok[len(strategyTagMarker)+1:]
+}
+
+// formatStrategies encodes strategies for the strategies hash, e.g. "0,3"
+func formatStrategies(strategies []providers.MatchStrategy) string {
+	encoded := make([]string, len(strategies))
+	for i, strategy := range strategies {
+		encoded[i] = strconv.Itoa(int(strategy))
+	}
+	return strings.Join(encoded, ",")
+}
+
+// deletionTags returns the token tags to remove for an entry, given its stored
+// strategies; entries indexed under a single strategy have untagged tokens
+func deletionTags(stored string) []string {
+	if stored == "" {
+		return []string{""}
+	}
+
+	var tags []string
+	for _, field := range strings.Split(stored, ",") {
+		if strategy, err := strconv.Atoi(field); err == nil {
+			tags = append(tags, strategyTag(providers.MatchStrategy(strategy)))
+		}
+	}
+	return tags
+}
+
+// SupportsMatchStrategies reports that entries can be indexed under any
+// combination of strategies. Each strategy's tokens are stored with a tag in the
+// namespace's token structures, while text and display are stored once
+func (p *Provider) SupportsMatchStrategies(strategies []providers.MatchStrategy) bool {
+	return true
+}
@@ -0,0 +1,57 @@
+package redis
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// suffixTokenMarker starts the tokens of MatchSuffix in LayoutLexicographic,
+// which hold an entry's whole text reversed, so that a suffix query becomes a
+// ZRANGEBYLEX prefix scan for the reversed query. Strategy tags are digits, so
+// it cannot be mistaken for one. Bytes are reversed rather than characters,
+// which is consistent for stored text and queries alike.
+const suffixTokenMarker = strategyTagMarker + ">"
+
+// reversedToken returns the lexicographic token of text for MatchSuffix
+func reversedToken(text string) string {
+	return suffixTokenMarker + reverseBytes(text)
+}
+
+// suffixOfToken returns the text a stored MatchSuffix token was built from and
+// whether the token is one, with or without a strategy tag
+func suffixOfToken(tok string) (string, bool) {
+	if !strings.HasPrefix(tok, suffixTokenMarker) {
+		tok = stripStrategyTag(tok)
+	}
+	reversed, ok := strings.CutPrefix(tok, suffixTokenMarker)
+	if !ok {
+		return "", false
+	}
+	return reverseBytes(reversed), true
+}
+
+// reverseBytes returns s with its bytes in reverse order
+func reverseBytes(s string) string {
+	reversed := make([]byte, len(s))
+	for i := range s {
+		reversed[len(s)-1-i] = s[i]
+	}
+	return string(reversed)
+}
+
+// removeSuffixMembers removes the MatchSuffix token of text
+func removeSuffixMembers(pipe redis.Pipeliner, ctx context.Context, key, tag, text, id string) {
+	pipe.ZRem(ctx, key, createPrefixMember(tag+reversedToken(text), id))
+}
+
+// SupportsMatchStrategy reports that every match strategy, including
+// MatchSuffix and MatchAnchoredSubstring, is supported in both layouts.
+// LayoutScored keeps a token set per suffix or anchored substring of the text
+// and looks the query up directly
+func (p *Provider) SupportsMatchStrategy(strategy providers.MatchStrategy) bool {
+	return true
+}
@@ -0,0 +1,57 @@
+// Package redisearch implements the autocomplete Provider interface using the
+// RediSearch query engine, built into Redis 8 and available as a module for
+// earlier versions. Entries are stored as hashes indexed by a single search
+// index, which is far more compact than the token sorted sets of the redis
+// provider and supports fuzzy matching on the server.
+package redisearch
+
+const (
+	// defaultIndexName is the name of the search index.
+	defaultIndexName = "autocomplete"
+
+	// defaultKeyPrefix is prepended to the keys of the hashes holding entries.
+	defaultKeyPrefix = "ac:doc:"
+
+	// maxFuzziness is the largest edit distance RediSearch supports in fuzzy queries.
+	maxFuzziness = 3
+)
+
+// Config holds Redis connection parameters and provider-specific options.
+type Config struct {
+	// Addr is the Redis server address in the format "host:port".
+	Addr string
+
+	// Password is the Redis password (empty string for no password).
+	Password string
+
+	// DB is the Redis database number. Search indexes are only supported in DB 0
+	// by most Redis deployments.
+	DB int
+
+	// IndexName is the name of the search index, created on startup if missing.
+	// Default: "autocomplete"
+	IndexName string
+
+	// KeyPrefix is prepended to the keys of the hashes holding entries. Every
+	// hash under it is indexed, so it must not be shared with other data.
+	// Changing it requires dropping the index and reindexing all data.
+	// Default: "ac:doc:"
+	KeyPrefix string
+
+	// Fuzziness is the maximum edit distance at which a query word still matches
+	// an indexed word, from 0 (exact) to 3. Fuzzy matches are added to prefix
+	// and substring matches, not used in their place.
+	// Default: 0
+	Fuzziness int
+}
+
+// setDefaults applies default values to config fields.
+func (c *Config) setDefaults() {
+	if c.IndexName == "" {
+		c.IndexName = defaultIndexName
+	}
+	if c.KeyPrefix == "" {
+		c.KeyPrefix = defaultKeyPrefix
+	}
+	c.Fuzziness = min(max(c.Fuzziness, 0), maxFuzziness)
+}
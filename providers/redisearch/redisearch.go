@@ -0,0 +1,307 @@
+package redisearch
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+const (
+	// defaultMaxResults is the default maximum number of results if not specified.
+	defaultMaxResults = 10
+
+	// defaultNGramSize is the default n-gram size when not specified in options.
+	defaultNGramSize = 3
+
+	// deleteBatchSize is the number of entries DeleteAll removes per batch.
+	deleteBatchSize = 1000
+
+	// minWordLength is the shortest query word RediSearch expands as a prefix or
+	// infix, per its default MINPREFIX setting. Shorter words are left out of queries.
+	minWordLength = 2
+
+	// separators are the characters, besides whitespace, at which RediSearch
+	// splits text into words by default.
+	separators = ",.<>{}[]\"':;!@#$%^&*()-+=~"
+)
+
+// Provider implements the autocomplete Provider interface using RediSearch.
+// Each entry is a hash holding its namespace, text, display and score, and one
+// search index covers the hashes of all namespaces.
+//
+// Every query word must match the start of a word in the entry's text for
+// MatchPrefix, and any part of a word for the other strategies. Matching is
+// always case-insensitive. Results are ranked by entry score.
+// All methods are safe for concurrent use.
+type Provider struct {
+	client *redis.Client
+	config Config
+}
+
+// New creates a RediSearch provider. It verifies connectivity with a PING
+// command and creates the search index if it does not exist.
+func New(config Config) (*Provider, error) {
+	config.setDefaults()
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     config.Addr,
+		Password: config.Password, // pragma: allowlist secret
+		DB:       config.DB,
+	})
+
+	ctx := context.Background()
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+
+	provider := &Provider{client: client, config: config}
+	if err := provider.createIndex(ctx); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return provider, nil
+}
+
+// createIndex creates the search index over the entry hashes. Stop words are
+// disabled and words are not stemmed, so every word can be completed.
+func (p *Provider) createIndex(ctx context.Context) error {
+	err := p.client.Do(ctx, "FT.CREATE", p.config.IndexName,
+		"ON", "HASH", "PREFIX", "1", p.config.KeyPrefix, "STOPWORDS", "0",
+		"SCHEMA",
+		"namespace", "TAG", "CASESENSITIVE",
+		"text", "TEXT", "NOSTEM", "WITHSUFFIXTRIE",
+		"score", "NUMERIC", "SORTABLE",
+	).Err()
+	if err != nil && !strings.Contains(err.Error(), "Index already exists") {
+		return fmt.Errorf("failed to create index %s: %w", p.config.IndexName, err)
+	}
+	return nil
+}
+
+// Index adds or updates an entry.
+func (p *Provider) Index(ctx context.Context, key, id, text, display string, options providers.IndexOptions) error {
+	if err := p.client.HSet(ctx, p.documentKey(key, id), documentFields(key, id, text, display, options)...).Err(); err != nil {
+		return fmt.Errorf("failed to index entry: %w", err)
+	}
+	return nil
+}
+
+// IndexAtomic writes all entries inside a single MULTI/EXEC transaction, so
+// queries observe either none or all of them.
+func (p *Provider) IndexAtomic(ctx context.Context, key string, entries []providers.IndexEntry) error {
+	pipe := p.client.TxPipeline()
+	for _, entry := range entries {
+		pipe.HSet(ctx, p.documentKey(key, entry.ID), documentFields(key, entry.ID, entry.Text, entry.Display, entry.Options)...)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to execute index transaction: %w", err)
+	}
+	return nil
+}
+
+// ContentHash returns the stored content hash for an entry and whether it exists.
+func (p *Provider) ContentHash(ctx context.Context, key, id string) (string, bool, error) {
+	values, err := p.client.HMGet(ctx, p.documentKey(key, id), "entry_id", "content_hash").Result()
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get content hash: %w", err)
+	}
+	if values[0] == nil {
+		return "", false, nil
+	}
+	hash, _ := values[1].(string)
+	return hash, true, nil
+}
+
+// SupportsMatchStrategies reports that entries can be queried under any combination
+// of strategies, since the index serves prefix and infix queries alike.
+func (p *Provider) SupportsMatchStrategies(strategies []providers.MatchStrategy) bool {
+	return true
+}
+
+// Query searches the namespace for entries matching the query.
+func (p *Provider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	searchQuery, ok := buildQuery(key, query, options, p.config.Fuzziness)
+	if !ok {
+		return []providers.ProviderResult{}, nil
+	}
+
+	size := options.MaxResults
+	if size <= 0 {
+		size = defaultMaxResults
+	}
+	reply, err := p.client.Do(ctx, "FT.SEARCH", p.config.IndexName, searchQuery,
+		"RETURN", "3", "entry_id", "display", "score",
+		"SORTBY", "score", "DESC",
+		"LIMIT", strconv.Itoa(options.Offset), strconv.Itoa(size),
+		"DIALECT", "2",
+	).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute search: %w", err)
+	}
+	return parseSearchReply(reply)
+}
+
+// Delete removes an entry.
+func (p *Provider) Delete(ctx context.Context, key, id string) error {
+	if err := p.client.Del(ctx, p.documentKey(key, id)).Err(); err != nil {
+		return fmt.Errorf("failed to delete entry: %w", err)
+	}
+	return nil
+}
+
+// DeleteAll removes all entries for a given key namespace, a batch at a time.
+func (p *Provider) DeleteAll(ctx context.Context, key string) error {
+	for {
+		reply, err := p.client.Do(ctx, "FT.SEARCH", p.config.IndexName, namespaceFilter(key),
+			"NOCONTENT", "LIMIT", "0", strconv.Itoa(deleteBatchSize), "DIALECT", "2",
+		).Slice()
+		if err != nil {
+			return fmt.Errorf("failed to find entries: %w", err)
+		}
+		if len(reply) <= 1 {
+			return nil
+		}
+
+		keys := make([]string, 0, len(reply)-1)
+		for _, k := range reply[1:] {
+			if s, ok := k.(string); ok {
+				keys = append(keys, s)
+			}
+		}
+		if err := p.client.Del(ctx, keys...).Err(); err != nil {
+			return fmt.Errorf("failed to delete entries: %w", err)
+		}
+	}
+}
+
+// Close closes the Redis connection.
+func (p *Provider) Close() error {
+	return p.client.Close()
+}
+
+// documentKey returns the key of the hash holding an entry.
+func (p *Provider) documentKey(key, id string) string {
+	return p.config.KeyPrefix + key + "\x00" + id
+}
+
+// documentFields returns the hash fields stored for an entry.
+func documentFields(key, id, text, display string, options providers.IndexOptions) []interface{} {
+	return []interface{}{
+		"namespace", encodeNamespace(key),
+		"entry_id", id,
+		"text", text,
+		"display", display,
+		"score", strconv.FormatFloat(options.Score, 'g', -1, 64),
+		"content_hash", options.ContentHash,
+	}
+}
+
+// encodeNamespace returns the tag value stored for a namespace. Namespaces are
+// hex-encoded so that tag separators and query syntax in them need no escaping.
+func encodeNamespace(key string) string {
+	return hex.EncodeToString([]byte(key))
+}
+
+// namespaceFilter returns the query clause matching the entries of a namespace.
+func namespaceFilter(key string) string {
+	return "@namespace:{" + encodeNamespace(key) + "}"
+}
+
+// buildQuery constructs the RediSearch query for a namespace, query text and
+// match strategy. It reports false if the query has no words to search for.
+func buildQuery(key, query string, options providers.QueryOptions, fuzziness int) (string, bool) {
+	if options.MatchStrategy == providers.MatchNOrMoreGram {
+		n := options.NGramSize
+		if n <= 0 {
+			n = defaultNGramSize
+		}
+		if utf8.RuneCountInString(strings.TrimSpace(query)) < n {
+			return "", false
+		}
+	}
+
+	var terms []string
+	for _, word := range strings.FieldsFunc(query, isSeparator) {
+		if utf8.RuneCountInString(word) < minWordLength {
+			continue
+		}
+		word = escapeWord(word)
+		term := word + "*"
+		if options.MatchStrategy != providers.MatchPrefix {
+			term = "*" + word + "*"
+		}
+		if fuzziness > 0 {
+			edits := strings.Repeat("%", fuzziness)
+			term = "(" + term + "|" + edits + word + edits + ")"
+		}
+		terms = append(terms, term)
+	}
+	if len(terms) == 0 {
+		return "", false
+	}
+
+	clauses := []string{namespaceFilter(key), "@text:(" + strings.Join(terms, " ") + ")"}
+	if options.MinScore > 0 {
+		clauses = append(clauses, "@score:["+strconv.FormatFloat(options.MinScore, 'g', -1, 64)+" +inf]")
+	}
+	return strings.Join(clauses, " "), true
+}
+
+// isSeparator reports whether RediSearch splits words at r.
+func isSeparator(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune(separators, r)
+}
+
+// escapeWord escapes the characters of a query word that RediSearch would
+// otherwise read as query syntax.
+func escapeWord(word string) string {
+	var b strings.Builder
+	for _, r := range word {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// parseSearchReply converts an FT.SEARCH reply, the total count followed by the
+// key and field-value list of each result, into provider results.
+func parseSearchReply(reply interface{}) ([]providers.ProviderResult, error) {
+	items, ok := reply.([]interface{})
+	if !ok || len(items) == 0 {
+		return nil, fmt.Errorf("unexpected search reply of type %T", reply)
+	}
+
+	results := make([]providers.ProviderResult, 0, (len(items)-1)/2)
+	for i := 2; i < len(items); i += 2 {
+		fields, ok := items[i].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected search result fields of type %T", items[i])
+		}
+
+		result := providers.ProviderResult{}
+		for j := 0; j+1 < len(fields); j += 2 {
+			name, _ := fields[j].(string)
+			value, _ := fields[j+1].(string)
+			switch name {
+			case "entry_id":
+				result.ID = value
+			case "display":
+				result.Display = value
+			case "score":
+				result.Score, _ = strconv.ParseFloat(value, 64)
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
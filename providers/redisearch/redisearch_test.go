@@ -0,0 +1,254 @@
+package redisearch
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+const testKey = "test"
+
+// startRedisStack starts a Redis Stack container and returns its address,
+// skipping the test when Docker is unavailable.
+func startRedisStack(t *testing.T) string {
+	t.Helper()
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	ctx := context.Background()
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "redis/redis-stack-server:latest",
+			ExposedPorts: []string{"6379/tcp"},
+			WaitingFor:   wait.ForLog("Ready to accept connections").WithStartupTimeout(time.Minute),
+		},
+		Started: true,
+	})
+	testcontainers.CleanupContainer(t, container)
+	if err != nil {
+		t.Fatalf("failed to start Redis Stack container: %v", err)
+	}
+	host, err := container.Host(ctx)
+	if err != nil {
+		t.Fatalf("failed to get container host: %v", err)
+	}
+	port, err := container.MappedPort(ctx, "6379")
+	if err != nil {
+		t.Fatalf("failed to get container port: %v", err)
+	}
+	return fmt.Sprintf("%s:%s", host, port.Port())
+}
+
+// newTestProvider returns a provider with config, closed when the test ends.
+func newTestProvider(t *testing.T, config Config) *Provider {
+	t.Helper()
+	provider, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = provider.Close() })
+	return provider
+}
+
+// queryIDs returns the IDs of the results of a query, in order.
+func queryIDs(t *testing.T, provider *Provider, key, query string, options providers.QueryOptions) []string {
+	t.Helper()
+	results, err := provider.Query(context.Background(), key, query, options)
+	if err != nil {
+		t.Fatalf("Query(%q) error = %v", query, err)
+	}
+	ids := make([]string, len(results))
+	for i, result := range results {
+		ids[i] = result.ID
+	}
+	return ids
+}
+
+func TestRediSearchProvider_RoundTrip(t *testing.T) {
+	addr := startRedisStack(t)
+	provider := newTestProvider(t, Config{Addr: addr})
+	ctx := context.Background()
+
+	entries := []struct {
+		id, text string
+		score    float64
+	}{
+		{"1", "Mumbai", 2},
+		{"2", "Navi Mumbai", 1},
+		{"3", "Mumbra", 3},
+		{"4", "Pune", 4},
+	}
+	for _, entry := range entries {
+		options := providers.IndexOptions{Score: entry.score, ContentHash: "v1-" + entry.id}
+		if err := provider.Index(ctx, testKey, entry.id, entry.text, entry.text+", MH", options); err != nil {
+			t.Fatalf("Index(%s) error = %v", entry.id, err)
+		}
+	}
+	// The same IDs in another namespace must not show up in testKey
+	if err := provider.Index(ctx, "other", "1", "Mumbai Other", "Mumbai Other", providers.IndexOptions{Score: 9}); err != nil {
+		t.Fatalf("Index() in another namespace error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		strategy providers.MatchStrategy
+		limit    int
+		want     []string
+	}{
+		{"prefix", "MUM", providers.MatchPrefix, 10, []string{"3", "1", "2"}},
+		{"prefix of each word", "navi mum", providers.MatchPrefix, 10, []string{"2"}},
+		{"prefix does not match inside words", "umb", providers.MatchPrefix, 10, []string{}},
+		{"substring", "umb", providers.MatchSubstring, 10, []string{"3", "1", "2"}},
+		{"ngram", "mbra", providers.MatchNGram, 10, []string{"3"}},
+		{"n-or-more too short", "mu", providers.MatchNOrMoreGram, 10, []string{}},
+		{"limit", "mum", providers.MatchPrefix, 1, []string{"3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := providers.QueryOptions{MatchStrategy: tt.strategy, NGramSize: 3, MaxResults: tt.limit}
+			if got := queryIDs(t, provider, testKey, tt.query, options); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Query(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+	prefix := providers.QueryOptions{MatchStrategy: providers.MatchPrefix, MaxResults: 10}
+	results, err := provider.Query(ctx, testKey, "pune", prefix)
+	if err != nil || len(results) != 1 || results[0].Display != "Pune, MH" || results[0].Score != 4 {
+		t.Errorf("Query(pune) = %v, %v; want entry 4 with its display and score", results, err)
+	}
+	minScore := providers.QueryOptions{MatchStrategy: providers.MatchPrefix, MaxResults: 10, MinScore: 2}
+	if got := queryIDs(t, provider, testKey, "mum", minScore); !reflect.DeepEqual(got, []string{"3", "1"}) {
+		t.Errorf("Query(mum) with MinScore 2 = %v, want [3 1]", got)
+	}
+
+	// Fuzzy matches are added to prefix matches
+	fuzzy := newTestProvider(t, Config{Addr: addr, Fuzziness: 1})
+	if got := queryIDs(t, fuzzy, testKey, "mumbay", prefix); !reflect.DeepEqual(got, []string{"1", "2"}) {
+		t.Errorf("Query(mumbay) with Fuzziness 1 = %v, want [1 2]", got)
+	}
+
+	// Reindexing an ID rewrites its hash, so its old text stops matching
+	if err := provider.Index(ctx, testKey, "3", "Thane", "Thane, MH", providers.IndexOptions{Score: 3, ContentHash: "v2-3"}); err != nil {
+		t.Fatalf("Index() overwrite error = %v", err)
+	}
+	if got := queryIDs(t, provider, testKey, "mum", prefix); !reflect.DeepEqual(got, []string{"1", "2"}) {
+		t.Errorf("Query(mum) after overwrite = %v, want [1 2]", got)
+	}
+	if hash, ok, err := provider.ContentHash(ctx, testKey, "3"); err != nil || !ok || hash != "v2-3" {
+		t.Errorf("ContentHash(3) = %q, %v, %v; want v2-3", hash, ok, err)
+	}
+	if _, ok, err := provider.ContentHash(ctx, testKey, "missing"); err != nil || ok {
+		t.Errorf("ContentHash(missing) = %v, %v; want not found", ok, err)
+	}
+
+	// IndexAtomic writes every entry
+	if err := provider.IndexAtomic(ctx, testKey, []providers.IndexEntry{
+		{ID: "5", Text: "Thane West", Display: "Thane West", Options: providers.IndexOptions{Score: 1}},
+		{ID: "6", Text: "Thanjavur", Display: "Thanjavur", Options: providers.IndexOptions{Score: 2}},
+	}); err != nil {
+		t.Fatalf("IndexAtomic() error = %v", err)
+	}
+	if got := queryIDs(t, provider, testKey, "than", prefix); !reflect.DeepEqual(got, []string{"3", "6", "5"}) {
+		t.Errorf("Query(than) after IndexAtomic = %v, want [3 6 5]", got)
+	}
+
+	// Delete and DeleteAll touch only their namespace
+	if err := provider.Delete(ctx, testKey, "1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if got := queryIDs(t, provider, testKey, "mum", prefix); !reflect.DeepEqual(got, []string{"2"}) {
+		t.Errorf("Query(mum) after Delete = %v, want [2]", got)
+	}
+	if err := provider.DeleteAll(ctx, testKey); err != nil {
+		t.Fatalf("DeleteAll() error = %v", err)
+	}
+	for _, query := range []string{"mum", "than", "pune"} {
+		if got := queryIDs(t, provider, testKey, query, prefix); len(got) != 0 {
+			t.Errorf("Query(%s) after DeleteAll = %v, want none", query, got)
+		}
+	}
+	if got := queryIDs(t, provider, "other", "mum", prefix); !reflect.DeepEqual(got, []string{"1"}) {
+		t.Errorf("Query(mum) in the other namespace after DeleteAll = %v, want [1]", got)
+	}
+}
+
+func TestBuildQuery(t *testing.T) {
+	// "cities" hex-encoded
+	const namespace = "@namespace:{636974696573}"
+
+	tests := []struct {
+		name      string
+		query     string
+		options   providers.QueryOptions
+		fuzziness int
+		want      string
+		ok        bool
+	}{
+		{"prefix", "new yo", providers.QueryOptions{MatchStrategy: providers.MatchPrefix}, 0,
+			namespace + " @text:(new* yo*)", true},
+		{"substring", "umba", providers.QueryOptions{MatchStrategy: providers.MatchSubstring}, 0,
+			namespace + " @text:(*umba*)", true},
+		{"fuzzy prefix", "mumbai", providers.QueryOptions{MatchStrategy: providers.MatchPrefix}, 2,
+			namespace + " @text:((mumbai*|%%mumbai%%))", true},
+		{"min score", "pune", providers.QueryOptions{MatchStrategy: providers.MatchPrefix, MinScore: 1.5}, 0,
+			namespace + " @text:(pune*) @score:[1.5 +inf]", true},
+		{"separators", "st. john's", providers.QueryOptions{MatchStrategy: providers.MatchPrefix}, 0,
+			namespace + " @text:(st* john*)", true},
+		{"escaped", "a/b|c", providers.QueryOptions{MatchStrategy: providers.MatchPrefix}, 0,
+			namespace + ` @text:(a\/b\|c*)`, true},
+		{"short words dropped", "a b", providers.QueryOptions{MatchStrategy: providers.MatchPrefix}, 0, "", false},
+		{"n-or-more too short", "mu", providers.QueryOptions{MatchStrategy: providers.MatchNOrMoreGram}, 0, "", false},
+		{"empty", "  ", providers.QueryOptions{MatchStrategy: providers.MatchPrefix}, 0, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := buildQuery("cities", tt.query, tt.options, tt.fuzziness)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("buildQuery(%q) = %q, %v; want %q, %v", tt.query, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestParseSearchReply(t *testing.T) {
+	reply := []interface{}{
+		int64(2),
+		"ac:doc:cities\x001", []interface{}{"entry_id", "1", "display", "Mumbai", "score", "2.5"},
+		"ac:doc:cities\x002", []interface{}{"entry_id", "2", "display", "Mumbra", "score", "0"},
+	}
+
+	got, err := parseSearchReply(reply)
+	if err != nil {
+		t.Fatalf("parseSearchReply() error = %v", err)
+	}
+	want := []providers.ProviderResult{
+		{ID: "1", Display: "Mumbai", Score: 2.5},
+		{ID: "2", Display: "Mumbra", Score: 0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseSearchReply() = %v, want %v", got, want)
+	}
+
+	if got, err := parseSearchReply([]interface{}{int64(0)}); err != nil || len(got) != 0 {
+		t.Errorf("parseSearchReply(empty) = %v, %v; want no results", got, err)
+	}
+	if _, err := parseSearchReply("OK"); err == nil {
+		t.Error("parseSearchReply(\"OK\") succeeded, want error")
+	}
+}
+
+func TestConfigDefaults(t *testing.T) {
+	config := Config{Fuzziness: 5}
+	config.setDefaults()
+	if config.IndexName != defaultIndexName || config.KeyPrefix != defaultKeyPrefix || config.Fuzziness != maxFuzziness {
+		t.Errorf("setDefaults() = %+v", config)
+	}
+}
@@ -0,0 +1,29 @@
+package redisearch
+
+import (
+	"fmt"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// init registers the RediSearch provider. Import this package with a blank identifier
+// to use RediSearch as the autocomplete backend:
+//
+//	import _ "github.com/remiges-tech/autocomplete/providers/redisearch"
+//
+//nolint:gochecknoinits // init() is the idiomatic pattern for provider registration
+func init() {
+	autocomplete.RegisterProvider("redisearch", NewProvider)
+}
+
+// NewProvider creates a new RediSearch provider from the given configuration.
+// It implements ProviderFactory and expects config to be of type redisearch.Config.
+func NewProvider(config interface{}) (providers.Provider, error) {
+	redisearchConfig, ok := config.(Config)
+	if !ok {
+		return nil, fmt.Errorf("invalid configuration type for RediSearch provider: expected redisearch.Config, got %T", config)
+	}
+
+	return New(redisearchConfig)
+}
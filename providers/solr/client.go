@@ -0,0 +1,101 @@
+package solr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// requestError reports a Solr response with an error status.
+type requestError struct {
+	status  int
+	message string
+}
+
+func (e *requestError) Error() string {
+	return fmt.Sprintf("status %d: %s", e.status, e.message)
+}
+
+// isNotFound reports whether err is a Solr response with status 404.
+func isNotFound(err error) bool {
+	var reqErr *requestError
+	return errors.As(err, &reqErr) && reqErr.status == http.StatusNotFound
+}
+
+// get sends a GET request to a path below the Solr base URL and decodes the
+// JSON response into out.
+func (p *Provider) get(ctx context.Context, path string, params url.Values, out any) error {
+	return p.do(ctx, http.MethodGet, path, params, nil, "", out)
+}
+
+// postJSON sends body as JSON to a path below the Solr base URL and decodes the
+// JSON response into out, which may be nil.
+func (p *Provider) postJSON(ctx context.Context, path string, params url.Values, body, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+	return p.do(ctx, http.MethodPost, path, params, bytes.NewReader(data), "application/json", out)
+}
+
+// postForm sends params as a form to a path below the Solr base URL, which keeps
+// long queries out of the URL, and decodes the JSON response into out.
+func (p *Provider) postForm(ctx context.Context, path string, params url.Values, out any) error {
+	return p.do(ctx, http.MethodPost, path, nil, strings.NewReader(params.Encode()), "application/x-www-form-urlencoded", out)
+}
+
+func (p *Provider) do(ctx context.Context, method, path string, params url.Values, body io.Reader, contentType string, out any) error {
+	target := p.config.URL + path
+	if len(params) > 0 {
+		target += "?" + params.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if p.config.Username != "" {
+		req.SetBasicAuth(p.config.Username, p.config.Password)
+	}
+
+	res, err := p.config.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return newRequestError(res)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// newRequestError builds the error for a failed response, using the message of
+// Solr's JSON error body when there is one.
+func newRequestError(res *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(res.Body, 64<<10))
+	var body struct {
+		Error struct {
+			Msg string `json:"msg"`
+		} `json:"error"`
+	}
+	message := strings.TrimSpace(string(data))
+	if json.Unmarshal(data, &body) == nil && body.Error.Msg != "" {
+		message = body.Error.Msg
+	}
+	return &requestError{status: res.StatusCode, message: message}
+}
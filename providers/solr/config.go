@@ -0,0 +1,71 @@
+// Package solr implements the autocomplete Provider interface using Apache Solr,
+// talking to its JSON APIs over HTTP.
+package solr
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	// defaultCommitWithin is how soon Solr commits writes by default.
+	defaultCommitWithin = time.Second
+
+	// defaultConfigSet is the configset collections are created from.
+	defaultConfigSet = "_default"
+)
+
+// Config holds Solr connection parameters and provider-specific options.
+type Config struct {
+	// URL is the base URL of Solr, e.g. "http://localhost:8983/solr".
+	URL string
+
+	// Collection is the name of the collection (or core) holding autocomplete data.
+	// In SolrCloud, a missing collection is created from ConfigSet. In standalone
+	// mode the core must already exist.
+	Collection string
+
+	// Username for basic authentication.
+	Username string
+
+	// Password for basic authentication.
+	Password string
+
+	// HTTPClient sends the requests. Default: http.DefaultClient
+	HTTPClient *http.Client
+
+	// CommitWithin is how soon Solr commits a write, making it visible to search.
+	// Default: 1 second
+	CommitWithin time.Duration
+
+	// ConfigSet, NumShards and ReplicationFactor are ONLY used when the provider
+	// creates the collection. Defaults: "_default", 1 and 1
+	ConfigSet         string
+	NumShards         int
+	ReplicationFactor int
+
+	// Suggester registers a SuggestComponent (AnalyzingInfixLookupFactory) on the
+	// collection and serves MatchPrefix queries from it instead of the edge
+	// n-gram field. The suggester is rebuilt on every commit.
+	// Default: false
+	Suggester bool
+}
+
+// setDefaults applies default values to config fields.
+func (c *Config) setDefaults() {
+	if c.HTTPClient == nil {
+		c.HTTPClient = http.DefaultClient
+	}
+	if c.CommitWithin == 0 {
+		c.CommitWithin = defaultCommitWithin
+	}
+	if c.ConfigSet == "" {
+		c.ConfigSet = defaultConfigSet
+	}
+	if c.NumShards == 0 {
+		c.NumShards = 1
+	}
+	if c.ReplicationFactor == 0 {
+		c.ReplicationFactor = 1
+	}
+}
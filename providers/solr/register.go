@@ -0,0 +1,29 @@
+package solr
+
+import (
+	"fmt"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// init registers the Solr provider. Import this package with a blank identifier
+// to use Solr as the autocomplete backend:
+//
+//	import _ "github.com/remiges-tech/autocomplete/providers/solr"
+//
+//nolint:gochecknoinits // init() is the idiomatic pattern for provider registration
+func init() {
+	autocomplete.RegisterProvider("solr", NewProvider)
+}
+
+// NewProvider creates a new Solr provider from the given configuration.
+// It implements ProviderFactory and expects config to be of type solr.Config.
+func NewProvider(config interface{}) (providers.Provider, error) {
+	solrConfig, ok := config.(Config)
+	if !ok {
+		return nil, fmt.Errorf("invalid configuration type for Solr provider: expected solr.Config, got %T", config)
+	}
+
+	return New(solrConfig)
+}
@@ -0,0 +1,216 @@
+package solr
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+const (
+	// suggestComponent and suggestHandler name the SuggestComponent and request
+	// handler registered when Config.Suggester is set.
+	suggestComponent = "autocomplete_suggest"
+	suggestHandler   = "/autocomplete_suggest"
+
+	// suggesterName names the suggester within the component.
+	suggesterName = "autocomplete"
+)
+
+// analyzer builds a Solr analyzer definition from a tokenizer and filters.
+func analyzer(tokenizer map[string]any, filters ...map[string]any) map[string]any {
+	return map[string]any{"tokenizer": tokenizer, "filters": filters}
+}
+
+var (
+	standardTokenizer = map[string]any{"class": "solr.StandardTokenizerFactory"}
+	lowerCaseFilter   = map[string]any{"class": "solr.LowerCaseFilterFactory"}
+)
+
+// fieldTypes are the analyzed field types for the match strategies, mirroring
+// the Elasticsearch provider's prefix, n-gram and substring analyzers.
+var fieldTypes = []map[string]any{
+	{
+		"name":          "autocomplete_prefix",
+		"class":         "solr.TextField",
+		"indexAnalyzer": analyzer(standardTokenizer, lowerCaseFilter, map[string]any{"class": "solr.EdgeNGramFilterFactory", "minGramSize": "1", "maxGramSize": "20"}),
+		"queryAnalyzer": analyzer(standardTokenizer, lowerCaseFilter),
+	},
+	{
+		"name":     "autocomplete_ngram",
+		"class":    "solr.TextField",
+		"analyzer": analyzer(map[string]any{"class": "solr.NGramTokenizerFactory", "minGramSize": "3", "maxGramSize": "20"}, lowerCaseFilter),
+	},
+	{
+		"name":          "autocomplete_substring",
+		"class":         "solr.TextField",
+		"indexAnalyzer": analyzer(standardTokenizer, lowerCaseFilter, map[string]any{"class": "solr.NGramFilterFactory", "minGramSize": "3", "maxGramSize": "20"}),
+		"queryAnalyzer": analyzer(standardTokenizer, lowerCaseFilter),
+	},
+}
+
+// fields are the fields of a stored document. The analyzed text fields are
+// filled from text by copy fields.
+var fields = []map[string]any{
+	{"name": "key", "type": "string", "indexed": true, "stored": true},
+	{"name": "entry_id", "type": "string", "indexed": true, "stored": true},
+	{"name": "text", "type": "text_general", "indexed": true, "stored": true, "multiValued": false},
+	{"name": "text_prefix", "type": "autocomplete_prefix", "indexed": true, "stored": false},
+	{"name": "text_ngram", "type": "autocomplete_ngram", "indexed": true, "stored": false},
+	{"name": "text_substring", "type": "autocomplete_substring", "indexed": true, "stored": false},
+	{"name": "display", "type": "string", "indexed": false, "stored": true, "docValues": false},
+	{"name": "weight", "type": "pfloat", "indexed": true, "stored": true},
+	{"name": "case_sensitive", "type": "boolean", "indexed": true, "stored": true},
+	{"name": "content_hash", "type": "string", "indexed": false, "stored": true, "docValues": false},
+	{"name": "suggest_payload", "type": "string", "indexed": false, "stored": true, "docValues": false},
+}
+
+// copyFields fill the analyzed text fields.
+var copyFields = []map[string]any{
+	{"source": "text", "dest": "text_prefix"},
+	{"source": "text", "dest": "text_ngram"},
+	{"source": "text", "dest": "text_substring"},
+}
+
+// schemaResponse is the part of the Schema API response the provider reads.
+type schemaResponse struct {
+	Schema struct {
+		FieldTypes []struct {
+			Name string `json:"name"`
+		} `json:"fieldTypes"`
+		Fields []struct {
+			Name string `json:"name"`
+		} `json:"fields"`
+		CopyFields []struct {
+			Source string `json:"source"`
+			Dest   string `json:"dest"`
+		} `json:"copyFields"`
+	} `json:"schema"`
+}
+
+// bootstrap creates the collection if it does not exist, adds the field types,
+// fields and copy fields it lacks, and registers the suggester if configured.
+func (p *Provider) bootstrap(ctx context.Context) error {
+	var schema schemaResponse
+	err := p.get(ctx, p.collectionPath("/schema"), nil, &schema)
+	if isNotFound(err) {
+		if err := p.createCollection(ctx); err != nil {
+			return fmt.Errorf("collection %s does not exist and could not be created: %w", p.config.Collection, err)
+		}
+		err = p.get(ctx, p.collectionPath("/schema"), nil, &schema)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read schema: %w", err)
+	}
+
+	if err := p.updateSchema(ctx, &schema); err != nil {
+		return fmt.Errorf("failed to update schema: %w", err)
+	}
+
+	if p.config.Suggester {
+		if err := p.registerSuggester(ctx); err != nil {
+			return fmt.Errorf("failed to register suggester: %w", err)
+		}
+	}
+	return nil
+}
+
+// createCollection creates the collection with the Collections API.
+func (p *Provider) createCollection(ctx context.Context) error {
+	params := url.Values{
+		"action":                {"CREATE"},
+		"name":                  {p.config.Collection},
+		"numShards":             {strconv.Itoa(p.config.NumShards)},
+		"replicationFactor":     {strconv.Itoa(p.config.ReplicationFactor)},
+		"collection.configName": {p.config.ConfigSet},
+	}
+	return p.get(ctx, "/admin/collections", params, nil)
+}
+
+// updateSchema adds the schema elements missing from the collection.
+func (p *Provider) updateSchema(ctx context.Context, schema *schemaResponse) error {
+	existingTypes := map[string]bool{}
+	for _, fieldType := range schema.Schema.FieldTypes {
+		existingTypes[fieldType.Name] = true
+	}
+	existingFields := map[string]bool{}
+	for _, field := range schema.Schema.Fields {
+		existingFields[field.Name] = true
+	}
+	existingCopies := map[string]bool{}
+	for _, copyField := range schema.Schema.CopyFields {
+		existingCopies[copyField.Source+">"+copyField.Dest] = true
+	}
+
+	commands := map[string][]map[string]any{}
+	for _, fieldType := range fieldTypes {
+		if !existingTypes[fieldType["name"].(string)] {
+			commands["add-field-type"] = append(commands["add-field-type"], fieldType)
+		}
+	}
+	for _, field := range fields {
+		if !existingFields[field["name"].(string)] {
+			commands["add-field"] = append(commands["add-field"], field)
+		}
+	}
+	for _, copyField := range copyFields {
+		if !existingCopies[copyField["source"].(string)+">"+copyField["dest"].(string)] {
+			commands["add-copy-field"] = append(commands["add-copy-field"], copyField)
+		}
+	}
+	if len(commands) == 0 {
+		return nil
+	}
+	return p.postJSON(ctx, p.collectionPath("/schema"), nil, commands, nil)
+}
+
+// overlayResponse is the part of the Config API overlay the provider reads.
+type overlayResponse struct {
+	Overlay struct {
+		SearchComponent map[string]any `json:"searchComponent"`
+		RequestHandler  map[string]any `json:"requestHandler"`
+	} `json:"overlay"`
+}
+
+// registerSuggester adds the SuggestComponent and its request handler with the
+// Config API, unless a previous run already did.
+func (p *Provider) registerSuggester(ctx context.Context) error {
+	var overlay overlayResponse
+	if err := p.get(ctx, p.collectionPath("/config/overlay"), nil, &overlay); err != nil {
+		return err
+	}
+
+	commands := map[string]any{}
+	if _, exists := overlay.Overlay.SearchComponent[suggestComponent]; !exists {
+		commands["add-searchcomponent"] = map[string]any{
+			"name":  suggestComponent,
+			"class": "solr.SuggestComponent",
+			"suggester": map[string]any{
+				"name":                     suggesterName,
+				"lookupImpl":               "AnalyzingInfixLookupFactory",
+				"dictionaryImpl":           "DocumentDictionaryFactory",
+				"field":                    "text",
+				"weightField":              "weight",
+				"payloadField":             "suggest_payload",
+				"contextField":             "key",
+				"suggestAnalyzerFieldType": "text_general",
+				"indexPath":                suggestComponent,
+				"buildOnCommit":            "true",
+				"buildOnStartup":           "false",
+			},
+		}
+	}
+	if _, exists := overlay.Overlay.RequestHandler[suggestHandler]; !exists {
+		commands["add-requesthandler"] = map[string]any{
+			"name":       suggestHandler,
+			"class":      "solr.SearchHandler",
+			"startup":    "lazy",
+			"defaults":   map[string]any{"suggest": true, "suggest.dictionary": suggesterName},
+			"components": []string{suggestComponent},
+		}
+	}
+	if len(commands) == 0 {
+		return nil
+	}
+	return p.postJSON(ctx, p.collectionPath("/config"), nil, commands, nil)
+}
@@ -0,0 +1,269 @@
+package solr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// defaultMaxResults is the default maximum number of results if not specified.
+const defaultMaxResults = 10
+
+// Provider implements the autocomplete Provider interface using Apache Solr.
+// All namespaces share one collection, with each document tagged by its namespace.
+//
+// Each match strategy searches its own copy of the text: MatchPrefix an edge
+// n-gram field matching the start of words, MatchNGram an n-gram field, and
+// MatchSubstring and MatchNOrMoreGram a field matching query words inside
+// indexed words. Every query word must match, always case-insensitively.
+// Results are ranked by Solr's relevance score, with the entry score breaking ties.
+type Provider struct {
+	config Config
+}
+
+// document represents the structure stored in Solr.
+type document struct {
+	ID            string  `json:"id"`
+	Key           string  `json:"key"`
+	EntryID       string  `json:"entry_id"`
+	Text          string  `json:"text"`
+	Display       string  `json:"display"`
+	Weight        float64 `json:"weight"`
+	CaseSensitive bool    `json:"case_sensitive"`
+	ContentHash   string  `json:"content_hash,omitempty"`
+
+	// Payload holds the entry for the suggester, which returns only the
+	// suggested text, its weight and this field.
+	Payload string `json:"suggest_payload,omitempty"`
+}
+
+// payload is the entry returned by the suggester, encoded as JSON.
+type payload struct {
+	ID      string  `json:"id"`
+	Display string  `json:"display"`
+	Score   float64 `json:"score"`
+}
+
+// New creates a Solr provider, creating the collection and adding the fields
+// it needs to the schema if they do not exist.
+func New(config Config) (*Provider, error) {
+	config.setDefaults()
+	config.URL = strings.TrimSuffix(config.URL, "/")
+
+	provider := &Provider{config: config}
+	if err := provider.bootstrap(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to connect to Solr: %w", err)
+	}
+	return provider, nil
+}
+
+// Index adds or updates an entry in the collection.
+func (p *Provider) Index(ctx context.Context, key, id, text, display string, options providers.IndexOptions) error {
+	doc := document{
+		ID:            generateDocumentID(key, id),
+		Key:           key,
+		EntryID:       id,
+		Text:          text,
+		Display:       display,
+		Weight:        options.Score,
+		CaseSensitive: options.CaseSensitive,
+		ContentHash:   options.ContentHash,
+	}
+	if p.config.Suggester {
+		data, err := json.Marshal(payload{ID: id, Display: display, Score: options.Score})
+		if err != nil {
+			return fmt.Errorf("failed to encode payload: %w", err)
+		}
+		doc.Payload = string(data)
+	}
+
+	if err := p.update(ctx, []document{doc}); err != nil {
+		return fmt.Errorf("failed to index document: %w", err)
+	}
+	return nil
+}
+
+// update sends an update request, to be committed within Config.CommitWithin.
+func (p *Provider) update(ctx context.Context, body any) error {
+	params := url.Values{"commitWithin": {strconv.FormatInt(p.config.CommitWithin.Milliseconds(), 10)}}
+	return p.postJSON(ctx, p.collectionPath("/update"), params, body, nil)
+}
+
+// ContentHash returns the stored content hash for an entry and whether it exists.
+func (p *Provider) ContentHash(ctx context.Context, key, id string) (string, bool, error) {
+	var response struct {
+		Doc *document `json:"doc"`
+	}
+	params := url.Values{"id": {generateDocumentID(key, id)}, "fl": {"content_hash"}}
+	if err := p.get(ctx, p.collectionPath("/get"), params, &response); err != nil {
+		return "", false, fmt.Errorf("failed to get document: %w", err)
+	}
+	if response.Doc == nil {
+		return "", false, nil
+	}
+	return response.Doc.ContentHash, true, nil
+}
+
+// SupportsMatchStrategies reports that entries can be queried under any combination
+// of strategies, since every document's text is copied into the field of each.
+func (p *Provider) SupportsMatchStrategies(strategies []providers.MatchStrategy) bool {
+	return true
+}
+
+// Query searches the namespace for entries matching the query.
+func (p *Provider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	if strings.TrimSpace(query) == "" {
+		return []providers.ProviderResult{}, nil
+	}
+	if p.config.Suggester && options.MatchStrategy == providers.MatchPrefix {
+		return p.suggest(ctx, key, query, options)
+	}
+
+	var response struct {
+		Response struct {
+			Docs []document `json:"docs"`
+		} `json:"response"`
+	}
+	if err := p.postForm(ctx, p.collectionPath("/select"), searchParams(key, query, options), &response); err != nil {
+		return nil, fmt.Errorf("failed to execute search: %w", err)
+	}
+
+	results := make([]providers.ProviderResult, 0, len(response.Response.Docs))
+	for _, doc := range response.Response.Docs {
+		results = append(results, providers.ProviderResult{ID: doc.EntryID, Display: doc.Display, Score: doc.Weight})
+	}
+	return results, nil
+}
+
+// searchParams builds the select request for a query.
+func searchParams(key, query string, options providers.QueryOptions) url.Values {
+	rows := options.MaxResults
+	if rows <= 0 {
+		rows = defaultMaxResults
+	}
+
+	params := url.Values{
+		"q":          {"{!edismax qf=" + strategyField(options.MatchStrategy) + " mm=100% v=$qq}"},
+		"qq":         {escapeQuery(query)},
+		"fq":         {"key:" + quote(key)},
+		"fl":         {"entry_id,display,weight"},
+		"sort":       {"score desc,weight desc"},
+		"rows":       {strconv.Itoa(rows)},
+		"start":      {strconv.Itoa(options.Offset)},
+		"wt":         {"json"},
+		"omitHeader": {"true"},
+	}
+	if options.MinScore > 0 {
+		params.Add("fq", "weight:["+strconv.FormatFloat(options.MinScore, 'g', -1, 64)+" TO *]")
+	}
+	return params
+}
+
+// strategyField returns the text field searched for a match strategy.
+func strategyField(strategy providers.MatchStrategy) string {
+	switch strategy {
+	case providers.MatchPrefix:
+		return "text_prefix"
+	case providers.MatchNGram:
+		return "text_ngram"
+	default:
+		return "text_substring"
+	}
+}
+
+// suggest answers a prefix query from the suggester. The suggester has no
+// offset, so it is asked for the skipped suggestions too.
+func (p *Provider) suggest(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	count := options.MaxResults
+	if count <= 0 {
+		count = defaultMaxResults
+	}
+
+	var response struct {
+		Suggest map[string]map[string]struct {
+			Suggestions []struct {
+				Payload string `json:"payload"`
+			} `json:"suggestions"`
+		} `json:"suggest"`
+	}
+	params := url.Values{
+		"suggest.q":     {query},
+		"suggest.cfq":   {quote(key)},
+		"suggest.count": {strconv.Itoa(options.Offset + count)},
+		"wt":            {"json"},
+	}
+	if err := p.get(ctx, p.collectionPath(suggestHandler), params, &response); err != nil {
+		return nil, fmt.Errorf("failed to execute suggest: %w", err)
+	}
+
+	results := []providers.ProviderResult{}
+	for i, suggestion := range response.Suggest[suggesterName][query].Suggestions {
+		if i < options.Offset {
+			continue
+		}
+		var entry payload
+		if err := json.Unmarshal([]byte(suggestion.Payload), &entry); err != nil {
+			return nil, fmt.Errorf("failed to decode suggestion payload: %w", err)
+		}
+		if entry.Score < options.MinScore {
+			continue
+		}
+		results = append(results, providers.ProviderResult{ID: entry.ID, Display: entry.Display, Score: entry.Score})
+	}
+	return results, nil
+}
+
+// Delete removes an entry from the collection.
+func (p *Provider) Delete(ctx context.Context, key, id string) error {
+	body := map[string]any{"delete": map[string]string{"id": generateDocumentID(key, id)}}
+	if err := p.update(ctx, body); err != nil {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+	return nil
+}
+
+// DeleteAll removes all entries for a given key namespace.
+func (p *Provider) DeleteAll(ctx context.Context, key string) error {
+	body := map[string]any{"delete": map[string]string{"query": "key:" + quote(key)}}
+	if err := p.update(ctx, body); err != nil {
+		return fmt.Errorf("failed to delete by query: %w", err)
+	}
+	return nil
+}
+
+// Close is a no-op; the provider holds no connections of its own.
+func (p *Provider) Close() error {
+	return nil
+}
+
+// collectionPath returns the path of a collection endpoint below the base URL.
+func (p *Provider) collectionPath(endpoint string) string {
+	return "/" + url.PathEscape(p.config.Collection) + endpoint
+}
+
+// generateDocumentID creates a unique document ID from key and id.
+func generateDocumentID(key, id string) string {
+	return fmt.Sprintf("%s:%s", key, id)
+}
+
+// quote returns s as a quoted Solr query term.
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// escapeQuery escapes the characters of the Lucene query syntax in user input.
+func escapeQuery(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`\+-!():^[]"{}~*?|&/`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,245 @@
+package solr
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// fakeSolr implements the Solr endpoints the provider uses for one collection.
+// Select and suggest match documents of the filtered namespace whose text
+// contains the query, case-insensitively, and commits are immediate.
+type fakeSolr struct {
+	mu             sync.Mutex
+	created        bool
+	schemaCommands map[string][]map[string]any
+	configCommands map[string]any
+	docs           map[string]document
+	lastSelect     url.Values
+}
+
+func newFakeSolr(t *testing.T) (*fakeSolr, *httptest.Server) {
+	t.Helper()
+	f := &fakeSolr{docs: map[string]document{}}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func (f *fakeSolr) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch path := strings.TrimPrefix(r.URL.Path, "/solr"); {
+	case path == "/admin/collections" && r.URL.Query().Get("action") == "CREATE":
+		f.created = true
+		writeJSON(w, http.StatusOK, map[string]any{"success": true})
+
+	case !f.created:
+		writeJSON(w, http.StatusNotFound, map[string]any{"error": map[string]any{"msg": "no such collection", "code": 404}})
+
+	case path == "/cities/schema" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]any{"schema": map[string]any{"fields": []map[string]string{{"name": "id"}}}})
+
+	case path == "/cities/schema":
+		_ = json.NewDecoder(r.Body).Decode(&f.schemaCommands)
+		writeJSON(w, http.StatusOK, map[string]any{})
+
+	case path == "/cities/config/overlay":
+		writeJSON(w, http.StatusOK, map[string]any{"overlay": map[string]any{}})
+
+	case path == "/cities/config":
+		_ = json.NewDecoder(r.Body).Decode(&f.configCommands)
+		writeJSON(w, http.StatusOK, map[string]any{})
+
+	case path == "/cities/update":
+		f.update(w, r)
+
+	case path == "/cities/get":
+		doc, found := f.docs[r.URL.Query().Get("id")]
+		if !found {
+			writeJSON(w, http.StatusOK, map[string]any{"doc": nil})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"doc": doc})
+
+	case path == "/cities/select":
+		_ = r.ParseForm()
+		f.lastSelect = r.PostForm
+		query := strings.ReplaceAll(r.PostForm.Get("qq"), `\`, "")
+		writeJSON(w, http.StatusOK, map[string]any{"response": map[string]any{"docs": f.match(r.PostForm.Get("fq"), query)}})
+
+	case path == "/cities"+suggestHandler:
+		params := r.URL.Query()
+		var suggestions []map[string]any
+		for _, doc := range f.match(params.Get("suggest.cfq"), params.Get("suggest.q")) {
+			suggestions = append(suggestions, map[string]any{"term": doc.Text, "weight": int(doc.Weight), "payload": doc.Payload})
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"suggest": map[string]any{suggesterName: map[string]any{
+			params.Get("suggest.q"): map[string]any{"numFound": len(suggestions), "suggestions": suggestions},
+		}}})
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeSolr) update(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("commitWithin") == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": map[string]any{"msg": "commitWithin missing"}})
+		return
+	}
+	var body json.RawMessage
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	var added []document
+	if json.Unmarshal(body, &added) == nil {
+		for _, doc := range added {
+			f.docs[doc.ID] = doc
+		}
+		writeJSON(w, http.StatusOK, map[string]any{})
+		return
+	}
+
+	var deletion struct {
+		Delete struct{ ID, Query string }
+	}
+	_ = json.Unmarshal(body, &deletion)
+	for id, doc := range f.docs {
+		if id == deletion.Delete.ID || deletion.Delete.Query == "key:"+quote(doc.Key) {
+			delete(f.docs, id)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{})
+}
+
+// match returns the documents of the namespace in a key filter whose text
+// contains the query, by descending weight.
+func (f *fakeSolr) match(keyFilter, query string) []document {
+	var matches []document
+	for _, doc := range f.docs {
+		if strings.HasSuffix(keyFilter, quote(doc.Key)) && strings.Contains(strings.ToLower(doc.Text), strings.ToLower(query)) {
+			matches = append(matches, doc)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Weight > matches[j].Weight })
+	return matches
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func TestSolrProvider(t *testing.T) {
+	fake, server := newFakeSolr(t)
+	provider, err := New(Config{URL: server.URL + "/solr/", Collection: "cities"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	if !fake.created {
+		t.Error("missing collection was not created")
+	}
+	if n := len(fake.schemaCommands["add-field"]); n != len(fields) {
+		t.Errorf("added %d fields, want %d", n, len(fields))
+	}
+	if n := len(fake.schemaCommands["add-field-type"]); n != len(fieldTypes) {
+		t.Errorf("added %d field types, want %d", n, len(fieldTypes))
+	}
+	if fake.configCommands != nil {
+		t.Error("suggester registered without Config.Suggester")
+	}
+
+	for _, entry := range []providers.IndexEntry{
+		{ID: "1", Text: "Mumbai", Display: "Mumbai, MH", Options: providers.IndexOptions{Score: 1.0, ContentHash: "h1"}},
+		{ID: "2", Text: "Navi Mumbai", Display: "Navi Mumbai", Options: providers.IndexOptions{Score: 2.0}},
+	} {
+		if err := provider.Index(ctx, "in", entry.ID, entry.Text, entry.Display, entry.Options); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	results, err := provider.Query(ctx, "in", "mum", providers.QueryOptions{MatchStrategy: providers.MatchPrefix, MinScore: 0.5})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(results) != 2 || results[0].ID != "2" || results[1].Display != "Mumbai, MH" {
+		t.Errorf("Query() = %+v", results)
+	}
+	if got := fake.lastSelect.Get("q"); got != "{!edismax qf=text_prefix mm=100% v=$qq}" {
+		t.Errorf("q = %q", got)
+	}
+	if got := fake.lastSelect["fq"]; len(got) != 2 || got[1] != "weight:[0.5 TO *]" {
+		t.Errorf("fq = %q", got)
+	}
+
+	if hash, exists, err := provider.ContentHash(ctx, "in", "1"); err != nil || !exists || hash != "h1" {
+		t.Errorf("ContentHash() = %q, %t, %v; want h1, true", hash, exists, err)
+	}
+	if err := provider.Delete(ctx, "in", "1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, exists, err := provider.ContentHash(ctx, "in", "1"); err != nil || exists {
+		t.Errorf("ContentHash() after delete = %t, %v; want false, nil", exists, err)
+	}
+
+	if err := provider.DeleteAll(ctx, "in"); err != nil {
+		t.Fatalf("DeleteAll() error = %v", err)
+	}
+	if len(fake.docs) != 0 {
+		t.Errorf("documents left after DeleteAll: %v", fake.docs)
+	}
+}
+
+func TestSolrProvider_Suggester(t *testing.T) {
+	fake, server := newFakeSolr(t)
+	fake.created = true
+	provider, err := New(Config{URL: server.URL + "/solr", Collection: "cities", Suggester: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	if fake.configCommands["add-searchcomponent"] == nil || fake.configCommands["add-requesthandler"] == nil {
+		t.Fatalf("suggester not registered: %v", fake.configCommands)
+	}
+
+	for id, text := range map[string]string{"1": "Pune", "2": "Punjab", "3": "Puducherry"} {
+		if err := provider.Index(ctx, "in", id, text, text+"!", providers.IndexOptions{Score: float64(len(text))}); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	results, err := provider.Query(ctx, "in", "Pun", providers.QueryOptions{MatchStrategy: providers.MatchPrefix, Offset: 1})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "1" || results[0].Display != "Pune!" || results[0].Score != 4 {
+		t.Errorf("Query() = %+v, want Pune after skipping Punjab", results)
+	}
+
+	// Other strategies still use the select handler.
+	if _, err := provider.Query(ctx, "in", "jab", providers.QueryOptions{MatchStrategy: providers.MatchSubstring}); err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if got := fake.lastSelect.Get("q"); !strings.Contains(got, "qf=text_substring") {
+		t.Errorf("substring q = %q", got)
+	}
+}
+
+func TestEscapeQuery(t *testing.T) {
+	if got, want := escapeQuery(`C++ (beta) key:"x"`), `C\+\+ \(beta\) key\:\"x\"`; got != want {
+		t.Errorf("escapeQuery() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,54 @@
+// Package sql implements the autocomplete Provider interface over any
+// database/sql driver, such as MySQL, MariaDB, or CockroachDB, using a single
+// portable table and LIKE patterns. Import the driver alongside this package.
+// Databases with a dedicated provider, such as PostgreSQL and SQLite, are
+// better served by it, as it uses indexes this package cannot rely on.
+package sql
+
+const (
+	// defaultTable is the table used when Config.Table is empty.
+	defaultTable = "autocomplete_entries"
+)
+
+// Placeholder selects how statement parameters are written.
+type Placeholder int
+
+const (
+	// PlaceholderQuestion writes parameters as "?", as MySQL, MariaDB, and SQLite expect.
+	PlaceholderQuestion Placeholder = iota
+
+	// PlaceholderDollar writes parameters as "$1", "$2", ..., as PostgreSQL and CockroachDB expect.
+	PlaceholderDollar
+)
+
+// Config holds database/sql connection parameters and provider-specific options.
+type Config struct {
+	// DriverName is the name the driver registered with database/sql,
+	// e.g. "mysql" or "pgx". Ignored by NewWithDB.
+	DriverName string
+
+	// DSN is the driver-specific connection string, e.g.
+	// "user:pass@tcp(localhost:3306)/app" for MySQL. Ignored by NewWithDB.
+	DSN string
+
+	// Placeholder selects the parameter syntax of the driver.
+	// Default: PlaceholderQuestion
+	Placeholder Placeholder
+
+	// Table is the name of the table holding autocomplete entries. All namespaces
+	// share the table and are isolated by its namespace column.
+	// Default: "autocomplete_entries"
+	Table string
+
+	// SkipSchemaSetup disables creating the table on startup. Set it when the
+	// database user lacks the privileges to do so and the table has been
+	// created by a migration instead (see Schema).
+	SkipSchemaSetup bool
+}
+
+// setDefaults applies default values to config fields.
+func (c *Config) setDefaults() {
+	if c.Table == "" {
+		c.Table = defaultTable
+	}
+}
@@ -0,0 +1,29 @@
+package sql
+
+import (
+	"fmt"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// init registers the database/sql provider. Import this package with a blank identifier
+// to use any database/sql database as the autocomplete backend:
+//
+//	import _ "github.com/remiges-tech/autocomplete/providers/sql"
+//
+//nolint:gochecknoinits // init() is the idiomatic pattern for provider registration
+func init() {
+	autocomplete.RegisterProvider("sql", NewProvider)
+}
+
+// NewProvider creates a new database/sql provider from the given configuration.
+// It implements ProviderFactory and expects config to be of type sql.Config.
+func NewProvider(config interface{}) (providers.Provider, error) {
+	sqlConfig, ok := config.(Config)
+	if !ok {
+		return nil, fmt.Errorf("invalid configuration type for database/sql provider: expected sql.Config, got %T", config)
+	}
+
+	return New(sqlConfig)
+}
@@ -0,0 +1,274 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+const (
+	// defaultNGramSize is the default n-gram size when not specified in options.
+	defaultNGramSize = 3
+
+	// schemaTemplate creates the entries table with types every supported database
+	// accepts. The token column holds the entry's text normalized for matching;
+	// its length keeps the primary key within MySQL's index size limit.
+	schemaTemplate = `
+CREATE TABLE IF NOT EXISTS %s (
+	namespace VARCHAR(255) NOT NULL,
+	id        VARCHAR(255) NOT NULL,
+	token     VARCHAR(1024) NOT NULL,
+	display   TEXT NOT NULL,
+	score     DOUBLE PRECISION NOT NULL,
+	PRIMARY KEY (namespace, id)
+)`
+
+	// likeEscape is the LIKE escape character. Backslash would need quoting
+	// that differs between databases.
+	likeEscape = "!"
+)
+
+// tableNamePattern restricts table names to plain, optionally schema-qualified identifiers,
+// since they are interpolated into SQL.
+var tableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// likeEscaper escapes LIKE wildcards in user queries with likeEscape.
+var likeEscaper = strings.NewReplacer(likeEscape, likeEscape+likeEscape, `%`, likeEscape+`%`, `_`, likeEscape+`_`)
+
+// Provider implements the autocomplete Provider interface over database/sql.
+// Entries of all namespaces are rows of a single table, isolated by the
+// namespace column, and matched with LIKE patterns over their normalized text.
+// All methods are safe for concurrent use.
+type Provider struct {
+	db          *sql.DB
+	table       string
+	placeholder Placeholder
+
+	// ownsDB is set when New opened db, so that Close closes it.
+	ownsDB bool
+}
+
+// New creates a new provider on a connection pool opened with the configured
+// driver. It verifies connectivity and, unless SkipSchemaSetup is set, creates
+// the table.
+func New(config Config) (*Provider, error) {
+	if config.DriverName == "" {
+		return nil, errors.New("sql driver name is required")
+	}
+	db, err := sql.Open(config.DriverName, config.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	provider, err := NewWithDB(db, config)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	provider.ownsDB = true
+	return provider, nil
+}
+
+// NewWithDB creates a new provider on an existing connection pool, e.g. one
+// shared with the rest of the application. DriverName and DSN are ignored.
+// Close leaves the pool open.
+func NewWithDB(db *sql.DB, config Config) (*Provider, error) {
+	if db == nil {
+		return nil, errors.New("sql database is nil")
+	}
+	config.setDefaults()
+	if !tableNamePattern.MatchString(config.Table) {
+		return nil, fmt.Errorf("invalid table name %q", config.Table)
+	}
+
+	ctx := context.Background()
+	if err := db.PingContext(ctx); err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	if !config.SkipSchemaSetup {
+		if _, err := db.ExecContext(ctx, Schema(config.Table)); err != nil {
+			return nil, fmt.Errorf("failed to create schema: %w", err)
+		}
+	}
+
+	return &Provider{db: db, table: config.Table, placeholder: config.Placeholder}, nil
+}
+
+// Schema returns the SQL that creates the entries table, for use in migrations
+// when Config.SkipSchemaSetup is set.
+func Schema(table string) string {
+	return fmt.Sprintf(schemaTemplate, table)
+}
+
+// param returns the placeholder of the nth statement parameter, counted from 1.
+func (p *Provider) param(n int) string {
+	if p.placeholder == PlaceholderDollar {
+		return "$" + strconv.Itoa(n)
+	}
+	return "?"
+}
+
+// Index adds or updates an entry in the autocomplete index.
+func (p *Provider) Index(ctx context.Context, key, id, text, display string, options providers.IndexOptions) error {
+	entries := []providers.IndexEntry{{ID: id, Text: text, Display: display, Options: options}}
+	if err := p.write(ctx, key, entries); err != nil {
+		return fmt.Errorf("failed to index entry: %w", err)
+	}
+	return nil
+}
+
+// IndexAtomic writes all entries inside a single transaction.
+func (p *Provider) IndexAtomic(ctx context.Context, key string, entries []providers.IndexEntry) error {
+	if err := p.write(ctx, key, entries); err != nil {
+		return fmt.Errorf("failed to index entries: %w", err)
+	}
+	return nil
+}
+
+// write replaces the rows of the entries in one transaction. Upsert syntax
+// differs between databases, so each row is deleted and inserted again.
+func (p *Provider) write(ctx context.Context, key string, entries []providers.IndexEntry) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	deleteStatement := fmt.Sprintf("DELETE FROM %s WHERE namespace = %s AND id = %s", p.table, p.param(1), p.param(2))
+	insertStatement := fmt.Sprintf("INSERT INTO %s (namespace, id, token, display, score) VALUES (%s, %s, %s, %s, %s)",
+		p.table, p.param(1), p.param(2), p.param(3), p.param(4), p.param(5))
+	for _, entry := range entries {
+		token := providers.SearchText(entry.Text, entry.Options.CaseSensitive)
+		if _, err := tx.ExecContext(ctx, deleteStatement, key, entry.ID); err != nil {
+			return fmt.Errorf("failed to replace entry %q: %w", entry.ID, err)
+		}
+		if _, err := tx.ExecContext(ctx, insertStatement, key, entry.ID, token, entry.Display, entry.Options.Score); err != nil {
+			return fmt.Errorf("failed to insert entry %q: %w", entry.ID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// Query searches for entries matching the given query.
+// Results are ranked by entry score, then ID.
+func (p *Provider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	searchQuery := providers.SearchText(query, options.CaseSensitive)
+
+	patterns, ok := matchPatterns(searchQuery, options)
+	if !ok {
+		return []providers.ProviderResult{}, nil
+	}
+
+	args := make([]interface{}, 0, len(patterns)+2)
+	args = append(args, key)
+	clauses := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		args = append(args, pattern)
+		clauses[i] = fmt.Sprintf("token LIKE %s ESCAPE '%s'", p.param(len(args)), likeEscape)
+	}
+	args = append(args, options.MaxResults)
+	statement := fmt.Sprintf(`
+SELECT id, display, score FROM %s
+WHERE namespace = %s AND %s
+ORDER BY score DESC, id ASC
+LIMIT %s`, p.table, p.param(1), strings.Join(clauses, " AND "), p.param(len(args)))
+
+	rows, err := p.db.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query autocomplete: %w", err)
+	}
+	defer rows.Close()
+
+	results := []providers.ProviderResult{}
+	for rows.Next() {
+		var result providers.ProviderResult
+		if err := rows.Scan(&result.ID, &result.Display, &result.Score); err != nil {
+			return nil, fmt.Errorf("failed to read query results: %w", err)
+		}
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read query results: %w", err)
+	}
+	return results, nil
+}
+
+// matchPatterns returns the LIKE patterns an entry's token must all match to
+// match the query under the given strategy. It reports false when no entry can
+// match, e.g. a query shorter than the n-gram size under MatchNOrMoreGram.
+func matchPatterns(searchQuery string, options providers.QueryOptions) ([]string, bool) {
+	if searchQuery == "" {
+		return nil, false
+	}
+	escaped := likeEscaper.Replace(searchQuery)
+	n := options.NGramSize
+	if n <= 0 {
+		n = defaultNGramSize
+	}
+
+	switch options.MatchStrategy {
+	case providers.MatchPrefix:
+		return []string{escaped + "%"}, true
+
+	case providers.MatchNGram:
+		if len(searchQuery) <= n {
+			// An n-gram starts with the query, so at least n-len(query) characters follow it
+			return []string{"%" + escaped + strings.Repeat("_", n-len(searchQuery)) + "%"}, true
+		}
+		// Longer queries match entries containing every n-gram of the query
+		patterns := make([]string, 0, len(searchQuery)-n+1)
+		for i := 0; i <= len(searchQuery)-n; i++ {
+			patterns = append(patterns, "%"+likeEscaper.Replace(searchQuery[i:i+n])+"%")
+		}
+		return patterns, true
+
+	case providers.MatchNOrMoreGram:
+		if len(searchQuery) < n {
+			return nil, false
+		}
+		return []string{"%" + escaped + "%"}, true
+
+	default:
+		return []string{"%" + escaped + "%"}, true
+	}
+}
+
+// SupportsMatchStrategies reports that entries can be queried under any combination
+// of strategies. Every strategy is a LIKE pattern over the same normalized text,
+// so nothing strategy-specific is stored.
+func (p *Provider) SupportsMatchStrategies(strategies []providers.MatchStrategy) bool {
+	return true
+}
+
+// Delete removes an entry from the index.
+func (p *Provider) Delete(ctx context.Context, key, id string) error {
+	_, err := p.db.ExecContext(ctx,
+		fmt.Sprintf("DELETE FROM %s WHERE namespace = %s AND id = %s", p.table, p.param(1), p.param(2)), key, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete entry: %w", err)
+	}
+	return nil
+}
+
+// DeleteAll removes all entries for a given key.
+func (p *Provider) DeleteAll(ctx context.Context, key string) error {
+	_, err := p.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE namespace = %s", p.table, p.param(1)), key)
+	if err != nil {
+		return fmt.Errorf("failed to delete entries: %w", err)
+	}
+	return nil
+}
+
+// Close closes the connection pool, unless it was passed to NewWithDB.
+func (p *Provider) Close() error {
+	if !p.ownsDB {
+		return nil
+	}
+	return p.db.Close()
+}
@@ -0,0 +1,228 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	// Registers the "sqlite" driver the tests run the provider on.
+	_ "modernc.org/sqlite"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers"
+	"github.com/remiges-tech/autocomplete/stress"
+)
+
+const testKey = "test"
+
+func testConfig(t *testing.T) Config {
+	path := filepath.Join(t.TempDir(), "entries.db")
+	return Config{
+		DriverName: "sqlite",
+		DSN:        fmt.Sprintf("file:%s?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)", path),
+	}
+}
+
+func newTestProvider(t *testing.T) *Provider {
+	provider, err := New(testConfig(t))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = provider.Close() })
+	return provider
+}
+
+func resultIDs(results []providers.ProviderResult) []string {
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+	return ids
+}
+
+func TestSQLProvider_MatchStrategies(t *testing.T) {
+	provider := newTestProvider(t)
+	ctx := context.Background()
+	entries := map[string]string{"1": "Mumbai", "2": "Navi Mumbai", "3": "Jammu", "4": "Mumbra"}
+	for id, text := range entries {
+		if err := provider.Index(ctx, testKey, id, text, text, providers.IndexOptions{Score: 1.0}); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		strategy providers.MatchStrategy
+		query    string
+		want     string
+	}{
+		{providers.MatchPrefix, "mum", "[1 4]"},
+		{providers.MatchPrefix, "umb", "[]"},
+		{providers.MatchSubstring, "umb", "[1 2 4]"},
+		{providers.MatchSubstring, "mu", "[1 2 3 4]"},
+		{providers.MatchNGram, "mu", "[1 2 4]"},
+		{providers.MatchNGram, "umbai", "[1 2]"},
+		{providers.MatchNOrMoreGram, "mu", "[]"},
+		{providers.MatchNOrMoreGram, "mmu", "[3]"},
+	}
+
+	for _, tt := range tests {
+		results, err := provider.Query(ctx, testKey, tt.query, providers.QueryOptions{
+			MaxResults:    10,
+			MatchStrategy: tt.strategy,
+			NGramSize:     3,
+		})
+		if err != nil {
+			t.Fatalf("strategy %d: Query(%q) error = %v", tt.strategy, tt.query, err)
+		}
+		if got := fmt.Sprint(resultIDs(results)); got != tt.want {
+			t.Errorf("strategy %d: Query(%q) = %v, want %v", tt.strategy, tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSQLProvider_EscapesWildcards(t *testing.T) {
+	provider := newTestProvider(t)
+	ctx := context.Background()
+	entries := map[string]string{"1": "100% cotton", "2": "1000 cotton", "3": "a_b!c", "4": "axb!c"}
+	for id, text := range entries {
+		if err := provider.Index(ctx, testKey, id, text, text, providers.IndexOptions{Score: 1.0}); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	for query, want := range map[string]string{"100%": "[1]", "a_b": "[3]", "b!c": "[3 4]"} {
+		results, err := provider.Query(ctx, testKey, query, providers.QueryOptions{MaxResults: 10, MatchStrategy: providers.MatchSubstring})
+		if err != nil {
+			t.Fatalf("Query(%q) error = %v", query, err)
+		}
+		if got := fmt.Sprint(resultIDs(results)); got != want {
+			t.Errorf("Query(%q) = %v, want %v", query, got, want)
+		}
+	}
+}
+
+func TestSQLProvider_UpdateAndDelete(t *testing.T) {
+	provider := newTestProvider(t)
+	ctx := context.Background()
+	options := providers.IndexOptions{Score: 1.0}
+	queryOptions := providers.QueryOptions{MaxResults: 10, MatchStrategy: providers.MatchPrefix}
+
+	if err := provider.Index(ctx, testKey, "1", "Mumbai", "Mumbai", options); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if err := provider.Index(ctx, testKey, "1", "Pune", "Pune, Maharashtra", options); err != nil {
+		t.Fatalf("Index() update error = %v", err)
+	}
+	if results, _ := provider.Query(ctx, testKey, "mum", queryOptions); len(results) != 0 {
+		t.Errorf("Query(mum) after update = %v, want no results", resultIDs(results))
+	}
+	results, err := provider.Query(ctx, testKey, "pun", queryOptions)
+	if err != nil || len(results) != 1 || results[0].Display != "Pune, Maharashtra" {
+		t.Errorf("Query(pun) after update = %v, %v; want the updated entry", results, err)
+	}
+
+	if err := provider.Delete(ctx, testKey, "1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if results, _ := provider.Query(ctx, testKey, "pun", queryOptions); len(results) != 0 {
+		t.Errorf("Query(pun) after delete = %v, want no results", resultIDs(results))
+	}
+}
+
+func TestSQLProvider_ScoreOrderAndNamespaces(t *testing.T) {
+	provider := newTestProvider(t)
+	ctx := context.Background()
+
+	entries := []providers.IndexEntry{
+		{ID: "1", Text: "Mumbai", Display: "Mumbai", Options: providers.IndexOptions{Score: 1.0}},
+		{ID: "2", Text: "Mumbra", Display: "Mumbra", Options: providers.IndexOptions{Score: 5.0}},
+	}
+	if err := provider.IndexAtomic(ctx, testKey, entries); err != nil {
+		t.Fatalf("IndexAtomic() error = %v", err)
+	}
+	if err := provider.Index(ctx, "other", "1", "Mumbai", "Mumbai", providers.IndexOptions{Score: 1.0}); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+
+	queryOptions := providers.QueryOptions{MaxResults: 10, MatchStrategy: providers.MatchPrefix}
+	results, err := provider.Query(ctx, testKey, "mum", queryOptions)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if got := fmt.Sprint(resultIDs(results)); got != "[2 1]" {
+		t.Errorf("Query() = %v, want the higher score first", got)
+	}
+
+	if err := provider.DeleteAll(ctx, testKey); err != nil {
+		t.Fatalf("DeleteAll() error = %v", err)
+	}
+	if results, _ := provider.Query(ctx, testKey, "mum", queryOptions); len(results) != 0 {
+		t.Errorf("Query() after DeleteAll = %v, want no results", resultIDs(results))
+	}
+	if results, _ := provider.Query(ctx, "other", "mum", queryOptions); len(results) != 1 {
+		t.Errorf("DeleteAll() should not affect other namespaces, got %v", resultIDs(results))
+	}
+}
+
+func TestParamPlaceholders(t *testing.T) {
+	question := &Provider{placeholder: PlaceholderQuestion}
+	dollar := &Provider{placeholder: PlaceholderDollar}
+	if got := question.param(3); got != "?" {
+		t.Errorf("PlaceholderQuestion param(3) = %q, want ?", got)
+	}
+	if got := dollar.param(3); got != "$3" {
+		t.Errorf("PlaceholderDollar param(3) = %q, want $3", got)
+	}
+}
+
+func TestNewWithDBLeavesPoolOpen(t *testing.T) {
+	config := testConfig(t)
+	db, err := sql.Open(config.DriverName, config.DSN)
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	provider, err := NewWithDB(db, Config{})
+	if err != nil {
+		t.Fatalf("NewWithDB() error = %v", err)
+	}
+	if err := provider.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := db.Ping(); err != nil {
+		t.Errorf("shared pool unusable after Close(): %v", err)
+	}
+}
+
+func TestNewRejectsInvalidConfig(t *testing.T) {
+	if _, err := New(Config{DSN: "file::memory:"}); err == nil {
+		t.Error("New() should require a driver name")
+	}
+	config := testConfig(t)
+	config.Table = "entries; DROP TABLE users"
+	if _, err := New(config); err == nil {
+		t.Error("New() should reject an invalid table name")
+	}
+}
+
+func TestSQLProvider_Stress(t *testing.T) {
+	strategies := []autocomplete.MatchStrategy{autocomplete.MatchPrefix, autocomplete.MatchNGram, autocomplete.MatchSubstring}
+	for _, strategy := range strategies {
+		t.Run(strategy.String(), func(t *testing.T) {
+			options := autocomplete.DefaultOptions()
+			options.MatchStrategy = strategy
+			ac, err := autocomplete.New("sql", autocomplete.NewConfigWithOptions(testConfig(t), options))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			defer func() { _ = ac.Close() }()
+
+			if err := stress.Run(context.Background(), ac, stress.Config{}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+// Package sqlite implements the autocomplete Provider interface using SQLite FTS5.
+// The whole index lives in a single database file, suiting desktop, edge, and
+// offline CLI deployments. The driver is pure Go, so no cgo toolchain is needed.
+package sqlite
+
+const (
+	// defaultTable is the table used when Config.Table is empty.
+	defaultTable = "autocomplete_entries"
+
+	// defaultBusyTimeoutMillis is how long a connection waits for a lock held by another writer.
+	defaultBusyTimeoutMillis = 5000
+)
+
+// Config holds SQLite database parameters and provider-specific options.
+type Config struct {
+	// Path is the database file, created if it does not exist.
+	// Use ":memory:" for a private in-memory database.
+	Path string
+
+	// Table is the name of the table holding autocomplete entries. All namespaces
+	// share the table and are isolated by its key column. The FTS5 index is
+	// stored in a companion table named <Table>_fts.
+	// Default: "autocomplete_entries"
+	Table string
+
+	// BusyTimeoutMillis is how long a connection waits for a lock held by another
+	// process before failing with SQLITE_BUSY.
+	// Default: 5000
+	BusyTimeoutMillis int
+}
+
+// setDefaults applies default values to config fields.
+func (c *Config) setDefaults() {
+	if c.Table == "" {
+		c.Table = defaultTable
+	}
+	if c.BusyTimeoutMillis == 0 {
+		c.BusyTimeoutMillis = defaultBusyTimeoutMillis
+	}
+}
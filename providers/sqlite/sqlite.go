@@ -0,0 +1,365 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	// Registers the pure Go "sqlite" database/sql driver, which includes FTS5.
+	_ "modernc.org/sqlite"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+const (
+	// defaultNGramSize is the default n-gram size when not specified in options.
+	defaultNGramSize = 3
+
+	// trigramLength is the shortest pattern run the FTS5 trigram index can serve.
+	trigramLength = 3
+
+	// memoryPath is the special path for a private in-memory database.
+	memoryPath = ":memory:"
+
+	// schemaTemplate creates the entries table and its FTS5 index. The entries table
+	// holds the data; its (key, search_text) index serves prefix matches as range
+	// scans. The external-content FTS5 table indexes search_text with the trigram
+	// tokenizer, which serves substring and n-gram GLOB patterns of three or more
+	// characters. Triggers keep the FTS index in sync with the entries table.
+	schemaTemplate = `
+CREATE TABLE IF NOT EXISTS %[1]s (
+	key            TEXT NOT NULL,
+	id             TEXT NOT NULL,
+	text           TEXT NOT NULL,
+	search_text    TEXT NOT NULL,
+	display        TEXT NOT NULL,
+	score          REAL NOT NULL DEFAULT 1,
+	case_sensitive INTEGER NOT NULL DEFAULT 0,
+	content_hash   TEXT NOT NULL DEFAULT '',
+	UNIQUE (key, id)
+);
+
+CREATE INDEX IF NOT EXISTS %[1]s_prefix_idx ON %[1]s (key, search_text);
+
+CREATE VIRTUAL TABLE IF NOT EXISTS %[1]s_fts USING fts5(
+	search_text,
+	content = '%[1]s',
+	content_rowid = 'rowid',
+	tokenize = 'trigram case_sensitive 1'
+);
+
+CREATE TRIGGER IF NOT EXISTS %[1]s_ai AFTER INSERT ON %[1]s BEGIN
+	INSERT INTO %[1]s_fts (rowid, search_text) VALUES (new.rowid, new.search_text);
+END;
+
+CREATE TRIGGER IF NOT EXISTS %[1]s_ad AFTER DELETE ON %[1]s BEGIN
+	INSERT INTO %[1]s_fts (%[1]s_fts, rowid, search_text) VALUES ('delete', old.rowid, old.search_text);
+END;
+
+CREATE TRIGGER IF NOT EXISTS %[1]s_au AFTER UPDATE ON %[1]s BEGIN
+	INSERT INTO %[1]s_fts (%[1]s_fts, rowid, search_text) VALUES ('delete', old.rowid, old.search_text);
+	INSERT INTO %[1]s_fts (rowid, search_text) VALUES (new.rowid, new.search_text);
+END;
+`
+
+	// upsertTemplate inserts an entry or replaces the stored entry with the same key and id.
+	upsertTemplate = `
+INSERT INTO %s (key, id, text, search_text, display, score, case_sensitive, content_hash)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (key, id) DO UPDATE SET
+	text = excluded.text,
+	search_text = excluded.search_text,
+	display = excluded.display,
+	score = excluded.score,
+	case_sensitive = excluded.case_sensitive,
+	content_hash = excluded.content_hash`
+)
+
+// tableNamePattern restricts table names to plain identifiers, since they are interpolated into SQL.
+var tableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// globEscaper escapes GLOB wildcards in user queries by wrapping them in character classes.
+var globEscaper = strings.NewReplacer(`[`, `[[]`, `*`, `[*]`, `?`, `[?]`)
+
+// Provider implements the autocomplete Provider interface using SQLite FTS5.
+// Entries of all namespaces are rows of a single table, isolated by the key column.
+// Matching uses case-sensitive GLOB patterns over text normalized at index time,
+// so case-insensitive entries are stored lowercased, as in the other providers.
+// All methods are safe for concurrent use.
+type Provider struct {
+	db    *sql.DB
+	table string
+}
+
+// New creates a new SQLite provider with the given configuration.
+// It opens (or creates) the database and creates the schema if needed.
+func New(config Config) (*Provider, error) {
+	config.setDefaults()
+	if config.Path == "" {
+		return nil, errors.New("sqlite database path is required")
+	}
+	if !tableNamePattern.MatchString(config.Table) {
+		return nil, fmt.Errorf("invalid table name %q", config.Table)
+	}
+
+	db, err := sql.Open("sqlite", dataSourceName(config))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SQLite database: %w", err)
+	}
+	if config.Path == memoryPath {
+		// Every connection to ":memory:" opens a separate database
+		db.SetMaxOpenConns(1)
+	}
+
+	ctx := context.Background()
+	if _, err := db.ExecContext(ctx, fmt.Sprintf(schemaTemplate, config.Table)); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to create schema: %w", err)
+	}
+
+	return &Provider{db: db, table: config.Table}, nil
+}
+
+// dataSourceName builds the driver DSN, enabling WAL journaling for file databases
+// so that queries do not block on concurrent writes.
+func dataSourceName(config Config) string {
+	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(%d)", config.Path, config.BusyTimeoutMillis)
+	if config.Path != memoryPath {
+		dsn += "&_pragma=journal_mode(WAL)"
+	}
+	return dsn
+}
+
+// Index adds or updates an entry in the autocomplete index.
+func (p *Provider) Index(ctx context.Context, key, id, text, display string, options providers.IndexOptions) error {
+	if err := p.upsert(ctx, p.db, key, id, text, display, options); err != nil {
+		return fmt.Errorf("failed to index entry: %w", err)
+	}
+	return nil
+}
+
+// IndexAtomic writes all entries inside a single transaction.
+func (p *Provider) IndexAtomic(ctx context.Context, key string, entries []providers.IndexEntry) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	for _, entry := range entries {
+		if err := p.upsert(ctx, tx, key, entry.ID, entry.Text, entry.Display, entry.Options); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to index entry %q: %w", entry.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit index transaction: %w", err)
+	}
+	return nil
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// upsert writes a single entry, storing its text normalized for matching.
+func (p *Provider) upsert(
+	ctx context.Context, db execer, key, id, text, display string, options providers.IndexOptions,
+) error {
+	searchText := providers.SearchText(text, options.CaseSensitive)
+
+	_, err := db.ExecContext(ctx, fmt.Sprintf(upsertTemplate, p.table),
+		key, id, text, searchText, display, options.Score, options.CaseSensitive, options.ContentHash)
+	return err
+}
+
+// ContentHash returns the stored content hash for an entry and whether it exists.
+func (p *Provider) ContentHash(ctx context.Context, key, id string) (string, bool, error) {
+	var hash string
+	err := p.db.QueryRowContext(ctx,
+		fmt.Sprintf("SELECT content_hash FROM %s WHERE key = ? AND id = ?", p.table), key, id,
+	).Scan(&hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get content hash: %w", err)
+	}
+	return hash, true, nil
+}
+
+// ListEntries returns up to count entries of the namespace in ID order,
+// starting after the ID given as cursor.
+func (p *Provider) ListEntries(ctx context.Context, key, cursor string, count int) ([]providers.StoredEntry, string, error) {
+	rows, err := p.db.QueryContext(ctx, fmt.Sprintf(
+		"SELECT id, text, display, content_hash FROM %s WHERE key = ? AND id > ? ORDER BY id LIMIT ?", p.table),
+		key, cursor, count)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []providers.StoredEntry
+	for rows.Next() {
+		var entry providers.StoredEntry
+		if err := rows.Scan(&entry.ID, &entry.Text, &entry.Display, &entry.ContentHash); err != nil {
+			return nil, "", fmt.Errorf("failed to read entries: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("failed to read entries: %w", err)
+	}
+
+	next := ""
+	if len(entries) == count {
+		next = entries[len(entries)-1].ID
+	}
+	return entries, next, nil
+}
+
+// Query searches for entries matching the given query.
+// Results are ranked by entry score, then match position, then text length.
+func (p *Provider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	searchQuery := providers.SearchText(query, options.CaseSensitive)
+
+	condition, args, ok := p.matchCondition(searchQuery, options)
+	if !ok {
+		return []providers.ProviderResult{}, nil
+	}
+
+	args = append([]interface{}{key}, args...)
+	args = append(args, searchQuery, options.MaxResults)
+	statement := fmt.Sprintf(`
+SELECT id, display, score FROM %s
+WHERE key = ? AND %s
+ORDER BY score DESC, instr(search_text, ?) ASC, length(text) ASC, id ASC
+LIMIT ?`, p.table, condition)
+
+	rows, err := p.db.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query autocomplete: %w", err)
+	}
+	defer rows.Close()
+
+	results := []providers.ProviderResult{}
+	for rows.Next() {
+		var result providers.ProviderResult
+		if err := rows.Scan(&result.ID, &result.Display, &result.Score); err != nil {
+			return nil, fmt.Errorf("failed to read query results: %w", err)
+		}
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read query results: %w", err)
+	}
+	return results, nil
+}
+
+// matchCondition returns the WHERE condition selecting entries that match the query
+// under the given strategy, and its arguments. Prefix matches are range scans on
+// the (key, search_text) index; other strategies go through the FTS5 trigram index
+// when the query is long enough, and scan the namespace otherwise.
+// It reports false when no entry can match.
+func (p *Provider) matchCondition(searchQuery string, options providers.QueryOptions) (string, []interface{}, bool) {
+	if searchQuery == "" {
+		return "", nil, false
+	}
+	escaped := globEscaper.Replace(searchQuery)
+	n := options.NGramSize
+	if n <= 0 {
+		n = defaultNGramSize
+	}
+
+	switch options.MatchStrategy {
+	case providers.MatchPrefix:
+		return "search_text GLOB ?", []interface{}{escaped + "*"}, true
+
+	case providers.MatchNGram:
+		if len(searchQuery) <= n {
+			// An n-gram starts with the query, so at least n-len(query) characters follow it
+			pattern := "*" + escaped + strings.Repeat("?", n-len(searchQuery)) + "*"
+			return p.substringCondition(pattern, len(searchQuery)), []interface{}{pattern}, true
+		}
+		// Longer queries match entries containing every n-gram of the query
+		clauses := make([]string, 0, len(searchQuery)-n+1)
+		args := make([]interface{}, 0, len(searchQuery)-n+1)
+		for i := 0; i <= len(searchQuery)-n; i++ {
+			pattern := "*" + globEscaper.Replace(searchQuery[i:i+n]) + "*"
+			clauses = append(clauses, p.substringCondition(pattern, n))
+			args = append(args, pattern)
+		}
+		return "(" + strings.Join(clauses, " AND ") + ")", args, true
+
+	case providers.MatchNOrMoreGram:
+		if len(searchQuery) < n {
+			return "", nil, false
+		}
+		pattern := "*" + escaped + "*"
+		return p.substringCondition(pattern, len(searchQuery)), []interface{}{pattern}, true
+
+	default:
+		pattern := "*" + escaped + "*"
+		return p.substringCondition(pattern, len(searchQuery)), []interface{}{pattern}, true
+	}
+}
+
+// substringCondition returns the condition for one GLOB pattern whose literal
+// part is length bytes long, using the trigram index when it can serve it.
+func (p *Provider) substringCondition(pattern string, length int) string {
+	if length < trigramLength {
+		return "search_text GLOB ?"
+	}
+	return fmt.Sprintf("rowid IN (SELECT rowid FROM %s_fts WHERE search_text GLOB ?)", p.table)
+}
+
+// SupportsMatchStrategies reports that entries can be queried under any combination
+// of strategies. Every strategy is a GLOB pattern over the same normalized text,
+// so nothing strategy-specific is stored.
+func (p *Provider) SupportsMatchStrategies(strategies []providers.MatchStrategy) bool {
+	return true
+}
+
+// Delete removes an entry from the index.
+func (p *Provider) Delete(ctx context.Context, key, id string) error {
+	_, err := p.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE key = ? AND id = ?", p.table), key, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete entry: %w", err)
+	}
+	return nil
+}
+
+// DeleteAll removes all entries for a given key.
+func (p *Provider) DeleteAll(ctx context.Context, key string) error {
+	_, err := p.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE key = ?", p.table), key)
+	if err != nil {
+		return fmt.Errorf("failed to delete entries: %w", err)
+	}
+	return nil
+}
+
+// Compact merges the segments of the FTS index into one, then rebuilds the
+// database file with VACUUM to release the pages of deleted and replaced
+// entries and, for file databases, truncates the write-ahead log.
+func (p *Provider) Compact(ctx context.Context) error {
+	statements := []string{
+		fmt.Sprintf("INSERT INTO %[1]s_fts (%[1]s_fts) VALUES ('optimize')", p.table),
+		"VACUUM",
+		"PRAGMA wal_checkpoint(TRUNCATE)",
+	}
+	for _, statement := range statements {
+		if _, err := p.db.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("failed to compact database: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close closes the database.
+func (p *Provider) Close() error {
+	return p.db.Close()
+}
@@ -0,0 +1,254 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers"
+	"github.com/remiges-tech/autocomplete/stress"
+)
+
+const testKey = "test"
+
+func newTestProvider(t *testing.T) *Provider {
+	t.Helper()
+	provider, err := New(Config{Path: filepath.Join(t.TempDir(), "autocomplete.db")})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = provider.Close() })
+	return provider
+}
+
+func resultIDs(results []providers.ProviderResult) []string {
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+	return ids
+}
+
+func TestSQLiteProvider_MatchStrategies(t *testing.T) {
+	provider := newTestProvider(t)
+	ctx := context.Background()
+
+	for id, text := range map[string]string{"1": "Mumbai", "2": "Navi Mumbai", "3": "Jammu", "4": "50% off*"} {
+		if err := provider.Index(ctx, testKey, id, text, text, providers.IndexOptions{Score: 1.0}); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		strategy providers.MatchStrategy
+		query    string
+		want     string
+	}{
+		{providers.MatchPrefix, "mum", "[1]"},
+		{providers.MatchPrefix, "navi m", "[2]"},
+		{providers.MatchNGram, "mu", "[1 2]"},
+		{providers.MatchNGram, "umba", "[1 2]"},
+		{providers.MatchNOrMoreGram, "mumb", "[1 2]"},
+		{providers.MatchNOrMoreGram, "mu", "[]"},
+		{providers.MatchSubstring, "mu", "[1 3 2]"},
+		{providers.MatchSubstring, "mumbai", "[1 2]"},
+		{providers.MatchSubstring, "% off*", "[4]"},
+		{providers.MatchSubstring, "*", "[4]"},
+		{providers.MatchSubstring, "xyz", "[]"},
+	}
+
+	for _, tt := range tests {
+		results, err := provider.Query(ctx, testKey, tt.query, providers.QueryOptions{
+			MaxResults:    10,
+			MatchStrategy: tt.strategy,
+			NGramSize:     3,
+		})
+		if err != nil {
+			t.Fatalf("strategy %d: Query(%q) error = %v", tt.strategy, tt.query, err)
+		}
+		if got := fmt.Sprint(resultIDs(results)); got != tt.want {
+			t.Errorf("strategy %d: Query(%q) = %v, want %v", tt.strategy, tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSQLiteProvider_CaseSensitivity(t *testing.T) {
+	provider := newTestProvider(t)
+	ctx := context.Background()
+
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchSubstring, CaseSensitive: true}
+	if err := provider.Index(ctx, testKey, "1", "Navi Mumbai", "Navi Mumbai", options); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+
+	for query, want := range map[string]int{"Mumbai": 1, "mumbai": 0} {
+		results, err := provider.Query(ctx, testKey, query, providers.QueryOptions{
+			MaxResults: 10, MatchStrategy: providers.MatchSubstring, CaseSensitive: true,
+		})
+		if err != nil {
+			t.Fatalf("Query() error = %v", err)
+		}
+		if len(results) != want {
+			t.Errorf("Query(%q) returned %d results, want %d", query, len(results), want)
+		}
+	}
+}
+
+func TestSQLiteProvider_UpdateDeleteAndNamespaces(t *testing.T) {
+	provider := newTestProvider(t)
+	ctx := context.Background()
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchSubstring, ContentHash: "h1"}
+	queryOptions := providers.QueryOptions{MaxResults: 10, MatchStrategy: providers.MatchSubstring}
+
+	if err := provider.IndexAtomic(ctx, testKey, []providers.IndexEntry{
+		{ID: "1", Text: "Pune", Display: "Pune", Options: options},
+		{ID: "2", Text: "Nagpur", Display: "Nagpur", Options: options},
+	}); err != nil {
+		t.Fatalf("IndexAtomic() error = %v", err)
+	}
+	if err := provider.Index(ctx, "other", "1", "Pune", "Pune", options); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if err := provider.Index(ctx, testKey, "1", "Nashik", "Nashik", options); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+
+	if results, _ := provider.Query(ctx, testKey, "pun", queryOptions); len(results) != 0 {
+		t.Errorf("previous text still matches after update: %v", resultIDs(results))
+	}
+	if results, _ := provider.Query(ctx, testKey, "ash", queryOptions); len(results) != 1 {
+		t.Errorf("updated text does not match: %v", resultIDs(results))
+	}
+	if hash, exists, _ := provider.ContentHash(ctx, testKey, "1"); !exists || hash != "h1" {
+		t.Errorf("ContentHash() = %q, %t, want h1, true", hash, exists)
+	}
+
+	if err := provider.Delete(ctx, testKey, "1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if results, _ := provider.Query(ctx, testKey, "ash", queryOptions); len(results) != 0 {
+		t.Errorf("deleted entry still matches: %v", resultIDs(results))
+	}
+	if _, exists, _ := provider.ContentHash(ctx, testKey, "1"); exists {
+		t.Error("ContentHash() reports deleted entry as existing")
+	}
+
+	if err := provider.DeleteAll(ctx, testKey); err != nil {
+		t.Fatalf("DeleteAll() error = %v", err)
+	}
+	if results, _ := provider.Query(ctx, testKey, "pur", queryOptions); len(results) != 0 {
+		t.Errorf("entry still matches after DeleteAll: %v", resultIDs(results))
+	}
+	if results, _ := provider.Query(ctx, "other", "pun", queryOptions); len(results) != 1 {
+		t.Errorf("DeleteAll affected another namespace: %v", resultIDs(results))
+	}
+}
+
+func TestSQLiteProvider_Registration(t *testing.T) {
+	ac, err := autocomplete.New("sqlite", autocomplete.NewConfig(Config{Path: ":memory:"}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer ac.Close()
+
+	ctx := context.Background()
+	if err := ac.Index(ctx, "IN", "India", "India"); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	results, err := ac.Query(ctx, "ndi", 10)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Display != "India" {
+		t.Errorf("Query() = %+v, want India", results)
+	}
+}
+
+func TestSQLiteProvider_NormalizationParity(t *testing.T) {
+	provider := newTestProvider(t)
+	ctx := context.Background()
+
+	texts := []string{"Navi Mumbai", "ÉCOLE Élémentaire", "Москва", "मुंबई"}
+	for _, strategy := range []providers.MatchStrategy{providers.MatchPrefix, providers.MatchSubstring} {
+		options := providers.IndexOptions{Score: 1.0, MatchStrategy: strategy}
+		for i, text := range texts {
+			if err := provider.Index(ctx, testKey, fmt.Sprint(i), text, text, options); err != nil {
+				t.Fatalf("Index() error = %v", err)
+			}
+		}
+
+		// Each text, queried in any case, finds its own entry.
+		for i, text := range texts {
+			for _, query := range []string{text, strings.ToUpper(text), strings.ToLower(text)} {
+				results, err := provider.Query(ctx, testKey, query, providers.QueryOptions{MaxResults: 10, MatchStrategy: strategy})
+				if err != nil {
+					t.Fatalf("Query() error = %v", err)
+				}
+				if got := resultIDs(results); len(got) != 1 || got[0] != fmt.Sprint(i) {
+					t.Errorf("strategy %d: Query(%q) = %v, want [%d]", strategy, query, got, i)
+				}
+			}
+		}
+	}
+}
+
+func TestSQLiteProvider_Stress(t *testing.T) {
+	for _, strategy := range []autocomplete.MatchStrategy{autocomplete.MatchPrefix, autocomplete.MatchNGram, autocomplete.MatchSubstring} {
+		t.Run(strategy.String(), func(t *testing.T) {
+			options := autocomplete.DefaultOptions()
+			options.MatchStrategy = strategy
+			ac, err := autocomplete.New("sqlite", autocomplete.NewConfigWithOptions(Config{Path: filepath.Join(t.TempDir(), "stress.db")}, options))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			defer func() { _ = ac.Close() }()
+
+			if err := stress.Run(context.Background(), ac, stress.Config{}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestSQLiteProvider_Compact(t *testing.T) {
+	provider := newTestProvider(t)
+	ctx := context.Background()
+
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchSubstring}
+	for i := 0; i < 500; i++ {
+		text := fmt.Sprintf("city %03d %s", i, strings.Repeat("x", 200))
+		if err := provider.Index(ctx, testKey, fmt.Sprint(i), text, text, options); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+	for i := 10; i < 500; i++ {
+		if err := provider.Delete(ctx, testKey, fmt.Sprint(i)); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+	}
+
+	freePages := func() int {
+		var n int
+		if err := provider.db.QueryRowContext(ctx, "PRAGMA freelist_count").Scan(&n); err != nil {
+			t.Fatalf("freelist_count error = %v", err)
+		}
+		return n
+	}
+	if freePages() == 0 {
+		t.Fatal("deleting entries should leave free pages")
+	}
+	if err := provider.Compact(ctx); err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	if n := freePages(); n != 0 {
+		t.Errorf("free pages after Compact() = %d, want 0", n)
+	}
+
+	results, err := provider.Query(ctx, testKey, "ty 00", providers.QueryOptions{MaxResults: 20, MatchStrategy: providers.MatchSubstring})
+	if err != nil || len(results) != 10 {
+		t.Errorf("Query() after Compact() = %v, %v; want the 10 remaining entries", resultIDs(results), err)
+	}
+}
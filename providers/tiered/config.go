@@ -0,0 +1,60 @@
+// Package tiered implements the autocomplete Provider interface as an
+// in-process index layered over a remote provider, such as Redis or PostgreSQL.
+// Queries are served from a copy of each namespace held in memory and fall
+// through to the remote provider on a miss; writes go to both.
+package tiered
+
+import (
+	"time"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+const (
+	// defaultSyncInterval is the interval used when Config.SyncInterval is zero.
+	defaultSyncInterval = time.Minute
+
+	// defaultLoadScore is the score entries are loaded with when Config.LoadOptions
+	// is nil. It matches the score autocomplete indexes entries with by default.
+	defaultLoadScore = 1.0
+)
+
+// Config holds the tiers and sync options of a tiered provider.
+type Config struct {
+	// Remote is the provider holding the authoritative copy of the entries.
+	// It must implement providers.EntryLister, so that namespaces can be loaded
+	// into memory. The tiered provider takes ownership of it and closes it on Close.
+	Remote providers.Provider
+
+	// SyncInterval is how often namespaces held in memory are reloaded from
+	// the remote provider, picking up writes made by other processes.
+	// Negative disables reloading.
+	// Default: 1 minute
+	SyncInterval time.Duration
+
+	// LoadOptions returns the options an entry loaded from the remote provider
+	// is indexed in memory with. Listings do not carry index options, so these
+	// must match those the entries were indexed with, e.g. the match strategy
+	// and the score given by autocomplete's Options.Scorer.
+	// Default: substring matching with score 1, as autocomplete.DefaultOptions
+	LoadOptions func(key string, entry providers.StoredEntry) providers.IndexOptions
+
+	// OnSyncError, if set, is called when loading a namespace from the remote
+	// provider fails. The namespace keeps being served as before the load.
+	OnSyncError func(key string, err error)
+}
+
+// setDefaults applies default values to config fields.
+func (c *Config) setDefaults() {
+	if c.SyncInterval == 0 {
+		c.SyncInterval = defaultSyncInterval
+	}
+	if c.LoadOptions == nil {
+		c.LoadOptions = defaultLoadOptions
+	}
+}
+
+// defaultLoadOptions indexes loaded entries as autocomplete's default options do.
+func defaultLoadOptions(key string, entry providers.StoredEntry) providers.IndexOptions {
+	return providers.IndexOptions{Score: defaultLoadScore, MatchStrategy: providers.MatchSubstring}
+}
@@ -0,0 +1,29 @@
+package tiered
+
+import (
+	"fmt"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// init registers the tiered provider. Import this package with a blank identifier
+// to layer an in-process index over a remote provider:
+//
+//	import _ "github.com/remiges-tech/autocomplete/providers/tiered"
+//
+//nolint:gochecknoinits // init() is the idiomatic pattern for provider registration
+func init() {
+	autocomplete.RegisterProvider("tiered", NewProvider)
+}
+
+// NewProvider creates a new tiered provider from the given configuration.
+// It implements ProviderFactory and expects config to be of type tiered.Config.
+func NewProvider(config interface{}) (providers.Provider, error) {
+	tieredConfig, ok := config.(Config)
+	if !ok {
+		return nil, fmt.Errorf("invalid configuration type for tiered provider: expected tiered.Config, got %T", config)
+	}
+
+	return New(tieredConfig)
+}
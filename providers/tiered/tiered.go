@@ -0,0 +1,277 @@
+package tiered
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/remiges-tech/autocomplete/providers"
+	"github.com/remiges-tech/autocomplete/providers/memory"
+)
+
+// loadBatchSize is the number of entries listed per call while loading a namespace.
+const loadBatchSize = 500
+
+// Provider implements the autocomplete Provider interface with two tiers.
+// The first query of a namespace starts loading it from the remote provider
+// into memory in the background; until the load completes, and whenever the
+// in-memory copy finds nothing, queries are answered by the remote provider.
+// Writes go to the remote provider first and then to the in-memory copy.
+// All methods are safe for concurrent use.
+type Provider struct {
+	remote      providers.Provider
+	lister      providers.EntryLister
+	loadOptions func(key string, entry providers.StoredEntry) providers.IndexOptions
+	onSyncError func(key string, err error)
+
+	mu     sync.Mutex
+	tiers  map[string]*tier
+	closed bool
+
+	// ctx is cancelled by Close, stopping loads and the sync loop.
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// tier is the in-memory state of a namespace.
+type tier struct {
+	// local holds the loaded copy of the namespace; nil until the first load completes.
+	local *memory.Provider
+
+	// load is the load in progress, if any.
+	load *load
+
+	// generation is incremented by DeleteAll, invalidating loads started before it.
+	generation uint64
+}
+
+// load is a copy of a namespace being filled from the remote provider.
+type load struct {
+	local      *memory.Provider
+	generation uint64
+
+	// written holds the IDs written through the provider since the load started.
+	// Their listed state may predate the write, so the load skips them.
+	written map[string]bool
+}
+
+// New creates a new tiered provider over config.Remote and starts the sync loop.
+func New(config Config) (*Provider, error) {
+	if config.Remote == nil {
+		return nil, errors.New("remote provider is required")
+	}
+	lister, ok := config.Remote.(providers.EntryLister)
+	if !ok {
+		return nil, fmt.Errorf("remote provider %T does not implement providers.EntryLister", config.Remote)
+	}
+	config.setDefaults()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Provider{
+		remote:      config.Remote,
+		lister:      lister,
+		loadOptions: config.LoadOptions,
+		onSyncError: config.OnSyncError,
+		tiers:       make(map[string]*tier),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+	if config.SyncInterval > 0 {
+		p.wg.Add(1)
+		go p.syncLoop(config.SyncInterval)
+	}
+	return p, nil
+}
+
+// Index writes an entry to the remote provider and then to the in-memory copy.
+func (p *Provider) Index(ctx context.Context, key, id, text, display string, options providers.IndexOptions) error {
+	if err := p.remote.Index(ctx, key, id, text, display, options); err != nil {
+		return err
+	}
+	p.writeLocal(key, id, func(local *memory.Provider) error {
+		return local.Index(ctx, key, id, text, display, options)
+	})
+	return nil
+}
+
+// Query searches the in-memory copy of the namespace, falling through to the
+// remote provider when the namespace is not loaded yet or nothing matches.
+func (p *Provider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	if local := p.localTier(key); local != nil {
+		results, err := local.Query(ctx, key, query, options)
+		if err == nil && len(results) > 0 {
+			return results, nil
+		}
+	}
+	return p.remote.Query(ctx, key, query, options)
+}
+
+// ListEntries lists the entries of the remote provider.
+func (p *Provider) ListEntries(ctx context.Context, key, cursor string, count int) ([]providers.StoredEntry, string, error) {
+	return p.lister.ListEntries(ctx, key, cursor, count)
+}
+
+// Delete removes an entry from the remote provider and then from the in-memory copy.
+func (p *Provider) Delete(ctx context.Context, key, id string) error {
+	if err := p.remote.Delete(ctx, key, id); err != nil {
+		return err
+	}
+	p.writeLocal(key, id, func(local *memory.Provider) error {
+		return local.Delete(ctx, key, id)
+	})
+	return nil
+}
+
+// DeleteAll removes all entries of the namespace from the remote provider and
+// empties its in-memory copy. A load in progress is discarded when it completes.
+func (p *Provider) DeleteAll(ctx context.Context, key string) error {
+	if err := p.remote.DeleteAll(ctx, key); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	t := p.tiers[key]
+	if t == nil {
+		return nil
+	}
+	t.generation++
+	if t.local != nil || t.load != nil {
+		// The namespace is now known to be empty, so it can be served from memory
+		t.local = newLocal()
+	}
+	return nil
+}
+
+// Close stops loading and syncing, then closes the remote provider.
+func (p *Provider) Close() error {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+
+	p.cancel()
+	p.wg.Wait()
+	return p.remote.Close()
+}
+
+// writeLocal applies a write that succeeded remotely to the in-memory copy of the
+// namespace and to the load in progress. The in-memory providers cannot fail.
+func (p *Provider) writeLocal(key, id string, write func(local *memory.Provider) error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	t := p.tiers[key]
+	if t == nil {
+		return
+	}
+	if t.local != nil {
+		_ = write(t.local)
+	}
+	if t.load != nil {
+		t.load.written[id] = true
+		_ = write(t.load.local)
+	}
+}
+
+// localTier returns the in-memory copy of the namespace, or nil if it is not
+// loaded yet. The first call for a namespace starts loading it.
+func (p *Provider) localTier(key string) *memory.Provider {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	t := p.tiers[key]
+	if t == nil {
+		t = &tier{}
+		p.tiers[key] = t
+	}
+	if t.local == nil && t.load == nil {
+		p.startLoad(key, t)
+	}
+	return t.local
+}
+
+// startLoad starts loading the namespace in the background.
+// The caller must hold p.mu.
+func (p *Provider) startLoad(key string, t *tier) {
+	if p.closed {
+		return
+	}
+	l := &load{local: newLocal(), generation: t.generation, written: make(map[string]bool)}
+	t.load = l
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		err := p.fill(key, l)
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		t.load = nil
+		switch {
+		case err != nil:
+			if p.onSyncError != nil && p.ctx.Err() == nil {
+				p.onSyncError(key, err)
+			}
+		case l.generation == t.generation:
+			t.local = l.local
+		}
+	}()
+}
+
+// fill copies the entries of the namespace from the remote provider into the load.
+func (p *Provider) fill(key string, l *load) error {
+	cursor := ""
+	for {
+		entries, next, err := p.lister.ListEntries(p.ctx, key, cursor, loadBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to load namespace %q: %w", key, err)
+		}
+
+		p.mu.Lock()
+		for _, entry := range entries {
+			if l.written[entry.ID] {
+				continue
+			}
+			options := p.loadOptions(key, entry)
+			options.ContentHash = entry.ContentHash
+			_ = l.local.Index(p.ctx, key, entry.ID, entry.Text, entry.Display, options)
+		}
+		p.mu.Unlock()
+
+		if next == "" {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// syncLoop reloads every namespace held in memory each interval until Close.
+func (p *Provider) syncLoop(interval time.Duration) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-ticker.C:
+			p.mu.Lock()
+			for key, t := range p.tiers {
+				if t.load == nil {
+					p.startLoad(key, t)
+				}
+			}
+			p.mu.Unlock()
+		}
+	}
+}
+
+// newLocal creates an empty in-memory copy of a namespace.
+func newLocal() *memory.Provider {
+	local, _ := memory.New(memory.Config{})
+	return local
+}
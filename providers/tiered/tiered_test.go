@@ -0,0 +1,234 @@
+package tiered
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers"
+	"github.com/remiges-tech/autocomplete/providers/memory"
+	"github.com/remiges-tech/autocomplete/stress"
+)
+
+const testKey = "test"
+
+var queryOptions = providers.QueryOptions{MaxResults: 10, MatchStrategy: providers.MatchSubstring}
+
+// countingRemote is an in-memory remote provider that counts the queries it answers.
+type countingRemote struct {
+	*memory.Provider
+	queries atomic.Int64
+}
+
+func (r *countingRemote) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	r.queries.Add(1)
+	return r.Provider.Query(ctx, key, query, options)
+}
+
+// unlistableRemote hides the EntryLister implementation of the memory provider.
+type unlistableRemote struct {
+	providers.Provider
+}
+
+func newRemote(t *testing.T) *countingRemote {
+	remote, err := memory.New(memory.Config{})
+	if err != nil {
+		t.Fatalf("memory.New() error = %v", err)
+	}
+	return &countingRemote{Provider: remote}
+}
+
+func newTestProvider(t *testing.T, config Config) *Provider {
+	provider, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = provider.Close() })
+	return provider
+}
+
+func index(t *testing.T, provider providers.Provider, id, text string) {
+	options := providers.IndexOptions{Score: 1.0, MatchStrategy: providers.MatchSubstring}
+	if err := provider.Index(context.Background(), testKey, id, text, text, options); err != nil {
+		t.Fatalf("Index(%s) error = %v", id, err)
+	}
+}
+
+func queryIDs(t *testing.T, provider providers.Provider, query string) string {
+	results, err := provider.Query(context.Background(), testKey, query, queryOptions)
+	if err != nil {
+		t.Fatalf("Query(%q) error = %v", query, err)
+	}
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+	return fmt.Sprint(ids)
+}
+
+// waitLoaded queries the namespace until its in-memory copy is loaded.
+func waitLoaded(t *testing.T, provider *Provider) {
+	deadline := time.Now().Add(5 * time.Second)
+	for provider.localTier(testKey) == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("namespace was not loaded")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestTieredProvider_ServesLoadedNamespaceFromMemory(t *testing.T) {
+	remote := newRemote(t)
+	index(t, remote, "1", "Mumbai")
+	index(t, remote, "2", "Navi Mumbai")
+	provider := newTestProvider(t, Config{Remote: remote, SyncInterval: -1})
+
+	// The first query falls through while the namespace loads
+	if got := queryIDs(t, provider, "mum"); got != "[1 2]" {
+		t.Errorf("Query() before load = %v, want [1 2]", got)
+	}
+	waitLoaded(t, provider)
+
+	before := remote.queries.Load()
+	if got := queryIDs(t, provider, "mum"); got != "[1 2]" {
+		t.Errorf("Query() after load = %v, want [1 2]", got)
+	}
+	if remote.queries.Load() != before {
+		t.Error("Query() of a loaded namespace should not reach the remote provider")
+	}
+
+	// Writes through the provider are visible in memory immediately
+	index(t, provider, "3", "Mumbra")
+	if err := provider.Delete(context.Background(), testKey, "1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if got := queryIDs(t, provider, "mum"); got != "[3 2]" {
+		t.Errorf("Query() after writes = %v, want [3 2]", got)
+	}
+	if remote.queries.Load() != before {
+		t.Error("Query() matching in memory should not reach the remote provider")
+	}
+	if got := queryIDs(t, remote.Provider, "mum"); got != "[3 2]" {
+		t.Errorf("remote Query() after writes = %v, want the writes written through", got)
+	}
+
+	// A miss in memory falls through
+	if got := queryIDs(t, provider, "pune"); got != "[]" {
+		t.Errorf("Query(pune) = %v, want []", got)
+	}
+	if remote.queries.Load() != before+1 {
+		t.Error("Query() without matches in memory should fall through to the remote provider")
+	}
+}
+
+func TestTieredProvider_SyncPicksUpRemoteWrites(t *testing.T) {
+	remote := newRemote(t)
+	index(t, remote, "1", "Mumbai")
+	provider := newTestProvider(t, Config{Remote: remote, SyncInterval: 10 * time.Millisecond})
+	waitLoaded(t, provider)
+
+	// Written by another process, straight to the remote provider
+	index(t, remote, "2", "Navi Mumbai")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for queryIDs(t, provider, "mum") != "[1 2]" {
+		if time.Now().After(deadline) {
+			t.Fatal("remote write was not synced into memory")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestTieredProvider_DeleteAll(t *testing.T) {
+	remote := newRemote(t)
+	index(t, remote, "1", "Mumbai")
+	provider := newTestProvider(t, Config{Remote: remote, SyncInterval: -1})
+	waitLoaded(t, provider)
+
+	if err := provider.DeleteAll(context.Background(), testKey); err != nil {
+		t.Fatalf("DeleteAll() error = %v", err)
+	}
+	if got := queryIDs(t, provider, "mum"); got != "[]" {
+		t.Errorf("Query() after DeleteAll = %v, want []", got)
+	}
+	index(t, provider, "2", "Mumbra")
+	if got := queryIDs(t, provider, "mum"); got != "[2]" {
+		t.Errorf("Query() after re-indexing = %v, want [2]", got)
+	}
+}
+
+func TestTieredProvider_LoadErrorFallsThrough(t *testing.T) {
+	remote := newRemote(t)
+	index(t, remote, "1", "Mumbai")
+	failures := make(chan error, 1)
+	provider := newTestProvider(t, Config{
+		Remote:       &failingLister{remote},
+		SyncInterval: -1,
+		OnSyncError:  func(key string, err error) { failures <- err },
+	})
+
+	if got := queryIDs(t, provider, "mum"); got != "[1]" {
+		t.Errorf("Query() = %v, want [1] from the remote provider", got)
+	}
+	select {
+	case err := <-failures:
+		if !errors.Is(err, errListing) {
+			t.Errorf("OnSyncError() error = %v, want %v", err, errListing)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnSyncError was not called")
+	}
+}
+
+var errListing = errors.New("listing failed")
+
+// failingLister is a remote provider whose listings fail.
+type failingLister struct {
+	*countingRemote
+}
+
+func (r *failingLister) ListEntries(ctx context.Context, key, cursor string, count int) ([]providers.StoredEntry, string, error) {
+	return nil, "", errListing
+}
+
+func TestNewRejectsInvalidConfig(t *testing.T) {
+	if _, err := New(Config{}); err == nil {
+		t.Error("New() should require a remote provider")
+	}
+	remote, _ := memory.New(memory.Config{})
+	if _, err := New(Config{Remote: unlistableRemote{remote}}); err == nil {
+		t.Error("New() should reject a remote provider that cannot list entries")
+	}
+}
+
+func TestTieredProvider_Stress(t *testing.T) {
+	for _, strategy := range []autocomplete.MatchStrategy{autocomplete.MatchPrefix, autocomplete.MatchNGram, autocomplete.MatchSubstring} {
+		t.Run(strategy.String(), func(t *testing.T) {
+			options := autocomplete.DefaultOptions()
+			options.MatchStrategy = strategy
+			remote, _ := memory.New(memory.Config{})
+			config := Config{
+				Remote:       remote,
+				SyncInterval: 5 * time.Millisecond,
+				LoadOptions: func(key string, entry providers.StoredEntry) providers.IndexOptions {
+					return providers.IndexOptions{
+						Score: 1.0, MatchStrategy: providers.MatchStrategy(strategy), NGramSize: options.NGramSize,
+					}
+				},
+			}
+			ac, err := autocomplete.New("tiered", autocomplete.NewConfigWithOptions(config, options))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			defer func() { _ = ac.Close() }()
+
+			if err := stress.Run(context.Background(), ac, stress.Config{}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
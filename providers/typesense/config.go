@@ -0,0 +1,55 @@
+// Package typesense implements the autocomplete Provider interface using Typesense,
+// an in-memory search engine with typo-tolerant prefix and infix search.
+package typesense
+
+import "time"
+
+const (
+	// defaultCollectionPrefix is prepended to namespaces to form collection names.
+	defaultCollectionPrefix = "autocomplete_"
+
+	// defaultConnectionTimeout bounds each request to the Typesense server.
+	defaultConnectionTimeout = 5 * time.Second
+)
+
+// Config holds Typesense connection parameters and provider-specific options.
+type Config struct {
+	// ServerURL is the URL of a single Typesense server, e.g. "http://localhost:8108".
+	ServerURL string
+
+	// Nodes are the URLs of the nodes of a Typesense cluster. Requests are spread
+	// across them and retried on another node on failure. Used instead of ServerURL
+	// when set.
+	Nodes []string
+
+	// APIKey authenticates requests. It needs document and collection permissions.
+	APIKey string
+
+	// ConnectionTimeout bounds each request to the server.
+	// Default: 5 seconds
+	ConnectionTimeout time.Duration
+
+	// Collection stores every namespace in one shared collection, with each
+	// document tagged by its namespace and queries filtered on it. When empty,
+	// each namespace gets its own collection named CollectionPrefix + namespace.
+	Collection string
+
+	// CollectionPrefix is prepended to a namespace to form its collection name
+	// when Collection is empty.
+	// Default: "autocomplete_"
+	CollectionPrefix string
+
+	// NumTypos is the number of typos allowed per query word, "0", "1" or "2".
+	// Empty keeps the Typesense default of 2.
+	NumTypos string
+}
+
+// setDefaults applies default values to config fields.
+func (c *Config) setDefaults() {
+	if c.CollectionPrefix == "" {
+		c.CollectionPrefix = defaultCollectionPrefix
+	}
+	if c.ConnectionTimeout == 0 {
+		c.ConnectionTimeout = defaultConnectionTimeout
+	}
+}
@@ -0,0 +1,29 @@
+package typesense
+
+import (
+	"fmt"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// init registers the Typesense provider. Import this package with a blank identifier
+// to use Typesense as the autocomplete backend:
+//
+//	import _ "github.com/remiges-tech/autocomplete/providers/typesense"
+//
+//nolint:gochecknoinits // init() is the idiomatic pattern for provider registration
+func init() {
+	autocomplete.RegisterProvider("typesense", NewProvider)
+}
+
+// NewProvider creates a new Typesense provider from the given configuration.
+// It implements ProviderFactory and expects config to be of type typesense.Config.
+func NewProvider(config interface{}) (providers.Provider, error) {
+	typesenseConfig, ok := config.(Config)
+	if !ok {
+		return nil, fmt.Errorf("invalid configuration type for Typesense provider: expected typesense.Config, got %T", config)
+	}
+
+	return New(typesenseConfig)
+}
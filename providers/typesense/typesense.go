@@ -0,0 +1,288 @@
+package typesense
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/typesense/typesense-go/v3/typesense"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"github.com/typesense/typesense-go/v3/typesense/api/pointer"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// defaultMaxResults is the default maximum number of results if not specified.
+const defaultMaxResults = 10
+
+// Provider implements the autocomplete Provider interface using Typesense.
+// Namespaces are stored in a collection each, or in one shared collection with
+// every document tagged by its namespace (see Config.Collection).
+//
+// The match strategy picks Typesense's search mode: MatchPrefix matches query
+// words against the start of indexed words, while the substring and n-gram
+// strategies also match them anywhere inside a word using infix search. Every
+// query word must match, with typo tolerance, and matching is always
+// case-insensitive. Results are ranked by Typesense's text match, with the
+// entry score breaking ties.
+type Provider struct {
+	client *typesense.Client
+	config Config
+
+	// ready holds the names of collections known to exist.
+	ready sync.Map
+}
+
+// document is the structure stored in Typesense.
+type document struct {
+	DocID       string  `json:"id"`
+	Key         string  `json:"key,omitempty"`
+	ID          string  `json:"entry_id"`
+	Text        string  `json:"text"`
+	Display     string  `json:"display"`
+	Score       float64 `json:"score"`
+	ContentHash string  `json:"content_hash,omitempty"`
+}
+
+// New creates a Typesense provider and checks that the server is reachable.
+func New(config Config) (*Provider, error) {
+	config.setDefaults()
+
+	options := []typesense.ClientOption{
+		typesense.WithAPIKey(config.APIKey),
+		typesense.WithConnectionTimeout(config.ConnectionTimeout),
+	}
+	if len(config.Nodes) > 0 {
+		options = append(options, typesense.WithNodes(config.Nodes))
+	} else {
+		options = append(options, typesense.WithServer(config.ServerURL))
+	}
+	client := typesense.NewClient(options...)
+
+	healthy, err := client.Health(context.Background(), config.ConnectionTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Typesense: %w", err)
+	}
+	if !healthy {
+		return nil, errors.New("failed to connect to Typesense: server is not healthy")
+	}
+
+	return &Provider{client: client, config: config}, nil
+}
+
+// Index adds or updates an entry in the namespace's collection.
+func (p *Provider) Index(ctx context.Context, key, id, text, display string, options providers.IndexOptions) error {
+	name, err := p.ensureCollection(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	doc := document{
+		DocID:       p.documentID(key, id),
+		ID:          id,
+		Text:        text,
+		Display:     display,
+		Score:       options.Score,
+		ContentHash: options.ContentHash,
+	}
+	if p.config.Collection != "" {
+		doc.Key = key
+	}
+	if _, err := p.client.Collection(name).Documents().Upsert(ctx, doc, &api.DocumentIndexParameters{}); err != nil {
+		return fmt.Errorf("failed to index document: %w", err)
+	}
+	return nil
+}
+
+// ensureCollection creates the collection holding a namespace, once per
+// collection for the lifetime of the provider.
+func (p *Provider) ensureCollection(ctx context.Context, key string) (string, error) {
+	name := p.collectionName(key)
+	if _, ready := p.ready.Load(name); ready {
+		return name, nil
+	}
+
+	_, err := p.client.Collections().Create(ctx, p.schema(name))
+	if err != nil && !hasStatus(err, http.StatusConflict) {
+		return "", fmt.Errorf("failed to create collection %s: %w", name, err)
+	}
+
+	p.ready.Store(name, struct{}{})
+	return name, nil
+}
+
+// schema returns the schema of a collection. Only the searched, filtered and
+// sorted fields are declared; Typesense stores the other document fields as-is.
+func (p *Provider) schema(name string) *api.CollectionSchema {
+	fields := []api.Field{
+		{Name: "text", Type: "string", Infix: pointer.True()},
+		{Name: "score", Type: "float"},
+	}
+	if p.config.Collection != "" {
+		fields = append(fields, api.Field{Name: "key", Type: "string"})
+	}
+	return &api.CollectionSchema{
+		Name:                name,
+		Fields:              fields,
+		DefaultSortingField: pointer.String("score"),
+	}
+}
+
+// ContentHash returns the stored content hash for an entry and whether it exists.
+func (p *Provider) ContentHash(ctx context.Context, key, id string) (string, bool, error) {
+	doc, err := p.client.Collection(p.collectionName(key)).Document(p.documentID(key, id)).Retrieve(ctx)
+	if hasStatus(err, http.StatusNotFound) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get document: %w", err)
+	}
+	hash, _ := doc["content_hash"].(string)
+	return hash, true, nil
+}
+
+// SupportsMatchStrategies reports that entries can be queried under any
+// combination of strategies, since the strategy only picks the search mode.
+func (p *Provider) SupportsMatchStrategies(strategies []providers.MatchStrategy) bool {
+	return true
+}
+
+// Query searches the namespace's collection for entries matching the query.
+func (p *Provider) Query(ctx context.Context, key, query string, options providers.QueryOptions) ([]providers.ProviderResult, error) {
+	if query == "" {
+		return []providers.ProviderResult{}, nil
+	}
+
+	response, err := p.client.Collection(p.collectionName(key)).Documents().Search(ctx, p.searchParams(key, query, options))
+	if hasStatus(err, http.StatusNotFound) {
+		return []providers.ProviderResult{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute search: %w", err)
+	}
+	if response.Hits == nil {
+		return []providers.ProviderResult{}, nil
+	}
+
+	results := make([]providers.ProviderResult, 0, len(*response.Hits))
+	for _, hit := range *response.Hits {
+		if hit.Document == nil {
+			continue
+		}
+		doc := *hit.Document
+		id, _ := doc["entry_id"].(string)
+		display, _ := doc["display"].(string)
+		score, _ := doc["score"].(float64)
+		results = append(results, providers.ProviderResult{ID: id, Display: display, Score: score})
+	}
+	return results, nil
+}
+
+// searchParams builds the search parameters for a query.
+func (p *Provider) searchParams(key, query string, options providers.QueryOptions) *api.SearchCollectionParams {
+	limit := options.MaxResults
+	if limit <= 0 {
+		limit = defaultMaxResults
+	}
+
+	infix := "always"
+	if options.MatchStrategy == providers.MatchPrefix {
+		infix = "off"
+	}
+
+	var filters []string
+	if p.config.Collection != "" {
+		filters = append(filters, "key:="+filterValue(key))
+	}
+	if options.MinScore > 0 {
+		filters = append(filters, fmt.Sprintf("score:>=%g", options.MinScore))
+	}
+
+	params := &api.SearchCollectionParams{
+		Q:                   pointer.String(query),
+		QueryBy:             pointer.String("text"),
+		Prefix:              pointer.String("true"),
+		Infix:               pointer.String(infix),
+		SortBy:              pointer.String("_text_match:desc,score:desc"),
+		IncludeFields:       pointer.String("entry_id,display,score"),
+		DropTokensThreshold: pointer.Int(0),
+		Limit:               pointer.Int(limit),
+		Offset:              pointer.Int(options.Offset),
+	}
+	if len(filters) > 0 {
+		params.FilterBy = pointer.String(strings.Join(filters, " && "))
+	}
+	if p.config.NumTypos != "" {
+		params.NumTypos = pointer.String(p.config.NumTypos)
+	}
+	return params
+}
+
+// Delete removes an entry from the namespace's collection.
+func (p *Provider) Delete(ctx context.Context, key, id string) error {
+	_, err := p.client.Collection(p.collectionName(key)).Document(p.documentID(key, id)).Delete(ctx)
+	if err != nil && !hasStatus(err, http.StatusNotFound) {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+	return nil
+}
+
+// DeleteAll removes all entries of a namespace. A namespace's own collection is
+// dropped; in a shared collection, the namespace's documents are deleted.
+func (p *Provider) DeleteAll(ctx context.Context, key string) error {
+	name := p.collectionName(key)
+
+	if p.config.Collection != "" {
+		_, err := p.client.Collection(name).Documents().Delete(ctx, &api.DeleteDocumentsParams{
+			FilterBy: pointer.String("key:=" + filterValue(key)),
+		})
+		if err != nil && !hasStatus(err, http.StatusNotFound) {
+			return fmt.Errorf("failed to delete documents: %w", err)
+		}
+		return nil
+	}
+
+	_, err := p.client.Collection(name).Delete(ctx)
+	p.ready.Delete(name)
+	if err != nil && !hasStatus(err, http.StatusNotFound) {
+		return fmt.Errorf("failed to delete collection %s: %w", name, err)
+	}
+	return nil
+}
+
+// Close is a no-op; the client holds no resources that need releasing.
+func (p *Provider) Close() error {
+	return nil
+}
+
+// collectionName returns the name of the collection holding a namespace.
+func (p *Provider) collectionName(key string) string {
+	if p.config.Collection != "" {
+		return p.config.Collection
+	}
+	return p.config.CollectionPrefix + key
+}
+
+// documentID encodes an entry ID as a document ID that is safe in URL paths. In a
+// shared collection the namespace is part of the ID, so namespaces may reuse IDs.
+func (p *Provider) documentID(key, id string) string {
+	if p.config.Collection != "" {
+		id = key + "\x00" + id
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(id))
+}
+
+// filterValue quotes a string for use in a filter_by expression.
+func filterValue(s string) string {
+	return "`" + strings.ReplaceAll(s, "`", "") + "`"
+}
+
+// hasStatus reports whether err is a Typesense response with the given HTTP status.
+func hasStatus(err error, status int) bool {
+	var httpErr *typesense.HTTPError
+	return errors.As(err, &httpErr) && httpErr.Status == status
+}
@@ -0,0 +1,288 @@
+package typesense
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+const testKey = "test"
+
+// fakeServer implements the parts of the Typesense API the provider uses.
+// Search matches documents whose text has a word starting with, or with infix
+// search containing, every query word, without typo tolerance.
+type fakeServer struct {
+	mu          sync.Mutex
+	collections map[string]map[string]map[string]any
+	schemas     map[string]map[string]any
+	lastSearch  url.Values
+}
+
+func newFakeServer(t *testing.T) (*fakeServer, *httptest.Server) {
+	t.Helper()
+	f := &fakeServer{collections: map[string]map[string]map[string]any{}, schemas: map[string]map[string]any{}}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func (f *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.URL.Path == "/health":
+		writeJSON(w, http.StatusOK, map[string]bool{"ok": true})
+
+	case r.Method == http.MethodPost && r.URL.Path == "/collections":
+		var schema map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&schema)
+		name, _ := schema["name"].(string)
+		if _, exists := f.collections[name]; exists {
+			writeJSON(w, http.StatusConflict, map[string]string{"message": "already exists"})
+			return
+		}
+		f.collections[name] = map[string]map[string]any{}
+		f.schemas[name] = schema
+		writeJSON(w, http.StatusCreated, schema)
+
+	case parts[0] == "collections" && len(parts) == 2 && r.Method == http.MethodDelete:
+		if _, exists := f.collections[parts[1]]; !exists {
+			writeJSON(w, http.StatusNotFound, map[string]string{"message": "not found"})
+			return
+		}
+		delete(f.collections, parts[1])
+		writeJSON(w, http.StatusOK, map[string]string{"name": parts[1]})
+
+	case parts[0] == "collections" && len(parts) >= 3 && parts[2] == "documents":
+		docs, exists := f.collections[parts[1]]
+		if !exists {
+			writeJSON(w, http.StatusNotFound, map[string]string{"message": "not found"})
+			return
+		}
+		f.documents(w, r, docs, parts[3:])
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeServer) documents(w http.ResponseWriter, r *http.Request, docs map[string]map[string]any, rest []string) {
+	switch {
+	case r.Method == http.MethodPost && len(rest) == 0:
+		var doc map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&doc)
+		docs[doc["id"].(string)] = doc
+		writeJSON(w, http.StatusCreated, doc)
+
+	case r.Method == http.MethodDelete && len(rest) == 0:
+		key := filterKey(r.URL.Query().Get("filter_by"))
+		deleted := 0
+		for id, doc := range docs {
+			if doc["key"] == key {
+				delete(docs, id)
+				deleted++
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]int{"num_deleted": deleted})
+
+	case r.Method == http.MethodGet && len(rest) == 1 && rest[0] == "search":
+		f.search(w, r.URL.Query(), docs)
+
+	case len(rest) == 1:
+		doc, found := docs[rest[0]]
+		if !found {
+			writeJSON(w, http.StatusNotFound, map[string]string{"message": "not found"})
+			return
+		}
+		if r.Method == http.MethodDelete {
+			delete(docs, rest[0])
+		}
+		writeJSON(w, http.StatusOK, doc)
+	}
+}
+
+func (f *fakeServer) search(w http.ResponseWriter, params url.Values, docs map[string]map[string]any) {
+	f.lastSearch = params
+	key := filterKey(params.Get("filter_by"))
+	infix := params.Get("infix") == "always"
+
+	var hits []map[string]any
+	for _, doc := range docs {
+		if key != nil && doc["key"] != key {
+			continue
+		}
+		if matchesWords(doc["text"].(string), params.Get("q"), infix) {
+			hits = append(hits, doc)
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i]["score"] != hits[j]["score"] {
+			return hits[i]["score"].(float64) > hits[j]["score"].(float64)
+		}
+		return hits[i]["entry_id"].(string) < hits[j]["entry_id"].(string)
+	})
+
+	results := make([]map[string]any, len(hits))
+	for i, doc := range hits {
+		results[i] = map[string]any{"document": doc}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"hits": results})
+}
+
+// filterKey extracts the namespace from a "key:=`...`" filter, or returns nil.
+func filterKey(filter string) any {
+	for _, clause := range strings.Split(filter, " && ") {
+		if value, ok := strings.CutPrefix(clause, "key:="); ok {
+			return strings.Trim(value, "`")
+		}
+	}
+	return nil
+}
+
+func matchesWords(text, query string, infix bool) bool {
+	words := strings.Fields(strings.ToLower(text))
+	for _, q := range strings.Fields(strings.ToLower(query)) {
+		found := false
+		for _, word := range words {
+			if strings.HasPrefix(word, q) || infix && strings.Contains(word, q) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func newTestProvider(t *testing.T, config Config) (*fakeServer, *Provider) {
+	t.Helper()
+	fake, server := newFakeServer(t)
+	config.ServerURL = server.URL
+	provider, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = provider.Close() })
+	return fake, provider
+}
+
+func TestTypesenseProvider_IndexQueryDelete(t *testing.T) {
+	fake, provider := newTestProvider(t, Config{NumTypos: "0"})
+	ctx := context.Background()
+
+	for id, text := range map[string]string{"IN/MH": "Mumbai Maharashtra", "IN/KA": "Bangalore Karnataka", "IN/NM": "Navi Mumbai"} {
+		options := providers.IndexOptions{Score: 1.0, ContentHash: "h-" + id}
+		if id == "IN/NM" {
+			options.Score = 2.0
+		}
+		if err := provider.Index(ctx, testKey, id, text, text+" (display)", options); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	schema := fake.schemas["autocomplete_test"]
+	if schema["default_sorting_field"] != "score" {
+		t.Errorf("default sorting field = %v, want score", schema["default_sorting_field"])
+	}
+
+	results, err := provider.Query(ctx, testKey, "mum", providers.QueryOptions{MaxResults: 10, MatchStrategy: providers.MatchPrefix})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(results) != 2 || results[0].ID != "IN/NM" || results[1].Display != "Mumbai Maharashtra (display)" {
+		t.Errorf("Query() = %+v, want Navi Mumbai then Mumbai", results)
+	}
+	if got := fake.lastSearch.Get("infix"); got != "off" {
+		t.Errorf("prefix query infix = %q, want off", got)
+	}
+	if got := fake.lastSearch.Get("num_typos"); got != "0" {
+		t.Errorf("num_typos = %q, want 0", got)
+	}
+
+	// Infix search matches inside words only for the substring strategy.
+	if results, _ := provider.Query(ctx, testKey, "arnat", providers.QueryOptions{MatchStrategy: providers.MatchPrefix}); len(results) != 0 {
+		t.Errorf("prefix Query(arnat) = %+v, want no results", results)
+	}
+	results, err = provider.Query(ctx, testKey, "arnat", providers.QueryOptions{MatchStrategy: providers.MatchSubstring})
+	if err != nil || len(results) != 1 || results[0].ID != "IN/KA" {
+		t.Errorf("substring Query(arnat) = %+v, %v; want IN/KA", results, err)
+	}
+
+	if hash, exists, err := provider.ContentHash(ctx, testKey, "IN/MH"); err != nil || !exists || hash != "h-IN/MH" {
+		t.Errorf("ContentHash() = %q, %t, %v; want h-IN/MH, true", hash, exists, err)
+	}
+	if err := provider.Delete(ctx, testKey, "IN/MH"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, exists, err := provider.ContentHash(ctx, testKey, "IN/MH"); err != nil || exists {
+		t.Errorf("ContentHash() after delete = %t, %v; want false, nil", exists, err)
+	}
+	if err := provider.Delete(ctx, testKey, "IN/MH"); err != nil {
+		t.Errorf("Delete() of missing entry error = %v", err)
+	}
+
+	if err := provider.DeleteAll(ctx, testKey); err != nil {
+		t.Fatalf("DeleteAll() error = %v", err)
+	}
+	if results, err := provider.Query(ctx, testKey, "mum", providers.QueryOptions{}); err != nil || len(results) != 0 {
+		t.Errorf("Query() after DeleteAll = %v, %v; want no results", results, err)
+	}
+	if err := provider.DeleteAll(ctx, testKey); err != nil {
+		t.Errorf("DeleteAll() of missing collection error = %v", err)
+	}
+
+	// The collection is created again on the next write.
+	if err := provider.Index(ctx, testKey, "IN/GA", "Goa", "Goa", providers.IndexOptions{Score: 1.0}); err != nil {
+		t.Errorf("Index() after DeleteAll error = %v", err)
+	}
+}
+
+func TestTypesenseProvider_SharedCollection(t *testing.T) {
+	fake, provider := newTestProvider(t, Config{Collection: "suggestions"})
+	ctx := context.Background()
+
+	for _, key := range []string{"cities", "towns"} {
+		if err := provider.Index(ctx, key, "1", "Pune "+key, "Pune", providers.IndexOptions{Score: 1.0}); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+	if n := len(fake.collections["suggestions"]); n != 2 {
+		t.Errorf("collection holds %d documents, want 2", n)
+	}
+
+	results, err := provider.Query(ctx, "towns", "pun", providers.QueryOptions{MinScore: 0.5})
+	if err != nil || len(results) != 1 {
+		t.Fatalf("Query() = %+v, %v; want one result", results, err)
+	}
+	if got := fake.lastSearch.Get("filter_by"); got != "key:=`towns` && score:>=0.5" {
+		t.Errorf("filter_by = %q", got)
+	}
+
+	if err := provider.DeleteAll(ctx, "cities"); err != nil {
+		t.Fatalf("DeleteAll() error = %v", err)
+	}
+	if _, exists, _ := provider.ContentHash(ctx, "cities", "1"); exists {
+		t.Error("entry of deleted namespace still exists")
+	}
+	if _, exists, _ := provider.ContentHash(ctx, "towns", "1"); !exists {
+		t.Error("DeleteAll() removed another namespace's entry")
+	}
+}
@@ -0,0 +1,66 @@
+package autocomplete
+
+import (
+	"context"
+	"math"
+)
+
+// QueryOptions override instance options for a single QueryWithOptions call.
+// Zero and nil fields keep the instance's values, or those of the
+// RequestOptions carried by the call's context.
+type QueryOptions struct {
+	// Limit is the maximum number of results, as the limit given to Query. It
+	// is subject to Options.MaxLimit and Options.LimitPolicy.
+	Limit int
+
+	// MinScore replaces Options.MinScore, e.g. zero to return weak matches to
+	// a caller that ranks them itself.
+	MinScore *float64
+
+	// CaseSensitive replaces Options.CaseSensitive. Entries are stored for the
+	// configured case handling, so a case-insensitive query of a
+	// case-sensitive namespace needs Options.CaseInsensitiveFallback, whose
+	// case-folded copy it queries, and a case-sensitive query of a
+	// case-insensitive namespace is not possible.
+	CaseSensitive *bool
+
+	// Filter replaces RequestOptions.Filter, restricting results to entries
+	// whose metadata has the given top-level field values.
+	Filter map[string]interface{}
+}
+
+// QueryWithOptions searches like Query with options overridden for this call.
+// See AutoComplete.QueryWithOptions for details.
+//
+//nolint:gocritic // hugeParam: opts is passed by value like Query's arguments
+func (a *autocompleteImpl) QueryWithOptions(ctx context.Context, query string, opts QueryOptions) ([]Result, error) {
+	if opts.MinScore != nil && (math.IsNaN(*opts.MinScore) || math.IsInf(*opts.MinScore, 0)) {
+		return nil, ErrInvalidScore
+	}
+
+	overrides := requestOptions(ctx)
+	if opts.MinScore != nil {
+		overrides.minScore = opts.MinScore
+	}
+	if opts.CaseSensitive != nil {
+		overrides.caseSensitive = opts.CaseSensitive
+	}
+	if opts.Filter != nil {
+		overrides.Filter = opts.Filter
+	}
+	return a.Query(WithRequestOptions(ctx, overrides), query, opts.Limit)
+}
+
+// queryCaseSensitive returns whether queries made with ctx match
+// case-sensitively, or ErrCaseSensitivityNotIndexed if the namespace is not
+// indexed for the case handling they ask for.
+func (a *autocompleteImpl) queryCaseSensitive(ctx context.Context) (bool, error) {
+	caseSensitive := requestOptions(ctx).caseSensitive
+	if caseSensitive == nil || *caseSensitive == a.config.Options.CaseSensitive {
+		return a.config.Options.CaseSensitive, nil
+	}
+	if *caseSensitive || !a.caseFallback() {
+		return false, ErrCaseSensitivityNotIndexed
+	}
+	return false, nil
+}
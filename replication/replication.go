@@ -0,0 +1,454 @@
+// Package replication replays the writes made to an AutoComplete instance
+// against another one, e.g. in another region, so that geo-distributed
+// deployments can serve suggestions from a nearby copy.
+//
+// A Replicator receives the writes of the source instance through its
+// Options.Hooks.OnMutation, queues them, and applies them to the target in
+// order from Run, retrying failures. Stats reports how far the target lags
+// behind. Backfill copies existing entries, to seed a new replica or to
+// catch up after the queue overflowed. With Config.WALPath set, queued writes
+// are also logged to disk, so those not yet applied when the process exits are
+// applied after it restarts:
+//
+//	replicator, _ := replication.New(replication.Config{Target: remote})
+//	config.Options.Hooks.OnMutation = replicator.OnMutation
+//	source, _ := autocomplete.New("redis", config)
+//	go replicator.Run(ctx)
+package replication
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"sync"
+	"time"
+
+	"github.com/remiges-tech/autocomplete"
+)
+
+const (
+	// defaultQueueSize is the queue size used when Config.QueueSize is zero.
+	defaultQueueSize = 10000
+
+	// defaultRetryDelay is the delay used when Config.RetryDelay is zero.
+	defaultRetryDelay = time.Second
+
+	// backfillBatchSize is the number of entries Backfill indexes per IndexBatch call.
+	backfillBatchSize = 100
+)
+
+// ErrBackfillRunning is returned by Backfill when another backfill is in progress.
+var ErrBackfillRunning = errors.New("backfill already running")
+
+// Config holds the target and queueing options of a Replicator.
+type Config struct {
+	// Target is the instance writes are replayed against. Writes are replayed
+	// as the caller made them, so it must be configured with the same options
+	// that shape what is stored, such as Normalizer and MatchStrategy.
+	Target autocomplete.AutoComplete
+
+	// QueueSize bounds the number of writes waiting to be applied. While the
+	// queue is full, further writes are dropped and counted in Stats.Dropped;
+	// run Backfill to catch up.
+	// Default: 10000
+	QueueSize int
+
+	// RetryDelay is the pause before retrying a write the target failed.
+	// Default: 1 second
+	RetryDelay time.Duration
+
+	// OnError, if set, is called with each failed attempt to apply a write,
+	// and with each write that could not be logged to the write-ahead log.
+	OnError func(mutation autocomplete.Mutation, err error)
+
+	// WALPath, if set, is the file queued writes are logged to before
+	// OnMutation returns. New queues the writes the file holds that were not
+	// applied, e.g. because the process exited while the target was
+	// unreachable, so that Run applies them. A write applied just before the
+	// process exited may be applied again, so increments through
+	// IncrementScore can count twice. Writes that cannot be logged are still
+	// queued, and reported to OnError.
+	// Default: "" (writes are queued in memory only)
+	WALPath string
+
+	// WALNoSync skips fsync after each write logged to WALPath, trading
+	// durability for write latency: writes then survive the process exiting,
+	// but not the machine crashing.
+	// Default: false
+	WALNoSync bool
+
+	// Clock, if set, is the source of the current time Stats.Lag is measured
+	// against. Set it to the source's Options.Clock, which stamps
+	// Mutation.Time, when that is set.
+	// Default: nil (the system clock)
+	Clock autocomplete.Clock
+}
+
+// Stats reports the progress of a Replicator.
+type Stats struct {
+	// Pending is the number of writes waiting to be applied.
+	Pending int
+
+	// Lag is the age of the oldest pending write, zero when none is pending.
+	Lag time.Duration
+
+	// Applied is the number of writes applied to the target.
+	Applied int64
+
+	// Failures is the number of failed attempts to apply a write.
+	Failures int64
+
+	// Dropped is the number of writes dropped because the queue was full.
+	Dropped int64
+}
+
+// BackfillStats reports the work done by Backfill.
+type BackfillStats struct {
+	// Indexed is the number of entries indexed into the target.
+	Indexed int64
+
+	// Skipped is the number of entries skipped because they were written or
+	// deleted through the source while the backfill ran.
+	Skipped int64
+}
+
+// Replicator queues the writes of a source instance and applies them to a target.
+// All methods are safe for concurrent use.
+type Replicator struct {
+	target     autocomplete.AutoComplete
+	queueSize  int
+	retryDelay time.Duration
+	onError    func(mutation autocomplete.Mutation, err error)
+	now        func() time.Time
+
+	// wake is signalled when a write is queued.
+	wake chan struct{}
+
+	// applyMu is held while a write or a backfill batch is applied, so that a
+	// backfill never overwrites a newer write of the same entry.
+	applyMu sync.Mutex
+
+	mu    sync.Mutex
+	queue []autocomplete.Mutation
+	stats Stats
+
+	// wal logs the queue when Config.WALPath is set; nil otherwise. nextSeq
+	// numbers the next write queued, so that queue[i] has number
+	// nextSeq-len(queue)+i.
+	wal     *wal
+	nextSeq uint64
+
+	// backfill tracks the entries written since the running backfill started;
+	// nil when no backfill runs.
+	backfill *backfill
+}
+
+// backfill is the state of a running Backfill.
+type backfill struct {
+	// written holds the IDs of entries written or deleted through the source.
+	written map[string]bool
+
+	// cleared is set when the namespace was cleared by DeleteAll.
+	cleared bool
+}
+
+// New creates a Replicator applying writes to config.Target.
+func New(config Config) (*Replicator, error) {
+	if config.Target == nil {
+		return nil, errors.New("replication target is required")
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaultQueueSize
+	}
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = defaultRetryDelay
+	}
+
+	r := &Replicator{
+		target:     config.Target,
+		queueSize:  config.QueueSize,
+		retryDelay: config.RetryDelay,
+		onError:    config.OnError,
+		now:        time.Now,
+		wake:       make(chan struct{}, 1),
+		nextSeq:    1,
+	}
+	if config.Clock != nil {
+		r.now = config.Clock.Now
+	}
+	if config.WALPath != "" {
+		w, pending, err := openWAL(config.WALPath, config.WALNoSync)
+		if err != nil {
+			return nil, err
+		}
+		r.wal = w
+		r.queue = pending
+		r.nextSeq += uint64(len(pending))
+	}
+	return r, nil
+}
+
+// OnMutation queues a write of the source instance. Set it as the source's
+// Options.Hooks.OnMutation. It never waits for the target; writes arriving
+// while the queue is full are dropped. With Config.WALPath set, it returns
+// once the write is logged.
+func (r *Replicator) OnMutation(ctx context.Context, mutation autocomplete.Mutation) {
+	r.mu.Lock()
+	if len(r.queue) >= r.queueSize {
+		r.stats.Dropped++
+		r.mu.Unlock()
+		return
+	}
+	var err error
+	if r.wal != nil {
+		err = r.wal.append(walRecord{Seq: r.nextSeq, Mutation: &mutation})
+	}
+	r.nextSeq++
+	r.queue = append(r.queue, mutation)
+	if r.backfill != nil {
+		r.backfill.record(mutation)
+	}
+	r.mu.Unlock()
+
+	if err != nil && r.onError != nil {
+		r.onError(mutation, err)
+	}
+
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run applies queued writes to the target in the order they were made until
+// ctx is done, then returns ctx.Err(). A write the target fails is retried
+// after Config.RetryDelay until it succeeds, holding back the writes after it.
+// Only one Run may be active at a time.
+func (r *Replicator) Run(ctx context.Context) error {
+	for {
+		mutation, ok := r.head()
+		if !ok {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-r.wake:
+			}
+			continue
+		}
+
+		r.applyMu.Lock()
+		err := r.apply(ctx, mutation)
+		r.applyMu.Unlock()
+		if err == nil {
+			if err := r.pop(); err != nil && r.onError != nil {
+				r.onError(mutation, err)
+			}
+			continue
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		r.mu.Lock()
+		r.stats.Failures++
+		r.mu.Unlock()
+		if r.onError != nil {
+			r.onError(mutation, err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(r.retryDelay):
+		}
+	}
+}
+
+// Stats returns the current replication progress.
+func (r *Replicator) Stats() Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := r.stats
+	stats.Pending = len(r.queue)
+	if len(r.queue) > 0 {
+		stats.Lag = r.now().Sub(r.queue[0].Time)
+	}
+	return stats
+}
+
+// Backfill indexes entries into the target, e.g. every entry of the source
+// read from the application's database, to seed a new replica or to catch up
+// after Stats.Dropped grew. It can run alongside Run: entries written or
+// deleted through the source after Backfill starts are skipped, as the queued
+// writes are newer, and a DeleteAll through the source stops it. Backfill
+// stops at the first entry the target fails to index.
+func (r *Replicator) Backfill(ctx context.Context, entries iter.Seq[autocomplete.Entry]) (BackfillStats, error) {
+	r.mu.Lock()
+	if r.backfill != nil {
+		r.mu.Unlock()
+		return BackfillStats{}, ErrBackfillRunning
+	}
+	state := &backfill{written: make(map[string]bool)}
+	r.backfill = state
+	r.mu.Unlock()
+
+	defer func() {
+		r.mu.Lock()
+		r.backfill = nil
+		r.mu.Unlock()
+	}()
+
+	var stats BackfillStats
+	batch := make([]autocomplete.Entry, 0, backfillBatchSize)
+	for entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+		batch = append(batch, entry)
+		if len(batch) < backfillBatchSize {
+			continue
+		}
+		done, err := r.backfillBatch(ctx, state, batch, &stats)
+		if err != nil || done {
+			return stats, err
+		}
+		batch = batch[:0]
+	}
+	if len(batch) > 0 {
+		_, err := r.backfillBatch(ctx, state, batch, &stats)
+		return stats, err
+	}
+	return stats, nil
+}
+
+// backfillBatch indexes the entries of a batch not written through the source
+// since the backfill started. It reports true when the backfill must stop
+// because the namespace was cleared.
+func (r *Replicator) backfillBatch(
+	ctx context.Context, state *backfill, batch []autocomplete.Entry, stats *BackfillStats,
+) (bool, error) {
+	r.applyMu.Lock()
+	defer r.applyMu.Unlock()
+
+	r.mu.Lock()
+	if state.cleared {
+		r.mu.Unlock()
+		return true, nil
+	}
+	pending := make([]autocomplete.Entry, 0, len(batch))
+	for _, entry := range batch {
+		if state.written[entry.ID] {
+			stats.Skipped++
+			continue
+		}
+		pending = append(pending, entry)
+	}
+	r.mu.Unlock()
+
+	for _, result := range r.target.IndexBatch(ctx, pending) {
+		if result.Err != nil {
+			return false, fmt.Errorf("failed to backfill entry %q: %w", result.ID, result.Err)
+		}
+		stats.Indexed++
+	}
+	return false, nil
+}
+
+// head returns the oldest pending write.
+func (r *Replicator) head() (autocomplete.Mutation, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.queue) == 0 {
+		return autocomplete.Mutation{}, false
+	}
+	return r.queue[0], true
+}
+
+// pop removes the oldest pending write once it has been applied, recording
+// that in the write-ahead log if there is one. A failure to record it only
+// means the write is applied again after a restart.
+func (r *Replicator) pop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.queue[0] = autocomplete.Mutation{}
+	r.queue = r.queue[1:]
+	r.stats.Applied++
+	if r.wal == nil {
+		return nil
+	}
+	return r.wal.applied(r.nextSeq-uint64(len(r.queue))-1, r.queue, r.queueSize)
+}
+
+// Close closes the write-ahead log, if Config.WALPath is set. Writes queued
+// afterwards are held in memory only; writes still queued are applied again
+// by the next Replicator opened on the log.
+func (r *Replicator) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.wal == nil {
+		return nil
+	}
+	err := r.wal.close()
+	r.wal = nil
+	return err
+}
+
+// apply replays a write against the target. Atomic writes fall back to
+// separate writes when the target cannot write atomically.
+func (r *Replicator) apply(ctx context.Context, mutation autocomplete.Mutation) error {
+	switch mutation.Op {
+	case autocomplete.MutationIndex:
+		if mutation.Atomic {
+			err := r.target.IndexAtomic(ctx, mutation.Entries)
+			if !errors.Is(err, autocomplete.ErrTransactionsUnsupported) {
+				return err
+			}
+		}
+		for _, entry := range mutation.Entries {
+			if err := indexEntry(ctx, r.target, entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	case autocomplete.MutationDelete:
+		return r.target.Delete(ctx, mutation.ID)
+	case autocomplete.MutationDeleteMany:
+		return r.target.DeleteMany(ctx, mutation.IDs)
+	case autocomplete.MutationDeleteAll:
+		return r.target.DeleteAll(ctx)
+	case autocomplete.MutationUpdateScore:
+		return r.target.UpdateScore(ctx, mutation.ID, mutation.Score)
+	case autocomplete.MutationIncrementScore:
+		return r.target.IncrementScore(ctx, mutation.ID, mutation.Score)
+	default:
+		return fmt.Errorf("unknown mutation %v", mutation.Op)
+	}
+}
+
+// indexEntry writes a single entry to target, with its metadata and score if
+// it has them.
+func indexEntry(ctx context.Context, target autocomplete.AutoComplete, entry autocomplete.Entry) error {
+	if len(entry.Metadata) > 0 || entry.Score != 0 {
+		return target.IndexBatch(ctx, []autocomplete.Entry{entry})[0].Err
+	}
+	return target.Index(ctx, entry.ID, entry.Text, entry.Display)
+}
+
+// record notes the entries a queued write touches.
+func (b *backfill) record(mutation autocomplete.Mutation) {
+	switch mutation.Op {
+	case autocomplete.MutationIndex:
+		for _, entry := range mutation.Entries {
+			b.written[entry.ID] = true
+		}
+	case autocomplete.MutationDelete:
+		b.written[mutation.ID] = true
+	case autocomplete.MutationDeleteMany:
+		for _, id := range mutation.IDs {
+			b.written[id] = true
+		}
+	case autocomplete.MutationDeleteAll:
+		b.cleared = true
+	}
+}
@@ -0,0 +1,355 @@
+package replication
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers/memory"
+)
+
+// newInstance creates an in-memory instance with prefix matching.
+func newInstance(t *testing.T, hooks autocomplete.Hooks) autocomplete.AutoComplete {
+	options := autocomplete.DefaultOptions()
+	options.MatchStrategy = autocomplete.MatchPrefix
+	options.Hooks = hooks
+	ac, err := autocomplete.New("memory", autocomplete.NewConfigWithOptions(memory.Config{}, options))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = ac.Close() })
+	return ac
+}
+
+func queryIDs(t *testing.T, ac autocomplete.AutoComplete, query string) string {
+	results, err := ac.Query(context.Background(), query, 10)
+	if err != nil {
+		t.Fatalf("Query(%q) error = %v", query, err)
+	}
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+	return fmt.Sprint(ids)
+}
+
+// waitCaughtUp waits until the replicator has no pending writes.
+func waitCaughtUp(t *testing.T, replicator *Replicator) {
+	deadline := time.Now().Add(5 * time.Second)
+	for replicator.Stats().Pending > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("replicator did not catch up: %+v", replicator.Stats())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// runReplicator runs the replicator until the test ends.
+func runReplicator(t *testing.T, replicator *Replicator) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = replicator.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func TestReplicatorReplaysWrites(t *testing.T) {
+	target := newInstance(t, autocomplete.Hooks{})
+	replicator, err := New(Config{Target: target})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	source := newInstance(t, autocomplete.Hooks{OnMutation: replicator.OnMutation})
+	runReplicator(t, replicator)
+
+	ctx := context.Background()
+	if err := source.Index(ctx, "1", "Mumbai", "Mumbai"); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	source.IndexBatch(ctx, []autocomplete.Entry{
+		{ID: "2", Text: "Mumbra", Display: "Mumbra"},
+		{ID: "bad", Text: "", Display: "not indexed"},
+	})
+	if err := source.IndexAtomic(ctx, []autocomplete.Entry{
+		{ID: "3", Text: "Mulund", Display: "Mulund"},
+		{ID: "4", Text: "Pune", Display: "Pune"},
+	}); err != nil {
+		t.Fatalf("IndexAtomic() error = %v", err)
+	}
+	if err := source.UpdateScore(ctx, "3", 10); err != nil {
+		t.Fatalf("UpdateScore() error = %v", err)
+	}
+	if err := source.IncrementScore(ctx, "2", 20); err != nil {
+		t.Fatalf("IncrementScore() error = %v", err)
+	}
+	if err := source.Delete(ctx, "1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	waitCaughtUp(t, replicator)
+	for _, query := range []string{"mu", "pune"} {
+		if got, want := queryIDs(t, target, query), queryIDs(t, source, query); got != want {
+			t.Errorf("target Query(%q) = %v, want %v as in the source", query, got, want)
+		}
+	}
+	if stats := replicator.Stats(); stats.Applied != 6 || stats.Lag != 0 {
+		t.Errorf("Stats() = %+v, want 6 writes applied and no lag", stats)
+	}
+
+	if err := source.DeleteAll(ctx); err != nil {
+		t.Fatalf("DeleteAll() error = %v", err)
+	}
+	waitCaughtUp(t, replicator)
+	if got := queryIDs(t, target, "mu"); got != "[]" {
+		t.Errorf("target Query() after DeleteAll = %v, want []", got)
+	}
+}
+
+// flakyTarget is a target whose writes fail while it is down.
+type flakyTarget struct {
+	autocomplete.AutoComplete
+	down atomic.Bool
+}
+
+func (f *flakyTarget) Index(ctx context.Context, id, text, display string) error {
+	if f.down.Load() {
+		return errors.New("region unreachable")
+	}
+	return f.AutoComplete.Index(ctx, id, text, display)
+}
+
+func TestReplicatorRetriesAndReportsLag(t *testing.T) {
+	target := &flakyTarget{AutoComplete: newInstance(t, autocomplete.Hooks{})}
+	target.down.Store(true)
+	var failures atomic.Int64
+	replicator, err := New(Config{
+		Target:     target,
+		RetryDelay: time.Millisecond,
+		OnError:    func(mutation autocomplete.Mutation, err error) { failures.Add(1) },
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	source := newInstance(t, autocomplete.Hooks{OnMutation: replicator.OnMutation})
+	runReplicator(t, replicator)
+
+	ctx := context.Background()
+	for _, id := range []string{"1", "2"} {
+		if err := source.Index(ctx, id, "Mumbai "+id, "Mumbai "+id); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	stats := replicator.Stats()
+	if stats.Pending != 2 || stats.Lag < 20*time.Millisecond || stats.Failures == 0 {
+		t.Errorf("Stats() while the target is down = %+v, want 2 pending writes lagging with failures", stats)
+	}
+	if failures.Load() == 0 {
+		t.Error("OnError was not called")
+	}
+
+	target.down.Store(false)
+	waitCaughtUp(t, replicator)
+	if got := queryIDs(t, target, "mum"); got != "[1 2]" {
+		t.Errorf("target Query() after recovery = %v, want [1 2]", got)
+	}
+}
+
+// manualClock is a Clock advanced by hand.
+type manualClock struct {
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	return c.now
+}
+
+func TestReplicatorLagFollowsClock(t *testing.T) {
+	clock := &manualClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	replicator, err := New(Config{Target: newInstance(t, autocomplete.Hooks{}), Clock: clock})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	options := autocomplete.DefaultOptions()
+	options.Clock = clock
+	options.Hooks.OnMutation = replicator.OnMutation
+	source, err := autocomplete.New("memory", autocomplete.NewConfigWithOptions(memory.Config{}, options))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer func() { _ = source.Close() }()
+
+	// Run is not started, so the write stays pending
+	if err := source.Index(context.Background(), "1", "Mumbai", "Mumbai"); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	clock.now = clock.now.Add(5 * time.Minute)
+	if stats := replicator.Stats(); stats.Pending != 1 || stats.Lag != 5*time.Minute {
+		t.Errorf("Stats() = %+v, want 1 pending write lagging 5m", stats)
+	}
+}
+
+func TestReplicatorWriteAheadLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "replication.wal")
+	ctx := context.Background()
+
+	// The first process queues writes but exits before applying them all
+	first, err := New(Config{Target: newInstance(t, autocomplete.Hooks{}), WALPath: path})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	source := newInstance(t, autocomplete.Hooks{OnMutation: first.OnMutation})
+	for _, id := range []string{"1", "2", "3"} {
+		if err := source.Index(ctx, id, "Mumbai "+id, "Mumbai "+id); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+	if err := source.Delete(ctx, "3"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	mutation, _ := first.head()
+	if err := first.apply(ctx, mutation); err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+	if err := first.pop(); err != nil {
+		t.Fatalf("pop() error = %v", err)
+	}
+	if err := first.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	// A write cut short by the crash is discarded
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	if _, err := f.WriteString(`{"seq":9,"mutation":{"Op":`); err != nil {
+		t.Fatalf("WriteString() error = %v", err)
+	}
+	_ = f.Close()
+
+	target := newInstance(t, autocomplete.Hooks{})
+	second, err := New(Config{Target: target, WALPath: path})
+	if err != nil {
+		t.Fatalf("New() after restart error = %v", err)
+	}
+	t.Cleanup(func() { _ = second.Close() })
+	if pending := second.Stats().Pending; pending != 3 {
+		t.Errorf("Stats().Pending after restart = %d, want the 3 writes not applied", pending)
+	}
+	runReplicator(t, second)
+	waitCaughtUp(t, second)
+	if got := queryIDs(t, target, "mum"); got != "[2]" {
+		t.Errorf("target Query() after replay = %v, want [2]", got)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() != 0 {
+		t.Errorf("write-ahead log after catching up = %v, %v; want it empty", info, err)
+	}
+}
+
+func TestReplicatorWriteAheadLogStaysBounded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "replication.wal")
+	replicator, err := New(Config{Target: newInstance(t, autocomplete.Hooks{}), WALPath: path, QueueSize: 4})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = replicator.Close() })
+
+	// One write stays queued while others are applied, so the log is never
+	// emptied and must be rewritten instead
+	ctx := context.Background()
+	for i := 0; i < 20; i++ {
+		replicator.OnMutation(ctx, autocomplete.Mutation{Op: autocomplete.MutationDelete, ID: fmt.Sprint(i)})
+		if i == 0 {
+			continue
+		}
+		if err := replicator.pop(); err != nil {
+			t.Fatalf("pop() error = %v", err)
+		}
+		if lines := countLines(t, path); lines > 8 {
+			t.Fatalf("write-ahead log holds %d lines after %d writes, want at most 8", lines, i+1)
+		}
+	}
+
+	pending, err := readWAL(path)
+	if err != nil || len(pending) != 1 || pending[0].ID != "19" {
+		t.Errorf("readWAL() = %+v, %v; want the last write only", pending, err)
+	}
+}
+
+// countLines returns the number of lines in the file at path.
+func countLines(t *testing.T, path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	return strings.Count(string(data), "\n")
+}
+
+func TestReplicatorBackfill(t *testing.T) {
+	target := newInstance(t, autocomplete.Hooks{})
+	replicator, err := New(Config{Target: target, QueueSize: 1})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	source := newInstance(t, autocomplete.Hooks{OnMutation: replicator.OnMutation})
+
+	// Without Run, the queue fills up and later writes are dropped
+	ctx := context.Background()
+	for _, id := range []string{"1", "2", "3"} {
+		if err := source.Index(ctx, id, "Pune "+id, "Pune "+id); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+	if stats := replicator.Stats(); stats.Dropped != 2 {
+		t.Fatalf("Stats().Dropped = %d, want 2", stats.Dropped)
+	}
+	runReplicator(t, replicator)
+	waitCaughtUp(t, replicator)
+
+	// Entry 3 is deleted through the source while the backfill reads its old state
+	entries := func(yield func(autocomplete.Entry) bool) {
+		for _, id := range []string{"1", "2", "3"} {
+			if id == "3" {
+				if err := source.Delete(ctx, "3"); err != nil {
+					t.Errorf("Delete() error = %v", err)
+				}
+			}
+			if !yield(autocomplete.Entry{ID: id, Text: "Pune " + id, Display: "Pune " + id}) {
+				return
+			}
+		}
+	}
+	stats, err := replicator.Backfill(ctx, entries)
+	if err != nil {
+		t.Fatalf("Backfill() error = %v", err)
+	}
+	if stats.Indexed != 2 || stats.Skipped != 1 {
+		t.Errorf("Backfill() = %+v, want 2 indexed and the deleted entry skipped", stats)
+	}
+
+	waitCaughtUp(t, replicator)
+	if got := queryIDs(t, target, "pune"); got != "[1 2]" {
+		t.Errorf("target Query() after backfill = %v, want [1 2]", got)
+	}
+}
+
+func TestNewRequiresTarget(t *testing.T) {
+	if _, err := New(Config{}); err == nil {
+		t.Error("New() should require a target")
+	}
+}
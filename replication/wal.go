@@ -0,0 +1,202 @@
+package replication
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/remiges-tech/autocomplete"
+)
+
+// The write-ahead log holds one JSON line per record: a queued write with its
+// sequence number, or a watermark recording that the writes up to a sequence
+// number were applied to the target. Once every queued write is applied the
+// log is emptied; while writes stay queued, it is rewritten with only them
+// after every QueueSize watermarks, so it stays bounded under steady load.
+
+// walRecord is a line of the write-ahead log.
+type walRecord struct {
+	Seq      uint64                 `json:"seq,omitempty"`
+	Mutation *autocomplete.Mutation `json:"mutation,omitempty"`
+	Applied  uint64                 `json:"applied,omitempty"`
+}
+
+// wal is the write-ahead log of a Replicator. Its methods are called with the
+// Replicator's mu held.
+type wal struct {
+	path   string
+	noSync bool
+	file   *os.File
+	size   int64
+
+	// watermarks is the number of watermarks appended since the log was last
+	// rewritten or emptied.
+	watermarks int
+}
+
+// openWAL opens the write-ahead log at path, creating it if it does not exist,
+// and returns the writes it holds that were not applied, in order. The log is
+// rewritten to hold only them, numbered from 1. A partly written record at the
+// end, left by a crash while a write was logged, is discarded.
+func openWAL(path string, noSync bool) (*wal, []autocomplete.Mutation, error) {
+	pending, err := readWAL(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	w := &wal{path: path, noSync: noSync}
+	if err := w.rewrite(pending, 1); err != nil {
+		return nil, nil, err
+	}
+	return w, pending, nil
+}
+
+// readWAL returns the writes logged at path after the last watermark.
+func readWAL(path string) ([]autocomplete.Mutation, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open write-ahead log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var records []walRecord
+	var applied uint64
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read write-ahead log: %w", err)
+		}
+		var r walRecord
+		if err := json.Unmarshal(line, &r); err != nil {
+			return nil, fmt.Errorf("failed to read write-ahead log: %w", err)
+		}
+		if r.Mutation != nil {
+			records = append(records, r)
+		}
+		applied = max(applied, r.Applied)
+	}
+
+	var pending []autocomplete.Mutation
+	for _, r := range records {
+		if r.Seq > applied {
+			pending = append(pending, *r.Mutation)
+		}
+	}
+	return pending, nil
+}
+
+// append logs a record and, unless noSync is set, flushes it to disk. A failed
+// append is cut off, so that later records stay readable.
+func (w *wal) append(r walRecord) error {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to encode write-ahead log record: %w", err)
+	}
+	line = append(line, '\n')
+
+	if _, err := w.file.Write(line); err != nil {
+		_ = w.file.Truncate(w.size)
+		return fmt.Errorf("failed to write write-ahead log: %w", err)
+	}
+	if !w.noSync {
+		if err := w.file.Sync(); err != nil {
+			_ = w.file.Truncate(w.size)
+			return fmt.Errorf("failed to sync write-ahead log: %w", err)
+		}
+	}
+	w.size += int64(len(line))
+	return nil
+}
+
+// applied records that the writes up to seq were applied, emptying the log
+// when none is left queued and rewriting it with the queued writes, the first
+// numbered seq+1, after every limit watermarks.
+func (w *wal) applied(seq uint64, queue []autocomplete.Mutation, limit int) error {
+	if len(queue) == 0 {
+		if err := w.file.Truncate(0); err != nil {
+			return fmt.Errorf("failed to truncate write-ahead log: %w", err)
+		}
+		w.size = 0
+		w.watermarks = 0
+		return nil
+	}
+	if w.watermarks+1 >= limit {
+		return w.rewrite(queue, seq+1)
+	}
+	if err := w.append(walRecord{Applied: seq}); err != nil {
+		return err
+	}
+	w.watermarks++
+	return nil
+}
+
+// rewrite replaces the log with one holding queue, numbered from first, and
+// reopens it for appending.
+func (w *wal) rewrite(queue []autocomplete.Mutation, first uint64) error {
+	tmp, err := os.CreateTemp(filepath.Dir(w.path), filepath.Base(w.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create write-ahead log: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	buffered := bufio.NewWriter(tmp)
+	encoder := json.NewEncoder(buffered)
+	for i := range queue {
+		if err := encoder.Encode(walRecord{Seq: first + uint64(i), Mutation: &queue[i]}); err != nil {
+			_ = tmp.Close()
+			return fmt.Errorf("failed to write write-ahead log: %w", err)
+		}
+	}
+	if err := buffered.Flush(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write write-ahead log: %w", err)
+	}
+	if !w.noSync {
+		if err := tmp.Sync(); err != nil {
+			_ = tmp.Close()
+			return fmt.Errorf("failed to sync write-ahead log: %w", err)
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write write-ahead log: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), w.path); err != nil {
+		return fmt.Errorf("failed to replace write-ahead log: %w", err)
+	}
+
+	file, err := os.OpenFile(w.path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open write-ahead log: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to open write-ahead log: %w", err)
+	}
+	if w.file != nil {
+		_ = w.file.Close()
+	}
+	w.file = file
+	w.size = info.Size()
+	w.watermarks = 0
+	return nil
+}
+
+// close closes the log file.
+func (w *wal) close() error {
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("failed to close write-ahead log: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package autocomplete
+
+import (
+	"context"
+	"slices"
+)
+
+// RequestOptions override options for the queries run with a context carrying
+// them, so that middleware can set them per request without changing handler
+// signatures. They apply to Query, QueryStrategy, and QueryEx. Zero fields
+// keep the instance's values.
+type RequestOptions struct {
+	// Limit is used by queries whose limit is 0 or negative, in place of
+	// Options.DefaultLimit. It is subject to Options.MaxLimit and
+	// Options.LimitPolicy like any other limit.
+	Limit int
+
+	// Namespace is queried in place of Options.Namespace, e.g. the namespace
+	// of the tenant making the request. The copies kept for
+	// Options.CaseInsensitiveFallback and Options.Segmenter are queried under
+	// it as well. Resolved display text is cached by entry ID alone, so IDs
+	// should be unique across namespaces when Options.DisplayCacheSize is set.
+	Namespace string
+
+	// Boosts multiplies the scores of the results with the given entry IDs,
+	// e.g. to favour the entries a tenant uses most, and re-ranks the results
+	// by their boosted scores. Only entries within the query's limit are
+	// re-ranked; boosts do not bring in further matches.
+	Boosts map[string]float64
+
+	// Filter restricts results to entries whose metadata (see
+	// AutoComplete.IndexWithMetadata) has the given top-level field values,
+	// e.g. {"state": "Maharashtra"}. Values are compared as JSON. Queries with
+	// a filter return ErrMetadataUnsupported if the provider cannot store
+	// metadata.
+	Filter map[string]interface{}
+
+	// Explain sets Result.Explanation on every result, breaking its score down
+	// into the components it was ranked by, e.g. so that product teams can see
+	// why results are ordered as they are.
+	Explain bool
+
+	// minScore and caseSensitive, when set, are the QueryOptions.MinScore and
+	// QueryOptions.CaseSensitive of a QueryWithOptions call.
+	minScore      *float64
+	caseSensitive *bool
+}
+
+// requestOptionsKey is the context key under which RequestOptions are stored.
+type requestOptionsKey struct{}
+
+// WithRequestOptions returns a copy of ctx carrying opts, which replace any
+// RequestOptions ctx already carries.
+func WithRequestOptions(ctx context.Context, opts RequestOptions) context.Context {
+	return context.WithValue(ctx, requestOptionsKey{}, opts)
+}
+
+// RequestOptionsFromContext returns the RequestOptions carried by ctx, or zero
+// options, e.g. so that HTTP handlers can tell which namespace a request queries.
+func RequestOptionsFromContext(ctx context.Context) RequestOptions {
+	return requestOptions(ctx)
+}
+
+// requestOptions returns the RequestOptions carried by ctx, or zero options.
+func requestOptions(ctx context.Context) RequestOptions {
+	opts, _ := ctx.Value(requestOptionsKey{}).(RequestOptions)
+	return opts
+}
+
+// queryNamespace returns the namespace a query made with ctx runs against.
+func (a *autocompleteImpl) queryNamespace(ctx context.Context) string {
+	if namespace := requestOptions(ctx).Namespace; namespace != "" {
+		return namespace
+	}
+	return a.config.Options.Namespace
+}
+
+// minScore returns the minimum score of results of queries made with ctx.
+func (a *autocompleteImpl) minScore(ctx context.Context) float64 {
+	if minScore := requestOptions(ctx).minScore; minScore != nil {
+		return *minScore
+	}
+	return a.tuning().minScore
+}
+
+// boostResults applies the RequestOptions.Boosts carried by ctx to results,
+// keeping the order of equally scored results.
+func boostResults(ctx context.Context, results []Result) []Result {
+	boosts := requestOptions(ctx).Boosts
+	if len(boosts) == 0 || len(results) == 0 {
+		return results
+	}
+	for i := range results {
+		if boost, ok := boosts[results[i].ID]; ok {
+			results[i].Score *= boost
+			if results[i].Explanation != nil {
+				results[i].Explanation.Boost = boost
+			}
+		}
+	}
+	return sortByScore(results)
+}
+
+// sortByScore sorts results by score, highest first, keeping the order of
+// equally scored results.
+func sortByScore(results []Result) []Result {
+	slices.SortStableFunc(results, func(x, y Result) int {
+		switch {
+		case x.Score > y.Score:
+			return -1
+		case x.Score < y.Score:
+			return 1
+		default:
+			return 0
+		}
+	})
+	return results
+}
@@ -0,0 +1,77 @@
+package autocomplete
+
+import (
+	"context"
+	"errors"
+)
+
+// Response holds the results of AutoComplete.QueryEx and what is known about
+// the matches beyond them. New fields are added here rather than as new
+// query methods.
+type Response struct {
+	// Results holds the results, as Query returns them.
+	Results []Result `json:"results"`
+
+	// Total is the number of matching entries when it is known without
+	// counting them separately, which is when every match was fetched, and
+	// -1 otherwise.
+	Total int `json:"total"`
+
+	// NextCursor continues the query after the last result when passed to
+	// AutoComplete.QueryPage. It is empty when every match was returned, when
+	// the results reach Options.MaxLimit, and for stale results.
+	NextCursor string `json:"next_cursor,omitempty"`
+
+	// Truncated is set when more entries match than the limit allowed.
+	Truncated bool `json:"truncated,omitempty"`
+
+	// More is the number of matching entries left out of Results, counted up
+	// to Options.MoreCountLimit. It is at most 1 when that option is zero.
+	More int `json:"more,omitempty"`
+
+	// MoreAtLeast is set when counting stopped at Options.MoreCountLimit, so
+	// that More is a lower bound, as in "and 100+ more".
+	MoreAtLeast bool `json:"more_at_least,omitempty"`
+
+	// TimedOut is set when the query's context deadline passed before the
+	// provider answered. Results is then empty.
+	TimedOut bool `json:"timed_out,omitempty"`
+}
+
+// QueryEx searches like Query and reports the results in a Response.
+// See AutoComplete.QueryEx for details.
+func (a *autocompleteImpl) QueryEx(ctx context.Context, query string, limit int) (Response, error) {
+	query = a.normalize(query)
+	options, err := a.queryOptions(ctx, query, limit)
+	if err != nil {
+		return Response{}, err
+	}
+
+	// Results beyond the limit tell whether the results are complete and how
+	// many more entries match
+	limit = options.MaxResults
+	probe := max(a.config.Options.MoreCountLimit, 1)
+	options.MaxResults += probe
+	results, err := a.queryOrStale(ctx, query, options)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return Response{Results: []Result{}, Total: -1, TimedOut: true}, nil
+	}
+	if err != nil {
+		return Response{}, err
+	}
+
+	results = boostResults(ctx, results)
+	if len(results) <= limit {
+		return Response{Results: results, Total: len(results)}, nil
+	}
+	more := len(results) - limit
+	response := Response{Results: results[:limit], Total: -1, Truncated: true, More: more, MoreAtLeast: more == probe}
+	if !response.MoreAtLeast {
+		response.Total = len(results)
+	}
+	if !results[0].Stale {
+		position := pageCursor{Query: pageFingerprint(a.queryNamespace(ctx), query, options), Offset: limit}
+		response.NextCursor = encodePageCursor(position, limit < a.tuning().maxLimit)
+	}
+	return response, nil
+}
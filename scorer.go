@@ -0,0 +1,102 @@
+package autocomplete
+
+import (
+	"context"
+	"math"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// defaultEntryScore is the score of entries when Options.Scorer is not set.
+const defaultEntryScore = 1.0
+
+// Scorer returns the score of an entry from its normalized text. Providers rank
+// entries with higher scores first among equally good matches.
+type Scorer func(text string) float64
+
+// entryScore returns the score an entry is indexed with: its own, if set,
+// otherwise the one Options.Scorer gives its normalized text.
+func (a *autocompleteImpl) entryScore(entry Entry) float64 {
+	if entry.Score != 0 {
+		return entry.Score
+	}
+	if a.config.Options.Scorer == nil {
+		return defaultEntryScore
+	}
+	return a.config.Options.Scorer(entry.Text)
+}
+
+// UpdateScore sets the score of a stored entry and of its copies.
+// See AutoComplete.UpdateScore for details.
+func (a *autocompleteImpl) UpdateScore(ctx context.Context, id string, score float64) error {
+	if err := validateRescore(id, score); err != nil {
+		return err
+	}
+	updater, ok := a.provider.(providers.ScoreUpdater)
+	if !ok {
+		return ErrScoreUpdateUnsupported
+	}
+
+	err := a.rescore(id, func(namespace, id string) error {
+		return updater.UpdateScore(ctx, namespace, id, score)
+	})
+	if err != nil {
+		return err
+	}
+	a.reportMutation(ctx, Mutation{Op: MutationUpdateScore, ID: id, Score: score})
+	return nil
+}
+
+// IncrementScore adds to the score of a stored entry and of its copies.
+// See AutoComplete.IncrementScore for details.
+func (a *autocompleteImpl) IncrementScore(ctx context.Context, id string, delta float64) error {
+	if err := validateRescore(id, delta); err != nil {
+		return err
+	}
+	incrementer, ok := a.provider.(providers.ScoreIncrementer)
+	if !ok {
+		return ErrScoreIncrementUnsupported
+	}
+
+	err := a.rescore(id, func(namespace, id string) error {
+		return incrementer.IncrementScore(ctx, namespace, id, delta)
+	})
+	if err != nil {
+		return err
+	}
+	a.reportMutation(ctx, Mutation{Op: MutationIncrementScore, ID: id, Score: delta})
+	return nil
+}
+
+// validateRescore checks the arguments of UpdateScore and IncrementScore.
+func validateRescore(id string, score float64) error {
+	if id == "" {
+		return ErrEmptyID
+	}
+	if math.IsNaN(score) || math.IsInf(score, 0) {
+		return ErrInvalidScore
+	}
+	return nil
+}
+
+// rescore applies a score change to an entry and to the copies kept of it for
+// Options.CaseInsensitiveFallback and Options.Segmenter, given the namespace
+// and ID of each.
+func (a *autocompleteImpl) rescore(id string, apply func(namespace, id string) error) error {
+	if err := apply(a.config.Options.Namespace, id); err != nil {
+		return err
+	}
+	if a.caseFallback() {
+		if err := apply(a.foldedNamespace(), id); err != nil {
+			return err
+		}
+	}
+	if a.config.Options.Segmenter != nil {
+		for n := 1; n <= maxSegments; n++ {
+			if err := apply(a.segmentNamespace(), segmentID(id, n)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package autocomplete
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+const (
+	// segmentNamespaceSuffix names the namespace holding the segment copies of
+	// the entries when Options.Segmenter is set.
+	segmentNamespaceSuffix = ":segments"
+
+	// segmentIDSeparator separates the entry ID from the segment number in the
+	// IDs of segment copies.
+	segmentIDSeparator = "\x00"
+
+	// maxSegments is the number of segments after the first that are indexed
+	// per entry. Text after the last of them is still matched as part of it.
+	maxSegments = 8
+)
+
+// DuplicatePolicy decides how the matches of an entry under several of its
+// segments combine into its result (see Options.Segmenter).
+type DuplicatePolicy int
+
+const (
+	// DuplicateFirst lists entries matched from the start of their text first,
+	// as the provider ranked them, and fills the remaining results with
+	// entries matched only from a later segment. Each entry keeps the score of
+	// its first match.
+	DuplicateFirst DuplicatePolicy = iota
+	// DuplicateMax scores each entry by its best-scoring match and ranks all
+	// matched entries by score.
+	DuplicateMax
+	// DuplicateSum scores each entry by the sum of the scores of its matches
+	// and ranks all matched entries by score, so entries matching in several
+	// segments rank above those matching in one.
+	DuplicateSum
+)
+
+// Segmenter returns the byte offsets in text at which segments after the first
+// begin, e.g. just past the "@" of an email address, in increasing order. Each
+// segment is indexed on its own, from its offset to the end of the text, so
+// prefix queries also match from the start of every segment.
+type Segmenter func(text string) []int
+
+// segmentNamespace returns the namespace holding the segment copies of the entries.
+func (a *autocompleteImpl) segmentNamespace() string {
+	return a.config.Options.Namespace + segmentNamespaceSuffix
+}
+
+// segmentID returns the ID of the copy of an entry for its n-th segment.
+func segmentID(id string, n int) string {
+	return id + segmentIDSeparator + strconv.Itoa(n)
+}
+
+// indexSegments writes a copy of an entry for each of its segments after the
+// first and removes the copies of segments the entry no longer has.
+//
+//nolint:gocritic // hugeParam: options is passed by value to match the Provider interface
+func (a *autocompleteImpl) indexSegments(ctx context.Context, entry Entry, options providers.IndexOptions) error {
+	if a.config.Options.Segmenter == nil {
+		return nil
+	}
+	offsets := a.config.Options.Segmenter(entry.Text)
+	n := 0
+	for _, offset := range offsets {
+		if offset <= 0 || offset >= len(entry.Text) || n == maxSegments {
+			continue
+		}
+		n++
+		err := a.provider.Index(ctx, a.segmentNamespace(), segmentID(entry.ID, n), entry.Text[offset:], entry.Display, options)
+		if err != nil {
+			return err
+		}
+	}
+	for n++; n <= maxSegments; n++ {
+		if err := a.provider.Delete(ctx, a.segmentNamespace(), segmentID(entry.ID, n)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteSegments removes the segment copies of an entry.
+func (a *autocompleteImpl) deleteSegments(ctx context.Context, id string) error {
+	if a.config.Options.Segmenter == nil {
+		return nil
+	}
+	for n := 1; n <= maxSegments; n++ {
+		if err := a.provider.Delete(ctx, a.segmentNamespace(), segmentID(id, n)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendSegmentMatches fills the results of a query against namespace up to its
+// limit with entries matched from the start of a later segment, after those
+// matched from the start of their text, or merges them with results under
+// Options.DuplicatePolicy. Each entry appears once.
+//
+//nolint:gocritic // hugeParam: options is passed by value to match the Provider interface
+func (a *autocompleteImpl) appendSegmentMatches(
+	ctx context.Context, namespace, query string, options providers.QueryOptions, results []Result,
+) ([]Result, error) {
+	policy := a.config.Options.DuplicatePolicy
+	if a.config.Options.Segmenter == nil || (policy == DuplicateFirst && len(results) >= options.MaxResults) {
+		return results, nil
+	}
+
+	limit := options.MaxResults
+	// An entry can match under several of its segments.
+	options.MaxResults = limit * maxSegments
+	providerResults, err := a.provider.Query(ctx, namespace+segmentNamespaceSuffix, query, options)
+	if err != nil {
+		return nil, err
+	}
+	if policy != DuplicateFirst {
+		return a.combineSegmentMatches(ctx, limit, results, providerResults), nil
+	}
+
+	seen := make(map[string]bool, len(results))
+	for _, result := range results {
+		seen[result.ID] = true
+	}
+	var matches []providers.ProviderResult
+	for _, pr := range providerResults {
+		pr.ID, _, _ = strings.Cut(pr.ID, segmentIDSeparator)
+		if seen[pr.ID] || len(results)+len(matches) >= limit {
+			continue
+		}
+		seen[pr.ID] = true
+		matches = append(matches, pr)
+	}
+	return append(results, a.orderTies(a.convertResults(ctx, matches, false))...), nil
+}
+
+// combineSegmentMatches merges the matches of segment copies into results,
+// combining the scores of entries matched more than once under
+// Options.DuplicatePolicy, and returns the best up to limit by score. Entries
+// are only combined among the matches fetched, up to limit from the text and
+// limit times maxSegments from the segments.
+func (a *autocompleteImpl) combineSegmentMatches(
+	ctx context.Context, limit int, results []Result, providerResults []providers.ProviderResult,
+) []Result {
+	for i := range providerResults {
+		providerResults[i].ID, _, _ = strings.Cut(providerResults[i].ID, segmentIDSeparator)
+	}
+	index := make(map[string]int, len(results))
+	for i, result := range results {
+		index[result.ID] = i
+	}
+	for _, match := range a.convertResults(ctx, providerResults, false) {
+		i, seen := index[match.ID]
+		switch {
+		case !seen:
+			index[match.ID] = len(results)
+			results = append(results, match)
+		case a.config.Options.DuplicatePolicy == DuplicateSum:
+			results[i].Score += match.Score
+			if e, m := results[i].Explanation, match.Explanation; e != nil && m != nil {
+				e.Provider += m.Provider
+				e.Entry += m.Entry
+				e.Proximity += m.Proximity
+			}
+		case match.Score > results[i].Score:
+			results[i].Score, results[i].Explanation = match.Score, match.Explanation
+		}
+	}
+	results = a.orderTies(sortByScore(results))
+	return results[:min(limit, len(results))]
+}
@@ -0,0 +1,151 @@
+package autocomplete
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+const (
+	// selfTestID is the ID of the sentinel entry indexed by the self-test.
+	selfTestID = "selftest-sentinel"
+
+	// selfTestText is the text of the sentinel entry. It mixes cases so that
+	// case sensitivity can be checked with a differently cased query.
+	selfTestText = "SelfTestSentinel"
+
+	// selfTestTimeout bounds the whole self-test, including waiting for
+	// providers with delayed visibility (e.g. Elasticsearch refresh) to show the entry.
+	selfTestTimeout = 10 * time.Second
+
+	// selfTestCleanupTimeout bounds the deletion of the throwaway namespace,
+	// which runs after selfTestTimeout may have expired.
+	selfTestCleanupTimeout = 5 * time.Second
+
+	// selfTestPollInterval is the delay between queries while waiting for the sentinel.
+	selfTestPollInterval = 100 * time.Millisecond
+)
+
+// NewOption configures optional behavior of New.
+type NewOption func(*newOptions)
+
+// newOptions holds the settings applied by NewOption functions.
+type newOptions struct {
+	selfTest  bool
+	warmStart io.Reader
+}
+
+// WithSelfTest makes New index and query a sentinel entry in a throwaway namespace
+// before returning, failing fast with ErrSelfTestFailed if the round trip does not
+// behave as configured (match strategy, case sensitivity). This catches backend
+// misconfigurations at deploy time instead of on the first user query.
+// The throwaway namespace is deleted afterwards.
+func WithSelfTest() NewOption {
+	return func(o *newOptions) {
+		o.selfTest = true
+	}
+}
+
+// selfTest runs the startup self-test described in WithSelfTest.
+func (a *autocompleteImpl) selfTest(parent context.Context) (err error) {
+	ctx, cancel := context.WithTimeout(parent, selfTestTimeout)
+	defer cancel()
+
+	// Cleanup gets its own deadline, as a sentinel that never shows up leaves
+	// ctx expired
+	namespace := a.config.Options.Namespace + ":selftest"
+	defer func() {
+		cleanupCtx, cancelCleanup := context.WithTimeout(context.WithoutCancel(parent), selfTestCleanupTimeout)
+		defer cancelCleanup()
+		if cleanupErr := a.provider.DeleteAll(cleanupCtx, namespace); cleanupErr != nil && err == nil {
+			err = fmt.Errorf("%w: failed to clean up namespace %q: %v", ErrSelfTestFailed, namespace, cleanupErr)
+		}
+	}()
+
+	strategy := a.config.Options.MatchStrategy
+	if err := a.provider.Index(ctx, namespace, selfTestID, selfTestText, selfTestText, a.indexOptions(Entry{Text: selfTestText, Display: selfTestText})); err != nil {
+		return fmt.Errorf("%w: failed to index sentinel entry: %v", ErrSelfTestFailed, err)
+	}
+
+	query := selfTestQuery(a.config.Options)
+	options, err := a.queryOptions(ctx, query, 0)
+	if err != nil {
+		return fmt.Errorf("%w: invalid sentinel query %q: %v", ErrSelfTestFailed, query, err)
+	}
+
+	found, err := a.waitForSentinel(ctx, namespace, query, options)
+	if err != nil {
+		return fmt.Errorf("%w: query %q failed: %v", ErrSelfTestFailed, query, err)
+	}
+	if !found {
+		return fmt.Errorf("%w: query %q (strategy %s) did not return sentinel entry %q within %s",
+			ErrSelfTestFailed, query, strategy, selfTestText, selfTestTimeout)
+	}
+
+	// The sentinel is visible now, so a differently cased query answers immediately
+	recased := strings.ToUpper(query)
+	found, err = a.sentinelMatches(ctx, namespace, recased, options)
+	if err != nil {
+		return fmt.Errorf("%w: query %q failed: %v", ErrSelfTestFailed, recased, err)
+	}
+	if found == a.config.Options.CaseSensitive {
+		return fmt.Errorf("%w: query %q returned sentinel entry %q = %t, want %t with CaseSensitive = %t",
+			ErrSelfTestFailed, recased, selfTestText, found, !found, a.config.Options.CaseSensitive)
+	}
+	return nil
+}
+
+// waitForSentinel queries until the sentinel entry is returned or ctx expires.
+//
+//nolint:gocritic // hugeParam: options is passed by value to match the Provider interface
+func (a *autocompleteImpl) waitForSentinel(
+	ctx context.Context, namespace, query string, options providers.QueryOptions,
+) (bool, error) {
+	ticker := time.NewTicker(selfTestPollInterval)
+	defer ticker.Stop()
+	for {
+		found, err := a.sentinelMatches(ctx, namespace, query, options)
+		if err != nil || found {
+			return found, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// sentinelMatches reports whether query returns the sentinel entry.
+//
+//nolint:gocritic // hugeParam: options is passed by value to match the Provider interface
+func (a *autocompleteImpl) sentinelMatches(
+	ctx context.Context, namespace, query string, options providers.QueryOptions,
+) (bool, error) {
+	results, err := a.provider.Query(ctx, namespace, query, options)
+	if err != nil {
+		return false, err
+	}
+	for _, result := range results {
+		if result.ID == selfTestID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// selfTestQuery returns a prefix of the sentinel text long enough for the
+// n-gram size and MinPrefixLength, which matches under every strategy.
+func selfTestQuery(options Options) string {
+	n := options.NGramSize
+	if n <= 0 {
+		n = defaultNGramSize
+	}
+	length := max(n, options.MinPrefixLength, 4)
+	return selfTestText[:min(length, len(selfTestText))]
+}
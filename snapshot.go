@@ -0,0 +1,68 @@
+package autocomplete
+
+import (
+	"context"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// Snapshot is a consistent, read-only view of a namespace for paging through
+// query results. Entries indexed or deleted after the snapshot was opened are
+// not visible through it.
+type Snapshot interface {
+	// Query searches the snapshot. Offset skips that many results, so successive
+	// pages are fetched with offsets 0, limit, 2*limit, and so on.
+	// Validation follows AutoComplete.Query; a negative offset returns ErrNegativeOffset.
+	Query(ctx context.Context, query string, limit, offset int) ([]Result, error)
+
+	// Close releases the snapshot on the backend.
+	Close(ctx context.Context) error
+}
+
+// snapshotImpl is the default implementation of Snapshot.
+type snapshotImpl struct {
+	ac          *autocompleteImpl
+	snapshotter providers.Snapshotter
+	id          string
+}
+
+// OpenSnapshot opens a consistent, read-only view of the namespace.
+// See AutoComplete.OpenSnapshot for details.
+func (a *autocompleteImpl) OpenSnapshot(ctx context.Context) (Snapshot, error) {
+	snapshotter, ok := a.provider.(providers.Snapshotter)
+	if !ok {
+		return nil, ErrSnapshotsUnsupported
+	}
+
+	id, err := snapshotter.OpenSnapshot(ctx, a.config.Options.Namespace, a.config.Options.SnapshotKeepAlive)
+	if err != nil {
+		return nil, err
+	}
+
+	return &snapshotImpl{ac: a, snapshotter: snapshotter, id: id}, nil
+}
+
+// Query searches the snapshot.
+// See Snapshot.Query for details.
+func (s *snapshotImpl) Query(ctx context.Context, query string, limit, offset int) ([]Result, error) {
+	if offset < 0 {
+		return nil, ErrNegativeOffset
+	}
+
+	query = s.ac.normalize(query)
+	options, err := s.ac.queryOptions(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	options.SnapshotID = s.id
+	options.SnapshotKeepAlive = s.ac.config.Options.SnapshotKeepAlive
+	options.Offset = offset
+
+	return s.ac.runQuery(ctx, query, options)
+}
+
+// Close releases the snapshot on the backend.
+// See Snapshot.Close for details.
+func (s *snapshotImpl) Close(ctx context.Context) error {
+	return s.snapshotter.CloseSnapshot(ctx, s.id)
+}
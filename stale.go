@@ -0,0 +1,78 @@
+package autocomplete
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// keptResults are the results of a query kept for Options.StaleCacheSize.
+type keptResults struct {
+	results []Result
+	fetched time.Time
+}
+
+// queryOrStale runs a query and keeps its results, or, when the provider fails,
+// answers it with the results kept from the last time it succeeded, flagged
+// with Stale. Without kept results, or once ctx is done, the error is returned.
+//
+//nolint:gocritic // hugeParam: options is passed by value to match the Provider interface
+func (a *autocompleteImpl) queryOrStale(ctx context.Context, query string, options providers.QueryOptions) ([]Result, error) {
+	results, err := a.queryWithFallback(ctx, query, options)
+	if a.staleResults == nil {
+		return results, err
+	}
+
+	namespace := a.queryNamespace(ctx)
+	key := staleKey(namespace, query, options)
+	if err == nil {
+		a.staleResults.put(key, keptResults{results: slices.Clone(results), fetched: a.clock.Now()})
+		return results, nil
+	}
+	if ctx.Err() != nil {
+		return nil, err
+	}
+	kept, ok := a.staleResults.get(key)
+	if !ok {
+		return nil, err
+	}
+
+	if a.config.Options.Hooks.OnStale != nil {
+		a.config.Options.Hooks.OnStale(ctx, StaleEvent{
+			Namespace: namespace,
+			Query:     query,
+			Err:       err,
+			Age:       a.clock.Now().Sub(kept.fetched),
+		})
+	}
+	stale := make([]Result, len(kept.results))
+	for i, result := range kept.results {
+		result.Stale = true
+		stale[i] = result
+	}
+	return stale, nil
+}
+
+// staleKey identifies a query among the kept results by everything that
+// decides its results.
+//
+//nolint:gocritic // hugeParam: options is passed by value to match the Provider interface
+func staleKey(namespace, query string, options providers.QueryOptions) string {
+	key := fmt.Sprintf("%s:%d:%d:%s", namespace, options.MatchStrategy, options.MaxResults, query)
+	if len(options.FilterMetadata) > 0 {
+		// Marshalled maps have sorted keys, so equal filters give equal keys
+		filter, _ := json.Marshal(options.FilterMetadata)
+		key += "\x00" + string(filter)
+	}
+	if options.ExplainScores {
+		key += "\x00explain"
+	}
+	// Case handling and minimum scores only vary between queries made with
+	// QueryWithOptions
+	key += fmt.Sprintf("\x00%t:%g", options.CaseSensitive, options.MinScore)
+	return key
+}
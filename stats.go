@@ -0,0 +1,49 @@
+package autocomplete
+
+import (
+	"context"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// Stats describes the size of a namespace's index.
+type Stats struct {
+	// Namespace is the namespace described.
+	Namespace string
+
+	// Entries is the number of entries indexed in the namespace.
+	Entries int64
+
+	// Tokens is the number of token postings in the namespace's index, as
+	// described for providers.IndexStats.
+	Tokens int64
+
+	// Bytes approximates the storage used by the namespace. It is measured
+	// differently by each provider, so it is meant for watching growth rather
+	// than comparing providers.
+	Bytes int64
+
+	// Details holds provider-specific figures, e.g. the Redis layout.
+	Details map[string]string
+}
+
+// Stats returns the size of the namespace's index.
+// See AutoComplete.Stats for details.
+func (a *autocompleteImpl) Stats(ctx context.Context) (Stats, error) {
+	reporter, ok := a.provider.(providers.StatsReporter)
+	if !ok {
+		return Stats{}, ErrStatsUnsupported
+	}
+	namespace := a.config.Options.Namespace
+	stats, err := reporter.IndexStats(ctx, namespace)
+	if err != nil {
+		return Stats{}, err
+	}
+	return Stats{
+		Namespace: namespace,
+		Entries:   stats.Entries,
+		Tokens:    stats.Tokens,
+		Bytes:     stats.Bytes,
+		Details:   stats.Details,
+	}, nil
+}
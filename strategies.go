@@ -0,0 +1,81 @@
+package autocomplete
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// QueryStrategy searches for entries matching the given query with a specific strategy.
+// See AutoComplete.QueryStrategy for details.
+func (a *autocompleteImpl) QueryStrategy(ctx context.Context, strategy MatchStrategy, query string, limit int) ([]Result, error) {
+	if !a.indexedUnder(strategy) {
+		return nil, fmt.Errorf("%w: %s", ErrStrategyNotIndexed, strategy)
+	}
+
+	query = a.normalize(query)
+	options, err := a.queryOptions(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	options.MatchStrategy = providers.MatchStrategy(strategy)
+
+	results, err := a.queryOrStale(ctx, query, options)
+	return boostResults(ctx, results), err
+}
+
+// indexedUnder reports whether entries of the namespace are indexed under strategy.
+func (a *autocompleteImpl) indexedUnder(strategy MatchStrategy) bool {
+	if len(a.config.Options.MatchStrategies) == 0 {
+		return strategy == a.config.Options.MatchStrategy
+	}
+	return slices.Contains(a.config.Options.MatchStrategies, strategy)
+}
+
+// validateStrategies checks that the provider supports MatchStrategy, that it
+// can index entries under every strategy in Options.MatchStrategies, and that
+// MatchStrategy is among them.
+func validateStrategies(provider providers.Provider, options Options) error {
+	for _, strategy := range append([]MatchStrategy{options.MatchStrategy}, options.MatchStrategies...) {
+		if !supportsStrategy(provider, strategy) {
+			return fmt.Errorf("%w: %s", ErrStrategyUnsupported, strategy)
+		}
+	}
+	if len(options.MatchStrategies) == 0 {
+		return nil
+	}
+	if !slices.Contains(options.MatchStrategies, options.MatchStrategy) {
+		return fmt.Errorf("%w: MatchStrategy %s is not listed in MatchStrategies", ErrStrategyNotIndexed, options.MatchStrategy)
+	}
+
+	indexer, ok := provider.(providers.MultiStrategyIndexer)
+	if !ok || !indexer.SupportsMatchStrategies(providerStrategies(options.MatchStrategies)) {
+		return ErrMultiStrategyUnsupported
+	}
+	return nil
+}
+
+// supportsStrategy reports whether the provider serves a match strategy. Every
+// provider serves the strategies up to MatchSubstring; later ones need
+// providers.StrategySupporter.
+func supportsStrategy(provider providers.Provider, strategy MatchStrategy) bool {
+	if strategy <= MatchSubstring {
+		return true
+	}
+	supporter, ok := provider.(providers.StrategySupporter)
+	return ok && supporter.SupportsMatchStrategy(providers.MatchStrategy(strategy))
+}
+
+// providerStrategies converts match strategies to their provider equivalents.
+func providerStrategies(strategies []MatchStrategy) []providers.MatchStrategy {
+	if len(strategies) == 0 {
+		return nil
+	}
+	converted := make([]providers.MatchStrategy, len(strategies))
+	for i, strategy := range strategies {
+		converted[i] = providers.MatchStrategy(strategy)
+	}
+	return converted
+}
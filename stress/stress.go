@@ -0,0 +1,362 @@
+// Package stress checks that an AutoComplete stays consistent under
+// concurrent Index, Query, and Delete calls.
+//
+// Run starts several workers, each owning a disjoint set of entry IDs, that
+// randomly index, re-index, delete, and query entries. Every query result is
+// checked as it is returned, and once the workers finish, every query is
+// checked against the entries that must remain. Running it under the race
+// detector (go test -race) also catches unsynchronized access in providers,
+// caches, and anything else behind the AutoComplete interface:
+//
+//	ac, _ := autocomplete.New("memory", autocomplete.NewConfig(memory.Config{}))
+//	if err := stress.Run(ctx, ac, stress.Config{}); err != nil {
+//		t.Fatal(err)
+//	}
+package stress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/remiges-tech/autocomplete"
+)
+
+const (
+	defaultWorkers      = 8
+	defaultOperations   = 200
+	defaultIDsPerWorker = 8
+	defaultQueryLimit   = 100
+
+	// maxViolations bounds how many violations Run reports.
+	maxViolations = 10
+
+	// maxMaintainPasses bounds how often Run calls Maintain once the workers
+	// finish when Config.StaleMatches is set.
+	maxMaintainPasses = 20
+
+	// displaySeparator separates the ID from the text in the displays Run indexes.
+	displaySeparator = "|"
+)
+
+// words are the terms entries are indexed under. No two share a trigram, so
+// under every match strategy a query for one word matches exactly the entries
+// currently indexed with it.
+var words = []string{"mumbai", "pune", "chennai", "kolkata"}
+
+// Config controls a stress run.
+type Config struct {
+	// Workers is the number of concurrent workers.
+	// Default: 8
+	Workers int
+
+	// Operations is the number of calls each worker makes.
+	// Default: 200
+	Operations int
+
+	// IDsPerWorker is the number of entries each worker owns.
+	// Workers times IDsPerWorker must not exceed QueryLimit.
+	// Default: 8
+	IDsPerWorker int
+
+	// QueryLimit is the limit of every query. It must not exceed the
+	// AutoComplete's MaxLimit.
+	// Default: 100
+	QueryLimit int
+
+	// Seed makes the sequence of operations reproducible.
+	// Default: 0
+	Seed uint64
+
+	// StaleDisplays relaxes the check that a result's display matches the
+	// query, for AutoComplete instances whose displays may lag behind the
+	// index, such as those with a DisplayResolver cache. Results are still
+	// checked to carry a display of their own ID.
+	// Default: false
+	StaleDisplays bool
+
+	// StaleMatches relaxes the checks for AutoComplete instances whose updates
+	// leave an entry matching its previous text until Maintain removes the
+	// stale tokens, as with the Redis provider. Results may then include
+	// entries that no longer match, but must not miss any that do. Maintain
+	// runs concurrently with the workers and again, until it removes nothing,
+	// before the final checks, which stay exact.
+	// Default: false
+	StaleMatches bool
+}
+
+// setDefaults applies default values to config fields.
+func (c *Config) setDefaults() {
+	if c.Workers <= 0 {
+		c.Workers = defaultWorkers
+	}
+	if c.Operations <= 0 {
+		c.Operations = defaultOperations
+	}
+	if c.IDsPerWorker <= 0 {
+		c.IDsPerWorker = defaultIDsPerWorker
+	}
+	if c.QueryLimit <= 0 {
+		c.QueryLimit = defaultQueryLimit
+	}
+}
+
+// Display returns the display Run indexes for an entry. An AutoComplete with
+// a DisplayResolver under test should resolve IDs to the display most
+// recently indexed for them.
+func Display(id, text string) string {
+	return id + displaySeparator + text
+}
+
+// Run exercises ac with concurrent calls as described in the package
+// documentation and returns an error describing any inconsistency found.
+// It deletes every entry of ac's namespace before and after the run.
+func Run(ctx context.Context, ac autocomplete.AutoComplete, config Config) error {
+	config.setDefaults()
+	if config.Workers*config.IDsPerWorker > config.QueryLimit {
+		return fmt.Errorf("stress: %d workers with %d IDs each exceed the query limit of %d",
+			config.Workers, config.IDsPerWorker, config.QueryLimit)
+	}
+	if err := ac.DeleteAll(ctx); err != nil {
+		return fmt.Errorf("stress: failed to clear namespace: %w", err)
+	}
+
+	r := &run{ac: ac, config: config, ids: make(map[string]bool)}
+	workers := make([]*worker, config.Workers)
+	for w := range workers {
+		workers[w] = &worker{
+			run:   r,
+			name:  fmt.Sprintf("worker %d", w),
+			rng:   rand.New(rand.NewPCG(config.Seed, uint64(w))),
+			texts: make(map[string]string),
+		}
+		for i := 0; i < config.IDsPerWorker; i++ {
+			id := fmt.Sprintf("w%d-%d", w, i)
+			workers[w].owned = append(workers[w].owned, id)
+			r.ids[id] = true
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.work(ctx)
+		}()
+	}
+	if config.StaleMatches {
+		done := make(chan struct{})
+		maintained := make(chan struct{})
+		go func() {
+			defer close(maintained)
+			r.maintain(ctx, done)
+		}()
+		wg.Wait()
+		close(done)
+		<-maintained
+
+		r.maintainFinal(ctx)
+	} else {
+		wg.Wait()
+	}
+
+	r.verifyFinal(ctx, workers)
+
+	if err := ac.DeleteAll(ctx); err != nil {
+		r.violate("failed to clear namespace: %v", err)
+	}
+	return r.err()
+}
+
+// run holds the state shared by the workers of a run.
+type run struct {
+	ac     autocomplete.AutoComplete
+	config Config
+	ids    map[string]bool
+
+	mu         sync.Mutex
+	violations []string
+	dropped    int
+}
+
+// violate records an inconsistency.
+func (r *run) violate(format string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.violations) == maxViolations {
+		r.dropped++
+		return
+	}
+	r.violations = append(r.violations, fmt.Sprintf(format, args...))
+}
+
+// failed reports whether any inconsistency has been recorded.
+func (r *run) failed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.violations) > 0
+}
+
+// err returns the recorded inconsistencies as one error.
+func (r *run) err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.violations) == 0 {
+		return nil
+	}
+	msg := "stress: " + strings.Join(r.violations, "\n\t")
+	if r.dropped > 0 {
+		msg += fmt.Sprintf("\n\t(and %d more)", r.dropped)
+	}
+	return errors.New(msg)
+}
+
+// maintain runs Maintain repeatedly until done is closed.
+func (r *run) maintain(ctx context.Context, done <-chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		if _, err := r.ac.Maintain(ctx, autocomplete.MaintenanceOptions{}); err != nil {
+			r.violate("Maintain failed: %v", err)
+			return
+		}
+	}
+}
+
+// maintainFinal runs Maintain until a pass removes nothing, as a pass may miss
+// tokens whose removal or creation overlapped it.
+func (r *run) maintainFinal(ctx context.Context) {
+	for pass := 0; pass < maxMaintainPasses; pass++ {
+		stats, err := r.ac.Maintain(ctx, autocomplete.MaintenanceOptions{})
+		if err != nil {
+			r.violate("final Maintain failed: %v", err)
+			return
+		}
+		if stats.Removed == 0 {
+			return
+		}
+	}
+	r.violate("final Maintain still removed tokens after %d passes", maxMaintainPasses)
+}
+
+// check verifies the results of a query made at any time: they fit the
+// limit, are distinct entries of the run, carry a display of their own ID,
+// and, unless displays or matches may be stale, a display matching the query.
+func (r *run) check(who, word string, results []autocomplete.Result) {
+	if len(results) > r.config.QueryLimit {
+		r.violate("%s: Query(%q) returned %d results, over the limit of %d", who, word, len(results), r.config.QueryLimit)
+	}
+	seen := make(map[string]bool, len(results))
+	for _, result := range results {
+		switch {
+		case !r.ids[result.ID]:
+			r.violate("%s: Query(%q) returned unknown ID %q", who, word, result.ID)
+		case seen[result.ID]:
+			r.violate("%s: Query(%q) returned ID %q twice", who, word, result.ID)
+		case !strings.HasPrefix(result.Display, result.ID+displaySeparator):
+			r.violate("%s: Query(%q) returned ID %q with the display %q of another entry", who, word, result.ID, result.Display)
+		case !r.config.StaleDisplays && !r.config.StaleMatches && !strings.Contains(result.Display, word):
+			r.violate("%s: Query(%q) returned ID %q with the non-matching display %q", who, word, result.ID, result.Display)
+		}
+		seen[result.ID] = true
+	}
+}
+
+// verifyFinal checks, once all workers have finished, that each word matches
+// exactly the entries last indexed with it.
+func (r *run) verifyFinal(ctx context.Context, workers []*worker) {
+	expected := make(map[string][]string)
+	for _, w := range workers {
+		for id, text := range w.texts {
+			word := strings.Fields(text)[0]
+			expected[word] = append(expected[word], id)
+		}
+	}
+
+	for _, word := range words {
+		results, err := r.ac.Query(ctx, word, r.config.QueryLimit)
+		if err != nil {
+			r.violate("final Query(%q) failed: %v", word, err)
+			continue
+		}
+		r.check("final", word, results)
+
+		got := make([]string, len(results))
+		for i, result := range results {
+			got[i] = result.ID
+		}
+		want := expected[word]
+		sort.Strings(got)
+		sort.Strings(want)
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			r.violate("final Query(%q) returned %v, want %v", word, got, want)
+		}
+	}
+}
+
+// worker makes random calls on the entries it owns, tracking their texts.
+type worker struct {
+	run   *run
+	name  string
+	rng   *rand.Rand
+	owned []string
+
+	// texts maps each owned entry that is currently indexed to its text.
+	texts map[string]string
+}
+
+func (w *worker) work(ctx context.Context) {
+	for op := 0; op < w.run.config.Operations && ctx.Err() == nil && !w.run.failed(); op++ {
+		id := w.owned[w.rng.IntN(len(w.owned))]
+		word := words[w.rng.IntN(len(words))]
+
+		switch n := w.rng.IntN(100); {
+		case n < 50:
+			text := fmt.Sprintf("%s %s v%d", word, id, op)
+			if err := w.run.ac.Index(ctx, id, text, Display(id, text)); err != nil {
+				w.run.violate("%s: Index(%q) failed: %v", w.name, id, err)
+				continue
+			}
+			w.texts[id] = text
+		case n < 65:
+			if err := w.run.ac.Delete(ctx, id); err != nil {
+				w.run.violate("%s: Delete(%q) failed: %v", w.name, id, err)
+				continue
+			}
+			delete(w.texts, id)
+		default:
+			results, err := w.run.ac.Query(ctx, word, w.run.config.QueryLimit)
+			if err != nil {
+				w.run.violate("%s: Query(%q) failed: %v", w.name, word, err)
+				continue
+			}
+			w.run.check(w.name, word, results)
+			w.checkOwn(word, results)
+		}
+	}
+}
+
+// checkOwn verifies a query against the entries the worker owns, which no
+// other worker changes: each must be returned exactly when its current text
+// has the word, or at least then if matches may be stale.
+func (w *worker) checkOwn(word string, results []autocomplete.Result) {
+	returned := make(map[string]bool, len(results))
+	for _, result := range results {
+		returned[result.ID] = true
+	}
+	for _, id := range w.owned {
+		text, indexed := w.texts[id]
+		want := indexed && strings.HasPrefix(text, word+" ")
+		if returned[id] != want && (want || !w.run.config.StaleMatches) {
+			w.run.violate("%s: Query(%q) returned %q: %v, want %v (current text %q)", w.name, word, id, returned[id], want, text)
+		}
+	}
+}
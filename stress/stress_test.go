@@ -0,0 +1,61 @@
+package stress_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/remiges-tech/autocomplete"
+	"github.com/remiges-tech/autocomplete/providers/memory"
+	"github.com/remiges-tech/autocomplete/stress"
+)
+
+// recordingAutoComplete records the display of every entry indexed through it.
+type recordingAutoComplete struct {
+	autocomplete.AutoComplete
+	displays *sync.Map
+}
+
+func (r recordingAutoComplete) Index(ctx context.Context, id, text, display string) error {
+	r.displays.Store(id, display)
+	return r.AutoComplete.Index(ctx, id, text, display)
+}
+
+func TestDisplayCache(t *testing.T) {
+	// The resolver serves the display most recently indexed for each ID
+	var displays sync.Map
+	options := autocomplete.DefaultOptions()
+	options.DisplayCacheSize = 16
+	options.DisplayResolver = func(ctx context.Context, ids []string) map[string]string {
+		resolved := make(map[string]string, len(ids))
+		for _, id := range ids {
+			if display, ok := displays.Load(id); ok {
+				resolved[id] = display.(string)
+			}
+		}
+		return resolved
+	}
+
+	ac, err := autocomplete.New("memory", autocomplete.NewConfigWithOptions(memory.Config{}, options))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer func() { _ = ac.Close() }()
+
+	err = stress.Run(context.Background(), recordingAutoComplete{AutoComplete: ac, displays: &displays}, stress.Config{StaleDisplays: true})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRunRejectsOversizedConfig(t *testing.T) {
+	ac, err := autocomplete.New("memory", autocomplete.NewConfig(memory.Config{}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer func() { _ = ac.Close() }()
+
+	if err := stress.Run(context.Background(), ac, stress.Config{Workers: 20, IDsPerWorker: 10}); err == nil {
+		t.Error("Run() should reject more entries than the query limit")
+	}
+}
@@ -0,0 +1,124 @@
+package autocomplete
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// TokenBudgetPolicy decides what happens to entries whose token expansion
+// exceeds Options.MaxTokensPerEntry.
+type TokenBudgetPolicy int
+
+const (
+	// TokenBudgetReject fails indexing with ErrTokenBudgetExceeded.
+	TokenBudgetReject TokenBudgetPolicy = iota
+	// TokenBudgetTruncate indexes the longest prefix of the text that fits the
+	// budget and reports the truncation through Hooks.OnTruncate.
+	TokenBudgetTruncate
+)
+
+// tokenCount returns how many tokens the configured strategies generate for text.
+// It mirrors the byte-oriented tokenization of the Redis provider; backends
+// with their own analyzers (Elasticsearch) may store a different number, so
+// treat the result as an estimate of index fan-out.
+func tokenCount(text string, options Options) int {
+	text = providers.SearchText(text, options.CaseSensitive)
+
+	n := options.NGramSize
+	if n <= 0 {
+		n = defaultNGramSize
+	}
+
+	if len(options.MatchStrategies) == 0 {
+		return strategyTokenCount(options.MatchStrategy, text, n)
+	}
+	total := 0
+	for _, strategy := range options.MatchStrategies {
+		total += strategyTokenCount(strategy, text, n)
+	}
+	return total
+}
+
+// strategyTokenCount returns how many tokens a single strategy generates for
+// search text.
+func strategyTokenCount(strategy MatchStrategy, text string, n int) int {
+	length := len(text)
+	switch strategy {
+	case MatchPrefix:
+		return length
+	case MatchNGram:
+		return max(0, length-n+1)
+	case MatchNOrMoreGram:
+		if length < n {
+			return 0
+		}
+		return (length - n + 1) * (length - n + 2) / 2
+	case MatchSubstring:
+		return length * (length + 1) / 2
+	case MatchSuffix:
+		return length
+	case MatchAnchoredSubstring:
+		return len(providers.AnchoredSubstrings(text, n))
+	default:
+		return length
+	}
+}
+
+// applyTokenBudget enforces Options.MaxTokensPerEntry on an entry, returning the
+// entry to index (possibly with truncated text) or ErrTokenBudgetExceeded.
+func (a *autocompleteImpl) applyTokenBudget(ctx context.Context, entry Entry) (Entry, error) {
+	budget := a.config.Options.MaxTokensPerEntry
+	if budget <= 0 {
+		return entry, nil
+	}
+
+	tokens := tokenCount(entry.Text, a.config.Options)
+	if tokens <= budget {
+		return entry, nil
+	}
+	if a.config.Options.TokenBudgetPolicy != TokenBudgetTruncate {
+		return entry, fmt.Errorf("%w: entry %q would generate %d tokens, budget is %d", ErrTokenBudgetExceeded, entry.ID, tokens, budget)
+	}
+
+	truncated := entry
+	truncated.Text = truncateToBudget(entry.Text, budget, a.config.Options)
+	if truncated.Text == "" {
+		return entry, fmt.Errorf("%w: entry %q cannot be truncated to %d tokens", ErrTokenBudgetExceeded, entry.ID, budget)
+	}
+
+	if a.config.Options.Hooks.OnTruncate != nil {
+		a.config.Options.Hooks.OnTruncate(ctx, TruncateEvent{
+			Namespace:      a.config.Options.Namespace,
+			ID:             entry.ID,
+			OriginalLength: len(entry.Text),
+			IndexedLength:  len(truncated.Text),
+			OriginalTokens: tokens,
+			IndexedTokens:  tokenCount(truncated.Text, a.config.Options),
+		})
+	}
+	return truncated, nil
+}
+
+// truncateToBudget returns the longest prefix of text, cut at a rune boundary,
+// whose token count fits the budget. Token counts grow with length, so the
+// cut point is found by binary search.
+func truncateToBudget(text string, budget int, options Options) string {
+	boundaries := make([]int, 0, len(text))
+	for i := range text {
+		if i > 0 {
+			boundaries = append(boundaries, i)
+		}
+	}
+	boundaries = append(boundaries, len(text))
+
+	cut := sort.Search(len(boundaries), func(i int) bool {
+		return tokenCount(text[:boundaries[i]], options) > budget
+	})
+	if cut == 0 {
+		return ""
+	}
+	return text[:boundaries[cut-1]]
+}
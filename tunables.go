@@ -0,0 +1,117 @@
+package autocomplete
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// OptionsPatch lists options to change on a live instance with
+// AutoComplete.UpdateOptions. Nil fields keep their current value. Only
+// options applied at query time can be patched; the others shape what is
+// stored and need reindexing.
+type OptionsPatch struct {
+	// DefaultLimit replaces Options.DefaultLimit.
+	DefaultLimit *int
+
+	// MaxLimit replaces Options.MaxLimit.
+	MaxLimit *int
+
+	// LimitPolicy replaces Options.LimitPolicy.
+	LimitPolicy *LimitPolicy
+
+	// MinPrefixLength replaces Options.MinPrefixLength.
+	MinPrefixLength *int
+
+	// MinPrefixLengthUnit replaces Options.MinPrefixLengthUnit.
+	MinPrefixLengthUnit *LengthUnit
+
+	// MinScore replaces Options.MinScore.
+	MinScore *float64
+}
+
+// tunables holds the options OptionsPatch can change. Queries read them on
+// every call, so UpdateOptions replaces them as a whole instead of modifying
+// them in place; the copies in config.Options keep the values given to New.
+type tunables struct {
+	defaultLimit        int
+	maxLimit            int
+	limitPolicy         LimitPolicy
+	minPrefixLength     int
+	minPrefixLengthUnit LengthUnit
+	minScore            float64
+}
+
+// newTunables returns the tunables of the given options.
+func newTunables(options Options) *tunables {
+	return &tunables{
+		defaultLimit:        options.DefaultLimit,
+		maxLimit:            options.MaxLimit,
+		limitPolicy:         options.LimitPolicy,
+		minPrefixLength:     options.MinPrefixLength,
+		minPrefixLengthUnit: options.MinPrefixLengthUnit,
+		minScore:            options.MinScore,
+	}
+}
+
+// tuning returns the current tunables.
+func (a *autocompleteImpl) tuning() *tunables {
+	return a.tunables.Load()
+}
+
+// UpdateOptions changes query-time options of a live instance.
+// See AutoComplete.UpdateOptions for details.
+func (a *autocompleteImpl) UpdateOptions(ctx context.Context, patch OptionsPatch) error {
+	a.tunablesMu.Lock()
+	defer a.tunablesMu.Unlock()
+
+	t := *a.tuning()
+	if patch.DefaultLimit != nil {
+		t.defaultLimit = *patch.DefaultLimit
+	}
+	if patch.MaxLimit != nil {
+		t.maxLimit = *patch.MaxLimit
+	}
+	if patch.LimitPolicy != nil {
+		t.limitPolicy = *patch.LimitPolicy
+	}
+	if patch.MinPrefixLength != nil {
+		t.minPrefixLength = *patch.MinPrefixLength
+	}
+	if patch.MinPrefixLengthUnit != nil {
+		t.minPrefixLengthUnit = *patch.MinPrefixLengthUnit
+	}
+	if patch.MinScore != nil {
+		t.minScore = *patch.MinScore
+	}
+
+	switch {
+	case t.defaultLimit <= 0:
+		return fmt.Errorf("%w: DefaultLimit must be positive, got %d", ErrInvalidOptions, t.defaultLimit)
+	case t.maxLimit < t.defaultLimit:
+		return fmt.Errorf("%w: MaxLimit %d is below DefaultLimit %d", ErrInvalidOptions, t.maxLimit, t.defaultLimit)
+	case t.minPrefixLength < 0:
+		return fmt.Errorf("%w: MinPrefixLength must not be negative, got %d", ErrInvalidOptions, t.minPrefixLength)
+	}
+
+	a.tunables.Store(&t)
+	return nil
+}
+
+// aboveMinScore drops the results scoring below Options.MinScore, or the
+// QueryOptions.MinScore of the query made with ctx, for providers that do not
+// apply providers.QueryOptions.MinScore themselves.
+func (a *autocompleteImpl) aboveMinScore(ctx context.Context, results []providers.ProviderResult) []providers.ProviderResult {
+	minScore := a.minScore(ctx)
+	if minScore == 0 {
+		return results
+	}
+	kept := make([]providers.ProviderResult, 0, len(results))
+	for _, result := range results {
+		if result.Score >= minScore {
+			kept = append(kept, result)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,68 @@
+package autocomplete
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/remiges-tech/autocomplete/providers"
+)
+
+// WithWarmStart makes New populate the namespace from a snapshot when it has no
+// entries, e.g. on the first start of a process using the memory provider or of
+// an ephemeral environment. The snapshot is a stream of JSON-encoded Entry
+// values, typically one per line:
+//
+//	{"id":"400001","text":"400001 Mumbai","display":"Mumbai GPO, 400001","metadata":{"state":"MH"},"score":5}
+//
+// Metadata and score are optional and are honored as by IndexBatch. A
+// namespace that already has entries is left as it is and the snapshot is not
+// read. New fails if the snapshot cannot be read or an entry cannot be
+// indexed, and with ErrWarmStartUnsupported if the provider does not implement
+// providers.EntryLister, which tells whether the namespace is empty.
+func WithWarmStart(snapshot io.Reader) NewOption {
+	return func(o *newOptions) {
+		o.warmStart = snapshot
+	}
+}
+
+// warmStart populates an empty namespace from a snapshot as described in WithWarmStart.
+func (a *autocompleteImpl) warmStart(ctx context.Context, snapshot io.Reader) error {
+	lister, ok := a.provider.(providers.EntryLister)
+	if !ok {
+		return ErrWarmStartUnsupported
+	}
+	existing, _, err := lister.ListEntries(ctx, a.config.Options.Namespace, "", 1)
+	if err != nil {
+		return fmt.Errorf("warm start: failed to check namespace: %w", err)
+	}
+	if len(existing) > 0 {
+		return nil
+	}
+
+	decoder := json.NewDecoder(snapshot)
+	for n := 1; ; n++ {
+		var entry Entry
+		err := decoder.Decode(&entry)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("warm start: failed to read entry %d: %w", n, err)
+		}
+		if err := a.warmStartEntry(ctx, entry); err != nil {
+			return fmt.Errorf("warm start: failed to index entry %q: %w", entry.ID, err)
+		}
+	}
+}
+
+// warmStartEntry indexes a snapshot entry, with its metadata and score if it
+// has them.
+func (a *autocompleteImpl) warmStartEntry(ctx context.Context, entry Entry) error {
+	if len(entry.Metadata) > 0 || entry.Score != 0 {
+		return a.IndexBatch(ctx, []Entry{entry})[0].Err
+	}
+	return a.Index(ctx, entry.ID, entry.Text, entry.Display)
+}